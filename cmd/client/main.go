@@ -1,27 +1,75 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
 
 	client "bomberman/internal/client"
+	"bomberman/pkg/ai"
 	"bomberman/pkg/core"
 )
 
 func main() {
+	// 读取上次保存的配置，作为命令行参数的默认值
+	configPath := client.DefaultConfigPath()
+	cfg, err := client.LoadClientConfig(configPath)
+	if err != nil {
+		log.Printf("读取客户端配置失败，使用默认配置: %v", err)
+	}
+
 	// 命令行参数
-	serverAddr := flag.String("server", "", "服务器地址（留空为单机模式）")
-	proto := flag.String("proto", "tcp", "服务器协议: tcp 或 kcp")
-	character := flag.Int("character", 0, "角色类型 (0=白, 1=黑, 2=红, 3=蓝)")
-	control := flag.String("control", "wasd", "控制方案 (wasd 或 arrow)")
+	serverAddr := flag.String("server", cfg.Server, "服务器地址（默认上次使用的地址，-server= 强制单机模式）")
+	proto := flag.String("proto", cfg.Proto, "服务器协议: tcp、kcp 或 ws")
+	character := flag.Int("character", cfg.Character, "角色类型 (0=白, 1=黑, 2=红, 3=蓝)")
+	control := flag.String("control", cfg.Control, "控制方案 (wasd 或 arrow)")
+	accountKey := flag.String("account", cfg.Account, "账号密钥（8-64 个字符）：服务器按账号保存角色、控制方案和房主的规则预设，换设备使用同一密钥即可恢复")
+	telemetry := flag.Bool("telemetry", cfg.Telemetry, "同意服务器收集本局的输入观察（输入频率、超前输入），被举报时随举报保存供运维核查；聊天也只有同意时才写入举报（默认不同意）")
+	bind := flag.String("bind", "", "改键并保存，例如 wasd.bomb=J,arrow.bomb=Numpad0,pad.bomb=X（动作: up/down/left/right/bomb/shove/sprint，手柄只能改 bomb/shove/sprint）")
+	bindingsPath := flag.String("bindings", "", "按键文件（JSON，格式同配置的 keys 字段）：使用其中的按键代替配置中的按键，-bind 的修改保存到该文件")
+	localPlayers := flag.Int("local-players", 1, "单机模式的本地玩家数（2 = 双人同屏，第二名玩家使用另一套控制方案）")
+	theme := flag.String("theme", cfg.Theme, "主题包 ("+strings.Join(client.ThemeNames(), ", ")+"，大厅中按 P 切换)")
+	status := flag.Bool("status", false, "查询 -server 的状态（名称、版本、人数、公告）后退出，无法连接时退出码为 1（用于监控）")
+	browse := flag.Bool("browse", false, "显示服务器列表，查看延迟与人数后选择连接（按数字键一键连接）")
+	saveServer := flag.String("save-server", "", "保存服务器到列表，格式 名称=地址[/协议]，例如 \"Home LAN=192.168.1.5:8080\"")
 	quick := flag.Bool("quick", false, "兼容模式：跳过大厅，直接加入默认房间")
+	hideHUD := flag.Bool("hide-hud", false, "启动时隐藏 HUD（游戏中按 F1 切换，用于录制）")
+	aiScriptPath := flag.String("ai-script", "", "AI 脚本文件，房主在大厅按 A 添加脚本 AI（需服务器 -debug-scenarios）")
+	caster := flag.Bool("caster", false, "解说模式：自动以观战者加入 -room（为空时选择游戏中的房间），只显示记分板，镜头自动跟随（1-4 跟随玩家，0 自动）")
+	casterRoom := flag.String("room", "", "解说模式要观战的房间 ID")
+	fullFPSUnfocused := flag.Bool("full-fps-unfocused", cfg.FullFPSUnfocused, "窗口失焦或最小化时仍满帧绘制（直播推流时使用，默认降低绘制频率省电）")
+	tournament := flag.Bool("tournament", false, "同屏淘汰赛：输入 3-8 名选手，两两 1v1（WASD 对方向键）决出冠军，不连接服务器")
+	fontFile := flag.String("font", "", "界面字体文件（TTF/OTF/TTC，需覆盖中文；默认使用内置字体，其次系统中文字体，都没有时使用点阵字体）")
+	editMap := flag.String("edit-map", "", "打开地图编辑器编辑该 JSON 地图文件（不存在时以内置地图为起点），按 U 上传到 -server")
+	assets := flag.String("assets", "", "精灵图目录：其中的同名 PNG 代替内置精灵图（布局见 internal/client/sprites/README.md），缺少的图使用内置版本")
+	volume := flag.Int("volume", cfg.Volume, "音量 0-100（游戏中 F5/F6 调节）")
+	mute := flag.Bool("mute", cfg.Muted, "静音启动（游戏中按 F4 切换）")
+	sessionLog := flag.String("session-log", client.DefaultSessionLogPath(), "退出时把会话摘要（对局数、RTT 与抖动、重连次数、预测纠偏）追加到该文件（空表示不写，单机模式不写）")
+	printSession := flag.Bool("session-summary", false, "退出时同时把会话摘要打印到标准输出")
 	flag.Parse()
 
+	// 显式指定的角色和控制方案以本地为准（并保存到账号），否则使用账号保存的偏好
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	explicitControl := ""
+	if explicit["control"] {
+		explicitControl = *control
+	}
+
+	if *status {
+		printServerStatus(*serverAddr, *proto)
+		return
+	}
+
 	// 解析角色类型
 	charType := core.CharacterType(*character)
 	if charType < core.CharacterWhite || charType > core.CharacterBlue {
@@ -39,16 +87,113 @@ func main() {
 		log.Fatalf("无效的控制方案: %s (使用 'wasd' 或 'arrow')", *control)
 	}
 
-	// 设置窗口选项
-	ebiten.SetWindowSize(client.ScreenWidth, client.ScreenHeight)
-	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeDisabled)
+	// 改键（两名本地玩家的按键不能冲突）
+	keysPath := configPath
+	keys := &cfg.Keys
+	if *bindingsPath != "" {
+		keysPath = *bindingsPath
+		loaded, err := client.LoadControlKeys(*bindingsPath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Fatalf("读取按键文件失败: %v", err)
+		}
+		keys = &loaded
+	}
+	if err := keys.Rebind(*bind); err != nil {
+		log.Fatalf("无效的改键设置: %v", err)
+	}
+	if err := client.SetControlKeys(*keys); err != nil {
+		log.Fatalf("%v（修改 %s 或使用 -bind）", err, keysPath)
+	}
+	if *bindingsPath != "" && *bind != "" {
+		if err := client.SaveControlKeys(*bindingsPath, *keys); err != nil {
+			log.Fatalf("保存按键文件失败: %v", err)
+		}
+	}
+	if *caster && (*serverAddr == "" || *browse || *quick) {
+		log.Fatalf("解说模式需要 -server，且不能与 -browse、-quick 同时使用")
+	}
+	if *localPlayers < 1 || *localPlayers > 2 {
+		log.Fatalf("无效的本地玩家数: %d", *localPlayers)
+	}
+
+	if err := client.SetTheme(*theme); err != nil {
+		log.Fatalf("无效的主题: %v", err)
+	}
+	client.InitFonts(*fontFile)
+	client.InitSprites(*assets)
+	if *volume < 0 || *volume > 100 {
+		log.Fatalf("无效的音量: %d（0-100）", *volume)
+	}
+	client.InitAudio(*volume, *mute)
+
+	if *saveServer != "" {
+		server, err := client.ParseSavedServer(*saveServer)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		cfg.AddServer(server)
+		log.Printf("已保存服务器: %s (%s)", server.Name, server.Address)
+	}
+
+	// 读取并预先校验 AI 脚本，避免到服务器才发现语法错误
+	var aiScript string
+	if *aiScriptPath != "" {
+		data, err := os.ReadFile(*aiScriptPath)
+		if err != nil {
+			log.Fatalf("读取 AI 脚本失败: %v", err)
+		}
+		if _, err := ai.ParseScript(string(data)); err != nil {
+			log.Fatalf("AI 脚本无效: %v", err)
+		}
+		aiScript = string(data)
+	}
+
+	// 记住本次使用的参数，下次启动无需再传
+	cfg.Server = *serverAddr
+	cfg.Proto = *proto
+	cfg.Character = *character
+	cfg.Control = *control
+	cfg.Account = *accountKey
+	cfg.Telemetry = *telemetry
+	cfg.FullFPSUnfocused = *fullFPSUnfocused
+
+	// 设置窗口选项（恢复上次的窗口位置与大小，画面按 Layout 缩放）
+	cfg.ApplyWindow()
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetTPS(client.FPS)
 
 	var game ebiten.Game
 	var title string
 	var networkClient *client.NetworkClient
+	var browser *client.ServerBrowser
+
+	if *editMap != "" {
+		// ========== 地图编辑器 ==========
+		editor, err := client.NewMapEditor(*editMap, charType, controlScheme)
+		if err != nil {
+			log.Fatalf("打开地图失败: %v", err)
+		}
+		editor.SetUploadServer(*serverAddr, *proto)
+		game = editor
+		title = "Bomberman - 地图编辑器 [" + *editMap + "]"
+	} else if *tournament {
+		// ========== 同屏淘汰赛 ==========
+		t := client.NewTournament()
+		t.SetHUDHidden(*hideHUD)
+		game = t
+		title = "Bomberman - 同屏淘汰赛"
+	} else if *browse {
+		// ========== 服务器列表 ==========
+		log.Printf("服务器列表: %d 个已保存的服务器", len(cfg.Servers))
 
-	if *serverAddr == "" {
+		browser = client.NewServerBrowser(cfg.Servers, charType, controlScheme)
+		browser.SetHUDHidden(*hideHUD)
+		browser.SetAIScript(aiScript)
+		browser.SetAccount(*accountKey, explicit["character"], explicitControl)
+		browser.SetTelemetry(*telemetry)
+		game = browser
+		title = "Bomberman - 服务器列表 [" + charType.String() + "] [" + controlScheme.String() + "]"
+	} else if *serverAddr == "" {
 		// ========== 单机模式 ==========
 		log.Println("========================================")
 		log.Println("  Bomberman - 单机模式")
@@ -58,7 +203,9 @@ func main() {
 		log.Println("========================================")
 
 		// 创建单机游戏
-		game = createLocalGame(charType, controlScheme)
+		localGame := createLocalGame(charType, controlScheme, *localPlayers)
+		localGame.SetHUDHidden(*hideHUD)
+		game = localGame
 		title = "Bomberman - 单机模式 [" + charType.String() + "] [" + controlScheme.String() + "]"
 	} else {
 		// ========== 联机模式 ==========
@@ -73,79 +220,167 @@ func main() {
 
 		// 创建联机游戏
 		networkClient = client.NewNetworkClient(*serverAddr, *proto, charType)
+		networkClient.SetAccount(*accountKey, explicit["character"], explicitControl)
+		networkClient.SetTelemetry(*telemetry)
 
 		if err := networkClient.Connect(); err != nil {
 			log.Fatalf("连接服务器失败: %v", err)
 		}
 		defer networkClient.Close()
-		setupSignalHandler(networkClient)
+		if err := networkClient.SaveControlPreference(); err != nil {
+			log.Printf("保存控制方案失败: %v", err)
+		}
 
 		if *quick {
 			if _, err := networkClient.JoinRoom("default"); err != nil {
 				log.Fatalf("加入默认房间失败: %v", err)
 			}
-			var err error
-			game, err = client.NewNetworkGameClient(networkClient, controlScheme)
+			if scheme, ok := networkClient.StoredControl(); ok {
+				controlScheme = scheme
+			}
+			gameClient, err := client.NewNetworkGameClient(networkClient, controlScheme)
 			if err != nil {
 				log.Fatalf("创建联机游戏失败: %v", err)
 			}
+			gameClient.SetHUDHidden(*hideHUD)
+			game = gameClient
 			title = "Bomberman - 联机模式 [" + *proto + "] [" + *serverAddr + "] [" + charType.String() + "] [" + controlScheme.String() + "]"
 		} else {
-			game = client.NewLobbyClient(networkClient, controlScheme)
+			lobby := client.NewLobbyClient(networkClient, controlScheme)
+			lobby.SetHUDHidden(*hideHUD)
+			lobby.SetAIScript(aiScript)
+			game = lobby
 			title = "Bomberman - 大厅 [" + *proto + "] [" + *serverAddr + "] [" + charType.String() + "] [" + controlScheme.String() + "]"
+			if *caster {
+				lobby.SetCaster(*casterRoom)
+				title = "Bomberman - 解说 [" + *serverAddr + "]"
+			}
 		}
 	}
 
 	ebiten.SetWindowTitle(title)
 
-	// 运行游戏
-	log.Println("游戏启动！")
-	if err := ebiten.RunGame(game); err != nil {
+	tracker := client.NewWindowTracker(client.NewIdleRenderer(client.NewAudioControls(game), !cfg.FullFPSUnfocused), cfg)
+	saveConfig := func() {
+		// 主题可能在大厅中切换过，以当前主题为准
+		latest := tracker.Config()
+		latest.Theme = client.ActiveTheme().Name
+		latest.Volume, latest.Muted = client.AudioSettings()
+		// 从服务器列表连接的服务器记为上次使用的服务器
+		if browser != nil {
+			if server, ok := browser.Connected(); ok {
+				latest.Server, latest.Proto = server.Address, server.Proto
+			}
+		}
+		if err := latest.Save(configPath); err != nil {
+			log.Printf("保存客户端配置失败: %v", err)
+		}
+	}
+	saveSession := func() {
+		summary := client.SessionSummary()
+		if summary == "" {
+			return
+		}
+		if *printSession {
+			fmt.Print(summary)
+		}
+		if *sessionLog == "" {
+			return
+		}
+		if err := client.AppendSessionSummary(*sessionLog); err != nil {
+			log.Printf("写入会话摘要失败: %v", err)
+		}
+	}
+	onExit := func() {
+		saveConfig()
+		saveSession()
+	}
+	closeNetwork := func() {
 		if networkClient != nil {
 			networkClient.Close()
 		}
-		log.Fatalf("游戏运行错误: %v", err)
+		if browser != nil {
+			browser.Close()
+		}
 	}
-}
+	setupSignalHandler(closeNetwork, onExit)
 
-func setupSignalHandler(networkClient *client.NetworkClient) {
-	if networkClient == nil {
-		return
+	// 运行游戏
+	log.Println("游戏启动！")
+	if err := ebiten.RunGame(tracker); err != nil {
+		closeNetwork()
+		onExit()
+		log.Fatalf("游戏运行错误: %v", err)
 	}
+	onExit()
+}
 
+func setupSignalHandler(closeNetwork func(), onExit func()) {
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		<-signalChan
-		networkClient.Close()
+		closeNetwork()
+		onExit()
 		os.Exit(0)
 	}()
 }
 
-// createLocalGame 创建单机游戏
-func createLocalGame(character core.CharacterType, controlScheme client.ControlScheme) *client.Game {
-	game := client.NewGame()
-	game.SetControlScheme(controlScheme)
+// printServerStatus 查询服务器状态并输出一行，供监控脚本使用
+func printServerStatus(serverAddr, proto string) {
+	if serverAddr == "" {
+		log.Fatal("-status 需要指定 -server")
+	}
+	status, err := client.QueryServerStatus(serverAddr, proto, 5*time.Second)
+	if err != nil {
+		fmt.Printf("%s offline: %v\n", serverAddr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s online name=%q version=%s ping=%dms players=%d rooms=%d max_rooms=%d motd=%q\n",
+		serverAddr, status.Name, status.Version, status.Ping.Milliseconds(),
+		status.Players, status.Rooms, status.MaxRooms, status.MOTD)
+}
 
-	// 创建本地玩家
-	x, y := client.GridToPlayerXY(0, 0)
-	player := client.NewPlayer(game, 1, x, y, character, false)
-	game.AddPlayer(player)
+// createLocalGame 创建单机游戏（Esc 暂停，可在菜单中重新开局）
+// 双人同屏时第二名玩家出生在右上角，使用另一套控制方案
+func createLocalGame(character core.CharacterType, controlScheme client.ControlScheme, localPlayers int) *client.Game {
+	setup := func(game *client.Game) {
+		// 创建本地玩家
+		x, y := client.GridToPlayerXY(0, 0)
+		player := client.NewPlayer(game, 1, x, y, character, false)
+		game.AddPlayer(player)
 
-	// 添加 AI 玩家（可选）
-	addAIPlayers(game, 3)
+		if localPlayers == 2 {
+			otherScheme := client.ControlArrow
+			if controlScheme == client.ControlArrow {
+				otherScheme = client.ControlWASD
+			}
+			x, y := client.GridToPlayerXY(core.MapWidth-1, 0)
+			second := client.NewPlayer(game, 2, x, y, core.CharacterRed, false)
+			second.SetControlScheme(otherScheme)
+			game.AddPlayer(second)
+		}
 
+		// 添加 AI 玩家（可选）
+		addAIPlayers(game, localPlayers+1, 4-localPlayers)
+	}
+
+	game := client.NewGame()
+	game.SetControlScheme(controlScheme)
+	setup(game)
+	game.EnablePause(setup)
 	return game
 }
 
-// addAIPlayers 添加 AI 玩家（用于测试）
-func addAIPlayers(game *client.Game, count int) {
+// addAIPlayers 从 firstID 开始添加 AI 玩家（用于测试）
+func addAIPlayers(game *client.Game, firstID int, count int) {
 	spawns := []struct{ x, y int }{
 		{core.MapWidth - 1, 0},                  // 右上角
 		{0, core.MapHeight - 1},                 // 左下角
 		{core.MapWidth - 1, core.MapHeight - 1}, // 右下角
 	}
+	spawns = spawns[firstID-2:]
 
 	chars := []core.CharacterType{
 		core.CharacterWhite,
@@ -155,7 +390,7 @@ func addAIPlayers(game *client.Game, count int) {
 
 	for i := 0; i < count && i < len(spawns); i++ {
 		x, y := client.GridToPlayerXY(spawns[i].x, spawns[i].y)
-		aiPlayer := client.NewPlayer(game, i+2, x, y, chars[i%len(chars)], true)
+		aiPlayer := client.NewPlayer(game, firstID+i, x, y, chars[i%len(chars)], true)
 		game.AddPlayer(aiPlayer)
 	}
 }
@@ -0,0 +1,109 @@
+// headlesscheck 无头构建检查：核心逻辑、协议与服务器不能依赖 ebiten（图形/输入库）
+//
+//	headlesscheck              检查默认的包列表
+//	headlesscheck -pkgs ./pkg/core,./pkg/ai
+//
+// 先用 go list -deps 检查依赖闭包中没有被禁止的模块，再以 CGO_ENABLED=0 编译这些包，
+// 确认它们可以在没有 GL/X11 的机器上构建（仅服务器的 Docker 镜像、CI）
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPackages 必须保持无头的包（客户端 internal/client、cmd/client 除外）
+var defaultPackages = []string{
+	"./pkg/core",
+	"./pkg/ai",
+	"./pkg/protocol",
+	"./pkg/fairseed",
+	"./pkg/resources",
+	"./pkg/version",
+	"./pkg/selfplay",
+	"./internal/server",
+	"./cmd/server",
+	"./cmd/replayconv",
+	"./cmd/simulate",
+}
+
+// forbiddenPrefixes 依赖图形或输入设备的模块
+var forbiddenPrefixes = []string{
+	"github.com/hajimehoshi/ebiten",
+	"github.com/ebitengine/",
+	"github.com/jezek/xgb",
+	"golang.org/x/image",
+}
+
+func main() {
+	pkgsFlag := flag.String("pkgs", strings.Join(defaultPackages, ","), "要检查的包（逗号分隔）")
+	flag.Parse()
+
+	pkgs := strings.Split(*pkgsFlag, ",")
+
+	violations, err := checkDeps(pkgs)
+	if err != nil {
+		log.Fatalf("列出依赖失败: %v", err)
+	}
+	for _, v := range violations {
+		log.Printf("FAIL %s", v)
+	}
+	if len(violations) > 0 {
+		log.Fatalf("%d 个包引入了图形依赖（把相关代码移到 internal/client，或拆出不依赖 ebiten 的部分）", len(violations))
+	}
+
+	if err := run("go", append([]string{"build", "-o", os.DevNull}, pkgs...)...); err != nil {
+		log.Fatalf("CGO_ENABLED=0 编译失败: %v", err)
+	}
+	log.Printf("全部 %d 个包通过（无图形依赖，CGO_ENABLED=0 可编译）", len(pkgs))
+}
+
+// checkDeps 对每个包列出依赖闭包，返回形如 "pkg -> 被禁止的依赖" 的违规项（每个包只报告第一个）
+func checkDeps(pkgs []string) ([]string, error) {
+	var violations []string
+	for _, pkg := range pkgs {
+		var out bytes.Buffer
+		cmd := exec.Command("go", "list", "-deps", "-f", "{{.ImportPath}}", pkg)
+		cmd.Env = headlessEnv()
+		cmd.Stdout = &out
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("%s: %w", pkg, err)
+		}
+		for _, dep := range strings.Fields(out.String()) {
+			if isForbidden(dep) {
+				violations = append(violations, fmt.Sprintf("%s -> %s", pkg, dep))
+				break
+			}
+		}
+	}
+	return violations, nil
+}
+
+func isForbidden(importPath string) bool {
+	for _, prefix := range forbiddenPrefixes {
+		if strings.HasPrefix(importPath, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// run 以无头环境执行命令，输出直接转发
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Env = headlessEnv()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// headlessEnv 关闭 cgo：ebiten 在 Linux 上需要 cgo（GL/X11），无头包不应受影响
+func headlessEnv() []string {
+	return append(os.Environ(), "CGO_ENABLED=0")
+}
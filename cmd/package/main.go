@@ -0,0 +1,257 @@
+// package 发布打包：为各平台编译客户端并打成带版本号的压缩包
+//
+//	package -version 1.2.0
+//	package -version 1.2.0 -targets windows/amd64,darwin/arm64 -out dist
+//
+// 版本号和 git 提交通过 -ldflags -X 写入 pkg/version，客户端在大厅显示版本，加入时携带协议版本。
+// 客户端的图形都由代码绘制，角色与地图名称编译在 pkg/resources 中，二进制不依赖外部资源文件。
+// windows、darwin 不需要 cgo，可以在任意平台交叉编译；linux 客户端需要 cgo，只能在 linux 上编译，
+// 其他平台打包时跳过并提示。每个平台生成 bomberman-<版本>-<系统>-<架构>.zip（windows）或
+// .tar.gz，输出目录中另有 SHA256SUMS
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// defaultTargets 默认发布的平台
+var defaultTargets = []string{
+	"windows/amd64",
+	"darwin/amd64",
+	"darwin/arm64",
+	"linux/amd64",
+}
+
+// validVersion 版本号同时出现在文件名中，只允许字母、数字、点和连字符
+var validVersion = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z.-]*$`)
+
+// extraFiles 随二进制一起打包的文件
+var extraFiles = []string{"README.md"}
+
+type target struct {
+	goos, goarch string
+}
+
+func (t target) String() string {
+	return t.goos + "/" + t.goarch
+}
+
+func main() {
+	versionFlag := flag.String("version", "", "发布版本号（必填，例如 1.2.0）")
+	targetsFlag := flag.String("targets", strings.Join(defaultTargets, ","), "目标平台（逗号分隔，格式 系统/架构）")
+	outDir := flag.String("out", "dist", "输出目录")
+	flag.Parse()
+
+	if !validVersion.MatchString(*versionFlag) {
+		log.Fatalf("无效的版本号 %q（只能包含字母、数字、. 和 -）", *versionFlag)
+	}
+	targets, err := parseTargets(*targetsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		log.Fatalf("创建输出目录失败: %v", err)
+	}
+
+	commit := gitCommit()
+	ldflags := fmt.Sprintf("-s -w -X bomberman/pkg/version.Game=%s -X bomberman/pkg/version.Commit=%s", *versionFlag, commit)
+
+	var archives []string
+	failed := 0
+	for _, t := range targets {
+		if t.goos == "linux" && runtime.GOOS != "linux" {
+			log.Printf("SKIP %s: linux 客户端需要 cgo，请在 linux 上打包", t)
+			continue
+		}
+		archive, err := packageTarget(t, *versionFlag, ldflags, *outDir)
+		if err != nil {
+			log.Printf("FAIL %s: %v", t, err)
+			failed++
+			continue
+		}
+		log.Printf("OK   %s -> %s", t, archive)
+		archives = append(archives, archive)
+	}
+
+	if err := writeChecksums(*outDir, archives); err != nil {
+		log.Fatalf("写入 SHA256SUMS 失败: %v", err)
+	}
+	if failed > 0 {
+		log.Fatalf("%d 个平台打包失败", failed)
+	}
+	log.Printf("版本 %s (%s) 打包完成: %d 个压缩包", *versionFlag, commit, len(archives))
+}
+
+// parseTargets 解析 系统/架构 列表
+func parseTargets(s string) ([]target, error) {
+	var targets []target
+	for _, item := range strings.Split(s, ",") {
+		goos, goarch, ok := strings.Cut(strings.TrimSpace(item), "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("无效的目标平台 %q（格式 系统/架构，例如 windows/amd64）", item)
+		}
+		targets = append(targets, target{goos: goos, goarch: goarch})
+	}
+	return targets, nil
+}
+
+// gitCommit 当前提交的短哈希（不在 git 仓库中时为 unknown）
+func gitCommit() string {
+	out, err := exec.Command("git", "rev-parse", "--short", "HEAD").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// packageTarget 编译一个平台的客户端并打包，返回压缩包路径
+func packageTarget(t target, version, ldflags, outDir string) (string, error) {
+	name := fmt.Sprintf("bomberman-%s-%s-%s", version, t.goos, t.goarch)
+	binary := "bomberman"
+	if t.goos == "windows" {
+		binary += ".exe"
+	}
+
+	buildDir, err := os.MkdirTemp("", "bomberman-package-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(buildDir)
+
+	binPath := filepath.Join(buildDir, binary)
+	cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", binPath, "./cmd/client")
+	cgo := "0"
+	if t.goos == "linux" {
+		cgo = "1"
+	}
+	cmd.Env = append(os.Environ(), "GOOS="+t.goos, "GOARCH="+t.goarch, "CGO_ENABLED="+cgo)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("编译失败: %w", err)
+	}
+
+	files := map[string]string{name + "/" + binary: binPath}
+	for _, f := range extraFiles {
+		files[name+"/"+filepath.Base(f)] = f
+	}
+
+	if t.goos == "windows" {
+		archive := filepath.Join(outDir, name+".zip")
+		return archive, writeZip(archive, files)
+	}
+	archive := filepath.Join(outDir, name+".tar.gz")
+	return archive, writeTarGz(archive, files, binary)
+}
+
+// sortedKeys 压缩包内文件按名称排序，保证同一输入生成相同的压缩包
+func sortedKeys(files map[string]string) []string {
+	keys := make([]string, 0, len(files))
+	for k := range files {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeZip(path string, files map[string]string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for _, name := range sortedKeys(files) {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			return err
+		}
+		if err := copyFile(w, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// writeTarGz 打包为 tar.gz，binary 保留可执行权限
+func writeTarGz(path string, files map[string]string, binary string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	gw := gzip.NewWriter(out)
+	tw := tar.NewWriter(gw)
+	for _, name := range sortedKeys(files) {
+		info, err := os.Stat(files[name])
+		if err != nil {
+			return err
+		}
+		mode := int64(0o644)
+		if filepath.Base(name) == binary {
+			mode = 0o755
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: info.Size(), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		if err := copyFile(tw, files[name]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+func copyFile(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeChecksums 写入 SHA256SUMS（sha256sum -c 格式）
+func writeChecksums(outDir string, archives []string) error {
+	var sb strings.Builder
+	for _, archive := range archives {
+		f, err := os.Open(archive)
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), filepath.Base(archive))
+	}
+	return os.WriteFile(filepath.Join(outDir, "SHA256SUMS"), []byte(sb.String()), 0o644)
+}
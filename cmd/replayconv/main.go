@@ -0,0 +1,72 @@
+// replayconv 将服务器广播录制文件（-record-dir 生成的 .rec.gz）转换为回放文件
+// 每局输出一个 <out>-<序号>.replay（序列化后的 Replay），可选输出 JSON 便于查看
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"bomberman/pkg/protocol"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func main() {
+	input := flag.String("in", "", "录制文件路径（.rec.gz）")
+	output := flag.String("out", "", "输出文件前缀（默认与录制文件同名）")
+	asJSON := flag.Bool("json", false, "输出 JSON 而不是二进制")
+	flag.Parse()
+
+	if *input == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	prefix := *output
+	if prefix == "" {
+		prefix = strings.TrimSuffix(strings.TrimSuffix(*input, ".gz"), ".rec")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("打开录制文件失败: %v", err)
+	}
+	defer file.Close()
+
+	reader, err := protocol.NewRecordingReader(file)
+	if err != nil {
+		log.Fatalf("读取录制文件失败: %v", err)
+	}
+	replays, err := protocol.BuildReplays(reader)
+	if err != nil {
+		// 已解析的对局仍然输出
+		log.Printf("录制文件解析中断: %v", err)
+	}
+	if len(replays) == 0 {
+		log.Fatalf("房间 %s 的录制中没有完整开局的对局", reader.RoomID)
+	}
+
+	ext := ".replay"
+	if *asJSON {
+		ext = ".replay.json"
+	}
+	for i, replay := range replays {
+		var data []byte
+		if *asJSON {
+			data, err = protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(replay)
+		} else {
+			data, err = proto.Marshal(replay)
+		}
+		if err != nil {
+			log.Fatalf("序列化回放失败: %v", err)
+		}
+		path := fmt.Sprintf("%s-%d%s", prefix, i+1, ext)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			log.Fatalf("写入回放失败: %v", err)
+		}
+		log.Printf("对局 %d: %d 帧 -> %s", i+1, len(replay.Frames), path)
+	}
+}
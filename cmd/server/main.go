@@ -2,23 +2,92 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"bomberman/internal/server"
+	"bomberman/pkg/ai"
+	"bomberman/pkg/version"
 )
 
 func main() {
 	// 命令行参数
 	address := flag.String("addr", ":8080", "服务器监听地址")
 	proto := flag.String("proto", "tcp", "服务器监听协议: tcp 或 kcp")
+	aiTree := flag.String("ai-tree", "", "AI 行为树 JSON 定义文件（加载或校验失败时使用内置行为树）")
+	dumpAITree := flag.Bool("dump-ai-tree", false, "输出内置 AI 行为树的 JSON 定义并退出（作为 -ai-tree 的编辑起点）")
+	wsAddr := flag.String("ws-addr", "", "WebSocket 监听地址（如 :8081，路径 /ws，空表示不开启）")
 	enableAI := flag.Bool("enable-ai", false, "是否启用 AI 玩家")
+	lobbyIdle := flag.Duration("lobby-idle", server.DefaultLobbyIdleTimeout, "大厅空闲断开时间（0 表示不限制）")
+	debugScenarios := flag.Bool("debug-scenarios", false, "开启调试场景 API（仅用于测试，不要在生产环境开启）")
+	debugAI := flag.Bool("debug-ai", false, "广播 AI 行为树节点与规划路径（调试 AI 用）")
+	recordDir := flag.String("record-dir", "", "录制每个房间的全部广播到该目录（用 replayconv 转换为回放，空表示不录制）")
+	admin := flag.Bool("admin", false, "从标准输入读取运维命令（观察房间、导出状态，输入 help 查看）")
+	maxRooms := flag.Int("max-rooms", server.MaxRooms, "房间数上限（<=0 表示不限制）")
+	offlineTimeout := flag.Duration("offline-timeout", server.OfflinePlayerTimeout, fmt.Sprintf("断线玩家的保留时间（受会话令牌有效期限制，最长 %v）", server.SessionReconnectWindow))
+	statsFile := flag.String("stats-file", "", "AI 与真人胜负统计的保存文件（空表示只在内存中统计，-admin 下输入 stats 查看）")
+	profilesFile := flag.String("profiles-file", "", "玩家偏好的保存文件，按客户端 -account 密钥保存角色、控制方案和房主的规则预设（空表示只保存在内存中）")
+	name := flag.String("name", server.DefaultServerName, "服务器名称（显示在客户端服务器列表中）")
+	motd := flag.String("motd", "", "服务器公告（显示在客户端服务器列表中）")
+	metricsAddr := flag.String("metrics-addr", "", "指标 HTTP 端点地址（如 :9100，路径 /metrics，Prometheus 文本格式；空表示不开启）")
+	reportsDir := flag.String("reports-dir", "", "玩家举报目录，保存举报及服务器对被举报玩家的输入和聊天记录，只收集客户端 -telemetry 同意的玩家（-admin 下输入 reports 查看，设置 REPORTS_TOKEN 时也可带令牌访问指标端点的 /reports；空表示不接受举报、不收集）")
+	syncCheck := flag.Bool("sync-check", false, "每个房间每 300 帧广播一次状态校验和，客户端比较后上报不一致（排查模拟漂移用）")
+	mapsDir := flag.String("maps-dir", "", "自定义地图目录，接受客户端地图编辑器上传的地图（空表示不接受上传）")
+	roomIdle := flag.Duration("room-idle", server.DefaultRoomIdleTimeout, "等待阶段无人准备或操作多久后解散房间（0 表示不限制）")
+	interestRadius := flag.Int("interest-radius", 0, "兴趣管理半径（格）：每名玩家只收到周围该范围内的玩家、炸弹和爆炸，减小状态包（0 表示发送全部实体）")
+	chaos := flag.String("chaos", "", "出站故障注入（仅用于测试），如 seed=7,delay=30ms,jitter=80ms,drop=0.05,dup=0.02,reorder=0.1：每个连接的出站包按参数随机延迟、重复、乱序或丢弃")
+	configPath := flag.String("config", "", "服务器配置文件（JSON），listeners 字段指定多个监听地址/协议，代替 -addr 和 -ws-addr")
 	flag.Parse()
+	if *dumpAITree {
+		dumpBuiltinAITree()
+		return
+	}
+	sources := applyEnv(flag.CommandLine)
+	logConfig(flag.CommandLine, sources)
 
 	// 创建服务器
 	gameServer := server.NewGameServer(*address, *proto, *enableAI)
+	gameServer.SetLobbyIdleTimeout(*lobbyIdle)
+	gameServer.SetDebugScenarios(*debugScenarios)
+	gameServer.SetDebugAI(*debugAI)
+	gameServer.SetRecordDir(*recordDir)
+	gameServer.SetMaxRooms(*maxRooms)
+	gameServer.SetOfflineTimeout(*offlineTimeout)
+	gameServer.SetRoomIdleTimeout(*roomIdle)
+	gameServer.SetStatsFile(*statsFile)
+	gameServer.SetProfilesFile(*profilesFile)
+	gameServer.SetServerName(*name)
+	gameServer.SetMOTD(*motd)
+	gameServer.SetWSAddr(*wsAddr)
+	gameServer.SetAITreeFile(*aiTree)
+	gameServer.SetMapsDir(*mapsDir)
+	gameServer.SetReportsDir(*reportsDir)
+	gameServer.SetReportsToken(os.Getenv("REPORTS_TOKEN"))
+	gameServer.SetMetricsAddr(*metricsAddr)
+	gameServer.SetSyncCheck(*syncCheck)
+	gameServer.SetInterestRadius(*interestRadius)
+	if *chaos != "" {
+		profile, err := server.ParseChaosProfile(*chaos)
+		if err != nil {
+			log.Fatalf("无效的故障注入参数: %v", err)
+		}
+		log.Printf("警告: 已开启出站故障注入 (%s)，仅用于测试", profile)
+		gameServer.SetChaos(&profile)
+	}
+
+	var listeners []server.ListenerConfig
+	if *configPath != "" {
+		cfg, err := server.LoadServerConfig(*configPath)
+		if err != nil {
+			log.Fatalf("读取服务器配置失败: %v", err)
+		}
+		listeners = cfg.Listeners
+		gameServer.SetListeners(listeners)
+	}
 
 	// 启动服务器（在新的 goroutine 中）
 	go func() {
@@ -30,14 +99,26 @@ func main() {
 	log.Println("========================================")
 	log.Println("  Bomberman 联机服务器")
 	log.Println("========================================")
-	log.Printf("监听协议: %s", *proto)
-	log.Printf("监听地址: %s", *address)
+	log.Printf("服务器: %s (%s)", *name, version.String())
+	if len(listeners) > 0 {
+		for _, l := range listeners {
+			log.Printf("监听器 %s: %s %s", l.Name, l.Proto, l.Addr)
+		}
+	} else {
+		log.Printf("监听协议: %s", *proto)
+		log.Printf("监听地址: %s", *address)
+	}
 	log.Printf("最大玩家数: %d", server.MaxPlayers)
 	log.Printf("服务器 TPS: %d", server.ServerTPS)
 	log.Println("========================================")
 	log.Println("服务器正在运行...")
 	log.Println("按 Ctrl+C 停止服务器")
 
+	if *admin {
+		log.Println("运维控制台已开启，输入 help 查看命令")
+		go server.NewAdminConsole(gameServer, os.Stdout).Run(os.Stdin)
+	}
+
 	// 等待中断信号
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -48,3 +129,65 @@ func main() {
 
 	log.Println("服务器已关闭，再见！")
 }
+
+// envPrefix 环境变量前缀：每个命令行参数都可以用 BOMBMAN_<参数名> 配置，
+// 参数名转大写、'-' 换成 '_'（例如 -lobby-idle 对应 BOMBMAN_LOBBY_IDLE）。
+// 优先级：命令行参数 > 环境变量 > 默认值
+const envPrefix = "BOMBMAN_"
+
+// envName 参数对应的环境变量名
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv 用环境变量填充命令行未指定的参数（需在 flag.Parse 之后调用）
+// 返回每个参数的来源：flag / env / default
+func applyEnv(fs *flag.FlagSet) map[string]string {
+	sources := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		sources[f.Name] = "flag"
+	})
+
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, ok := sources[f.Name]; ok {
+			return
+		}
+		sources[f.Name] = "default"
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, value); err != nil {
+			log.Fatalf("环境变量 %s 的值无效: %v", envName(f.Name), err)
+		}
+		sources[f.Name] = "env"
+	})
+	return sources
+}
+
+// logConfig 打印最终生效的配置（密钥只显示是否设置）
+func logConfig(fs *flag.FlagSet, sources map[string]string) {
+	log.Println("生效配置:")
+	fs.VisitAll(func(f *flag.Flag) {
+		log.Printf("  %-16s = %-12q (%s, %s)", f.Name, f.Value.String(), sources[f.Name], envName(f.Name))
+	})
+	if os.Getenv("JWT_SECRET") != "" {
+		log.Printf("  %-16s = %-12q (env, JWT_SECRET)", "jwt-secret", "******")
+	} else {
+		log.Printf("  %-16s = 未设置，使用开发默认密钥（生产环境请设置 JWT_SECRET）", "jwt-secret")
+	}
+	if os.Getenv("REPORTS_TOKEN") != "" {
+		log.Printf("  %-16s = %-12q (env, REPORTS_TOKEN)", "reports-token", "******")
+	} else {
+		log.Printf("  %-16s = 未设置，指标端点不提供 /reports", "reports-token")
+	}
+}
+
+// dumpBuiltinAITree 把内置 AI 行为树的 JSON 定义输出到标准输出
+func dumpBuiltinAITree() {
+	data, err := ai.MarshalTreeDef(ai.DefaultTreeDef())
+	if err != nil {
+		log.Fatalf("导出 AI 行为树失败: %v", err)
+	}
+	os.Stdout.Write(append(data, '\n'))
+}
@@ -0,0 +1,230 @@
+// simulate 无头自对弈：在确定性核心上用 AI（或外部策略）跑完整对局，可导出训练数据
+//
+//	simulate -games 100 -players 4 -difficulty hard -export data.jsonl
+//	simulate -games 10 -policy "python3 policy.py" -export eval.jsonl
+//
+// 导出文件为 JSONL，每行一个 selfplay.Record：
+//
+//	{"game":0,"seed":1,"map":"default",
+//	 "obs":{"frame":0,"player":1,"alive":true,"grid_x":0,"grid_y":0,...,"view":[...],"danger":[...],"enemies":[...]},
+//	 "action":{"right":true,"bomb":true},
+//	 "outcome":{"result":"win","reward":1,"death_frame":0,"end_frame":3120}}
+//
+// obs 是该帧动作生效前的状态特征（字段含义见 pkg/selfplay/observation.go），action 是玩家在该帧选择的输入，
+// outcome 是该玩家在这一局的最终结果（对局结束后回填）。-every N 每 N 帧采样一次
+//
+// -policy 启动一个外部进程控制 1 号玩家：每帧向它的 stdin 写一行 obs JSON，从 stdout 读一行 action JSON
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"bomberman/pkg/ai"
+	"bomberman/pkg/core"
+	"bomberman/pkg/selfplay"
+)
+
+// simulation 一次运行的参数
+type simulation struct {
+	mapID      string
+	def        *core.MapDefinition
+	players    int
+	difficulty ai.Difficulty
+	maxFrames  int32
+	every      int32
+	policy     *selfplay.ExternalPolicy
+
+	observer *selfplay.Observer
+	writer   *selfplay.Writer
+}
+
+// result 一局的结果
+type result struct {
+	winnerID   int
+	winnerTeam int
+	frames     int32
+}
+
+func main() {
+	games := flag.Int("games", 1, "对局数")
+	seed := flag.Int64("seed", 1, "第一局的种子（之后每局加 1）")
+	mapID := flag.String("map", core.MapLayoutDefault, "内置地图 ID 或 JSON 地图文件路径")
+	players := flag.Int("players", 4, "玩家数（不能超过地图的出生点数）")
+	difficulty := flag.String("difficulty", "normal", "AI 难度：easy、normal、hard")
+	maxFrames := flag.Int("max-frames", 0, "每局最多运行的帧数（0 表示直到限时结束）")
+	export := flag.String("export", "", "导出训练数据的 JSONL 文件（为空时只统计胜负）")
+	every := flag.Int("every", 1, "每隔多少帧导出一次")
+	policy := flag.String("policy", "", "控制 1 号玩家的外部策略命令（stdin/stdout 每帧一行 JSON）")
+	flag.Parse()
+
+	if *games <= 0 || *players < 2 || *every <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	def, err := loadMap(*mapID)
+	if err != nil {
+		log.Fatalf("加载地图失败: %v", err)
+	}
+	if !def.FitsPlayers(*players) {
+		log.Fatalf("地图 %s 只有 %d 个出生点，不能容纳 %d 名玩家", *mapID, len(def.Spawns), *players)
+	}
+	level, ok := parseDifficulty(*difficulty)
+	if !ok {
+		log.Fatalf("未知的 AI 难度 %q", *difficulty)
+	}
+
+	sim := &simulation{
+		mapID:      *mapID,
+		def:        def,
+		players:    *players,
+		difficulty: level,
+		maxFrames:  int32(*maxFrames),
+		every:      int32(*every),
+		observer:   selfplay.NewObserver(),
+	}
+	if *export != "" {
+		file, err := os.Create(*export)
+		if err != nil {
+			log.Fatalf("创建导出文件失败: %v", err)
+		}
+		defer file.Close()
+		sim.writer = selfplay.NewWriter(file)
+	}
+	if *policy != "" {
+		fields := strings.Fields(*policy)
+		sim.policy, err = selfplay.StartExternalPolicy(1, fields[0], fields[1:]...)
+		if err != nil {
+			log.Fatalf("启动外部策略失败: %v", err)
+		}
+		defer sim.policy.Close()
+	}
+
+	wins := make(map[int]int)
+	draws := 0
+	var frames int64
+	for i := 0; i < *games; i++ {
+		res, err := sim.run(i, *seed+int64(i))
+		if err != nil {
+			log.Fatalf("第 %d 局失败: %v", i, err)
+		}
+		frames += int64(res.frames)
+		if res.winnerID >= 0 {
+			wins[res.winnerID]++
+		} else {
+			draws++
+		}
+	}
+
+	fmt.Printf("games=%d avg_frames=%d draws=%d", *games, frames/int64(*games), draws)
+	for id := 1; id <= *players; id++ {
+		fmt.Printf(" p%d=%d", id, wins[id])
+	}
+	fmt.Println()
+	if sim.writer != nil {
+		fmt.Printf("exported %d records to %s\n", sim.writer.Count(), *export)
+	}
+}
+
+// loadMap 内置地图 ID 或地图文件
+func loadMap(id string) (*core.MapDefinition, error) {
+	if def := core.BuiltinMap(id); def != nil {
+		return def, nil
+	}
+	return core.LoadMapDefinition(id)
+}
+
+func parseDifficulty(name string) (ai.Difficulty, bool) {
+	for _, d := range []ai.Difficulty{ai.DifficultyEasy, ai.DifficultyNormal, ai.DifficultyHard} {
+		if strings.EqualFold(d.String(), name) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// run 跑完一局：与服务器相同的帧顺序（先应用输入，再 Update），限时结束或达到帧数上限时判为平局
+func (s *simulation) run(index int, seed int64) (result, error) {
+	game := core.NewGame(seed)
+	game.Map = core.NewGameMapFromDefinition(s.def, seed)
+	if game.Config.MatchDurationFrames > 0 {
+		game.MatchEndFrame = game.Config.MatchDurationFrames
+	}
+
+	controllers := make(map[int]selfplay.Controller, s.players)
+	for id := 1; id <= s.players; id++ {
+		cell := s.def.SpawnCell(id)
+		x, y := core.GridToPlayerXY(cell.X, cell.Y)
+		game.AddPlayer(core.NewPlayer(id, x, y, core.CharacterType((id-1)%4)))
+		controllers[id] = ai.NewAIController(id, s.difficulty)
+	}
+	if s.policy != nil {
+		controllers[s.policy.PlayerID] = s.policy
+	}
+
+	deathFrames := make(map[int]int32)
+	for !game.IsGameOver() {
+		if game.MatchEndFrame > 0 && game.CurrentFrame >= game.MatchEndFrame {
+			break
+		}
+		if s.maxFrames > 0 && game.CurrentFrame >= s.maxFrames {
+			break
+		}
+
+		// 先记录所有玩家在本帧输入生效前的特征，再依次决策并应用输入
+		var observations map[int]*selfplay.Observation
+		if s.writer != nil && game.CurrentFrame%s.every == 0 {
+			observations = make(map[int]*selfplay.Observation, len(game.Players))
+			for _, p := range game.Players {
+				if !p.Dead {
+					observations[p.ID] = s.observer.Observe(game, p.ID)
+				}
+			}
+		}
+		for _, p := range game.Players {
+			if p.Dead {
+				continue
+			}
+			input := controllers[p.ID].Decide(game)
+			if obs := observations[p.ID]; obs != nil {
+				s.writer.Add(&selfplay.Record{
+					Game:   index,
+					Seed:   seed,
+					Map:    s.mapID,
+					Obs:    obs,
+					Action: selfplay.ActionFromInput(input),
+				})
+			}
+			core.ApplyInput(game, p.ID, input, game.CurrentFrame)
+		}
+		if s.policy != nil && s.policy.Err() != nil {
+			return result{}, s.policy.Err()
+		}
+
+		game.Update()
+		for _, p := range game.Players {
+			if _, ok := deathFrames[p.ID]; p.Dead && !ok {
+				deathFrames[p.ID] = game.CurrentFrame
+			}
+		}
+	}
+
+	res := result{winnerID: -1, winnerTeam: core.TeamNone, frames: game.CurrentFrame}
+	if game.IsGameOver() {
+		if game.Rules.Teams {
+			res.winnerTeam = game.WinningTeam()
+		}
+		if alive := game.GetAlivePlayers(); len(alive) == 1 {
+			res.winnerID = alive[0].ID
+		}
+	}
+	if s.writer != nil {
+		if err := s.writer.FinishGame(selfplay.Outcomes(game, res.winnerID, res.winnerTeam, deathFrames)); err != nil {
+			return result{}, fmt.Errorf("写入导出文件失败: %w", err)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"image/color"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// aiDebugColors 每个 AI 一种颜色，便于区分路径
+var aiDebugColors = []color.RGBA{
+	{255, 220, 60, 220},
+	{80, 220, 255, 220},
+	{255, 120, 220, 220},
+	{140, 255, 120, 220},
+}
+
+// AIDebugOverlay AI 调试叠加层：绘制规划路径、目标格子和当前行为树节点
+// 只有服务器以 -debug-ai 启动时才会收到数据，F3 切换显示
+type AIDebugOverlay struct {
+	state  *gamev1.DebugAIState
+	hidden bool
+	keys   keyTracker
+}
+
+// Update 接收最新的调试信息并处理显示开关
+func (o *AIDebugOverlay) Update(network *NetworkClient) {
+	for {
+		state := network.ReceiveDebugAIState()
+		if state == nil {
+			break
+		}
+		o.state = state
+	}
+	if o.keys.JustPressed(ebiten.KeyF3) {
+		o.hidden = !o.hidden
+	}
+}
+
+// Draw 绘制叠加层
+func (o *AIDebugOverlay) Draw(screen *ebiten.Image, game *core.Game) {
+	if o.hidden || o.state == nil {
+		return
+	}
+
+	for i, info := range o.state.Ais {
+		player := game.GetPlayer(int(info.PlayerId))
+		if player == nil || player.Dead {
+			continue
+		}
+		clr := aiDebugColors[i%len(aiDebugColors)]
+
+		// 路径：从玩家中心依次连到每个格子中心
+		prevX := float32(player.X) + float32(player.Width)/2
+		prevY := float32(player.Y) + float32(player.Height)/2
+		for _, cell := range info.Path {
+			cx, cy := cellCenter(cell)
+			vector.StrokeLine(screen, prevX, prevY, cx, cy, 2, clr, true)
+			vector.DrawFilledRect(screen, cx-2, cy-2, 4, 4, clr, false)
+			prevX, prevY = cx, cy
+		}
+
+		// 目标格子
+		if info.HasTarget && info.Target != nil {
+			x := float32(info.Target.X * core.TileSize)
+			y := float32(info.Target.Y * core.TileSize)
+			vector.StrokeRect(screen, x+1, y+1, core.TileSize-2, core.TileSize-2, 2, clr, false)
+		}
+
+		// 节点名称
+		if info.Node != "" {
+			drawText(screen, int(player.X), int(player.Y)-14, info.Node, clr)
+		}
+	}
+}
+
+func cellCenter(cell *gamev1.GridCell) (float32, float32) {
+	return float32(cell.X*core.TileSize + core.TileSize/2), float32(cell.Y*core.TileSize + core.TileSize/2)
+}
@@ -0,0 +1,295 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// 音效与背景音乐：没有音频素材，启动时按参数合成 16 位立体声 PCM。
+// 音效由 soundCues（sound_cues.go）根据每帧的游戏状态变化触发，单机与联机共用；大厅与房间界面播放循环背景音乐。
+// 任何界面下 F4 静音、F5/F6 调节音量，设置随客户端配置保存（-volume、-mute）
+
+// Sound 音效
+type Sound int
+
+const (
+	SoundBombPlace    Sound = iota // 放置炸弹
+	SoundExplosion                 // 爆炸
+	SoundDeath                     // 玩家阵亡
+	SoundPowerUp                   // 拾取道具
+	SoundCountdown                 // 倒计时（最后几秒每秒一次）
+	SoundCountdownEnd              // 倒计时结束
+	soundCount
+)
+
+const (
+	audioSampleRate  = 44100
+	soundMinInterval = 50 * time.Millisecond // 同一音效的最短间隔（同一帧多个爆炸只播一次）
+	musicGain        = 0.4                   // 背景音乐相对音效的音量
+	volumeStep       = 10
+	volumeShownFor   = 1500 * time.Millisecond
+
+	muteKey       = ebiten.KeyF4
+	volumeDownKey = ebiten.KeyF5
+	volumeUpKey   = ebiten.KeyF6
+)
+
+// audioManager 音频输出（只在游戏循环内访问）
+type audioManager struct {
+	ctx        *audio.Context
+	sounds     [soundCount][]byte
+	music      *audio.Player
+	musicOn    bool
+	volume     int // 0-100
+	muted      bool
+	lastPlayed [soundCount]time.Time
+}
+
+// audioOut 当前的音频输出，InitAudio 之前为 nil（此时所有音效调用都不做任何事）
+var audioOut *audioManager
+
+// InitAudio 创建音频输出并合成音效，volume 为 0-100
+func InitAudio(volume int, muted bool) {
+	m := &audioManager{
+		ctx:    audio.NewContext(audioSampleRate),
+		volume: clampVolume(volume),
+		muted:  muted,
+	}
+	m.sounds = [soundCount][]byte{
+		SoundBombPlace:    pcm(tone(90*time.Millisecond, 220, 110, sine, 6)),
+		SoundExplosion:    pcm(mix(noiseBurst(600*time.Millisecond, 5, 0.08), tone(400*time.Millisecond, 70, 40, sine, 5))),
+		SoundDeath:        pcm(gain(tone(450*time.Millisecond, 700, 120, square, 2.5), 0.6)),
+		SoundPowerUp:      pcm(gain(join(tone(60*time.Millisecond, 523, 523, square, 1), tone(60*time.Millisecond, 659, 659, square, 1), tone(120*time.Millisecond, 784, 1047, square, 2)), 0.5)),
+		SoundCountdown:    pcm(gain(tone(120*time.Millisecond, 880, 880, square, 3), 0.5)),
+		SoundCountdownEnd: pcm(gain(tone(350*time.Millisecond, 1320, 1320, square, 2), 0.5)),
+	}
+	data := pcm(lobbyMusic())
+	music, err := m.ctx.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(data), int64(len(data))))
+	if err != nil {
+		log.Printf("创建背景音乐失败: %v", err)
+	} else {
+		m.music = music
+	}
+	audioOut = m
+	m.apply()
+}
+
+// AudioSettings 当前音量与静音状态（退出时保存到配置）
+func AudioSettings() (volume int, muted bool) {
+	if audioOut == nil {
+		return 0, false
+	}
+	return audioOut.volume, audioOut.muted
+}
+
+func clampVolume(volume int) int {
+	return max(0, min(100, volume))
+}
+
+// playSound 以当前音量播放一次音效
+func playSound(s Sound) {
+	playSoundScaled(s, 1)
+}
+
+// playSoundScaled 按 scale（0~1）缩放音量播放一次音效（远处的爆炸更轻，见 explosion_falloff.go）
+func playSoundScaled(s Sound, scale float64) {
+	m := audioOut
+	if m == nil || m.muted || m.volume == 0 {
+		return
+	}
+	now := time.Now()
+	if now.Sub(m.lastPlayed[s]) < soundMinInterval {
+		return
+	}
+	m.lastPlayed[s] = now
+	player := m.ctx.NewPlayerFromBytes(m.sounds[s])
+	player.SetVolume(m.gain() * scale)
+	player.Play()
+}
+
+// setLobbyMusic 开始或暂停背景音乐（每帧调用，状态不变时不做任何事）
+func setLobbyMusic(on bool) {
+	m := audioOut
+	if m == nil || m.musicOn == on {
+		return
+	}
+	m.musicOn = on
+	m.apply()
+}
+
+func (m *audioManager) gain() float64 {
+	return float64(m.volume) / 100
+}
+
+// apply 按音量、静音和当前界面更新背景音乐
+func (m *audioManager) apply() {
+	if m.music == nil {
+		return
+	}
+	m.music.SetVolume(m.gain() * musicGain)
+	if m.musicOn && !m.muted && m.volume > 0 {
+		if !m.music.IsPlaying() {
+			m.music.Play()
+		}
+	} else if m.music.IsPlaying() {
+		m.music.Pause()
+	}
+}
+
+// AudioControls 包装 ebiten.Game，在任何界面下处理静音与音量按键，并短暂显示当前音量
+type AudioControls struct {
+	ebiten.Game
+
+	keys       keyTracker
+	shownUntil time.Time
+}
+
+// NewAudioControls 创建音量按键包装（需先调用 InitAudio）
+func NewAudioControls(game ebiten.Game) *AudioControls {
+	return &AudioControls{Game: game}
+}
+
+func (a *AudioControls) Update() error {
+	if m := audioOut; m != nil {
+		changed := true
+		switch {
+		case a.keys.JustPressed(muteKey):
+			m.muted = !m.muted
+		case a.keys.JustPressed(volumeDownKey):
+			m.volume = clampVolume(m.volume - volumeStep)
+		case a.keys.JustPressed(volumeUpKey):
+			m.volume = clampVolume(m.volume + volumeStep)
+		default:
+			changed = false
+		}
+		if changed {
+			m.apply()
+			a.shownUntil = time.Now().Add(volumeShownFor)
+		}
+	}
+	return a.Game.Update()
+}
+
+func (a *AudioControls) Draw(screen *ebiten.Image) {
+	a.Game.Draw(screen)
+	if audioOut == nil || time.Now().After(a.shownUntil) {
+		return
+	}
+	label := fmt.Sprintf("Volume %d%%  (F4 mute, F5/F6 -/+)", audioOut.volume)
+	if audioOut.muted {
+		label = "Sound muted  (F4 unmute)"
+	}
+	drawCenteredText(screen, label, ScreenWidth/2, ScreenHeight-30, color.RGBA{230, 230, 230, 255})
+}
+
+// ========== 合成 ==========
+
+// pcm 把 [-1, 1] 的单声道采样转换为 16 位小端立体声
+func pcm(samples []float64) []byte {
+	out := make([]byte, len(samples)*4)
+	for i, s := range samples {
+		v := uint16(int16(max(-1, min(1, s)) * math.MaxInt16))
+		binary.LittleEndian.PutUint16(out[i*4:], v)
+		binary.LittleEndian.PutUint16(out[i*4+2:], v)
+	}
+	return out
+}
+
+func sine(phase float64) float64 {
+	return math.Sin(2 * math.Pi * phase)
+}
+
+func square(phase float64) float64 {
+	if math.Mod(phase, 1) < 0.5 {
+		return 0.5
+	}
+	return -0.5
+}
+
+func triangle(phase float64) float64 {
+	return 4*math.Abs(math.Mod(phase, 1)-0.5) - 1
+}
+
+// tone 频率从 from 线性滑到 to 的一个音，按 decay 指数衰减（开头 5ms 淡入避免爆音）
+func tone(d time.Duration, from, to float64, wave func(float64) float64, decay float64) []float64 {
+	n := int(d.Seconds() * audioSampleRate)
+	out := make([]float64, n)
+	phase := 0.0
+	for i := range out {
+		t := float64(i) / float64(n)
+		phase += (from + (to-from)*t) / audioSampleRate
+		attack := min(1, float64(i)/(0.005*audioSampleRate))
+		out[i] = wave(phase) * math.Exp(-decay*t) * attack
+	}
+	return out
+}
+
+// noiseBurst 低通后的白噪声（smooth 越小越沉闷），按 decay 指数衰减。种子固定，每次启动音色相同
+func noiseBurst(d time.Duration, decay, smooth float64) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	n := int(d.Seconds() * audioSampleRate)
+	out := make([]float64, n)
+	level := 0.0
+	for i := range out {
+		level += (rng.Float64()*2 - 1 - level) * smooth
+		out[i] = level * 3 * math.Exp(-decay*float64(i)/float64(n))
+	}
+	return out
+}
+
+// mix 叠加两段采样（长度取较长者）
+func mix(a, b []float64) []float64 {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	out := append([]float64(nil), a...)
+	for i, s := range b {
+		out[i] += s
+	}
+	return out
+}
+
+// join 依次拼接
+func join(parts ...[]float64) []float64 {
+	var out []float64
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func gain(samples []float64, g float64) []float64 {
+	for i := range samples {
+		samples[i] *= g
+	}
+	return samples
+}
+
+// lobbyMusic 大厅背景音乐：Am-F-C-G 四小节的和弦分解（低音方波 + 三角波旋律），约 8 秒循环
+func lobbyMusic() []float64 {
+	const step = 250 * time.Millisecond // 八分音符（120 BPM）
+	// 和弦音相对 A3 的半音
+	chords := [][3]float64{{0, 3, 7}, {-4, 0, 3}, {3, 7, 10}, {-2, 2, 5}}
+	pattern := []int{0, 1, 2, 1, 0, 2, 1, 2}
+	note := func(semitones float64) float64 {
+		return 220 * math.Pow(2, semitones/12)
+	}
+	var out []float64
+	for _, chord := range chords {
+		for _, idx := range pattern {
+			melody := gain(tone(step, note(chord[idx]+12), note(chord[idx]+12), triangle, 3), 0.25)
+			bass := gain(tone(step, note(chord[0]-12), note(chord[0]-12), square, 1), 0.15)
+			out = append(out, mix(melody, bass)...)
+		}
+	}
+	return out
+}
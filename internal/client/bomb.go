@@ -22,20 +22,23 @@ func NewBombRenderer(bomb *core.Bomb) *BombRenderer {
 // Draw 绘制炸弹
 func (b *BombRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 	bomb := b.Bomb
-	// 格子坐标转像素坐标
+	theme := ActiveTheme()
+	// 格子坐标转像素坐标（滑动中的炸弹带偏移）
 	centerOffset := float32(core.TileSize) / 2
-	cx := float32(bomb.GridX*core.TileSize) + centerOffset
-	cy := float32(bomb.GridY*core.TileSize) + centerOffset
+	x, y := bomb.PixelPosition()
+	cx := float32(x) + centerOffset
+	cy := float32(y) + centerOffset
 
 	// 计算闪烁效果（使用帧）
 	elapsedFrames := int(bomb.ExplodeAtFrame - currentFrame)
-	elapsedFrames = core.BombFuseFrames - elapsedFrames
+	fuseFrames := int(bomb.FuseFrames())
+	elapsedFrames = fuseFrames - elapsedFrames
 	if elapsedFrames < 0 {
 		elapsedFrames = 0
 	}
 	ratio := 0.0
-	if core.BombFuseFrames > 0 {
-		ratio = float64(elapsedFrames) / float64(core.BombFuseFrames)
+	if fuseFrames > 0 {
+		ratio = float64(elapsedFrames) / float64(fuseFrames)
 	}
 	if ratio > 1 {
 		ratio = 1
@@ -48,30 +51,35 @@ func (b *BombRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 	blink := math.Sin(float64(elapsedFrames) * 0.1) // 快速闪烁
 	alpha := uint8(200 + 55*blink)
 
-	// 炸弹主体（黑色）
-	bombColor := color.RGBA{0, 0, 0, alpha}
-	vector.FillCircle(screen, cx, cy, radius, bombColor, false)
-
-	// 炸弹轮廓
-	vector.StrokeCircle(screen, cx, cy, radius, 2,
-		color.RGBA{50, 50, 50, 255}, false)
-
-	// 引线（根据时间变短）
-	fuseLength := float32(15 * (1 - ratio))
-	if fuseLength > 0 {
-		fuseX := cx - radius*0.5
-		fuseY := cy - radius
-
-		// 引线（棕色）
-		vector.StrokeLine(screen, fuseX, fuseY, fuseX-fuseLength*0.5, fuseY-fuseLength,
-			2, color.RGBA{139, 69, 19, 255}, false)
-
-		// 引线火花（红色，闪烁）
-		if blink > 0 {
-			sparkX := fuseX - fuseLength*0.5
-			sparkY := fuseY - fuseLength
-			sparkColor := color.RGBA{255, uint8(100 + 155*blink), 0, 255}
-			vector.DrawFilledCircle(screen, sparkX, sparkY, 3, sparkColor, false)
+	if sheet := sprites.bomb; sheet != nil {
+		// 精灵图按引线燃烧进度选帧
+		frame := sheet.frame(int(ratio*float64(sheet.cols)), 0)
+		drawSprite(screen, frame, x, y, 1, nil, float32(alpha)/255)
+	} else {
+		// 炸弹主体
+		vector.FillCircle(screen, cx, cy, radius, withAlpha(theme.BombBody, alpha), false)
+
+		// 炸弹轮廓
+		vector.StrokeCircle(screen, cx, cy, radius, 2,
+			theme.BombOutline, false)
+
+		// 引线（根据时间变短）
+		fuseLength := float32(15 * (1 - ratio))
+		if fuseLength > 0 {
+			fuseX := cx - radius*0.5
+			fuseY := cy - radius
+
+			// 引线
+			vector.StrokeLine(screen, fuseX, fuseY, fuseX-fuseLength*0.5, fuseY-fuseLength,
+				2, theme.Fuse, false)
+
+			// 引线火花（闪烁）
+			if blink > 0 {
+				sparkX := fuseX - fuseLength*0.5
+				sparkY := fuseY - fuseLength
+				sparkColor := lerpColor(theme.SparkDim, theme.SparkBright, blink)
+				vector.DrawFilledCircle(screen, sparkX, sparkY, 3, sparkColor, false)
+			}
 		}
 	}
 
@@ -80,23 +88,25 @@ func (b *BombRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 		warningAlpha := uint8((ratio - 0.7) / 0.3 * 100)
 		warningRadius := radius + float32(10*(ratio-0.7)/0.3)
 		vector.StrokeCircle(screen, cx, cy, warningRadius, 2,
-			color.RGBA{255, 0, 0, warningAlpha}, false)
+			withAlpha(theme.BombWarning, warningAlpha), false)
 	}
 }
 
 // ExplosionRenderer 爆炸渲染器
 type ExplosionRenderer struct {
 	Explosion *core.Explosion
+	Intensity float64 // 强度系数（0~1，随与本地玩家的距离衰减），缩放整体透明度
 }
 
 // NewExplosionRenderer 创建爆炸渲染器
 func NewExplosionRenderer(explosion *core.Explosion) *ExplosionRenderer {
-	return &ExplosionRenderer{Explosion: explosion}
+	return &ExplosionRenderer{Explosion: explosion, Intensity: 1}
 }
 
 // Draw 绘制爆炸效果
 func (e *ExplosionRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 	explosion := e.Explosion
+	theme := ActiveTheme()
 	ratio := 0.0
 	totalFrames := int(explosion.ExpiresAtFrame - explosion.CreatedAtFrame)
 	if totalFrames > 0 {
@@ -107,8 +117,8 @@ func (e *ExplosionRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 		ratio = 1
 	}
 
-	// 爆炸逐渐消失
-	alpha := uint8(255 * (1 - ratio))
+	// 爆炸逐渐消失，远处的爆炸整体更淡
+	alpha := uint8(255 * (1 - ratio) * e.Intensity)
 
 	for _, cell := range explosion.Cells {
 		px := float32(cell.GridX * core.TileSize)
@@ -118,17 +128,21 @@ func (e *ExplosionRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 		scale := float32(0.3 + 0.7*math.Min(ratio*2, 1.0))
 		offset := float32(core.TileSize) * (1 - scale) / 2
 
-		// 火焰效果：黄色到红色渐变
+		// 火焰效果：初期、中期、后期三段渐变（经典主题为黄→橙→红）
 		var explosionColor color.RGBA
 		if ratio < 0.3 {
-			// 初期：亮黄色
-			explosionColor = color.RGBA{255, 255, 0, alpha}
+			explosionColor = withAlpha(theme.ExplosionEarly, alpha)
 		} else if ratio < 0.6 {
-			// 中期：橙色
-			explosionColor = color.RGBA{255, 165, 0, alpha}
+			explosionColor = withAlpha(theme.ExplosionMid, alpha)
 		} else {
-			// 后期：红色
-			explosionColor = color.RGBA{255, 0, 0, alpha}
+			explosionColor = withAlpha(theme.ExplosionLate, alpha)
+		}
+
+		if sheet := sprites.explosion; sheet != nil {
+			// 灰度火焰按阶段颜色着色
+			frame := sheet.frame(int(ratio*float64(sheet.cols)), 0)
+			drawSprite(screen, frame, float64(px), float64(py), float64(scale), explosionColor, 1)
+			continue
 		}
 
 		// 绘制爆炸主体
@@ -136,19 +150,19 @@ func (e *ExplosionRenderer) Draw(screen *ebiten.Image, currentFrame int32) {
 			float32(core.TileSize)*scale, float32(core.TileSize)*scale,
 			explosionColor, false)
 
-		// 添加内部高亮（白色中心）
+		// 添加内部高亮
 		if ratio < 0.5 {
-			innerAlpha := uint8(200 * (1 - ratio*2))
+			innerAlpha := uint8(200 * (1 - ratio*2) * e.Intensity)
 			innerScale := scale * 0.6
 			innerOffset := float32(core.TileSize) * (1 - innerScale) / 2
 			vector.DrawFilledRect(screen, px+innerOffset, py+innerOffset,
 				float32(core.TileSize)*innerScale, float32(core.TileSize)*innerScale,
-				color.RGBA{255, 255, 255, innerAlpha}, false)
+				withAlpha(theme.ExplosionCore, innerAlpha), false)
 		}
 
 		// 爆炸边缘效果
 		vector.StrokeRect(screen, px+offset, py+offset,
 			float32(core.TileSize)*scale, float32(core.TileSize)*scale,
-			2, color.RGBA{255, 100, 0, alpha}, false)
+			2, withAlpha(theme.ExplosionEdge, alpha), false)
 	}
 }
@@ -0,0 +1,23 @@
+package client
+
+// bombDeadReckoningMaxFrames 没有新快照时最多推算的帧数（超过后停在原地等服务器纠正）
+const bombDeadReckoningMaxFrames = 12
+
+// extrapolateBombs 本帧没有收到快照时，按速度把滑动中的炸弹向前推算一帧
+// 停止规则与服务器相同（core.Bomb.StepSlide），下一次快照到达时直接覆盖
+func (ngc *NetworkGameClient) extrapolateBombs() {
+	if ngc.bombExtrapolatedFrames >= bombDeadReckoningMaxFrames {
+		return
+	}
+	game := ngc.game.coreGame
+	moved := false
+	for _, bomb := range game.Bombs {
+		if bomb.Sliding() {
+			bomb.StepSlide(game)
+			moved = true
+		}
+	}
+	if moved {
+		ngc.bombExtrapolatedFrames++
+	}
+}
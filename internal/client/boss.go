@@ -0,0 +1,105 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 首领战：首领本体画成 2x2 格的方块（受伤无敌期间闪烁），蓄力喷火时闪烁提示火焰覆盖的格子，
+// 顶部血条按阶段分段
+
+// 血条位置和尺寸（左上角，避开中间的倒计时和右侧的击杀播报）
+const (
+	bossBarX      = 8
+	bossBarY      = 10
+	bossBarWidth  = 160
+	bossBarHeight = 8
+)
+
+var (
+	bossBodyColors = [core.BossStages]color.RGBA{
+		{120, 40, 160, 255},
+		{180, 50, 90, 255},
+		{220, 60, 30, 255},
+	}
+	bossEyeColor       = color.RGBA{255, 240, 120, 255}
+	bossChargeColor    = color.RGBA{255, 255, 255, 200}
+	bossFireWarnColor  = color.RGBA{255, 120, 20, 110}
+	bossBarBackColor   = color.RGBA{40, 40, 40, 220}
+	bossBarFillColor   = color.RGBA{220, 40, 40, 255}
+	bossBarBorderColor = color.RGBA{230, 230, 230, 255}
+)
+
+// drawBoss 绘制首领及蓄力中的喷火预警（世界坐标）
+func (g *Game) drawBoss(screen *ebiten.Image) {
+	b := g.coreGame.Boss
+	if b == nil || b.Defeated() {
+		return
+	}
+	frame := g.coreGame.CurrentFrame
+	charging := b.Attack != core.BossAttackNone
+
+	if charging && b.Attack == core.BossAttackFire && (frame/hazardFlashFrames)%2 == 0 {
+		for _, cell := range g.coreGame.BossFireCells(b.Direction) {
+			x := float32(cell.GridX * core.TileSize)
+			y := float32(cell.GridY * core.TileSize)
+			vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, bossFireWarnColor, false)
+		}
+	}
+
+	if frame < b.HurtUntilFrame && (frame/4)%2 == 1 {
+		return
+	}
+	x := float32(b.GridX * core.TileSize)
+	y := float32(b.GridY * core.TileSize)
+	size := float32(core.BossSize * core.TileSize)
+	vector.DrawFilledRect(screen, x+2, y+2, size-4, size-4, bossBodyColors[b.Stage()-1], false)
+	if charging {
+		vector.StrokeRect(screen, x+1, y+1, size-2, size-2, 3, bossChargeColor, false)
+	}
+
+	// 两只眼睛朝向当前方向
+	off := core.DirectionOffset(b.Direction)
+	cx, cy := x+size/2+float32(off.GridX)*size/6, y+size/2+float32(off.GridY)*size/6
+	gap := size / 6
+	if off.GridX != 0 {
+		vector.DrawFilledCircle(screen, cx, cy-gap, 4, bossEyeColor, false)
+		vector.DrawFilledCircle(screen, cx, cy+gap, 4, bossEyeColor, false)
+	} else {
+		vector.DrawFilledCircle(screen, cx-gap, cy, 4, bossEyeColor, false)
+		vector.DrawFilledCircle(screen, cx+gap, cy, 4, bossEyeColor, false)
+	}
+}
+
+// drawBossHealthBar 在屏幕顶部绘制首领血条（关乎胜负，不属于 HUD，始终显示），阶段之间画分隔线
+func (g *Game) drawBossHealthBar(screen *ebiten.Image) {
+	b := g.coreGame.Boss
+	if b == nil || b.MaxHP <= 0 {
+		return
+	}
+	label := fmt.Sprintf("BOSS %d/%d", max(b.HP, 0), b.MaxHP)
+	drawText(screen, bossBarX, bossBarY-2, label, bossBarBorderColor)
+
+	x := float32(bossBarX + textWidth(label) + 6)
+	fill := float32(bossBarWidth) * float32(max(b.HP, 0)) / float32(b.MaxHP)
+	vector.DrawFilledRect(screen, x, bossBarY, bossBarWidth, bossBarHeight, bossBarBackColor, false)
+	vector.DrawFilledRect(screen, x, bossBarY, fill, bossBarHeight, bossBarFillColor, false)
+	for stage := 1; stage < core.BossStages; stage++ {
+		sx := x + float32(bossBarWidth*stage/core.BossStages)
+		vector.StrokeLine(screen, sx, bossBarY, sx, bossBarY+bossBarHeight, 1, bossBarBorderColor, false)
+	}
+	vector.StrokeRect(screen, x, bossBarY, bossBarWidth, bossBarHeight, 1, bossBarBorderColor, false)
+}
+
+// formatBossGameOverMessage 首领战的结束提示
+func formatBossGameOverMessage(defeated bool) string {
+	if defeated {
+		return "Boss Defeated!"
+	}
+	return "The Boss Wins!"
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 滚动镜头：地图比屏幕大（大地图）时，世界画面先画到地图大小的画布上，再按镜头裁到屏幕。
+// 镜头跟随本地玩家（观战或本地玩家阵亡后跟随第一个存活的玩家），不会移出地图
+
+// cameraFollowEase 镜头每帧向目标移动的比例（开局直接对准目标）
+const cameraFollowEase = 0.15
+
+// scrollCamera 大地图的滚动镜头
+type scrollCamera struct {
+	canvas      *ebiten.Image
+	x, y        float64 // 镜头左上角在世界中的像素坐标
+	initialized bool
+}
+
+// worldPixels 地图的像素尺寸
+func (g *Game) worldPixels() (int, int) {
+	m := g.coreGame.Map
+	return m.Width * TileSize, m.Height * TileSize
+}
+
+// mapScrolls 地图是否比屏幕大（需要滚动镜头）
+func (g *Game) mapScrolls() bool {
+	width, height := g.worldPixels()
+	return width > ScreenWidth || height > ScreenHeight
+}
+
+// worldCanvas 返回清空后的地图大小的画布
+func (c *scrollCamera) worldCanvas(g *Game) *ebiten.Image {
+	width, height := g.worldPixels()
+	c.canvas = clearedCanvas(c.canvas, width, height)
+	return c.canvas
+}
+
+// present 镜头移向跟随目标，把画布上镜头范围内的部分画到屏幕
+func (c *scrollCamera) present(g *Game, screen *ebiten.Image) {
+	worldW, worldH := g.worldPixels()
+	targetX, targetY := g.cameraTarget()
+	targetX = math.Max(0, math.Min(float64(worldW-ScreenWidth), targetX-ScreenWidth/2))
+	targetY = math.Max(0, math.Min(float64(worldH-ScreenHeight), targetY-ScreenHeight/2))
+	if !c.initialized {
+		c.x, c.y = targetX, targetY
+		c.initialized = true
+	}
+	c.x += (targetX - c.x) * cameraFollowEase
+	c.y += (targetY - c.y) * cameraFollowEase
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-math.Round(c.x), -math.Round(c.y))
+	screen.DrawImage(c.canvas, op)
+}
+
+// cameraTarget 镜头跟随的位置：存活的本地玩家，否则是第一个存活的玩家，都不在时为地图中央
+func (g *Game) cameraTarget() (float64, float64) {
+	var follow *Player
+	for _, player := range g.players {
+		if player.corePlayer.Dead || player.outOfView {
+			continue
+		}
+		if player.isLocal {
+			follow = player
+			break
+		}
+		if follow == nil {
+			follow = player
+		}
+	}
+	if follow == nil {
+		width, height := g.worldPixels()
+		return float64(width) / 2, float64(height) / 2
+	}
+	x, y := follow.GetRenderPosition()
+	return x + float64(follow.corePlayer.Width)/2, y + float64(follow.corePlayer.Height)/2
+}
+
+// clearedCanvas 复用尺寸相同的画布（清空后返回），尺寸变化时重新创建
+func clearedCanvas(canvas *ebiten.Image, width, height int) *ebiten.Image {
+	if canvas == nil || canvas.Bounds().Dx() != width || canvas.Bounds().Dy() != height {
+		return ebiten.NewImage(width, height)
+	}
+	canvas.Clear()
+	return canvas
+}
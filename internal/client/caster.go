@@ -0,0 +1,207 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"sort"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 解说模式：以观战者身份加入，隐藏 HUD 只保留记分板，镜头放大并自动跟随最热闹的区域
+const (
+	casterZoom          = 1.6             // 镜头放大倍数
+	casterFollowEase    = 0.08            // 镜头每帧向目标移动的比例
+	casterRetryInterval = 3 * time.Second // 自动加入失败后的重试间隔
+	casterResultDelay   = 5 * time.Second // 对局结束后停留多久返回房间
+	casterAutoFollow    = -1              // 自动跟随（不锁定玩家）
+	casterScoreHeight   = 20
+)
+
+var (
+	casterScoreBackground = color.RGBA{0, 0, 0, 170}
+	casterAliveColor      = color.RGBA{230, 230, 230, 255}
+	casterDeadColor       = color.RGBA{120, 120, 120, 255}
+	casterFollowColor     = color.RGBA{255, 220, 120, 255}
+)
+
+// casterFollowKeys 切换跟随第 N 名玩家（按玩家 ID 排序），0 恢复自动跟随
+var casterFollowKeys = []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5, ebiten.Key6, ebiten.Key7, ebiten.Key8}
+
+// casterView 解说模式的镜头与记分板状态
+type casterView struct {
+	keys    keyTracker
+	follow  int     // 跟随的玩家 ID，casterAutoFollow 表示自动
+	centerX float64 // 当前镜头中心（像素）
+	centerY float64
+	canvas  *ebiten.Image // 世界画面先绘制到这里，再按镜头缩放到屏幕
+}
+
+func newCasterView() *casterView {
+	return &casterView{
+		follow:  casterAutoFollow,
+		centerX: ScreenWidth / 2,
+		centerY: ScreenHeight / 2,
+	}
+}
+
+// sortedPlayers 按玩家 ID 排序的玩家列表（数字键与记分板使用同一顺序）
+func (g *Game) sortedPlayers() []*Player {
+	players := append([]*Player(nil), g.players...)
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].corePlayer.ID < players[j].corePlayer.ID
+	})
+	return players
+}
+
+// Update 处理切换跟随的热键并移动镜头
+func (c *casterView) Update(g *Game) {
+	players := g.sortedPlayers()
+	for i, key := range casterFollowKeys {
+		if c.keys.JustPressed(key) && i < len(players) {
+			c.follow = players[i].corePlayer.ID
+		}
+	}
+	if c.keys.JustPressed(ebiten.Key0) {
+		c.follow = casterAutoFollow
+	}
+
+	targetX, targetY := c.target(g)
+	c.centerX += (targetX - c.centerX) * casterFollowEase
+	c.centerY += (targetY - c.centerY) * casterFollowEase
+}
+
+// target 镜头目标：锁定的存活玩家，否则是玩家和炸弹最密集的区域
+func (c *casterView) target(g *Game) (float64, float64) {
+	var points [][2]float64
+	for _, player := range g.players {
+		p := player.corePlayer
+		if p.Dead || player.outOfView {
+			continue
+		}
+		x := p.X + core.PlayerWidth/2
+		y := p.Y + core.PlayerHeight/2
+		if p.ID == c.follow {
+			return x, y
+		}
+		points = append(points, [2]float64{x, y})
+	}
+	for _, bomb := range g.coreGame.Bombs {
+		points = append(points, [2]float64{
+			float64(bomb.GridX*core.TileSize + core.TileSize/2),
+			float64(bomb.GridY*core.TileSize + core.TileSize/2),
+		})
+	}
+	if len(points) == 0 {
+		width, height := g.worldPixels()
+		return float64(width) / 2, float64(height) / 2
+	}
+
+	// 以每个点为中心取一个画面大小的窗口，选包含点最多的窗口，镜头对准窗口内的点的中心
+	halfW := ScreenWidth / casterZoom / 2
+	halfH := ScreenHeight / casterZoom / 2
+	bestCount := 0
+	var bestX, bestY float64
+	for _, center := range points {
+		count := 0
+		var sumX, sumY float64
+		for _, p := range points {
+			if math.Abs(p[0]-center[0]) <= halfW && math.Abs(p[1]-center[1]) <= halfH {
+				count++
+				sumX += p[0]
+				sumY += p[1]
+			}
+		}
+		if count > bestCount {
+			bestCount = count
+			bestX, bestY = sumX/float64(count), sumY/float64(count)
+		}
+	}
+	return bestX, bestY
+}
+
+// worldCanvas 返回清空后的世界画布（与地图一样大）
+func (c *casterView) worldCanvas(g *Game) *ebiten.Image {
+	width, height := g.worldPixels()
+	c.canvas = clearedCanvas(c.canvas, width, height)
+	return c.canvas
+}
+
+// present 按镜头把世界画布缩放绘制到屏幕（镜头不会移出地图）
+func (c *casterView) present(g *Game, screen *ebiten.Image) {
+	worldW, worldH := g.worldPixels()
+	halfW := ScreenWidth / casterZoom / 2
+	halfH := ScreenHeight / casterZoom / 2
+	cx := math.Max(halfW, math.Min(float64(worldW)-halfW, c.centerX))
+	cy := math.Max(halfH, math.Min(float64(worldH)-halfH, c.centerY))
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-cx, -cy)
+	op.GeoM.Scale(casterZoom, casterZoom)
+	op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2)
+	op.Filter = ebiten.FilterLinear
+	screen.DrawImage(c.canvas, op)
+}
+
+// drawCasterScoreboard 顶部记分板：每名玩家的名字、击杀数与存活状态，以及剩余时间
+func (g *Game) drawCasterScoreboard(screen *ebiten.Image) {
+	c := g.caster
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, casterScoreHeight, casterScoreBackground, false)
+
+	x := 8
+	for i, player := range g.sortedPlayers() {
+		p := player.corePlayer
+		name := fmt.Sprintf("P%d", p.ID)
+		if tag, ok := g.nameTags[p.ID]; ok {
+			name = tag.text
+		}
+		entry := fmt.Sprintf("%d %s K%d", i+1, name, p.Kills)
+		clr := color.Color(casterAliveColor)
+		switch {
+		case p.Dead:
+			clr = casterDeadColor
+		case p.ID == c.follow:
+			clr = casterFollowColor
+		}
+		drawText(screen, x, 4, entry, clr)
+		x += textWidth(entry) + 16
+	}
+
+	if !g.gameOver && g.matchEndFrame > 0 {
+		timer := "TIME " + g.countdownText
+		drawText(screen, ScreenWidth-textWidth(timer)-8, 4, timer, casterAliveColor)
+	}
+}
+
+// autoSpectate 解说模式回到大厅时自动观战：指定的房间，否则优先选择游戏中的房间
+func (lc *LobbyClient) autoSpectate() {
+	if lc.joinInFlight || time.Now().Before(lc.casterRetryAt) {
+		return
+	}
+	roomID := lc.casterRoom
+	if roomID == "" {
+		for _, room := range lc.roomList {
+			if room == nil {
+				continue
+			}
+			if roomID == "" {
+				roomID = room.Id
+			}
+			if room.Status == gamev1.RoomStatus_ROOM_STATUS_PLAYING {
+				roomID = room.Id
+				break
+			}
+		}
+	}
+	if roomID == "" {
+		return
+	}
+	lc.casterRetryAt = time.Now().Add(casterRetryInterval)
+	lc.startSpectate(roomID)
+}
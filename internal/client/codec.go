@@ -0,0 +1,136 @@
+package client
+
+import (
+	"fmt"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/protocol"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// DecodeServerPacket 解析客户端收到的数据包，返回对应的 protobuf 消息
+// 与服务器的 server.DecodePacket 对应，协议一致性由 internal/conformance 的测试校验
+func DecodeServerPacket(data []byte) (proto.Message, error) {
+	pkt, err := protocol.UnmarshalPacket(data)
+	if err != nil {
+		return nil, fmt.Errorf("反序列化失败: %w", err)
+	}
+
+	switch pkt.Type {
+	case gamev1.MessageType_MESSAGE_TYPE_JOIN_RESPONSE:
+		resp, err := protocol.ParseJoinResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析加入响应失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_GAME_STATE:
+		state, err := protocol.ParseGameState(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析状态失败: %w", err)
+		}
+		return state, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_DELTA_STATE:
+		delta, err := protocol.ParseDeltaState(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析增量状态失败: %w", err)
+		}
+		return delta, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT:
+		event, err := protocol.ParseGameEvent(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析事件失败: %w", err)
+		}
+		return event, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_PING:
+		ping, err := protocol.ParsePing(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析 Ping 失败: %w", err)
+		}
+		return ping, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_PONG:
+		pong, err := protocol.ParsePong(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析 Pong 失败: %w", err)
+		}
+		return pong, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_RECONNECT_RESPONSE:
+		resp, err := protocol.ParseReconnectResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析重连响应失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_ROOM_LIST_RESPONSE:
+		resp, err := protocol.ParseRoomListResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析房间列表失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_ROOM_ACTION_RESPONSE:
+		resp, err := protocol.ParseRoomActionResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析房间操作响应失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_ROOM_STATE_UPDATE:
+		update, err := protocol.ParseRoomStateUpdate(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析房间状态失败: %w", err)
+		}
+		return update, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_SERVER_NOTICE:
+		notice, err := protocol.ParseServerNotice(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析服务器通知失败: %w", err)
+		}
+		return notice, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_DEBUG_AI_STATE:
+		state, err := protocol.ParseDebugAIState(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析 AI 调试信息失败: %w", err)
+		}
+		return state, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_RESPONSE:
+		resp, err := protocol.ParseServerStatusResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析服务器状态失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_RESPONSE:
+		resp, err := protocol.ParseReplaySearchResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析录像搜索结果失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_RESPONSE:
+		resp, err := protocol.ParseMapUploadResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析地图上传结果失败: %w", err)
+		}
+		return resp, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_RESPONSE:
+		resp, err := protocol.ParseProfileUpdateResponse(pkt)
+		if err != nil {
+			return nil, fmt.Errorf("解析偏好修改结果失败: %w", err)
+		}
+		return resp, nil
+
+	default:
+		return nil, fmt.Errorf("未知消息类型: %v", pkt.Type)
+	}
+}
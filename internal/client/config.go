@@ -0,0 +1,139 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ClientConfig 客户端持久化配置（上次使用的连接参数与窗口位置）
+// 命令行参数优先，未指定时使用这里保存的值
+type ClientConfig struct {
+	Server    string         `json:"server"`
+	Proto     string         `json:"proto"`
+	Servers   []SavedServer  `json:"servers"` // 服务器列表（-browse 时选择连接）
+	Character int            `json:"character"`
+	Control   string         `json:"control"`
+	Account   string         `json:"account,omitempty"` // 账号密钥（服务器按账号保存角色、控制方案和规则预设）
+	Telemetry bool           `json:"telemetry"`         // 同意服务器收集输入观察并写入举报（默认不同意）
+	Theme     string         `json:"theme"`
+	Keys      ControlKeys    `json:"keys"` // 两个控制方案的按键（双人同屏时各归一名玩家）
+	Window    WindowGeometry `json:"window"`
+	Volume    int            `json:"volume"` // 音量 0-100（游戏中 F5/F6 调节）
+	Muted     bool           `json:"muted"`  // 静音（游戏中 F4 切换）
+	// 失焦或最小化时仍保持满帧绘制（直播推流时使用，默认降频省电）
+	FullFPSUnfocused bool `json:"full_fps_unfocused"`
+}
+
+// WindowGeometry 窗口位置与大小
+type WindowGeometry struct {
+	X           int  `json:"x"`
+	Y           int  `json:"y"`
+	Width       int  `json:"width"`
+	Height      int  `json:"height"`
+	HasPosition bool `json:"has_position"` // 首次启动没有位置，交给系统决定
+}
+
+// DefaultClientConfig 默认配置
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		Proto:   "tcp",
+		Control: "wasd",
+		Theme:   "classic",
+		Keys:    DefaultControlKeys(),
+		Volume:  80,
+		Window: WindowGeometry{
+			Width:  ScreenWidth,
+			Height: ScreenHeight,
+		},
+	}
+}
+
+// DefaultConfigPath 默认配置文件路径（用户配置目录下的 bomberman/client.json）
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "bomberman-client.json"
+	}
+	return filepath.Join(dir, "bomberman", "client.json")
+}
+
+// LoadClientConfig 读取配置，文件不存在时返回默认配置
+func LoadClientConfig(path string) (ClientConfig, error) {
+	cfg := DefaultClientConfig()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return DefaultClientConfig(), err
+	}
+	if cfg.Window.Width <= 0 || cfg.Window.Height <= 0 {
+		cfg.Window.Width, cfg.Window.Height = ScreenWidth, ScreenHeight
+	}
+	return cfg, nil
+}
+
+// Save 写入配置（先写临时文件再改名，避免中途退出留下半个文件）
+func (c ClientConfig) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ApplyWindow 恢复窗口大小与位置（需在 RunGame 之前调用）
+func (c ClientConfig) ApplyWindow() {
+	ebiten.SetWindowSize(c.Window.Width, c.Window.Height)
+	if c.Window.HasPosition {
+		ebiten.SetWindowPosition(c.Window.X, c.Window.Y)
+	}
+}
+
+// WindowTracker 包装 ebiten.Game，在游戏循环中记录窗口位置与大小
+// 窗口相关 API 只能在游戏循环内调用，因此每帧采样，退出时通过 Config 取出保存
+type WindowTracker struct {
+	ebiten.Game
+
+	mu  sync.Mutex
+	cfg ClientConfig
+}
+
+// NewWindowTracker 创建窗口跟踪器
+func NewWindowTracker(game ebiten.Game, cfg ClientConfig) *WindowTracker {
+	return &WindowTracker{Game: game, cfg: cfg}
+}
+
+// Config 返回包含最新窗口位置的配置（可在其他 goroutine 调用）
+func (t *WindowTracker) Config() ClientConfig {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg
+}
+
+func (t *WindowTracker) Update() error {
+	x, y := ebiten.WindowPosition()
+	w, h := ebiten.WindowSize()
+	if w > 0 && h > 0 {
+		t.mu.Lock()
+		t.cfg.Window = WindowGeometry{X: x, Y: y, Width: w, Height: h, HasPosition: true}
+		t.mu.Unlock()
+	}
+	return t.Game.Update()
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// stateBaselineFrames 保留的最近完整状态数（服务器的增量基线不会早于它的历史窗口）
+const stateBaselineFrames = 2 * core.TPS
+
+// rememberState 保存收到（或重建）的完整状态，作为之后增量的基线，并在下一个输入包中确认
+// 帧号回退说明开始了新的对局，旧对局的状态不能再作为基线
+// 只在接收协程中调用
+func (nc *NetworkClient) rememberState(state *gamev1.GameState) {
+	if n := len(nc.stateBaselines); n > 0 && state.FrameId <= nc.stateBaselines[n-1].FrameId {
+		nc.stateBaselines = nc.stateBaselines[:0]
+	}
+	nc.stateBaselines = append(nc.stateBaselines, state)
+	if len(nc.stateBaselines) > stateBaselineFrames {
+		nc.stateBaselines = nc.stateBaselines[len(nc.stateBaselines)-stateBaselineFrames:]
+	}
+	nc.ackStateFrame.Store(state.FrameId)
+}
+
+// applyDeltaState 在对应的基线上重建完整状态；基线已丢弃时返回 nil（继续确认旧的帧，服务器会改发完整状态）
+func (nc *NetworkClient) applyDeltaState(delta *gamev1.DeltaState) *gamev1.GameState {
+	for i := len(nc.stateBaselines) - 1; i >= 0; i-- {
+		base := nc.stateBaselines[i]
+		if base.FrameId != delta.BaseFrameId {
+			continue
+		}
+		state, err := protocol.ApplyDeltaState(base, delta)
+		if err != nil {
+			log.Printf("应用增量状态失败: %v", err)
+			return nil
+		}
+		nc.rememberState(state)
+		return state
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// desyncReportInterval 两次上报不同步的最短间隔（持续不同步时只偶尔上报，避免刷屏）
+const desyncReportInterval = 5 * time.Second
+
+// frameChecksum 某一帧的校验和
+type frameChecksum struct {
+	frame    int32
+	checksum core.StateChecksum
+	ok       bool
+}
+
+// checkStateChecksum 服务器每隔 core.ChecksumIntervalFrames 帧附带一次校验和，
+// 应用完状态后用本地的地图和炸弹计算同一份校验和：地图只靠增量变化累积，
+// 丢失或重复应用变化都会在这里暴露出来。玩家使用本次状态中的权威位置（本地预测和插值不参与比较）。
+// 帧同步校验（SyncCheckEvent）的帧也在这里记下本地校验和，等事件到达后比较。
+// 服务器开启兴趣管理时状态只包含部分实体，不做比较
+func (ngc *NetworkGameClient) checkStateChecksum(state *gamev1.GameState) {
+	if state.InterestRadius > 0 {
+		return
+	}
+	syncFrame := state.FrameId%core.SyncCheckIntervalFrames == 0
+	if state.Checksum == nil && !syncFrame {
+		return
+	}
+	local := ngc.mirroredChecksum(state)
+	if state.Checksum != nil {
+		ngc.compareChecksum(state.FrameId, protocol.ProtoChecksumToCore(state.Checksum), local)
+	}
+	if syncFrame {
+		ngc.syncLocal = frameChecksum{frame: state.FrameId, checksum: local, ok: true}
+		ngc.matchSyncCheck()
+	}
+}
+
+// onSyncCheck 收到帧同步校验：本地已应用该帧时立即比较，否则等该帧的状态到达
+func (ngc *NetworkGameClient) onSyncCheck(frameID int32, check *gamev1.SyncCheckEvent) {
+	ngc.syncServer = frameChecksum{frame: frameID, checksum: protocol.ProtoChecksumToCore(check.Checksum), ok: true}
+	ngc.matchSyncCheck()
+}
+
+// matchSyncCheck 双方都有同一帧的校验和时比较；帧不同时丢弃较旧的一方
+// （本地跳过了该帧的状态时无法校验，等下一次）
+func (ngc *NetworkGameClient) matchSyncCheck() {
+	server, local := &ngc.syncServer, &ngc.syncLocal
+	if !server.ok || !local.ok {
+		return
+	}
+	switch {
+	case server.frame < local.frame:
+		server.ok = false
+	case server.frame > local.frame:
+		local.ok = false
+	default:
+		ngc.compareChecksum(server.frame, server.checksum, local.checksum)
+		server.ok, local.ok = false, false
+	}
+}
+
+// mirroredChecksum 本地镜像状态的校验和：地图和炸弹取自本地，玩家取自本次状态
+func (ngc *NetworkGameClient) mirroredChecksum(state *gamev1.GameState) core.StateChecksum {
+	players := make([]*core.Player, 0, len(state.Players))
+	for _, p := range state.Players {
+		if corePlayer := protocol.ProtoPlayerToCore(p); corePlayer != nil {
+			players = append(players, corePlayer)
+		}
+	}
+	game := ngc.game.coreGame
+	return core.ChecksumOf(game.Map, game.Bombs, players)
+}
+
+// compareChecksum 比较服务器与本地的校验和，不一致时记录日志并限频上报
+func (ngc *NetworkGameClient) compareChecksum(frameID int32, server, local core.StateChecksum) {
+	parts := server.Diff(local)
+	if len(parts) == 0 {
+		return
+	}
+
+	ngc.desyncCount++
+	session.desynced()
+	log.Printf("帧 %d 与服务器状态不同步（%s），累计 %d 次", frameID, strings.Join(parts, ","), ngc.desyncCount)
+	if time.Since(ngc.lastDesyncReport) < desyncReportInterval {
+		return
+	}
+	ngc.lastDesyncReport = time.Now()
+	if err := ngc.network.SendDesyncReport(frameID, server, local); err != nil {
+		log.Printf("上报不同步失败: %v", err)
+	}
+}
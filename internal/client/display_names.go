@@ -0,0 +1,30 @@
+package client
+
+import (
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/protocol"
+	"bomberman/pkg/resources"
+)
+
+// uiLang 界面文案的语言：游戏内字体只有 ASCII，统一使用英文
+const uiLang = resources.LangEN
+
+// characterLabel 房间成员的角色名称，旧服务器没有下发 character_id 时按角色枚举查找
+func characterLabel(player *gamev1.RoomPlayer) string {
+	id := player.CharacterId
+	if id == "" {
+		id = protocol.ProtoCharacterTypeToCore(player.Character).ID()
+	}
+	if id == "" {
+		return "?"
+	}
+	return resources.Character(id, uiLang).Name
+}
+
+// mapLabel 地图名称，旧服务器没有下发 map_id 时为空
+func mapLabel(mapID string) string {
+	if mapID == "" {
+		return ""
+	}
+	return resources.Map(mapID, uiLang).Name
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"image/color"
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// overtimeFightFrames 淹没后 "FIGHT!" 横幅的显示时长
+const overtimeFightFrames = 2 * core.TPS
+
+var (
+	overtimeWarningColor = color.RGBA{40, 90, 200, 90}
+	overtimeFloodColor   = color.RGBA{20, 60, 160, 210}
+	overtimeWaveColor    = color.RGBA{120, 180, 255, 200}
+	overtimeArenaColor   = color.RGBA{255, 210, 60, 255}
+	overtimeBannerColor  = color.RGBA{255, 80, 60, 255}
+	overtimeVignette     = color.RGBA{200, 20, 20, 255}
+)
+
+// onOvertimeCountdown 服务器广播的决斗加时倒计时（状态同步也会带上淹没帧，这里只是更早生效）
+func (ngc *NetworkGameClient) onOvertimeCountdown(e *gamev1.OvertimeCountdownEvent) {
+	ngc.game.coreGame.OvertimeFloodFrame = e.FloodFrame
+	if e.SecondsLeft == 0 {
+		log.Printf("决斗加时：门口竞技场外已被淹没")
+	} else {
+		log.Printf("决斗加时：%d 秒后淹没门口 (%d,%d) 竞技场外的格子", e.SecondsLeft, e.DoorX, e.DoorY)
+	}
+}
+
+// drawOvertimeFlood 绘制决斗加时：倒计时期间将被淹没的格子闪烁蓝色，淹没后铺满水面；
+// 门口竞技场的边框随心跳节奏闪烁
+func (g *Game) drawOvertimeFlood(screen *ebiten.Image) {
+	cells := g.coreGame.OvertimeFloodCells()
+	if cells == nil {
+		return
+	}
+	frame := g.coreGame.CurrentFrame
+	flooding := g.coreGame.OvertimeFlooding()
+
+	if flooding || (frame/hazardFlashFrames)%2 == 0 {
+		for _, cell := range cells {
+			x := float32(cell.GridX * core.TileSize)
+			y := float32(cell.GridY * core.TileSize)
+			if !flooding {
+				vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, overtimeWarningColor, false)
+				continue
+			}
+			vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, overtimeFloodColor, false)
+			wave := float32((int(frame)/2 + cell.GridX*5 + cell.GridY*3) % core.TileSize)
+			vector.DrawFilledRect(screen, x, y+wave, core.TileSize, 2, overtimeWaveColor, false)
+		}
+	}
+
+	door := g.coreGame.Map.HiddenDoorPos
+	x := float32((door.X - core.OvertimeArenaRadius) * core.TileSize)
+	y := float32((door.Y - core.OvertimeArenaRadius) * core.TileSize)
+	size := float32((2*core.OvertimeArenaRadius + 1) * core.TileSize)
+	width := float32(2)
+	if frame%core.TPS < core.TPS/4 {
+		width = 4
+	}
+	vector.StrokeRect(screen, x, y, size, size, width, overtimeArenaColor, false)
+}
+
+// drawOvertimeBanner 绘制决斗加时横幅：倒计时数字每秒放大后收缩，屏幕边缘泛红；淹没后短暂显示 FIGHT!
+func (g *Game) drawOvertimeBanner(screen *ebiten.Image) {
+	if g.gameOver || !g.coreGame.OvertimeTriggered() {
+		return
+	}
+	left := g.coreGame.OvertimeFloodFrame - g.coreGame.CurrentFrame
+	if left <= -overtimeFightFrames {
+		return
+	}
+
+	if left <= 0 {
+		if (-left/hazardFlashFrames)%2 == 0 {
+			drawScaledCenteredText(screen, "FIGHT!", ScreenWidth/2, 70, 4, overtimeBannerColor)
+		}
+		return
+	}
+
+	// 本秒已过去的比例：每秒开始时数字最大、边缘最红
+	elapsed := float64((core.TPS-left%core.TPS)%core.TPS) / core.TPS
+	seconds := (left + core.TPS - 1) / core.TPS
+
+	drawOvertimeVignette(screen, uint8(120*(1-elapsed)))
+	drawScaledCenteredText(screen, "OVERTIME DUEL", ScreenWidth/2, 40, 2, overtimeBannerColor)
+	drawScaledCenteredText(screen, string(rune('0'+seconds%10)), ScreenWidth/2, 70, 3+2*(1-elapsed), overtimeArenaColor)
+	drawCenteredText(screen, "Stay near the door!", ScreenWidth/2, 150, uiTextPrimary)
+}
+
+// drawOvertimeVignette 在屏幕四周画一圈红色边框
+func drawOvertimeVignette(screen *ebiten.Image, alpha uint8) {
+	const border = 12
+	clr := overtimeVignette
+	clr.A = alpha
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, border, clr, false)
+	vector.DrawFilledRect(screen, 0, ScreenHeight-border, ScreenWidth, border, clr, false)
+	vector.DrawFilledRect(screen, 0, border, border, ScreenHeight-2*border, clr, false)
+	vector.DrawFilledRect(screen, ScreenWidth-border, border, border, ScreenHeight-2*border, clr, false)
+}
+
+// drawScaledCenteredText 按比例放大绘制居中文字（界面字体只有一种字号）
+func drawScaledCenteredText(screen *ebiten.Image, msg string, centerX, y int, scale float64, clr color.Color) {
+	width := measureText(msg) * scale
+	options := &text.DrawOptions{}
+	options.GeoM.Scale(scale, scale)
+	options.GeoM.Translate(float64(centerX)-width/2, float64(y))
+	options.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, msg, uiFace, options)
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"image/color"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// doorPingDurationFrames 位置提示在地图上的显示时长
+const doorPingDurationFrames = core.TPS * 3 / 2
+
+var doorPingColor = color.RGBA{255, 90, 70, 255}
+
+// doorPing 门口蹲守位置提示（服务器 DoorCampPingEvent）
+type doorPing struct {
+	playerID     int32
+	gridX, gridY int
+	startFrame   int32
+}
+
+// addDoorPing 记录新的位置提示（同一玩家只保留最新一次）
+func (g *Game) addDoorPing(playerID int32, gridX, gridY int) {
+	ping := doorPing{playerID: playerID, gridX: gridX, gridY: gridY, startFrame: g.coreGame.CurrentFrame}
+	for i, p := range g.doorPings {
+		if p.playerID == playerID {
+			g.doorPings[i] = ping
+			return
+		}
+	}
+	g.doorPings = append(g.doorPings, ping)
+}
+
+// drawDoorPings 在被暴露的格子上绘制扩散的圆环
+func (g *Game) drawDoorPings(screen *ebiten.Image) {
+	frame := g.coreGame.CurrentFrame
+	active := g.doorPings[:0]
+	for _, p := range g.doorPings {
+		elapsed := frame - p.startFrame
+		if elapsed < 0 || elapsed >= doorPingDurationFrames {
+			continue
+		}
+		active = append(active, p)
+
+		progress := float32(elapsed) / float32(doorPingDurationFrames)
+		cx := float32(p.gridX*core.TileSize + core.TileSize/2)
+		cy := float32(p.gridY*core.TileSize + core.TileSize/2)
+		radius := core.TileSize/2 + progress*core.TileSize
+		clr := doorPingColor
+		clr.A = uint8(255 * (1 - progress))
+		vector.StrokeCircle(screen, cx, cy, radius, 2, clr, true)
+	}
+	g.doorPings = active
+}
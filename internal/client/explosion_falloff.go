@@ -0,0 +1,49 @@
+package client
+
+import (
+	"math"
+
+	"bomberman/pkg/core"
+)
+
+// 爆炸强度随距离衰减：近处的爆炸醒目，远处的爆炸淡一些但仍清晰可见
+const (
+	explosionFullRange    = 3.0  // 距离本地玩家多少格以内保持全强度
+	explosionFadeRange    = 12.0 // 衰减到最低强度的距离（格）
+	explosionMinIntensity = 0.45 // 最低强度
+)
+
+// explosionFalloff 按距离（格）计算爆炸强度系数（explosionMinIntensity~1）
+// 画面透明度和爆炸音效的音量（sound_cues.go）都按它缩放
+func explosionFalloff(distance float64) float64 {
+	if distance <= explosionFullRange {
+		return 1
+	}
+	t := (distance - explosionFullRange) / (explosionFadeRange - explosionFullRange)
+	if t > 1 {
+		t = 1
+	}
+	return 1 - t*(1-explosionMinIntensity)
+}
+
+// explosionIntensity 爆炸相对最近的本地存活玩家的强度
+// 没有本地存活玩家（观战、已阵亡）时按全强度显示
+func (g *Game) explosionIntensity(explosion *core.Explosion) float64 {
+	cx := float64(explosion.GridX) + 0.5
+	cy := float64(explosion.GridY) + 0.5
+
+	nearest := math.Inf(1)
+	for _, player := range g.players {
+		if !player.isLocal || player.corePlayer.Dead {
+			continue
+		}
+		p := player.corePlayer
+		px := (p.X + core.PlayerWidth/2) / core.TileSize
+		py := (p.Y + core.PlayerHeight/2) / core.TileSize
+		nearest = math.Min(nearest, math.Hypot(px-cx, py-cy))
+	}
+	if math.IsInf(nearest, 1) {
+		return 1
+	}
+	return explosionFalloff(nearest)
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"golang.org/x/image/font/basicfont"
+)
+
+// 界面字体：优先使用覆盖中文的 TrueType/OpenType 字体，加载失败时退回 basicfont（只有 ASCII）。
+// 所有界面文字都通过 uiFace 绘制，宽度用 measureText 计算，换字体后居中、右对齐仍然正确
+
+// uiFontSize 矢量字体的字号，与 basicfont 的 13 像素行高一致，界面布局不需要调整
+const uiFontSize = 13
+
+//go:embed fonts
+var bundledFonts embed.FS
+
+// basicFace 点阵字体（始终可用）
+var basicFace text.Face = text.NewGoXFace(basicfont.Face7x13)
+
+// uiFace 当前的界面字体（InitFonts 之前为点阵字体）
+var uiFace = basicFace
+
+// systemFontPaths 常见的系统中文字体，没有内置字体时尝试
+var systemFontPaths = []string{
+	"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc",
+	"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc",
+	"/System/Library/Fonts/PingFang.ttc",
+	"/System/Library/Fonts/STHeiti Light.ttc",
+	`C:\Windows\Fonts\msyh.ttc`,
+	`C:\Windows\Fonts\simhei.ttf`,
+}
+
+// InitFonts 选择界面字体（需在游戏开始前调用）：依次尝试 fontFile（-font，空表示不指定）、
+// 内置字体和系统中文字体，全部失败时继续使用点阵字体。返回实际使用的字体来源
+func InitFonts(fontFile string) string {
+	type candidate struct {
+		name string
+		load func() ([]byte, error)
+	}
+	var candidates []candidate
+	if fontFile != "" {
+		candidates = append(candidates, candidate{fontFile, func() ([]byte, error) { return os.ReadFile(fontFile) }})
+	}
+	if name, ok := bundledFontName(); ok {
+		candidates = append(candidates, candidate{"bundled " + name, func() ([]byte, error) { return bundledFonts.ReadFile(name) }})
+	}
+	for _, p := range systemFontPaths {
+		candidates = append(candidates, candidate{p, func() ([]byte, error) { return os.ReadFile(p) }})
+	}
+
+	for _, c := range candidates {
+		data, err := c.load()
+		if err != nil {
+			if c.name == fontFile {
+				log.Printf("读取字体 %s 失败: %v", c.name, err)
+			}
+			continue
+		}
+		face, err := parseFontFace(data)
+		if err != nil {
+			log.Printf("字体 %s 无法使用: %v", c.name, err)
+			continue
+		}
+		uiFace = face
+		log.Printf("界面字体: %s", c.name)
+		return c.name
+	}
+	uiFace = basicFace
+	log.Printf("没有可用的中文字体，使用点阵字体（中文显示为方框，可用 -font 指定字体文件）")
+	return "basicfont"
+}
+
+// bundledFontName 嵌入目录中的第一个字体文件
+func bundledFontName() (string, bool) {
+	entries, err := fs.ReadDir(bundledFonts, "fonts")
+	if err != nil {
+		return "", false
+	}
+	for _, e := range entries {
+		switch strings.ToLower(path.Ext(e.Name())) {
+		case ".ttf", ".otf", ".ttc":
+			return "fonts/" + e.Name(), true
+		}
+	}
+	return "", false
+}
+
+// parseFontFace 解析字体文件（.ttc 字体集合使用其中第一个字体）
+func parseFontFace(data []byte) (text.Face, error) {
+	source, err := text.NewGoTextFaceSource(bytes.NewReader(data))
+	if err != nil {
+		sources, collectionErr := text.NewGoTextFaceSourcesFromCollection(bytes.NewReader(data))
+		if collectionErr != nil {
+			return nil, err
+		}
+		if len(sources) == 0 {
+			return nil, fmt.Errorf("字体集合中没有字体")
+		}
+		source = sources[0]
+	}
+	return &text.GoTextFace{Source: source, Size: uiFontSize}, nil
+}
+
+// measureText 文字以当前界面字体绘制时的宽度（像素）
+func measureText(msg string) float64 {
+	return text.Advance(msg, uiFace)
+}
+
+// textWidth 同 measureText，向上取整（用于整数坐标的布局）
+func textWidth(msg string) int {
+	return int(math.Ceil(measureText(msg)))
+}
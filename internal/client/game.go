@@ -5,12 +5,12 @@ import (
 	"image/color"
 	"time"
 
+	gamev1 "bomberman/api/gen/bomberman/v1"
 	"bomberman/pkg/core"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/font/basicfont"
 )
 
 // Direction 重新导出
@@ -28,8 +28,8 @@ const (
 type ControlScheme int
 
 const (
-	ControlWASD  ControlScheme = iota // WASD + 空格键
-	ControlArrow                      // 方向键+回车键
+	ControlWASD  ControlScheme = iota // 默认 WASD + 空格键（E 推人，左 Shift 冲刺），可在配置中改键
+	ControlArrow                      // 默认方向键+回车键（右 Shift 推人，右 Ctrl 冲刺），可在配置中改键
 )
 
 func (c ControlScheme) String() string {
@@ -57,15 +57,33 @@ type Game struct {
 	coreGame            *core.Game
 	players             []*Player
 	bombRenderers       []*BombRenderer
+	predictedBombs      []*BombRenderer // 联机模式本地预测的幽灵炸弹（见 predicted_bombs.go）
 	explosionRenderers  []*ExplosionRenderer
 	mapRenderer         *MapRenderer
 	gameOver            bool
 	gameOverMessage     string
+	gameOverDetail      string // post-match stats line under the message
 	matchEndFrame       int32
 	countdownText       string
 	lastCountdownSecond int32
 	lastUpdateTime      time.Time
 	controlScheme       ControlScheme
+	spectatorCount      int32                  // 当前观战人数
+	doorPings           []doorPing             // 门口蹲守位置提示
+	blastCells          map[core.GridPos]int32 // 最近爆炸覆盖过的格子 -> 最后一次看到的帧（tile_debris.go）
+	tileDebris          []tileDebris           // 没见过爆炸就消失的砖块的碎裂动画
+	hazards             []core.HazardOverlay   // 地图危险区域覆盖
+	suddenDeathWarnings []core.GridPos         // 突然死亡即将落墙的格子
+	caster              *casterView            // 解说模式（nil 表示普通模式）
+	camera              scrollCamera           // 大地图的滚动镜头（camera.go）
+	nameTags            map[int]nameTag        // 玩家头顶名字（联机模式由房间信息填充）
+	series              *gamev1.SeriesScore    // 多回合对局的比分（nil 表示单局，rounds.go）
+	intermission        *roundIntermission     // 回合间歇（nil 表示不在间歇中）
+	offline             map[int]time.Time      // 断线对手的离线保护截止时间（offline_badge.go）
+	hud                 HUDVisibility
+	sounds              soundCues  // 音效触发（sound_cues.go）
+	renderersStale      bool       // 降频渲染期间渲染器尚未同步（见 idle_render.go）
+	pause               *pauseMenu // 单机模式的暂停菜单（nil 表示不能暂停，见 pause_menu.go）
 }
 
 // NewGame 创建新游戏
@@ -124,14 +142,33 @@ func (g *Game) SetControlScheme(scheme ControlScheme) {
 	g.controlScheme = scheme
 }
 
+// SetHUDHidden 设置是否隐藏 HUD（F1 可随时切换）
+func (g *Game) SetHUDHidden(hidden bool) {
+	g.hud.SetHidden(hidden)
+}
+
+// HUDHidden HUD 当前是否隐藏
+func (g *Game) HUDHidden() bool {
+	return !g.hud.Visible()
+}
+
 // Update 更新游戏状态
 func (g *Game) Update() error {
+	g.hud.Update()
+
+	if paused, err := g.updatePause(); paused || err != nil {
+		return err
+	}
+
 	if g.gameOver {
 		return nil
 	}
 
 	// 更新核心游戏逻辑（不再需要 deltaTime）
 	g.coreGame.Update()
+	g.hazards = g.coreGame.HazardOverlays()
+	g.suddenDeathWarnings = g.coreGame.SuddenDeathWarnings()
+	g.playSoundCues()
 
 	// 检查游戏是否结束
 	if g.coreGame.IsGameOver() {
@@ -139,7 +176,7 @@ func (g *Game) Update() error {
 	}
 
 	// 同步渲染器列表
-	g.syncRenderers()
+	g.syncRenderersIfVisible()
 
 	// 更新玩家动画和输入
 	for _, player := range g.players {
@@ -151,6 +188,8 @@ func (g *Game) Update() error {
 
 // syncRenderers 同步渲染器列表
 func (g *Game) syncRenderers() {
+	g.renderersStale = false
+
 	// 同步炸弹渲染器
 	g.bombRenderers = g.bombRenderers[:0]
 	for _, bomb := range g.coreGame.Bombs {
@@ -167,31 +206,109 @@ func (g *Game) syncRenderers() {
 // Draw 绘制游戏画面
 func (g *Game) Draw(screen *ebiten.Image) {
 	g.updateCountdownText()
+	if g.renderersStale {
+		g.syncRenderers()
+	}
+
+	// 解说模式先画到世界画布，再按镜头缩放到屏幕；大地图先画到地图大小的画布，再按滚动镜头裁到屏幕
+	world := screen
+	switch {
+	case g.caster != nil:
+		world = g.caster.worldCanvas(g)
+	case g.mapScrolls():
+		world = g.camera.worldCanvas(g)
+	}
 
 	// 绘制地图
-	g.mapRenderer.Draw(screen)
+	g.mapRenderer.Draw(world)
+
+	// 绘制危险区域
+	g.drawHazards(world)
+	g.drawSuddenDeathWarnings(world)
+	g.drawOvertimeFlood(world)
+
+	// 绘制道具
+	g.drawItems(world)
+	g.drawTileDebris(world)
 
 	// 绘制爆炸效果
 	for _, renderer := range g.explosionRenderers {
-		renderer.Draw(screen, g.coreGame.CurrentFrame)
+		renderer.Intensity = g.explosionIntensity(renderer.Explosion)
+		renderer.Draw(world, g.coreGame.CurrentFrame)
 	}
 
 	// 绘制炸弹
 	for _, renderer := range g.bombRenderers {
-		renderer.Draw(screen, g.coreGame.CurrentFrame)
+		renderer.Draw(world, g.coreGame.CurrentFrame)
 	}
+	for _, renderer := range g.predictedBombs {
+		renderer.Draw(world, g.coreGame.CurrentFrame)
+	}
+
+	// 绘制首领
+	g.drawBoss(world)
 
 	// 绘制玩家
 	for _, player := range g.players {
-		player.Draw(screen)
+		if !player.outOfView {
+			player.Draw(world)
+		}
+	}
+
+	g.drawDoorPings(world)
+	g.drawOfflineBadges(world)
+
+	switch {
+	case g.caster != nil:
+		g.drawNameTags(world)
+		g.caster.present(g, screen)
+	case world != screen:
+		if g.hud.Visible() {
+			g.drawNameTags(world)
+		}
+		g.camera.present(g, screen)
 	}
 
-	// 游戏结束提示
+	// 决斗加时横幅（关乎生死，不属于 HUD，始终显示）
+	g.drawOvertimeBanner(screen)
+	g.drawBossHealthBar(screen)
+	g.drawIntermission(screen)
+
+	// 游戏结束提示（需要玩家确认，不属于 HUD，始终显示）
 	if g.gameOver {
-		drawGameOverOverlay(screen, g.gameOverMessage)
-	} else if g.matchEndFrame > 0 {
+		drawGameOverOverlay(screen, g.gameOverMessage, g.gameOverDetail)
+	}
+	if g.caster == nil {
+		g.drawScoreboard(screen)
+	}
+	g.drawPauseMenu(screen)
+
+	// 解说模式只保留记分板
+	if g.caster != nil {
+		g.drawCasterScoreboard(screen)
+		return
+	}
+
+	if g.hud.Visible() {
+		if world == screen {
+			g.drawNameTags(screen)
+		}
+		g.drawHUD(screen)
+	}
+}
+
+// drawHUD 绘制 HUD 层
+func (g *Game) drawHUD(screen *ebiten.Image) {
+	if !g.gameOver && g.matchEndFrame > 0 {
 		drawCenteredText(screen, "TIME "+g.countdownText, ScreenWidth/2, 10, color.RGBA{230, 230, 230, 255})
 	}
+
+	if g.spectatorCount > 0 {
+		drawText(screen, ScreenWidth-96, 10, fmt.Sprintf("WATCHING %d", g.spectatorCount), color.RGBA{200, 200, 200, 255})
+	}
+
+	g.drawPlayerStats(screen)
+	g.drawRoundScore(screen)
 }
 
 // SetGameOverMessage sets the game over message
@@ -199,13 +316,18 @@ func (g *Game) SetGameOverMessage(message string) {
 	g.gameOverMessage = message
 }
 
+// SetGameOverDetail sets the stats line shown under the game over message
+func (g *Game) SetGameOverDetail(detail string) {
+	g.gameOverDetail = detail
+}
+
 // Layout 设置屏幕布局
 func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
 	return ScreenWidth, ScreenHeight
 }
 
 // drawGameOverOverlay draws the game over overlay with message
-func drawGameOverOverlay(screen *ebiten.Image, message string) {
+func drawGameOverOverlay(screen *ebiten.Image, message, detail string) {
 	// Dim background
 	overlay := ebiten.NewImage(ScreenWidth, ScreenHeight)
 	overlay.Fill(color.RGBA{0, 0, 0, 160})
@@ -252,6 +374,9 @@ func drawGameOverOverlay(screen *ebiten.Image, message string) {
 	} else {
 		drawCenteredText(screen, "Press Enter to Continue", ScreenWidth/2, messageY, color.RGBA{150, 160, 175, 255})
 	}
+	if detail != "" {
+		drawCenteredText(screen, detail, ScreenWidth/2, panelY+84, color.RGBA{150, 160, 175, 255})
+	}
 }
 
 func (g *Game) updateCountdownText() {
@@ -273,12 +398,10 @@ func (g *Game) updateCountdownText() {
 
 // drawCenteredText draws text centered at the given position
 func drawCenteredText(screen *ebiten.Image, textStr string, centerX, y int, clr color.Color) {
-	font := text.NewGoXFace(basicfont.Face7x13)
-	textWidth := len(textStr) * 7
-	x := centerX - textWidth/2
+	x := centerX - textWidth(textStr)/2
 
 	options := &text.DrawOptions{}
 	options.GeoM.Translate(float64(x), float64(y))
 	options.ColorScale.ScaleWithColor(clr)
-	text.Draw(screen, textStr, font, options)
+	text.Draw(screen, textStr, uiFace, options)
 }
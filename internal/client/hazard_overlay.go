@@ -0,0 +1,63 @@
+package client
+
+import (
+	"image/color"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// hazardFlashFrames 预警闪烁的半周期，剩余时间不足一秒时加快一倍
+const hazardFlashFrames = core.TPS / 4
+
+var (
+	hazardWarningColor = color.RGBA{255, 60, 30, 90}
+	hazardLavaColor    = color.RGBA{255, 90, 20, 200}
+	hazardLavaEdge     = color.RGBA{255, 200, 60, 255}
+	suddenDeathColor   = color.RGBA{120, 120, 140, 150}
+	suddenDeathEdge    = color.RGBA{255, 60, 30, 220}
+)
+
+// drawHazards 绘制地图危险区域：预警时闪烁红色，生效时铺满熔岩
+func (g *Game) drawHazards(screen *ebiten.Image) {
+	frame := g.coreGame.CurrentFrame
+	for _, overlay := range g.hazards {
+		if !overlay.Active {
+			period := int32(hazardFlashFrames)
+			if overlay.EndFrame-frame < core.TPS {
+				period /= 2
+			}
+			if (frame/period)%2 == 1 {
+				continue
+			}
+		}
+		for _, cell := range overlay.Cells {
+			x := float32(cell.GridX * core.TileSize)
+			y := float32(cell.GridY * core.TileSize)
+			if overlay.Active {
+				vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, hazardLavaColor, false)
+				vector.StrokeRect(screen, x+1, y+1, core.TileSize-2, core.TileSize-2, 2, hazardLavaEdge, false)
+			} else {
+				vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, hazardWarningColor, false)
+			}
+		}
+	}
+}
+
+// drawSuddenDeathWarnings 绘制突然死亡即将落墙的格子（闪烁的灰色墙影）
+func (g *Game) drawSuddenDeathWarnings(screen *ebiten.Image) {
+	if len(g.suddenDeathWarnings) == 0 {
+		return
+	}
+	if (g.coreGame.CurrentFrame/hazardFlashFrames)%2 == 1 {
+		return
+	}
+	for _, cell := range g.suddenDeathWarnings {
+		x := float32(cell.GridX * core.TileSize)
+		y := float32(cell.GridY * core.TileSize)
+		vector.DrawFilledRect(screen, x, y, core.TileSize, core.TileSize, suddenDeathColor, false)
+		vector.StrokeRect(screen, x+1, y+1, core.TileSize-2, core.TileSize-2, 2, suddenDeathEdge, false)
+	}
+}
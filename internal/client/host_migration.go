@@ -0,0 +1,17 @@
+package client
+
+import gamev1 "bomberman/api/gen/bomberman/v1"
+
+// hostChangedText 房主变更的提示文字
+func hostChangedText(e *gamev1.HostChangedEvent, localID int32) string {
+	switch {
+	case e.NewHostId == 0:
+		return "Host left: the first player back becomes host"
+	case e.NewHostId == localID:
+		return "You are now the host"
+	case e.NewHostName != "":
+		return e.NewHostName + " is now the host"
+	default:
+		return playerLabel(e.NewHostId) + " is now the host"
+	}
+}
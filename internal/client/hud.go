@@ -0,0 +1,30 @@
+package client
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// hudToggleKey 切换 HUD 显示的按键
+const hudToggleKey = ebiten.KeyF1
+
+// HUDVisibility HUD 层（计时、观战人数、调试叠加层等）的显示开关
+// 隐藏后只绘制地图、炸弹、爆炸和玩家，用于录制干净的游戏画面
+type HUDVisibility struct {
+	hidden bool
+	keys   keyTracker
+}
+
+// Update 处理切换按键
+func (h *HUDVisibility) Update() {
+	if h.keys.JustPressed(hudToggleKey) {
+		h.hidden = !h.hidden
+	}
+}
+
+// Visible HUD 是否可见
+func (h *HUDVisibility) Visible() bool {
+	return !h.hidden
+}
+
+// SetHidden 设置是否隐藏 HUD
+func (h *HUDVisibility) SetHidden(hidden bool) {
+	h.hidden = hidden
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 省电渲染：窗口失焦或最小化时降低绘制频率。Update 仍按 60 TPS 运行，网络心跳、输入预测与对帐不受影响，
+// 只是 Draw 隔一段时间才真正绘制一次（跳过的帧保留上一帧画面），纯视觉的本地模拟（渲染器同步、行走动画）也随之暂停。
+// 直播推流等需要失焦时保持满帧的场景可以关闭（-full-fps-unfocused 或配置 full_fps_unfocused）
+const (
+	idleUnfocusedDrawInterval = 100 * time.Millisecond // 失焦时约 10 FPS
+	idleMinimizedDrawInterval = time.Second            // 最小化时每秒绘制一次
+)
+
+// renderIdle 当前是否处于降频渲染（只在游戏循环内读写）
+var renderIdle bool
+
+// renderingIdle 降频渲染期间跳过纯视觉的本地模拟
+func renderingIdle() bool {
+	return renderIdle
+}
+
+// IdleRenderer 包装 ebiten.Game，在窗口失焦或最小化时降低绘制频率
+type IdleRenderer struct {
+	ebiten.Game
+
+	enabled      bool
+	drawInterval time.Duration // 0 表示每帧绘制
+	lastDraw     time.Time
+}
+
+// NewIdleRenderer 创建省电渲染包装，enabled 为 false 时始终满帧绘制
+func NewIdleRenderer(game ebiten.Game, enabled bool) *IdleRenderer {
+	return &IdleRenderer{Game: game, enabled: enabled}
+}
+
+func (r *IdleRenderer) Update() error {
+	var interval time.Duration
+	if r.enabled {
+		switch {
+		case ebiten.IsWindowMinimized():
+			interval = idleMinimizedDrawInterval
+		case !ebiten.IsFocused():
+			interval = idleUnfocusedDrawInterval
+		}
+	}
+	if interval != r.drawInterval {
+		// 降频期间不清屏，跳过绘制的帧沿用上一帧画面
+		ebiten.SetScreenClearedEveryFrame(interval == 0)
+		r.drawInterval = interval
+		r.lastDraw = time.Time{}
+	}
+	renderIdle = interval > 0
+	return r.Game.Update()
+}
+
+func (r *IdleRenderer) Draw(screen *ebiten.Image) {
+	if r.drawInterval > 0 {
+		now := time.Now()
+		if now.Sub(r.lastDraw) < r.drawInterval {
+			return
+		}
+		r.lastDraw = now
+	}
+	r.Game.Draw(screen)
+}
+
+// syncRenderersIfVisible 在 Update 中同步渲染器，降频渲染期间推迟到下一次 Draw
+func (g *Game) syncRenderersIfVisible() {
+	if renderingIdle() {
+		g.renderersStale = true
+		return
+	}
+	g.syncRenderers()
+}
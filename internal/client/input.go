@@ -0,0 +1,98 @@
+package client
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// InputState 一帧的操作输入（与输入设备无关）
+type InputState struct {
+	Up, Down, Left, Right bool
+	Bomb, Shove, Sprint   bool
+}
+
+// InputProvider 输入来源：键盘、手柄，或多个来源的组合
+type InputProvider interface {
+	Input() InputState
+}
+
+// keyboardInput 按一套按键读取键盘
+type keyboardInput struct {
+	keys KeyBindings
+}
+
+func (k keyboardInput) Input() InputState {
+	return InputState{
+		Up:     ebiten.IsKeyPressed(k.keys.Up),
+		Down:   ebiten.IsKeyPressed(k.keys.Down),
+		Left:   ebiten.IsKeyPressed(k.keys.Left),
+		Right:  ebiten.IsKeyPressed(k.keys.Right),
+		Bomb:   ebiten.IsKeyPressed(k.keys.Bomb),
+		Shove:  ebiten.IsKeyPressed(k.keys.Shove),
+		Sprint: ebiten.IsKeyPressed(k.keys.Sprint),
+	}
+}
+
+// gamepadStickDeadzone 摇杆死区，避免摇杆回中不准时角色自己走动
+const gamepadStickDeadzone = 0.5
+
+// gamepadIDs 复用的手柄 ID 缓冲（输入只在 Update 中读取，不需要加锁）
+var gamepadIDs []ebiten.GamepadID
+
+// gamepadInput 读取第 slot 个已连接的手柄（按连接顺序，双人同屏时第二名玩家使用第 2 个手柄）
+// 只支持标准布局的手柄：方向键或左摇杆移动，按键见 GamepadBindings
+type gamepadInput struct {
+	slot    int
+	buttons GamepadBindings
+}
+
+func (g gamepadInput) Input() InputState {
+	gamepadIDs = ebiten.AppendGamepadIDs(gamepadIDs[:0])
+	if g.slot >= len(gamepadIDs) {
+		return InputState{}
+	}
+	id := gamepadIDs[g.slot]
+	if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+		return InputState{}
+	}
+
+	pressed := func(b ebiten.StandardGamepadButton) bool {
+		return ebiten.IsStandardGamepadButtonPressed(id, b)
+	}
+	x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal)
+	y := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickVertical)
+	return InputState{
+		Up:     pressed(ebiten.StandardGamepadButtonLeftTop) || y < -gamepadStickDeadzone,
+		Down:   pressed(ebiten.StandardGamepadButtonLeftBottom) || y > gamepadStickDeadzone,
+		Left:   pressed(ebiten.StandardGamepadButtonLeftLeft) || x < -gamepadStickDeadzone,
+		Right:  pressed(ebiten.StandardGamepadButtonLeftRight) || x > gamepadStickDeadzone,
+		Bomb:   pressed(g.buttons.Bomb.button()),
+		Shove:  pressed(g.buttons.Shove.button()),
+		Sprint: pressed(g.buttons.Sprint.button()),
+	}
+}
+
+// multiInput 合并多个输入来源：任一来源按下即视为按下
+type multiInput []InputProvider
+
+func (m multiInput) Input() InputState {
+	var s InputState
+	for _, provider := range m {
+		in := provider.Input()
+		s.Up = s.Up || in.Up
+		s.Down = s.Down || in.Down
+		s.Left = s.Left || in.Left
+		s.Right = s.Right || in.Right
+		s.Bomb = s.Bomb || in.Bomb
+		s.Shove = s.Shove || in.Shove
+		s.Sprint = s.Sprint || in.Sprint
+	}
+	return s
+}
+
+// Input 控制方案的输入：方案的按键加上第 padSlot 个手柄
+func (c ControlScheme) Input(padSlot int) InputProvider {
+	return multiInput{
+		keyboardInput{keys: c.Keys()},
+		gamepadInput{slot: padSlot, buttons: activeControlKeys.Gamepad},
+	}
+}
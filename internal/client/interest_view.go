@@ -0,0 +1,38 @@
+package client
+
+import (
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 兴趣管理（服务器 -interest-radius）：状态只包含本地玩家周围的玩家、炸弹和爆炸。
+// 离开范围的玩家仍在对局中，保留在记分板上、标记为不在视野内而不再绘制，真正离开仍由 PlayerLeft 移除。
+// 炸弹和爆炸每次状态整体同步，不需要额外处理
+
+// markOutOfView 状态中缺少的玩家：开启兴趣管理时视为离开视野，返回 true；否则返回 false（由调用方移除）
+func (ngc *NetworkGameClient) markOutOfView(state *gamev1.GameState, player *Player) bool {
+	if state.InterestRadius <= 0 {
+		return false
+	}
+	player.outOfView = true
+	return true
+}
+
+// onEntityVisibility 服务器通知已有的玩家进出视野。状态与事件分开接收，
+// 早于已应用状态帧的事件已经过时（状态本身反映了更新的视野），忽略
+func (ngc *NetworkGameClient) onEntityVisibility(frameID int32, e *gamev1.EntityVisibilityEvent) {
+	if frameID < ngc.game.coreGame.CurrentFrame {
+		return
+	}
+	set := func(refs []*gamev1.EntityRef, outOfView bool) {
+		for _, ref := range refs {
+			if ref.Kind != gamev1.EntityKind_ENTITY_KIND_PLAYER || int(ref.Id) == ngc.playerID {
+				continue
+			}
+			if player, ok := ngc.playersMap[int(ref.Id)]; ok {
+				player.outOfView = outOfView
+			}
+		}
+	}
+	set(e.Left, true)
+	set(e.Entered, false)
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"image/color"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// itemBobFrames 道具上下浮动的周期（帧）
+const itemBobFrames = core.TPS
+
+var itemBorderColor = color.RGBA{255, 255, 255, 200}
+
+// itemStyle 道具的底色与字母
+func itemStyle(itemType core.ItemType) (color.RGBA, string) {
+	switch itemType {
+	case core.ItemBombUp:
+		return color.RGBA{60, 60, 70, 255}, "B"
+	case core.ItemFireUp:
+		return color.RGBA{220, 90, 30, 255}, "F"
+	case core.ItemSpeedUp:
+		return color.RGBA{40, 160, 220, 255}, "S"
+	case core.ItemKick:
+		return color.RGBA{60, 170, 80, 255}, "K"
+	}
+	return color.RGBA{150, 150, 150, 255}, "?"
+}
+
+// drawItems 绘制地上的道具：带字母的色块，轻微上下浮动
+func (g *Game) drawItems(screen *ebiten.Image) {
+	const inset = 6
+	size := float32(core.TileSize - 2*inset)
+	for _, item := range g.coreGame.Items {
+		bob := float32(0)
+		if (g.coreGame.CurrentFrame-item.SpawnedAtFrame)%itemBobFrames < itemBobFrames/2 {
+			bob = -1
+		}
+		x := float32(item.GridX*core.TileSize + inset)
+		y := float32(item.GridY*core.TileSize+inset) + bob
+		fill, letter := itemStyle(item.Type)
+		vector.DrawFilledRect(screen, x, y, size, size, fill, false)
+		vector.StrokeRect(screen, x, y, size, size, 1, itemBorderColor, false)
+		drawCenteredText(screen, letter, item.GridX*core.TileSize+core.TileSize/2, int(y)+int(size)/2-7, itemBorderColor)
+	}
+}
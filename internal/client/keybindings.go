@@ -0,0 +1,240 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// KeyBindings 一套按键（每个控制方案一套，双人同屏时两名本地玩家各用一套）
+// JSON 中按键使用 ebiten 的按键名，例如 "W"、"ArrowUp"、"Space"、"ShiftRight"
+type KeyBindings struct {
+	Up     ebiten.Key `json:"up"`
+	Down   ebiten.Key `json:"down"`
+	Left   ebiten.Key `json:"left"`
+	Right  ebiten.Key `json:"right"`
+	Bomb   ebiten.Key `json:"bomb"`
+	Shove  ebiten.Key `json:"shove"`
+	Sprint ebiten.Key `json:"sprint"`
+}
+
+// GamepadBindings 手柄按键（所有手柄共用；移动固定为方向键和左摇杆）
+type GamepadBindings struct {
+	Bomb   PadButton `json:"bomb"`
+	Shove  PadButton `json:"shove"`
+	Sprint PadButton `json:"sprint"`
+}
+
+// PadButton 标准布局手柄的按键名（按 Xbox 手柄命名）
+type PadButton string
+
+// padButtonNames 可用的手柄按键名（错误提示用）
+const padButtonNames = "A, B, X, Y, LB, RB, LT, RT, Back, Start"
+
+var padButtons = map[PadButton]ebiten.StandardGamepadButton{
+	"A":     ebiten.StandardGamepadButtonRightBottom,
+	"B":     ebiten.StandardGamepadButtonRightRight,
+	"X":     ebiten.StandardGamepadButtonRightLeft,
+	"Y":     ebiten.StandardGamepadButtonRightTop,
+	"LB":    ebiten.StandardGamepadButtonFrontTopLeft,
+	"RB":    ebiten.StandardGamepadButtonFrontTopRight,
+	"LT":    ebiten.StandardGamepadButtonFrontBottomLeft,
+	"RT":    ebiten.StandardGamepadButtonFrontBottomRight,
+	"Back":  ebiten.StandardGamepadButtonCenterLeft,
+	"Start": ebiten.StandardGamepadButtonCenterRight,
+}
+
+func (b PadButton) button() ebiten.StandardGamepadButton {
+	return padButtons[b]
+}
+
+// ControlKeys 两个控制方案的按键和手柄按键（客户端配置的 keys 字段）
+type ControlKeys struct {
+	WASD    KeyBindings     `json:"wasd"`
+	Arrow   KeyBindings     `json:"arrow"`
+	Gamepad GamepadBindings `json:"gamepad"`
+}
+
+// DefaultControlKeys 默认按键
+func DefaultControlKeys() ControlKeys {
+	return ControlKeys{
+		WASD: KeyBindings{
+			Up: ebiten.KeyW, Down: ebiten.KeyS, Left: ebiten.KeyA, Right: ebiten.KeyD,
+			Bomb: ebiten.KeySpace, Shove: ebiten.KeyE, Sprint: ebiten.KeyShiftLeft,
+		},
+		Arrow: KeyBindings{
+			Up: ebiten.KeyArrowUp, Down: ebiten.KeyArrowDown, Left: ebiten.KeyArrowLeft, Right: ebiten.KeyArrowRight,
+			Bomb: ebiten.KeyEnter, Shove: ebiten.KeyShiftRight, Sprint: ebiten.KeyControlRight,
+		},
+		Gamepad: GamepadBindings{Bomb: "A", Shove: "B", Sprint: "RT"},
+	}
+}
+
+// LoadControlKeys 读取按键文件（格式同客户端配置的 keys 字段），文件中缺少的按键使用默认值
+func LoadControlKeys(path string) (ControlKeys, error) {
+	keys := DefaultControlKeys()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return keys, err
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return DefaultControlKeys(), err
+	}
+	return keys, nil
+}
+
+// SaveControlKeys 写入按键文件
+func SaveControlKeys(path string, keys ControlKeys) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// activeControlKeys 当前生效的按键（启动时设置）
+var activeControlKeys = DefaultControlKeys()
+
+// SetControlKeys 校验并启用按键配置
+func SetControlKeys(keys ControlKeys) error {
+	if err := keys.Validate(); err != nil {
+		return err
+	}
+	activeControlKeys = keys
+	return nil
+}
+
+// Keys 控制方案当前的按键
+func (c ControlScheme) Keys() KeyBindings {
+	if c == ControlArrow {
+		return activeControlKeys.Arrow
+	}
+	return activeControlKeys.WASD
+}
+
+// bindingSlot 按键配置中的一个位置，例如 wasd.bomb
+type bindingSlot struct {
+	name string
+	key  *ebiten.Key
+}
+
+func (k *ControlKeys) slots() []bindingSlot {
+	var slots []bindingSlot
+	for _, scheme := range []struct {
+		name     string
+		bindings *KeyBindings
+	}{{"wasd", &k.WASD}, {"arrow", &k.Arrow}} {
+		b := scheme.bindings
+		slots = append(slots,
+			bindingSlot{scheme.name + ".up", &b.Up},
+			bindingSlot{scheme.name + ".down", &b.Down},
+			bindingSlot{scheme.name + ".left", &b.Left},
+			bindingSlot{scheme.name + ".right", &b.Right},
+			bindingSlot{scheme.name + ".bomb", &b.Bomb},
+			bindingSlot{scheme.name + ".shove", &b.Shove},
+			bindingSlot{scheme.name + ".sprint", &b.Sprint},
+		)
+	}
+	return slots
+}
+
+// Validate 检查按键冲突：两名本地玩家（以及同一玩家的不同动作）不能共用一个键
+func (k ControlKeys) Validate() error {
+	used := make(map[ebiten.Key]string)
+	var conflicts []string
+	for _, slot := range k.slots() {
+		if other, ok := used[*slot.key]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s 与 %s 都是 %s", other, slot.name, *slot.key))
+			continue
+		}
+		used[*slot.key] = slot.name
+	}
+	usedPad := make(map[PadButton]string)
+	for _, slot := range []struct {
+		name   string
+		button PadButton
+	}{{"pad.bomb", k.Gamepad.Bomb}, {"pad.shove", k.Gamepad.Shove}, {"pad.sprint", k.Gamepad.Sprint}} {
+		if _, ok := padButtons[slot.button]; !ok {
+			return fmt.Errorf("未知手柄按键 %q（可选: %s）", slot.button, padButtonNames)
+		}
+		if other, ok := usedPad[slot.button]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("%s 与 %s 都是 %s", other, slot.name, slot.button))
+			continue
+		}
+		usedPad[slot.button] = slot.name
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("按键冲突: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// Rebind 按 "wasd.bomb=J,arrow.shove=Numpad0,pad.bomb=X" 格式修改按键（不做冲突检查）
+func (k *ControlKeys) Rebind(spec string) error {
+	slots := k.slots()
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, keyName, ok := strings.Cut(item, "=")
+		if !ok {
+			return fmt.Errorf("无效的按键设置 %q（格式: wasd.bomb=J）", item)
+		}
+		name, keyName = strings.TrimSpace(name), strings.TrimSpace(keyName)
+		if action, ok := strings.CutPrefix(strings.ToLower(name), "pad."); ok {
+			if err := k.Gamepad.rebind(action, keyName); err != nil {
+				return err
+			}
+			continue
+		}
+		var key ebiten.Key
+		if err := key.UnmarshalText([]byte(keyName)); err != nil {
+			return fmt.Errorf("未知按键 %q", keyName)
+		}
+		found := false
+		for _, slot := range slots {
+			if strings.EqualFold(slot.name, name) {
+				*slot.key = key
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("未知动作 %q（可选: wasd/arrow . up/down/left/right/bomb/shove/sprint，pad . bomb/shove/sprint）", name)
+		}
+	}
+	return nil
+}
+
+// rebind 修改一个手柄按键（按键名不区分大小写）
+func (g *GamepadBindings) rebind(action, buttonName string) error {
+	var button PadButton
+	for b := range padButtons {
+		if strings.EqualFold(string(b), buttonName) {
+			button = b
+			break
+		}
+	}
+	if button == "" {
+		return fmt.Errorf("未知手柄按键 %q（可选: %s）", buttonName, padButtonNames)
+	}
+	switch action {
+	case "bomb":
+		g.Bomb = button
+	case "shove":
+		g.Shove = button
+	case "sprint":
+		g.Sprint = button
+	default:
+		return fmt.Errorf("未知手柄动作 %q（可选: bomb/shove/sprint）", action)
+	}
+	return nil
+}
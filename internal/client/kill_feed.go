@@ -0,0 +1,80 @@
+package client
+
+import (
+	"image/color"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	killFeedDurationFrames = 4 * core.TPS
+	killFeedMaxEntries     = 4
+)
+
+var killFeedColor = color.RGBA{255, 170, 120, 255}
+
+type killFeedEntry struct {
+	text  string
+	until int32
+}
+
+// KillFeed 右上角的击杀播报（击杀者为炸弹最后的接触者）
+type KillFeed struct {
+	entries []killFeedEntry
+}
+
+// Add 记录一条死亡事件
+func (k *KillFeed) Add(e *gamev1.PlayerDiedEvent, localID int32, frame int32) {
+	victim := playerLabel(e.PlayerId)
+	if e.PlayerId == localID {
+		victim = "You"
+	}
+
+	var text string
+	switch {
+	case e.KillerId == core.KillerHazard:
+		text = victim + " fell into lava"
+	case e.KillerId == core.KillerSuddenDeath:
+		text = victim + " was crushed by a wall"
+	case e.KillerId == core.KillerOvertime:
+		text = victim + " was swept away by the flood"
+	case e.KillerId == core.KillerBoss:
+		text = "The boss crushed " + victim
+	case e.KillerId < 0:
+		text = victim + " self-destructed"
+	case e.KillerId == localID:
+		text = "You blew up " + victim
+	default:
+		text = playerLabel(e.KillerId) + " blew up " + victim
+	}
+
+	k.entries = append(k.entries, killFeedEntry{text: text, until: frame + killFeedDurationFrames})
+	if len(k.entries) > killFeedMaxEntries {
+		k.entries = k.entries[len(k.entries)-killFeedMaxEntries:]
+	}
+}
+
+// Draw 绘制未过期的播报，最新的在最下面
+func (k *KillFeed) Draw(screen *ebiten.Image, frame int32) {
+	active := k.entries[:0]
+	for _, entry := range k.entries {
+		if frame < entry.until {
+			active = append(active, entry)
+		}
+	}
+	k.entries = active
+
+	y := 26
+	for _, entry := range k.entries {
+		drawText(screen, ScreenWidth-textWidth(entry.text)-8, y, entry.text, killFeedColor)
+		y += 14
+	}
+}
+
+// playerLabel 对局内玩家的显示名（与结算界面一致）
+func playerLabel(id int32) string {
+	return "Player " + string(rune('A'+id))
+}
@@ -1,36 +1,37 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 	"image/color"
 	"time"
 
 	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+	"bomberman/pkg/resources"
+	"bomberman/pkg/version"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
-	"golang.org/x/image/font/basicfont"
 )
 
-var lobbyFont = text.NewGoXFace(basicfont.Face7x13)
-
 // UI Color Palette
+// 背景和面板底色来自当前主题（见 theme.go）
 var (
-	uiBackground      = color.RGBA{12, 16, 24, 255}
-	uiPanelBackground = color.RGBA{24, 28, 36, 255}
-	uiPanelBorder     = color.RGBA{60, 70, 85, 255}
-	uiTextPrimary     = color.RGBA{230, 235, 245, 255}
-	uiTextSecondary   = color.RGBA{150, 160, 175, 255}
-	uiTextMuted       = color.RGBA{100, 110, 125, 255}
-	uiAccent          = color.RGBA{255, 200, 80, 255}
-	uiAccentDim       = color.RGBA{180, 140, 55, 255}
-	uiSuccess         = color.RGBA{80, 200, 120, 255}
-	uiWarning         = color.RGBA{230, 180, 80, 255}
-	uiError           = color.RGBA{230, 90, 90, 255}
-	uiRoomWaiting     = color.RGBA{80, 180, 220, 255}
-	uiRoomPlaying     = color.RGBA{220, 100, 100, 255}
-	uiRoomFull        = color.RGBA{140, 140, 160, 255}
+	uiPanelBorder   = color.RGBA{60, 70, 85, 255}
+	uiTextPrimary   = color.RGBA{230, 235, 245, 255}
+	uiTextSecondary = color.RGBA{150, 160, 175, 255}
+	uiTextMuted     = color.RGBA{100, 110, 125, 255}
+	uiAccent        = color.RGBA{255, 200, 80, 255}
+	uiAccentDim     = color.RGBA{180, 140, 55, 255}
+	uiSuccess       = color.RGBA{80, 200, 120, 255}
+	uiWarning       = color.RGBA{230, 180, 80, 255}
+	uiError         = color.RGBA{230, 90, 90, 255}
+	uiRoomWaiting   = color.RGBA{80, 180, 220, 255}
+	uiRoomPlaying   = color.RGBA{220, 100, 100, 255}
+	uiRoomFull      = color.RGBA{140, 140, 160, 255}
 )
 
 // UI Layout Constants
@@ -77,11 +78,15 @@ type LobbyClient struct {
 	controlScheme  ControlScheme
 	screen         lobbyScreen
 	roomList       []*gamev1.RoomInfo
+	listView       roomListView                          // 房间列表的页码与过滤条件（room_list.go）
+	roomListReq    *Future[*gamev1.RoomListResponse]     // 最近一次房间列表请求（更早的响应不再显示）
+	pendingActions []*Future[*gamev1.RoomActionResponse] // 等待响应的房间操作，按发送顺序处理
 	roomState      *gamev1.RoomStateUpdate
 	selectedIndex  int
 	lastListFetch  time.Time
 	lastError      string
 	joinInFlight   bool
+	joinMode       joinMode // 当前进行中的加入请求身份
 	joinResultChan chan joinResult
 	input          keyTracker
 	// Room creation input state
@@ -91,6 +96,30 @@ type LobbyClient struct {
 	// Toast notification
 	toastMessage string
 	toastTimer   float32
+	// Waiting room map thumbnail
+	mapPreview MapPreview
+	// HUD hidden state, carried across matches
+	hudHidden bool
+	// Script source sent with ADD_AI (debug servers only), empty for normal AI
+	aiScript string
+	// Difficulty of AI players added with the A key (V cycles)
+	aiDifficulty gamev1.AIDifficulty
+	// Latest nudge while a single unready player holds up the room
+	readyNudge *gamev1.ReadyNudgeEvent
+	// Room chat: recent messages and events, and the line being typed
+	chatLog    []string
+	chatMode   bool
+	chatBuffer string
+	// Index of the proposal that Tab selected for voting (room_vote.go)
+	selectedProposal int
+	// Caster mode: spectate casterRoom (or any running room) without input
+	caster         bool
+	casterRoom     string
+	casterRetryAt  time.Time
+	casterResultAt time.Time
+	// Fresh connection after giving up on an in-game reconnect
+	redialing    bool
+	redialResult chan error
 
 	game *NetworkGameClient
 }
@@ -101,9 +130,28 @@ func NewLobbyClient(network *NetworkClient, controlScheme ControlScheme) *LobbyC
 		controlScheme:  controlScheme,
 		screen:         screenLobby,
 		joinResultChan: make(chan joinResult, 1),
+		redialResult:   make(chan error, 1),
+		aiDifficulty:   gamev1.AIDifficulty_AI_DIFFICULTY_NORMAL,
 	}
 }
 
+// SetHUDHidden sets whether matches start with the HUD hidden
+func (lc *LobbyClient) SetHUDHidden(hidden bool) {
+	lc.hudHidden = hidden
+}
+
+// SetCaster turns on caster mode: the client keeps spectating roomID (or
+// the first running room when empty) and shows a clean scoreboard view
+func (lc *LobbyClient) SetCaster(roomID string) {
+	lc.caster = true
+	lc.casterRoom = roomID
+}
+
+// SetAIScript makes the A key add scripted AI players running src
+func (lc *LobbyClient) SetAIScript(src string) {
+	lc.aiScript = src
+}
+
 func (lc *LobbyClient) Update() error {
 	// Update toast timer
 	if lc.toastTimer > 0 {
@@ -113,6 +161,7 @@ func (lc *LobbyClient) Update() error {
 		}
 	}
 
+	setLobbyMusic(lc.screen != screenGame)
 	switch lc.screen {
 	case screenLobby:
 		lc.updateLobby()
@@ -134,6 +183,7 @@ func (lc *LobbyClient) Draw(screen *ebiten.Image) {
 		if lc.game != nil {
 			lc.game.Draw(screen)
 		}
+		lc.drawToast(screen) // 对局中房间操作的错误
 	}
 }
 
@@ -151,45 +201,71 @@ func (lc *LobbyClient) updateLobby() {
 		return
 	}
 
-	if time.Since(lc.lastListFetch) > time.Second {
-		_ = lc.network.RequestRoomList(1, 20)
-		lc.lastListFetch = time.Now()
+	if lc.updateRedial() {
+		return
+	}
+
+	// Wait for the in-flight list request (so the list still updates when latency exceeds the
+	// refresh interval), except right after returning to the lobby
+	if time.Since(lc.lastListFetch) > time.Second && (lc.roomListReq == nil || lc.lastListFetch.IsZero()) {
+		lc.requestRoomList()
 	}
 
 	for {
-		resp := lc.network.ReceiveRoomList()
-		if resp == nil {
+		notice := lc.network.ReceiveNotice()
+		if notice == nil {
 			break
 		}
-		lc.roomList = resp.Rooms
-		if lc.selectedIndex >= len(lc.roomList) {
-			lc.selectedIndex = 0
+		switch notice.Type {
+		case gamev1.NoticeType_NOTICE_TYPE_IDLE_WARNING:
+			lc.showToast(fmt.Sprintf("Idle: disconnecting in %ds", notice.SecondsRemaining), uiWarning)
+		case gamev1.NoticeType_NOTICE_TYPE_IDLE_DISCONNECT:
+			lc.lastError = "Disconnected: idle too long"
 		}
 	}
 
+	lc.pollRoomList()
+
+	if lc.caster {
+		lc.autoSpectate()
+	}
+
 	select {
 	case res := <-lc.joinResultChan:
 		lc.joinInFlight = false
 		if res.err != nil {
-			lc.lastError = res.err.Error()
+			lc.lastError = friendlyJoinError(res.err)
 			break
 		}
 		lc.lastError = ""
+		if scheme, ok := lc.network.StoredControl(); ok {
+			lc.controlScheme = scheme
+		}
 		if res.resp != nil {
 			lc.roomState = res.resp.RoomState
+			lc.setRoomHistory(res.resp.History)
+			lc.closeChat()
 			lc.screen = screenRoom
+			// 观战或接管进行中的对局：直接进入游戏画面
+			if lc.roomState != nil && lc.roomState.Status == gamev1.RoomStatus_ROOM_STATUS_PLAYING {
+				lc.enterGame()
+			}
 		}
 	default:
 	}
 
 	if lc.input.JustPressed(ebiten.KeyR) {
-		_ = lc.network.RequestRoomList(1, 20)
-		lc.lastListFetch = time.Now()
+		lc.requestRoomList()
 	}
+	lc.updateRoomListKeys()
 
 	if lc.input.JustPressed(ebiten.KeyQ) {
 		lc.startJoin("")
 	}
+	if lc.input.JustPressed(ebiten.KeyP) {
+		cycleTheme()
+		lc.showToast("Theme: "+ActiveTheme().Name, uiTextSecondary)
+	}
 	if lc.input.JustPressed(ebiten.KeyC) {
 		lc.inputMode = true
 		lc.inputBuffer = ""
@@ -211,6 +287,18 @@ func (lc *LobbyClient) updateLobby() {
 			lc.startJoin(room.Id)
 		}
 	}
+	if lc.input.JustPressed(ebiten.KeyV) && lc.selectedIndex >= 0 && lc.selectedIndex < len(lc.roomList) {
+		room := lc.roomList[lc.selectedIndex]
+		if room != nil {
+			lc.startSpectate(room.Id)
+		}
+	}
+	if lc.input.JustPressed(ebiten.KeyT) && lc.selectedIndex >= 0 && lc.selectedIndex < len(lc.roomList) {
+		room := lc.roomList[lc.selectedIndex]
+		if room != nil && room.Status == gamev1.RoomStatus_ROOM_STATUS_PLAYING {
+			lc.beginJoin(room.Id, joinAsTakeover)
+		}
+	}
 }
 
 func (lc *LobbyClient) handleInputMode() {
@@ -255,40 +343,51 @@ func (lc *LobbyClient) updateRoom() {
 		}
 		lc.roomState = update
 	}
+	lc.clearStaleReadyNudge()
 
-	for {
-		resp := lc.network.ReceiveRoomActionResponse()
-		if resp == nil {
-			break
-		}
-		if !resp.Success {
-			lc.lastError = resp.ErrorMessage
-			lc.showToast(resp.ErrorMessage, uiError)
-			continue
-		}
-		lc.lastError = ""
-		if resp.RoomId == "" {
-			lc.roomState = nil
-			lc.screen = screenLobby
-			lc.lastListFetch = time.Time{}
-		}
-	}
+	lc.pollRoomActions()
 
 	for {
 		event := lc.network.ReceiveEvent()
 		if event == nil {
 			break
 		}
-		if _, ok := event.Event.(*gamev1.GameEvent_GameStart); ok {
-			gameClient, err := NewNetworkGameClient(lc.network, lc.controlScheme)
-			if err == nil {
-				lc.game = gameClient
-				lc.screen = screenGame
-			} else {
-				lc.lastError = err.Error()
-			}
+		switch e := event.Event.(type) {
+		case *gamev1.GameEvent_GameStart:
+			lc.readyNudge = nil
+			lc.closeChat()
+			lc.enterGame()
+		case *gamev1.GameEvent_SpectatorJoined:
+			lc.showToast(e.SpectatorJoined.Name+" is watching", uiTextSecondary)
+		case *gamev1.GameEvent_RoomIdleWarning:
+			lc.showToast(fmt.Sprintf("Room idle: closing in %ds unless someone acts", e.RoomIdleWarning.SecondsRemaining), uiWarning)
+		case *gamev1.GameEvent_ReadyNudge:
+			lc.handleReadyNudge(e.ReadyNudge)
+		case *gamev1.GameEvent_HostChanged:
+			lc.showToast(hostChangedText(e.HostChanged, lc.network.GetPlayerID()), uiTextSecondary)
+		case *gamev1.GameEvent_RoomHistory:
+			lc.appendRoomHistory(e.RoomHistory)
 		}
 	}
+	if history, ok := lc.network.TakeRoomHistory(); ok {
+		lc.setRoomHistory(history)
+	}
+
+	if lc.chatMode {
+		lc.handleChatInput()
+		return
+	}
+	if lc.input.JustPressed(ebiten.KeySlash) {
+		lc.chatMode = true
+		return
+	}
+
+	if lc.network.IsSpectating() {
+		if lc.input.JustPressed(ebiten.KeyL) || lc.input.JustPressed(ebiten.KeyEscape) {
+			lc.leaveRoom()
+		}
+		return
+	}
 
 	if lc.input.JustPressed(ebiten.KeySpace) {
 		lc.toggleReady()
@@ -299,8 +398,56 @@ func (lc *LobbyClient) updateRoom() {
 	if lc.input.JustPressed(ebiten.KeyA) {
 		lc.addAI(1)
 	}
+	if lc.input.JustPressed(ebiten.KeyV) {
+		lc.cycleAIDifficulty()
+	}
+	if lc.input.JustPressed(ebiten.KeyM) {
+		lc.rerollSeed()
+	}
+	if lc.input.JustPressed(ebiten.KeyN) {
+		lc.cycleMap()
+	}
+	if lc.input.JustPressed(ebiten.KeyD) {
+		lc.toggleDoorCampPing()
+	}
+	if lc.input.JustPressed(ebiten.KeyC) {
+		lc.togglePlayerCollision()
+	}
+	if lc.input.JustPressed(ebiten.KeyH) {
+		lc.toggleMapHazards()
+	}
+	if lc.input.JustPressed(ebiten.KeyX) {
+		lc.toggleSuddenDeath()
+	}
+	if lc.input.JustPressed(ebiten.KeyG) {
+		lc.toggleFairSeed()
+	}
+	if lc.input.JustPressed(ebiten.KeyE) {
+		lc.toggleTeams()
+	}
+	if lc.input.JustPressed(ebiten.KeyY) {
+		lc.toggleFriendlyFire()
+	}
+	if lc.input.JustPressed(ebiten.KeyO) {
+		lc.toggleDoorOvertime()
+	}
+	if lc.input.JustPressed(ebiten.KeyB) {
+		lc.toggleBossMode()
+	}
+	if lc.input.JustPressed(ebiten.KeyT) {
+		lc.switchTeam()
+	}
+	for i, key := range matchConfigKeys {
+		if lc.input.JustPressed(key) {
+			lc.cycleMatchConfig(i)
+		}
+	}
+	if lc.input.JustPressed(ebiten.KeyF) {
+		lc.startWithoutUnready()
+	}
+	lc.updateProposalKeys()
 	if lc.input.JustPressed(ebiten.KeyL) || lc.input.JustPressed(ebiten.KeyEscape) {
-		_ = lc.network.LeaveRoom()
+		lc.leaveRoom()
 	}
 }
 
@@ -310,8 +457,30 @@ func (lc *LobbyClient) updateGame() {
 		return
 	}
 	_ = lc.game.Update()
+	// 开局前发出的操作和服务器主动发送的响应在对局中也要处理，不能等回到房间才按过期丢弃
+	lc.pollRoomActions()
+	if lc.screen != screenGame {
+		// 对局中被移出房间（被踢出等），回到大厅
+		lc.hudHidden = lc.game.game.HUDHidden()
+		lc.game = nil
+		return
+	}
+	if lc.game.Abandoned() {
+		lc.abandonGame()
+		return
+	}
+	if lc.game.NextRoundStarted() {
+		lc.hudHidden = lc.game.game.HUDHidden()
+		lc.enterGame()
+		return
+	}
 	if lc.game.game.gameOver {
-		if lc.input.JustPressed(ebiten.KeySpace) || lc.input.JustPressed(ebiten.KeyEnter) {
+		if lc.caster && lc.casterResultAt.IsZero() {
+			lc.casterResultAt = time.Now().Add(casterResultDelay)
+		}
+		casterDone := lc.caster && time.Now().After(lc.casterResultAt)
+		if casterDone || lc.input.JustPressed(ebiten.KeySpace) || lc.input.JustPressed(ebiten.KeyEnter) {
+			lc.hudHidden = lc.game.game.HUDHidden()
 			lc.game = nil
 			lc.screen = screenRoom
 		}
@@ -319,14 +488,58 @@ func (lc *LobbyClient) updateGame() {
 	}
 }
 
+// enterGame 创建对局客户端并切换到游戏画面
+func (lc *LobbyClient) enterGame() {
+	gameClient, err := NewNetworkGameClient(lc.network, lc.controlScheme)
+	if err != nil {
+		lc.lastError = err.Error()
+		return
+	}
+	if lc.roomState != nil {
+		gameClient.game.spectatorCount = lc.roomState.SpectatorCount
+		gameClient.game.nameTags = nameTagsFromRoom(lc.roomState.Players)
+	}
+	gameClient.game.SetHUDHidden(lc.hudHidden)
+	gameClient.game.series = lc.network.SeriesScore()
+	gameClient.showSeedCheck(lc.network.SeedCheck())
+	if lc.caster {
+		gameClient.SetCaster()
+		lc.casterResultAt = time.Time{}
+	}
+	lc.game = gameClient
+	lc.screen = screenGame
+}
+
 func (lc *LobbyClient) startJoin(roomID string) {
+	lc.beginJoin(roomID, joinAsPlayer)
+}
+
+func (lc *LobbyClient) startSpectate(roomID string) {
+	lc.beginJoin(roomID, joinAsSpectator)
+}
+
+func (lc *LobbyClient) beginJoin(roomID string, mode joinMode) {
 	if lc.joinInFlight {
 		return
 	}
 	lc.joinInFlight = true
+	lc.joinMode = mode
 	lc.lastError = ""
 	go func() {
-		resp, err := lc.network.JoinRoom(roomID)
+		var resp *gamev1.JoinResponse
+		var err error
+		switch mode {
+		case joinAsSpectator:
+			resp, err = lc.network.SpectateRoom(roomID)
+		case joinAsTakeover:
+			resp, err = lc.network.TakeOverAI(roomID)
+		default:
+			resp, err = lc.network.JoinRoom(roomID)
+			// 快速加入时目标房间可能刚好被抢满/开局，重新请求让服务器挑选下一个候选房间
+			for attempt := 1; roomID == "" && isRetryableJoinError(err) && attempt < quickJoinMaxAttempts; attempt++ {
+				resp, err = lc.network.JoinRoom(roomID)
+			}
+		}
 		select {
 		case lc.joinResultChan <- joinResult{resp: resp, err: err}:
 		default:
@@ -350,7 +563,7 @@ func (lc *LobbyClient) toggleReady() {
 		Type:  gamev1.RoomActionType_ROOM_ACTION_READY,
 		Ready: !ready,
 	}
-	_ = lc.network.SendRoomAction(action)
+	lc.sendRoomAction(action)
 }
 
 func (lc *LobbyClient) startGame() {
@@ -363,7 +576,7 @@ func (lc *LobbyClient) startGame() {
 	action := &gamev1.RoomAction{
 		Type: gamev1.RoomActionType_ROOM_ACTION_START,
 	}
-	_ = lc.network.SendRoomAction(action)
+	lc.sendRoomAction(action)
 }
 
 func (lc *LobbyClient) addAI(count int32) {
@@ -374,10 +587,246 @@ func (lc *LobbyClient) addAI(count int32) {
 		return
 	}
 	action := &gamev1.RoomAction{
-		Type:    gamev1.RoomActionType_ROOM_ACTION_ADD_AI,
-		AiCount: count,
+		Type:         gamev1.RoomActionType_ROOM_ACTION_ADD_AI,
+		AiCount:      count,
+		AiScript:     lc.aiScript,
+		AiDifficulty: lc.aiDifficulty,
+	}
+	lc.sendRoomAction(action)
+}
+
+// cycleAIDifficulty picks the difficulty for the next AI added (Easy -> Normal -> Hard)
+func (lc *LobbyClient) cycleAIDifficulty() {
+	switch lc.aiDifficulty {
+	case gamev1.AIDifficulty_AI_DIFFICULTY_EASY:
+		lc.aiDifficulty = gamev1.AIDifficulty_AI_DIFFICULTY_NORMAL
+	case gamev1.AIDifficulty_AI_DIFFICULTY_NORMAL:
+		lc.aiDifficulty = gamev1.AIDifficulty_AI_DIFFICULTY_HARD
+	default:
+		lc.aiDifficulty = gamev1.AIDifficulty_AI_DIFFICULTY_EASY
+	}
+}
+
+// cycleMap switches to the next map: the built-in layouts first, then the
+// approved community maps; other players propose the map instead
+func (lc *LobbyClient) cycleMap() {
+	if lc.roomState == nil {
+		return
+	}
+	choices := append(core.BuiltinMapIDs(), lc.roomState.MapChoices...)
+	current := core.MapLayout(lc.roomState.MapId)
+	if lc.roomState.CustomMap != "" {
+		current = lc.roomState.CustomMap
+	}
+	next := 0
+	for i, name := range choices {
+		if name == current {
+			next = (i + 1) % len(choices)
+			break
+		}
+	}
+	if !lc.isHost() {
+		lc.proposeMap(choices[next])
+		return
+	}
+	action := &gamev1.RoomAction{
+		Type:    gamev1.RoomActionType_ROOM_ACTION_SET_MAP,
+		MapName: choices[next],
+	}
+	lc.sendRoomAction(action)
+}
+
+func (lc *LobbyClient) rerollSeed() {
+	if lc.roomState == nil {
+		return
+	}
+	if lc.roomState.HostId != lc.network.GetPlayerID() {
+		return
+	}
+	action := &gamev1.RoomAction{
+		Type: gamev1.RoomActionType_ROOM_ACTION_REROLL_SEED,
+	}
+	lc.sendRoomAction(action)
+}
+
+// setRules 房主修改房间规则（只修改传入的字段，其余沿用当前规则），其他玩家发出提议
+func (lc *LobbyClient) setRules(update func(rules *core.GameRules)) {
+	if lc.roomState == nil {
+		return
+	}
+	rules := protocol.ProtoRulesToCore(lc.roomState.Rules)
+	update(&rules)
+	if !lc.isHost() {
+		lc.proposeRules(rules)
+		return
+	}
+	action := &gamev1.RoomAction{
+		Type:  gamev1.RoomActionType_ROOM_ACTION_SET_RULES,
+		Rules: protocol.CoreRulesToProto(rules),
+	}
+	lc.sendRoomAction(action)
+}
+
+func (lc *LobbyClient) toggleDoorCampPing() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.DoorCampPing = !rules.DoorCampPing
+	})
+}
+
+func (lc *LobbyClient) togglePlayerCollision() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.PlayerCollision = !rules.PlayerCollision
+	})
+}
+
+func (lc *LobbyClient) toggleMapHazards() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.MapHazards = !rules.MapHazards
+	})
+}
+
+func (lc *LobbyClient) toggleSuddenDeath() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.SuddenDeath = !rules.SuddenDeath
+	})
+}
+
+func (lc *LobbyClient) toggleFairSeed() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.FairSeed = !rules.FairSeed
+	})
+}
+
+func (lc *LobbyClient) toggleTeams() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.Teams = !rules.Teams
+	})
+}
+
+func (lc *LobbyClient) toggleFriendlyFire() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.FriendlyFire = !rules.FriendlyFire
+	})
+}
+
+func (lc *LobbyClient) toggleDoorOvertime() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.DoorOvertime = !rules.DoorOvertime
+	})
+}
+
+func (lc *LobbyClient) toggleBossMode() {
+	lc.setRules(func(rules *core.GameRules) {
+		rules.BossMode = !rules.BossMode
+	})
+}
+
+// matchConfigKeys 房主循环切换对局参数的按键，顺序与 cycleMatchConfig 的字段一致
+var matchConfigKeys = []ebiten.Key{ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3, ebiten.KeyDigit4, ebiten.KeyDigit5, ebiten.KeyDigit6}
+
+// 对局参数的可选值（按键循环切换，服务器会再限制范围）
+var (
+	fuseOptions     = []int32{2 * core.TPS, 3 * core.TPS, 4 * core.TPS}
+	speedOptions    = []float64{1.5, core.PlayerSpeedPerFrame, 2.5}
+	rangeOptions    = []int{1, 2, 3, 4}
+	maxBombsOptions = []int{1, 2, 3, 4}
+	durationOptions = []int32{60 * core.TPS, 120 * core.TPS, 180 * core.TPS, 300 * core.TPS, 0}
+	roundsOptions   = []int32{1, 2, 3}
+)
+
+// nextOption 返回 current 之后的下一个可选值（不在列表中时返回第一个）
+func nextOption[T comparable](options []T, current T) T {
+	for i, v := range options {
+		if v == current {
+			return options[(i+1)%len(options)]
+		}
+	}
+	return options[0]
+}
+
+// cycleMatchConfig 房主切换第 field 项对局参数（0 引信，1 速度，2 范围，3 炸弹数，4 时长，5 回合数）
+func (lc *LobbyClient) cycleMatchConfig(field int) {
+	if lc.roomState == nil {
+		return
+	}
+	if lc.roomState.HostId != lc.network.GetPlayerID() {
+		return
+	}
+	config := protocol.ProtoMatchConfigToCore(lc.roomState.Config)
+	switch field {
+	case 0:
+		config.BombFuseFrames = nextOption(fuseOptions, config.BombFuseFrames)
+	case 1:
+		config.PlayerSpeed = nextOption(speedOptions, config.PlayerSpeed)
+	case 2:
+		config.BombRange = nextOption(rangeOptions, config.BombRange)
+	case 3:
+		config.MaxBombs = nextOption(maxBombsOptions, config.MaxBombs)
+	case 4:
+		config.MatchDurationFrames = nextOption(durationOptions, config.MatchDurationFrames)
+	case 5:
+		config.RoundsToWin = nextOption(roundsOptions, max(config.RoundsToWin, 1))
+	}
+	action := &gamev1.RoomAction{
+		Type:   gamev1.RoomActionType_ROOM_ACTION_SET_CONFIG,
+		Config: protocol.CoreMatchConfigToProto(config),
 	}
-	_ = lc.network.SendRoomAction(action)
+	lc.sendRoomAction(action)
+}
+
+// matchConfigText formats the match settings on one line
+func matchConfigText(config core.MatchConfig) string {
+	duration := "No limit"
+	if config.MatchDurationFrames > 0 {
+		seconds := int(config.MatchDurationFrames) / core.TPS
+		duration = fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+	}
+	rounds := "" // 多回合对局显示 FT<N>（先赢 N 回合）
+	if config.RoundsToWin > 1 {
+		rounds = fmt.Sprintf(" FT%d", config.RoundsToWin)
+	}
+	return fmt.Sprintf("[1-6] Fuse %.1fs Spd %.1f Rng %d Bombs %d %s%s",
+		core.FramesToSeconds(int(config.BombFuseFrames)), config.PlayerSpeed, config.BombRange, config.MaxBombs, duration, rounds)
+}
+
+// switchTeam 组队模式下换到另一队
+func (lc *LobbyClient) switchTeam() {
+	if lc.roomState == nil || !lc.roomState.GetRules().GetTeams() {
+		return
+	}
+	team := int32(core.TeamA)
+	for _, player := range lc.roomState.Players {
+		if player.Id == lc.network.GetPlayerID() && player.Team == core.TeamA {
+			team = core.TeamB
+		}
+	}
+	action := &gamev1.RoomAction{
+		Type: gamev1.RoomActionType_ROOM_ACTION_SET_TEAM,
+		Team: team,
+	}
+	lc.sendRoomAction(action)
+}
+
+// quickJoinMaxAttempts 快速加入的最大尝试次数
+const quickJoinMaxAttempts = 3
+
+// isRetryableJoinError 房间已满/已开局的竞争失败可以换一个房间重试
+func isRetryableJoinError(err error) bool {
+	var joinErr *JoinError
+	if !errors.As(err, &joinErr) {
+		return false
+	}
+	return joinErr.Code == gamev1.ErrorCode_ERROR_CODE_ROOM_FULL ||
+		joinErr.Code == gamev1.ErrorCode_ERROR_CODE_ROOM_PLAYING
+}
+
+// friendlyJoinError converts a join failure into a user-facing message
+func friendlyJoinError(err error) string {
+	var joinErr *JoinError
+	if errors.As(err, &joinErr) {
+		return friendlyErrorMessage(joinErr.Code, joinErr.Params, joinErr.Message)
+	}
+	return err.Error()
 }
 
 // showToast displays a toast notification message
@@ -425,12 +874,14 @@ func (lc *LobbyClient) drawToast(screen *ebiten.Image) {
 
 func (lc *LobbyClient) drawLobby(screen *ebiten.Image) {
 	// Background
-	screen.Fill(uiBackground)
+	screen.Fill(ActiveTheme().Background)
 
 	// Header panel
 	drawPanel(screen, 0, 0, ScreenWidth, 64)
 	drawText(screen, uiPanelPadding, 18, "LOBBY", uiTextPrimary)
-	drawText(screen, uiPanelPadding, 38, "Q:Quick  C:Create  R:Refresh  Enter:Join  W/S:Navigate", uiTextSecondary)
+	versionText := "v" + version.Game
+	drawText(screen, ScreenWidth-uiPanelPadding-textWidth(versionText), 18, versionText, uiTextMuted)
+	drawText(screen, uiPanelPadding, 38, "Q:Quick  C:Create  R:Refresh  Enter:Join  V:Watch  T:TakeOver  P:Theme  W/S:Navigate", uiTextSecondary)
 
 	// Room list panel
 	panelX := uiPanelMargin
@@ -444,6 +895,8 @@ func (lc *LobbyClient) drawLobby(screen *ebiten.Image) {
 	drawText(screen, panelX+uiPanelPadding+12, headerY, "ROOM", uiTextMuted)
 	drawText(screen, panelX+uiPanelPadding+200, headerY, "PLAYERS", uiTextMuted)
 	drawText(screen, panelX+uiPanelPadding+300, headerY, "STATUS", uiTextMuted)
+	pageText := lc.listView.pageText()
+	drawText(screen, panelX+panelWidth-uiPanelPadding-textWidth(pageText), headerY, pageText, uiTextMuted)
 
 	// Room list rows
 	y := headerY + uiRowHeight + 4
@@ -453,7 +906,7 @@ func (lc *LobbyClient) drawLobby(screen *ebiten.Image) {
 		}
 
 		rowY := y + i*uiRowHeight
-		if rowY > panelY+panelHeight-uiRowHeight {
+		if rowY > panelY+panelHeight-2*uiRowHeight {
 			break
 		}
 
@@ -475,7 +928,7 @@ func (lc *LobbyClient) drawLobby(screen *ebiten.Image) {
 		if name == "" {
 			name = room.Id
 		}
-		roomName := fmt.Sprintf("%s[%d] %s (%s)", indicator, i+1, name, room.Id)
+		roomName := fmt.Sprintf("%s[%d] %s (%s)", indicator, lc.listView.rowNumber(i), name, room.Id)
 		drawText(screen, panelX+uiPanelPadding, rowY+5, roomName, indicatorColor)
 
 		// Player count
@@ -485,12 +938,20 @@ func (lc *LobbyClient) drawLobby(screen *ebiten.Image) {
 		// Status with color
 		statusText, statusColor := roomStatusWithColor(room.Status)
 		drawText(screen, panelX+uiPanelPadding+300, rowY+5, statusText, statusColor)
+
+		// Map
+		drawText(screen, panelX+uiPanelPadding+400, rowY+5, mapLabel(room.MapId), uiTextSecondary)
 	}
+	drawText(screen, panelX+uiPanelPadding, panelY+panelHeight-uiPanelPadding-8, lc.listView.filterText(), uiTextSecondary)
 
 	// Footer status
 	footerY := ScreenHeight - 24
 	if lc.joinInFlight {
-		drawText(screen, panelX+uiPanelPadding, footerY, "JOINING...", uiAccent)
+		status := "JOINING..."
+		if lc.joinMode == joinAsTakeover {
+			status = "WAITING FOR HOST APPROVAL..."
+		}
+		drawText(screen, panelX+uiPanelPadding, footerY, status, uiAccent)
 	}
 	if lc.lastError != "" {
 		drawText(screen, panelX+uiPanelPadding, footerY+12, lc.lastError, uiError)
@@ -537,7 +998,7 @@ func (lc *LobbyClient) drawInputDialog(screen *ebiten.Image) {
 	drawText(screen, dialogX+uiPanelPadding, inputY, inputText, uiTextPrimary)
 
 	// Draw blinking cursor
-	cursorX := dialogX + uiPanelPadding + len(inputText)*8
+	cursorX := dialogX + uiPanelPadding + textWidth(inputText) + 1
 	if int(lc.inputCursorBlink)%2 == 0 {
 		cursorImg := ebiten.NewImage(2, 14)
 		cursorImg.Fill(uiAccent)
@@ -551,7 +1012,7 @@ func (lc *LobbyClient) drawInputDialog(screen *ebiten.Image) {
 }
 
 func (lc *LobbyClient) drawRoom(screen *ebiten.Image) {
-	screen.Fill(uiBackground)
+	screen.Fill(ActiveTheme().Background)
 
 	// Header panel
 	drawPanel(screen, 0, 0, ScreenWidth, 64)
@@ -561,7 +1022,11 @@ func (lc *LobbyClient) drawRoom(screen *ebiten.Image) {
 		roomID = lc.roomState.RoomId
 	}
 	drawText(screen, uiPanelPadding, 18, "ROOM: "+roomID, uiTextPrimary)
-	drawText(screen, uiPanelPadding, 38, "Space:Ready  Enter:Start  A:AddAI  L:Leave", uiTextSecondary)
+	if lc.network.IsSpectating() {
+		drawText(screen, uiPanelPadding, 38, "Spectating  L:Leave", uiTextSecondary)
+	} else {
+		drawText(screen, uiPanelPadding, 38, "Space:Ready Enter:Start A:AddAI V:AILevel M:NewMap N:PickMap T:Team L:Leave  Rules: D C H X G E Y", uiTextSecondary)
+	}
 
 	// Players panel
 	panelX := uiPanelMargin
@@ -595,11 +1060,15 @@ func (lc *LobbyClient) drawRoom(screen *ebiten.Image) {
 			}
 
 			// Player name and character
-			playerText := fmt.Sprintf(" %s %s", player.Name, shortCharacter(player.Character))
+			playerText := fmt.Sprintf(" %s %s", roomPlayerLabel(player), characterLabel(player))
+			if lc.roomState.GetRules().GetTeams() {
+				playerText = fmt.Sprintf(" [T%d]%s", player.Team, playerText)
+			}
 			drawText(screen, panelX+uiPanelPadding, rowY+5, flags, flagColor)
 			drawText(screen, panelX+uiPanelPadding+28, rowY+5, playerText, uiTextPrimary)
 		}
 	}
+	lc.drawChat(screen, panelX+uiPanelPadding, panelY+panelHeight-uiRowHeight)
 
 	// Room info panel (right side)
 	infoPanelX := panelX + panelWidth + uiPanelMargin
@@ -612,18 +1081,83 @@ func (lc *LobbyClient) drawRoom(screen *ebiten.Image) {
 	// Room info content
 	infoY := infoHeaderY + uiRowHeight + 8
 	if lc.roomState != nil {
-		playerCount := fmt.Sprintf("Players: %d / 4", len(lc.roomState.Players))
+		capacity := int(lc.roomState.MaxPlayers)
+		if capacity == 0 {
+			capacity = core.StandardMapPlayers // 旧服务器不下发人数上限
+		}
+		playerCount := fmt.Sprintf("Players: %d / %d", len(lc.roomState.Players), capacity)
+		if name := lc.roomState.CustomMap; name != "" {
+			playerCount = fmt.Sprintf("Players: %d/%d  Map: %s", len(lc.roomState.Players), capacity, name)
+		} else if name := mapLabel(lc.roomState.MapId); name != "" {
+			playerCount = fmt.Sprintf("Players: %d/%d  Map: %s", len(lc.roomState.Players), capacity, name)
+		}
 		drawText(screen, infoPanelX+uiPanelPadding, infoY, playerCount, uiTextPrimary)
 
 		// Host indicator
 		isHost := lc.roomState.HostId == lc.network.GetPlayerID()
 		hostText := "You are: Host"
 		hostColor := uiAccent
-		if !isHost {
+		if lc.network.IsSpectating() {
+			hostText = "You are: Spectator"
+			hostColor = uiTextSecondary
+		} else if !isHost {
 			hostText = "You are: Guest"
 			hostColor = uiTextSecondary
 		}
 		drawText(screen, infoPanelX+uiPanelPadding, infoY+uiRowHeight, hostText, hostColor)
+
+		// Map preview (top-right of the info panel)
+		previewX := infoPanelX + infoPanelWidth - uiPanelPadding - previewWidth
+		seedText := fmt.Sprintf("Seed: %d", lc.roomState.Seed)
+		if lc.roomState.Seed == 0 && len(lc.roomState.SeedCommitment) > 0 {
+			// 公平种子：开局前只有承诺，没有地图可以预览
+			drawText(screen, previewX, infoHeaderY+uiRowHeight, "Map hidden", uiTextMuted)
+			seedText = "Seed: committed " + commitmentLabel(lc.roomState.SeedCommitment)
+		} else {
+			lc.mapPreview.Draw(screen, previewX, infoHeaderY, lc.roomState.Seed, lc.network.GetMapDefinition(), lc.roomState.Players, lc.network.GetPlayerID())
+		}
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+2*uiRowHeight, seedText, uiTextSecondary)
+
+		rulesText := "[D] Door ping: " + onOff(lc.roomState.GetRules().GetDoorCampPing())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+3*uiRowHeight, rulesText, uiTextSecondary)
+		collisionText := "[C] Collision: " + onOff(lc.roomState.GetRules().GetPlayerCollision())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+4*uiRowHeight, collisionText, uiTextSecondary)
+		hazardsText := "[H] Hazards: " + onOff(lc.roomState.GetRules().GetMapHazards())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+5*uiRowHeight, hazardsText, uiTextSecondary)
+		shrinkText := "[X] Sudden death: " + onOff(lc.roomState.GetRules().GetSuddenDeath())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+6*uiRowHeight, shrinkText, uiTextSecondary)
+		fairSeedText := "[G] Fair seed: " + onOff(lc.roomState.GetRules().GetFairSeed())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+7*uiRowHeight, fairSeedText, uiTextSecondary)
+		teamsText := "[E] Teams 2v2: " + onOff(lc.roomState.GetRules().GetTeams())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+8*uiRowHeight, teamsText, uiTextSecondary)
+		friendlyFireText := "[Y] Friendly fire: " + onOff(lc.roomState.GetRules().GetFriendlyFire())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+9*uiRowHeight, friendlyFireText, uiTextSecondary)
+
+		overtimeText := "[O] Door overtime: " + onOff(lc.roomState.GetRules().GetDoorOvertime()) +
+			"  [B] Boss: " + onOff(lc.roomState.GetRules().GetBossMode())
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+10*uiRowHeight, overtimeText, uiTextSecondary)
+
+		configText := matchConfigText(protocol.ProtoMatchConfigToCore(lc.roomState.Config))
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+11*uiRowHeight, configText, uiTextSecondary)
+		aiText := "[V] New AI: " + aiDifficultyLabel(lc.aiDifficulty)
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+12*uiRowHeight, aiText, uiTextSecondary)
+		mapText := fmt.Sprintf("[N] Map: %s (%d built-in, %d community)",
+			resources.Map(core.MapLayout(lc.roomState.MapId), uiLang).Name, len(core.BuiltinMapIDs()), len(lc.roomState.MapChoices))
+		if lc.roomState.CustomMap != "" {
+			mapText = "[N] Map: " + lc.roomState.CustomMap
+		}
+		drawText(screen, infoPanelX+uiPanelPadding, infoY+13*uiRowHeight, mapText, uiTextSecondary)
+
+		// Pending proposals, then the spectator list
+		spectatorY := lc.drawProposals(screen, infoPanelX+uiPanelPadding, infoY+14*uiRowHeight)
+		drawText(screen, infoPanelX+uiPanelPadding, spectatorY, fmt.Sprintf("Spectators: %d", lc.roomState.SpectatorCount), uiTextMuted)
+		for i, name := range lc.roomState.SpectatorNames {
+			rowY := spectatorY + (i+1)*uiRowHeight
+			if rowY > panelY+panelHeight-uiRowHeight {
+				break
+			}
+			drawText(screen, infoPanelX+uiPanelPadding+8, rowY, name, uiTextSecondary)
+		}
 	}
 
 	// Footer status
@@ -632,15 +1166,37 @@ func (lc *LobbyClient) drawRoom(screen *ebiten.Image) {
 		drawText(screen, panelX+uiPanelPadding, footerY, lc.lastError, uiError)
 	}
 
+	lc.drawReadyNudge(screen)
+
 	// Draw toast notification
 	lc.drawToast(screen)
 }
 
+// teamLabel formats a team number for display
+func teamLabel(team int) string {
+	switch team {
+	case core.TeamA:
+		return "Team 1"
+	case core.TeamB:
+		return "Team 2"
+	default:
+		return "No team"
+	}
+}
+
+// onOff formats a rule flag for display
+func onOff(enabled bool) string {
+	if enabled {
+		return "ON"
+	}
+	return "OFF"
+}
+
 // drawPanel draws a panel with background and border
 func drawPanel(screen *ebiten.Image, x, y, width, height int) {
 	// Panel background
 	panelImg := ebiten.NewImage(width, height)
-	panelImg.Fill(uiPanelBackground)
+	panelImg.Fill(ActiveTheme().Panel)
 
 	// Draw border using vector strokes
 	borders := []struct {
@@ -714,7 +1270,7 @@ func drawText(screen *ebiten.Image, x, y int, msg string, clr color.Color) {
 	options := &text.DrawOptions{}
 	options.GeoM.Translate(float64(x), float64(y))
 	options.ColorScale.ScaleWithColor(clr)
-	text.Draw(screen, msg, lobbyFont, options)
+	text.Draw(screen, msg, uiFace, options)
 }
 
 func roomStatusLabel(status gamev1.RoomStatus) string {
@@ -727,18 +1283,3 @@ func roomStatusLabel(status gamev1.RoomStatus) string {
 		return "UNKNOWN"
 	}
 }
-
-func shortCharacter(char gamev1.CharacterType) string {
-	switch char {
-	case gamev1.CharacterType_CHARACTER_TYPE_WHITE:
-		return "WHITE"
-	case gamev1.CharacterType_CHARACTER_TYPE_BLACK:
-		return "BLACK"
-	case gamev1.CharacterType_CHARACTER_TYPE_RED:
-		return "RED"
-	case gamev1.CharacterType_CHARACTER_TYPE_BLUE:
-		return "BLUE"
-	default:
-		return "UNKNOWN"
-	}
-}
@@ -20,8 +20,9 @@ func NewMapRenderer(gameMap *core.GameMap) *MapRenderer {
 
 // Draw 绘制地图
 func (m *MapRenderer) Draw(screen *ebiten.Image) {
-	for y := 0; y < core.MapHeight; y++ {
-		for x := 0; x < core.MapWidth; x++ {
+	theme := ActiveTheme()
+	for y := 0; y < m.GameMap.Height; y++ {
+		for x := 0; x < m.GameMap.Width; x++ {
 			px := float32(x * core.TileSize)
 			py := float32(y * core.TileSize)
 
@@ -29,20 +30,26 @@ func (m *MapRenderer) Draw(screen *ebiten.Image) {
 			var c color.Color
 			switch tile {
 			case core.TileEmpty:
-				c = color.RGBA{34, 139, 34, 255} // 草地绿
+				c = theme.Grass
 			case core.TileWall:
-				c = color.RGBA{80, 80, 80, 255} // 灰色墙
+				c = theme.Wall
 			case core.TileBrick:
-				c = color.RGBA{205, 133, 63, 255} // 砖块棕色
+				c = theme.Brick
 			case core.TileDoor:
-				c = color.RGBA{255, 215, 0, 255} // 金色门
+				c = theme.Door
+			}
+
+			// 灰度格子精灵图按主题颜色着色
+			if sheet := sprites.tiles; sheet != nil {
+				drawSprite(screen, sheet.frame(tileSpriteColumns[tile], 0), float64(px), float64(py), 1, c, 1)
+				continue
 			}
 
 			// 绘制方块
 			vector.DrawFilledRect(screen, px, py, core.TileSize, core.TileSize, c, false)
 
 			// 绘制边框
-			vector.StrokeRect(screen, px, py, core.TileSize, core.TileSize, 1, color.RGBA{0, 0, 0, 100}, false)
+			vector.StrokeRect(screen, px, py, core.TileSize, core.TileSize, 1, theme.TileBorder, false)
 
 			// 为砖块添加纹理效果
 			if tile == core.TileBrick {
@@ -50,7 +57,7 @@ func (m *MapRenderer) Draw(screen *ebiten.Image) {
 				for i := 0; i < 3; i++ {
 					lineY := py + float32(i*10+5)
 					vector.StrokeLine(screen, px+2, lineY, px+core.TileSize-2, lineY, 1,
-						color.RGBA{180, 118, 53, 255}, false)
+						theme.BrickDetail, false)
 				}
 			}
 
@@ -58,9 +65,9 @@ func (m *MapRenderer) Draw(screen *ebiten.Image) {
 			if tile == core.TileWall {
 				// 十字纹理
 				vector.StrokeLine(screen, px+core.TileSize/2, py+5, px+core.TileSize/2, py+core.TileSize-5,
-					2, color.RGBA{60, 60, 60, 255}, false)
+					2, theme.WallDetail, false)
 				vector.StrokeLine(screen, px+5, py+core.TileSize/2, px+core.TileSize-5, py+core.TileSize/2,
-					2, color.RGBA{60, 60, 60, 255}, false)
+					2, theme.WallDetail, false)
 			}
 
 			// 为门添加特殊效果
@@ -69,13 +76,13 @@ func (m *MapRenderer) Draw(screen *ebiten.Image) {
 				for i := 0; i < 4; i++ {
 					lineY := py + float32(i*8+6)
 					vector.StrokeLine(screen, px+8, lineY, px+core.TileSize-8, lineY, 2,
-						color.RGBA{218, 165, 32, 255}, false)
+						theme.DoorRung, false)
 				}
 				// 垂直支柱
 				vector.StrokeLine(screen, px+10, py+6, px+10, py+core.TileSize-6, 2,
-					color.RGBA{184, 134, 11, 255}, false)
+					theme.DoorPost, false)
 				vector.StrokeLine(screen, px+core.TileSize-10, py+6, px+core.TileSize-10, py+core.TileSize-6, 2,
-					color.RGBA{184, 134, 11, 255}, false)
+					theme.DoorPost, false)
 			}
 		}
 	}
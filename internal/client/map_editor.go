@@ -0,0 +1,530 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 地图编辑器：鼠标绘制墙壁/砖块/出生点/门的候选位置，
+// 校验后保存为 JSON 地图定义（见 core.MapDefinition），可在本地与 AI 试玩，或上传到服务器的地图目录
+
+const (
+	editorCellSize  = 24
+	editorMapX      = uiPanelMargin
+	editorMapY      = uiPanelMargin
+	editorMapWidth  = core.MapWidth * editorCellSize
+	editorMapHeight = core.MapHeight * editorCellSize
+	editorPanelX    = editorMapX + editorMapWidth + uiPanelMargin
+
+	mapUploadTimeout = 5 * time.Second
+)
+
+// editorBrush 当前画笔
+type editorBrush int
+
+const (
+	brushEmpty editorBrush = iota
+	brushWall
+	brushBrick
+	brushSpawn // 切换出生点
+	brushDoor  // 切换门的候选位置
+)
+
+var editorBrushNames = []string{"EMPTY", "WALL", "BRICK", "SPAWN", "DOOR"}
+
+var editorBrushKeys = []ebiten.Key{ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5}
+
+var (
+	editorDoorColor  = color.RGBA{200, 120, 230, 255}
+	editorErrorColor = color.RGBA{230, 60, 60, 255}
+)
+
+// MapEditor 地图编辑器场景
+type MapEditor struct {
+	path          string
+	name          string
+	tiles         [][]byte
+	spawns        []core.MapCell
+	doors         []core.MapCell
+	itemDrops     *core.ItemDropTable // 地图的道具掉落表覆盖（编辑器不修改，保存 JSON 时原样保留）
+	brush         editorBrush
+	input         keyTracker
+	mouseWasDown  bool
+	character     core.CharacterType
+	controlScheme ControlScheme
+
+	status      string
+	statusColor color.Color
+	errorCell   *core.MapCell // 校验失败的格子（高亮显示）
+
+	server     string
+	proto      string
+	uploading  bool
+	uploadChan chan error
+
+	testGame *Game // 试玩中的本地游戏（Esc 返回编辑器）
+}
+
+// NewMapEditor 打开地图编辑器：path 存在时加载该地图（.txt 为文本地图，其余为 JSON），否则以内置地图为起点
+func NewMapEditor(path string, character core.CharacterType, controlScheme ControlScheme) (*MapEditor, error) {
+	def, err := core.LoadMapFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		def = core.DefaultMapDefinition()
+		def.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if def.Height() != core.MapHeight {
+		return nil, fmt.Errorf("地图编辑器只能编辑 %dx%d 的标准地图（%s 为 %dx%d）", core.MapWidth, core.MapHeight, def.Name, def.Width(), def.Height())
+	}
+
+	me := &MapEditor{
+		path:          path,
+		name:          def.Name,
+		spawns:        append([]core.MapCell(nil), def.Spawns...),
+		doors:         append([]core.MapCell(nil), def.DoorCandidates...),
+		itemDrops:     def.ItemDrops,
+		brush:         brushWall,
+		character:     character,
+		controlScheme: controlScheme,
+		uploadChan:    make(chan error, 1),
+	}
+	me.tiles = make([][]byte, core.MapHeight)
+	for y, row := range def.Tiles {
+		me.tiles[y] = []byte(row)
+	}
+	me.setStatus("Editing "+filepath.Base(path), uiTextSecondary)
+	return me, nil
+}
+
+// SetUploadServer 设置上传地图的服务器（空表示不能上传）
+func (me *MapEditor) SetUploadServer(serverAddr, proto string) {
+	me.server = serverAddr
+	me.proto = proto
+}
+
+func (me *MapEditor) setStatus(msg string, clr color.Color) {
+	me.status = msg
+	me.statusColor = clr
+}
+
+// definition 用当前编辑内容生成地图定义
+func (me *MapEditor) definition() *core.MapDefinition {
+	def := &core.MapDefinition{
+		Name:           me.name,
+		Tiles:          make([]string, core.MapHeight),
+		Spawns:         append([]core.MapCell(nil), me.spawns...),
+		DoorCandidates: append([]core.MapCell(nil), me.doors...),
+		ItemDrops:      me.itemDrops,
+	}
+	for y, row := range me.tiles {
+		def.Tiles[y] = string(row)
+	}
+	return def
+}
+
+// validate 校验当前地图，失败时在状态栏显示原因并高亮出错的格子
+func (me *MapEditor) validate() (*core.MapDefinition, bool) {
+	def := me.definition()
+	err := def.Validate()
+	if err == nil {
+		// 与服务器审核一致：出生点不公平的地图无法通过上传
+		err = def.CheckFairness()
+	}
+	me.errorCell = nil
+	if err == nil {
+		return def, true
+	}
+	log.Printf("地图校验失败: %v", err)
+	var mapErr *core.MapError
+	if errors.As(err, &mapErr) {
+		if mapErr.Reason != core.MapErrorName && mapErr.Cell != (core.MapCell{}) {
+			cell := mapErr.Cell
+			me.errorCell = &cell
+		}
+		me.setStatus(mapErrorText(mapErr), uiError)
+	} else {
+		me.setStatus("Invalid map", uiError)
+	}
+	return nil, false
+}
+
+// mapErrorText 地图错误的界面提示（界面字体只支持 ASCII）
+func mapErrorText(err *core.MapError) string {
+	at := fmt.Sprintf(" at (%d,%d)", err.Cell.X, err.Cell.Y)
+	switch err.Reason {
+	case core.MapErrorName:
+		return fmt.Sprintf("Map name must be 1-%d characters", core.MaxMapNameLen)
+	case core.MapErrorSpawnCount:
+		return fmt.Sprintf("Place %d-%d spawns", core.MinMapSpawns, core.MaxMapSpawns)
+	case core.MapErrorSpawn:
+		return "Spawn must be on an empty tile" + at
+	case core.MapErrorDoor:
+		return fmt.Sprintf("Door candidates must be bricks (max %d)", core.MaxMapDoorCandidates)
+	case core.MapErrorUnreachable:
+		return "Walls cut off the cell" + at
+	case core.MapErrorNoDoor:
+		return "No reachable brick for the door"
+	case core.MapErrorUnfair:
+		return "Spawns are unfair (cover, distance to enemies or bricks)" + at
+	case core.MapErrorItemDrops:
+		return "Invalid item_drops table (percent 0-100, known items, weights >= 0)"
+	default:
+		return "Invalid map"
+	}
+}
+
+func (me *MapEditor) Update() error {
+	if me.testGame != nil {
+		if me.input.JustPressed(ebiten.KeyEscape) {
+			me.testGame = nil
+			me.setStatus("Back to editor", uiTextSecondary)
+			return nil
+		}
+		return me.testGame.Update()
+	}
+
+	select {
+	case err := <-me.uploadChan:
+		me.uploading = false
+		if err != nil {
+			log.Printf("上传地图失败: %v", err)
+			me.setStatus("Upload failed (see console)", uiError)
+		} else {
+			me.setStatus("Uploaded "+me.name+", waiting for server approval", uiSuccess)
+		}
+	default:
+	}
+
+	for i, key := range editorBrushKeys {
+		if me.input.JustPressed(key) {
+			me.brush = editorBrush(i)
+		}
+	}
+	if me.input.JustPressed(ebiten.KeyV) {
+		if _, ok := me.validate(); ok {
+			me.setStatus("Map is valid", uiSuccess)
+		}
+	}
+	if me.input.JustPressed(ebiten.KeyS) {
+		me.save()
+	}
+	if me.input.JustPressed(ebiten.KeyP) {
+		me.startTestPlay()
+		return nil
+	}
+	if me.input.JustPressed(ebiten.KeyU) {
+		me.upload()
+	}
+
+	me.handleMouse()
+	return nil
+}
+
+// handleMouse 左键用当前画笔绘制（出生点和门在按下时切换），右键擦除
+func (me *MapEditor) handleMouse() {
+	left := ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft)
+	right := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
+	justDown := left && !me.mouseWasDown
+	me.mouseWasDown = left
+
+	mx, my := ebiten.CursorPosition()
+	if mx < editorMapX || my < editorMapY {
+		return
+	}
+	cell := core.MapCell{X: (mx - editorMapX) / editorCellSize, Y: (my - editorMapY) / editorCellSize}
+	if cell.X >= core.MapWidth || cell.Y >= core.MapHeight {
+		return
+	}
+
+	switch {
+	case right:
+		me.paint(cell, brushEmpty)
+	case left && (me.brush == brushSpawn || me.brush == brushDoor):
+		if justDown {
+			me.paint(cell, me.brush)
+		}
+	case left:
+		me.paint(cell, me.brush)
+	}
+}
+
+// paint 在格子上应用画笔，保持出生点在空地、门的候选位置在砖块上
+func (me *MapEditor) paint(cell core.MapCell, brush editorBrush) {
+	switch brush {
+	case brushEmpty:
+		me.setTile(cell, '.')
+		me.spawns = removeCell(me.spawns, cell)
+	case brushWall:
+		me.setTile(cell, 'W')
+	case brushBrick:
+		me.setTile(cell, 'B')
+	case brushSpawn:
+		if containsCell(me.spawns, cell) {
+			me.spawns = removeCell(me.spawns, cell)
+			return
+		}
+		if len(me.spawns) >= core.MaxMapSpawns {
+			me.setStatus(fmt.Sprintf("At most %d spawns", core.MaxMapSpawns), uiWarning)
+			return
+		}
+		me.setTile(cell, '.')
+		me.spawns = append(me.spawns, cell)
+	case brushDoor:
+		if containsCell(me.doors, cell) {
+			me.doors = removeCell(me.doors, cell)
+			return
+		}
+		if len(me.doors) >= core.MaxMapDoorCandidates {
+			me.setStatus(fmt.Sprintf("At most %d door candidates", core.MaxMapDoorCandidates), uiWarning)
+			return
+		}
+		me.setTile(cell, 'B')
+		me.doors = append(me.doors, cell)
+	}
+}
+
+// setTile 修改地图块，并移除因此失效的出生点和门的候选位置
+func (me *MapEditor) setTile(cell core.MapCell, tile byte) {
+	me.tiles[cell.Y][cell.X] = tile
+	if tile != '.' {
+		me.spawns = removeCell(me.spawns, cell)
+	}
+	if tile != 'B' {
+		me.doors = removeCell(me.doors, cell)
+	}
+	if me.errorCell != nil && *me.errorCell == cell {
+		me.errorCell = nil
+	}
+}
+
+func containsCell(cells []core.MapCell, cell core.MapCell) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+func removeCell(cells []core.MapCell, cell core.MapCell) []core.MapCell {
+	for i, c := range cells {
+		if c == cell {
+			return append(cells[:i], cells[i+1:]...)
+		}
+	}
+	return cells
+}
+
+// save 校验后保存到编辑的文件
+func (me *MapEditor) save() {
+	def, ok := me.validate()
+	if !ok {
+		return
+	}
+	data, err := core.MarshalMapDefinition(def)
+	if core.IsMapTextFile(me.path) {
+		data = core.MarshalMapText(def)
+	}
+	if err != nil {
+		me.setStatus("Could not encode map", uiError)
+		return
+	}
+	if err := os.WriteFile(me.path, data, 0o644); err != nil {
+		log.Printf("保存地图失败: %v", err)
+		me.setStatus("Save failed (see console)", uiError)
+		return
+	}
+	me.setStatus("Saved "+filepath.Base(me.path), uiSuccess)
+}
+
+// startTestPlay 用当前地图开一局本地游戏：玩家 1 由本地控制，其余出生点由 AI 占据
+func (me *MapEditor) startTestPlay() {
+	def, ok := me.validate()
+	if !ok {
+		return
+	}
+	seed := time.Now().UnixNano()
+	game := NewGameWithSeed(seed)
+	game.coreGame.Map = core.NewGameMapFromDefinition(def, seed)
+	game.mapRenderer = NewMapRenderer(game.coreGame.Map)
+	game.SetControlScheme(me.controlScheme)
+
+	chars := []core.CharacterType{me.character, core.CharacterBlack, core.CharacterRed, core.CharacterBlue}
+	for i := range def.Spawns {
+		id := i + 1
+		cell := def.SpawnCell(id)
+		x, y := GridToPlayerXY(cell.X, cell.Y)
+		game.AddPlayer(NewPlayer(game, id, x, y, chars[i%len(chars)], id != 1))
+	}
+	me.testGame = game
+}
+
+// upload 校验后在后台上传到服务器的地图目录
+func (me *MapEditor) upload() {
+	if me.uploading {
+		return
+	}
+	if me.server == "" {
+		me.setStatus("No server (start with -server)", uiWarning)
+		return
+	}
+	def, ok := me.validate()
+	if !ok {
+		return
+	}
+	me.uploading = true
+	me.setStatus("Uploading...", uiAccent)
+	go func() {
+		me.uploadChan <- UploadMap(me.server, me.proto, def, mapUploadTimeout)
+	}()
+}
+
+// UploadMap 用一次性连接上传地图定义（服务器需开启 -maps-dir，同名地图不会被覆盖）
+func UploadMap(serverAddr, proto string, def *core.MapDefinition, timeout time.Duration) error {
+	data, err := json.Marshal(def)
+	if err != nil {
+		return err
+	}
+	if len(data) > core.MaxMapFileSize {
+		return fmt.Errorf("地图过大（%d 字节，上限 %d）", len(data), core.MaxMapFileSize)
+	}
+
+	conn, err := dialServer(serverAddr, proto)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	packet, err := protocol.NewMapUploadRequestPacket(def.Name, data)
+	if err != nil {
+		return err
+	}
+	buf, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return err
+	}
+	if err := writePacket(conn, buf); err != nil {
+		return err
+	}
+
+	// 服务器可能先推送其他消息（例如 Ping），读到上传结果为止
+	for {
+		buf, err := readPacket(conn)
+		if err != nil {
+			return err
+		}
+		msg, err := DecodeServerPacket(buf)
+		if err != nil {
+			continue
+		}
+		if resp, ok := msg.(*gamev1.MapUploadResponse); ok {
+			if !resp.Success {
+				return errors.New(resp.ErrorMessage)
+			}
+			return nil
+		}
+	}
+}
+
+func (me *MapEditor) Draw(screen *ebiten.Image) {
+	if me.testGame != nil {
+		me.testGame.Draw(screen)
+		drawText(screen, uiPanelPadding, ScreenHeight-16, "TEST PLAY  Esc:Back to editor", uiAccent)
+		return
+	}
+
+	screen.Fill(ActiveTheme().Background)
+	me.drawGrid(screen)
+
+	// 画笔面板
+	panelWidth := ScreenWidth - editorPanelX - uiPanelMargin
+	drawPanel(screen, editorPanelX, editorMapY, panelWidth, editorMapHeight)
+	drawText(screen, editorPanelX+8, editorMapY+16, "MAP EDITOR", uiAccent)
+	name := me.name
+	if len(name) > 16 {
+		name = name[:16]
+	}
+	drawText(screen, editorPanelX+8, editorMapY+32, name, uiTextPrimary)
+	for i, name := range editorBrushNames {
+		y := editorMapY + 60 + i*uiRowHeight
+		clr := uiTextSecondary
+		if editorBrush(i) == me.brush {
+			drawSelectionRect(screen, editorPanelX+4, y-4, panelWidth-8, uiRowHeight)
+			clr = uiTextPrimary
+		}
+		drawText(screen, editorPanelX+8, y+8, fmt.Sprintf("%d %s", i+1, name), clr)
+	}
+	infoY := editorMapY + 60 + len(editorBrushNames)*uiRowHeight + 12
+	drawText(screen, editorPanelX+8, infoY, fmt.Sprintf("Spawns %d/%d", len(me.spawns), core.MaxMapSpawns), uiTextSecondary)
+	drawText(screen, editorPanelX+8, infoY+16, fmt.Sprintf("Doors  %d/%d", len(me.doors), core.MaxMapDoorCandidates), uiTextSecondary)
+	if len(me.doors) == 0 {
+		drawText(screen, editorPanelX+8, infoY+32, "(any brick)", uiTextMuted)
+	}
+
+	// 帮助与状态
+	helpY := editorMapY + editorMapHeight + 24
+	drawText(screen, uiPanelMargin, helpY, "LMB:Paint  RMB:Erase  1-5:Brush", uiTextSecondary)
+	upload := "U:Upload"
+	if me.server == "" {
+		upload = "U:Upload (needs -server)"
+	}
+	drawText(screen, uiPanelMargin, helpY+16, "V:Validate  S:Save  P:Test play vs AI  "+upload, uiTextSecondary)
+	drawText(screen, uiPanelMargin, helpY+40, me.status, me.statusColor)
+}
+
+// drawGrid 绘制编辑中的地图：出生点标玩家编号，门的候选位置加边框，校验失败的格子标红
+func (me *MapEditor) drawGrid(screen *ebiten.Image) {
+	theme := ActiveTheme()
+	vector.DrawFilledRect(screen, editorMapX, editorMapY, editorMapWidth, editorMapHeight, theme.Grass, false)
+	for y, row := range me.tiles {
+		for x, tile := range row {
+			px := float32(editorMapX + x*editorCellSize)
+			py := float32(editorMapY + y*editorCellSize)
+			switch tile {
+			case 'W':
+				vector.DrawFilledRect(screen, px, py, editorCellSize, editorCellSize, theme.Wall, false)
+			case 'B':
+				vector.DrawFilledRect(screen, px+1, py+1, editorCellSize-2, editorCellSize-2, theme.Brick, false)
+			}
+		}
+	}
+	for _, c := range me.doors {
+		px := float32(editorMapX + c.X*editorCellSize)
+		py := float32(editorMapY + c.Y*editorCellSize)
+		vector.StrokeRect(screen, px+2, py+2, editorCellSize-4, editorCellSize-4, 2, editorDoorColor, false)
+	}
+	for i, c := range me.spawns {
+		px := editorMapX + c.X*editorCellSize
+		py := editorMapY + c.Y*editorCellSize
+		vector.DrawFilledCircle(screen, float32(px+editorCellSize/2), float32(py+editorCellSize/2), editorCellSize/2-3, previewSpawn, false)
+		drawText(screen, px+editorCellSize/2-3, py+editorCellSize/2+4, fmt.Sprint(i+1), uiTextPrimary)
+	}
+	if me.errorCell != nil {
+		px := float32(editorMapX + me.errorCell.X*editorCellSize)
+		py := float32(editorMapY + me.errorCell.Y*editorCellSize)
+		vector.StrokeRect(screen, px, py, editorCellSize, editorCellSize, 2, editorErrorColor, false)
+	}
+}
+
+func (me *MapEditor) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ScreenWidth, ScreenHeight
+}
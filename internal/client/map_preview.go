@@ -0,0 +1,124 @@
+package client
+
+import (
+	"image/color"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	previewCellSize = 5 // 缩略图每格像素（标准地图，大地图缩小到同一块区域内）
+	previewWidth    = core.MapWidth * previewCellSize
+	previewHeight   = core.MapHeight * previewCellSize
+)
+
+var previewSpawn = color.RGBA{80, 180, 220, 255}
+
+// spawnCorners 出生角落（与服务器 getSpawnPosition 保持一致）
+var spawnCorners = []core.GridPos{
+	{GridX: 0, GridY: 0},
+	{GridX: core.MapWidth - 1, GridY: 0},
+	{GridX: 0, GridY: core.MapHeight - 1},
+	{GridX: core.MapWidth - 1, GridY: core.MapHeight - 1},
+}
+
+// spawnCornerFor 按玩家 ID 计算出生角落
+func spawnCornerFor(playerID int32) core.GridPos {
+	if playerID <= 0 {
+		return spawnCorners[0]
+	}
+	return spawnCorners[int(playerID-1)%len(spawnCorners)]
+}
+
+// MapPreview 等待房间中的地图缩略图
+// 地图只由地图定义和种子决定，三者和主题不变时复用已绘制的底图
+type MapPreview struct {
+	seed  int64
+	def   *core.MapDefinition // 房间选择的地图（nil 表示默认地图）
+	theme string
+	valid bool
+	cell  int // 每格像素
+	base  *ebiten.Image
+}
+
+// Draw 在 (x, y) 绘制缩略图，并标出已占用的出生点（自己的出生点高亮）
+func (mp *MapPreview) Draw(screen *ebiten.Image, x, y int, seed int64, def *core.MapDefinition, players []*gamev1.RoomPlayer, selfID int32) {
+	if !mp.valid || mp.seed != seed || mp.def != def || mp.theme != ActiveTheme().Name {
+		mp.rebuild(seed, def)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(x), float64(y))
+	screen.DrawImage(mp.base, op)
+
+	for _, player := range players {
+		if player == nil {
+			continue
+		}
+		corner := spawnCornerFor(player.Id)
+		if def != nil {
+			cell := def.SpawnCell(int(player.Id))
+			corner = core.GridPos{GridX: cell.X, GridY: cell.Y}
+		}
+		clr := color.Color(previewSpawn)
+		if player.Id == selfID {
+			clr = uiAccent
+		}
+		vector.DrawFilledRect(
+			screen,
+			float32(x+corner.GridX*mp.cell),
+			float32(y+corner.GridY*mp.cell),
+			float32(mp.cell),
+			float32(mp.cell),
+			clr,
+			false,
+		)
+	}
+}
+
+// rebuild 根据种子重新生成底图（隐藏门不显示，避免泄露位置）
+func (mp *MapPreview) rebuild(seed int64, def *core.MapDefinition) {
+	gameMap := core.NewGameMap(seed)
+	if def != nil {
+		gameMap = core.NewGameMapFromDefinition(def, seed)
+	}
+	mp.cell = min(previewWidth/gameMap.Width, previewHeight/gameMap.Height)
+	width, height := gameMap.Width*mp.cell, gameMap.Height*mp.cell
+	if mp.base == nil || mp.base.Bounds().Dx() != width || mp.base.Bounds().Dy() != height {
+		mp.base = ebiten.NewImage(width, height)
+	}
+	theme := ActiveTheme()
+	mp.base.Fill(theme.Grass)
+
+	for gy := 0; gy < gameMap.Height; gy++ {
+		for gx := 0; gx < gameMap.Width; gx++ {
+			var clr color.Color
+			switch gameMap.GetTile(gx, gy) {
+			case core.TileWall:
+				clr = theme.Wall
+			case core.TileBrick:
+				clr = theme.Brick
+			default:
+				continue
+			}
+			vector.DrawFilledRect(
+				mp.base,
+				float32(gx*mp.cell),
+				float32(gy*mp.cell),
+				float32(mp.cell),
+				float32(mp.cell),
+				clr,
+				false,
+			)
+		}
+	}
+
+	mp.seed = seed
+	mp.def = def
+	mp.theme = theme.Name
+	mp.valid = true
+}
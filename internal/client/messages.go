@@ -0,0 +1,128 @@
+package client
+
+import (
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// roomActionLabels 错误参数中的房间操作名 -> 展示文案
+var roomActionLabels = map[string]string{
+	"ready":            "change ready state",
+	"start":            "start the game",
+	"add_ai":           "add AI players",
+	"kick":             "kick players",
+	"reroll_seed":      "change the map",
+	"set_rules":        "change room rules",
+	"spectate":         "spectate",
+	"takeover":         "approve AI takeovers",
+	"set_team":         "change teams",
+	"set_config":       "change match settings",
+	"set_map":          "choose the map",
+	"propose":          "propose changes",
+	"vote":             "vote",
+	"resolve_proposal": "apply proposals",
+}
+
+// errorParam 取第 i 个参数，缺失时返回 def
+func errorParam(params []string, i int, def string) string {
+	if i < len(params) && params[i] != "" {
+		return params[i]
+	}
+	return def
+}
+
+// actionLabel 渲染错误参数中的操作名
+func actionLabel(params []string) string {
+	action := errorParam(params, 0, "")
+	if label, ok := roomActionLabels[action]; ok {
+		return label
+	}
+	return "do that"
+}
+
+// friendlyErrorMessage renders a server error code and its params as a
+// user-facing message; fallback is the server's log text for unknown codes
+func friendlyErrorMessage(code gamev1.ErrorCode, params []string, fallback string) string {
+	switch code {
+	case gamev1.ErrorCode_ERROR_CODE_ROOM_FULL:
+		if len(params) >= 2 {
+			return "Room is full (" + params[0] + "/" + params[1] + "), try another one"
+		}
+		return "Room is full, try another one"
+	case gamev1.ErrorCode_ERROR_CODE_ROOM_PLAYING:
+		return "Game already in progress, press V to watch or T to take over an AI"
+	case gamev1.ErrorCode_ERROR_CODE_ROOM_NOT_FOUND:
+		if room := errorParam(params, 0, ""); room != "" {
+			return "Room " + room + " no longer exists"
+		}
+		return "Room no longer exists"
+	case gamev1.ErrorCode_ERROR_CODE_BANNED:
+		return "You are not allowed to join this room"
+	case gamev1.ErrorCode_ERROR_CODE_VERSION_MISMATCH:
+		if server := errorParam(params, 0, ""); server != "" {
+			return "Server runs version " + server + " (protocol " + errorParam(params, 1, "?") + "), please update"
+		}
+		return "Client version mismatch, please update"
+	case gamev1.ErrorCode_ERROR_CODE_NOT_HOST:
+		return "Only the host can " + actionLabel(params)
+	case gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM:
+		return "You are not in this room"
+	case gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS:
+		return "Cannot " + actionLabel(params) + " while a game is running"
+	case gamev1.ErrorCode_ERROR_CODE_AI_DISABLED:
+		return "AI players are disabled on this server"
+	case gamev1.ErrorCode_ERROR_CODE_NOT_ENOUGH_PLAYERS:
+		return "Need at least " + errorParam(params, 1, "2") + " players to start"
+	case gamev1.ErrorCode_ERROR_CODE_PLAYERS_NOT_READY:
+		return "Some players are not ready"
+	case gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION:
+		return "Invalid room action"
+	case gamev1.ErrorCode_ERROR_CODE_LEGACY_UNSUPPORTED:
+		return "This room does not support that"
+	case gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND:
+		return "That player is no longer in the room"
+	case gamev1.ErrorCode_ERROR_CODE_KICKED:
+		return "You were kicked from the room"
+	case gamev1.ErrorCode_ERROR_CODE_ROOM_CLOSED:
+		return "Room has been closed"
+	case gamev1.ErrorCode_ERROR_CODE_NO_AI_AVAILABLE:
+		return "No AI player available to take over"
+	case gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED:
+		return "The host did not approve the takeover"
+	case gamev1.ErrorCode_ERROR_CODE_DEBUG_DISABLED:
+		return "Debug features are disabled on this server"
+	case gamev1.ErrorCode_ERROR_CODE_INVALID_SCRIPT:
+		if line := errorParam(params, 0, "0"); line != "0" {
+			return "AI script error on line " + line
+		}
+		return "AI script is invalid"
+	case gamev1.ErrorCode_ERROR_CODE_TOO_MANY_ROOMS:
+		return "Server has no free rooms, join an existing one"
+	case gamev1.ErrorCode_ERROR_CODE_ROOM_IDLE:
+		return "Room closed: nobody started a game for too long"
+	case gamev1.ErrorCode_ERROR_CODE_NOT_READY_REMOVED:
+		return "The host started without you because you were not ready"
+	case gamev1.ErrorCode_ERROR_CODE_TEAMS_UNBALANCED:
+		return "Both teams need at least one player"
+	case gamev1.ErrorCode_ERROR_CODE_MAP_NOT_FOUND:
+		if name := errorParam(params, 0, ""); name != "" {
+			return "Map " + name + " is no longer available"
+		}
+		return "That map is no longer available"
+	case gamev1.ErrorCode_ERROR_CODE_REPORTS_DISABLED:
+		return "This server does not accept player reports"
+	case gamev1.ErrorCode_ERROR_CODE_REPORT_COOLDOWN:
+		return "Please wait " + errorParam(params, 0, "a few") + " seconds before reporting again"
+	case gamev1.ErrorCode_ERROR_CODE_SESSION_INVALID:
+		return "Session is no longer valid, please rejoin"
+	case gamev1.ErrorCode_ERROR_CODE_SESSION_EXPIRED:
+		return "Session expired, please rejoin"
+	case gamev1.ErrorCode_ERROR_CODE_INVALID_ACCOUNT_KEY:
+		return "Account key must be " + errorParam(params, 0, "8") + "-" + errorParam(params, 1, "64") + " characters"
+	case gamev1.ErrorCode_ERROR_CODE_PROPOSAL_NOT_FOUND:
+		return "That proposal was already handled"
+	case gamev1.ErrorCode_ERROR_CODE_INTERNAL:
+		return "Server error, please try again"
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"image/color"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+var (
+	nameTagColor   = color.RGBA{230, 230, 230, 220}
+	nameTagAIColor = color.RGBA{150, 200, 255, 220}
+)
+
+// aiDifficultyLabel AI 难度标签，真人和脚本 AI 返回空
+func aiDifficultyLabel(difficulty gamev1.AIDifficulty) string {
+	switch difficulty {
+	case gamev1.AIDifficulty_AI_DIFFICULTY_EASY:
+		return "Easy"
+	case gamev1.AIDifficulty_AI_DIFFICULTY_NORMAL:
+		return "Normal"
+	case gamev1.AIDifficulty_AI_DIFFICULTY_HARD:
+		return "Hard"
+	}
+	return ""
+}
+
+// roomPlayerLabel 玩家显示名，AI 带上难度，例如 "Bomba (Hard)"
+func roomPlayerLabel(player *gamev1.RoomPlayer) string {
+	if label := aiDifficultyLabel(player.AiDifficulty); label != "" {
+		return player.Name + " (" + label + ")"
+	}
+	return player.Name
+}
+
+// nameTag 玩家头顶显示的名字
+type nameTag struct {
+	text string
+	isAI bool
+}
+
+// nameTagsFromRoom 从房间成员列表生成头顶名字
+func nameTagsFromRoom(players []*gamev1.RoomPlayer) map[int]nameTag {
+	tags := make(map[int]nameTag, len(players))
+	for _, player := range players {
+		if player == nil {
+			continue
+		}
+		tags[int(player.Id)] = nameTag{text: roomPlayerLabel(player), isAI: player.IsAi}
+	}
+	return tags
+}
+
+// drawNameTags 在存活玩家头顶绘制名字，AI 用蓝色区分
+func (g *Game) drawNameTags(screen *ebiten.Image) {
+	for _, player := range g.players {
+		cp := player.corePlayer
+		tag, ok := g.nameTags[cp.ID]
+		if !ok || cp.Dead || player.outOfView {
+			continue
+		}
+		clr := nameTagColor
+		if tag.isAI {
+			clr = nameTagAIColor
+		}
+		x, y := player.GetRenderPosition()
+		drawCenteredText(screen, tag.text, int(x)+cp.Width/2, int(y)-16, clr)
+	}
+}
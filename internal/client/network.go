@@ -2,7 +2,6 @@ package client
 
 import (
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -30,6 +29,17 @@ const (
 	statsLogInterval = 5 * time.Second
 )
 
+// JoinError 加入房间失败（携带服务器返回的错误码）
+type JoinError struct {
+	Code    gamev1.ErrorCode
+	Params  []string // 错误码参数，用于本地化展示
+	Message string   // 服务器日志文案，仅作兜底
+}
+
+func (e *JoinError) Error() string {
+	return fmt.Sprintf("加入失败: %s", e.Message)
+}
+
 // NetworkClient 网络客户端
 
 type NetworkClient struct {
@@ -41,10 +51,29 @@ type NetworkClient struct {
 	playerID      int32
 	character     core.CharacterType
 	gameSeed      int64
+	roomRules     core.GameRules      // 房间规则（开始游戏时用于本地预测）
+	matchConfig   core.MatchConfig    // 对局参数（开始游戏时用于本地预测）
+	series        *gamev1.SeriesScore // 多回合对局的比分（nil 表示单局）
+	mapDef        *core.MapDefinition // 房主选择的地图（nil 表示默认地图）
+	mapJSON       []byte              // 社区地图的原始 JSON，用于判断地图是否变化
+	mapLayout     string              // 当前地图（内置地图 ID 或社区地图名）
 	tps           int32
 	sessionToken  string // 会话令牌，用于重连
 	playerName    string
 	currentRoomID string
+	spectating    bool // 当前是否以观战者身份在房间中
+	telemetry     bool // 同意服务器收集输入观察并写入举报（-telemetry）
+
+	// 账号偏好（profile.go）
+	account account
+
+	// 重连响应中的房间历史，由大厅界面取走
+	roomHistory        []*gamev1.RoomHistoryEntry
+	roomHistoryPending bool
+
+	// 公平种子：开始前收到的承诺与开局后的校验结果
+	seedCommitment []byte
+	seedCheck      SeedCheck
 
 	// 网络
 	connected bool
@@ -52,20 +81,30 @@ type NetworkClient struct {
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 
+	// 等待响应的请求（requests.go）
+	requests requestTracker
+
 	// 消息队列
-	stateChan         chan *gamev1.GameState
-	eventChan         chan *gamev1.GameEvent
-	joinRespChan      chan *gamev1.JoinResponse
-	reconnectRespChan chan *gamev1.ReconnectResponse
-	roomListChan      chan *gamev1.RoomListResponse
-	roomStateChan     chan *gamev1.RoomStateUpdate
-	roomActionChan    chan *gamev1.RoomActionResponse
+	stateChan        chan *gamev1.GameState
+	eventChan        chan *gamev1.GameEvent
+	roomStateChan    chan *gamev1.RoomStateUpdate
+	roomActionChan   chan *gamev1.RoomActionResponse // 没有对应 Future 的房间操作响应（服务器主动发送或请求已超时）
+	noticeChan       chan *gamev1.ServerNotice
+	debugAIChan      chan *gamev1.DebugAIState
+	replaySearchChan chan *gamev1.ReplaySearchResponse
 
 	// 发送队列
 	inputSeq        int32
 	sendChan        chan []byte
 	lastServerFrame int32
 
+	// 增量状态：最近的完整状态（只在接收协程中访问）与随输入包确认的状态帧
+	stateBaselines []*gamev1.GameState
+	ackStateFrame  atomic.Int32
+
+	// 已收到的关键事件序号（只在接收协程中访问），服务器重发时用于去重
+	seenEventSeqs map[int32]bool
+
 	// 时间同步（毫秒）
 	timeOffsetMs        int64
 	lastServerTimeMs    int64
@@ -88,24 +127,24 @@ func NewNetworkClient(serverAddr, proto string, character core.CharacterType) *N
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &NetworkClient{
-		serverAddr:        serverAddr,
-		proto:             proto,
-		character:         character,
-		playerID:          -1,
-		playerName:        "Player",
-		ctx:               ctx,
-		cancel:            cancel,
-		stateChan:         make(chan *gamev1.GameState, 256),
-		eventChan:         make(chan *gamev1.GameEvent, 64),
-		joinRespChan:      make(chan *gamev1.JoinResponse, 1),
-		reconnectRespChan: make(chan *gamev1.ReconnectResponse, 1),
-		roomListChan:      make(chan *gamev1.RoomListResponse, 4),
-		roomStateChan:     make(chan *gamev1.RoomStateUpdate, 8),
-		roomActionChan:    make(chan *gamev1.RoomActionResponse, 4),
-		sendChan:          make(chan []byte, 256),
-		errChan:           make(chan error, 1),
-		rttSamples:        make([]int64, rttSampleWindow),
-		statsLogger:       time.NewTicker(statsLogInterval),
+		serverAddr:       serverAddr,
+		proto:            proto,
+		character:        character,
+		playerID:         -1,
+		playerName:       "Player",
+		ctx:              ctx,
+		cancel:           cancel,
+		stateChan:        make(chan *gamev1.GameState, 256),
+		eventChan:        make(chan *gamev1.GameEvent, 64),
+		roomStateChan:    make(chan *gamev1.RoomStateUpdate, 8),
+		roomActionChan:   make(chan *gamev1.RoomActionResponse, 4),
+		noticeChan:       make(chan *gamev1.ServerNotice, 4),
+		debugAIChan:      make(chan *gamev1.DebugAIState, 4),
+		replaySearchChan: make(chan *gamev1.ReplaySearchResponse, 4),
+		sendChan:         make(chan []byte, 256),
+		errChan:          make(chan error, 1),
+		rttSamples:       make([]int64, rttSampleWindow),
+		statsLogger:      time.NewTicker(statsLogInterval),
 	}
 }
 
@@ -124,6 +163,7 @@ func (nc *NetworkClient) Connect() error {
 	nc.lastPacketTime.Store(time.Now()) // 初始化最后收包时间
 
 	log.Printf("已连接到服务器: %s", conn.RemoteAddr())
+	session.connected(nc.serverAddr, nc.proto)
 
 	// 启动接收循环
 	nc.wg.Add(1)
@@ -145,9 +185,14 @@ func (nc *NetworkClient) Connect() error {
 }
 
 func (nc *NetworkClient) dial() (net.Conn, error) {
-	switch nc.proto {
+	return dialServer(nc.serverAddr, nc.proto)
+}
+
+// dialServer 按协议建立到服务器的连接（服务器列表的状态查询也使用）
+func dialServer(serverAddr, proto string) (net.Conn, error) {
+	switch proto {
 	case "", "tcp":
-		conn, err := net.DialTimeout("tcp", nc.serverAddr, 5*time.Second)
+		conn, err := net.DialTimeout("tcp", serverAddr, 5*time.Second)
 		if err != nil {
 			return nil, err
 		}
@@ -157,14 +202,16 @@ func (nc *NetworkClient) dial() (net.Conn, error) {
 		}
 		return conn, nil
 	case "kcp":
-		conn, err := kcp.DialWithOptions(nc.serverAddr, nil, 0, 0)
+		conn, err := kcp.DialWithOptions(serverAddr, nil, 0, 0)
 		if err != nil {
 			return nil, err
 		}
 		// 不需要 SetStreamMode，我们使用长度前缀协议处理消息边界
 		return conn, nil
+	case "ws":
+		return dialWebSocket(serverAddr)
 	default:
-		return nil, fmt.Errorf("不支持的协议: %s", nc.proto)
+		return nil, fmt.Errorf("不支持的协议: %s", proto)
 	}
 }
 
@@ -218,6 +265,32 @@ func (nc *NetworkClient) GetGameSeed() int64 {
 	return nc.gameSeed
 }
 
+// TakeRoomHistory 取走最近一次重连收到的房间历史（Reconnect 与大厅界面在同一协程调用）
+func (nc *NetworkClient) TakeRoomHistory() ([]*gamev1.RoomHistoryEntry, bool) {
+	if !nc.roomHistoryPending {
+		return nil, false
+	}
+	history := nc.roomHistory
+	nc.roomHistory = nil
+	nc.roomHistoryPending = false
+	return history, true
+}
+
+// GetMatchConfig 获取当前房间的对局参数
+func (nc *NetworkClient) GetMatchConfig() core.MatchConfig {
+	return nc.matchConfig
+}
+
+// SeriesScore 多回合对局的比分（新回合开局时随房间状态更新，nil 表示单局）
+func (nc *NetworkClient) SeriesScore() *gamev1.SeriesScore {
+	return nc.series
+}
+
+// GetRoomRules 获取当前房间规则
+func (nc *NetworkClient) GetRoomRules() core.GameRules {
+	return nc.roomRules
+}
+
 func (nc *NetworkClient) GetTPS() int32 {
 	return nc.tps
 }
@@ -227,52 +300,92 @@ func (nc *NetworkClient) IsConnected() bool {
 	return nc.connected
 }
 
+// joinMode 加入房间的身份
+type joinMode int
+
+const (
+	joinAsPlayer    joinMode = iota // 普通玩家（仅等待中的房间）
+	joinAsSpectator                 // 观战者
+	joinAsTakeover                  // 接管游戏中的 AI（需房主同意）
+)
+
 // JoinRoom 加入房间
 func (nc *NetworkClient) JoinRoom(roomID string) (*gamev1.JoinResponse, error) {
+	return nc.join(roomID, joinAsPlayer)
+}
+
+// SpectateRoom 以观战者身份加入房间（游戏进行中也可加入）
+func (nc *NetworkClient) SpectateRoom(roomID string) (*gamev1.JoinResponse, error) {
+	return nc.join(roomID, joinAsSpectator)
+}
+
+// TakeOverAI 申请接管游戏中房间的一个 AI，阻塞到房主审批完成（审批期间连接照常收发 Ping）
+func (nc *NetworkClient) TakeOverAI(roomID string) (*gamev1.JoinResponse, error) {
+	return nc.join(roomID, joinAsTakeover)
+}
+
+// IsSpectating 是否以观战者身份在房间中
+func (nc *NetworkClient) IsSpectating() bool {
+	return nc.spectating
+}
+
+func (nc *NetworkClient) join(roomID string, mode joinMode) (*gamev1.JoinResponse, error) {
 	if !nc.connected {
 		return nil, errors.New("未连接到服务器")
 	}
 
-	for {
-		select {
-		case <-nc.joinRespChan:
-			continue
-		default:
-		}
-		break
-	}
-
-	if err := nc.sendJoinRequest(roomID); err != nil {
+	f := newFuture[*gamev1.JoinResponse](nc)
+	if err := nc.sendJoinRequest(f.id, roomID, mode); err != nil {
+		nc.requests.forget(f.id)
 		return nil, fmt.Errorf("发送加入请求失败: %w", err)
 	}
 
 	select {
-	case resp := <-nc.joinRespChan:
+	case m := <-f.done:
+		resp, _ := m.(*gamev1.JoinResponse)
 		if resp == nil {
 			return nil, errors.New("加入响应为空")
 		}
 		if !resp.Success {
-			return nil, fmt.Errorf("加入失败: %s", resp.ErrorMessage)
+			return nil, &JoinError{Code: resp.ErrorCode, Params: resp.ErrorParams, Message: resp.ErrorMessage}
 		}
 		nc.playerID = resp.PlayerId
 		nc.gameSeed = resp.GameSeed
+		nc.roomRules = protocol.ProtoRulesToCore(resp.RoomState.GetRules())
+		nc.matchConfig = protocol.ProtoMatchConfigToCore(resp.RoomState.GetConfig())
+		nc.trackSeedCommitment(resp.RoomState)
+		nc.trackMap(resp.MapId, resp.RoomState.GetCustomMap(), resp.RoomState.GetCustomMapJson())
 		nc.tps = resp.Tps
 		nc.sessionToken = resp.SessionToken
 		nc.currentRoomID = resp.RoomId
-		log.Printf("加入房间成功: %s (玩家 %d)", resp.RoomId, nc.playerID)
+		nc.spectating = mode == joinAsSpectator
+		nc.applyProfile(resp.Profile)
+		log.Printf("加入房间成功: %s (玩家 %d, 观战: %v)", resp.RoomId, nc.playerID, nc.spectating)
 		return resp, nil
 
 	case err := <-nc.errChan:
+		nc.requests.forget(f.id)
 		return nil, err
 
-	case <-time.After(10 * time.Second):
+	case <-time.After(requestTimeout):
+		nc.requests.forget(f.id)
 		return nil, errors.New("等待加入响应超时")
 	}
 }
 
-// RequestRoomList 请求房间列表
-func (nc *NetworkClient) RequestRoomList(page, pageSize int32) error {
-	packet, err := protocol.NewRoomListRequestPacket(page, pageSize)
+// RequestRoomList 请求一页房间列表，返回的 Future 收到对应的响应（req 的请求 ID 由这里填写）
+func (nc *NetworkClient) RequestRoomList(req *gamev1.RoomListRequest) (*Future[*gamev1.RoomListResponse], error) {
+	f := newFuture[*gamev1.RoomListResponse](nc)
+	req.RequestId = f.id
+	if err := nc.sendRoomListRequest(req); err != nil {
+		nc.requests.forget(f.id)
+		return nil, err
+	}
+	return f, nil
+}
+
+func (nc *NetworkClient) sendRoomListRequest(req *gamev1.RoomListRequest) error {
+	packet, err := protocol.NewRoomListRequestPacket(req)
 	if err != nil {
 		return err
 	}
@@ -283,8 +396,31 @@ func (nc *NetworkClient) RequestRoomList(page, pageSize int32) error {
 	return nc.sendMessage(data)
 }
 
-// SendRoomAction 发送房间操作
-func (nc *NetworkClient) SendRoomAction(action *gamev1.RoomAction) error {
+// SearchReplays 搜索服务器上的录像（结果通过 ReceiveReplaySearch 获取）
+func (nc *NetworkClient) SearchReplays(req *gamev1.ReplaySearchRequest) error {
+	packet, err := protocol.NewReplaySearchRequestPacket(req)
+	if err != nil {
+		return err
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return err
+	}
+	return nc.sendMessage(data)
+}
+
+// RequestRoomAction 发送房间操作，返回的 Future 收到对应的响应（action 的请求 ID 由这里填写）
+func (nc *NetworkClient) RequestRoomAction(action *gamev1.RoomAction) (*Future[*gamev1.RoomActionResponse], error) {
+	f := newFuture[*gamev1.RoomActionResponse](nc)
+	action.RequestId = f.id
+	if err := nc.sendRoomAction(action); err != nil {
+		nc.requests.forget(f.id)
+		return nil, err
+	}
+	return f, nil
+}
+
+func (nc *NetworkClient) sendRoomAction(action *gamev1.RoomAction) error {
 	packet, err := protocol.NewRoomActionPacket(action)
 	if err != nil {
 		return err
@@ -296,12 +432,30 @@ func (nc *NetworkClient) SendRoomAction(action *gamev1.RoomAction) error {
 	return nc.sendMessage(data)
 }
 
+// SetTelemetry 加入房间时是否同意服务器收集本局的输入观察（被举报时随举报保存，需在加入前调用）
+func (nc *NetworkClient) SetTelemetry(enabled bool) {
+	nc.telemetry = enabled
+}
+
+// SendDesyncReport 上报本地状态与服务器校验和不一致
+func (nc *NetworkClient) SendDesyncReport(frameID int32, server, client core.StateChecksum) error {
+	packet, err := protocol.NewDesyncReportPacket(frameID, protocol.CoreChecksumToProto(server), protocol.CoreChecksumToProto(client))
+	if err != nil {
+		return err
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return err
+	}
+	return nc.sendMessage(data)
+}
+
 // LeaveRoom 离开房间
-func (nc *NetworkClient) LeaveRoom() error {
+func (nc *NetworkClient) LeaveRoom() (*Future[*gamev1.RoomActionResponse], error) {
 	action := &gamev1.RoomAction{
 		Type: gamev1.RoomActionType_ROOM_ACTION_LEAVE,
 	}
-	return nc.SendRoomAction(action)
+	return nc.RequestRoomAction(action)
 }
 
 // ========== 消息接收 ==========
@@ -322,45 +476,26 @@ func (nc *NetworkClient) receiveLoop() {
 		// 这是解决 "阻塞在 binary.Read 导致 wg.Wait 卡死" 的关键
 		nc.conn.SetReadDeadline(time.Now().Add(1 * time.Second))
 
-		// 读取消息长度（4 字节）
-		var length uint32
-		if err := binary.Read(nc.conn, binary.BigEndian, &length); err != nil {
-			// 超时不是错误，继续循环以检查 ctx
+		// 读取一个数据包（TCP/KCP 为长度前缀，WebSocket 为一帧）
+		data, err := readPacket(nc.conn)
+		if err != nil {
+			// 还没读到数据就超时不是错误，继续循环以检查 ctx
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				continue
 			}
 			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
 				select {
-				case nc.errChan <- fmt.Errorf("读取长度失败: %w", err):
+				case nc.errChan <- fmt.Errorf("读取消息失败: %w", err):
 				default:
 				}
 			}
 			return
 		}
 
-		// 检查消息大小
-		if length > MaxPacketSize {
-			select {
-			case nc.errChan <- fmt.Errorf("消息过大 (%d bytes)", length):
-			default:
-			}
-			return
-		}
-
-		if length == 0 {
+		if len(data) == 0 {
 			continue
 		}
 
-		// 读取消息体
-		data := make([]byte, length)
-		if _, err := io.ReadFull(nc.conn, data); err != nil {
-			select {
-			case nc.errChan <- fmt.Errorf("读取数据失败: %w", err):
-			default:
-			}
-			return
-		}
-
 		// 处理消息
 		if err := nc.handleMessage(data); err != nil {
 			log.Printf("处理消息失败: %v", err)
@@ -372,26 +507,27 @@ func (nc *NetworkClient) receiveLoop() {
 func (nc *NetworkClient) handleMessage(data []byte) error {
 	nc.lastPacketTime.Store(time.Now())
 
-	pkt, err := protocol.UnmarshalPacket(data)
+	msg, err := DecodeServerPacket(data)
 	if err != nil {
-		return fmt.Errorf("反序列化失败: %w", err)
+		return err
 	}
 
-	switch pkt.Type {
-	case gamev1.MessageType_MESSAGE_TYPE_JOIN_RESPONSE:
-		resp, err := protocol.ParseJoinResponse(pkt)
-		if err != nil {
-			return fmt.Errorf("解析加入响应失败: %w", err)
-		}
+	switch m := msg.(type) {
+	case *gamev1.JoinResponse:
+		nc.requests.resolve(m.RequestId, m)
+
+	case *gamev1.GameState:
+		nc.lastServerFrame = m.FrameId
+		nc.rememberState(m)
 		select {
-		case nc.joinRespChan <- resp:
+		case nc.stateChan <- m:
 		default:
 		}
 
-	case gamev1.MessageType_MESSAGE_TYPE_GAME_STATE:
-		state, err := protocol.ParseGameState(pkt)
-		if err != nil {
-			return fmt.Errorf("解析状态失败: %w", err)
+	case *gamev1.DeltaState:
+		state := nc.applyDeltaState(m)
+		if state == nil {
+			return nil
 		}
 		nc.lastServerFrame = state.FrameId
 		select {
@@ -399,87 +535,95 @@ func (nc *NetworkClient) handleMessage(data []byte) error {
 		default:
 		}
 
-	case gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT:
-		event, err := protocol.ParseGameEvent(pkt)
-		if err != nil {
-			return fmt.Errorf("解析事件失败: %w", err)
+	case *gamev1.GameEvent:
+		if m.EventSeq > 0 && !nc.acceptCriticalEvent(m.EventSeq) {
+			return nil
 		}
 		select {
-		case nc.eventChan <- event:
+		case nc.eventChan <- m:
 		default:
 		}
 
-	case gamev1.MessageType_MESSAGE_TYPE_PING:
-		ping, err := protocol.ParsePing(pkt)
-		if err != nil {
-			return fmt.Errorf("解析 Ping 失败: %w", err)
-		}
-		return nc.sendPong(ping.ClientTime)
+	case *gamev1.Ping:
+		return nc.sendPong(m.ClientTime)
 
-	case gamev1.MessageType_MESSAGE_TYPE_PONG:
-		pong, err := protocol.ParsePong(pkt)
-		if err != nil {
-			return fmt.Errorf("解析 Pong 失败: %w", err)
+	case *gamev1.Pong:
+		nc.handlePong(m)
+
+	case *gamev1.ReconnectResponse:
+		if m.CurrentState != nil {
+			nc.rememberState(m.CurrentState)
 		}
-		nc.handlePong(pong)
-		return nil
+		nc.requests.resolve(m.RequestId, m)
 
-	case gamev1.MessageType_MESSAGE_TYPE_RECONNECT_RESPONSE:
-		resp, err := protocol.ParseReconnectResponse(pkt)
-		if err != nil {
-			return fmt.Errorf("解析重连响应失败: %w", err)
+	case *gamev1.RoomListResponse:
+		nc.requests.resolve(m.RequestId, m)
+
+	case *gamev1.RoomActionResponse:
+		if m.SessionToken != "" {
+			nc.sessionToken = m.SessionToken
 		}
-		select {
-		case nc.reconnectRespChan <- resp:
-		default:
+		nc.currentRoomID = m.RoomId
+		if m.RoomId == "" {
+			nc.playerID = -1
+			nc.spectating = false
 		}
-		return nil
-
-	case gamev1.MessageType_MESSAGE_TYPE_ROOM_LIST_RESPONSE:
-		resp, err := protocol.ParseRoomListResponse(pkt)
-		if err != nil {
-			return fmt.Errorf("解析房间列表失败: %w", err)
+		if nc.requests.resolve(m.RequestId, m) {
+			return nil
 		}
 		select {
-		case nc.roomListChan <- resp:
+		case nc.roomActionChan <- m:
 		default:
 		}
-		return nil
 
-	case gamev1.MessageType_MESSAGE_TYPE_ROOM_ACTION_RESPONSE:
-		resp, err := protocol.ParseRoomActionResponse(pkt)
-		if err != nil {
-			return fmt.Errorf("解析房间操作响应失败: %w", err)
+	case *gamev1.RoomStateUpdate:
+		if m.RoomId != "" {
+			nc.currentRoomID = m.RoomId
 		}
-		if resp.SessionToken != "" {
-			nc.sessionToken = resp.SessionToken
-		}
-		nc.currentRoomID = resp.RoomId
-		if resp.RoomId == "" {
-			nc.playerID = -1
+		// 房主可能在开始前更换种子，客户端需要用新种子生成地图
+		if m.Seed != 0 {
+			nc.gameSeed = m.Seed
 		}
+		nc.roomRules = protocol.ProtoRulesToCore(m.Rules)
+		nc.matchConfig = protocol.ProtoMatchConfigToCore(m.Config)
+		nc.series = m.Series
+		nc.trackSeedCommitment(m)
+		nc.trackMapDefinition(m)
+		// 对局中大厅不读取房间状态，队列满时丢弃最旧的一条，保证回到大厅时拿到最新的（如对局中的房主变更）
 		select {
-		case nc.roomActionChan <- resp:
+		case nc.roomStateChan <- m:
 		default:
+			select {
+			case <-nc.roomStateChan:
+			default:
+			}
+			select {
+			case nc.roomStateChan <- m:
+			default:
+			}
 		}
-		return nil
 
-	case gamev1.MessageType_MESSAGE_TYPE_ROOM_STATE_UPDATE:
-		update, err := protocol.ParseRoomStateUpdate(pkt)
-		if err != nil {
-			return fmt.Errorf("解析房间状态失败: %w", err)
+	case *gamev1.ServerNotice:
+		log.Printf("服务器通知: %s", m.Message)
+		select {
+		case nc.noticeChan <- m:
+		default:
 		}
-		if update.RoomId != "" {
-			nc.currentRoomID = update.RoomId
+
+	case *gamev1.DebugAIState:
+		select {
+		case nc.debugAIChan <- m:
+		default:
 		}
+
+	case *gamev1.ReplaySearchResponse:
 		select {
-		case nc.roomStateChan <- update:
+		case nc.replaySearchChan <- m:
 		default:
 		}
-		return nil
 
-	default:
-		return fmt.Errorf("未知消息类型: %v", pkt.Type)
+	case *gamev1.ProfileUpdateResponse:
+		nc.handleProfileUpdateResponse(m)
 	}
 
 	return nil
@@ -501,16 +645,7 @@ func (nc *NetworkClient) sendLoop() {
 				return
 			}
 
-			// 发送长度前缀（4 字节）
-			length := uint32(len(data))
-			if err := binary.Write(nc.conn, binary.BigEndian, length); err != nil {
-				log.Printf("发送长度失败: %v", err)
-				nc.closeConn() // 使用 closeConn 避免死锁
-				return
-			}
-
-			// 发送数据体
-			if _, err := nc.conn.Write(data); err != nil {
+			if err := writePacket(nc.conn, data); err != nil {
 				log.Printf("发送数据失败: %v", err)
 				nc.closeConn() // 使用 closeConn 避免死锁
 				return
@@ -520,9 +655,13 @@ func (nc *NetworkClient) sendLoop() {
 }
 
 // sendJoinRequest 发送加入请求
-func (nc *NetworkClient) sendJoinRequest(roomID string) error {
+func (nc *NetworkClient) sendJoinRequest(requestID int32, roomID string, mode joinMode) error {
 	protoCharType := protocol.CoreCharacterTypeToProto(nc.character)
-	packet, err := protocol.NewJoinRequestPacket(nc.playerName, protoCharType, roomID)
+	if nc.account.key != "" && !nc.account.keepCharacter {
+		// 未指定角色：由服务器使用账号保存的角色
+		protoCharType = gamev1.CharacterType_CHARACTER_TYPE_UNSPECIFIED
+	}
+	packet, err := protocol.NewJoinRequestPacket(requestID, nc.playerName, protoCharType, roomID, mode == joinAsSpectator, mode == joinAsTakeover, nc.account.key, nc.telemetry)
 	if err != nil {
 		return err
 	}
@@ -547,6 +686,34 @@ func (nc *NetworkClient) sendPong(clientTime int64) error {
 	return nc.sendMessage(data)
 }
 
+// acceptCriticalEvent 确认关键事件（重复收到也要确认，上一次的确认可能丢了），返回是否第一次收到
+func (nc *NetworkClient) acceptCriticalEvent(seq int32) bool {
+	if err := nc.sendEventAck(seq); err != nil {
+		log.Printf("确认事件 %d 失败: %v", seq, err)
+	}
+	if nc.seenEventSeqs[seq] {
+		return false
+	}
+	if nc.seenEventSeqs == nil {
+		nc.seenEventSeqs = make(map[int32]bool)
+	}
+	nc.seenEventSeqs[seq] = true
+	return true
+}
+
+// sendEventAck 立即发送事件确认（不等下一个输入包，游戏结束后客户端不再发送输入）
+func (nc *NetworkClient) sendEventAck(seq int32) error {
+	packet, err := protocol.NewEventAckPacket([]int32{seq})
+	if err != nil {
+		return err
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return err
+	}
+	return nc.sendMessage(data)
+}
+
 // sendMessage 发送消息
 func (nc *NetworkClient) sendMessage(data []byte) error {
 	select {
@@ -604,6 +771,7 @@ func (nc *NetworkClient) handlePong(pong *gamev1.Pong) {
 	if nc.rttSampleCount < rttSampleWindow {
 		nc.rttSampleCount++
 	}
+	session.rtt(rtt)
 
 	measuredOffset := pong.ServerTime - (pong.ClientTime + rtt/2)
 	prev := atomic.LoadInt64(&nc.timeOffsetMs)
@@ -642,7 +810,8 @@ func (nc *NetworkClient) SendInputWithSeq(frameID int32, up, down, left, right,
 	nc.inputSeq++
 	seq := nc.inputSeq
 
-	packet, err := protocol.NewClientInputPacket(seq, frameID, up, down, left, right, bomb)
+	inputs := []*gamev1.InputData{{FrameId: frameID, Up: up, Down: down, Left: left, Right: right, Bomb: bomb}}
+	packet, err := protocol.NewClientInputPacketWithAck(seq, inputs, nc.ackStateFrame.Load())
 	if err != nil {
 		log.Printf("构造输入失败: %v", err)
 		return seq
@@ -670,7 +839,7 @@ func (nc *NetworkClient) SendInputBatch(inputs []*gamev1.InputData) int32 {
 	nc.inputSeq++
 	seq := nc.inputSeq
 
-	packet, err := protocol.NewClientInputPacketWithInputs(seq, inputs)
+	packet, err := protocol.NewClientInputPacketWithAck(seq, inputs, nc.ackStateFrame.Load())
 	if err != nil {
 		log.Printf("构造批量输入失败: %v", err)
 		return seq
@@ -711,30 +880,51 @@ func (nc *NetworkClient) ReceiveEvent() *gamev1.GameEvent {
 	}
 }
 
-// ReceiveRoomList 接收房间列表（非阻塞）
-func (nc *NetworkClient) ReceiveRoomList() *gamev1.RoomListResponse {
+// ReceiveRoomState 接收房间状态（非阻塞）
+func (nc *NetworkClient) ReceiveRoomState() *gamev1.RoomStateUpdate {
 	select {
-	case resp := <-nc.roomListChan:
+	case update := <-nc.roomStateChan:
+		return update
+	default:
+		return nil
+	}
+}
+
+// ReceiveRoomActionResponse 接收没有对应 Future 的房间操作响应（非阻塞）：服务器主动发送的和超时后才到达的，
+// 其余响应由 RequestRoomAction 返回的 Future 接收
+func (nc *NetworkClient) ReceiveRoomActionResponse() *gamev1.RoomActionResponse {
+	select {
+	case resp := <-nc.roomActionChan:
 		return resp
 	default:
 		return nil
 	}
 }
 
-// ReceiveRoomState 接收房间状态（非阻塞）
-func (nc *NetworkClient) ReceiveRoomState() *gamev1.RoomStateUpdate {
+// ReceiveNotice 接收服务器通知（非阻塞）
+func (nc *NetworkClient) ReceiveNotice() *gamev1.ServerNotice {
 	select {
-	case update := <-nc.roomStateChan:
-		return update
+	case notice := <-nc.noticeChan:
+		return notice
 	default:
 		return nil
 	}
 }
 
-// ReceiveRoomActionResponse 接收房间操作响应（非阻塞）
-func (nc *NetworkClient) ReceiveRoomActionResponse() *gamev1.RoomActionResponse {
+// ReceiveDebugAIState 接收 AI 调试信息（非阻塞，只有调试房间会下发）
+func (nc *NetworkClient) ReceiveDebugAIState() *gamev1.DebugAIState {
 	select {
-	case resp := <-nc.roomActionChan:
+	case state := <-nc.debugAIChan:
+		return state
+	default:
+		return nil
+	}
+}
+
+// ReceiveReplaySearch 接收录像搜索结果（非阻塞）
+func (nc *NetworkClient) ReceiveReplaySearch() *gamev1.ReplaySearchResponse {
+	select {
+	case resp := <-nc.replaySearchChan:
 		return resp
 	default:
 		return nil
@@ -854,9 +1044,15 @@ func (nc *NetworkClient) Reconnect() (*gamev1.GameState, error) {
 	nc.resetInternalState()
 	log.Printf("[重连] 内部状态已重置")
 
-	// 3. 重新建立连接（重连时使用 KCP）
-	log.Printf("[重连] 正在建立 KCP 连接到 %s...", nc.serverAddr)
-	conn, err := nc.dialKCP()
+	// 3. 重新建立连接（重连时使用 KCP；WebSocket 的地址不能用于 KCP，仍使用 WebSocket）
+	reconnectProto := "KCP"
+	dial := nc.dialKCP
+	if nc.proto == "ws" {
+		reconnectProto = "WebSocket"
+		dial = nc.dial
+	}
+	log.Printf("[重连] 正在建立 %s 连接到 %s...", reconnectProto, nc.serverAddr)
+	conn, err := dial()
 	if err != nil {
 		return nil, fmt.Errorf("重连失败: %w", err)
 	}
@@ -864,7 +1060,7 @@ func (nc *NetworkClient) Reconnect() (*gamev1.GameState, error) {
 	nc.connected = true
 	nc.lastPacketTime.Store(time.Now()) // 重置最后收包时间
 
-	log.Printf("[重连] KCP 连接已建立: %s", conn.RemoteAddr())
+	log.Printf("[重连] %s 连接已建立: %s", reconnectProto, conn.RemoteAddr())
 
 	// 4. 启动工作 goroutine
 	nc.wg.Add(1)
@@ -883,7 +1079,9 @@ func (nc *NetworkClient) Reconnect() (*gamev1.GameState, error) {
 
 	// 5. 发送重连请求
 	log.Printf("[重连] 发送重连请求 (token: %s...)", nc.sessionToken[:min(8, len(nc.sessionToken))])
-	if err := nc.sendReconnectRequest(); err != nil {
+	f := newFuture[*gamev1.ReconnectResponse](nc)
+	if err := nc.sendReconnectRequest(f.id); err != nil {
+		nc.requests.forget(f.id)
 		nc.Close()
 		return nil, fmt.Errorf("发送重连请求失败: %w", err)
 	}
@@ -891,23 +1089,36 @@ func (nc *NetworkClient) Reconnect() (*gamev1.GameState, error) {
 	// 6. 等待重连响应
 	log.Printf("[重连] 等待服务器响应...")
 	select {
-	case resp := <-nc.reconnectRespChan:
+	case m := <-f.done:
+		resp, _ := m.(*gamev1.ReconnectResponse)
 		if resp == nil {
 			nc.Close()
 			return nil, errors.New("重连响应为空")
 		}
 		if !resp.Success {
 			nc.Close()
-			return nil, fmt.Errorf("重连失败: %s", resp.ErrorMessage)
+			switch resp.ErrorCode {
+			case gamev1.ErrorCode_ERROR_CODE_SESSION_INVALID, gamev1.ErrorCode_ERROR_CODE_SESSION_EXPIRED:
+				// 令牌不会再变得有效，清空后重连界面提示回到大厅
+				nc.sessionToken = ""
+			}
+			return nil, fmt.Errorf("重连失败: %s", friendlyErrorMessage(resp.ErrorCode, resp.ErrorParams, resp.ErrorMessage))
+		}
+		if resp.SessionToken != "" {
+			nc.sessionToken = resp.SessionToken
 		}
 		log.Printf("[重连] 重连成功！玩家 ID: %d", nc.playerID)
+		nc.roomHistory = resp.History
+		nc.roomHistoryPending = true
 		return resp.CurrentState, nil
 
 	case err := <-nc.errChan:
+		nc.requests.forget(f.id)
 		nc.Close()
 		return nil, fmt.Errorf("重连时发生错误: %w", err)
 
-	case <-time.After(10 * time.Second):
+	case <-time.After(requestTimeout):
+		nc.requests.forget(f.id)
 		nc.Close()
 		return nil, errors.New("等待重连响应超时")
 	}
@@ -928,17 +1139,21 @@ func (nc *NetworkClient) resetInternalState() {
 	// 3. 重建通道（确保干净的状态）
 	nc.stateChan = make(chan *gamev1.GameState, 256)
 	nc.eventChan = make(chan *gamev1.GameEvent, 64)
-	nc.joinRespChan = make(chan *gamev1.JoinResponse, 1)
-	nc.reconnectRespChan = make(chan *gamev1.ReconnectResponse, 1)
-	nc.roomListChan = make(chan *gamev1.RoomListResponse, 4)
 	nc.roomStateChan = make(chan *gamev1.RoomStateUpdate, 8)
 	nc.roomActionChan = make(chan *gamev1.RoomActionResponse, 4)
+	nc.noticeChan = make(chan *gamev1.ServerNotice, 4)
+	nc.debugAIChan = make(chan *gamev1.DebugAIState, 4)
+	nc.replaySearchChan = make(chan *gamev1.ReplaySearchResponse, 4)
 	nc.sendChan = make(chan []byte, 256)
 	nc.errChan = make(chan error, 1)
+	nc.requests.reset()
 
 	// 4. 重置输入序列号（重要！服务器会忽略过期的序列号）
 	nc.inputSeq = 0
 	nc.lastServerFrame = 0
+	nc.stateBaselines = nil
+	nc.ackStateFrame.Store(0)
+	nc.seenEventSeqs = nil
 
 	// 5. 重置 RTT 统计
 	nc.rttSamples = make([]int64, rttSampleWindow)
@@ -973,45 +1188,45 @@ drainEvent:
 		select {
 		case <-nc.eventChan:
 		default:
-			goto drainJoin
+			goto drainRoomState
 		}
 	}
-drainJoin:
+drainRoomState:
 	for {
 		select {
-		case <-nc.joinRespChan:
+		case <-nc.roomStateChan:
 		default:
-			goto drainReconnect
+			goto drainRoomAction
 		}
 	}
-drainReconnect:
+drainRoomAction:
 	for {
 		select {
-		case <-nc.reconnectRespChan:
+		case <-nc.roomActionChan:
 		default:
-			goto drainRoomList
+			goto drainNotice
 		}
 	}
-drainRoomList:
+drainNotice:
 	for {
 		select {
-		case <-nc.roomListChan:
+		case <-nc.noticeChan:
 		default:
-			goto drainRoomState
+			goto drainDebugAI
 		}
 	}
-drainRoomState:
+drainDebugAI:
 	for {
 		select {
-		case <-nc.roomStateChan:
+		case <-nc.debugAIChan:
 		default:
-			goto drainRoomAction
+			goto drainReplaySearch
 		}
 	}
-drainRoomAction:
+drainReplaySearch:
 	for {
 		select {
-		case <-nc.roomActionChan:
+		case <-nc.replaySearchChan:
 		default:
 			goto drainSend
 		}
@@ -1035,8 +1250,9 @@ drainErr:
 }
 
 // sendReconnectRequest 发送重连请求
-func (nc *NetworkClient) sendReconnectRequest() error {
+func (nc *NetworkClient) sendReconnectRequest(requestID int32) error {
 	req := &gamev1.ReconnectRequest{
+		RequestId:    requestID,
 		SessionToken: nc.sessionToken,
 	}
 
@@ -1068,6 +1284,24 @@ func (nc *NetworkClient) CanReconnect() bool {
 	return nc.sessionToken != ""
 }
 
+// Redial 放弃原有会话，重新建立一个全新的连接（断线后放弃重连、回到大厅时使用）
+func (nc *NetworkClient) Redial() error {
+	nc.connected = false
+	nc.cancel()
+	if nc.conn != nil {
+		nc.conn.Close()
+	}
+	nc.wg.Wait()
+
+	nc.resetInternalState()
+	nc.sessionToken = ""
+	nc.playerID = -1
+	nc.currentRoomID = ""
+	nc.spectating = false
+
+	return nc.Connect()
+}
+
 // checkHealthLoop 定期检查连接健康状态
 func (nc *NetworkClient) checkHealthLoop() {
 	defer nc.wg.Done()
@@ -1,7 +1,9 @@
 package client
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"time"
 
 	gamev1 "bomberman/api/gen/bomberman/v1"
@@ -13,15 +15,17 @@ import (
 
 // NetworkGameClient 联机游戏客户端（简化版）
 type NetworkGameClient struct {
-	game           *Game
-	network        *NetworkClient
-	playerID       int
-	playersMap     map[int]*Player
-	inputHistory   []inputFrame
-	pendingInputs  []predictedInput
-	nextInputFrame int32
-	hasAuthState   bool
-	authState      authoritativeState
+	game             *Game
+	network          *NetworkClient
+	playerID         int
+	playersMap       map[int]*Player
+	inputHistory     []inputFrame
+	pendingInputs    []predictedInput
+	nextInputFrame   int32
+	hasAuthState     bool
+	authState        authoritativeState
+	remoteAuth       map[int]remotePosition // 远端玩家最新的权威位置（玩家碰撞预测用）
+	remoteBombFrames map[int]int32          // 远端玩家最近一次放弹的服务器帧（推测阻挡用）
 
 	// 自适应参数
 	lastAdaptiveUpdate time.Time
@@ -30,8 +34,32 @@ type NetworkGameClient struct {
 	reconnecting         bool
 	reconnectDelay       time.Duration
 	lastReconnectAttempt time.Time
+	reconnectAttempts    int        // 本次断线以来发起的重连次数（重连界面显示）
+	giveUpRequested      bool       // 玩家按了放弃，等进行中的重连结束后生效
+	abandoned            bool       // 已放弃重连，由 LobbyClient 回到大厅
+	reconnectKeys        keyTracker // 重连界面的按键
 
 	ignoreBombUntilRelease bool
+	bombKeyDown            bool         // 上一帧是否按着放弹键
+	bombQueuedUntil        int32        // 排队中的放弹按键的截止帧（0 表示没有）
+	bombExtrapolatedFrames int          // 上次快照后滑动炸弹已推算的帧数
+	predictedBombs         []*core.Bomb // 本地预测、尚未被权威状态确认的幽灵炸弹（见 predicted_bombs.go）
+	lastPredictedBombFrame int32        // 最近一次预测放弹的帧
+
+	aiDebug  AIDebugOverlay // AI 调试叠加层（仅调试服务器下发数据）
+	takeover TakeoverPrompt // AI 接管审批提示（房主）与公告
+	killFeed KillFeed       // 击杀播报
+
+	nextRound bool // 回合间歇中收到了下一回合的开局事件（rounds.go）
+
+	seedNotice      string // 开局时的公平种子校验结果
+	seedCheck       SeedCheck
+	seedNoticeUntil time.Time
+
+	desyncCount      int           // 本局发现的校验和不一致次数
+	lastDesyncReport time.Time     // 最近一次上报不同步的时间（限频）
+	syncServer       frameChecksum // 等待比较的帧同步校验（服务器）
+	syncLocal        frameChecksum // 等待比较的帧同步校验（本地）
 }
 
 type inputFrame struct {
@@ -39,6 +67,8 @@ type inputFrame struct {
 	up, down    bool
 	left, right bool
 	bomb        bool
+	shove       bool
+	sprint      bool
 }
 
 type predictedInput struct {
@@ -46,6 +76,12 @@ type predictedInput struct {
 	frameID     int32
 	up, down    bool
 	left, right bool
+	sprint      bool
+}
+
+type remotePosition struct {
+	x, y     float64
+	isMoving bool
 }
 
 type authoritativeState struct {
@@ -53,27 +89,38 @@ type authoritativeState struct {
 	x, y             float64
 	direction        core.DirectionType
 	isMoving         bool
+	stamina          int   // 冲刺体力（重放未确认的冲刺输入从这里开始扣）
+	sprinting        bool  // 服务器最后一次应用的输入是否在冲刺
 	lastProcessedSeq int32 // 服务器确认的最后输入序号
 }
 
 // NewNetworkGameClient 创建联机游戏客户端
 func NewNetworkGameClient(network *NetworkClient, controlScheme ControlScheme) (*NetworkGameClient, error) {
 	game := NewGameWithSeed(network.GetGameSeed())
+	if def := network.GetMapDefinition(); def != nil {
+		game.coreGame.Map = core.NewGameMapFromDefinition(def, network.GetGameSeed())
+		game.mapRenderer = NewMapRenderer(game.coreGame.Map)
+	}
 	game.controlScheme = controlScheme
 
 	// 客户端只渲染状态，不进行权威逻辑
 	game.coreGame.IsAuthoritative = false
+	game.coreGame.Rules = network.GetRoomRules()
+	game.coreGame.Config = network.GetMatchConfig()
 
 	client := &NetworkGameClient{
-		game:           game,
-		network:        network,
-		playerID:       int(network.GetPlayerID()),
-		playersMap:     make(map[int]*Player),
-		reconnectDelay: 2 * time.Second, // 初始重连延迟 2 秒
-	}
-	if controlScheme == ControlArrow && ebiten.IsKeyPressed(ebiten.KeyEnter) {
+		game:             game,
+		network:          network,
+		playerID:         int(network.GetPlayerID()),
+		playersMap:       make(map[int]*Player),
+		remoteAuth:       make(map[int]remotePosition),
+		remoteBombFrames: make(map[int]int32),
+		reconnectDelay:   2 * time.Second, // 初始重连延迟 2 秒
+	}
+	if controlScheme.Input(0).Input().Bomb {
 		client.ignoreBombUntilRelease = true
 	}
+	session.matchStarted()
 
 	return client, nil
 }
@@ -82,20 +129,14 @@ func NewNetworkGameClient(network *NetworkClient, controlScheme ControlScheme) (
 func (ngc *NetworkGameClient) Update() error {
 	now := time.Now()
 
-	// 0. 检查连接状态，触发重连
-	if !ngc.network.IsConnected() && !ngc.reconnecting {
-		if ngc.network.CanReconnect() {
-			// 检查是否可以尝试重连
-			if ngc.lastReconnectAttempt.IsZero() || now.Sub(ngc.lastReconnectAttempt) >= ngc.reconnectDelay {
-				go ngc.tryReconnect()
-			}
-		}
-		// 连接断开时跳过更新
+	// 0. 连接断开或正在重连时只处理重连界面（见 reconnect_overlay.go）
+	if !ngc.network.IsConnected() || ngc.reconnecting {
+		ngc.updateReconnect(now)
 		return nil
 	}
-
-	// 0.1 如果正在重连，跳过更新
-	if ngc.reconnecting {
+	if ngc.giveUpRequested {
+		// 放弃请求发出时重连恰好成功，仍按玩家的选择回到大厅
+		ngc.abandoned = true
 		return nil
 	}
 
@@ -116,6 +157,8 @@ func (ngc *NetworkGameClient) Update() error {
 	}
 	if latestState != nil {
 		ngc.applyServerState(latestState)
+	} else {
+		ngc.extrapolateBombs()
 	}
 
 	// 2. 发送本地输入
@@ -130,7 +173,7 @@ func (ngc *NetworkGameClient) Update() error {
 	ngc.updateRemoteSmoothing()
 
 	// 3. 同步渲染器
-	ngc.game.syncRenderers()
+	ngc.game.syncRenderersIfVisible()
 
 	// 4. 更新玩家动画
 	for _, player := range ngc.game.players {
@@ -139,13 +182,40 @@ func (ngc *NetworkGameClient) Update() error {
 
 	// 5. 处理事件
 	ngc.handleNetworkEvents()
+	ngc.game.playSoundCues()
+	ngc.aiDebug.Update(ngc.network)
+	ngc.takeover.Update(ngc.network, ngc.game.coreGame.CurrentFrame)
+	ngc.game.hud.Update()
+	if ngc.game.caster != nil {
+		ngc.game.caster.Update(ngc.game)
+	}
 
 	return nil
 }
 
+// SetHUDHidden 设置是否隐藏 HUD（F1 可随时切换）
+func (ngc *NetworkGameClient) SetHUDHidden(hidden bool) {
+	ngc.game.SetHUDHidden(hidden)
+}
+
+// SetCaster 开启解说模式：隐藏 HUD 只保留记分板，镜头自动跟随，数字键切换跟随的玩家
+func (ngc *NetworkGameClient) SetCaster() {
+	ngc.game.caster = newCasterView()
+}
+
 // Draw 绘制游戏
 func (ngc *NetworkGameClient) Draw(screen *ebiten.Image) {
 	ngc.game.Draw(screen)
+	if ngc.game.caster != nil {
+		return
+	}
+	if ngc.game.hud.Visible() {
+		ngc.aiDebug.Draw(screen, ngc.game.coreGame)
+		ngc.killFeed.Draw(screen, ngc.game.coreGame.CurrentFrame)
+	}
+	ngc.takeover.Draw(screen, ngc.game.coreGame.CurrentFrame, ngc.game.hud.Visible())
+	ngc.drawSeedNotice(screen)
+	ngc.drawReconnectOverlay(screen)
 }
 
 // Layout 设置布局
@@ -206,10 +276,14 @@ func (ngc *NetworkGameClient) applyServerState(state *gamev1.GameState) {
 				y:                protoPlayer.Y,
 				direction:        corePlayer.Direction,
 				isMoving:         protoPlayer.IsMoving,
+				stamina:          int(protoPlayer.Stamina),
+				sprinting:        protoPlayer.Sprinting,
 				lastProcessedSeq: lastSeq,
 			}
 			ngc.hasAuthState = true
 		} else if playerRenderer.smoother != nil {
+			ngc.remoteAuth[playerID] = remotePosition{x: protoPlayer.X, y: protoPlayer.Y, isMoving: protoPlayer.IsMoving}
+			ngc.noteRemoteBomb(playerRenderer, protoPlayer, state.FrameId)
 			playerRenderer.smoother.AddStateSnapshot(
 				serverTimeMs,
 				protoPlayer.X,
@@ -218,16 +292,30 @@ func (ngc *NetworkGameClient) applyServerState(state *gamev1.GameState) {
 				protoPlayer.IsMoving,
 			)
 		}
+		playerRenderer.outOfView = false
 		corePlayer.Dead = protoPlayer.Dead
 		corePlayer.Character = protocol.ProtoCharacterTypeToCore(protoPlayer.Character)
+		corePlayer.Team = int(protoPlayer.Team)
+		corePlayer.CanKick = protoPlayer.CanKick
+		corePlayer.Stamina = int(protoPlayer.Stamina)
+		corePlayer.Sprinting = protoPlayer.Sprinting
+		corePlayer.Kills = int(protoPlayer.Kills)
 		corePlayer.NextPlacementFrame = int32(protoPlayer.NextPlacementFrame)
 		corePlayer.MaxBombs = int(protoPlayer.MaxBombs)
+		if protoPlayer.BombRange > 0 {
+			corePlayer.BombRange = int(protoPlayer.BombRange)
+		}
+		if protoPlayer.Speed > 0 {
+			corePlayer.Speed = protoPlayer.Speed
+		}
+		playerRenderer.authActiveBombs = int(protoPlayer.CurrentBombs)
+		playerRenderer.hasAuthBombs = true
 
 	}
 
-	// 移除已不存在的玩家
+	// 移除已不存在的玩家（开启兴趣管理时只是离开了视野）
 	for playerID, playerRenderer := range ngc.playersMap {
-		if _, ok := activePlayers[playerID]; ok {
+		if _, ok := activePlayers[playerID]; ok || ngc.markOutOfView(state, playerRenderer) {
 			continue
 		}
 
@@ -244,12 +332,22 @@ func (ngc *NetworkGameClient) applyServerState(state *gamev1.GameState) {
 			}
 		}
 		delete(ngc.playersMap, playerID)
+		delete(ngc.remoteAuth, playerID)
+		delete(ngc.remoteBombFrames, playerID)
 		log.Printf("玩家 %d 离开（状态同步）", playerID)
 	}
 
 	ngc.syncBombs(state.Bombs)
+	ngc.reconcilePredictedBombs(state.FrameId)
 	ngc.syncExplosions(state.Explosions)
-	ngc.applyTileChanges(state.TileChanges)
+	ngc.syncItems(state.Items)
+	ngc.syncPlayerEffects(state.PlayerEffects, state.FrameId)
+	ngc.game.hazards = protocol.ProtoHazardsToCore(state.Hazards)
+	ngc.game.suddenDeathWarnings = protocol.ProtoGridCellsToCore(state.WarningTiles)
+	ngc.game.coreGame.OvertimeFloodFrame = state.OvertimeFloodFrame
+	ngc.game.coreGame.Boss = protocol.ProtoBossToCore(state.Boss)
+	ngc.game.applyRemoteTileChanges(state.TileChanges)
+	ngc.checkStateChecksum(state)
 }
 
 func (ngc *NetworkGameClient) updateRemoteSmoothing() {
@@ -268,6 +366,7 @@ func (ngc *NetworkGameClient) updateRemoteSmoothing() {
 
 // syncBombs 同步炸弹
 func (ngc *NetworkGameClient) syncBombs(protoBombs []*gamev1.BombState) {
+	ngc.bombExtrapolatedFrames = 0
 	ngc.game.coreGame.Bombs = ngc.game.coreGame.Bombs[:0]
 	for _, protoBomb := range protoBombs {
 		bomb := protocol.ProtoBombToCore(protoBomb)
@@ -288,13 +387,27 @@ func (ngc *NetworkGameClient) syncExplosions(protoExplosions []*gamev1.Explosion
 	}
 }
 
-// applyTileChanges 同步地图变化
-func (ngc *NetworkGameClient) applyTileChanges(changes []*gamev1.TileChange) {
-	if len(changes) == 0 {
-		return
+// syncItems 同步道具
+func (ngc *NetworkGameClient) syncItems(protoItems []*gamev1.ItemState) {
+	ngc.game.coreGame.Items = ngc.game.coreGame.Items[:0]
+	for _, protoItem := range protoItems {
+		item := protocol.ProtoItemToCore(protoItem)
+		if item != nil {
+			ngc.game.coreGame.Items = append(ngc.game.coreGame.Items, item)
+		}
 	}
-	for _, tc := range changes {
-		ngc.game.coreGame.Map.SetTile(int(tc.X), int(tc.Y), core.TileType(tc.NewType))
+}
+
+// syncPlayerEffects 同步玩家效果（未出现在列表中的玩家视为无效果）
+func (ngc *NetworkGameClient) syncPlayerEffects(protoEffects []*gamev1.PlayerEffects, frameID int32) {
+	effects := make(map[int][]core.Effect, len(protoEffects))
+	for _, pe := range protoEffects {
+		if pe != nil {
+			effects[int(pe.PlayerId)] = protocol.ProtoPlayerEffectsToCore(pe, frameID)
+		}
+	}
+	for playerID, playerRenderer := range ngc.playersMap {
+		playerRenderer.corePlayer.Effects = effects[playerID]
 	}
 }
 
@@ -310,7 +423,7 @@ func (ngc *NetworkGameClient) handleInput() {
 		return
 	}
 
-	up, down, left, right, bomb := getInputState(ngc.game.controlScheme)
+	up, down, left, right, bomb, shove, sprint := getInputState(ngc.game.controlScheme.Input(0))
 	if ngc.ignoreBombUntilRelease {
 		if bomb {
 			bomb = false
@@ -330,10 +443,11 @@ func (ngc *NetworkGameClient) handleInput() {
 	}
 	targetFrame := ngc.nextInputFrame
 	ngc.nextInputFrame++
+	bomb = ngc.queueBomb(localPlayer, bomb, targetFrame)
 
 	if len(ngc.inputHistory) > 0 && ngc.inputHistory[len(ngc.inputHistory)-1].frameID == targetFrame {
 		last := &ngc.inputHistory[len(ngc.inputHistory)-1]
-		last.up, last.down, last.left, last.right, last.bomb = up, down, left, right, bomb
+		last.up, last.down, last.left, last.right, last.bomb, last.shove, last.sprint = up, down, left, right, bomb, shove, sprint
 	} else {
 		ngc.inputHistory = append(ngc.inputHistory, inputFrame{
 			frameID: targetFrame,
@@ -342,6 +456,8 @@ func (ngc *NetworkGameClient) handleInput() {
 			left:    left,
 			right:   right,
 			bomb:    bomb,
+			shove:   shove,
+			sprint:  sprint,
 		})
 		if len(ngc.inputHistory) > InputBufferSize {
 			ngc.inputHistory = ngc.inputHistory[len(ngc.inputHistory)-InputBufferSize:]
@@ -362,19 +478,56 @@ func (ngc *NetworkGameClient) handleInput() {
 			Left:    item.left,
 			Right:   item.right,
 			Bomb:    item.bomb,
+			Shove:   item.shove,
+			Sprint:  item.sprint,
 		})
 	}
 	seq := ngc.network.SendInputBatch(inputs)
 
 	// 应用预测输入，记录 seq
-	ngc.applyPredictedInput(seq, targetFrame, up, down, left, right)
+	// 放弹和推人只由服务器判定：被推的玩家位置由服务器下发，
+	// 自己被推开时误差超过平滑阈值，纠偏会直接跳到权威位置；放弹只在本地显示幽灵炸弹
+	ngc.applyPredictedInput(seq, targetFrame, up, down, left, right, sprint)
+	if bomb {
+		ngc.predictBomb(localPlayer, targetFrame)
+	}
+}
+
+// queueBomb 放弹按键排队：按下的瞬间本地判断还放不了时，继续替玩家按住，
+// 直到可以放置的那一帧或排队超时（服务器另有同样长度的缓冲兜底）
+func (ngc *NetworkGameClient) queueBomb(local *Player, bomb bool, targetFrame int32) bool {
+	pressed := bomb && !ngc.bombKeyDown
+	ngc.bombKeyDown = bomb
+
+	if pressed && !canPlaceBombAt(local, targetFrame) {
+		ngc.bombQueuedUntil = targetFrame + core.BombInputBufferFrames
+	}
+	if ngc.bombQueuedUntil == 0 {
+		return bomb
+	}
+	if targetFrame > ngc.bombQueuedUntil {
+		ngc.bombQueuedUntil = 0
+		return bomb
+	}
+	if canPlaceBombAt(local, targetFrame) {
+		ngc.bombQueuedUntil = 0
+	}
+	return true
+}
+
+// canPlaceBombAt 按最新的权威状态估计本地玩家在指定帧能否放弹
+func canPlaceBombAt(local *Player, frame int32) bool {
+	if local.corePlayer.NextPlacementFrame > frame {
+		return false
+	}
+	return !local.hasAuthBombs || local.authActiveBombs < local.corePlayer.MaxBombs
 }
 
-func (ngc *NetworkGameClient) applyPredictedInput(seq int32, frameID int32, up, down, left, right bool) {
+func (ngc *NetworkGameClient) applyPredictedInput(seq int32, frameID int32, up, down, left, right, sprint bool) {
 	// 如果最后一个 pending 的 seq 相同，则更新（同一帧多次调用）
 	if len(ngc.pendingInputs) > 0 && ngc.pendingInputs[len(ngc.pendingInputs)-1].seq == seq {
 		last := &ngc.pendingInputs[len(ngc.pendingInputs)-1]
-		last.up, last.down, last.left, last.right = up, down, left, right
+		last.up, last.down, last.left, last.right, last.sprint = up, down, left, right, sprint
 	} else {
 		ngc.pendingInputs = append(ngc.pendingInputs, predictedInput{
 			seq:     seq,
@@ -383,6 +536,7 @@ func (ngc *NetworkGameClient) applyPredictedInput(seq int32, frameID int32, up,
 			down:    down,
 			left:    left,
 			right:   right,
+			sprint:  sprint,
 		})
 		// 限制缓冲区大小
 		if len(ngc.pendingInputs) > InputBufferSize {
@@ -395,13 +549,49 @@ func (ngc *NetworkGameClient) applyPredictedInput(seq int32, frameID int32, up,
 		return
 	}
 
-	core.ApplyInput(ngc.game.coreGame, ngc.playerID, core.Input{
-		Up:    up,
-		Down:  down,
-		Left:  left,
-		Right: right,
-		Bomb:  false,
-	}, frameID)
+	ngc.withAuthoritativeRemotes(func() {
+		ngc.withSpeculativeBombs(frameID, func() {
+			core.ApplyInput(ngc.game.coreGame, ngc.playerID, core.Input{
+				Up:     up,
+				Down:   down,
+				Left:   left,
+				Right:  right,
+				Bomb:   false,
+				Sprint: sprint,
+			}, frameID)
+		})
+	})
+}
+
+// withAuthoritativeRemotes 玩家碰撞开启时，预测期间把远端玩家临时放回最新的权威位置
+// 远端玩家的渲染位置经过插值、落后于服务器，用它做碰撞会让预测结果与服务器不一致
+func (ngc *NetworkGameClient) withAuthoritativeRemotes(fn func()) {
+	if !ngc.game.coreGame.Rules.PlayerCollision {
+		fn()
+		return
+	}
+
+	type renderedPosition struct {
+		player *core.Player
+		x, y   float64
+	}
+	restore := make([]renderedPosition, 0, len(ngc.remoteAuth))
+	for playerID, pos := range ngc.remoteAuth {
+		remote := ngc.playersMap[playerID]
+		if remote == nil || remote.corePlayer == nil {
+			continue
+		}
+		restore = append(restore, renderedPosition{player: remote.corePlayer, x: remote.corePlayer.X, y: remote.corePlayer.Y})
+		remote.corePlayer.X = pos.x
+		remote.corePlayer.Y = pos.y
+	}
+
+	fn()
+
+	for _, r := range restore {
+		r.player.X = r.x
+		r.player.Y = r.y
+	}
 }
 
 func (ngc *NetworkGameClient) reconcileLocalPlayer(state authoritativeState) {
@@ -431,17 +621,24 @@ func (ngc *NetworkGameClient) reconcileLocalPlayer(state authoritativeState) {
 	local.corePlayer.Y = state.y
 	local.corePlayer.Direction = state.direction
 	local.corePlayer.IsMoving = state.isMoving
-
-	// 重放未确认的输入
-	for _, in := range ngc.pendingInputs {
-		core.ApplyInput(ngc.game.coreGame, ngc.playerID, core.Input{
-			Up:    in.up,
-			Down:  in.down,
-			Left:  in.left,
-			Right: in.right,
-			Bomb:  false,
-		}, in.frameID)
-	}
+	local.corePlayer.Stamina = state.stamina
+	local.corePlayer.Sprinting = state.sprinting
+
+	// 重放未确认的输入（与实时预测使用同样的碰撞来源，包括推测的炸弹）
+	ngc.withAuthoritativeRemotes(func() {
+		ngc.withSpeculativeBombs(ngc.nextInputFrame, func() {
+			for _, in := range ngc.pendingInputs {
+				core.ApplyInput(ngc.game.coreGame, ngc.playerID, core.Input{
+					Up:     in.up,
+					Down:   in.down,
+					Left:   in.left,
+					Right:  in.right,
+					Bomb:   false,
+					Sprint: in.sprint,
+				}, in.frameID)
+			}
+		})
+	})
 
 	// ===== 3. 纠偏平滑：如果误差小于阈值，使用 LERP 过渡 =====
 	reconcileX := local.corePlayer.X
@@ -452,6 +649,9 @@ func (ngc *NetworkGameClient) reconcileLocalPlayer(state authoritativeState) {
 	errorDist := dx*dx + dy*dy // 使用平方避免开根号
 
 	threshold := ReconciliationSmoothThreshold * ReconciliationSmoothThreshold
+	if errorDist > 0 {
+		session.corrected(math.Sqrt(errorDist), errorDist >= threshold)
+	}
 	if errorDist > 0 && errorDist < threshold {
 		// 小误差：使用 LERP 从预测位置向纠偏位置平滑过渡
 		// 新位置 = 预测位置 + (纠偏位置 - 预测位置) * factor
@@ -470,11 +670,23 @@ func (ngc *NetworkGameClient) handleNetworkEvents() {
 		}
 
 		switch e := event.Event.(type) {
+		case *gamev1.GameEvent_RoundEnd:
+			ngc.onRoundEnd(e.RoundEnd)
+		case *gamev1.GameEvent_GameStart:
+			if ngc.game.intermission != nil {
+				ngc.nextRound = true
+			}
 		case *gamev1.GameEvent_GameOver:
 			ngc.game.gameOver = true
-			winnerID := e.GameOver.WinnerId
-			message := ngc.formatGameOverMessage(winnerID)
+			message := ngc.formatGameOverMessage(e.GameOver.WinnerId)
+			if team := e.GameOver.WinningTeam; team != 0 {
+				message = ngc.formatTeamGameOverMessage(int(team))
+			}
+			if ngc.game.coreGame.Boss != nil {
+				message = formatBossGameOverMessage(e.GameOver.BossDefeated)
+			}
 			ngc.game.SetGameOverMessage(message)
+			ngc.game.SetGameOverDetail(ngc.formatInputStats(e.GameOver.PlayerStats))
 		case *gamev1.GameEvent_PlayerLeft:
 			playerID := int(e.PlayerLeft.PlayerId)
 			if playerRenderer, exists := ngc.playersMap[playerID]; exists {
@@ -491,8 +703,41 @@ func (ngc *NetworkGameClient) handleNetworkEvents() {
 					}
 				}
 				delete(ngc.playersMap, playerID)
+				delete(ngc.remoteAuth, playerID)
+				delete(ngc.remoteBombFrames, playerID)
+				delete(ngc.game.offline, playerID)
 				log.Printf("玩家 %d 离开", playerID)
 			}
+		case *gamev1.GameEvent_SpectatorJoined:
+			ngc.game.spectatorCount = e.SpectatorJoined.SpectatorCount
+			log.Printf("观战者 %s 加入", e.SpectatorJoined.Name)
+		case *gamev1.GameEvent_SpectatorLeft:
+			ngc.game.spectatorCount = e.SpectatorLeft.SpectatorCount
+		case *gamev1.GameEvent_PlayerConnectionState:
+			ngc.onConnectionState(e.PlayerConnectionState)
+		case *gamev1.GameEvent_OvertimeCountdown:
+			ngc.onOvertimeCountdown(e.OvertimeCountdown)
+		case *gamev1.GameEvent_SyncCheck:
+			ngc.onSyncCheck(event.FrameId, e.SyncCheck)
+		case *gamev1.GameEvent_EntityVisibility:
+			ngc.onEntityVisibility(event.FrameId, e.EntityVisibility)
+		case *gamev1.GameEvent_DoorCampPing:
+			ping := e.DoorCampPing
+			ngc.game.addDoorPing(ping.PlayerId, int(ping.GridX), int(ping.GridY))
+		case *gamev1.GameEvent_PlayerDied:
+			ngc.killFeed.Add(e.PlayerDied, int32(ngc.playerID), ngc.game.coreGame.CurrentFrame)
+			ngc.game.recordDeath(e.PlayerDied, event.FrameId)
+		case *gamev1.GameEvent_TakeoverRequest:
+			ngc.takeover.OnRequest(e.TakeoverRequest)
+		case *gamev1.GameEvent_HostChanged:
+			ngc.takeover.Notify(hostChangedText(e.HostChanged, int32(ngc.playerID)), ngc.game.coreGame.CurrentFrame)
+			log.Printf("房主变更: %d -> %d", e.HostChanged.PreviousHostId, e.HostChanged.NewHostId)
+		case *gamev1.GameEvent_AiTakeover:
+			ngc.takeover.OnTakeover(e.AiTakeover, ngc.game.coreGame.CurrentFrame)
+			if ngc.game.nameTags != nil {
+				ngc.game.nameTags[int(e.AiTakeover.PlayerId)] = nameTag{text: e.AiTakeover.PlayerName}
+			}
+			log.Printf("%s 接管了 %s", e.AiTakeover.PlayerName, e.AiTakeover.PreviousName)
 		}
 	}
 }
@@ -504,22 +749,10 @@ func (ngc *NetworkGameClient) Close() {
 	}
 }
 
-// getInputState 获取当前输入状态
-func getInputState(scheme ControlScheme) (up, down, left, right, bomb bool) {
-	if scheme == ControlWASD {
-		up = ebiten.IsKeyPressed(ebiten.KeyW)
-		down = ebiten.IsKeyPressed(ebiten.KeyS)
-		left = ebiten.IsKeyPressed(ebiten.KeyA)
-		right = ebiten.IsKeyPressed(ebiten.KeyD)
-		bomb = ebiten.IsKeyPressed(ebiten.KeySpace)
-	} else {
-		up = ebiten.IsKeyPressed(ebiten.KeyArrowUp)
-		down = ebiten.IsKeyPressed(ebiten.KeyArrowDown)
-		left = ebiten.IsKeyPressed(ebiten.KeyArrowLeft)
-		right = ebiten.IsKeyPressed(ebiten.KeyArrowRight)
-		bomb = ebiten.IsKeyPressed(ebiten.KeyEnter)
-	}
-	return
+// getInputState 获取当前输入状态（键盘与手柄合并）
+func getInputState(provider InputProvider) (up, down, left, right, bomb, shove, sprint bool) {
+	in := provider.Input()
+	return in.Up, in.Down, in.Left, in.Right, in.Bomb, in.Shove, in.Sprint
 }
 
 // ========== 自适应网络参数 ==========
@@ -617,6 +850,7 @@ func (ngc *NetworkGameClient) tryReconnect() {
 	log.Printf("连接断开，正在尝试重连...")
 
 	state, err := ngc.network.Reconnect()
+	session.reconnected(err == nil)
 	if err != nil {
 		log.Printf("重连失败: %v", err)
 		ngc.reconnecting = false
@@ -630,6 +864,7 @@ func (ngc *NetworkGameClient) tryReconnect() {
 
 	// 重连成功，恢复延迟
 	ngc.reconnectDelay = 2 * time.Second
+	ngc.reconnectAttempts = 0
 	ngc.reconnecting = false
 
 	log.Printf("重连成功！恢复游戏状态...")
@@ -640,6 +875,27 @@ func (ngc *NetworkGameClient) tryReconnect() {
 	}
 }
 
+// formatInputStats formats the local player's input delay from the post-match stats
+func (ngc *NetworkGameClient) formatInputStats(stats []*gamev1.PlayerMatchStats) string {
+	for _, s := range stats {
+		if s.PlayerId != int32(ngc.playerID) || s.Inputs == 0 {
+			continue
+		}
+		avgMs := float64(s.AvgInputDelayFrames) * 1000 / core.TPS
+		maxMs := core.FramesToMillis(int(s.MaxInputDelayFrames))
+		return fmt.Sprintf("Input lag avg %.0fms max %dms (%d late)", avgMs, maxMs, s.LateInputs)
+	}
+	return ""
+}
+
+// formatTeamGameOverMessage formats the game over message for a team victory
+func (ngc *NetworkGameClient) formatTeamGameOverMessage(team int) string {
+	if p := ngc.game.coreGame.GetPlayer(ngc.playerID); p != nil && p.Team == team {
+		return "Your Team Wins!"
+	}
+	return teamLabel(team) + " Wins!"
+}
+
 // formatGameOverMessage formats the game over message based on winner ID
 func (ngc *NetworkGameClient) formatGameOverMessage(winnerID int32) string {
 	if winnerID == -1 {
@@ -652,12 +908,12 @@ func (ngc *NetworkGameClient) formatGameOverMessage(winnerID int32) string {
 			if winnerID == int32(ngc.playerID) {
 				return "You Win!"
 			}
-			return "Player " + string(rune('A'+p.ID)) + " Wins!"
+			return playerLabel(int32(p.ID)) + " Wins!"
 		}
 	}
 
 	if winnerID == int32(ngc.playerID) {
 		return "You Win!"
 	}
-	return "Player " + string(rune('A'+winnerID)) + " Wins!"
+	return playerLabel(winnerID) + " Wins!"
 }
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	offlineBadgeColor = color.RGBA{255, 200, 80, 255}
+	offlineBadgeBG    = color.RGBA{0, 0, 0, 170}
+)
+
+// onConnectionState 对手断线进入离线保护时记下保护截止时间（角色会停在原地），重连后移除
+func (ngc *NetworkGameClient) onConnectionState(e *gamev1.PlayerConnectionStateEvent) {
+	playerID := int(e.PlayerId)
+	if playerID == ngc.playerID {
+		return
+	}
+	switch e.State {
+	case gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_OFFLINE:
+		if ngc.game.offline == nil {
+			ngc.game.offline = make(map[int]time.Time)
+		}
+		ngc.game.offline[playerID] = time.Now().Add(time.Duration(e.SecondsRemaining) * time.Second)
+	case gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_RECONNECTED:
+		delete(ngc.game.offline, playerID)
+	}
+}
+
+// drawOfflineBadges 在断线的玩家头顶绘制 "reconnecting 47s"，解释角色为什么不动
+// （关乎对局走向，不属于 HUD，始终显示）
+func (g *Game) drawOfflineBadges(screen *ebiten.Image) {
+	if len(g.offline) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, player := range g.players {
+		cp := player.corePlayer
+		deadline, ok := g.offline[cp.ID]
+		if !ok || cp.Dead || player.outOfView {
+			continue
+		}
+		seconds := max(0, int((deadline.Sub(now)+time.Second-1)/time.Second))
+		label := fmt.Sprintf("reconnecting %ds", seconds)
+		x, y := player.GetRenderPosition()
+		centerX := int(x) + cp.Width/2
+		width := textWidth(label) + 8
+		vector.DrawFilledRect(screen, float32(centerX-width/2), float32(y)-32, float32(width), 16, offlineBadgeBG, false)
+		drawCenteredText(screen, label, centerX, int(y)-30, offlineBadgeColor)
+	}
+}
@@ -0,0 +1,127 @@
+package client
+
+import (
+	"image/color"
+	"log"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 单机模式的暂停菜单：Esc 打开/关闭，打开期间 core.Game 不更新。
+// 菜单可以继续、重新开局（换一个种子，重新添加本地玩家和 AI）或退出游戏
+
+// 菜单项
+const (
+	pauseContinue = iota
+	pauseRestart
+	pauseQuit
+)
+
+var pauseMenuItems = []string{"Continue", "Restart", "Quit"}
+
+// pauseSelectKeys 确认菜单项的按键（同时是两套控制方案的放弹键）
+var pauseSelectKeys = []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace}
+
+// pauseMenu 暂停菜单状态
+type pauseMenu struct {
+	open     bool
+	selected int
+	keys     keyTracker
+	setup    func(g *Game) // 重新开局时向新游戏添加本地玩家和 AI
+
+	// 用 Enter/空格 选择继续后，等按键松开再恢复，避免按键被当成放弹
+	waitRelease bool
+}
+
+// EnablePause 开启暂停菜单（单机模式）。setup 向游戏添加本地玩家和 AI，
+// 调用方先用它搭好第一局，重新开局时再对新游戏调用一次
+func (g *Game) EnablePause(setup func(g *Game)) {
+	g.pause = &pauseMenu{setup: setup}
+}
+
+// updatePause 处理暂停菜单的按键，返回本帧是否暂停（暂停时跳过游戏更新）
+func (g *Game) updatePause() (bool, error) {
+	p := g.pause
+	if p == nil {
+		return false, nil
+	}
+	if p.waitRelease {
+		for _, key := range pauseSelectKeys {
+			if ebiten.IsKeyPressed(key) {
+				return true, nil
+			}
+		}
+		p.waitRelease = false
+	}
+
+	if p.keys.JustPressed(ebiten.KeyEscape) {
+		p.open = !p.open
+		p.selected = pauseContinue
+		return p.open, nil
+	}
+	if !p.open {
+		return false, nil
+	}
+
+	if p.keys.JustPressed(ebiten.KeyUp) || p.keys.JustPressed(ebiten.KeyW) {
+		p.selected = (p.selected + len(pauseMenuItems) - 1) % len(pauseMenuItems)
+	}
+	if p.keys.JustPressed(ebiten.KeyDown) || p.keys.JustPressed(ebiten.KeyS) {
+		p.selected = (p.selected + 1) % len(pauseMenuItems)
+	}
+	selected := false
+	for _, key := range pauseSelectKeys {
+		if p.keys.JustPressed(key) {
+			selected = true
+		}
+	}
+	if !selected {
+		return true, nil
+	}
+
+	switch p.selected {
+	case pauseRestart:
+		g.restart()
+	case pauseQuit:
+		return true, ebiten.Termination
+	}
+	p.open = false
+	p.waitRelease = true
+	return true, nil
+}
+
+// restart 换一个种子重新开局：重建地图、渲染器和全部玩家（AI 控制器也重新创建），
+// 保留控制方案、HUD 开关和暂停菜单
+func (g *Game) restart() {
+	fresh := NewGame()
+	fresh.controlScheme = g.controlScheme
+	fresh.hud = g.hud
+	fresh.pause = g.pause
+	g.pause.setup(fresh)
+	*g = *fresh
+	log.Printf("重新开局，种子 %d", g.coreGame.Seed)
+}
+
+// drawPauseMenu 暂停时压暗画面，中间显示菜单
+func (g *Game) drawPauseMenu(screen *ebiten.Image) {
+	if g.pause == nil || !g.pause.open {
+		return
+	}
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, ScreenHeight, color.RGBA{0, 0, 0, 150}, false)
+
+	width := 200
+	height := 2*uiPanelPadding + (len(pauseMenuItems)+2)*uiRowHeight
+	x := (ScreenWidth - width) / 2
+	y := (ScreenHeight - height) / 2
+	drawPanel(screen, x, y, width, height)
+	drawCenteredText(screen, "PAUSED", ScreenWidth/2, y+uiPanelPadding, uiTextPrimary)
+	for i, item := range pauseMenuItems {
+		clr := color.Color(uiTextSecondary)
+		if i == g.pause.selected {
+			item = "> " + item + " <"
+			clr = uiAccent
+		}
+		drawCenteredText(screen, item, ScreenWidth/2, y+uiPanelPadding+(i+2)*uiRowHeight, clr)
+	}
+}
@@ -10,6 +10,9 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
+// playerWalkFrames 行走动画的帧数（与玩家精灵图的列数一致）
+const playerWalkFrames = 4
+
 // PlayerRenderer 玩家渲染器
 type PlayerRenderer struct {
 	corePlayer *core.Player
@@ -26,9 +29,20 @@ type Player struct {
 	isLocal      bool
 	smoother     *RemoteSmoother
 
+	// 双人同屏时第二名本地玩家使用自己的控制方案（否则跟随 Game 的方案）
+	controlScheme    ControlScheme
+	ownControlScheme bool
+
 	// 本地玩家渲染/模拟分离
 	renderX, renderY  float64 // 渲染位置（平滑跟随模拟位置）
 	renderInitialized bool    // 是否已初始化渲染位置
+
+	// 服务器下发的在场炸弹数（联网模式，单机模式直接统计本地炸弹）
+	authActiveBombs int
+	hasAuthBombs    bool
+
+	// 离开本地玩家的视野（联网模式，服务器开启兴趣管理时），不绘制
+	outOfView bool
 }
 
 // NewPlayer 创建新玩家
@@ -49,18 +63,29 @@ func NewPlayer(g *Game, id int, x, y int, charType core.CharacterType, useAI boo
 	}
 
 	if useAI {
-		p.aiController = ai.NewAIController(id)
+		p.aiController = ai.NewAIController(id, ai.DifficultyNormal)
 	}
 
 	return p
 }
 
+// SetControlScheme 为本地玩家指定独立的控制方案（双人同屏）
+func (p *Player) SetControlScheme(scheme ControlScheme) {
+	p.controlScheme = scheme
+	p.ownControlScheme = true
+}
+
 // Update 更新玩家状态（输入处理）
 func (p *Player) Update(controlScheme ControlScheme, coreGame *core.Game, currentFrame int32) {
 	// 处理输入
+	// 双人同屏时第二名本地玩家使用第 2 个手柄
+	input := controlScheme.Input(0)
+	if p.ownControlScheme {
+		input = p.controlScheme.Input(1)
+	}
 	if !p.corePlayer.Dead {
 		if p.isLocal {
-			p.handleInput(controlScheme, coreGame, currentFrame)
+			p.handleInput(input, coreGame, currentFrame)
 		} else if p.aiController != nil {
 			// AI 控制
 			input := p.aiController.Decide(coreGame)
@@ -68,13 +93,17 @@ func (p *Player) Update(controlScheme ControlScheme, coreGame *core.Game, curren
 		}
 	}
 
-	// 更新动画（使用固定时间步长）
-	p.renderer.updateAnimation(core.FrameSeconds)
+	// 更新动画（使用固定时间步长，降频渲染时跳过）
+	if !renderingIdle() {
+		p.renderer.updateAnimation(core.FrameSeconds)
+	}
 }
 
 // UpdateAnimation 仅更新动画（用于网络客户端）
 func (p *Player) UpdateAnimation(deltaTime float64) {
-	p.renderer.updateAnimation(deltaTime)
+	if !renderingIdle() {
+		p.renderer.updateAnimation(deltaTime)
+	}
 
 	// 本地玩家更新渲染位置（平滑跟随模拟位置）
 	if p.isLocal {
@@ -119,39 +148,30 @@ func (p *Player) GetRenderPosition() (float64, float64) {
 	return p.corePlayer.X, p.corePlayer.Y
 }
 
-// handleInput 处理键盘输入
-func (p *Player) handleInput(controlScheme ControlScheme, coreGame *core.Game, currentFrame int32) {
-	// 炸弹按键
-	var bombKeyPressed bool
-	if controlScheme == ControlWASD {
-		bombKeyPressed = ebiten.IsKeyPressed(ebiten.KeySpace)
-	} else {
-		bombKeyPressed = ebiten.IsKeyPressed(ebiten.KeyEnter)
-	}
+// handleInput 处理键盘/手柄输入
+func (p *Player) handleInput(provider InputProvider, coreGame *core.Game, currentFrame int32) {
+	input := provider.Input()
 
-	if bombKeyPressed {
+	// 炸弹按键
+	if input.Bomb {
 		bomb := p.corePlayer.PlaceBomb(coreGame, currentFrame)
 		if bomb != nil {
 			coreGame.AddBomb(bomb)
 		}
 	}
 
-	// 移动距离（现在是像素/帧）
+	// 移动距离（现在是像素/帧），冲刺时加速
 	moveDistance := p.corePlayer.Speed
+	moving := input.Up || input.Down || input.Left || input.Right
+	if p.corePlayer.UpdateSprint(input.Sprint && moving) {
+		moveDistance *= core.SprintSpeedMultiplier
+	}
 
 	// 移动按键
-	var upPressed, downPressed, leftPressed, rightPressed bool
-	if controlScheme == ControlWASD {
-		upPressed = ebiten.IsKeyPressed(ebiten.KeyW)
-		downPressed = ebiten.IsKeyPressed(ebiten.KeyS)
-		leftPressed = ebiten.IsKeyPressed(ebiten.KeyA)
-		rightPressed = ebiten.IsKeyPressed(ebiten.KeyD)
-	} else {
-		upPressed = ebiten.IsKeyPressed(ebiten.KeyArrowUp)
-		downPressed = ebiten.IsKeyPressed(ebiten.KeyArrowDown)
-		leftPressed = ebiten.IsKeyPressed(ebiten.KeyArrowLeft)
-		rightPressed = ebiten.IsKeyPressed(ebiten.KeyArrowRight)
-	}
+	upPressed := input.Up
+	downPressed := input.Down
+	leftPressed := input.Left
+	rightPressed := input.Right
 
 	// 尝试移动
 	if upPressed {
@@ -191,6 +211,12 @@ func (p *Player) Draw(screen *ebiten.Image) {
 	px := float32(renderX) - offset
 	py := float32(renderY) - offset
 
+	if sheet := sprites.players[player.Character]; sheet != nil {
+		frame := sheet.frame(renderer.AnimFrame%sheet.cols, directionRow(player.Direction))
+		drawSprite(screen, frame, float64(px), float64(py), 1, nil, 1)
+		return
+	}
+
 	// 根据方向调整绘制
 	var drawX, drawY float32
 	var bodyWidth, bodyHeight float32
@@ -212,7 +238,7 @@ func (p *Player) Draw(screen *ebiten.Image) {
 	// 绘制手部（根据动画帧）
 	handSize := bodyWidth * 0.25
 	handOffset := float32(0.0)
-	if renderer.AnimFrame == 1 {
+	if renderer.AnimFrame%2 == 1 {
 		handOffset = 2.0
 	}
 
@@ -225,7 +251,7 @@ func (p *Player) Draw(screen *ebiten.Image) {
 	// 绘制脚（根据动画帧）
 	footSize := bodyWidth * 0.3
 	footOffset := float32(0.0)
-	if renderer.AnimFrame == 1 {
+	if renderer.AnimFrame%2 == 1 {
 		footOffset = 2.0
 	}
 
@@ -283,11 +309,11 @@ func (r *PlayerRenderer) updateAnimation(deltaTime float64) {
 		return
 	}
 
-	// 动画速度：每0.15秒切换一帧
+	// 动画速度：每0.15秒切换一帧（精灵图有 4 帧行走动画，矢量绘制只区分奇偶帧）
 	r.AnimTime += deltaTime
 	if r.AnimTime >= 0.15 {
 		r.AnimTime = 0
-		r.AnimFrame = (r.AnimFrame + 1) % 2
+		r.AnimFrame = (r.AnimFrame + 1) % playerWalkFrames
 	}
 }
 
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	playerStatsHeight = 18
+	playerStatsMargin = 4
+
+	staminaBarWidth  = 80
+	staminaBarHeight = 5
+)
+
+var (
+	playerStatsBackground = color.RGBA{0, 0, 0, 150}
+	playerStatsText       = color.RGBA{230, 230, 230, 255}
+
+	staminaReadyColor = color.RGBA{90, 200, 90, 255}
+	staminaLowColor   = color.RGBA{200, 160, 60, 255} // 低于再次冲刺所需的体力
+)
+
+// localPlayer 本地操控的玩家（观战或已离开时返回 nil）
+func (g *Game) localPlayer() *Player {
+	for _, p := range g.players {
+		if p.isLocal {
+			return p
+		}
+	}
+	return nil
+}
+
+// activeBombs 玩家在场炸弹数，联网模式以服务器下发为准
+func (g *Game) activeBombs(p *Player) int {
+	if p.hasAuthBombs {
+		return p.authActiveBombs
+	}
+	return g.coreGame.ActiveBombCount(p.corePlayer.ID)
+}
+
+// drawPlayerStats 在左下角绘制本地玩家状态条：剩余炸弹、范围、速度、生效中的效果
+func (g *Game) drawPlayerStats(screen *ebiten.Image) {
+	local := g.localPlayer()
+	if local == nil || local.corePlayer.Dead {
+		return
+	}
+	p := local.corePlayer
+
+	bombsLeft := p.MaxBombs - g.activeBombs(local)
+	if bombsLeft < 0 {
+		bombsLeft = 0
+	}
+	parts := []string{
+		fmt.Sprintf("BOMB %d/%d", bombsLeft, p.MaxBombs),
+		fmt.Sprintf("RANGE %d", p.BombRange),
+		fmt.Sprintf("SPEED x%.1f", p.Speed/core.PlayerSpeedPerFrame),
+	}
+	if p.CanKick {
+		parts = append(parts, "KICK")
+	}
+	for _, e := range p.Effects {
+		parts = append(parts, effectLabel(e, g.coreGame.CurrentFrame))
+	}
+	line := strings.Join(parts, "  ")
+
+	width := float32(textWidth(line) + playerStatsMargin*2)
+	y := float32(ScreenHeight - playerStatsHeight - playerStatsMargin)
+	vector.DrawFilledRect(screen, playerStatsMargin, y, width, playerStatsHeight, playerStatsBackground, false)
+	drawText(screen, playerStatsMargin*2, int(y)+3, line, playerStatsText)
+
+	drawStaminaBar(screen, playerStatsMargin, y-staminaBarHeight-2, p)
+}
+
+// drawStaminaBar 在状态条上方绘制冲刺体力条（体力不足以重新开始冲刺时变色）
+func drawStaminaBar(screen *ebiten.Image, x, y float32, p *core.Player) {
+	vector.DrawFilledRect(screen, x, y, staminaBarWidth, staminaBarHeight, playerStatsBackground, false)
+	clr := staminaReadyColor
+	if !p.CanSprint() {
+		clr = staminaLowColor
+	}
+	fill := staminaBarWidth * float32(p.Stamina) / core.StaminaMax
+	vector.DrawFilledRect(screen, x, y, fill, staminaBarHeight, clr, false)
+}
+
+// effectLabel 效果名称及剩余秒数（永久效果不显示时间）
+func effectLabel(e core.Effect, currentFrame int32) string {
+	name := "?"
+	switch e.Type {
+	case core.EffectSpeedBoost:
+		name = "FAST"
+	case core.EffectSlow:
+		name = "SLOW"
+	case core.EffectShield:
+		name = "SHIELD"
+	}
+	remain := e.RemainingFrames(currentFrame)
+	if remain < 0 {
+		return name
+	}
+	return fmt.Sprintf("%s %ds", name, (remain+core.TPS-1)/core.TPS)
+}
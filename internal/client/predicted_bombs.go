@@ -0,0 +1,84 @@
+package client
+
+import "bomberman/pkg/core"
+
+// 放弹预测：放弹只由服务器判定，炸弹要一个往返后才出现在权威状态里。
+// 本地判断能放时立刻在脚下显示一颗幽灵炸弹（不参与碰撞，碰撞仍以权威炸弹为准），
+// 收到权威炸弹列表后按放置者和放置帧确认（由权威炸弹接替显示）或撤销
+
+// bombPredictionWindowFrames 权威炸弹与幽灵炸弹放置帧允许的偏差（服务器的按键缓冲可能晚几帧放出）；
+// 权威状态超过预测帧这么多仍没有对应的炸弹时撤销
+const bombPredictionWindowFrames = core.BombInputBufferFrames
+
+// predictBomb 本帧发送了放弹输入且本地判断能放时，在本地玩家（已应用本帧移动）脚下放一颗幽灵炸弹
+func (ngc *NetworkGameClient) predictBomb(local *Player, frameID int32) {
+	if !canPlaceBombAt(local, frameID) {
+		return
+	}
+	p := local.corePlayer
+	if local.hasAuthBombs && local.authActiveBombs+len(ngc.predictedBombs) >= p.MaxBombs {
+		return
+	}
+	if len(ngc.predictedBombs) > 0 && frameID < ngc.lastPredictedBombFrame+core.BombPlacementDelayFrames {
+		return
+	}
+
+	game := ngc.game.coreGame
+	cell := core.PlayerXYToGrid(int(p.X), int(p.Y))
+	if game.Map.GetTile(cell.GridX, cell.GridY) != core.TileEmpty ||
+		hasBombAt(game.Bombs, cell) || hasBombAt(ngc.predictedBombs, cell) {
+		return
+	}
+
+	bomb := core.NewBomb(cell.GridX, cell.GridY, p.ID, frameID)
+	bomb.ExplodeAtFrame = frameID + game.Config.BombFuseFrames
+	bomb.ExplosionRange = p.BombRange
+	ngc.predictedBombs = append(ngc.predictedBombs, bomb)
+	ngc.lastPredictedBombFrame = frameID
+	ngc.syncPredictedBombRenderers()
+}
+
+// reconcilePredictedBombs 用权威炸弹列表核对幽灵炸弹：本地玩家在放置帧附近放出的权威炸弹确认一颗幽灵炸弹，
+// 权威状态已超过预测帧却没有对应炸弹的（服务器没放出来）撤销
+func (ngc *NetworkGameClient) reconcilePredictedBombs(frameID int32) {
+	if len(ngc.predictedBombs) == 0 {
+		return
+	}
+
+	confirmed := make(map[*core.Bomb]bool)
+	kept := ngc.predictedBombs[:0]
+	for _, ghost := range ngc.predictedBombs {
+		if auth := ngc.matchPredictedBomb(ghost, confirmed); auth != nil {
+			confirmed[auth] = true
+			continue
+		}
+		if frameID > ghost.PlacedAtFrame+bombPredictionWindowFrames {
+			continue
+		}
+		kept = append(kept, ghost)
+	}
+	ngc.predictedBombs = kept
+	ngc.syncPredictedBombRenderers()
+}
+
+// matchPredictedBomb 寻找与幽灵炸弹对应的权威炸弹（同一放置者、放置帧相差不超过窗口，每颗只能确认一次）
+func (ngc *NetworkGameClient) matchPredictedBomb(ghost *core.Bomb, confirmed map[*core.Bomb]bool) *core.Bomb {
+	for _, bomb := range ngc.game.coreGame.Bombs {
+		if bomb.OwnerID != ghost.OwnerID || confirmed[bomb] {
+			continue
+		}
+		diff := bomb.PlacedAtFrame - ghost.PlacedAtFrame
+		if diff >= -bombPredictionWindowFrames && diff <= bombPredictionWindowFrames {
+			return bomb
+		}
+	}
+	return nil
+}
+
+// syncPredictedBombRenderers 幽灵炸弹与权威炸弹画在同一层
+func (ngc *NetworkGameClient) syncPredictedBombRenderers() {
+	ngc.game.predictedBombs = ngc.game.predictedBombs[:0]
+	for _, bomb := range ngc.predictedBombs {
+		ngc.game.predictedBombs = append(ngc.game.predictedBombs, NewBombRenderer(bomb))
+	}
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"log"
+	"sync"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/protocol"
+)
+
+// account 账号偏好：加入时携带账号密钥，服务器保存角色、控制方案和房主的规则预设，
+// 换设备用同一个密钥即可恢复。命令行显式指定的角色、控制方案优先，并保存为新的偏好
+type account struct {
+	key           string
+	keepCharacter bool   // 命令行指定了角色：使用本地角色（服务器随加入请求保存）
+	control       string // 命令行指定的控制方案（wasd/arrow），连接后上传；为空时使用服务器保存的方案
+
+	mu      sync.Mutex
+	profile *gamev1.PlayerProfile // 服务器保存的偏好（加入或修改后更新）
+}
+
+// SetAccount 设置账号密钥（需在连接前调用）。keepCharacter 表示命令行显式指定了角色，
+// control 为命令行显式指定的控制方案（为空表示未指定）；指定了的以本地为准，否则使用服务器保存的偏好
+func (nc *NetworkClient) SetAccount(key string, keepCharacter bool, control string) {
+	nc.account.key = key
+	nc.account.keepCharacter = keepCharacter
+	nc.account.control = control
+}
+
+// SaveControlPreference 把命令行指定的控制方案保存到账号（连接后调用，没有账号或未指定时不发送）
+func (nc *NetworkClient) SaveControlPreference() error {
+	if nc.account.key == "" || nc.account.control == "" {
+		return nil
+	}
+	packet, err := protocol.NewProfileUpdateRequestPacket(nc.account.key, &gamev1.PlayerProfile{Control: nc.account.control})
+	if err != nil {
+		return err
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return err
+	}
+	return nc.sendMessage(data)
+}
+
+// StoredControl 账号保存的控制方案（命令行显式指定了控制方案或没有保存时返回 false）
+func (nc *NetworkClient) StoredControl() (ControlScheme, bool) {
+	if nc.account.control != "" {
+		return 0, false
+	}
+	nc.account.mu.Lock()
+	defer nc.account.mu.Unlock()
+	return controlSchemeByName(nc.account.profile.GetControl())
+}
+
+// applyProfile 加入成功后记录服务器保存的偏好，未指定角色时本地角色以服务器为准
+func (nc *NetworkClient) applyProfile(profile *gamev1.PlayerProfile) {
+	if profile == nil {
+		return
+	}
+	nc.account.mu.Lock()
+	nc.account.profile = profile
+	nc.account.mu.Unlock()
+	if !nc.account.keepCharacter && profile.Character != gamev1.CharacterType_CHARACTER_TYPE_UNSPECIFIED {
+		nc.character = protocol.ProtoCharacterTypeToCore(profile.Character)
+	}
+}
+
+// handleProfileUpdateResponse 记录修改后的偏好（失败只记日志，不影响游戏）
+func (nc *NetworkClient) handleProfileUpdateResponse(resp *gamev1.ProfileUpdateResponse) {
+	if !resp.Success {
+		log.Printf("保存账号偏好失败: %s", friendlyErrorMessage(resp.ErrorCode, resp.ErrorParams, resp.ErrorMessage))
+		return
+	}
+	nc.account.mu.Lock()
+	nc.account.profile = resp.Profile
+	nc.account.mu.Unlock()
+	log.Printf("账号偏好已保存")
+}
+
+// controlSchemeByName 命令行 -control 的取值对应的控制方案
+func controlSchemeByName(name string) (ControlScheme, bool) {
+	switch name {
+	case "wasd":
+		return ControlWASD, true
+	case "arrow":
+		return ControlArrow, true
+	}
+	return 0, false
+}
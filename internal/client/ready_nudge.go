@@ -0,0 +1,83 @@
+package client
+
+import (
+	"image/color"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+var (
+	readyNudgeBackground = color.RGBA{120, 70, 20, 230}
+	readyNudgeBorder     = color.RGBA{230, 180, 80, 255}
+)
+
+// handleReadyNudge records a nudge from the server; the nudged player gets
+// a banner and the host is told they can start without them
+func (lc *LobbyClient) handleReadyNudge(nudge *gamev1.ReadyNudgeEvent) {
+	lc.readyNudge = nudge
+	if lc.roomState != nil && lc.roomState.HostId == lc.network.GetPlayerID() {
+		lc.showToast(nudge.PlayerName+" is not ready, F: start without them", uiWarning)
+	}
+}
+
+// clearStaleReadyNudge drops the nudge once that player is ready or gone
+func (lc *LobbyClient) clearStaleReadyNudge() {
+	if lc.readyNudge == nil {
+		return
+	}
+	if lc.roomState != nil {
+		for _, player := range lc.roomState.Players {
+			if player.Id == lc.readyNudge.PlayerId && !player.IsReady {
+				return
+			}
+		}
+	}
+	lc.readyNudge = nil
+}
+
+// startWithoutUnready asks the server to start without the nudged player
+func (lc *LobbyClient) startWithoutUnready() {
+	if lc.roomState == nil || lc.readyNudge == nil {
+		return
+	}
+	if lc.roomState.HostId != lc.network.GetPlayerID() {
+		return
+	}
+	action := &gamev1.RoomAction{
+		Type: gamev1.RoomActionType_ROOM_ACTION_START_WITHOUT_UNREADY,
+	}
+	lc.sendRoomAction(action)
+}
+
+// drawReadyNudge draws a flashing banner for the nudged player and a
+// reminder of the F key for the host
+func (lc *LobbyClient) drawReadyNudge(screen *ebiten.Image) {
+	if lc.readyNudge == nil || lc.roomState == nil {
+		return
+	}
+
+	var msg string
+	switch lc.network.GetPlayerID() {
+	case lc.readyNudge.PlayerId:
+		if time.Now().UnixMilli()/500%2 == 1 {
+			return
+		}
+		msg = "Everyone is waiting for you! Press Space to ready"
+	case lc.roomState.HostId:
+		msg = lc.readyNudge.PlayerName + " is not ready. F: start without them"
+	default:
+		return
+	}
+
+	width := float32(ScreenWidth - 2*uiPanelMargin)
+	height := float32(32)
+	x := float32(uiPanelMargin)
+	y := float32(ScreenHeight-100) - height
+	vector.DrawFilledRect(screen, x, y, width, height, readyNudgeBackground, false)
+	vector.StrokeRect(screen, x, y, width, height, 2, readyNudgeBorder, false)
+	drawText(screen, int(x)+uiPanelPadding, int(y)+10, msg, uiTextPrimary)
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 断线重连界面的按键
+const (
+	reconnectRetryKey  = ebiten.KeyR      // 跳过退避等待，立即重连
+	reconnectGiveUpKey = ebiten.KeyEscape // 放弃重连，回到大厅
+)
+
+var (
+	reconnectDimColor   = color.RGBA{0, 0, 0, 150}
+	reconnectPanelColor = color.RGBA{30, 35, 45, 230}
+)
+
+// updateReconnect 断线或重连中时代替正常的帧更新：处理按键，退避时间到（或按 R）时发起下一次重连
+// 放弃请求在进行中的重连结束后生效，之后由 LobbyClient 通过 Abandoned 回到大厅
+func (ngc *NetworkGameClient) updateReconnect(now time.Time) {
+	if ngc.reconnectKeys.JustPressed(reconnectGiveUpKey) {
+		ngc.giveUpRequested = true
+	}
+	retry := ngc.reconnectKeys.JustPressed(reconnectRetryKey)
+	if ngc.reconnecting {
+		return
+	}
+	if ngc.giveUpRequested {
+		ngc.abandoned = true
+		return
+	}
+	if !ngc.network.CanReconnect() {
+		return
+	}
+	if retry || ngc.lastReconnectAttempt.IsZero() || now.Sub(ngc.lastReconnectAttempt) >= ngc.reconnectDelay {
+		ngc.reconnectAttempts++
+		ngc.reconnecting = true
+		ngc.lastReconnectAttempt = now
+		go ngc.tryReconnect()
+	}
+}
+
+// Abandoned 玩家在断线后选择了放弃，对局客户端不再可用
+func (ngc *NetworkGameClient) Abandoned() bool {
+	return ngc.abandoned
+}
+
+// drawReconnectOverlay 断线时遮住冻结的画面，显示重连次数、下一次重连的倒计时和可用按键
+func (ngc *NetworkGameClient) drawReconnectOverlay(screen *ebiten.Image) {
+	if ngc.network.IsConnected() && !ngc.reconnecting {
+		return
+	}
+
+	vector.DrawFilledRect(screen, 0, 0, ScreenWidth, ScreenHeight, reconnectDimColor, false)
+	const panelWidth, panelHeight = 340, 110
+	panelX := float32(ScreenWidth-panelWidth) / 2
+	panelY := float32(ScreenHeight-panelHeight) / 2
+	vector.DrawFilledRect(screen, panelX, panelY, panelWidth, panelHeight, reconnectPanelColor, false)
+	vector.StrokeRect(screen, panelX, panelY, panelWidth, panelHeight, 2, uiWarning, false)
+
+	status, keys := ngc.reconnectStatus(time.Now())
+	y := int(panelY) + 16
+	drawCenteredText(screen, "Connection lost", ScreenWidth/2, y, uiWarning)
+	drawCenteredText(screen, status, ScreenWidth/2, y+28, uiTextPrimary)
+	drawCenteredText(screen, keys, ScreenWidth/2, y+56, uiTextSecondary)
+}
+
+// reconnectStatus 重连界面的状态行和按键提示
+func (ngc *NetworkGameClient) reconnectStatus(now time.Time) (status, keys string) {
+	switch {
+	case !ngc.network.CanReconnect():
+		return "Session expired, cannot rejoin", "[Esc] Back to lobby"
+	case ngc.giveUpRequested:
+		return fmt.Sprintf("Giving up after attempt %d...", ngc.reconnectAttempts), ""
+	case ngc.reconnecting:
+		return fmt.Sprintf("Reconnecting... (attempt %d)", ngc.reconnectAttempts), "[Esc] Give up"
+	}
+	wait := ngc.reconnectDelay - now.Sub(ngc.lastReconnectAttempt)
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds < 0 {
+		seconds = 0
+	}
+	if ngc.reconnectAttempts == 0 {
+		return fmt.Sprintf("Reconnecting in %ds", seconds), "[R] Retry now  [Esc] Give up"
+	}
+	return fmt.Sprintf("Attempt %d failed, retrying in %ds", ngc.reconnectAttempts, seconds), "[R] Retry now  [Esc] Give up"
+}
+
+// abandonGame 玩家放弃了断线重连：回到大厅。连接仍在（重连成功后才放弃）时正常离开房间，
+// 由房间界面收到离开响应后切回大厅；否则旧会话已无法恢复，重新建立一个全新的连接
+func (lc *LobbyClient) abandonGame() {
+	lc.hudHidden = lc.game.game.HUDHidden()
+	lc.game = nil
+	lc.closeChat()
+	if lc.network.IsConnected() {
+		lc.screen = screenRoom
+		lc.leaveRoom()
+		return
+	}
+	lc.roomState = nil
+	lc.screen = screenLobby
+	lc.startRedial()
+}
+
+// startRedial 在后台重新连接服务器，结果由 updateRedial 读取
+func (lc *LobbyClient) startRedial() {
+	lc.redialing = true
+	lc.lastError = "Reconnecting to lobby..."
+	go func() {
+		lc.redialResult <- lc.network.Redial()
+	}()
+}
+
+// updateRedial 大厅在连接断开或重新连接期间不收发消息，返回 true 表示跳过本帧的大厅更新；
+// 连接失败后按 R 再次尝试
+func (lc *LobbyClient) updateRedial() bool {
+	if lc.redialing {
+		select {
+		case err := <-lc.redialResult:
+			lc.redialing = false
+			if err != nil {
+				lc.lastError = "Lobby connection failed, press R to retry"
+				return true
+			}
+			lc.lastError = ""
+			lc.lastListFetch = time.Time{}
+			return false
+		default:
+			return true
+		}
+	}
+	if lc.network.IsConnected() {
+		return false
+	}
+	if lc.input.JustPressed(ebiten.KeyR) {
+		lc.startRedial()
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 请求与响应的对应：加入、重连、房间列表和房间操作请求携带客户端生成的请求 ID，服务器在响应中原样带回，
+// 接收协程按请求 ID 把响应交给发出该请求的 Future，调用方不再依赖响应的先后顺序推断是哪个请求的结果。
+// 只有会读取结果的调用方才分配请求 ID；没有对应 Future 的房间操作响应（服务器主动发送的被踢出、房间关闭等，
+// 以及超时后才到达的响应）仍通过 ReceiveRoomActionResponse 获取
+
+// requestTimeout 超过该时间仍未收到响应的请求视为丢失
+const requestTimeout = 10 * time.Second
+
+// Future 一个已发送请求的响应（只会收到一次）
+type Future[T proto.Message] struct {
+	id   int32
+	sent time.Time
+	done chan proto.Message
+}
+
+// ID 请求 ID
+func (f *Future[T]) ID() int32 {
+	return f.id
+}
+
+// Poll 非阻塞获取响应，还没有收到时 ok 为 false
+func (f *Future[T]) Poll() (resp T, ok bool) {
+	select {
+	case m := <-f.done:
+		resp, _ = m.(T)
+		return resp, true
+	default:
+		return resp, false
+	}
+}
+
+// Stale 超过 requestTimeout 仍未收到响应（连接断开、重连前发出或服务器丢弃了请求）
+func (f *Future[T]) Stale() bool {
+	return time.Since(f.sent) > requestTimeout
+}
+
+// requestTracker 等待响应的请求（接收协程与游戏循环共用，内部加锁）。
+// 请求 ID 在客户端生命周期内单调递增、重连时不重置，旧连接上迟到的响应不会对应到新请求。
+// 超过 requestTimeout 的请求在分配新 ID 或收到响应时清理，永远收不到响应的请求不会一直占用
+type requestTracker struct {
+	mu      sync.Mutex
+	lastID  int32
+	pending map[int32]pendingRequest
+}
+
+type pendingRequest struct {
+	done chan proto.Message
+	sent time.Time
+}
+
+// expired 与 Future.Stale 使用同一个发送时间：调用方放弃的请求不会再收到响应
+func (p pendingRequest) expired(now time.Time) bool {
+	return now.Sub(p.sent) > requestTimeout
+}
+
+// next 分配请求 ID（从 1 开始，0 表示不关联请求），同时清理超时的请求
+func (t *requestTracker) next() (int32, pendingRequest) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastID++
+	if t.lastID <= 0 {
+		t.lastID = 1
+	}
+	now := time.Now()
+	if t.pending == nil {
+		t.pending = make(map[int32]pendingRequest)
+	}
+	for id, p := range t.pending {
+		if p.expired(now) {
+			delete(t.pending, id)
+		}
+	}
+	p := pendingRequest{done: make(chan proto.Message, 1), sent: now}
+	t.pending[t.lastID] = p
+	return t.lastID, p
+}
+
+// resolve 把响应交给对应的请求；请求 ID 为 0、未知（已放弃、重连前的请求）或已超时时返回 false，
+// 由调用方交给原有的通道
+func (t *requestTracker) resolve(id int32, resp proto.Message) bool {
+	if id == 0 {
+		return false
+	}
+	t.mu.Lock()
+	p, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mu.Unlock()
+	if !ok || p.expired(time.Now()) {
+		return false
+	}
+	p.done <- resp
+	return true
+}
+
+// forget 放弃等待一个请求（发送失败或超时）
+func (t *requestTracker) forget(id int32) {
+	t.mu.Lock()
+	delete(t.pending, id)
+	t.mu.Unlock()
+}
+
+// reset 放弃全部等待中的请求（重连时调用，ID 继续递增）
+func (t *requestTracker) reset() {
+	t.mu.Lock()
+	t.pending = nil
+	t.mu.Unlock()
+}
+
+// newFuture 为即将发送的请求分配请求 ID（只在调用方会读取结果时使用）
+func newFuture[T proto.Message](nc *NetworkClient) *Future[T] {
+	id, p := nc.requests.next()
+	return &Future[T]{id: id, sent: p.sent, done: p.done}
+}
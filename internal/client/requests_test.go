@@ -0,0 +1,77 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+func TestRequestTrackerResolve(t *testing.T) {
+	var tracker requestTracker
+	id, p := tracker.next()
+	if id != 1 {
+		t.Fatalf("第一个请求 ID = %d，期望 1", id)
+	}
+
+	resp := &gamev1.RoomActionResponse{RequestId: id, Success: true}
+	if !tracker.resolve(id, resp) {
+		t.Fatal("等待中的请求应被认领")
+	}
+	if got := <-p.done; got != resp {
+		t.Errorf("收到 %v，期望 %v", got, resp)
+	}
+	if tracker.resolve(id, resp) {
+		t.Error("同一个请求只能认领一次")
+	}
+	if tracker.resolve(0, resp) {
+		t.Error("请求 ID 0 不对应任何请求")
+	}
+}
+
+// TestRequestTrackerExpires 超时的请求不再认领响应（交给原有通道），并在分配新 ID 时清理
+func TestRequestTrackerExpires(t *testing.T) {
+	var tracker requestTracker
+	expiredID, _ := tracker.next()
+	tracker.next()
+	tracker.mu.Lock()
+	p := tracker.pending[expiredID]
+	p.sent = time.Now().Add(-requestTimeout - time.Second)
+	tracker.pending[expiredID] = p
+	tracker.mu.Unlock()
+
+	if tracker.resolve(expiredID, &gamev1.RoomActionResponse{RequestId: expiredID}) {
+		t.Error("超时的请求不应认领响应")
+	}
+
+	staleID, _ := tracker.next()
+	tracker.mu.Lock()
+	p = tracker.pending[staleID]
+	p.sent = time.Now().Add(-requestTimeout - time.Second)
+	tracker.pending[staleID] = p
+	tracker.mu.Unlock()
+
+	tracker.next()
+	tracker.mu.Lock()
+	_, stale := tracker.pending[staleID]
+	pending := len(tracker.pending)
+	tracker.mu.Unlock()
+	if stale {
+		t.Error("分配新 ID 时应清理超时的请求")
+	}
+	if pending != 2 {
+		t.Errorf("等待中的请求 %d 个，期望 2", pending)
+	}
+}
+
+// TestFutureMatchesTrackerDeadline 调用方放弃（Stale）的请求在追踪器中同时过期
+func TestFutureMatchesTrackerDeadline(t *testing.T) {
+	nc := &NetworkClient{}
+	f := newFuture[*gamev1.RoomActionResponse](nc)
+	nc.requests.mu.Lock()
+	p := nc.requests.pending[f.ID()]
+	nc.requests.mu.Unlock()
+	if !p.sent.Equal(f.sent) {
+		t.Errorf("Future 发送时间 %v 与追踪器 %v 不一致", f.sent, p.sent)
+	}
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// sendRoomAction 发送房间操作，响应由 pollRoomActions 按发送顺序处理
+func (lc *LobbyClient) sendRoomAction(action *gamev1.RoomAction) {
+	f, err := lc.network.RequestRoomAction(action)
+	if err != nil {
+		return
+	}
+	lc.pendingActions = append(lc.pendingActions, f)
+}
+
+// leaveRoom 离开房间，收到离开响应后切回大厅
+func (lc *LobbyClient) leaveRoom() {
+	f, err := lc.network.LeaveRoom()
+	if err != nil {
+		return
+	}
+	lc.pendingActions = append(lc.pendingActions, f)
+}
+
+// pollRoomActions 处理已收到的房间操作响应（房间和对局画面每帧调用）：先处理自己发出的操作（按发送顺序，
+// 丢弃超时未响应的，之后才到达的响应走下面的通道），再处理没有对应 Future 的（被踢出、房间空闲关闭等）
+func (lc *LobbyClient) pollRoomActions() {
+	pending := lc.pendingActions[:0]
+	for _, f := range lc.pendingActions {
+		if resp, ok := f.Poll(); ok {
+			if resp != nil {
+				lc.handleRoomActionResponse(resp)
+			}
+			continue
+		}
+		if !f.Stale() {
+			pending = append(pending, f)
+		}
+	}
+	clear(lc.pendingActions[len(pending):])
+	lc.pendingActions = pending
+
+	for {
+		resp := lc.network.ReceiveRoomActionResponse()
+		if resp == nil {
+			break
+		}
+		lc.handleRoomActionResponse(resp)
+	}
+}
+
+func (lc *LobbyClient) handleRoomActionResponse(resp *gamev1.RoomActionResponse) {
+	// 服务器定期续期会话令牌（令牌已由 NetworkClient 保存），不是房间操作的结果
+	if resp.Success && resp.RequestId == 0 && resp.RoomId != "" {
+		return
+	}
+	if !resp.Success {
+		lc.lastError = friendlyErrorMessage(resp.ErrorCode, resp.ErrorParams, resp.ErrorMessage)
+		lc.showToast(lc.lastError, uiError)
+		switch resp.ErrorCode {
+		case gamev1.ErrorCode_ERROR_CODE_KICKED, gamev1.ErrorCode_ERROR_CODE_ROOM_IDLE, gamev1.ErrorCode_ERROR_CODE_NOT_READY_REMOVED:
+			lc.roomState = nil
+			lc.screen = screenLobby
+			lc.lastListFetch = time.Time{}
+		}
+		return
+	}
+	lc.lastError = ""
+	if resp.RoomId == "" {
+		lc.roomState = nil
+		lc.screen = screenLobby
+		lc.lastListFetch = time.Time{}
+	}
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"strings"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	chatLogLines     = 8   // lines kept and shown in the room screen
+	chatLineChars    = 32  // characters that fit in the players panel
+	chatInputMaxChar = 120 // matches the server limit
+
+	reportCommand = "/report " // chat prefix that reports a player instead of sending a message
+)
+
+// setRoomHistory replaces the chat log with the history sent on join or reconnect
+func (lc *LobbyClient) setRoomHistory(history []*gamev1.RoomHistoryEntry) {
+	lc.chatLog = nil
+	for _, entry := range history {
+		lc.appendRoomHistory(entry)
+	}
+}
+
+// appendRoomHistory adds one chat message or room event to the log
+func (lc *LobbyClient) appendRoomHistory(entry *gamev1.RoomHistoryEntry) {
+	line := roomHistoryLine(entry)
+	if line == "" {
+		return
+	}
+	lc.chatLog = append(lc.chatLog, line)
+	if len(lc.chatLog) > chatLogLines {
+		lc.chatLog = lc.chatLog[len(lc.chatLog)-chatLogLines:]
+	}
+}
+
+// roomHistoryLine formats a history entry for display
+func roomHistoryLine(entry *gamev1.RoomHistoryEntry) string {
+	name := entry.PlayerName
+	if name == "" {
+		name = playerLabel(entry.PlayerId)
+	}
+	switch entry.Kind {
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_CHAT:
+		return name + ": " + entry.Text
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_JOINED:
+		return "* " + name + " joined"
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_LEFT:
+		return "* " + name + " left"
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_READY:
+		return "* " + name + " is ready"
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_UNREADY:
+		return "* " + name + " is not ready"
+	default:
+		return ""
+	}
+}
+
+// handleChatInput edits the chat line while typing; Enter sends, Esc cancels
+func (lc *LobbyClient) handleChatInput() {
+	if lc.input.JustPressed(ebiten.KeyBackspace) && len(lc.chatBuffer) > 0 {
+		lc.chatBuffer = lc.chatBuffer[:len(lc.chatBuffer)-1]
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(lc.chatBuffer) < chatInputMaxChar && r >= ' ' && r <= '~' {
+			lc.chatBuffer += string(r)
+		}
+	}
+	if lc.input.JustPressed(ebiten.KeyEnter) {
+		if args, ok := strings.CutPrefix(lc.chatBuffer, reportCommand); ok {
+			lc.reportPlayer(args)
+		} else if lc.chatBuffer != "" {
+			lc.sendRoomAction(&gamev1.RoomAction{
+				Type:     gamev1.RoomActionType_ROOM_ACTION_CHAT,
+				ChatText: lc.chatBuffer,
+			})
+		}
+		lc.closeChat()
+	}
+	if lc.input.JustPressed(ebiten.KeyEscape) {
+		lc.closeChat()
+	}
+}
+
+// reportPlayer handles "/report <name> [reason]": flags a player in the room
+// for the server admins (the server attaches its own observations)
+func (lc *LobbyClient) reportPlayer(args string) {
+	name, reason, _ := strings.Cut(strings.TrimSpace(args), " ")
+	if lc.roomState == nil || name == "" {
+		lc.showToast("Usage: "+reportCommand+"<name> [reason]", uiWarning)
+		return
+	}
+	for _, player := range lc.roomState.Players {
+		if player.IsAi || !strings.EqualFold(player.Name, name) {
+			continue
+		}
+		lc.sendRoomAction(&gamev1.RoomAction{
+			Type:         gamev1.RoomActionType_ROOM_ACTION_REPORT,
+			TargetPlayer: player.Id,
+			ReportReason: strings.TrimSpace(reason),
+		})
+		lc.showToast("Report sent for "+player.Name, uiSuccess)
+		return
+	}
+	lc.showToast("No player named "+name+" in this room", uiWarning)
+}
+
+func (lc *LobbyClient) closeChat() {
+	lc.chatMode = false
+	lc.chatBuffer = ""
+}
+
+// drawChat draws the chat log and input line at the bottom of the players panel
+func (lc *LobbyClient) drawChat(screen *ebiten.Image, x, bottomY int) {
+	y := bottomY - (chatLogLines+1)*uiRowHeight
+	drawText(screen, x, y, "CHAT (/ to talk)", uiTextMuted)
+	for i, line := range lc.chatLog {
+		drawText(screen, x, y+(i+1)*uiRowHeight, clipChatLine(line), uiTextSecondary)
+	}
+	if lc.chatMode {
+		input := "> " + lc.chatBuffer
+		if len(input) > chatLineChars {
+			input = input[len(input)-chatLineChars:]
+		}
+		drawText(screen, x, bottomY, input, uiTextPrimary)
+	}
+}
+
+// clipChatLine shortens a line to fit the panel
+func clipChatLine(line string) string {
+	runes := []rune(line)
+	if len(runes) <= chatLineChars {
+		return line
+	}
+	return string(runes[:chatLineChars-3]) + "..."
+}
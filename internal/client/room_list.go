@@ -0,0 +1,131 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// lobbyPageSize 大厅每页显示的房间数（房间列表面板能容纳的行数）
+const lobbyPageSize = 15
+
+// roomListView 大厅房间列表的页码与过滤条件，分页和过滤都由服务器完成
+type roomListView struct {
+	page  int32 // 当前页（从 1 开始）
+	total int32 // 满足过滤条件的房间总数
+
+	waitingOnly bool
+	notFull     bool
+	hasAI       bool
+}
+
+// pages 总页数（没有房间时为 1）
+func (v *roomListView) pages() int32 {
+	return max((v.total+lobbyPageSize-1)/lobbyPageSize, 1)
+}
+
+func (v *roomListView) request() *gamev1.RoomListRequest {
+	return &gamev1.RoomListRequest{
+		Page:        max(v.page, 1),
+		PageSize:    lobbyPageSize,
+		WaitingOnly: v.waitingOnly,
+		NotFull:     v.notFull,
+		HasAi:       v.hasAI,
+	}
+}
+
+// requestRoomList 按当前页码和过滤条件请求房间列表（翻页或切换过滤条件后，之前请求的响应不再显示）
+func (lc *LobbyClient) requestRoomList() {
+	if f, err := lc.network.RequestRoomList(lc.listView.request()); err == nil {
+		lc.roomListReq = f
+	}
+	lc.lastListFetch = time.Now()
+}
+
+// pollRoomList 显示最近一次请求的房间列表（收到后或超时未响应时放弃该请求，按周期重新请求）
+func (lc *LobbyClient) pollRoomList() {
+	f := lc.roomListReq
+	if f == nil {
+		return
+	}
+	if resp, ok := f.Poll(); ok {
+		lc.roomListReq = nil
+		if resp != nil {
+			lc.applyRoomList(resp)
+		}
+		return
+	}
+	if f.Stale() {
+		lc.roomListReq = nil
+	}
+}
+
+// applyRoomList 显示服务器返回的一页房间（页码以服务器为准：过滤后页数变少时服务器返回最后一页）
+func (lc *LobbyClient) applyRoomList(resp *gamev1.RoomListResponse) {
+	lc.roomList = resp.Rooms
+	lc.listView.total = resp.Total
+	if resp.Page > 0 {
+		lc.listView.page = resp.Page
+	}
+	if lc.selectedIndex >= len(lc.roomList) {
+		lc.selectedIndex = 0
+	}
+}
+
+// updateRoomListKeys A/D 翻页，1/2/3 切换过滤条件，变化后立即重新请求
+func (lc *LobbyClient) updateRoomListKeys() {
+	v := &lc.listView
+	changed := false
+	if (lc.input.JustPressed(ebiten.KeyArrowLeft) || lc.input.JustPressed(ebiten.KeyA)) && v.page > 1 {
+		v.page--
+		changed = true
+	}
+	if (lc.input.JustPressed(ebiten.KeyArrowRight) || lc.input.JustPressed(ebiten.KeyD)) && v.page < v.pages() {
+		v.page++
+		changed = true
+	}
+	filters := []struct {
+		key  ebiten.Key
+		flag *bool
+	}{
+		{ebiten.Key1, &v.waitingOnly},
+		{ebiten.Key2, &v.notFull},
+		{ebiten.Key3, &v.hasAI},
+	}
+	for _, f := range filters {
+		if lc.input.JustPressed(f.key) {
+			*f.flag = !*f.flag
+			v.page = 1
+			changed = true
+		}
+	}
+	if changed {
+		lc.selectedIndex = 0
+		lc.requestRoomList()
+	}
+}
+
+// pageText 页码与房间数，如 "Page 2/3 (41 rooms)"
+func (v *roomListView) pageText() string {
+	return fmt.Sprintf("Page %d/%d (%d rooms)", max(v.page, 1), v.pages(), v.total)
+}
+
+// filterText 过滤开关的提示，如 "A/D:Page  1:Waiting[x]  2:Open seats[ ]  3:With AI[ ]"
+func (v *roomListView) filterText() string {
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	return fmt.Sprintf("A/D:Page  1:Waiting%s  2:Open seats%s  3:With AI%s",
+		check(v.waitingOnly), check(v.notFull), check(v.hasAI))
+}
+
+// rowNumber 选中页中第 i 行房间的序号（跨页连续编号）
+func (v *roomListView) rowNumber(i int) int {
+	return int(max(v.page, 1)-1)*lobbyPageSize + i + 1
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"bytes"
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// trackMapDefinition 记录房主选择的地图，地图不变时保留原对象（缩略图据此复用）
+func (nc *NetworkClient) trackMapDefinition(state *gamev1.RoomStateUpdate) {
+	if state == nil {
+		return
+	}
+	nc.trackMap(state.MapId, state.CustomMap, state.CustomMapJson)
+}
+
+// trackMap 社区地图使用服务器下发的定义；内置地图只有 ID，使用本地的同一份定义
+func (nc *NetworkClient) trackMap(mapID, customMap string, data []byte) {
+	layout := core.MapLayout(mapID)
+	if len(data) == 0 {
+		if len(nc.mapJSON) == 0 && nc.mapLayout == layout {
+			return
+		}
+		nc.mapJSON = nil
+		nc.mapLayout = layout
+		nc.mapDef = nil
+		if layout != core.MapLayoutDefault {
+			nc.mapDef = core.BuiltinMap(layout)
+			if nc.mapDef == nil {
+				log.Printf("未知的内置地图 %s，按默认地图显示（客户端版本可能过旧）", layout)
+			}
+		}
+		return
+	}
+	if bytes.Equal(data, nc.mapJSON) {
+		return
+	}
+	nc.mapJSON = data
+	nc.mapLayout = layout
+	nc.mapDef = nil
+	def, err := core.ParseMapDefinition(data)
+	if err != nil {
+		log.Printf("解析社区地图 %s 失败: %v", customMap, err)
+		return
+	}
+	nc.mapDef = def
+}
+
+// GetMapDefinition 当前房间的地图定义（nil 表示默认地图）
+func (nc *NetworkClient) GetMapDefinition() *core.MapDefinition {
+	return nc.mapDef
+}
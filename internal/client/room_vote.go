@@ -0,0 +1,185 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 对局之间的地图/规则提议：非房主按地图和规则键时发出提议而不是直接修改，
+// Tab 选择提议，U/J 投赞成/反对票；房主用 U/J 采纳或驳回
+
+// isHost 本地玩家是否为房主
+func (lc *LobbyClient) isHost() bool {
+	return lc.roomState != nil && lc.roomState.HostId == lc.network.GetPlayerID()
+}
+
+// proposeMap 提议更换地图
+func (lc *LobbyClient) proposeMap(name string) {
+	lc.sendProposal(&gamev1.RoomProposal{MapName: &name})
+}
+
+// proposeRules 提议修改规则（提议的是完整规则，房主采纳时整体替换）
+func (lc *LobbyClient) proposeRules(rules core.GameRules) {
+	lc.sendProposal(&gamev1.RoomProposal{Rules: protocol.CoreRulesToProto(rules)})
+}
+
+func (lc *LobbyClient) sendProposal(proposal *gamev1.RoomProposal) {
+	action := &gamev1.RoomAction{
+		Type:     gamev1.RoomActionType_ROOM_ACTION_PROPOSE,
+		Proposal: proposal,
+	}
+	lc.sendRoomAction(action)
+	lc.showToast("Proposal sent, waiting for votes", uiTextSecondary)
+}
+
+// currentProposal 选中的提议（列表变短时回到第一条）
+func (lc *LobbyClient) currentProposal() *gamev1.RoomProposal {
+	if lc.roomState == nil || len(lc.roomState.Proposals) == 0 {
+		return nil
+	}
+	if lc.selectedProposal >= len(lc.roomState.Proposals) {
+		lc.selectedProposal = 0
+	}
+	return lc.roomState.Proposals[lc.selectedProposal]
+}
+
+// updateProposalKeys Tab 选择下一条提议，U/J 对选中的提议投票（房主为采纳/驳回）
+func (lc *LobbyClient) updateProposalKeys() {
+	if lc.roomState == nil || len(lc.roomState.Proposals) == 0 {
+		return
+	}
+	if lc.input.JustPressed(ebiten.KeyTab) {
+		lc.selectedProposal = (lc.selectedProposal + 1) % len(lc.roomState.Proposals)
+	}
+	if lc.input.JustPressed(ebiten.KeyU) {
+		lc.answerProposal(true)
+	}
+	if lc.input.JustPressed(ebiten.KeyJ) {
+		lc.answerProposal(false)
+	}
+}
+
+func (lc *LobbyClient) answerProposal(approve bool) {
+	proposal := lc.currentProposal()
+	if proposal == nil {
+		return
+	}
+	actionType := gamev1.RoomActionType_ROOM_ACTION_VOTE
+	if lc.isHost() {
+		actionType = gamev1.RoomActionType_ROOM_ACTION_RESOLVE_PROPOSAL
+	}
+	action := &gamev1.RoomAction{
+		Type:         actionType,
+		TargetPlayer: proposal.Id,
+		Approve:      approve,
+	}
+	lc.sendRoomAction(action)
+}
+
+// proposalText describes a proposal relative to the current room settings,
+// e.g. "Ann: map Arena, Teams ON" with the vote tally
+func (lc *LobbyClient) proposalText(p *gamev1.RoomProposal) string {
+	var changes []string
+	if p.MapName != nil {
+		name := p.GetMapName()
+		if name == core.MapLayoutDefault || core.IsBuiltinMap(name) {
+			name = mapLabel(name)
+		}
+		changes = append(changes, "map "+name)
+	}
+	if p.Rules != nil {
+		changes = append(changes, rulesDiff(protocol.ProtoRulesToCore(lc.roomState.Rules), protocol.ProtoRulesToCore(p.Rules))...)
+	}
+	if len(changes) == 0 {
+		changes = append(changes, "no change")
+	}
+	return fmt.Sprintf("%s: %s  +%d -%d", lc.roomPlayerName(p.ProposerId), strings.Join(changes, ", "), len(p.YesIds), len(p.NoIds))
+}
+
+// rulesDiff lists the rule flags that differ, e.g. ["Teams ON", "Hazards OFF"]
+func rulesDiff(cur, next core.GameRules) []string {
+	flags := []struct {
+		label     string
+		cur, next bool
+	}{
+		{"Door ping", cur.DoorCampPing, next.DoorCampPing},
+		{"Collision", cur.PlayerCollision, next.PlayerCollision},
+		{"Hazards", cur.MapHazards, next.MapHazards},
+		{"Sudden death", cur.SuddenDeath, next.SuddenDeath},
+		{"Fair seed", cur.FairSeed, next.FairSeed},
+		{"Teams", cur.Teams, next.Teams},
+		{"Friendly fire", cur.FriendlyFire, next.FriendlyFire},
+		{"Overtime", cur.DoorOvertime, next.DoorOvertime},
+		{"Boss", cur.BossMode, next.BossMode},
+	}
+	var diff []string
+	for _, f := range flags {
+		if f.cur != f.next {
+			diff = append(diff, f.label+" "+onOff(f.next))
+		}
+	}
+	return diff
+}
+
+// roomPlayerName 房间成员的名字（已离开时显示 ID）
+func (lc *LobbyClient) roomPlayerName(id int32) string {
+	for _, player := range lc.roomState.Players {
+		if player.Id == id {
+			return player.Name
+		}
+	}
+	return fmt.Sprintf("P%d", id)
+}
+
+// myVote 本地玩家对提议的投票：1 赞成，-1 反对，0 未投票
+func (lc *LobbyClient) myVote(p *gamev1.RoomProposal) int {
+	me := lc.network.GetPlayerID()
+	for _, id := range p.YesIds {
+		if id == me {
+			return 1
+		}
+	}
+	for _, id := range p.NoIds {
+		if id == me {
+			return -1
+		}
+	}
+	return 0
+}
+
+// drawProposals draws the pending proposals starting at y and returns the
+// y below the last row
+func (lc *LobbyClient) drawProposals(screen *ebiten.Image, x, y int) int {
+	proposals := lc.roomState.GetProposals()
+	if len(proposals) == 0 {
+		return y
+	}
+	hint := "Tab:Select U:Yes J:No"
+	if lc.isHost() {
+		hint = "Tab:Select U:Apply J:Reject"
+	}
+	drawText(screen, x, y, "PROPOSALS  "+hint, uiTextMuted)
+	lc.currentProposal() // 列表变短时修正选中项
+	for i, p := range proposals {
+		y += uiRowHeight
+		marker := "  "
+		if i == lc.selectedProposal {
+			marker = "> "
+		}
+		clr := uiTextSecondary
+		switch lc.myVote(p) {
+		case 1:
+			clr = uiSuccess
+		case -1:
+			clr = uiError
+		}
+		drawText(screen, x, y, marker+lc.proposalText(p), clr)
+	}
+	return y + uiRowHeight
+}
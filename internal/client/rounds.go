@@ -0,0 +1,107 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 多回合对局：左上角显示回合比分；一回合结束后显示回合间歇画面，服务器开始下一回合时由 LobbyClient 换新的对局客户端
+
+var (
+	roundScoreColor   = color.RGBA{230, 230, 230, 255}
+	intermissionPanel = color.RGBA{30, 35, 45, 230}
+	intermissionTitle = color.RGBA{255, 220, 120, 255}
+)
+
+// roundIntermission 回合结束到下一回合开始之间显示的内容
+type roundIntermission struct {
+	event  *gamev1.RoundEndEvent
+	nextAt time.Time
+}
+
+// onRoundEnd 记录回合结果；系列赛已决出胜者时只更新比分，结果由随后的 GameOver 显示
+func (ngc *NetworkGameClient) onRoundEnd(e *gamev1.RoundEndEvent) {
+	ngc.game.series = e.Score
+	if e.MatchWinnerId >= 0 {
+		return
+	}
+	ngc.game.intermission = &roundIntermission{
+		event:  e,
+		nextAt: time.Now().Add(time.Duration(e.IntermissionMs) * time.Millisecond),
+	}
+}
+
+// NextRoundStarted 回合间歇中收到了下一回合的开局事件
+func (ngc *NetworkGameClient) NextRoundStarted() bool {
+	return ngc.nextRound
+}
+
+// roundPlayerName 比分中的玩家名（本地玩家为 "You"）
+func (g *Game) roundPlayerName(id int32, localID int) string {
+	if int(id) == localID {
+		return "You"
+	}
+	if tag, ok := g.nameTags[int(id)]; ok {
+		return tag.text
+	}
+	return playerLabel(id)
+}
+
+// seriesScoreText formats the score line, e.g. "You 1  Bob 0"
+func (g *Game) seriesScoreText(score *gamev1.SeriesScore, localID int) string {
+	parts := make([]string, 0, len(score.Wins))
+	for _, w := range score.Wins {
+		parts = append(parts, fmt.Sprintf("%s %d", g.roundPlayerName(w.PlayerId, localID), w.Wins))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// drawRoundScore 左上角的回合比分
+func (g *Game) drawRoundScore(screen *ebiten.Image) {
+	if g.series == nil {
+		return
+	}
+	line := fmt.Sprintf("ROUND %d (first to %d)  %s", g.series.Round, g.series.RoundsToWin, g.seriesScoreText(g.series, g.localPlayerID()))
+	drawText(screen, 8, 10, line, roundScoreColor)
+}
+
+// drawIntermission 回合间歇画面：本回合结果、比分和下一回合倒计时
+func (g *Game) drawIntermission(screen *ebiten.Image) {
+	if g.intermission == nil {
+		return
+	}
+	e := g.intermission.event
+	localID := g.localPlayerID()
+
+	result := "Draw"
+	switch {
+	case e.WinningTeam != 0:
+		result = teamLabel(int(e.WinningTeam)) + " takes the round"
+	case e.WinnerId >= 0:
+		result = g.roundPlayerName(e.WinnerId, localID) + " takes the round"
+	}
+	seconds := int(time.Until(g.intermission.nextAt).Seconds() + 0.999)
+
+	width, height := 360, 110
+	x, y := (ScreenWidth-width)/2, (ScreenHeight-height)/2
+	vector.DrawFilledRect(screen, float32(x), float32(y), float32(width), float32(height), intermissionPanel, false)
+	drawCenteredText(screen, fmt.Sprintf("ROUND %d OVER", e.Round), ScreenWidth/2, y+14, intermissionTitle)
+	drawCenteredText(screen, result, ScreenWidth/2, y+38, roundScoreColor)
+	drawCenteredText(screen, g.seriesScoreText(e.Score, localID), ScreenWidth/2, y+60, roundScoreColor)
+	drawCenteredText(screen, fmt.Sprintf("Next round in %ds", max(seconds, 0)), ScreenWidth/2, y+84, uiTextSecondary)
+}
+
+// localPlayerID 本地玩家 ID（观战时为 -1）
+func (g *Game) localPlayerID() int {
+	if p := g.localPlayer(); p != nil {
+		return p.corePlayer.ID
+	}
+	return -1
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 按住 Tab 显示的记分板：本局每名玩家的击杀/阵亡（K/D）
+const (
+	scoreboardWidth     = 280
+	scoreboardRowHeight = 18
+	scoreboardPadding   = 10
+	scoreboardKillsX    = 170 // K 列相对面板左边的位置
+	scoreboardDeathsX   = 210
+	scoreboardSelfX     = 245
+)
+
+var (
+	scoreboardBackground = color.RGBA{20, 24, 32, 220}
+	scoreboardHeader     = color.RGBA{160, 170, 190, 255}
+	scoreboardText       = color.RGBA{230, 230, 230, 255}
+	scoreboardLocal      = color.RGBA{255, 220, 120, 255}
+	scoreboardDead       = color.RGBA{130, 130, 130, 255}
+)
+
+// recordDeath 联网模式把服务器的死亡事件记入本地 KillLog（客户端不做伤害判定，KillLog 只能来自事件）
+func (g *Game) recordDeath(e *gamev1.PlayerDiedEvent, frame int32) {
+	killer := int(e.KillerId)
+	if e.KillerId == -1 {
+		killer = int(e.PlayerId)
+	}
+	g.coreGame.KillLog = append(g.coreGame.KillLog, core.KillRecord{
+		Frame:    frame,
+		VictimID: int(e.PlayerId),
+		KillerID: killer,
+	})
+}
+
+// scoreboardRow 记分板的一行
+type scoreboardRow struct {
+	player *core.Player
+	name   string
+	stats  core.KillStats
+}
+
+// scoreboardRows 按击杀数从高到低排列（相同时按玩家 ID）
+func (g *Game) scoreboardRows() []scoreboardRow {
+	rows := make([]scoreboardRow, 0, len(g.players))
+	for _, player := range g.sortedPlayers() {
+		p := player.corePlayer
+		stats := g.coreGame.KillStats(p.ID)
+		if p.Dead && stats.Deaths == 0 {
+			stats.Deaths = 1 // 重连前错过了死亡事件，以同步的状态为准
+		}
+		name := playerLabel(int32(p.ID))
+		if tag, ok := g.nameTags[p.ID]; ok {
+			name = tag.text
+		}
+		rows = append(rows, scoreboardRow{player: p, name: name, stats: stats})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].stats.Kills > rows[j].stats.Kills
+	})
+	return rows
+}
+
+// drawScoreboard 按住 Tab 时在屏幕中央绘制记分板
+func (g *Game) drawScoreboard(screen *ebiten.Image) {
+	if !ebiten.IsKeyPressed(ebiten.KeyTab) {
+		return
+	}
+	rows := g.scoreboardRows()
+	height := scoreboardPadding*2 + scoreboardRowHeight*(len(rows)+1)
+	x := (ScreenWidth - scoreboardWidth) / 2
+	y := (ScreenHeight - height) / 2
+	vector.DrawFilledRect(screen, float32(x), float32(y), scoreboardWidth, float32(height), scoreboardBackground, false)
+
+	top := y + scoreboardPadding
+	drawText(screen, x+scoreboardPadding, top, "PLAYER", scoreboardHeader)
+	drawText(screen, x+scoreboardKillsX, top, "K", scoreboardHeader)
+	drawText(screen, x+scoreboardDeathsX, top, "D", scoreboardHeader)
+	drawText(screen, x+scoreboardSelfX, top, "SELF", scoreboardHeader)
+
+	local := g.localPlayer()
+	for i, row := range rows {
+		rowY := top + scoreboardRowHeight*(i+1)
+		clr := color.Color(scoreboardText)
+		switch {
+		case local != nil && row.player == local.corePlayer:
+			clr = scoreboardLocal
+		case row.player.Dead:
+			clr = scoreboardDead
+		}
+		drawText(screen, x+scoreboardPadding, rowY, row.name, clr)
+		drawText(screen, x+scoreboardKillsX, rowY, fmt.Sprint(row.stats.Kills), clr)
+		drawText(screen, x+scoreboardDeathsX, rowY, fmt.Sprint(row.stats.Deaths), clr)
+		drawText(screen, x+scoreboardSelfX, rowY, fmt.Sprint(row.stats.SelfKills), clr)
+	}
+}
@@ -0,0 +1,461 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	serverStatusTimeout  = 2 * time.Second // 单次状态查询超时
+	serverStatusInterval = 3 * time.Second // 状态刷新间隔
+	serverBannerMaxLen   = 54              // 服务器名称与公告一行最多显示的字符数
+)
+
+// SavedServer 保存的服务器（客户端配置的 servers 字段）
+type SavedServer struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Proto   string `json:"proto,omitempty"` // 空表示 tcp
+}
+
+// ParseSavedServer 解析 "名称=地址[/协议]"，例如 "Home LAN=192.168.1.5:8080/kcp"
+func ParseSavedServer(spec string) (SavedServer, error) {
+	name, addr, ok := strings.Cut(spec, "=")
+	name, addr = strings.TrimSpace(name), strings.TrimSpace(addr)
+	if !ok || name == "" || addr == "" {
+		return SavedServer{}, fmt.Errorf("无效的服务器 %q（格式: 名称=地址[/协议]）", spec)
+	}
+	server := SavedServer{Name: name, Address: addr}
+	if i := strings.LastIndex(addr, "/"); i >= 0 {
+		server.Address, server.Proto = addr[:i], strings.ToLower(addr[i+1:])
+	}
+	if server.Proto != "" && server.Proto != "tcp" && server.Proto != "kcp" && server.Proto != "ws" {
+		return SavedServer{}, fmt.Errorf("不支持的协议: %s", server.Proto)
+	}
+	return server, nil
+}
+
+// AddServer 保存服务器，同名服务器会被替换
+func (c *ClientConfig) AddServer(server SavedServer) {
+	for i, existing := range c.Servers {
+		if strings.EqualFold(existing.Name, server.Name) {
+			c.Servers[i] = server
+			return
+		}
+	}
+	c.Servers = append(c.Servers, server)
+}
+
+// ServerStatus 服务器状态查询结果
+type ServerStatus struct {
+	Ping     time.Duration
+	Name     string
+	Version  string
+	MOTD     string
+	Rooms    int32
+	MaxRooms int32 // 0 表示不限制
+	Players  int32
+}
+
+// QueryServerStatus 用一次性连接查询服务器状态（不进入大厅，查询完即断开）
+func QueryServerStatus(serverAddr, proto string, timeout time.Duration) (ServerStatus, error) {
+	conn, err := dialServer(serverAddr, proto)
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	start := time.Now()
+	packet, err := protocol.NewServerStatusRequestPacket(start.UnixMilli())
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return ServerStatus{}, err
+	}
+	if err := writePacket(conn, data); err != nil {
+		return ServerStatus{}, err
+	}
+
+	// 服务器可能先推送其他消息（例如 Ping），读到状态响应为止
+	for {
+		buf, err := readPacket(conn)
+		if err != nil {
+			return ServerStatus{}, err
+		}
+		msg, err := DecodeServerPacket(buf)
+		if err != nil {
+			continue
+		}
+		if resp, ok := msg.(*gamev1.ServerStatusResponse); ok {
+			return ServerStatus{
+				Ping:     time.Since(start),
+				Name:     resp.ServerName,
+				Version:  resp.Version,
+				MOTD:     resp.Motd,
+				Rooms:    resp.Rooms,
+				MaxRooms: resp.MaxRooms,
+				Players:  resp.Players,
+			}, nil
+		}
+	}
+}
+
+// serverEntry 服务器列表中的一行
+type serverEntry struct {
+	server    SavedServer
+	status    ServerStatus
+	err       error
+	queried   bool // 已有查询结果
+	inFlight  bool
+	lastQuery time.Time
+}
+
+type statusResult struct {
+	index  int
+	status ServerStatus
+	err    error
+}
+
+type connectResult struct {
+	server  SavedServer
+	network *NetworkClient
+	err     error
+}
+
+// serverDigitKeys 一键连接列表前 9 个服务器
+var serverDigitKeys = []ebiten.Key{
+	ebiten.KeyDigit1, ebiten.KeyDigit2, ebiten.KeyDigit3,
+	ebiten.KeyDigit4, ebiten.KeyDigit5, ebiten.KeyDigit6,
+	ebiten.KeyDigit7, ebiten.KeyDigit8, ebiten.KeyDigit9,
+}
+
+// ServerBrowser shows saved servers with live ping and player counts,
+// then hands over to the lobby once connected.
+type ServerBrowser struct {
+	entries       []serverEntry
+	selectedIndex int
+	input         keyTracker
+	statusChan    chan statusResult
+	connectChan   chan connectResult
+	connecting    bool
+	lastError     string
+
+	character     core.CharacterType
+	controlScheme ControlScheme
+	hudHidden     bool
+	aiScript      string
+
+	// 账号偏好（见 NetworkClient.SetAccount）
+	accountKey    string
+	keepCharacter bool
+	control       string
+	telemetry     bool // 见 NetworkClient.SetTelemetry
+
+	// 连接成功后由信号处理 goroutine 读取，用于退出时断开
+	mu        sync.Mutex
+	network   *NetworkClient
+	connected SavedServer
+
+	lobby *LobbyClient
+}
+
+func NewServerBrowser(servers []SavedServer, character core.CharacterType, controlScheme ControlScheme) *ServerBrowser {
+	entries := make([]serverEntry, len(servers))
+	for i, server := range servers {
+		entries[i].server = server
+	}
+	return &ServerBrowser{
+		entries:       entries,
+		statusChan:    make(chan statusResult, len(servers)),
+		connectChan:   make(chan connectResult, 1),
+		character:     character,
+		controlScheme: controlScheme,
+	}
+}
+
+// SetHUDHidden sets whether matches start with the HUD hidden
+func (sb *ServerBrowser) SetHUDHidden(hidden bool) {
+	sb.hudHidden = hidden
+}
+
+// SetAIScript makes the A key add scripted AI players running src
+func (sb *ServerBrowser) SetAIScript(src string) {
+	sb.aiScript = src
+}
+
+// SetAccount 连接时携带的账号密钥（见 NetworkClient.SetAccount）
+func (sb *ServerBrowser) SetAccount(key string, keepCharacter bool, control string) {
+	sb.accountKey = key
+	sb.keepCharacter = keepCharacter
+	sb.control = control
+}
+
+// SetTelemetry 连接后加入房间时是否同意收集输入观察（见 NetworkClient.SetTelemetry）
+func (sb *ServerBrowser) SetTelemetry(enabled bool) {
+	sb.telemetry = enabled
+}
+
+// Connected 已连接的服务器（用于保存为上次使用的服务器）
+func (sb *ServerBrowser) Connected() (SavedServer, bool) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.connected, sb.network != nil
+}
+
+// Close 断开已建立的连接
+func (sb *ServerBrowser) Close() {
+	sb.mu.Lock()
+	network := sb.network
+	sb.mu.Unlock()
+	if network != nil {
+		network.Close()
+	}
+}
+
+func (sb *ServerBrowser) Update() error {
+	if sb.lobby != nil {
+		return sb.lobby.Update()
+	}
+
+	sb.refreshStatus()
+
+	select {
+	case res := <-sb.connectChan:
+		sb.connecting = false
+		if res.err != nil {
+			log.Printf("连接 %s 失败: %v", res.server.Address, res.err)
+			sb.lastError = "Could not connect to " + res.server.Name
+			break
+		}
+		sb.enterLobby(res.server, res.network)
+		return nil
+	default:
+	}
+
+	if sb.connecting {
+		return nil
+	}
+	if sb.input.JustPressed(ebiten.KeyR) {
+		for i := range sb.entries {
+			sb.entries[i].lastQuery = time.Time{}
+		}
+	}
+	if sb.input.JustPressed(ebiten.KeyArrowUp) || sb.input.JustPressed(ebiten.KeyW) {
+		if sb.selectedIndex > 0 {
+			sb.selectedIndex--
+		}
+	}
+	if sb.input.JustPressed(ebiten.KeyArrowDown) || sb.input.JustPressed(ebiten.KeyS) {
+		if sb.selectedIndex < len(sb.entries)-1 {
+			sb.selectedIndex++
+		}
+	}
+	if sb.input.JustPressed(ebiten.KeyEnter) && sb.selectedIndex < len(sb.entries) {
+		sb.connect(sb.selectedIndex)
+		return nil
+	}
+	for i, key := range serverDigitKeys {
+		if sb.input.JustPressed(key) && i < len(sb.entries) {
+			sb.selectedIndex = i
+			sb.connect(i)
+			break
+		}
+	}
+	return nil
+}
+
+// refreshStatus 定期在后台查询每个服务器的状态，并收取查询结果
+func (sb *ServerBrowser) refreshStatus() {
+	for i := range sb.entries {
+		entry := &sb.entries[i]
+		if entry.inFlight || time.Since(entry.lastQuery) < serverStatusInterval {
+			continue
+		}
+		entry.inFlight = true
+		entry.lastQuery = time.Now()
+		go func(index int, server SavedServer) {
+			status, err := QueryServerStatus(server.Address, server.Proto, serverStatusTimeout)
+			sb.statusChan <- statusResult{index: index, status: status, err: err}
+		}(i, entry.server)
+	}
+
+	for {
+		select {
+		case res := <-sb.statusChan:
+			entry := &sb.entries[res.index]
+			entry.inFlight = false
+			entry.queried = true
+			entry.status, entry.err = res.status, res.err
+		default:
+			return
+		}
+	}
+}
+
+func (sb *ServerBrowser) connect(index int) {
+	server := sb.entries[index].server
+	sb.connecting = true
+	sb.lastError = ""
+	go func() {
+		network := NewNetworkClient(server.Address, server.Proto, sb.character)
+		network.SetAccount(sb.accountKey, sb.keepCharacter, sb.control)
+		network.SetTelemetry(sb.telemetry)
+		err := network.Connect()
+		if err == nil {
+			if err := network.SaveControlPreference(); err != nil {
+				log.Printf("保存控制方案失败: %v", err)
+			}
+		}
+		sb.connectChan <- connectResult{server: server, network: network, err: err}
+	}()
+}
+
+func (sb *ServerBrowser) enterLobby(server SavedServer, network *NetworkClient) {
+	sb.mu.Lock()
+	sb.network = network
+	sb.connected = server
+	sb.mu.Unlock()
+
+	sb.lobby = NewLobbyClient(network, sb.controlScheme)
+	sb.lobby.SetHUDHidden(sb.hudHidden)
+	sb.lobby.SetAIScript(sb.aiScript)
+	ebiten.SetWindowTitle("Bomberman - 大厅 [" + server.Name + "] [" + server.Address + "]")
+}
+
+func (sb *ServerBrowser) Draw(screen *ebiten.Image) {
+	if sb.lobby != nil {
+		sb.lobby.Draw(screen)
+		return
+	}
+
+	screen.Fill(ActiveTheme().Background)
+
+	drawPanel(screen, 0, 0, ScreenWidth, 64)
+	drawText(screen, uiPanelPadding, 18, "SERVERS", uiTextPrimary)
+	drawText(screen, uiPanelPadding, 38, "1-9:Connect  Enter:Connect  R:Refresh  W/S:Navigate", uiTextSecondary)
+
+	panelX := uiPanelMargin
+	panelY := 64 + uiPanelMargin
+	panelWidth := ScreenWidth - 2*uiPanelMargin
+	panelHeight := ScreenHeight - 64 - 2*uiPanelMargin - 28
+	drawPanel(screen, panelX, panelY, panelWidth, panelHeight)
+
+	headerY := panelY + uiPanelPadding + 4
+	if len(sb.entries) == 0 {
+		drawText(screen, panelX+uiPanelPadding, headerY, "No saved servers.", uiTextSecondary)
+		drawText(screen, panelX+uiPanelPadding, headerY+uiRowHeight, "Add one with -save-server \"Home=192.168.1.5:8080\"", uiTextMuted)
+		return
+	}
+
+	drawText(screen, panelX+uiPanelPadding+12, headerY, "SERVER", uiTextMuted)
+	drawText(screen, panelX+uiPanelPadding+200, headerY, "PING", uiTextMuted)
+	drawText(screen, panelX+uiPanelPadding+270, headerY, "PLAYERS", uiTextMuted)
+	drawText(screen, panelX+uiPanelPadding+340, headerY, "ROOMS", uiTextMuted)
+
+	y := headerY + uiRowHeight + 4
+	for i, entry := range sb.entries {
+		rowY := y + i*uiRowHeight*2
+		if rowY > panelY+panelHeight-2*uiRowHeight {
+			break
+		}
+
+		indicator := "  "
+		indicatorColor := uiTextSecondary
+		if i == sb.selectedIndex {
+			drawSelectionRect(screen, panelX+uiPanelPadding, rowY-2, panelWidth-2*uiPanelPadding, uiRowHeight*2)
+			indicator = "> "
+			indicatorColor = uiTextPrimary
+		}
+		number := " "
+		if i < len(serverDigitKeys) {
+			number = fmt.Sprint(i + 1)
+		}
+		drawText(screen, panelX+uiPanelPadding, rowY+5, fmt.Sprintf("%s[%s] %s", indicator, number, entry.server.Name), indicatorColor)
+		address := entry.server.Address
+		if entry.server.Proto != "" {
+			address += " (" + entry.server.Proto + ")"
+		}
+		drawText(screen, panelX+uiPanelPadding+28, rowY+5+uiRowHeight-4, address, uiTextMuted)
+		if entry.queried && entry.err == nil {
+			drawText(screen, panelX+uiPanelPadding+200, rowY+5+uiRowHeight-4, serverBanner(entry.status), uiTextMuted)
+		}
+
+		switch {
+		case !entry.queried:
+			drawText(screen, panelX+uiPanelPadding+200, rowY+5, "...", uiTextMuted)
+		case entry.err != nil:
+			drawText(screen, panelX+uiPanelPadding+200, rowY+5, "OFFLINE", uiError)
+		default:
+			drawText(screen, panelX+uiPanelPadding+200, rowY+5, fmt.Sprintf("%dms", entry.status.Ping.Milliseconds()), pingColor(entry.status.Ping))
+			drawText(screen, panelX+uiPanelPadding+270, rowY+5, fmt.Sprint(entry.status.Players), uiTextPrimary)
+			rooms := fmt.Sprint(entry.status.Rooms)
+			if entry.status.MaxRooms > 0 {
+				rooms = fmt.Sprintf("%d/%d", entry.status.Rooms, entry.status.MaxRooms)
+			}
+			drawText(screen, panelX+uiPanelPadding+340, rowY+5, rooms, uiTextPrimary)
+		}
+	}
+
+	footerY := ScreenHeight - 24
+	if sb.connecting {
+		drawText(screen, panelX+uiPanelPadding, footerY, "CONNECTING...", uiAccent)
+	}
+	if sb.lastError != "" {
+		drawText(screen, panelX+uiPanelPadding, footerY+12, sb.lastError, uiError)
+	}
+}
+
+func (sb *ServerBrowser) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ScreenWidth, ScreenHeight
+}
+
+// serverBanner formats the server's own name, version and MOTD for display
+func serverBanner(status ServerStatus) string {
+	banner := status.Name
+	if status.Version != "" {
+		banner += " " + status.Version
+	}
+	if status.MOTD != "" {
+		banner += " - " + status.MOTD
+	}
+	banner = asciiOnly(banner)
+	if len(banner) > serverBannerMaxLen {
+		banner = banner[:serverBannerMaxLen-3] + "..."
+	}
+	return banner
+}
+
+// asciiOnly replaces characters the lobby font cannot draw
+func asciiOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e {
+			return '?'
+		}
+		return r
+	}, s)
+}
+
+// pingColor colors a latency reading: green is comfortable, red is laggy
+func pingColor(ping time.Duration) color.Color {
+	switch {
+	case ping < 80*time.Millisecond:
+		return uiSuccess
+	case ping < 150*time.Millisecond:
+		return uiWarning
+	default:
+		return uiError
+	}
+}
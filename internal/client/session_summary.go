@@ -0,0 +1,154 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 会话摘要：本次运行期间的联机统计（对局数、RTT 与抖动、重连次数、本地预测的纠偏情况），
+// 退出时追加到本地日志文件，玩家反馈"一直卡"时不用开着调试界面复现，翻日志就能看出是延迟、抖动、断线还是预测误差。
+// 没有连接过服务器的运行（单机、同屏淘汰赛、地图编辑器）不写
+
+// sessionStats 会话统计（接收协程记录 RTT，游戏循环记录其余数据，内部加锁）
+type sessionStats struct {
+	mu        sync.Mutex
+	startedAt time.Time
+	servers   []string // 连接过的服务器（地址/协议，按首次连接顺序）
+
+	matches int
+
+	rttCount      int
+	rttSum, rttSq float64 // 毫秒
+	rttMax        int64
+
+	reconnects        int
+	reconnectFailures int
+
+	corrections        int     // 权威状态与本地预测不一致的次数
+	snappedCorrections int     // 误差超过平滑阈值、直接跳到权威位置的次数
+	correctionSum      float64 // 误差距离之和（像素）
+	correctionMax      float64
+
+	desyncs int
+}
+
+// session 本次运行的会话统计
+var session = &sessionStats{startedAt: time.Now()}
+
+func (s *sessionStats) connected(addr, proto string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	server := addr + "/" + proto
+	for _, known := range s.servers {
+		if known == server {
+			return
+		}
+	}
+	s.servers = append(s.servers, server)
+}
+
+func (s *sessionStats) matchStarted() {
+	s.mu.Lock()
+	s.matches++
+	s.mu.Unlock()
+}
+
+func (s *sessionStats) rtt(ms int64) {
+	s.mu.Lock()
+	s.rttCount++
+	s.rttSum += float64(ms)
+	s.rttSq += float64(ms) * float64(ms)
+	s.rttMax = max(s.rttMax, ms)
+	s.mu.Unlock()
+}
+
+func (s *sessionStats) reconnected(ok bool) {
+	s.mu.Lock()
+	if ok {
+		s.reconnects++
+	} else {
+		s.reconnectFailures++
+	}
+	s.mu.Unlock()
+}
+
+// corrected 记录一次预测纠偏，dist 为预测位置与重放后位置的距离（像素），snapped 表示直接跳转
+func (s *sessionStats) corrected(dist float64, snapped bool) {
+	s.mu.Lock()
+	s.corrections++
+	if snapped {
+		s.snappedCorrections++
+	}
+	s.correctionSum += dist
+	s.correctionMax = max(s.correctionMax, dist)
+	s.mu.Unlock()
+}
+
+func (s *sessionStats) desynced() {
+	s.mu.Lock()
+	s.desyncs++
+	s.mu.Unlock()
+}
+
+// SessionSummary 本次运行的会话摘要（多行文本）；没有连接过服务器时返回空字符串
+func SessionSummary() string {
+	s := session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.servers) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== 会话 %s - %s（%s）===\n",
+		s.startedAt.Format("2006-01-02 15:04:05"), now.Format("15:04:05"), now.Sub(s.startedAt).Round(time.Second))
+	fmt.Fprintf(&b, "服务器: %s\n", strings.Join(s.servers, ", "))
+	fmt.Fprintf(&b, "对局: %d\n", s.matches)
+	if s.rttCount > 0 {
+		avg := s.rttSum / float64(s.rttCount)
+		jitter := math.Sqrt(max(0, s.rttSq/float64(s.rttCount)-avg*avg))
+		fmt.Fprintf(&b, "RTT: 平均 %.0fms, 抖动 %.0fms, 最大 %dms（%d 次采样）\n", avg, jitter, s.rttMax, s.rttCount)
+	} else {
+		b.WriteString("RTT: 没有采样\n")
+	}
+	fmt.Fprintf(&b, "重连: 成功 %d 次, 失败 %d 次\n", s.reconnects, s.reconnectFailures)
+	if s.corrections > 0 {
+		fmt.Fprintf(&b, "预测纠偏: %d 次（直接跳转 %d 次）, 平均 %.1fpx, 最大 %.1fpx\n",
+			s.corrections, s.snappedCorrections, s.correctionSum/float64(s.corrections), s.correctionMax)
+	} else {
+		b.WriteString("预测纠偏: 0 次\n")
+	}
+	fmt.Fprintf(&b, "状态不同步: %d 次\n", s.desyncs)
+	return b.String()
+}
+
+// DefaultSessionLogPath 默认会话日志路径（与配置文件同一目录下的 sessions.log）
+func DefaultSessionLogPath() string {
+	return filepath.Join(filepath.Dir(DefaultConfigPath()), "sessions.log")
+}
+
+// AppendSessionSummary 把会话摘要追加到日志文件；没有连接过服务器时不写
+func AppendSessionSummary(path string) error {
+	summary := SessionSummary()
+	if summary == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(summary + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,128 @@
+package client
+
+import "bomberman/pkg/core"
+
+// countdownBeepSeconds 限时结束和决斗加时淹没前最后几秒每秒响一次
+const countdownBeepSeconds = 5
+
+// soundCues 比较相邻两帧的核心状态触发音效：新炸弹、新爆炸、玩家阵亡、道具属性提升与倒计时。
+// 单机模式由本地模拟推进，联机模式由服务器状态同步推进，两者共用同一套判断
+type soundCues struct {
+	primed          bool
+	lastFrame       int32
+	bombs           map[core.GridPos]int32 // 格子 -> 放置帧号
+	lastExplosion   int32                  // 已播放过的最新爆炸的创建帧号
+	dead            map[int]bool
+	power           map[int]int // 玩家 -> 道具强化程度（powerLevel）
+	countdownSecond int32
+}
+
+// playSoundCues 根据本帧的状态变化播放音效（对局重新开始时只记录状态，不补放）
+func (g *Game) playSoundCues() {
+	c := &g.sounds
+	game := g.coreGame
+	if c.primed && game.CurrentFrame < c.lastFrame {
+		c.primed = false
+	}
+	silent := !c.primed
+	c.primed = true
+	prevFrame := c.lastFrame
+	c.lastFrame = game.CurrentFrame
+
+	bombs := make(map[core.GridPos]int32, len(game.Bombs))
+	placed := false
+	for _, bomb := range game.Bombs {
+		cell := core.GridPos{GridX: bomb.GridX, GridY: bomb.GridY}
+		bombs[cell] = bomb.PlacedAtFrame
+		// 早就放下、刚进入视野的炸弹（服务器兴趣管理）不算新放置
+		if frame, ok := c.bombs[cell]; (!ok || frame != bomb.PlacedAtFrame) && bomb.PlacedAtFrame >= prevFrame {
+			placed = true
+		}
+	}
+	c.bombs = bombs
+
+	// 同一帧的多个新爆炸只播一次，音量取离本地玩家最近的那个
+	explosionVolume := 0.0
+	newest := c.lastExplosion
+	for _, explosion := range game.Explosions {
+		if explosion.CreatedAtFrame > c.lastExplosion {
+			newest = max(newest, explosion.CreatedAtFrame)
+			explosionVolume = max(explosionVolume, g.explosionIntensity(explosion))
+		}
+	}
+	c.lastExplosion = newest
+
+	if c.dead == nil {
+		c.dead = make(map[int]bool)
+		c.power = make(map[int]int)
+	}
+	died, poweredUp := false, false
+	for _, p := range game.Players {
+		if p.Dead && !c.dead[p.ID] {
+			died = true
+		}
+		c.dead[p.ID] = p.Dead
+		level := powerLevel(p)
+		if prev, ok := c.power[p.ID]; ok && level > prev && !p.Dead {
+			poweredUp = true
+		}
+		c.power[p.ID] = level
+	}
+
+	second := g.countdownSecond()
+	countdown := second != c.countdownSecond && second >= 0
+	c.countdownSecond = second
+
+	if silent {
+		return
+	}
+	if placed {
+		playSound(SoundBombPlace)
+	}
+	if explosionVolume > 0 {
+		playSoundScaled(SoundExplosion, explosionVolume)
+	}
+	if died {
+		playSound(SoundDeath)
+	}
+	if poweredUp {
+		playSound(SoundPowerUp)
+	}
+	if countdown {
+		if second == 0 {
+			playSound(SoundCountdownEnd)
+		} else {
+			playSound(SoundCountdown)
+		}
+	}
+}
+
+// powerLevel 道具带来的强化程度（任何一项提升都视为拾取了道具）
+func powerLevel(p *core.Player) int {
+	level := p.MaxBombs + p.BombRange + len(p.Effects) + int(p.Speed*100)
+	if p.CanKick {
+		level++
+	}
+	return level
+}
+
+// countdownSecond 倒计时剩余秒数（向上取整），不在最后 countdownBeepSeconds 秒内时为 -1。
+// 决斗加时倒计时优先于对局限时
+func (g *Game) countdownSecond() int32 {
+	game := g.coreGame
+	endFrame := g.matchEndFrame
+	if endFrame <= 0 {
+		endFrame = game.MatchEndFrame
+	}
+	remaining := int32(-1)
+	switch {
+	case game.OvertimeTriggered():
+		remaining = game.OvertimeFloodFrame - game.CurrentFrame
+	case endFrame > 0:
+		remaining = endFrame - game.CurrentFrame
+	}
+	if remaining < 0 || remaining > countdownBeepSeconds*core.TPS {
+		return -1
+	}
+	return (remaining + core.TPS - 1) / core.TPS
+}
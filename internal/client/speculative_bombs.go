@@ -0,0 +1,76 @@
+package client
+
+import (
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// speculativeBombWindowFrames 远端玩家放弹后多少帧内仍视为"正在连续放弹"
+const speculativeBombWindowFrames = core.TPS
+
+// noteRemoteBomb 远端玩家的活跃炸弹数增加时记下放弹帧（推测阻挡用）
+func (ngc *NetworkGameClient) noteRemoteBomb(remote *Player, protoPlayer *gamev1.PlayerState, frameID int32) {
+	if remote.hasAuthBombs && int(protoPlayer.CurrentBombs) > remote.authActiveBombs {
+		ngc.remoteBombFrames[remote.corePlayer.ID] = frameID
+	}
+}
+
+// speculativeBombCells 预测时视为有炸弹的格子
+// 远端玩家刚放过炸弹、在权威状态中站着不动、并且还能再放时，它脚下的格子
+// 很可能马上出现炸弹；服务器的炸弹要一个往返后才能收到，本地提前挡住，
+// 避免先走进去再被纠偏拉回
+func (ngc *NetworkGameClient) speculativeBombCells(frameID int32) []core.GridPos {
+	game := ngc.game.coreGame
+	var cells []core.GridPos
+	for playerID, pos := range ngc.remoteAuth {
+		remote := ngc.playersMap[playerID]
+		if remote == nil || remote.corePlayer.Dead || pos.isMoving {
+			continue
+		}
+		placedAt, ok := ngc.remoteBombFrames[playerID]
+		if !ok || game.CurrentFrame-placedAt > speculativeBombWindowFrames {
+			continue
+		}
+		p := remote.corePlayer
+		if p.NextPlacementFrame > frameID || (remote.hasAuthBombs && remote.authActiveBombs >= p.MaxBombs) {
+			continue
+		}
+
+		cell := core.PlayerXYToGrid(int(pos.x), int(pos.y))
+		if game.Map.GetTile(cell.GridX, cell.GridY) != core.TileEmpty || hasBombAt(game.Bombs, cell) {
+			continue
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+func hasBombAt(bombs []*core.Bomb, cell core.GridPos) bool {
+	for _, bomb := range bombs {
+		if bomb.GridX == cell.GridX && bomb.GridY == cell.GridY {
+			return true
+		}
+	}
+	return false
+}
+
+// withSpeculativeBombs 预测期间在推测的格子上临时放置炸弹，只参与本地玩家的碰撞
+func (ngc *NetworkGameClient) withSpeculativeBombs(frameID int32, fn func()) {
+	cells := ngc.speculativeBombCells(frameID)
+	if len(cells) == 0 {
+		fn()
+		return
+	}
+
+	game := ngc.game.coreGame
+	n := len(game.Bombs)
+	for _, cell := range cells {
+		bomb := core.NewBomb(cell.GridX, cell.GridY, -1, frameID)
+		bomb.ExplodeAtFrame = frameID + game.Config.BombFuseFrames
+		game.Bombs = append(game.Bombs, bomb)
+	}
+
+	fn()
+
+	game.Bombs = game.Bombs[:n]
+}
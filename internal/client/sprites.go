@@ -0,0 +1,146 @@
+package client
+
+import (
+	"embed"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/png" // 注册 PNG 解码器
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 精灵图：玩家（四个方向的行走动画）、炸弹、爆炸和地图格子按精灵图绘制。
+// 每张图由 spriteFrameSize 见方的帧排成网格（布局见 sprites/README.md），默认使用构建时嵌入的 sprites 目录，
+// -assets 目录中的同名文件优先。缺少或尺寸不对的图只影响对应的渲染器，它退回到矢量绘制
+
+// spriteFrameSize 每帧的边长（与格子一样大）
+const spriteFrameSize = core.TileSize
+
+//go:embed sprites
+var bundledSprites embed.FS
+
+// spriteSheet 等大帧排成网格的精灵图
+type spriteSheet struct {
+	image      *ebiten.Image
+	cols, rows int
+}
+
+// frame 第 row 行第 col 列的帧（超出范围时取最后一帧）
+func (s *spriteSheet) frame(col, row int) *ebiten.Image {
+	col = max(0, min(col, s.cols-1))
+	row = max(0, min(row, s.rows-1))
+	x, y := col*spriteFrameSize, row*spriteFrameSize
+	return s.image.SubImage(image.Rect(x, y, x+spriteFrameSize, y+spriteFrameSize)).(*ebiten.Image)
+}
+
+// 地图格子精灵图中各格子所在的列
+var tileSpriteColumns = map[core.TileType]int{
+	core.TileEmpty: 0,
+	core.TileWall:  1,
+	core.TileBrick: 2,
+	core.TileDoor:  3,
+}
+
+// spriteSet 已加载的精灵图（nil 表示该类精灵图不可用，使用矢量绘制）
+type spriteSet struct {
+	players   map[core.CharacterType]*spriteSheet // 4 列行走帧 x 4 行方向（下、上、左、右）
+	bomb      *spriteSheet                        // 引线燃烧的各帧
+	explosion *spriteSheet                        // 火焰从出现到消散的各帧（灰度，按主题颜色着色）
+	tiles     *spriteSheet                        // 空地、墙、砖块、门（灰度，按主题颜色着色）
+}
+
+// sprites 当前的精灵图（InitSprites 之前全部为 nil）
+var sprites spriteSet
+
+// InitSprites 加载精灵图（需在游戏开始前调用），dir 为 -assets 指定的目录（空表示只用内置精灵图）。
+// 返回加载成功的精灵图数量和总数
+func InitSprites(dir string) (loaded, total int) {
+	load := func(name string, cols, rows int) *spriteSheet {
+		total++
+		sheet, err := loadSpriteSheet(dir, name, cols, rows)
+		if err != nil {
+			log.Printf("精灵图 %s 不可用，使用矢量绘制: %v", name, err)
+			return nil
+		}
+		loaded++
+		return sheet
+	}
+
+	set := spriteSet{players: make(map[core.CharacterType]*spriteSheet)}
+	for _, charType := range []core.CharacterType{core.CharacterWhite, core.CharacterBlack, core.CharacterRed, core.CharacterBlue} {
+		if sheet := load("player_"+charType.ID()+".png", 4, 4); sheet != nil {
+			set.players[charType] = sheet
+		}
+	}
+	set.bomb = load("bomb.png", 1, 1)
+	set.explosion = load("explosion.png", 1, 1)
+	set.tiles = load("tiles.png", len(tileSpriteColumns), 1)
+	sprites = set
+	log.Printf("精灵图: 加载 %d/%d", loaded, total)
+	return loaded, total
+}
+
+// loadSpriteSheet 读取精灵图：先找 dir 中的文件，没有时使用内置文件。
+// 图片尺寸必须是帧大小的整数倍，且至少有 minCols 列、minRows 行
+func loadSpriteSheet(dir, name string, minCols, minRows int) (*spriteSheet, error) {
+	var data fs.File
+	var err error
+	if dir != "" {
+		data, err = os.Open(filepath.Join(dir, name))
+	}
+	if dir == "" || err != nil {
+		data, err = bundledSprites.Open("sprites/" + name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+
+	img, _, err := image.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	size := img.Bounds().Size()
+	if size.X%spriteFrameSize != 0 || size.Y%spriteFrameSize != 0 {
+		return nil, fmt.Errorf("尺寸 %dx%d 不是 %d 的整数倍", size.X, size.Y, spriteFrameSize)
+	}
+	cols, rows := size.X/spriteFrameSize, size.Y/spriteFrameSize
+	if cols < minCols || rows < minRows {
+		return nil, fmt.Errorf("至少需要 %dx%d 帧，只有 %dx%d", minCols, minRows, cols, rows)
+	}
+	return &spriteSheet{image: ebiten.NewImageFromImage(img), cols: cols, rows: rows}, nil
+}
+
+// directionRow 玩家精灵图中朝向所在的行
+func directionRow(dir core.DirectionType) int {
+	switch dir {
+	case core.DirUp:
+		return 1
+	case core.DirLeft:
+		return 2
+	case core.DirRight:
+		return 3
+	}
+	return 0
+}
+
+// drawSprite 把一帧画到格子大小的位置 (x, y)，按 scale 以帧中心缩放；tint 为着色（nil 表示原色），alpha 为整体透明度
+func drawSprite(screen, frame *ebiten.Image, x, y, scale float64, tint color.Color, alpha float32) {
+	op := &ebiten.DrawImageOptions{}
+	half := float64(spriteFrameSize) / 2
+	op.GeoM.Translate(-half, -half)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(x+half, y+half)
+	if tint != nil {
+		op.ColorScale.ScaleWithColor(tint)
+	}
+	op.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(frame, op)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// takeoverNoticeFrames 公告（AI 接管、房主变更）的显示时长
+const takeoverNoticeFrames = 3 * core.TPS
+
+var (
+	takeoverPromptColor = color.RGBA{255, 220, 90, 255}
+	takeoverNoticeColor = color.RGBA{150, 220, 255, 255}
+)
+
+// TakeoverPrompt 房主审批 AI 接管请求（Y 同意 / N 拒绝），以及接管成功、房主变更的公告
+type TakeoverPrompt struct {
+	pending     *gamev1.TakeoverRequestEvent
+	decision    *Future[*gamev1.RoomActionResponse] // 已发送的审批结果，失败时（申请者已断开、对局已结束）显示原因
+	notice      string
+	noticeUntil int32
+	keys        keyTracker
+}
+
+// OnRequest 收到接管申请（只有房主会收到）
+func (t *TakeoverPrompt) OnRequest(e *gamev1.TakeoverRequestEvent) {
+	t.pending = e
+}
+
+// OnTakeover 某个 AI 已被真人接管
+func (t *TakeoverPrompt) OnTakeover(e *gamev1.AITakeoverEvent, frame int32) {
+	t.Notify(fmt.Sprintf("%s took over %s", e.PlayerName, e.PreviousName), frame)
+}
+
+// Notify 显示一条公告
+func (t *TakeoverPrompt) Notify(notice string, frame int32) {
+	t.notice = notice
+	t.noticeUntil = frame + takeoverNoticeFrames
+}
+
+// Update 处理审批按键，过期的申请自动丢弃（服务器按超时拒绝）
+func (t *TakeoverPrompt) Update(network *NetworkClient, frame int32) {
+	t.pollDecision(frame)
+	if t.pending == nil {
+		return
+	}
+	if frame >= t.pending.ExpiresAtFrame {
+		t.pending = nil
+		return
+	}
+
+	approve := t.keys.JustPressed(ebiten.KeyY)
+	deny := t.keys.JustPressed(ebiten.KeyN)
+	if !approve && !deny {
+		return
+	}
+	f, err := network.RequestRoomAction(&gamev1.RoomAction{
+		Type:         gamev1.RoomActionType_ROOM_ACTION_APPROVE_TAKEOVER,
+		TargetPlayer: t.pending.RequestId,
+		Approve:      approve,
+	})
+	if err == nil {
+		t.decision = f
+	}
+	t.pending = nil
+}
+
+// pollDecision 审批结果：成功时等待接管公告，失败时显示原因
+func (t *TakeoverPrompt) pollDecision(frame int32) {
+	if t.decision == nil {
+		return
+	}
+	resp, ok := t.decision.Poll()
+	if !ok {
+		if t.decision.Stale() {
+			t.decision = nil
+		}
+		return
+	}
+	t.decision = nil
+	if resp != nil && !resp.Success {
+		t.Notify(friendlyErrorMessage(resp.ErrorCode, resp.ErrorParams, resp.ErrorMessage), frame)
+	}
+}
+
+// Draw 绘制审批提示与公告
+func (t *TakeoverPrompt) Draw(screen *ebiten.Image, frame int32, hudVisible bool) {
+	if t.pending != nil {
+		seconds := (t.pending.ExpiresAtFrame - frame + core.TPS - 1) / core.TPS
+		msg := fmt.Sprintf("%s wants to take over an AI  Y:Accept N:Deny (%ds)", t.pending.PlayerName, seconds)
+		drawCenteredText(screen, msg, ScreenWidth/2, 28, takeoverPromptColor)
+	}
+	if hudVisible && t.notice != "" && frame < t.noticeUntil {
+		drawCenteredText(screen, t.notice, ScreenWidth/2, 44, takeoverNoticeColor)
+	}
+}
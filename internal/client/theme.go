@@ -0,0 +1,180 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+)
+
+// Theme 客户端主题包：界面背景、地图格子、炸弹与爆炸的配色
+// 渲染器每帧从当前主题取色，切换主题立即生效
+type Theme struct {
+	Name string
+
+	// 界面
+	Background color.RGBA // 大厅背景
+	Panel      color.RGBA // 面板背景
+
+	// 地图
+	Grass       color.RGBA
+	TileBorder  color.RGBA
+	Wall        color.RGBA
+	WallDetail  color.RGBA
+	Brick       color.RGBA
+	BrickDetail color.RGBA
+	Door        color.RGBA
+	DoorRung    color.RGBA
+	DoorPost    color.RGBA
+
+	// 炸弹（主体透明度随闪烁变化）
+	BombBody    color.RGBA
+	BombOutline color.RGBA
+	Fuse        color.RGBA
+	SparkDim    color.RGBA
+	SparkBright color.RGBA
+	BombWarning color.RGBA
+
+	// 爆炸（透明度随消散变化）
+	ExplosionEarly color.RGBA
+	ExplosionMid   color.RGBA
+	ExplosionLate  color.RGBA
+	ExplosionCore  color.RGBA
+	ExplosionEdge  color.RGBA
+}
+
+// builtinThemes 内置主题，第一个为默认
+var builtinThemes = []Theme{
+	{
+		Name:           "classic",
+		Background:     color.RGBA{12, 16, 24, 255},
+		Panel:          color.RGBA{24, 28, 36, 255},
+		Grass:          color.RGBA{34, 139, 34, 255},
+		TileBorder:     color.RGBA{0, 0, 0, 100},
+		Wall:           color.RGBA{80, 80, 80, 255},
+		WallDetail:     color.RGBA{60, 60, 60, 255},
+		Brick:          color.RGBA{205, 133, 63, 255},
+		BrickDetail:    color.RGBA{180, 118, 53, 255},
+		Door:           color.RGBA{255, 215, 0, 255},
+		DoorRung:       color.RGBA{218, 165, 32, 255},
+		DoorPost:       color.RGBA{184, 134, 11, 255},
+		BombBody:       color.RGBA{0, 0, 0, 255},
+		BombOutline:    color.RGBA{50, 50, 50, 255},
+		Fuse:           color.RGBA{139, 69, 19, 255},
+		SparkDim:       color.RGBA{255, 100, 0, 255},
+		SparkBright:    color.RGBA{255, 255, 0, 255},
+		BombWarning:    color.RGBA{255, 0, 0, 255},
+		ExplosionEarly: color.RGBA{255, 255, 0, 255},
+		ExplosionMid:   color.RGBA{255, 165, 0, 255},
+		ExplosionLate:  color.RGBA{255, 0, 0, 255},
+		ExplosionCore:  color.RGBA{255, 255, 255, 255},
+		ExplosionEdge:  color.RGBA{255, 100, 0, 255},
+	},
+	{
+		Name:           "dark",
+		Background:     color.RGBA{6, 6, 10, 255},
+		Panel:          color.RGBA{18, 18, 26, 255},
+		Grass:          color.RGBA{30, 38, 48, 255},
+		TileBorder:     color.RGBA{0, 0, 0, 140},
+		Wall:           color.RGBA{70, 72, 92, 255},
+		WallDetail:     color.RGBA{50, 52, 68, 255},
+		Brick:          color.RGBA{92, 68, 108, 255},
+		BrickDetail:    color.RGBA{76, 54, 92, 255},
+		Door:           color.RGBA{120, 220, 255, 255},
+		DoorRung:       color.RGBA{90, 180, 220, 255},
+		DoorPost:       color.RGBA{60, 140, 180, 255},
+		BombBody:       color.RGBA{8, 8, 12, 255},
+		BombOutline:    color.RGBA{120, 120, 150, 255},
+		Fuse:           color.RGBA{110, 110, 130, 255},
+		SparkDim:       color.RGBA{120, 200, 255, 255},
+		SparkBright:    color.RGBA{230, 250, 255, 255},
+		BombWarning:    color.RGBA{160, 120, 255, 255},
+		ExplosionEarly: color.RGBA{210, 240, 255, 255},
+		ExplosionMid:   color.RGBA{120, 160, 255, 255},
+		ExplosionLate:  color.RGBA{150, 80, 220, 255},
+		ExplosionCore:  color.RGBA{255, 255, 255, 255},
+		ExplosionEdge:  color.RGBA{100, 120, 255, 255},
+	},
+	{
+		// 取自 NES 调色板
+		Name:           "retro",
+		Background:     color.RGBA{0, 0, 0, 255},
+		Panel:          color.RGBA{32, 32, 32, 255},
+		Grass:          color.RGBA{0, 168, 0, 255},
+		TileBorder:     color.RGBA{0, 0, 0, 255},
+		Wall:           color.RGBA{188, 188, 188, 255},
+		WallDetail:     color.RGBA{124, 124, 124, 255},
+		Brick:          color.RGBA{172, 124, 0, 255},
+		BrickDetail:    color.RGBA{124, 8, 0, 255},
+		Door:           color.RGBA{248, 184, 0, 255},
+		DoorRung:       color.RGBA{248, 120, 88, 255},
+		DoorPost:       color.RGBA{172, 124, 0, 255},
+		BombBody:       color.RGBA{0, 0, 0, 255},
+		BombOutline:    color.RGBA{252, 252, 252, 255},
+		Fuse:           color.RGBA{188, 188, 188, 255},
+		SparkDim:       color.RGBA{248, 56, 0, 255},
+		SparkBright:    color.RGBA{248, 184, 0, 255},
+		BombWarning:    color.RGBA{248, 56, 0, 255},
+		ExplosionEarly: color.RGBA{252, 252, 252, 255},
+		ExplosionMid:   color.RGBA{248, 184, 0, 255},
+		ExplosionLate:  color.RGBA{248, 56, 0, 255},
+		ExplosionCore:  color.RGBA{252, 252, 252, 255},
+		ExplosionEdge:  color.RGBA{228, 92, 16, 255},
+	},
+}
+
+// activeTheme 当前主题（启动时设置，之后只在游戏循环中切换）
+var activeTheme = builtinThemes[0]
+
+// ActiveTheme 当前主题
+func ActiveTheme() Theme {
+	return activeTheme
+}
+
+// ThemeNames 内置主题名称
+func ThemeNames() []string {
+	names := make([]string, len(builtinThemes))
+	for i, theme := range builtinThemes {
+		names[i] = theme.Name
+	}
+	return names
+}
+
+// SetTheme 按名称切换主题（不区分大小写，空名称为默认主题）
+func SetTheme(name string) error {
+	if name == "" {
+		activeTheme = builtinThemes[0]
+		return nil
+	}
+	for _, theme := range builtinThemes {
+		if strings.EqualFold(theme.Name, name) {
+			activeTheme = theme
+			return nil
+		}
+	}
+	return fmt.Errorf("未知主题 %q（可选: %s）", name, strings.Join(ThemeNames(), ", "))
+}
+
+// cycleTheme 切换到下一个内置主题
+func cycleTheme() {
+	for i, theme := range builtinThemes {
+		if theme.Name == activeTheme.Name {
+			activeTheme = builtinThemes[(i+1)%len(builtinThemes)]
+			return
+		}
+	}
+	activeTheme = builtinThemes[0]
+}
+
+// withAlpha 替换颜色的透明度
+func withAlpha(c color.RGBA, alpha uint8) color.RGBA {
+	c.A = alpha
+	return c
+}
+
+// lerpColor 两种颜色线性插值（t 取 0~1）
+func lerpColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"math"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// 补发的砖块碎裂：状态包丢失（或增量基线较旧）时，炸开砖块的爆炸可能整个落在丢失的包里，
+// 客户端只在下一次状态中看到砖块消失。应用地图变化时检查最近是否见过覆盖该格子的爆炸，
+// 没有见过（包括首领压碎的砖块）就在原地补一段碎裂动画，画面与正常炸开保持一致
+const (
+	tileDebrisFrames  = 24       // 碎裂动画时长
+	tileDebrisPieces  = 6        // 碎片数量
+	blastMemoryFrames = core.TPS // 爆炸覆盖过的格子记住多久（炸开后才到达的地图变化不再补动画）
+)
+
+// tileDebris 一个格子的碎裂动画
+type tileDebris struct {
+	gridX, gridY int
+	startFrame   int32
+}
+
+// applyRemoteTileChanges 同步服务器下发的地图变化（在同步爆炸之后调用）。
+// 中途加入时第一个状态带着开局以来的全部变化，只应用不补动画
+func (g *Game) applyRemoteTileChanges(changes []*gamev1.TileChange) {
+	animate := g.blastCells != nil
+	g.noteBlastCells()
+	for _, tc := range changes {
+		g.applyRemoteTileChange(int(tc.X), int(tc.Y), core.TileType(tc.NewType), animate)
+	}
+}
+
+// noteBlastCells 记录当前爆炸覆盖的格子
+func (g *Game) noteBlastCells() {
+	frame := g.coreGame.CurrentFrame
+	if g.blastCells == nil {
+		g.blastCells = make(map[core.GridPos]int32)
+	}
+	for cell, seen := range g.blastCells {
+		if frame-seen > blastMemoryFrames || seen > frame {
+			delete(g.blastCells, cell)
+		}
+	}
+	for _, exp := range g.coreGame.Explosions {
+		for _, cell := range exp.Cells {
+			g.blastCells[cell] = frame
+		}
+	}
+}
+
+// applyRemoteTileChange 应用一个格子的变化，没见过爆炸就消失的砖块补一段碎裂动画
+func (g *Game) applyRemoteTileChange(x, y int, newType core.TileType, animate bool) {
+	old := g.coreGame.Map.GetTile(x, y)
+	g.coreGame.Map.SetTile(x, y, newType)
+	if !animate || old != core.TileBrick || newType == core.TileBrick || newType == core.TileWall {
+		return // 只有砖块被破坏才需要动画（突然死亡落墙另有预警）
+	}
+	if _, seen := g.blastCells[core.GridPos{GridX: x, GridY: y}]; seen {
+		return
+	}
+	g.tileDebris = append(g.tileDebris, tileDebris{gridX: x, gridY: y, startFrame: g.coreGame.CurrentFrame})
+}
+
+// drawTileDebris 碎片从格子中心向外飞散、缩小并淡出，开头有一团尘土
+func (g *Game) drawTileDebris(screen *ebiten.Image) {
+	frame := g.coreGame.CurrentFrame
+	theme := ActiveTheme()
+	active := g.tileDebris[:0]
+	for _, d := range g.tileDebris {
+		elapsed := frame - d.startFrame
+		if elapsed < 0 || elapsed >= tileDebrisFrames {
+			continue
+		}
+		active = append(active, d)
+
+		progress := float64(elapsed) / tileDebrisFrames
+		fade := uint8(255 * (1 - progress))
+		cx := float64(d.gridX*core.TileSize + core.TileSize/2)
+		cy := float64(d.gridY*core.TileSize + core.TileSize/2)
+
+		dust := theme.BrickDetail
+		dust.A = fade / 2
+		vector.DrawFilledCircle(screen, float32(cx), float32(cy), float32(core.TileSize/2*(0.6+0.6*progress)), dust, true)
+
+		piece := theme.Brick
+		piece.A = fade
+		// 每个格子的碎片方向错开一些，避免所有碎片都一模一样
+		base := float64((d.gridX*7+d.gridY*13)%tileDebrisPieces) * math.Pi / 18
+		dist := core.TileSize * 0.8 * (1 - (1-progress)*(1-progress))
+		size := float32(core.TileSize/4) * float32(1-progress*0.6)
+		for i := 0; i < tileDebrisPieces; i++ {
+			angle := base + float64(i)*2*math.Pi/tileDebrisPieces
+			px := cx + math.Cos(angle)*dist
+			py := cy + math.Sin(angle)*dist + 6*progress*progress // 略微下落
+			vector.DrawFilledRect(screen, float32(px)-size/2, float32(py)-size/2, size, size, piece, false)
+		}
+	}
+	g.tileDebris = active
+}
@@ -0,0 +1,405 @@
+package client
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+
+	"bomberman/pkg/core"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// 同屏淘汰赛：单机上输入 3-8 名选手，随机排出单败淘汰对阵（人数不足 2 的幂时用轮空补齐），
+// 每场由两名选手在同一台电脑上 1v1（WASD 对方向键，没有 AI），两场之间显示对阵图，决出冠军。
+// 成绩只保存在内存中，冠军画面可以用同一批选手再来一届
+const (
+	tournamentMinPlayers  = 3
+	tournamentMaxPlayers  = 8
+	tournamentNameMaxLen  = 12
+	tournamentReadyFrames = 3 * core.TPS // 每场开始前的倒计时（让选手把手放到键盘上，也避免确认键被当成放弹）
+	tournamentResultDelay = core.TPS     // 比赛结束后多久才接受回车（回车也是方向键一方的放弹键）
+)
+
+// 对阵图中的特殊位置
+const (
+	tournamentBye     = -1 // 轮空
+	tournamentPending = -2 // 等待上一轮结果
+)
+
+type tournamentPhase int
+
+const (
+	tournamentEntry    tournamentPhase = iota // 输入选手名字
+	tournamentBracket                         // 对阵图
+	tournamentMatch                           // 比赛中
+	tournamentChampion                        // 冠军
+)
+
+// bracket 单败淘汰对阵：rounds[r][i] 为第 r 轮第 i 个位置上的选手下标（或 tournamentBye / tournamentPending），
+// 第 r 轮第 2i 与 2i+1 位的胜者进入第 r+1 轮第 i 位，最后一轮只有一个位置，即冠军
+type bracket struct {
+	rounds [][]int
+}
+
+// newBracket 随机排位，轮空分散到不同的对阵中（不会出现轮空对轮空）
+func newBracket(players int, rng *rand.Rand) *bracket {
+	size := 2
+	for size < players {
+		size *= 2
+	}
+	order := rng.Perm(players)
+	byes := size - players
+
+	first := make([]int, 0, size)
+	for i := 0; i < size/2; i++ {
+		first = append(first, order[0])
+		order = order[1:]
+		if i < byes {
+			first = append(first, tournamentBye)
+		} else {
+			first = append(first, order[0])
+			order = order[1:]
+		}
+	}
+
+	b := &bracket{rounds: [][]int{first}}
+	for n := size / 2; n >= 1; n /= 2 {
+		round := make([]int, n)
+		for i := range round {
+			round[i] = tournamentPending
+		}
+		b.rounds = append(b.rounds, round)
+	}
+	return b
+}
+
+// next 下一场要打的比赛（第 round 轮第 match 组），轮空的对阵直接晋级；没有比赛时 ok 为 false
+func (b *bracket) next() (round, match int, ok bool) {
+	for r := 0; r < len(b.rounds)-1; r++ {
+		for i := 0; i < len(b.rounds[r+1]); i++ {
+			if b.rounds[r+1][i] != tournamentPending {
+				continue
+			}
+			a, c := b.rounds[r][2*i], b.rounds[r][2*i+1]
+			switch {
+			case a == tournamentPending || c == tournamentPending:
+				continue
+			case c == tournamentBye:
+				b.rounds[r+1][i] = a
+			case a == tournamentBye:
+				b.rounds[r+1][i] = c
+			default:
+				return r, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// players 第 round 轮第 match 组的两名选手
+func (b *bracket) players(round, match int) (int, int) {
+	return b.rounds[round][2*match], b.rounds[round][2*match+1]
+}
+
+// champion 冠军的下标（还没决出时返回 tournamentPending）
+func (b *bracket) champion() int {
+	return b.rounds[len(b.rounds)-1][0]
+}
+
+// Tournament 同屏淘汰赛（Ebiten 游戏循环）
+type Tournament struct {
+	rng   *rand.Rand
+	input keyTracker
+	phase tournamentPhase
+
+	names  []string
+	buffer string // 正在输入的名字
+	notice string
+
+	bracket      *bracket
+	round, match int
+	wins         map[string]int // 每名选手的累计胜场（按名字，跨届累计）
+	titles       map[string]int // 每名选手的冠军次数
+	game         *Game
+	matchPlayers [2]int
+	readyFrames  int
+	resultFrames int
+	hudHidden    bool
+}
+
+// NewTournament 创建同屏淘汰赛，从输入选手名字开始
+func NewTournament() *Tournament {
+	return &Tournament{
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		wins:   make(map[string]int),
+		titles: make(map[string]int),
+	}
+}
+
+// SetHUDHidden 设置比赛中是否隐藏 HUD
+func (t *Tournament) SetHUDHidden(hidden bool) {
+	t.hudHidden = hidden
+}
+
+func (t *Tournament) Update() error {
+	switch t.phase {
+	case tournamentEntry:
+		t.updateEntry()
+	case tournamentBracket:
+		if t.input.JustPressed(ebiten.KeyEnter) {
+			t.startMatch()
+		}
+	case tournamentMatch:
+		return t.updateMatch()
+	case tournamentChampion:
+		if t.input.JustPressed(ebiten.KeyEnter) {
+			t.start()
+		} else if t.input.JustPressed(ebiten.KeyN) {
+			t.phase = tournamentEntry
+		}
+	}
+	return nil
+}
+
+// updateEntry 输入名字：回车添加，输入为空时回车开始；退格删除字符，输入为空时删除最后一名选手
+func (t *Tournament) updateEntry() {
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(t.buffer) < tournamentNameMaxLen && r > ' ' && r <= '~' {
+			t.buffer += string(r)
+		}
+	}
+	if t.input.JustPressed(ebiten.KeyBackspace) {
+		if t.buffer != "" {
+			t.buffer = t.buffer[:len(t.buffer)-1]
+		} else if len(t.names) > 0 {
+			t.names = t.names[:len(t.names)-1]
+		}
+	}
+	if !t.input.JustPressed(ebiten.KeyEnter) {
+		return
+	}
+	if t.buffer == "" {
+		if len(t.names) < tournamentMinPlayers {
+			t.notice = fmt.Sprintf("Need at least %d players", tournamentMinPlayers)
+			return
+		}
+		t.start()
+		return
+	}
+	if len(t.names) >= tournamentMaxPlayers {
+		t.notice = fmt.Sprintf("At most %d players", tournamentMaxPlayers)
+		return
+	}
+	for _, name := range t.names {
+		if strings.EqualFold(name, t.buffer) {
+			t.notice = t.buffer + " is already entered"
+			return
+		}
+	}
+	t.names = append(t.names, t.buffer)
+	t.buffer = ""
+	t.notice = ""
+}
+
+// start 用当前选手开始新的一届（重新抽签）
+func (t *Tournament) start() {
+	t.bracket = newBracket(len(t.names), t.rng)
+	t.advance()
+	log.Printf("淘汰赛开始: %d 名选手 %s", len(t.names), strings.Join(t.names, ", "))
+}
+
+// advance 找到下一场比赛，全部打完时进入冠军画面
+func (t *Tournament) advance() {
+	round, match, ok := t.bracket.next()
+	if !ok {
+		champion := t.bracket.champion()
+		t.titles[t.names[champion]]++
+		t.phase = tournamentChampion
+		log.Printf("淘汰赛冠军: %s", t.names[champion])
+		return
+	}
+	t.round, t.match = round, match
+	t.phase = tournamentBracket
+}
+
+// startMatch 开始下一场 1v1：左上角 WASD，右下角方向键
+func (t *Tournament) startMatch() {
+	a, b := t.bracket.players(t.round, t.match)
+	t.matchPlayers = [2]int{a, b}
+
+	game := NewGame()
+	game.SetHUDHidden(t.hudHidden)
+	x, y := GridToPlayerXY(0, 0)
+	first := NewPlayer(game, 1, x, y, core.CharacterWhite, false)
+	first.SetControlScheme(ControlWASD)
+	game.AddPlayer(first)
+	x, y = GridToPlayerXY(core.MapWidth-1, core.MapHeight-1)
+	second := NewPlayer(game, 2, x, y, core.CharacterRed, false)
+	second.SetControlScheme(ControlArrow)
+	game.AddPlayer(second)
+	game.nameTags = map[int]nameTag{
+		1: {text: t.names[a] + " (WASD)"},
+		2: {text: t.names[b] + " (Arrows)"},
+	}
+
+	t.game = game
+	t.readyFrames = tournamentReadyFrames
+	t.resultFrames = 0
+	t.phase = tournamentMatch
+}
+
+// updateMatch 倒计时结束后运行比赛；结束后显示结果，按回车回到对阵图（同归于尽则重赛）
+func (t *Tournament) updateMatch() error {
+	confirm := t.input.JustPressed(ebiten.KeyEnter) // 每帧都读取，按住放弹键不会在结束时被当成一次新的按下
+	if t.readyFrames > 0 {
+		t.readyFrames--
+		return nil
+	}
+	if !t.game.gameOver {
+		if err := t.game.Update(); err != nil {
+			return err
+		}
+		if t.game.gameOver {
+			t.game.SetGameOverMessage(t.matchResultText())
+			t.game.SetGameOverDetail("Press Enter for the bracket")
+		}
+		return nil
+	}
+	if t.resultFrames < tournamentResultDelay {
+		t.resultFrames++
+		return nil
+	}
+	if !confirm {
+		return nil
+	}
+
+	winner := t.matchWinner()
+	t.game = nil
+	if winner == tournamentPending {
+		t.startMatch()
+		return nil
+	}
+	loser := t.matchPlayers[0] + t.matchPlayers[1] - winner
+	t.wins[t.names[winner]]++
+	t.bracket.rounds[t.round+1][t.match] = winner
+	log.Printf("淘汰赛第 %d 轮: %s 击败 %s", t.round+1, t.names[winner], t.names[loser])
+	t.advance()
+	return nil
+}
+
+// matchWinner 存活的选手（同归于尽返回 tournamentPending）
+func (t *Tournament) matchWinner() int {
+	for _, p := range t.game.coreGame.Players {
+		if !p.Dead {
+			return t.matchPlayers[p.ID-1]
+		}
+	}
+	return tournamentPending
+}
+
+func (t *Tournament) matchResultText() string {
+	winner := t.matchWinner()
+	if winner == tournamentPending {
+		return "Draw! The match will be replayed"
+	}
+	return t.names[winner] + " wins!"
+}
+
+func (t *Tournament) Draw(screen *ebiten.Image) {
+	switch t.phase {
+	case tournamentEntry:
+		t.drawEntry(screen)
+	case tournamentBracket:
+		t.drawBracket(screen)
+		a, b := t.bracket.players(t.round, t.match)
+		drawCenteredText(screen, fmt.Sprintf("Next: %s (WASD) vs %s (Arrows)", t.names[a], t.names[b]), ScreenWidth/2, ScreenHeight-52, uiAccent)
+		drawCenteredText(screen, "Press Enter to play", ScreenWidth/2, ScreenHeight-30, uiTextSecondary)
+	case tournamentMatch:
+		t.game.Draw(screen)
+		if t.readyFrames > 0 {
+			a, b := t.matchPlayers[0], t.matchPlayers[1]
+			drawCenteredText(screen, t.names[a]+" vs "+t.names[b], ScreenWidth/2, ScreenHeight/2-20, uiTextPrimary)
+			drawCenteredText(screen, fmt.Sprint(t.readyFrames/core.TPS+1), ScreenWidth/2, ScreenHeight/2+4, uiAccent)
+		}
+	case tournamentChampion:
+		t.drawBracket(screen)
+		drawCenteredText(screen, "Champion: "+t.names[t.bracket.champion()], ScreenWidth/2, ScreenHeight-52, uiSuccess)
+		drawCenteredText(screen, "Enter: play again with the same players   N: change players", ScreenWidth/2, ScreenHeight-30, uiTextSecondary)
+	}
+}
+
+// drawEntry 选手名单和累计成绩
+func (t *Tournament) drawEntry(screen *ebiten.Image) {
+	screen.Fill(ActiveTheme().Background)
+	drawPanel(screen, 0, 0, ScreenWidth, 64)
+	drawText(screen, uiPanelPadding, 18, "HOT SEAT TOURNAMENT", uiTextPrimary)
+	drawText(screen, uiPanelPadding, 38, fmt.Sprintf("Type a name + Enter to add (%d-%d players), Enter on an empty line to start", tournamentMinPlayers, tournamentMaxPlayers), uiTextSecondary)
+
+	x := uiPanelMargin + uiPanelPadding
+	y := 64 + uiPanelMargin + uiPanelPadding
+	for i, name := range t.names {
+		line := fmt.Sprintf("%d. %s", i+1, name)
+		if t.wins[name] > 0 || t.titles[name] > 0 {
+			line += fmt.Sprintf("   wins %d  titles %d", t.wins[name], t.titles[name])
+		}
+		drawText(screen, x, y+i*uiRowHeight, line, uiTextPrimary)
+	}
+	if len(t.names) < tournamentMaxPlayers {
+		drawText(screen, x, y+len(t.names)*uiRowHeight, fmt.Sprintf("%d. %s_", len(t.names)+1, t.buffer), uiAccent)
+	}
+	if t.notice != "" {
+		drawText(screen, x, ScreenHeight-40, t.notice, uiWarning)
+	}
+}
+
+// drawBracket 按轮次分列画出对阵图，已分出胜负的对阵中胜者高亮、败者变暗
+func (t *Tournament) drawBracket(screen *ebiten.Image) {
+	screen.Fill(ActiveTheme().Background)
+	drawText(screen, uiPanelPadding, 18, "BRACKET", uiTextPrimary)
+
+	rounds := t.bracket.rounds
+	columnWidth := (ScreenWidth - 2*uiPanelPadding) / len(rounds)
+	top, height := 40, ScreenHeight-40-70
+	for r, round := range rounds {
+		x := uiPanelPadding + r*columnWidth
+		rowHeight := height / len(round)
+		for i, entrant := range round {
+			y := top + i*rowHeight + rowHeight/2
+			label, clr := t.bracketLabel(r, i, entrant)
+			drawText(screen, x, y, label, clr)
+		}
+	}
+}
+
+// bracketLabel 对阵图中一个位置的文字和颜色
+func (t *Tournament) bracketLabel(round, slot, entrant int) (string, color.Color) {
+	switch entrant {
+	case tournamentBye:
+		return "(bye)", uiTextMuted
+	case tournamentPending:
+		return "?", uiTextMuted
+	}
+	name := t.names[entrant]
+	if round == len(t.bracket.rounds)-1 {
+		return name, uiSuccess
+	}
+	switch t.bracket.rounds[round+1][slot/2] {
+	case tournamentPending:
+		if t.phase == tournamentBracket && round == t.round && slot/2 == t.match {
+			return name, uiAccent
+		}
+		return name, uiTextPrimary
+	case entrant:
+		return name, uiSuccess
+	default:
+		return name, uiTextMuted
+	}
+}
+
+func (t *Tournament) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return ScreenWidth, ScreenHeight
+}
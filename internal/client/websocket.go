@@ -0,0 +1,60 @@
+package client
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// webSocketPath 服务器 WebSocket 监听的路径（与 server.WebSocketPath 一致）
+const webSocketPath = "/ws"
+
+// dialWebSocket 建立 WebSocket 连接
+// serverAddr 可以是 host:port（使用 ws://host:port/ws），也可以是完整的 ws:// 或 wss:// 地址
+func dialWebSocket(serverAddr string) (net.Conn, error) {
+	location := serverAddr
+	if !strings.Contains(location, "://") {
+		location = "ws://" + location + webSocketPath
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	origin := "http://" + u.Host
+	if u.Scheme == "wss" {
+		origin = "https://" + u.Host
+	}
+	config, err := websocket.NewConfig(location, origin)
+	if err != nil {
+		return nil, err
+	}
+	config.Dialer = &net.Dialer{Timeout: 5 * time.Second}
+
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	ws.MaxPayloadBytes = MaxPacketSize
+	return &wsConn{Conn: ws}, nil
+}
+
+// wsConn WebSocket 连接，每个二进制帧是一个完整的数据包（不使用长度前缀）
+type wsConn struct {
+	*websocket.Conn
+}
+
+// ReadPacket 读取一帧
+func (c *wsConn) ReadPacket() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.Conn, &data)
+	return data, err
+}
+
+// WritePacket 把数据包作为一个二进制帧发送
+func (c *wsConn) WritePacket(data []byte) error {
+	return websocket.Message.Send(c.Conn, data)
+}
@@ -0,0 +1,127 @@
+package conformance
+
+import (
+	"fmt"
+	"strings"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// receiver 数据包的接收方
+type receiver int
+
+const (
+	toServer receiver = 1 << iota // 由 server.DecodePacket 解析
+	toClient                      // 由 client.DecodeServerPacket 解析
+)
+
+// receivers 每种消息类型的接收方；新增 MessageType 时必须在这里登记，否则校验失败
+var receivers = map[gamev1.MessageType]receiver{
+	gamev1.MessageType_MESSAGE_TYPE_JOIN_REQUEST:            toServer,
+	gamev1.MessageType_MESSAGE_TYPE_CLIENT_INPUT:            toServer,
+	gamev1.MessageType_MESSAGE_TYPE_PING:                    toServer | toClient,
+	gamev1.MessageType_MESSAGE_TYPE_RECONNECT_REQUEST:       toServer,
+	gamev1.MessageType_MESSAGE_TYPE_ROOM_LIST_REQUEST:       toServer,
+	gamev1.MessageType_MESSAGE_TYPE_ROOM_ACTION:             toServer,
+	gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_REQUEST:   toServer,
+	gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_REQUEST:   toServer,
+	gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_REQUEST:      toServer,
+	gamev1.MessageType_MESSAGE_TYPE_DESYNC_REPORT:           toServer,
+	gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_REQUEST:  toServer,
+	gamev1.MessageType_MESSAGE_TYPE_JOIN_RESPONSE:           toClient,
+	gamev1.MessageType_MESSAGE_TYPE_GAME_STATE:              toClient,
+	gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT:              toClient,
+	gamev1.MessageType_MESSAGE_TYPE_PONG:                    toServer | toClient,
+	gamev1.MessageType_MESSAGE_TYPE_RECONNECT_RESPONSE:      toClient,
+	gamev1.MessageType_MESSAGE_TYPE_ROOM_LIST_RESPONSE:      toClient,
+	gamev1.MessageType_MESSAGE_TYPE_ROOM_ACTION_RESPONSE:    toClient,
+	gamev1.MessageType_MESSAGE_TYPE_ROOM_STATE_UPDATE:       toClient,
+	gamev1.MessageType_MESSAGE_TYPE_SERVER_NOTICE:           toClient,
+	gamev1.MessageType_MESSAGE_TYPE_DEBUG_AI_STATE:          toClient,
+	gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_RESPONSE:  toClient,
+	gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_RESPONSE:  toClient,
+	gamev1.MessageType_MESSAGE_TYPE_DELTA_STATE:             toClient,
+	gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_RESPONSE:     toClient,
+	gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_RESPONSE: toClient,
+}
+
+// conformanceCase 一个一致性用例：编码后的数据包和期望的解析结果
+type conformanceCase struct {
+	Name    string
+	Type    gamev1.MessageType
+	Payload proto.Message
+}
+
+// payloadType 按命名约定找到消息类型对应的载荷消息（MESSAGE_TYPE_GAME_STATE -> GameState）
+func payloadType(t gamev1.MessageType) (protoreflect.MessageType, error) {
+	want := strings.ReplaceAll(strings.TrimPrefix(t.String(), "MESSAGE_TYPE_"), "_", "")
+	messages := gamev1.File_bomberman_v1_game_proto.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		md := messages.Get(i)
+		if strings.ToUpper(string(md.Name())) == want {
+			return protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+		}
+	}
+	return nil, fmt.Errorf("找不到 %v 对应的载荷消息", t)
+}
+
+// buildCases 根据 proto 定义生成全部用例：
+// 每种消息类型一个空载荷用例和一个全字段用例；顶层 oneof 的每个成员各生成一个用例
+func buildCases() ([]conformanceCase, error) {
+	var cases []conformanceCase
+	values := gamev1.MessageType(0).Descriptor().Values()
+	for i := 0; i < values.Len(); i++ {
+		t := gamev1.MessageType(values.Get(i).Number())
+		if t == gamev1.MessageType_MESSAGE_TYPE_UNSPECIFIED {
+			continue
+		}
+		if _, ok := receivers[t]; !ok {
+			return nil, fmt.Errorf("消息类型 %v 未登记接收方", t)
+		}
+		mt, err := payloadType(t)
+		if err != nil {
+			return nil, err
+		}
+
+		base := strings.ToLower(strings.TrimPrefix(t.String(), "MESSAGE_TYPE_"))
+		cases = append(cases, conformanceCase{Name: base + "-empty", Type: t, Payload: mt.New().Interface()})
+
+		oneofs := mt.Descriptor().Oneofs()
+		var picks []protoreflect.FieldDescriptor
+		for j := 0; j < oneofs.Len(); j++ {
+			if oneofs.Get(j).IsSynthetic() {
+				continue
+			}
+			fields := oneofs.Get(j).Fields()
+			for k := 0; k < fields.Len(); k++ {
+				picks = append(picks, fields.Get(k))
+			}
+		}
+		if len(picks) == 0 {
+			msg := mt.New()
+			fillMessage(msg, nil, 0)
+			cases = append(cases, conformanceCase{Name: base + "-full", Type: t, Payload: msg.Interface()})
+			continue
+		}
+		for _, pick := range picks {
+			msg := mt.New()
+			fillMessage(msg, pick, 0)
+			cases = append(cases, conformanceCase{Name: base + "-" + string(pick.Name()), Type: t, Payload: msg.Interface()})
+		}
+	}
+	return cases, nil
+}
+
+// encodeCase 确定性地编码数据包（map 字段按 key 排序，保证金标文件稳定）
+func encodeCase(c conformanceCase) ([]byte, error) {
+	opts := proto.MarshalOptions{Deterministic: true}
+	payload, err := opts.Marshal(c.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Marshal(&gamev1.Packet{Type: c.Type, Payload: payload})
+}
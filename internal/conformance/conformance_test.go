@@ -0,0 +1,151 @@
+// Package conformance 协议一致性测试：根据 proto 定义生成数据包，校验服务器与客户端的解析结果，
+// 并与 testdata 中的金标数据包比较，发现字段编号或类型的意外变化
+//
+//	go test ./internal/conformance            校验
+//	go test ./internal/conformance -update    重新生成金标文件（修改 proto 并执行 make gen 之后）
+//
+// 每个用例对应 testdata/<name>.bin（编码后的 Packet）和 <name>.json（期望的解析结果）
+package conformance
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+var update = flag.Bool("update", false, "重新生成金标文件")
+
+const goldenDir = "testdata"
+
+// TestDecoders 每个用例交给接收方的解析器，结果必须与载荷完全一致（解析时丢弃的字段会被发现）
+func TestDecoders(t *testing.T) {
+	cases, err := buildCases()
+	if err != nil {
+		t.Fatalf("生成用例失败: %v", err)
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := encodeCase(c)
+			if err != nil {
+				t.Fatalf("编码失败: %v", err)
+			}
+			checkReceivers(t, c, data, c.Payload)
+		})
+	}
+}
+
+// TestGolden 当前定义生成的数据包与金标一致，金标数据包按期望结果解析
+func TestGolden(t *testing.T) {
+	cases, err := buildCases()
+	if err != nil {
+		t.Fatalf("生成用例失败: %v", err)
+	}
+	if *update {
+		if err := writeGolden(goldenDir, cases); err != nil {
+			t.Fatalf("写入金标文件失败: %v", err)
+		}
+		t.Logf("已生成 %d 个用例到 %s", len(cases), goldenDir)
+		return
+	}
+	if _, err := os.Stat(goldenDir); os.IsNotExist(err) {
+		t.Skip("没有金标文件，执行 make conformance-gen 生成")
+	}
+
+	known := make(map[string]bool, len(cases))
+	for _, c := range cases {
+		known[c.Name] = true
+		t.Run(c.Name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(goldenDir, c.Name+".bin"))
+			if err != nil {
+				t.Fatalf("缺少金标文件（proto 新增了消息？执行 make conformance-gen）: %v", err)
+			}
+
+			// 1. 编码稳定：字段编号或类型变化会导致不一致
+			encoded, err := encodeCase(c)
+			if err != nil {
+				t.Fatalf("编码失败: %v", err)
+			}
+			if !bytes.Equal(encoded, data) {
+				t.Error("编码结果与金标不一致（如果是有意修改协议，执行 make conformance-gen 更新金标文件）")
+			}
+
+			// 2. 接收方按期望结果解析金标数据包
+			expected := c.Payload.ProtoReflect().New().Interface()
+			raw, err := os.ReadFile(filepath.Join(goldenDir, c.Name+".json"))
+			if err == nil {
+				err = protojson.Unmarshal(raw, expected)
+			}
+			if err != nil {
+				t.Fatalf("读取期望结果失败: %v", err)
+			}
+			checkReceivers(t, c, data, expected)
+		})
+	}
+
+	// 金标目录中多余的用例（proto 删除了消息或字段）
+	files, _ := filepath.Glob(filepath.Join(goldenDir, "*.bin"))
+	for _, path := range files {
+		if name := strings.TrimSuffix(filepath.Base(path), ".bin"); !known[name] {
+			t.Errorf("%s: 金标文件没有对应的用例", name)
+		}
+	}
+}
+
+func writeGolden(dir string, cases []conformanceCase) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	// 清理旧用例，避免已删除的消息类型残留
+	old, _ := filepath.Glob(filepath.Join(dir, "*.bin"))
+	jsons, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	for _, path := range append(old, jsons...) {
+		os.Remove(path)
+	}
+
+	for _, c := range cases {
+		data, err := encodeCase(c)
+		if err != nil {
+			return err
+		}
+		expected, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(c.Payload)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, c.Name+".bin"), data, 0o644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, c.Name+".json"), expected, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReceivers 数据包交给登记的接收方解析，结果必须与 expected 一致
+func checkReceivers(t *testing.T, c conformanceCase, data []byte, expected proto.Message) {
+	t.Helper()
+	if receivers[c.Type]&toServer != 0 {
+		checkDecoder(t, "server", decodeWithServer, data, expected)
+	}
+	if receivers[c.Type]&toClient != 0 {
+		checkDecoder(t, "client", decodeWithClient, data, expected)
+	}
+}
+
+func checkDecoder(t *testing.T, side string, decode func([]byte) (proto.Message, error), data []byte, expected proto.Message) {
+	t.Helper()
+	got, err := decode(data)
+	if err != nil {
+		t.Errorf("[%s] 解析失败: %v", side, err)
+		return
+	}
+	if !proto.Equal(got, expected) {
+		t.Errorf("[%s] 解析结果与期望不一致\n  期望: %v\n  实际: %v", side, expected, got)
+	}
+}
@@ -0,0 +1,105 @@
+package conformance
+
+import (
+	"fmt"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/internal/client"
+	"bomberman/internal/server"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// decodeWithServer 用服务器的解析器解析数据包，再还原为 protobuf 消息以便与期望结果比较
+// 服务器解析时丢弃的字段在还原后缺失，比较时即可发现
+func decodeWithServer(data []byte) (proto.Message, error) {
+	ev, err := server.DecodePacket(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ev.Kind {
+	case server.EventJoin:
+		return &gamev1.JoinRequest{
+			RequestId:  ev.Join.RequestID,
+			PlayerName: ev.Join.PlayerName,
+			Character:  ev.Join.Character,
+			RoomId:     ev.Join.RoomID,
+			Spectate:   ev.Join.Spectate,
+			TakeOverAi: ev.Join.TakeOverAI,
+
+			ProtocolVersion: ev.Join.ProtocolVersion,
+			ClientVersion:   ev.Join.ClientVersion,
+
+			AccountKey: ev.Join.AccountKey,
+
+			TelemetryOptIn: ev.Join.TelemetryOptIn,
+		}, nil
+	case server.EventInput:
+		input := &gamev1.ClientInput{Seq: ev.Input.Seq}
+		for _, in := range ev.Input.Inputs {
+			input.Inputs = append(input.Inputs, &gamev1.InputData{
+				FrameId: in.FrameID,
+				Up:      in.Up,
+				Down:    in.Down,
+				Left:    in.Left,
+				Right:   in.Right,
+				Bomb:    in.Bomb,
+				Shove:   in.Shove,
+				Sprint:  in.Sprint,
+			})
+		}
+		return input, nil
+	case server.EventPing:
+		return &gamev1.Ping{ClientTime: ev.Ping.ClientTime}, nil
+	case server.EventPong:
+		return &gamev1.Pong{ClientTime: ev.Pong.ClientTime, ServerTime: ev.Pong.ServerTime, ServerFrame: ev.Pong.ServerFrame}, nil
+	case server.EventReconnect:
+		return &gamev1.ReconnectRequest{RequestId: ev.Reconnect.RequestID, SessionToken: ev.Reconnect.SessionToken}, nil
+	case server.EventRoomList:
+		return &gamev1.RoomListRequest{
+			RequestId:   ev.RoomList.RequestID,
+			Page:        ev.RoomList.Page,
+			PageSize:    ev.RoomList.PageSize,
+			WaitingOnly: ev.RoomList.WaitingOnly,
+			NotFull:     ev.RoomList.NotFull,
+			HasAi:       ev.RoomList.HasAI,
+		}, nil
+	case server.EventRoomAction:
+		return ev.RoomAction.Action, nil
+	case server.EventServerStatus:
+		return &gamev1.ServerStatusRequest{ClientTime: ev.Status.ClientTime}, nil
+	case server.EventReplaySearch:
+		return &gamev1.ReplaySearchRequest{
+			PlayerName: ev.ReplaySearch.PlayerName,
+			Map:        ev.ReplaySearch.Map,
+			SinceMs:    ev.ReplaySearch.SinceMs,
+			UntilMs:    ev.ReplaySearch.UntilMs,
+			Limit:      ev.ReplaySearch.Limit,
+		}, nil
+	case server.EventMapUpload:
+		return &gamev1.MapUploadRequest{Name: ev.MapUpload.Name, MapJson: ev.MapUpload.MapJSON}, nil
+	case server.EventDesyncReport:
+		checksum := func(c core.StateChecksum) *gamev1.StateChecksum {
+			if c == (core.StateChecksum{}) {
+				return nil // 服务器不区分缺失与全零的校验和
+			}
+			return protocol.CoreChecksumToProto(c)
+		}
+		return &gamev1.DesyncReport{
+			FrameId: ev.DesyncReport.FrameID,
+			Server:  checksum(ev.DesyncReport.Server),
+			Client:  checksum(ev.DesyncReport.Client),
+		}, nil
+	case server.EventProfileUpdate:
+		return &gamev1.ProfileUpdateRequest{AccountKey: ev.Profile.AccountKey, Profile: ev.Profile.Profile}, nil
+	}
+	return nil, fmt.Errorf("服务器未处理该消息类型")
+}
+
+// decodeWithClient 用客户端的解析器解析数据包
+func decodeWithClient(data []byte) (proto.Message, error) {
+	return client.DecodeServerPacket(data)
+}
@@ -0,0 +1,101 @@
+package conformance
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// maxFillDepth 嵌套消息的最大填充深度（防止递归消息无限展开）
+const maxFillDepth = 4
+
+// fillMessage 按字段定义确定性地填充消息的每个字段
+// oneof 只填充 pick 指定的成员（为空时填充第一个成员），重复字段填充 2 个元素，map 填充 1 个条目
+func fillMessage(m protoreflect.Message, pick protoreflect.FieldDescriptor, depth int) {
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			chosen := oneof.Fields().Get(0)
+			if pick != nil && pick.ContainingOneof() == oneof {
+				chosen = pick
+			}
+			if fd != chosen {
+				continue
+			}
+		}
+		fillField(m, fd, depth)
+	}
+}
+
+func fillField(m protoreflect.Message, fd protoreflect.FieldDescriptor, depth int) {
+	switch {
+	case fd.IsMap():
+		mp := m.Mutable(fd).Map()
+		key := scalarValue(fd.MapKey(), 0).MapKey()
+		if fd.MapValue().Message() != nil {
+			if depth >= maxFillDepth {
+				return
+			}
+			val := mp.NewValue()
+			fillMessage(val.Message(), nil, depth+1)
+			mp.Set(key, val)
+			return
+		}
+		mp.Set(key, scalarValue(fd.MapValue(), 0))
+
+	case fd.IsList():
+		list := m.Mutable(fd).List()
+		for i := 0; i < 2; i++ {
+			if fd.Message() != nil {
+				if depth >= maxFillDepth {
+					return
+				}
+				elem := list.NewElement()
+				fillMessage(elem.Message(), nil, depth+1)
+				list.Append(elem)
+				continue
+			}
+			list.Append(scalarValue(fd, i))
+		}
+
+	case fd.Message() != nil:
+		if depth >= maxFillDepth {
+			return
+		}
+		fillMessage(m.Mutable(fd).Message(), nil, depth+1)
+
+	default:
+		m.Set(fd, scalarValue(fd, 0))
+	}
+}
+
+// scalarValue 标量字段的确定性非零值：由字段编号和元素下标决定，
+// 整数使用多字节 varint 范围，枚举使用最后一个定义值
+func scalarValue(fd protoreflect.FieldDescriptor, index int) protoreflect.Value {
+	n := int64(fd.Number())*10 + int64(index) + 1
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return protoreflect.ValueOfBool(true)
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		return protoreflect.ValueOfEnum(values.Get(values.Len() - 1).Number())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return protoreflect.ValueOfInt32(int32(n * 1000))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return protoreflect.ValueOfInt64(n * 1_000_000_007)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return protoreflect.ValueOfUint32(uint32(n * 1000))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return protoreflect.ValueOfUint64(uint64(n * 1_000_000_007))
+	case protoreflect.FloatKind:
+		return protoreflect.ValueOfFloat32(float32(n) + 0.5)
+	case protoreflect.DoubleKind:
+		return protoreflect.ValueOfFloat64(float64(n) + 0.25)
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(fmt.Sprintf("%s-%d", fd.Name(), index+1))
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte{byte(n), byte(n >> 8), 0xff})
+	}
+	panic(fmt.Sprintf("未处理的字段类型 %v (%s)", fd.Kind(), fd.FullName()))
+}
@@ -0,0 +1,324 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bomberman/pkg/core"
+
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// adminObserverIDBase 运维观察者 ID 从 -1 开始递减，与玩家/观战者 ID 区分
+const adminObserverIDBase = -1
+
+const adminConsoleHelp = `可用命令:
+  rooms                    列出所有房间
+  watch <room> [秒]        挂载观察者，每隔指定秒数（默认 1）输出房间摘要
+  unwatch <room>           卸载观察者
+  status <room>            输出房间当前摘要
+  dump <room> <file>       将完整游戏状态（JSON）写入文件
+  stats                    AI 与真人对局胜负统计（按地图、难度）
+  maps                     列出待审核和已通过的社区地图
+  approve <map>            审核通过，之后房主可以在房间中选择
+  reject <map>             拒绝并删除待审核的地图
+  remove <map>             下架已通过的地图
+  reports [条数]           列出最近的玩家举报（默认 20 条）
+  report <id>              查看一条举报的完整记录
+  listeners                列出监听器及其连接数
+  stop-listener <name>     停止一个监听器（不再接受新连接，已有连接不受影响）
+  help                     显示帮助`
+
+// AdminConsole 运维控制台：逐行读取命令，用于排查线上房间
+// 只能在单个 goroutine 中使用
+type AdminConsole struct {
+	server   *GameServer
+	out      io.Writer
+	watchers map[string]*HeadlessSpectator // roomID -> 观察者
+	nextID   int32
+}
+
+// NewAdminConsole 创建运维控制台，命令输出写入 out
+func NewAdminConsole(server *GameServer, out io.Writer) *AdminConsole {
+	return &AdminConsole{
+		server:   server,
+		out:      out,
+		watchers: make(map[string]*HeadlessSpectator),
+		nextID:   adminObserverIDBase,
+	}
+}
+
+// Run 逐行执行命令直到输入结束
+func (c *AdminConsole) Run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := c.Exec(line); err != nil {
+			fmt.Fprintf(c.out, "错误: %v\n", err)
+		}
+	}
+}
+
+// Exec 执行一条命令
+func (c *AdminConsole) Exec(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	if c.server.roomManager == nil {
+		return fmt.Errorf("服务器未启动")
+	}
+
+	args := fields[1:]
+	switch fields[0] {
+	case "help":
+		fmt.Fprintln(c.out, adminConsoleHelp)
+		return nil
+	case "rooms":
+		return c.listRooms()
+	case "watch":
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("用法: watch <room> [秒]")
+		}
+		seconds := 1.0
+		if len(args) == 2 {
+			v, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || v <= 0 {
+				return fmt.Errorf("无效的间隔 %q", args[1])
+			}
+			seconds = v
+		}
+		return c.watch(args[0], int32(core.SecondsToFrames(seconds)))
+	case "unwatch":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: unwatch <room>")
+		}
+		return c.unwatch(args[0])
+	case "status":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: status <room>")
+		}
+		state, err := c.server.roomManager.SnapshotRoom(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(c.out, "[%s] %s\n", args[0], SummarizeGameState(state))
+		return nil
+	case "dump":
+		if len(args) != 2 {
+			return fmt.Errorf("用法: dump <room> <file>")
+		}
+		return c.dump(args[0], args[1])
+	case "stats":
+		return c.printStats()
+	case "maps":
+		return c.listMaps()
+	case "reports":
+		if len(args) > 1 {
+			return fmt.Errorf("用法: reports [条数]")
+		}
+		limit := 0
+		if len(args) == 1 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil || v <= 0 {
+				return fmt.Errorf("无效的条数 %q", args[0])
+			}
+			limit = v
+		}
+		return c.listReports(limit)
+	case "report":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: report <id>")
+		}
+		return c.showReport(args[0])
+	case "listeners":
+		c.listListeners()
+		return nil
+	case "stop-listener":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: stop-listener <name>")
+		}
+		return c.server.StopListener(args[0])
+	case "approve", "reject", "remove":
+		if len(args) != 1 {
+			return fmt.Errorf("用法: %s <map>", fields[0])
+		}
+		return c.moderateMap(fields[0], args[0])
+	}
+	return fmt.Errorf("未知命令 %q（输入 help 查看帮助）", fields[0])
+}
+
+func (c *AdminConsole) listRooms() error {
+	stats := c.server.roomManager.GetRoomStats()
+	ids := make([]string, 0, len(stats))
+	for id := range stats {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	fmt.Fprintf(c.out, "共 %d 个房间\n", len(ids))
+	for _, id := range ids {
+		s := stats[id]
+		watching := ""
+		if w, ok := c.watchers[id]; ok && !w.Closed() {
+			watching = " (观察中)"
+		}
+		fmt.Fprintf(c.out, "  %s 玩家 %d 状态 %d 帧 %d%s\n", id, s.PlayerCount, s.State, s.FrameID, watching)
+	}
+	return nil
+}
+
+func (c *AdminConsole) printStats() error {
+	stats := c.server.MatchStats()
+	if stats == nil {
+		return fmt.Errorf("对局统计不可用")
+	}
+	entries := stats.Entries()
+	if len(entries) == 0 {
+		fmt.Fprintln(c.out, "暂无 AI 与真人的对局")
+		return nil
+	}
+	fmt.Fprintf(c.out, "  %-16s %-8s %6s %6s %6s %6s %8s\n", "地图", "难度", "局数", "AI胜", "真人胜", "平局", "AI胜率")
+	for _, e := range entries {
+		fmt.Fprintf(c.out, "  %-16s %-8s %6d %6d %6d %6d %7.1f%%\n", e.Map, e.Difficulty, e.Games, e.AIWins, e.HumanWins, e.Draws, e.AIWinRate()*100)
+	}
+	return nil
+}
+
+func (c *AdminConsole) listMaps() error {
+	if c.server.maps == nil {
+		return fmt.Errorf("服务器未开启社区地图（-maps-dir）")
+	}
+	pending := c.server.maps.Pending()
+	fmt.Fprintf(c.out, "待审核 %d 张\n", len(pending))
+	for _, name := range pending {
+		fmt.Fprintf(c.out, "  %s\n", name)
+	}
+	approved := c.server.maps.Names()
+	fmt.Fprintf(c.out, "已通过 %d 张\n", len(approved))
+	for _, name := range approved {
+		fmt.Fprintf(c.out, "  %s\n", name)
+	}
+	return nil
+}
+
+func (c *AdminConsole) moderateMap(op, name string) error {
+	var err error
+	switch op {
+	case "approve":
+		err = c.server.maps.Approve(name)
+	case "reject":
+		err = c.server.maps.Reject(name)
+	case "remove":
+		err = c.server.maps.Remove(name)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "地图 %s: %s 完成\n", name, op)
+	return nil
+}
+
+func (c *AdminConsole) listReports(limit int) error {
+	if c.server.reports == nil {
+		return fmt.Errorf("服务器未开启玩家举报（-reports-dir）")
+	}
+	reports := c.server.reports.List(limit)
+	if len(reports) == 0 {
+		fmt.Fprintln(c.out, "暂无举报")
+		return nil
+	}
+	for _, r := range reports {
+		late := "未同意收集"
+		if r.Observed {
+			late = fmt.Sprintf("迟到输入 %d", r.LateInputs)
+		}
+		fmt.Fprintf(c.out, "  %-22s %s %-12s %s -> %s  %s  %s\n", r.ID, r.CreatedAt.Format("01-02 15:04"), r.RoomID, r.Reporter, r.Target, late, r.Reason)
+	}
+	return nil
+}
+
+func (c *AdminConsole) showReport(id string) error {
+	if c.server.reports == nil {
+		return fmt.Errorf("服务器未开启玩家举报（-reports-dir）")
+	}
+	report, err := c.server.reports.Get(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out, string(data))
+	return nil
+}
+
+func (c *AdminConsole) watch(roomID string, summaryFrames int32) error {
+	if w, ok := c.watchers[roomID]; ok {
+		if !w.Closed() {
+			return fmt.Errorf("房间 %s 已在观察中", roomID)
+		}
+		delete(c.watchers, roomID)
+	}
+
+	observer := NewHeadlessSpectator(c.nextID, summaryFrames)
+	if err := c.server.roomManager.AttachObserver(roomID, observer); err != nil {
+		return err
+	}
+	c.nextID--
+	c.watchers[roomID] = observer
+	fmt.Fprintf(c.out, "开始观察房间 %s\n", roomID)
+	return nil
+}
+
+func (c *AdminConsole) unwatch(roomID string) error {
+	observer, ok := c.watchers[roomID]
+	if !ok {
+		return fmt.Errorf("房间 %s 未在观察中", roomID)
+	}
+	delete(c.watchers, roomID)
+	if observer.Closed() {
+		return nil
+	}
+	if err := c.server.roomManager.DetachObserver(roomID, observer); err != nil {
+		return err
+	}
+	observer.Close()
+	return nil
+}
+
+func (c *AdminConsole) dump(roomID, path string) error {
+	state, err := c.server.roomManager.SnapshotRoom(roomID)
+	if err != nil {
+		return err
+	}
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("序列化游戏状态失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("写入文件失败: %w", err)
+	}
+	fmt.Fprintf(c.out, "房间 %s 帧 %d 的状态已写入 %s\n", roomID, state.FrameId, path)
+	return nil
+}
+
+func (c *AdminConsole) listListeners() {
+	for _, l := range c.server.ListenerStats() {
+		state := "运行中"
+		if l.Stopped {
+			state = "已停止"
+		}
+		fmt.Fprintf(c.out, "%-16s %-4s %-16s %s  当前连接 %d  累计 %d\n", l.Name, l.Proto, l.Addr, state, l.Active, l.Accepted)
+	}
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/ai"
+)
+
+// aiNames AI 玩家的候选名字（同一房间内不重复）
+var aiNames = []string{
+	"Bomba", "Fuse", "Sparky", "Kaboom", "Dynamo", "Blaze",
+	"Cinder", "Flint", "Nitro", "Ember", "Boomer", "Pyro",
+}
+
+// pickAIName 随机挑选一个房间内未被使用的名字，全部用完时退回 AI-<id>
+func (r *Room) pickAIName(playerID int32) string {
+	used := make(map[string]bool, len(r.playerNames))
+	for _, name := range r.playerNames {
+		used[name] = true
+	}
+	for _, i := range rand.Perm(len(aiNames)) {
+		if !used[aiNames[i]] {
+			return aiNames[i]
+		}
+	}
+	return fmt.Sprintf("AI-%d", playerID)
+}
+
+func aiDifficultyToProto(d ai.Difficulty) gamev1.AIDifficulty {
+	switch d {
+	case ai.DifficultyEasy:
+		return gamev1.AIDifficulty_AI_DIFFICULTY_EASY
+	case ai.DifficultyNormal:
+		return gamev1.AIDifficulty_AI_DIFFICULTY_NORMAL
+	case ai.DifficultyHard:
+		return gamev1.AIDifficulty_AI_DIFFICULTY_HARD
+	}
+	return gamev1.AIDifficulty_AI_DIFFICULTY_UNSPECIFIED
+}
+
+// aiDifficultyFromProto 未指定或未知的难度按 Normal 处理
+func aiDifficultyFromProto(d gamev1.AIDifficulty) ai.Difficulty {
+	switch d {
+	case gamev1.AIDifficulty_AI_DIFFICULTY_EASY:
+		return ai.DifficultyEasy
+	case gamev1.AIDifficulty_AI_DIFFICULTY_HARD:
+		return ai.DifficultyHard
+	}
+	return ai.DifficultyNormal
+}
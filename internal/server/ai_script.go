@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/ai"
+)
+
+// MaxAIScriptBytes 脚本 AI 源码的大小上限
+const MaxAIScriptBytes = 16 << 10
+
+// parseAIScript 解析房主随 ADD_AI 提交的脚本，空源码表示添加普通 AI
+// 脚本 AI 只在调试房间（-debug-scenarios）可用
+func (r *Room) parseAIScript(src string) (*ai.Script, error) {
+	if src == "" {
+		return nil, nil
+	}
+	if !r.scenariosEnabled {
+		return nil, newRoomError(gamev1.ErrorCode_ERROR_CODE_DEBUG_DISABLED, "房间 %s 未开启调试功能，不能添加脚本 AI", r.id)
+	}
+	if len(src) > MaxAIScriptBytes {
+		return nil, newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_INVALID_SCRIPT, []string{"0"}, "脚本过大 (%d > %d 字节)", len(src), MaxAIScriptBytes)
+	}
+
+	script, err := ai.ParseScript(src)
+	if err != nil {
+		line := 0
+		var scriptErr *ai.ScriptError
+		if errors.As(err, &scriptErr) {
+			line = scriptErr.Line
+		}
+		return nil, newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_INVALID_SCRIPT, []string{strconv.Itoa(line)}, "解析 AI 脚本失败: %v", err)
+	}
+	return script, nil
+}
+
+// loadAITree 读取 AI 行为树 JSON 定义，失败时记录日志并返回 nil（使用内置行为树）
+func loadAITree(path string) ai.Node {
+	def, err := ai.LoadTreeDef(path)
+	if err != nil {
+		log.Printf("加载 AI 行为树 %s 失败，使用内置行为树: %v", path, err)
+		return nil
+	}
+	tree, err := def.Build()
+	if err != nil {
+		log.Printf("构建 AI 行为树 %s 失败，使用内置行为树: %v", path, err)
+		return nil
+	}
+	log.Printf("已加载 AI 行为树: %s", path)
+	return tree
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"log"
+	"math/rand"
+
+	"bomberman/pkg/core"
+)
+
+// 首领控制器：首领战（RoomRules.boss_mode）中决定首领何时移动、朝哪里攻击，规则本身在 core/boss.go。
+// 首领追向最近的存活玩家，阶段越高移动和攻击越频繁；从第二阶段起玩家与首领同行（列）时改为喷火
+
+// 各阶段的移动间隔和攻击间隔（帧），下标为阶段 - 1
+var (
+	bossMoveFrames   = [core.BossStages]int32{30, 20, 12}
+	bossAttackFrames = [core.BossStages]int32{4 * core.TPS, 3 * core.TPS, 2 * core.TPS}
+)
+
+// bossFirstAttackFrames 开局后首领第一次攻击前的帧数（给玩家离开出生点的时间）
+const bossFirstAttackFrames = 3 * core.TPS
+
+type bossController struct {
+	rng             *rand.Rand // 走不通时随机换方向（按对局种子，录像可复现）
+	nextMoveFrame   int32
+	nextAttackFrame int32
+	stage           int // 上一次看到的阶段，用于记录阶段变化
+}
+
+func newBossController(seed int64, frame int32) *bossController {
+	return &bossController{
+		rng:             rand.New(rand.NewSource(seed)),
+		nextMoveFrame:   frame + bossMoveFrames[0],
+		nextAttackFrame: frame + bossFirstAttackFrames,
+		stage:           1,
+	}
+}
+
+// spawnBoss 首领战开局时放出首领（地图上没有能放下首领的位置时退化为普通对局）
+func (r *Room) spawnBoss() {
+	r.boss = nil
+	r.bossTileChanges = nil
+	if !r.rules.BossMode {
+		return
+	}
+	r.bossTileChanges = r.game.SpawnBoss()
+	if r.game.Boss == nil {
+		log.Printf("房间 %s 地图中没有能放下首领的位置，首领战未开启", r.id)
+		return
+	}
+	r.boss = newBossController(r.seed, r.frameID)
+	log.Printf("房间 %s 首领出现在 (%d,%d)", r.id, r.game.Boss.GridX, r.game.Boss.GridY)
+}
+
+// updateBoss 每帧在核心逻辑更新前决定首领的行动
+func (r *Room) updateBoss() {
+	b := r.game.Boss
+	if r.boss == nil || b == nil || b.Defeated() {
+		return
+	}
+	bc := r.boss
+	stage := b.Stage()
+	if stage != bc.stage {
+		bc.stage = stage
+		log.Printf("房间 %s 首领进入第 %d 阶段（剩余 %d/%d）", r.id, stage, b.HP, b.MaxHP)
+	}
+
+	target := r.bossTarget()
+	if target == nil || b.Attack != core.BossAttackNone {
+		return
+	}
+	cell := core.PlayerXYToGrid(int(target.X), int(target.Y))
+
+	if r.frameID >= bc.nextAttackFrame {
+		dir, aligned := bossDirectionTo(b, cell)
+		attack := core.BossAttackBombs
+		if aligned && stage >= 2 {
+			attack = core.BossAttackFire
+		}
+		r.game.ChargeBossAttack(attack, dir)
+		bc.nextAttackFrame = r.frameID + core.BossWarnFrames + bossAttackFrames[stage-1]
+		return
+	}
+
+	if r.frameID >= bc.nextMoveFrame {
+		bc.nextMoveFrame = r.frameID + bossMoveFrames[stage-1]
+		dir, _ := bossDirectionTo(b, cell)
+		if r.moveBoss(dir) {
+			return
+		}
+		// 正面被炸弹挡住：随机换一个方向试试
+		for _, i := range bc.rng.Perm(4) {
+			if r.moveBoss(core.DirectionType(i)) {
+				return
+			}
+		}
+	}
+}
+
+// moveBoss 移动首领，压碎的砖块随下一次状态广播下发
+func (r *Room) moveBoss(dir core.DirectionType) bool {
+	changes, moved := r.game.MoveBoss(dir)
+	r.bossTileChanges = append(r.bossTileChanges, changes...)
+	return moved
+}
+
+// bossTarget 离首领最近的存活玩家
+func (r *Room) bossTarget() *core.Player {
+	b := r.game.Boss
+	var target *core.Player
+	best := 0
+	for _, p := range r.game.GetAlivePlayers() {
+		cell := core.PlayerXYToGrid(int(p.X), int(p.Y))
+		dist := abs(cell.GridX-b.GridX) + abs(cell.GridY-b.GridY)
+		if target == nil || dist < best {
+			target, best = p, dist
+		}
+	}
+	return target
+}
+
+// bossDirectionTo 首领朝向 cell 的方向（优先相差较远的轴），aligned 表示 cell 在首领的行或列范围内（喷火打得到）
+func bossDirectionTo(b *core.Boss, cell core.GridPos) (dir core.DirectionType, aligned bool) {
+	var dx, dy int
+	switch {
+	case cell.GridX < b.GridX:
+		dx = cell.GridX - b.GridX
+	case cell.GridX >= b.GridX+core.BossSize:
+		dx = cell.GridX - (b.GridX + core.BossSize - 1)
+	}
+	switch {
+	case cell.GridY < b.GridY:
+		dy = cell.GridY - b.GridY
+	case cell.GridY >= b.GridY+core.BossSize:
+		dy = cell.GridY - (b.GridY + core.BossSize - 1)
+	}
+	aligned = dx == 0 || dy == 0
+
+	if abs(dx) >= abs(dy) && dx != 0 {
+		if dx < 0 {
+			return core.DirLeft, aligned
+		}
+		return core.DirRight, aligned
+	}
+	if dy < 0 {
+		return core.DirUp, aligned
+	}
+	return core.DirDown, aligned
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"bomberman/pkg/protocol"
+)
+
+// recorderSessionID 录制器在观察者表中的 ID（与控制台观察者的 -1, -2, ... 区分）
+const recorderSessionID int32 = -1 << 30
+
+// BroadcastRecorder 将房间的全部出站广播写入压缩录制文件（实现 Session，作为观察者挂载）
+// 录制文件可用 cmd/replayconv 转换为回放
+type BroadcastRecorder struct {
+	roomID string
+	path   string
+	file   *os.File
+	writer *protocol.RecordingWriter
+	failed bool
+}
+
+// NewBroadcastRecorder 在 dir 下创建 <房间ID>-<时间>.rec.gz
+func NewBroadcastRecorder(dir, roomID string) (*BroadcastRecorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建录制目录失败: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.rec.gz", roomID, time.Now().Format("20060102-150405"))
+	path := filepath.Join(dir, name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建录制文件失败: %w", err)
+	}
+	writer, err := protocol.NewRecordingWriter(file, roomID)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("写入录制文件头失败: %w", err)
+	}
+	return &BroadcastRecorder{roomID: roomID, path: path, file: file, writer: writer}, nil
+}
+
+func (b *BroadcastRecorder) ID() int32               { return recorderSessionID }
+func (b *BroadcastRecorder) GetRoomID() string       { return b.roomID }
+func (b *BroadcastRecorder) SetRoomID(roomID string) {}
+func (b *BroadcastRecorder) SetPlayerID(id int32)    {}
+
+// Send 在房间 goroutine 中调用；写入失败后停止录制，不影响房间运行
+func (b *BroadcastRecorder) Send(data []byte) error {
+	if b.failed || b.writer == nil {
+		return nil
+	}
+	if err := b.writer.Write(time.Now(), data); err != nil {
+		b.failed = true
+		log.Printf("房间 %s 录制写入失败，停止录制: %v", b.roomID, err)
+	}
+	return nil
+}
+
+func (b *BroadcastRecorder) Close() {
+	b.CloseWithoutNotify()
+}
+
+// CloseWithoutNotify 刷新并关闭录制文件（房间关闭时调用）
+func (b *BroadcastRecorder) CloseWithoutNotify() {
+	if b.writer == nil {
+		return
+	}
+	if err := b.writer.Close(); err != nil {
+		log.Printf("房间 %s 关闭录制失败: %v", b.roomID, err)
+	}
+	b.file.Close()
+	b.writer = nil
+	log.Printf("房间 %s 录制已保存: %s", b.roomID, b.path)
+}
+
+// startRecording 挂载录制器（需在房间 goroutine 启动前调用）
+func (r *Room) startRecording(dir string) {
+	recorder, err := NewBroadcastRecorder(dir, r.id)
+	if err != nil {
+		log.Printf("房间 %s 无法开始录制: %v", r.id, err)
+		return
+	}
+	r.observers[recorder.ID()] = recorder
+	r.recorder = recorder
+	log.Printf("房间 %s 开始录制广播: %s", r.id, recorder.path)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 网络故障注入（仅用于测试）：每个连接的出站数据包按故障参数随机延迟、重复、乱序或丢弃，
+// 用来在集成测试中确认客户端不依赖按序、恰好一次的送达，并能从丢包中恢复。
+// 每个连接的随机数种子为 Seed 加连接序号，同样的参数和连接顺序得到同样的故障序列
+
+// chaosReorderHold 乱序的包额外推迟的时间，让之后的包先送达
+const chaosReorderHold = 150 * time.Millisecond
+
+// ChaosProfile 出站故障参数
+type ChaosProfile struct {
+	Seed      int64
+	Delay     time.Duration // 固定延迟
+	Jitter    time.Duration // 额外的随机延迟 [0, Jitter)
+	Drop      float64       // 丢弃概率
+	Duplicate float64       // 重复发送概率（副本单独计算延迟）
+	Reorder   float64       // 乱序概率（额外推迟 chaosReorderHold）
+}
+
+// ParseChaosProfile 解析 "seed=7,delay=30ms,jitter=80ms,drop=0.05,dup=0.02,reorder=0.1"，省略的字段为 0
+func ParseChaosProfile(spec string) (ChaosProfile, error) {
+	var p ChaosProfile
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return p, fmt.Errorf("故障参数 %q 缺少 =", field)
+		}
+		var err error
+		switch key {
+		case "seed":
+			p.Seed, err = strconv.ParseInt(value, 10, 64)
+		case "delay":
+			p.Delay, err = time.ParseDuration(value)
+		case "jitter":
+			p.Jitter, err = time.ParseDuration(value)
+		case "drop":
+			p.Drop, err = parseChance(value)
+		case "dup":
+			p.Duplicate, err = parseChance(value)
+		case "reorder":
+			p.Reorder, err = parseChance(value)
+		default:
+			return p, fmt.Errorf("未知的故障参数 %q（可用 seed、delay、jitter、drop、dup、reorder）", key)
+		}
+		if err != nil {
+			return p, fmt.Errorf("故障参数 %s 无效: %w", key, err)
+		}
+	}
+	if p.Delay < 0 || p.Jitter < 0 {
+		return p, fmt.Errorf("延迟不能为负数")
+	}
+	return p, nil
+}
+
+func parseChance(value string) (float64, error) {
+	chance, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if chance < 0 || chance > 1 {
+		return 0, fmt.Errorf("概率必须在 0-1 之间")
+	}
+	return chance, nil
+}
+
+func (p ChaosProfile) String() string {
+	return fmt.Sprintf("seed=%d,delay=%s,jitter=%s,drop=%g,dup=%g,reorder=%g",
+		p.Seed, p.Delay, p.Jitter, p.Drop, p.Duplicate, p.Reorder)
+}
+
+// chaosPacket 等待发出的包
+type chaosPacket struct {
+	data []byte
+	due  time.Time
+}
+
+// chaosQueue 一个连接的出站故障队列（只在该连接的发送循环中访问）
+type chaosQueue struct {
+	profile ChaosProfile
+	rng     *rand.Rand
+	pending []chaosPacket // 按 due 排序，同一时刻到期的包保持进入顺序
+	timer   *time.Timer
+}
+
+func newChaosQueue(profile ChaosProfile, connIndex int64) *chaosQueue {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	return &chaosQueue{
+		profile: profile,
+		rng:     rand.New(rand.NewSource(profile.Seed + connIndex)),
+		timer:   timer,
+	}
+}
+
+// push 按故障参数安排一个出站包（可能丢弃或安排两次），返回是否被丢弃
+func (q *chaosQueue) push(data []byte, now time.Time) bool {
+	if q.rng.Float64() < q.profile.Drop {
+		return true
+	}
+	q.schedule(data, now)
+	if q.rng.Float64() < q.profile.Duplicate {
+		q.schedule(data, now)
+	}
+	return false
+}
+
+func (q *chaosQueue) schedule(data []byte, now time.Time) {
+	delay := q.profile.Delay
+	if q.profile.Jitter > 0 {
+		delay += time.Duration(q.rng.Int63n(int64(q.profile.Jitter)))
+	}
+	if q.rng.Float64() < q.profile.Reorder {
+		delay += chaosReorderHold
+	}
+	pkt := chaosPacket{data: data, due: now.Add(delay)}
+	i := sort.Search(len(q.pending), func(i int) bool { return q.pending[i].due.After(pkt.due) })
+	q.pending = append(q.pending, chaosPacket{})
+	copy(q.pending[i+1:], q.pending[i:])
+	q.pending[i] = pkt
+	q.resetTimer(now)
+}
+
+// due 取出到期的包（按到期时间顺序），并为下一个包重新设置定时器
+func (q *chaosQueue) due(now time.Time) [][]byte {
+	n := 0
+	for n < len(q.pending) && !q.pending[n].due.After(now) {
+		n++
+	}
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = q.pending[i].data
+	}
+	q.pending = append(q.pending[:0], q.pending[n:]...)
+	q.resetTimer(now)
+	return out
+}
+
+func (q *chaosQueue) resetTimer(now time.Time) {
+	q.timer.Stop()
+	if len(q.pending) > 0 {
+		q.timer.Reset(q.pending[0].due.Sub(now))
+	}
+}
@@ -23,9 +23,19 @@ func DecodePacket(data []byte) (*ServerEvent, error) {
 		return &ServerEvent{
 			Kind: EventJoin,
 			Join: &JoinEvent{
+				RequestID:  req.RequestId,
 				PlayerName: req.PlayerName,
 				Character:  req.Character,
 				RoomID:     req.RoomId,
+				Spectate:   req.Spectate,
+				TakeOverAI: req.TakeOverAi,
+
+				ProtocolVersion: req.ProtocolVersion,
+				ClientVersion:   req.ClientVersion,
+
+				AccountKey: req.AccountKey,
+
+				TelemetryOptIn: req.TelemetryOptIn,
 			},
 		}, nil
 
@@ -43,13 +53,17 @@ func DecodePacket(data []byte) (*ServerEvent, error) {
 				Left:    in.Left,
 				Right:   in.Right,
 				Bomb:    in.Bomb,
+				Shove:   in.Shove,
+				Sprint:  in.Sprint,
 			})
 		}
 		return &ServerEvent{
 			Kind: EventInput,
 			Input: &InputEvent{
-				Seq:    input.Seq,
-				Inputs: items,
+				Seq:           input.Seq,
+				Inputs:        items,
+				AckStateFrame: input.AckStateFrame,
+				AckEventSeqs:  input.AckEventSeqs,
 			},
 		}, nil
 
@@ -80,7 +94,7 @@ func DecodePacket(data []byte) (*ServerEvent, error) {
 		}
 		return &ServerEvent{
 			Kind:      EventReconnect,
-			Reconnect: &ReconnectEvent{SessionToken: req.SessionToken},
+			Reconnect: &ReconnectEvent{RequestID: req.RequestId, SessionToken: req.SessionToken},
 		}, nil
 
 	case gamev1.MessageType_MESSAGE_TYPE_ROOM_LIST_REQUEST:
@@ -91,8 +105,12 @@ func DecodePacket(data []byte) (*ServerEvent, error) {
 		return &ServerEvent{
 			Kind: EventRoomList,
 			RoomList: &RoomListEvent{
-				Page:     req.Page,
-				PageSize: req.PageSize,
+				RequestID:   req.RequestId,
+				Page:        req.Page,
+				PageSize:    req.PageSize,
+				WaitingOnly: req.WaitingOnly,
+				NotFull:     req.NotFull,
+				HasAI:       req.HasAi,
 			},
 		}, nil
 
@@ -108,6 +126,72 @@ func DecodePacket(data []byte) (*ServerEvent, error) {
 			},
 		}, nil
 
+	case gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_REQUEST:
+		req, err := protocol.ParseServerStatusRequest(pkt)
+		if err != nil {
+			return nil, err
+		}
+		return &ServerEvent{
+			Kind:   EventServerStatus,
+			Status: &ServerStatusEvent{ClientTime: req.ClientTime},
+		}, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_REQUEST:
+		req, err := protocol.ParseReplaySearchRequest(pkt)
+		if err != nil {
+			return nil, err
+		}
+		return &ServerEvent{
+			Kind: EventReplaySearch,
+			ReplaySearch: &ReplaySearchEvent{
+				PlayerName: req.PlayerName,
+				Map:        req.Map,
+				SinceMs:    req.SinceMs,
+				UntilMs:    req.UntilMs,
+				Limit:      req.Limit,
+			},
+		}, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_REQUEST:
+		req, err := protocol.ParseMapUploadRequest(pkt)
+		if err != nil {
+			return nil, err
+		}
+		return &ServerEvent{
+			Kind: EventMapUpload,
+			MapUpload: &MapUploadEvent{
+				Name:    req.Name,
+				MapJSON: req.MapJson,
+			},
+		}, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_DESYNC_REPORT:
+		report, err := protocol.ParseDesyncReport(pkt)
+		if err != nil {
+			return nil, err
+		}
+		return &ServerEvent{
+			Kind: EventDesyncReport,
+			DesyncReport: &DesyncReportEvent{
+				FrameID: report.FrameId,
+				Server:  protocol.ProtoChecksumToCore(report.Server),
+				Client:  protocol.ProtoChecksumToCore(report.Client),
+			},
+		}, nil
+
+	case gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_REQUEST:
+		req, err := protocol.ParseProfileUpdateRequest(pkt)
+		if err != nil {
+			return nil, err
+		}
+		return &ServerEvent{
+			Kind: EventProfileUpdate,
+			Profile: &ProfileUpdateEvent{
+				AccountKey: req.AccountKey,
+				Profile:    req.Profile,
+			},
+		}, nil
+
 	default:
 		return &ServerEvent{Kind: EventUnknown}, nil
 	}
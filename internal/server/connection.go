@@ -2,7 +2,6 @@ package server
 
 import (
 	"context"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -12,6 +11,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/protocol"
+
 	"golang.org/x/time/rate"
 )
 
@@ -38,6 +40,8 @@ type Connection struct {
 	playerID int32
 	roomID   string // 玩家所属的房间 ID
 
+	listener *managedListener // 接受该连接的监听器（统计每个监听器的连接数）
+
 	// 发送队列
 	sendChan chan []byte
 	closeCh  chan struct{}
@@ -46,8 +50,15 @@ type Connection struct {
 
 	lastRecvTime atomic.Value
 
+	// 大厅空闲检测：只有主动操作（加入、房间操作、输入、重连）才算活跃，
+	// Ping 与房间列表轮询不算
+	lastActivityTime atomic.Value
+	idleWarned       atomic.Bool
+
 	// 全局消息限流器（所有消息类型共享）
 	rateLimiter *rate.Limiter
+
+	chaos *chaosQueue // 出站故障注入（chaos.go，nil 表示不注入）
 }
 
 // NewConnection 创建新连接，连接到服务器上
@@ -62,6 +73,10 @@ func NewConnection(conn net.Conn, server *GameServer) *Connection {
 		rateLimiter: rate.NewLimiter(globalMessageRateLimit, globalMessageBurst),
 	}
 	c.lastRecvTime.Store(time.Now())
+	c.lastActivityTime.Store(time.Now())
+	if server != nil && server.chaos != nil {
+		c.chaos = newChaosQueue(*server.chaos, server.chaosConns.Add(1))
+	}
 	return c
 }
 
@@ -70,6 +85,12 @@ func (c *Connection) Handle(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	log.Printf("玩家 %d: 连接处理开始", c.getPlayerID())
+	c.server.metrics.connections.Add(1)
+	defer c.server.metrics.connections.Add(-1)
+	if c.listener != nil {
+		c.listener.active.Add(1)
+		defer c.listener.active.Add(-1)
+	}
 
 	wg.Add(1)
 	go c.startHeartbeat(ctx, wg)
@@ -130,6 +151,13 @@ func (c *Connection) closeWithNotify(notify bool) {
 	log.Printf("玩家 %d: 连接已关闭", c.getPlayerID())
 }
 
+// Closed 连接是否已关闭
+func (c *Connection) Closed() bool {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	return c.closed
+}
+
 // Send 发送数据（异步）
 func (c *Connection) Send(data []byte) error {
 	c.closeMu.Lock()
@@ -143,6 +171,9 @@ func (c *Connection) Send(data []byte) error {
 	case c.sendChan <- data:
 		return nil
 	default:
+		if c.server != nil {
+			c.server.metrics.sendQueueFull.Add(1)
+		}
 		return ErrSendQueueFull
 	}
 }
@@ -153,6 +184,13 @@ func (c *Connection) sendLoop(ctx context.Context, wg *sync.WaitGroup) {
 
 	log.Printf("玩家 %d: 发送循环启动", c.getPlayerID())
 
+	// 开启故障注入时，出队的包先进入故障队列，到期后再写出
+	var chaosDue <-chan time.Time
+	if c.chaos != nil {
+		defer c.chaos.timer.Stop()
+		chaosDue = c.chaos.timer.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -164,27 +202,37 @@ func (c *Connection) sendLoop(ctx context.Context, wg *sync.WaitGroup) {
 				// 通道已关闭
 				return
 			}
-
-			// 发送数据长度前缀（4 字节）
-			length := uint32(len(data))
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
-			if err := binary.Write(c.conn, binary.BigEndian, length); err != nil {
-				log.Printf("玩家 %d: 发送长度失败: %v", c.getPlayerID(), err)
-				c.Close()
+			if c.chaos != nil {
+				if c.chaos.push(data, time.Now()) && c.server != nil {
+					c.server.metrics.chaosDropped.Add(1)
+				}
+				continue
+			}
+			if !c.write(data) {
 				return
 			}
 
-			// 发送数据体
-			_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
-			if _, err := c.conn.Write(data); err != nil {
-				log.Printf("玩家 %d: 发送数据失败: %v", c.getPlayerID(), err)
-				c.Close()
-				return
+		case now := <-chaosDue:
+			for _, data := range c.chaos.due(now) {
+				if !c.write(data) {
+					return
+				}
 			}
 		}
 	}
 }
 
+// write 写出一个包，失败时关闭连接并返回 false
+func (c *Connection) write(data []byte) bool {
+	_ = c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := writePacket(c.conn, data); err != nil {
+		log.Printf("玩家 %d: 发送数据失败: %v", c.getPlayerID(), err)
+		c.Close()
+		return false
+	}
+	return true
+}
+
 // receiveLoop 接收循环
 func (c *Connection) receiveLoop(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
@@ -198,44 +246,24 @@ func (c *Connection) receiveLoop(ctx context.Context, wg *sync.WaitGroup) {
 			return
 
 		default:
-			// 读取消息长度（4 字节）
-			var length uint32
+			// 读取一个数据包（TCP/KCP 为长度前缀，WebSocket 为一帧）
 			_ = c.conn.SetReadDeadline(time.Now().Add(readTimeout))
-			if err := binary.Read(c.conn, binary.BigEndian, &length); err != nil {
+			data, err := readPacket(c.conn)
+			if err != nil {
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 					log.Printf("玩家 %d: 读取超时", c.getPlayerID())
 				} else if err != io.EOF {
-					log.Printf("玩家 %d: 读取长度失败: %v", c.getPlayerID(), err)
+					log.Printf("玩家 %d: 读取数据失败: %v", c.getPlayerID(), err)
 				}
 				c.Close()
 				return
 			}
 
-			// 检查消息大小
-			if length > MaxPacketSize {
-				log.Printf("玩家 %d: 消息过大 (%d bytes)", c.getPlayerID(), length)
-				c.Close()
-				return
-			}
-
-			if length == 0 {
+			if len(data) == 0 {
 				log.Printf("玩家 %d: 收到空消息", c.getPlayerID())
 				continue
 			}
 
-			// 读取消息体
-			data := make([]byte, length)
-			_ = c.conn.SetReadDeadline(time.Now().Add(readTimeout))
-			if _, err := io.ReadFull(c.conn, data); err != nil {
-				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-					log.Printf("玩家 %d: 读取超时", c.getPlayerID())
-				} else {
-					log.Printf("玩家 %d: 读取数据失败: %v", c.getPlayerID(), err)
-				}
-				c.Close()
-				return
-			}
-
 			// 处理消息
 			c.onMessageReceived()
 			if err := c.handleMessage(data); err != nil {
@@ -258,6 +286,24 @@ func (c *Connection) handleMessage(data []byte) error {
 		return fmt.Errorf("反序列化失败: %w", err)
 	}
 
+	// 状态查询、地图上传和偏好修改在加入前就可以发送，不分配玩家、不计入大厅活跃
+	if event.Kind == EventServerStatus {
+		c.server.handleServerStatus(c, event.Status)
+		return nil
+	}
+	if event.Kind == EventMapUpload {
+		c.server.handleMapUpload(c, event.MapUpload)
+		return nil
+	}
+	if event.Kind == EventProfileUpdate {
+		c.server.handleProfileUpdate(c, event.Profile)
+		return nil
+	}
+
+	if event.Kind != EventPing && event.Kind != EventRoomList {
+		c.touchActivity()
+	}
+
 	switch event.Kind {
 	case EventJoin:
 		if c.getPlayerID() >= 0 {
@@ -286,6 +332,12 @@ func (c *Connection) handleMessage(data []byte) error {
 	case EventRoomAction:
 		c.server.handleRoomAction(c, event.RoomAction)
 
+	case EventReplaySearch:
+		c.server.handleReplaySearch(c, event.ReplaySearch)
+
+	case EventDesyncReport:
+		c.server.handleDesyncReport(c, event.DesyncReport)
+
 	default:
 		return fmt.Errorf("未知消息类型")
 	}
@@ -349,6 +401,9 @@ func (c *Connection) startHeartbeat(ctx context.Context, wg *sync.WaitGroup) {
 				c.Close()
 				return
 			}
+			if c.checkLobbyIdle() {
+				return
+			}
 		}
 	}
 }
@@ -356,3 +411,57 @@ func (c *Connection) startHeartbeat(ctx context.Context, wg *sync.WaitGroup) {
 func (c *Connection) onMessageReceived() {
 	c.lastRecvTime.Store(time.Now())
 }
+
+func (c *Connection) touchActivity() {
+	c.lastActivityTime.Store(time.Now())
+	c.idleWarned.Store(false)
+}
+
+// checkLobbyIdle 检查大厅空闲，返回 true 表示连接已被关闭
+// 房间内的连接不受限制，并且离开房间后重新开始计时
+func (c *Connection) checkLobbyIdle() bool {
+	timeout := c.server.lobbyIdleTimeout
+	if timeout <= 0 {
+		return false
+	}
+	if c.GetRoomID() != "" {
+		c.touchActivity()
+		return false
+	}
+
+	lastActivity, _ := c.lastActivityTime.Load().(time.Time)
+	idle := time.Since(lastActivity)
+
+	if idle >= timeout {
+		log.Printf("%s: 大厅空闲 %v，断开连接", c, idle.Truncate(time.Second))
+		c.sendNotice(gamev1.NoticeType_NOTICE_TYPE_IDLE_DISCONNECT, "长时间未操作，已断开连接", 0)
+		// 给发送循环一点时间把通知发出去
+		time.AfterFunc(200*time.Millisecond, c.Close)
+		return true
+	}
+
+	warnAt := timeout - lobbyIdleWarnBefore
+	if warnAt < 0 {
+		warnAt = timeout / 2
+	}
+	if idle >= warnAt && !c.idleWarned.Load() {
+		c.idleWarned.Store(true)
+		remaining := int32((timeout - idle).Seconds())
+		c.sendNotice(gamev1.NoticeType_NOTICE_TYPE_IDLE_WARNING, fmt.Sprintf("长时间未操作，%d 秒后将断开连接", remaining), remaining)
+	}
+	return false
+}
+
+func (c *Connection) sendNotice(noticeType gamev1.NoticeType, message string, secondsRemaining int32) {
+	packet, err := protocol.NewServerNoticePacket(noticeType, message, secondsRemaining)
+	if err != nil {
+		log.Printf("构造服务器通知失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化服务器通知失败: %v", err)
+		return
+	}
+	_ = c.Send(data)
+}
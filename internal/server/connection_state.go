@@ -0,0 +1,46 @@
+package server
+
+import (
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 对局中玩家断线进入离线保护时角色停在原地，广播 PlayerConnectionState 让其他玩家知道对方在重连、
+// 还剩多少秒会被移出对局；重连后再广播一次。等待阶段不广播（房间状态已经反映在线情况）
+
+// broadcastConnectionState 广播玩家的连接状态（只在对局中）
+func (r *Room) broadcastConnectionState(playerID int32, state gamev1.PlayerConnectionState) {
+	if r.legacyMode || r.state != StateRunning {
+		return
+	}
+	r.broadcastEvent(r.connectionStateEvent(playerID, state))
+}
+
+// sendOfflineStates 重连的玩家补发其他仍在离线保护中的玩家
+func (r *Room) sendOfflineStates(conn Session) {
+	if r.legacyMode || r.state != StateRunning {
+		return
+	}
+	for playerID := range r.offlinePlayers {
+		if playerID != conn.ID() {
+			r.sendEvent(conn, r.connectionStateEvent(playerID, gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_OFFLINE))
+		}
+	}
+}
+
+func (r *Room) connectionStateEvent(playerID int32, state gamev1.PlayerConnectionState) *gamev1.GameEvent {
+	event := &gamev1.PlayerConnectionStateEvent{PlayerId: playerID, State: state}
+	if state == gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_OFFLINE {
+		event.SecondsRemaining = r.offlineSecondsRemaining(playerID)
+	}
+	return &gamev1.GameEvent{
+		Event: &gamev1.GameEvent_PlayerConnectionState{PlayerConnectionState: event},
+	}
+}
+
+// offlineSecondsRemaining 离线保护剩余秒数（向上取整）
+func (r *Room) offlineSecondsRemaining(playerID int32) int32 {
+	remaining := r.offlineTimeout - time.Since(r.offlinePlayers[playerID])
+	return int32(max(0, (remaining+time.Second-1)/time.Second))
+}
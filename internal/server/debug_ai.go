@@ -0,0 +1,62 @@
+package server
+
+import (
+	"log"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// debugAIIntervalFrames AI 调试信息广播间隔（约 100ms，调试用无需每帧发送）
+const debugAIIntervalFrames = core.TPS / 10
+
+// broadcastDebugAIState 调试房间中广播每个 AI 的行为树节点、目标和规划路径
+func (r *Room) broadcastDebugAIState() {
+	if !r.debugAI || len(r.aiControllers) == 0 || r.frameID%debugAIIntervalFrames != 0 {
+		return
+	}
+
+	ids := make([]int, 0, len(r.aiControllers))
+	for id := range r.aiControllers {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+
+	state := &gamev1.DebugAIState{
+		FrameId: r.frameID,
+		Ais:     make([]*gamev1.AIDebugInfo, 0, len(ids)),
+	}
+	for _, id := range ids {
+		debug := r.aiControllers[int32(id)].DebugState()
+		info := &gamev1.AIDebugInfo{
+			PlayerId: int32(id),
+			Node:     debug.Node,
+			Path:     make([]*gamev1.GridCell, 0, len(debug.Path)),
+		}
+		if debug.Target != nil {
+			info.HasTarget = true
+			info.Target = &gamev1.GridCell{X: int32(debug.Target.GridX), Y: int32(debug.Target.GridY)}
+		}
+		for _, p := range debug.Path {
+			info.Path = append(info.Path, &gamev1.GridCell{X: int32(p.GridX), Y: int32(p.GridY)})
+		}
+		state.Ais = append(state.Ais, info)
+	}
+
+	packet, err := protocol.NewDebugAIStatePacket(state)
+	if err != nil {
+		log.Printf("构造 AI 调试信息失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化 AI 调试信息失败: %v", err)
+		return
+	}
+	for _, conn := range r.connections {
+		_ = conn.Send(data) // 调试信息丢了无所谓，不触发慢连接处理
+	}
+	r.sendToSpectators(data)
+}
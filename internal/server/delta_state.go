@@ -0,0 +1,105 @@
+package server
+
+import (
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// 增量状态广播
+//
+// 每帧的完整状态保存在 stateHistory 中，客户端在输入包里确认已收到的最新状态帧（ack_state_frame），
+// 服务器以该帧为基线发送 DeltaState。没有确认（旧客户端、观战者、录制）、确认的帧已不在历史中，
+// 或到了定期完整快照的帧时发送完整的 GameState。
+const (
+	stateHistoryFrames   = core.TPS     // 保留的历史状态帧数（确认延迟超过 1 秒时退回完整快照）
+	fullSnapshotInterval = 2 * core.TPS // 定期发送完整快照的间隔（帧）
+)
+
+// recordStateHistory 记录本帧广播的完整状态（同一帧多次广播时只保留最后一次）
+func (r *Room) recordStateHistory(state *gamev1.GameState) {
+	if n := len(r.stateHistory); n > 0 && r.stateHistory[n-1].FrameId >= state.FrameId {
+		r.stateHistory = r.stateHistory[:n-1]
+	}
+	r.stateHistory = append(r.stateHistory, state)
+	if len(r.stateHistory) > stateHistoryFrames {
+		r.stateHistory = r.stateHistory[len(r.stateHistory)-stateHistoryFrames:]
+	}
+}
+
+// deltaBaseline 玩家确认过、仍在历史中的基线状态；需要发送完整快照时返回 nil
+func (r *Room) deltaBaseline(playerID int32) *gamev1.GameState {
+	if r.frameID%fullSnapshotInterval == 0 {
+		return nil
+	}
+	ack, ok := r.stateAcks[playerID]
+	if !ok || ack >= r.frameID {
+		return nil
+	}
+	for _, state := range r.stateHistory {
+		if state.FrameId == ack {
+			return state
+		}
+	}
+	return nil
+}
+
+// tileChangesSince 基线之后（不含基线帧）所有历史帧的地图变化，同一格子只保留最后一次
+func (r *Room) tileChangesSince(baseFrame int32) []*gamev1.TileChange {
+	type cell struct{ x, y int32 }
+	latest := make(map[cell]int)
+	var changes []*gamev1.TileChange
+	for _, state := range r.stateHistory {
+		if state.FrameId <= baseFrame {
+			continue
+		}
+		for _, tc := range state.TileChanges {
+			key := cell{tc.X, tc.Y}
+			if i, ok := latest[key]; ok {
+				changes[i] = tc
+				continue
+			}
+			latest[key] = len(changes)
+			changes = append(changes, tc)
+		}
+	}
+	return changes
+}
+
+// marshalDeltaState 构造相对基线的增量状态包
+func (r *Room) marshalDeltaState(base, cur *gamev1.GameState) ([]byte, error) {
+	delta := protocol.DiffGameState(base, cur, r.tileChangesSince(base.FrameId))
+	packet, err := protocol.NewDeltaStatePacket(delta)
+	if err != nil {
+		return nil, err
+	}
+	return protocol.MarshalPacket(packet)
+}
+
+// stateDataFor 发给玩家的状态包：有可用基线时发送增量（按基线帧缓存，确认同一帧的玩家共用），否则发送完整状态
+func (r *Room) stateDataFor(playerID int32, cur *gamev1.GameState, full []byte, deltas map[int32][]byte) []byte {
+	base := r.deltaBaseline(playerID)
+	if base == nil {
+		return full
+	}
+	if data, ok := deltas[base.FrameId]; ok {
+		return data
+	}
+	data, err := r.marshalDeltaState(base, cur)
+	if err != nil {
+		log.Printf("构造增量状态失败: %v", err)
+		return full
+	}
+	deltas[base.FrameId] = data
+	return data
+}
+
+// resetStateHistory 清空历史、确认和兴趣管理的视野（新对局的帧号从 0 开始，旧的确认不再有效）
+func (r *Room) resetStateHistory() {
+	r.stateHistory = nil
+	r.stateAcks = make(map[int32]int32)
+	r.interestVisible = make(map[int32]entitySet)
+	r.interestAll = nil
+}
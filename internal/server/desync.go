@@ -0,0 +1,41 @@
+package server
+
+import (
+	"log"
+	"strings"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// handleDesyncReport 记录客户端上报的状态不一致（客户端已限频），用于排查确定性问题：
+// 日志中带上不一致的部分（地图、炸弹、玩家）和双方的校验和，同时计入指标
+func (s *GameServer) handleDesyncReport(conn Session, report *DesyncReportEvent) {
+	if report == nil || conn.ID() < 0 {
+		return
+	}
+	s.metrics.desyncReports.Add(1)
+	parts := report.Server.Diff(report.Client)
+	if len(parts) == 0 {
+		parts = []string{"none"}
+	}
+	log.Printf("房间 %s 玩家 %d 在帧 %d 状态不同步（%s）: 服务器 %08x/%08x/%08x 客户端 %08x/%08x/%08x",
+		conn.GetRoomID(), conn.ID(), report.FrameID, strings.Join(parts, ","),
+		report.Server.Map, report.Server.Bombs, report.Server.Players,
+		report.Client.Map, report.Client.Bombs, report.Client.Players)
+}
+
+// broadcastSyncCheck 开启帧同步校验时，每 core.SyncCheckIntervalFrames 帧在状态广播之后发送该帧的校验和
+func (r *Room) broadcastSyncCheck() {
+	if !r.syncCheck || r.frameID%core.SyncCheckIntervalFrames != 0 {
+		return
+	}
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_SyncCheck{
+			SyncCheck: &gamev1.SyncCheckEvent{
+				Checksum: protocol.CoreChecksumToProto(r.game.Checksum()),
+			},
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 错误参数中使用的房间操作名（客户端据此本地化）
+const (
+	actionReady      = "ready"
+	actionStart      = "start"
+	actionAddAI      = "add_ai"
+	actionKick       = "kick"
+	actionRerollSeed = "reroll_seed"
+	actionSetRules   = "set_rules"
+	actionSpectate   = "spectate"
+	actionTakeover   = "takeover"
+	actionSetTeam    = "set_team"
+	actionSetConfig  = "set_config"
+	actionSetMap     = "set_map"
+	actionPropose    = "propose"
+	actionVote       = "vote"
+	actionResolve    = "resolve_proposal"
+)
+
+// errRoomClosed 房间已关闭（房间协程退出后的请求）
+var errRoomClosed = &RoomError{Code: gamev1.ErrorCode_ERROR_CODE_ROOM_CLOSED, Msg: "房间已关闭"}
+
+// RoomError 带错误码的房间错误，错误码和参数会随响应透传给客户端
+// Msg 仅用于服务器日志，客户端根据 Code/Params 渲染本地化文案
+type RoomError struct {
+	Code   gamev1.ErrorCode
+	Params []string
+	Msg    string
+}
+
+func (e *RoomError) Error() string {
+	return e.Msg
+}
+
+// newRoomError 构造带错误码的错误
+func newRoomError(code gamev1.ErrorCode, format string, args ...interface{}) error {
+	return &RoomError{Code: code, Msg: fmt.Sprintf(format, args...)}
+}
+
+// newRoomErrorWithParams 构造带错误码和参数的错误
+func newRoomErrorWithParams(code gamev1.ErrorCode, params []string, format string, args ...interface{}) error {
+	return &RoomError{Code: code, Params: params, Msg: fmt.Sprintf(format, args...)}
+}
+
+// countParams 构造 [当前, 上限] 形式的参数
+func countParams(current, limit int) []string {
+	return []string{strconv.Itoa(current), strconv.Itoa(limit)}
+}
+
+// errorCodeOf 提取错误码，普通错误返回 UNSPECIFIED
+func errorCodeOf(err error) gamev1.ErrorCode {
+	var roomErr *RoomError
+	if errors.As(err, &roomErr) {
+		return roomErr.Code
+	}
+	return gamev1.ErrorCode_ERROR_CODE_UNSPECIFIED
+}
+
+// errorParamsOf 提取错误码参数
+func errorParamsOf(err error) []string {
+	var roomErr *RoomError
+	if errors.As(err, &roomErr) {
+		return roomErr.Params
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
 )
 
 type EventKind int
@@ -15,6 +16,11 @@ const (
 	EventReconnect
 	EventRoomList
 	EventRoomAction
+	EventServerStatus
+	EventReplaySearch
+	EventMapUpload
+	EventDesyncReport
+	EventProfileUpdate
 )
 
 type InputData struct {
@@ -24,12 +30,39 @@ type InputData struct {
 	Left    bool
 	Right   bool
 	Bomb    bool
+	Shove   bool
+	Sprint  bool
+}
+
+// coreInput 转换为核心逻辑的输入
+func (input InputData) coreInput() core.Input {
+	return core.Input{
+		Up:     input.Up,
+		Down:   input.Down,
+		Left:   input.Left,
+		Right:  input.Right,
+		Bomb:   input.Bomb,
+		Shove:  input.Shove,
+		Sprint: input.Sprint,
+	}
 }
 
 type JoinEvent struct {
+	RequestID  int32 // 客户端请求 ID，原样带回加入响应
 	PlayerName string
 	Character  gamev1.CharacterType
 	RoomID     string // 房间 ID，空字符串表示自动分配到默认房间
+	Spectate   bool   // 是否以观战者身份加入
+	TakeOverAI bool   // 游戏进行中接管一个 AI（需房主同意）
+
+	ProtocolVersion int32  // 客户端协议版本（旧客户端为 0）
+	ClientVersion   string // 客户端发布版本号
+
+	AccountKey string                // 账号密钥（空表示不使用账号偏好）
+	Account    string                // 由 AccountKey 得到的账号 ID（服务器填写）
+	Profile    *gamev1.PlayerProfile // 账号保存的偏好，随加入响应下发（服务器填写）
+
+	TelemetryOptIn bool // 同意收集输入观察并写入举报（report_store.go）
 }
 
 type InputEvent struct {
@@ -37,6 +70,9 @@ type InputEvent struct {
 	RoomID   string // 房间 ID
 	Seq      int32
 	Inputs   []InputData
+
+	AckStateFrame int32   // 客户端确认的最新状态帧（增量状态的基线），0 表示只接收完整状态
+	AckEventSeqs  []int32 // 客户端确认收到的关键事件序号
 }
 
 type PingEvent struct {
@@ -50,25 +86,63 @@ type PongEvent struct {
 }
 
 type ReconnectEvent struct {
+	RequestID    int32
 	SessionToken string
 }
 
 type RoomListEvent struct {
-	Page     int32
-	PageSize int32
+	RequestID   int32
+	Page        int32
+	PageSize    int32
+	WaitingOnly bool
+	NotFull     bool
+	HasAI       bool
 }
 
 type RoomActionEvent struct {
 	Action *gamev1.RoomAction
 }
 
+type ServerStatusEvent struct {
+	ClientTime int64
+}
+
+type ReplaySearchEvent struct {
+	PlayerName string
+	Map        string
+	SinceMs    int64
+	UntilMs    int64
+	Limit      int32
+}
+
+type MapUploadEvent struct {
+	Name    string
+	MapJSON []byte
+}
+
+type DesyncReportEvent struct {
+	FrameID int32
+	Server  core.StateChecksum
+	Client  core.StateChecksum
+}
+
+type ProfileUpdateEvent struct {
+	AccountKey string
+	Profile    *gamev1.PlayerProfile // 未设置的字段保持不变
+}
+
 type ServerEvent struct {
-	Kind       EventKind
-	Join       *JoinEvent
-	Input      *InputEvent
-	Ping       *PingEvent
-	Pong       *PongEvent
-	Reconnect  *ReconnectEvent
-	RoomList   *RoomListEvent
-	RoomAction *RoomActionEvent
+	Kind         EventKind
+	Join         *JoinEvent
+	Input        *InputEvent
+	Ping         *PingEvent
+	Pong         *PongEvent
+	Reconnect    *ReconnectEvent
+	RoomList     *RoomListEvent
+	RoomAction   *RoomActionEvent
+	Status       *ServerStatusEvent
+	ReplaySearch *ReplaySearchEvent
+	MapUpload    *MapUploadEvent
+	DesyncReport *DesyncReportEvent
+	Profile      *ProfileUpdateEvent
 }
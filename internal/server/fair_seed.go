@@ -0,0 +1,33 @@
+package server
+
+import (
+	"bomberman/pkg/fairseed"
+)
+
+// seedCommitment 当前种子的承诺；种子不变时承诺保持不变，换种子后重新生成
+func (r *Room) seedCommitment() fairseed.Commitment {
+	if r.seedCommit.Hash == nil || r.seedCommit.Seed != r.seed {
+		r.seedCommit = fairseed.Commit(r.seed)
+	}
+	return r.seedCommit
+}
+
+// visibleSeed 下发给客户端的种子：公平种子规则下开始前隐藏（为 0）
+func (r *Room) visibleSeed() int64 {
+	if r.rules.FairSeed && r.state == StateWaiting {
+		return 0
+	}
+	return r.seed
+}
+
+// seedReveal 房间状态中的承诺与盐：开始前只给承诺，开局后连同盐一起公开
+func (r *Room) seedReveal() (commitment, salt []byte) {
+	if !r.rules.FairSeed {
+		return nil, nil
+	}
+	c := r.seedCommitment()
+	if r.state == StateWaiting {
+		return c.Hash, nil
+	}
+	return c.Hash, c.Salt
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+)
+
+// 数据包分帧：TCP/KCP 是字节流，每个数据包前加 4 字节大端长度前缀；
+// 自带消息边界的连接（WebSocket，见 websocket.go）实现 packetConn，直接按帧收发
+
+// packetConn 自带消息边界的连接，一次读写一个完整的数据包
+type packetConn interface {
+	ReadPacket() ([]byte, error)
+	WritePacket(data []byte) error
+}
+
+// readPacket 读取一个完整的数据包：读取 4 字节长度前缀和数据体
+func readPacket(conn net.Conn) ([]byte, error) {
+	if pc, ok := conn.(packetConn); ok {
+		return pc.ReadPacket()
+	}
+
+	var length uint32
+	if err := binary.Read(conn, binary.BigEndian, &length); err != nil {
+		return nil, err
+	}
+	if length > MaxPacketSize {
+		return nil, fmt.Errorf("消息过大 (%d bytes)", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(conn, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// writePacket 发送一个数据包：先发送长度前缀，再发送数据体
+func writePacket(conn net.Conn, data []byte) error {
+	if pc, ok := conn.(packetConn); ok {
+		return pc.WritePacket(data)
+	}
+
+	if err := binary.Write(conn, binary.BigEndian, uint32(len(data))); err != nil {
+		return err
+	}
+	_, err := conn.Write(data)
+	return err
+}
@@ -4,19 +4,38 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	gamev1 "bomberman/api/gen/bomberman/v1"
 	"bomberman/pkg/protocol"
+	"bomberman/pkg/version"
 )
 
+// DefaultServerName 默认服务器名称（状态查询返回）
+const DefaultServerName = "Bomberman"
+
 const (
-	MaxPlayers   = 4  // 最大玩家数
+	MaxPlayers   = 8  // 最大玩家数（超过 core.StandardMapPlayers 人时换成大地图，见 room_map.go）
 	ServerTPS    = 60 // 服务器每秒更新次数
 	TickDuration = time.Second / ServerTPS
 )
 
+// 大厅空闲策略（未加入任何房间的连接）
+const (
+	DefaultLobbyIdleTimeout = 10 * time.Minute // 默认空闲断开时间
+	lobbyIdleWarnBefore     = time.Minute      // 断开前多久发送警告
+)
+
+// 等待阶段的房间空闲策略（无人准备、无人操作）
+const (
+	DefaultRoomIdleTimeout = 5 * time.Minute // 默认解散时间
+	roomIdleWarnBefore     = time.Minute     // 解散前多久发送警告
+)
+
 // GameState 服务端房间状态
 type GameState int
 
@@ -31,13 +50,40 @@ type GameServer struct {
 	roomManager *RoomManager
 
 	// 配置
-	enableAI bool
-
-	// 网络 - 支持双协议监听
-	tcpListener ServerListener
-	kcpListener ServerListener
-	tcpAddr     string
-	kcpAddr     string
+	enableAI         bool
+	lobbyIdleTimeout time.Duration // <=0 表示不限制
+	debugScenarios   bool          // 允许房间执行调试场景（仅用于测试环境）
+	debugAI          bool          // 房间广播 AI 调试信息（仅用于调参）
+	recordDir        string        // 房间广播录制目录（空表示不录制）
+	maxRooms         int           // 房间数上限
+	offlineTimeout   time.Duration // 离线玩家保留时间
+	roomIdleTimeout  time.Duration // 等待阶段房间空闲解散时间，<=0 表示不限制
+	statsFile        string        // AI 与真人胜负统计文件（空表示只在内存中统计）
+	serverName       string        // 服务器名称（状态查询返回）
+	motd             string        // 服务器公告（状态查询返回）
+	matchStats       *MatchStats
+	replayIndex      *ReplayIndex  // 录像索引（开启录制时，保存在录制目录下）
+	aiTreeFile       string        // AI 行为树 JSON 定义文件（空表示使用内置树）
+	mapsDir          string        // 社区地图目录（空表示不接受上传）
+	maps             *mapCatalog   // 社区地图（上传、审核、房间选图），未开启时为 nil
+	metricsAddr      string        // 指标 HTTP 端点地址（空表示不开启）
+	reportsDir       string        // 玩家举报目录（空表示不接受举报）
+	reports          *reportStore  // 玩家举报，未开启时为 nil
+	reportsToken     string        // 指标端点 /reports 的访问令牌（空表示不在指标端点提供举报）
+	syncCheck        bool          // 房间定期广播帧同步校验（SyncCheckEvent）
+	interestRadius   int           // 兴趣管理半径（格，0 表示不过滤）
+	profilesFile     string        // 玩家偏好文件（空表示只保存在内存中）
+	profiles         *ProfileStore // 按账号保存的玩家偏好
+	chaos            *ChaosProfile // 出站故障注入参数（仅用于测试，nil 表示不注入）
+	chaosConns       atomic.Int64  // 已应用故障注入的连接数（决定每个连接的随机数种子）
+	metrics          serverMetrics
+
+	// 网络 - 默认 TCP + KCP 监听同一地址，另可开启 WebSocket；配置文件可指定任意多个监听器
+	addr            string
+	wsAddr          string           // WebSocket 监听地址（空表示不开启）
+	listenerConfigs []ListenerConfig // 配置文件中的监听器（空表示使用 addr/wsAddr）
+	listenersMu     sync.Mutex
+	listeners       []*managedListener
 
 	// 控制
 	ctx      context.Context
@@ -51,47 +97,227 @@ func NewGameServer(addr, proto string, enableAI bool) *GameServer {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &GameServer{
-		tcpAddr:  addr, // TCP 监听地址
-		kcpAddr:  addr, // KCP 监听同一地址（不同协议）
+		addr:     addr, // TCP 与 KCP 监听同一地址（不同协议）
 		enableAI: enableAI,
+
+		lobbyIdleTimeout: DefaultLobbyIdleTimeout,
+		maxRooms:         MaxRooms,
+		serverName:       DefaultServerName,
+		offlineTimeout:   OfflinePlayerTimeout,
+		roomIdleTimeout:  DefaultRoomIdleTimeout,
+
 		ctx:      ctx,
 		cancel:   cancel,
 		shutdown: make(chan struct{}),
 	}
 }
 
+// SetLobbyIdleTimeout 设置大厅空闲断开时间（需在 Start 前调用，<=0 表示关闭）
+func (s *GameServer) SetLobbyIdleTimeout(timeout time.Duration) {
+	s.lobbyIdleTimeout = timeout
+}
+
+// SetDebugScenarios 开启调试场景 API（需在 Start 前调用，生产环境不要开启）
+func (s *GameServer) SetDebugScenarios(enabled bool) {
+	s.debugScenarios = enabled
+}
+
+// SetDebugAI 开启 AI 调试信息广播（需在 Start 前调用，会增加带宽并暴露 AI 意图）
+func (s *GameServer) SetDebugAI(enabled bool) {
+	s.debugAI = enabled
+}
+
+// SetRecordDir 录制每个房间的全部出站广播到 dir（需在 Start 前调用，空表示不录制）
+func (s *GameServer) SetRecordDir(dir string) {
+	s.recordDir = dir
+}
+
+// SetWSAddr 设置 WebSocket 监听地址（需在 Start 前调用，空表示不开启）
+// WebSocket 不能与 TCP 共用端口，需要单独的地址
+func (s *GameServer) SetWSAddr(addr string) {
+	s.wsAddr = addr
+}
+
+// SetAITreeFile 设置 AI 行为树 JSON 定义文件（需在 Start 前调用，空表示使用内置树）
+func (s *GameServer) SetAITreeFile(path string) {
+	s.aiTreeFile = path
+}
+
+// SetMapsDir 设置社区地图目录：客户端地图编辑器上传的地图进入待审核队列，
+// 运维审核通过后可在房间中选择（需在 Start 前调用，空表示不开启）
+func (s *GameServer) SetMapsDir(dir string) {
+	s.mapsDir = dir
+}
+
+// SetMetricsAddr 设置指标 HTTP 端点地址（需在 Start 前调用，路径 /metrics，空表示不开启）
+func (s *GameServer) SetMetricsAddr(addr string) {
+	s.metricsAddr = addr
+}
+
+// SetReportsDir 设置玩家举报目录：举报连同服务器对被举报玩家的观察记录（输入频率、输入延迟、聊天）
+// 保存在这里，运维在控制台或指标端点查看（需在 Start 前调用，空表示不接受举报、不收集观察数据）。
+// 观察数据只收集加入时同意的玩家
+func (s *GameServer) SetReportsDir(dir string) {
+	s.reportsDir = dir
+}
+
+// SetReportsToken 设置指标端点 /reports 的访问令牌（需在 Start 前调用）。
+// 空表示举报只能在控制台查看，指标端点不提供
+func (s *GameServer) SetReportsToken(token string) {
+	s.reportsToken = token
+}
+
+// SetSyncCheck 开启帧同步校验：每个房间每 core.SyncCheckIntervalFrames 帧广播一次状态校验和，
+// 客户端比较后上报不一致，服务器记录日志（需在 Start 前调用）
+func (s *GameServer) SetSyncCheck(enabled bool) {
+	s.syncCheck = enabled
+}
+
+// SetInterestRadius 开启兴趣管理：每名玩家只收到周围 radius 格内的玩家、炸弹和爆炸，
+// 已有实体进出范围时单独通知（需在 Start 前调用，<=0 表示不过滤）
+func (s *GameServer) SetInterestRadius(radius int) {
+	s.interestRadius = max(0, radius)
+}
+
+// SetChaos 开启出站故障注入：每个连接的出站包按 profile 随机延迟、重复、乱序或丢弃
+// （需在 Start 前调用，仅用于测试客户端的恢复能力，nil 表示不注入）
+func (s *GameServer) SetChaos(profile *ChaosProfile) {
+	s.chaos = profile
+}
+
+// SetMaxRooms 设置房间数上限（需在 Start 前调用，<=0 表示不限制）
+func (s *GameServer) SetMaxRooms(maxRooms int) {
+	s.maxRooms = maxRooms
+}
+
+// SetOfflineTimeout 设置断线玩家的保留时间（需在 Start 前调用）
+// 超过 SessionReconnectWindow 时截断：再久断线玩家的会话令牌可能已过期，无法重连
+func (s *GameServer) SetOfflineTimeout(timeout time.Duration) {
+	if timeout > SessionReconnectWindow {
+		log.Printf("断线玩家保留时间 %v 超过会话令牌保证的有效期，按 %v 处理", timeout, SessionReconnectWindow)
+		timeout = SessionReconnectWindow
+	}
+	s.offlineTimeout = timeout
+}
+
+// SetRoomIdleTimeout 设置等待阶段房间空闲解散时间（需在 Start 前调用，<=0 表示关闭）
+func (s *GameServer) SetRoomIdleTimeout(timeout time.Duration) {
+	s.roomIdleTimeout = timeout
+}
+
+// SetProfilesFile 设置玩家偏好（角色、控制方案、房主的规则预设）的保存文件，按账号密钥保存
+// （需在 Start 前调用，空表示只保存在内存中，服务器重启后丢失）
+func (s *GameServer) SetProfilesFile(path string) {
+	s.profilesFile = path
+}
+
+// SetStatsFile 设置 AI 与真人胜负统计的保存文件（需在 Start 前调用，空表示不保存）
+func (s *GameServer) SetStatsFile(path string) {
+	s.statsFile = path
+}
+
+// SetServerName 设置服务器名称（显示在客户端服务器列表中）
+func (s *GameServer) SetServerName(name string) {
+	s.serverName = name
+}
+
+// SetMOTD 设置服务器公告（显示在客户端服务器列表中，空表示没有公告）
+func (s *GameServer) SetMOTD(motd string) {
+	s.motd = motd
+}
+
+// MatchStats AI 与真人胜负统计（Start 之后可用）
+func (s *GameServer) MatchStats() *MatchStats {
+	return s.matchStats
+}
+
+// RunScenario 在指定房间执行调试场景
+func (s *GameServer) RunScenario(roomID string, ops []ScenarioOp) error {
+	if s.roomManager == nil {
+		return fmt.Errorf("服务器未启动")
+	}
+	return s.roomManager.RunScenario(roomID, ops)
+}
+
 // Start 启动服务器
 func (s *GameServer) Start() error {
-	log.Printf("启动游戏服务器 (TCP + KCP): %s", s.tcpAddr)
-
-	// 监听 TCP
-	tcpListener, err := newListener("tcp", s.tcpAddr)
-	if err != nil {
-		return fmt.Errorf("监听 TCP 失败: %w", err)
+	configs := s.listenerConfigs
+	if len(configs) == 0 {
+		configs = defaultListeners(s.addr, s.wsAddr)
 	}
-	s.tcpListener = tcpListener
+	log.Printf("启动游戏服务器 (%d 个监听器)", len(configs))
 
-	// 监听 KCP
-	kcpListener, err := newListener("kcp", s.kcpAddr)
+	listeners, err := openListeners(configs)
 	if err != nil {
-		tcpListener.Close()
-		return fmt.Errorf("监听 KCP 失败: %w", err)
+		return err
+	}
+	s.listenersMu.Lock()
+	s.listeners = listeners
+	s.listenersMu.Unlock()
+	for _, l := range listeners {
+		log.Printf("监听中: %s", l.label())
 	}
-	s.kcpListener = kcpListener
-
-	log.Printf("TCP 监听中: %s", s.tcpAddr)
-	log.Printf("KCP 监听中: %s", s.kcpAddr)
 
 	s.roomManager = NewRoomManager(s.ctx, s.enableAI)
+	s.roomManager.debugScenarios = s.debugScenarios
+	s.roomManager.debugAI = s.debugAI
+	s.roomManager.recordDir = s.recordDir
+	s.roomManager.maxRooms = s.maxRooms
+	s.roomManager.offlineTimeout = s.offlineTimeout
+	s.roomManager.roomIdleTimeout = s.roomIdleTimeout
+	s.roomManager.syncCheck = s.syncCheck
+	s.roomManager.interestRadius = int32(s.interestRadius)
+	matchStats, err := LoadMatchStats(s.statsFile)
+	if err != nil {
+		log.Printf("读取对局统计失败，从零开始统计: %v", err)
+	}
+	s.matchStats = matchStats
+	s.roomManager.matchStats = matchStats
+	profiles, err := LoadProfileStore(s.profilesFile)
+	if err != nil {
+		log.Printf("读取玩家偏好失败，从空开始: %v", err)
+	}
+	profiles.RunWriter(s.ctx, &s.wg)
+	s.profiles = profiles
+	s.roomManager.profiles = profiles
+	if s.recordDir != "" {
+		if err := os.MkdirAll(s.recordDir, 0o755); err != nil {
+			log.Printf("创建录制目录失败: %v", err)
+		}
+		replayIndex, err := LoadReplayIndex(s.recordDir)
+		if err != nil {
+			log.Printf("读取录像索引失败，从空索引开始: %v", err)
+		}
+		s.replayIndex = replayIndex
+		s.roomManager.replayIndex = replayIndex
+	}
+	if s.mapsDir != "" {
+		s.maps = newMapCatalog(s.mapsDir)
+		s.roomManager.maps = s.maps
+	}
+	if s.reportsDir != "" {
+		s.reports = newReportStore(s.reportsDir)
+		s.roomManager.reports = s.reports
+	}
+	if s.aiTreeFile != "" {
+		s.roomManager.aiTree = loadAITree(s.aiTreeFile)
+	}
+	if s.debugScenarios {
+		log.Printf("警告: 调试场景 API 已开启，不要在生产环境使用")
+	}
 	s.roomManager.Run(&s.wg)
 
-	// 启动 TCP 连接接受循环
-	s.wg.Add(1)
-	go s.acceptLoopTCP()
+	// 启动指标导出
+	if s.metricsAddr != "" {
+		s.wg.Add(1)
+		go s.runMetricsExporter(s.metricsAddr)
+	}
 
-	// 启动 KCP 连接接受循环
-	s.wg.Add(1)
-	go s.acceptLoopKCP()
+	// 每个监听器一个连接接受循环
+	for _, l := range listeners {
+		s.wg.Add(1)
+		go s.acceptLoop(l)
+	}
 
 	// 等待关闭信号
 	<-s.shutdown
@@ -112,12 +338,11 @@ func (s *GameServer) Shutdown() {
 	}
 
 	// 关闭监听器
-	if s.tcpListener != nil {
-		s.tcpListener.Close()
-	}
-	if s.kcpListener != nil {
-		s.kcpListener.Close()
+	s.listenersMu.Lock()
+	for _, l := range s.listeners {
+		l.stop()
 	}
+	s.listenersMu.Unlock()
 
 	// 关闭 shutdown 通道
 	close(s.shutdown)
@@ -128,43 +353,41 @@ func (s *GameServer) Shutdown() {
 	log.Println("服务器已关闭")
 }
 
-// acceptLoopTCP 接受 TCP 客户端连接
-func (s *GameServer) acceptLoopTCP() {
-	defer s.wg.Done()
-	s.acceptLoop("TCP", s.tcpListener)
-}
-
-// acceptLoopKCP 接受 KCP 客户端连接
-func (s *GameServer) acceptLoopKCP() {
+// acceptLoop 一个监听器的连接接受循环（服务器关闭或单独停止该监听器时退出）
+func (s *GameServer) acceptLoop(l *managedListener) {
 	defer s.wg.Done()
-	s.acceptLoop("KCP", s.kcpListener)
-}
+	defer close(l.done)
 
-// acceptLoop 通用连接接受循环
-func (s *GameServer) acceptLoop(proto string, listener ServerListener) {
+	name := l.config.Name
 	for {
 		select {
 		case <-s.ctx.Done():
-			log.Printf("停止接受新 %s 连接", proto)
+			log.Printf("停止接受新 %s 连接", name)
 			return
 		default:
 		}
 
-		conn, err := listener.Accept()
+		conn, err := l.listener.Accept()
 		if err != nil {
+			if l.stopped.Load() {
+				log.Printf("停止接受新 %s 连接", name)
+				return
+			}
 			select {
 			case <-s.ctx.Done():
 				return
 			default:
-				log.Printf("[%s] 接受连接失败: %v", proto, err)
+				log.Printf("[%s] 接受连接失败: %v", name, err)
 				continue
 			}
 		}
 
-		log.Printf("[%s] 新连接来自: %s", proto, conn.RemoteAddr())
+		log.Printf("[%s] 新连接来自: %s", name, conn.RemoteAddr())
+		l.accepted.Add(1)
 
 		// 创建连接对象
 		connection := NewConnection(conn, s)
+		connection.listener = l
 
 		// 启动连接处理
 		s.wg.Add(1)
@@ -177,7 +400,46 @@ func (s *GameServer) handleJoinRequest(conn Session, req *JoinEvent) error {
 	if s.roomManager == nil {
 		return fmt.Errorf("房间未初始化")
 	}
-	return s.roomManager.Join(conn, *req)
+	if err := checkClientVersion(req); err != nil {
+		sendJoinFailure(conn, req.RequestID, err)
+		return err
+	}
+	if err := s.resolveJoinProfile(req); err != nil {
+		sendJoinFailure(conn, req.RequestID, err)
+		return err
+	}
+	if err := s.roomManager.Join(conn, *req); err != nil {
+		sendJoinFailure(conn, req.RequestID, err)
+		return err
+	}
+	return nil
+}
+
+// checkClientVersion 协议版本协商：拒绝协议版本不一致的客户端（旧客户端不携带版本，放行）
+func checkClientVersion(req *JoinEvent) error {
+	if req.ProtocolVersion == 0 || req.ProtocolVersion == version.Protocol {
+		return nil
+	}
+	return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_VERSION_MISMATCH,
+		[]string{version.Game, strconv.Itoa(version.Protocol)},
+		"客户端 %s 的协议版本 %d 与服务器 %d 不一致", req.ClientVersion, req.ProtocolVersion, version.Protocol)
+}
+
+// sendJoinFailure 通知客户端加入失败（携带错误码，接管审批的结果由房间异步发送）
+func sendJoinFailure(conn Session, requestID int32, joinErr error) {
+	packet, err := protocol.NewJoinFailurePacket(requestID, errorCodeOf(joinErr), errorParamsOf(joinErr), joinErr.Error())
+	if err != nil {
+		log.Printf("构造加入失败响应失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化加入失败响应失败: %v", err)
+		return
+	}
+	if err := conn.Send(data); err != nil {
+		log.Printf("发送加入失败响应失败: %v", err)
+	}
 }
 
 // handleClientInput 处理客户端输入
@@ -219,38 +481,38 @@ func (s *GameServer) handlePing(conn Session, ping *PingEvent) {
 
 // handleReconnect 处理重连请求
 func (s *GameServer) handleReconnect(conn Session, req *ReconnectEvent) {
-	if req == nil || req.SessionToken == "" {
-		s.sendReconnectResponse(conn, false, "缺少会话令牌", nil)
+	if req == nil {
 		return
 	}
 
-	// 验证 Token
+	// 验证 Token（签名、签发者、有效期），失败时客户端根据错误码放弃重连
 	playerID, roomID, err := VerifySessionToken(req.SessionToken)
 	if err != nil {
 		log.Printf("重连失败: Token 验证失败: %v", err)
-		s.sendReconnectResponse(conn, false, "会话令牌无效或已过期", nil)
+		s.sendReconnectResponse(conn, req.RequestID, false, err, "", nil, nil)
 		return
 	}
 
 	if roomID == "" {
 		conn.SetPlayerID(-1)
 		conn.SetRoomID("")
-		s.sendReconnectResponse(conn, true, "", nil)
+		s.sendReconnectResponse(conn, req.RequestID, true, nil, s.refreshSessionToken(0, ""), nil, nil)
 		return
 	}
 
 	if s.roomManager == nil {
-		s.sendReconnectResponse(conn, false, "房间未初始化", nil)
+		s.sendReconnectResponse(conn, req.RequestID, false, newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "房间未初始化"), "", nil, nil)
 		return
 	}
 
 	// 尝试恢复会话
-	currentState, err := s.roomManager.ReconnectPlayer(conn.ID(), playerID, roomID, conn)
+	currentState, history, err := s.roomManager.ReconnectPlayer(conn.ID(), playerID, roomID, conn)
 	if err != nil {
 		log.Printf("重连失败: 玩家 %d: %v", playerID, err)
 		conn.SetPlayerID(-1)
 		conn.SetRoomID("")
-		s.sendReconnectResponse(conn, true, "房间已关闭，返回大厅", nil)
+		closed := newRoomError(gamev1.ErrorCode_ERROR_CODE_ROOM_CLOSED, "房间已关闭，返回大厅")
+		s.sendReconnectResponse(conn, req.RequestID, true, closed, s.refreshSessionToken(0, ""), nil, nil)
 		return
 	}
 
@@ -259,42 +521,99 @@ func (s *GameServer) handleReconnect(conn Session, req *ReconnectEvent) {
 	conn.SetRoomID(roomID)
 
 	log.Printf("玩家 %d 重连成功", playerID)
-	s.sendReconnectResponse(conn, true, "", currentState)
+	s.sendReconnectResponse(conn, req.RequestID, true, nil, s.refreshSessionToken(playerID, roomID), currentState, history)
 }
 
-// handleRoomListRequest 处理房间列表请求
-func (s *GameServer) handleRoomListRequest(conn Session, req *RoomListEvent) {
-	if s.roomManager == nil {
+// refreshSessionToken 重连成功后签发新的会话令牌，有效期从现在重新计算（签发失败时客户端继续使用旧令牌）
+func (s *GameServer) refreshSessionToken(playerID int32, roomID string) string {
+	token, err := GenerateSessionToken(playerID, roomID)
+	if err != nil {
+		log.Printf("刷新会话令牌失败: %v", err)
+		return ""
+	}
+	return token
+}
+
+// handleServerStatus 处理服务器状态查询（客户端服务器列表与外部监控使用，加入前即可查询）
+func (s *GameServer) handleServerStatus(conn Session, req *ServerStatusEvent) {
+	if req == nil {
 		return
 	}
-	rooms := s.roomManager.GetRoomList()
-	total := int32(len(rooms))
+	var rooms []*gamev1.RoomInfo
+	if s.roomManager != nil {
+		rooms = s.roomManager.GetRoomList()
+	}
+	players := int32(0)
+	for _, room := range rooms {
+		players += room.CurrentPlayers
+	}
 
-	page := int32(1)
-	pageSize := int32(20)
-	if req != nil {
-		if req.Page > 0 {
-			page = req.Page
+	packet, err := protocol.NewServerStatusResponsePacket(req.ClientTime, int32(len(rooms)), int32(max(s.maxRooms, 0)), players, s.serverName, version.Game, s.motd)
+	if err != nil {
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return
+	}
+	_ = conn.Send(data)
+}
+
+// handleReplaySearch 处理录像搜索（服务器未开启录制时返回空列表）
+func (s *GameServer) handleReplaySearch(conn Session, req *ReplaySearchEvent) {
+	if req == nil {
+		return
+	}
+	var summaries []*gamev1.ReplaySummary
+	total := 0
+	if s.replayIndex != nil {
+		query := ReplayQuery{Player: req.PlayerName, Map: req.Map, Limit: int(req.Limit)}
+		if req.SinceMs > 0 {
+			query.Since = time.UnixMilli(req.SinceMs)
 		}
-		if req.PageSize > 0 {
-			pageSize = req.PageSize
+		if req.UntilMs > 0 {
+			query.Until = time.UnixMilli(req.UntilMs)
+		}
+		var entries []ReplayIndexEntry
+		entries, total = s.replayIndex.Search(query)
+		for _, entry := range entries {
+			summaries = append(summaries, replaySummaryToProto(entry))
 		}
 	}
 
-	start := (page - 1) * pageSize
-	if start < 0 {
-		start = 0
+	packet, err := protocol.NewReplaySearchResponsePacket(summaries, int32(total))
+	if err != nil {
+		log.Printf("构造录像搜索响应失败: %v", err)
+		return
 	}
-	if start > total {
-		start = total
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化录像搜索响应失败: %v", err)
+		return
 	}
-	end := start + pageSize
-	if end > total {
-		end = total
+	_ = conn.Send(data)
+}
+
+// handleRoomListRequest 处理房间列表请求（按请求的过滤条件和页码返回一页）
+func (s *GameServer) handleRoomListRequest(conn Session, req *RoomListEvent) {
+	if s.roomManager == nil {
+		return
+	}
+	query := RoomListQuery{}
+	var requestID int32
+	if req != nil {
+		requestID = req.RequestID
+		query = RoomListQuery{
+			Page:        req.Page,
+			PageSize:    req.PageSize,
+			WaitingOnly: req.WaitingOnly,
+			NotFull:     req.NotFull,
+			HasAI:       req.HasAI,
+		}
 	}
+	list := s.roomManager.ListRooms(query)
 
-	sliced := rooms[start:end]
-	packet, err := protocol.NewRoomListResponsePacket(sliced, total)
+	packet, err := protocol.NewRoomListResponsePacket(requestID, list.Rooms, list.Total, list.Page, list.PageSize)
 	if err != nil {
 		log.Printf("构造房间列表响应失败: %v", err)
 		return
@@ -312,22 +631,22 @@ func (s *GameServer) handleRoomListRequest(conn Session, req *RoomListEvent) {
 // handleRoomAction 处理房间操作
 func (s *GameServer) handleRoomAction(conn Session, req *RoomActionEvent) {
 	if req == nil || req.Action == nil {
-		s.sendRoomActionResponse(conn, false, "房间操作为空", "", conn.GetRoomID())
+		s.sendRoomActionResponse(conn, 0, false, newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "房间操作为空"), "", conn.GetRoomID())
 		return
 	}
 	if s.roomManager == nil {
-		s.sendRoomActionResponse(conn, false, "房间未初始化", "", conn.GetRoomID())
+		s.sendRoomActionResponse(conn, req.Action.GetRequestId(), false, newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "房间未初始化"), "", conn.GetRoomID())
 		return
 	}
 	roomID := conn.GetRoomID()
 	if roomID == "" {
-		s.sendRoomActionResponse(conn, false, "未加入房间", "", "")
+		s.sendRoomActionResponse(conn, req.Action.GetRequestId(), false, newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "未加入房间"), "", "")
 		return
 	}
 
 	err := s.roomManager.HandleRoomAction(roomID, conn.ID(), req.Action)
 	if err != nil {
-		s.sendRoomActionResponse(conn, false, err.Error(), "", roomID)
+		s.sendRoomActionResponse(conn, req.Action.GetRequestId(), false, err, "", roomID)
 		return
 	}
 
@@ -340,11 +659,16 @@ func (s *GameServer) handleRoomAction(conn Session, req *RoomActionEvent) {
 			newToken = token
 		}
 	}
-	s.sendRoomActionResponse(conn, true, "", newToken, newRoomID)
+	s.sendRoomActionResponse(conn, req.Action.GetRequestId(), true, nil, newToken, newRoomID)
 }
 
-func (s *GameServer) sendRoomActionResponse(conn Session, success bool, errMsg string, sessionToken string, roomID string) {
-	packet, err := protocol.NewRoomActionResponsePacket(success, errMsg, sessionToken, roomID)
+// sendRoomActionResponse 发送房间操作响应，requestID 为房间操作的请求 ID，actionErr 的错误码和参数透传给客户端
+func (s *GameServer) sendRoomActionResponse(conn Session, requestID int32, success bool, actionErr error, sessionToken string, roomID string) {
+	errMsg := ""
+	if actionErr != nil {
+		errMsg = actionErr.Error()
+	}
+	packet, err := protocol.NewRoomActionResponsePacket(requestID, success, errorCodeOf(actionErr), errorParamsOf(actionErr), errMsg, sessionToken, roomID)
 	if err != nil {
 		log.Printf("构造房间操作响应失败: %v", err)
 		return
@@ -359,9 +683,13 @@ func (s *GameServer) sendRoomActionResponse(conn Session, success bool, errMsg s
 	}
 }
 
-// sendReconnectResponse 发送重连响应
-func (s *GameServer) sendReconnectResponse(conn Session, success bool, errMsg string, currentState *gamev1.GameState) {
-	packet, err := protocol.NewReconnectResponsePacket(success, errMsg, currentState)
+// sendReconnectResponse 发送重连响应（requestID 为重连请求的请求 ID）
+func (s *GameServer) sendReconnectResponse(conn Session, requestID int32, success bool, reconnectErr error, sessionToken string, currentState *gamev1.GameState, history []*gamev1.RoomHistoryEntry) {
+	errMsg := ""
+	if reconnectErr != nil {
+		errMsg = reconnectErr.Error()
+	}
+	packet, err := protocol.NewReconnectResponsePacket(requestID, success, errorCodeOf(reconnectErr), errorParamsOf(reconnectErr), errMsg, sessionToken, currentState, history)
 	if err != nil {
 		log.Printf("构造重连响应失败: %v", err)
 		return
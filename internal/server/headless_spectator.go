@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// HeadlessSpectator 无界面的运维观察者（实现 Session）
+// 解析收到的广播，每隔 summaryFrames 帧输出一行文本摘要，关键事件立即输出
+type HeadlessSpectator struct {
+	id            int32
+	summaryFrames int32
+
+	mu          sync.Mutex
+	roomID      string
+	latest      *gamev1.GameState
+	lastSummary int32
+	closed      bool
+}
+
+// NewHeadlessSpectator 创建观察者，id 需与玩家/观战者 ID 区分（使用负数）
+func NewHeadlessSpectator(id int32, summaryFrames int32) *HeadlessSpectator {
+	if summaryFrames <= 0 {
+		summaryFrames = core.TPS
+	}
+	return &HeadlessSpectator{id: id, summaryFrames: summaryFrames, lastSummary: -summaryFrames}
+}
+
+func (h *HeadlessSpectator) ID() int32 { return h.id }
+
+func (h *HeadlessSpectator) GetRoomID() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.roomID
+}
+
+func (h *HeadlessSpectator) SetRoomID(roomID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.roomID = roomID
+}
+
+func (h *HeadlessSpectator) SetPlayerID(id int32) {}
+
+// Send 在房间 goroutine 中调用，只做解析和日志
+func (h *HeadlessSpectator) Send(data []byte) error {
+	pkt, err := protocol.UnmarshalPacket(data)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return fmt.Errorf("观察者 %d 已关闭", h.id)
+	}
+
+	switch pkt.Type {
+	case gamev1.MessageType_MESSAGE_TYPE_GAME_STATE:
+		state, err := protocol.ParseGameState(pkt)
+		if err != nil {
+			return err
+		}
+		h.latest = state
+		if state.FrameId < h.lastSummary || state.FrameId-h.lastSummary >= h.summaryFrames {
+			h.lastSummary = state.FrameId
+			log.Printf("[观察 %s] %s", h.roomID, SummarizeGameState(state))
+		}
+	case gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT:
+		event, err := protocol.ParseGameEvent(pkt)
+		if err != nil {
+			return err
+		}
+		if text := describeEvent(event); text != "" {
+			log.Printf("[观察 %s] 帧 %d %s", h.roomID, event.FrameId, text)
+		}
+	}
+	return nil
+}
+
+// Latest 最近一次收到的游戏状态（可能为 nil）
+func (h *HeadlessSpectator) Latest() *gamev1.GameState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.latest
+}
+
+// Closed 房间关闭或已卸载
+func (h *HeadlessSpectator) Closed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.closed
+}
+
+func (h *HeadlessSpectator) Close() {
+	h.CloseWithoutNotify()
+}
+
+func (h *HeadlessSpectator) CloseWithoutNotify() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		log.Printf("[观察 %s] 观察结束", h.roomID)
+	}
+}
+
+// SummarizeGameState 游戏状态的单行文本摘要：帧号、阶段、存活玩家（格子坐标）、炸弹和爆炸数
+func SummarizeGameState(state *gamev1.GameState) string {
+	alive := 0
+	var players []string
+	for _, p := range state.Players {
+		if p.Dead {
+			players = append(players, fmt.Sprintf("P%d(dead)", p.Id))
+			continue
+		}
+		alive++
+		pos := core.PlayerXYToGrid(int(p.X), int(p.Y))
+		players = append(players, fmt.Sprintf("P%d(%d,%d)", p.Id, pos.GridX, pos.GridY))
+	}
+	return fmt.Sprintf("帧 %d %s 存活 %d/%d [%s] 炸弹 %d 爆炸 %d 道具 %d",
+		state.FrameId, state.Phase, alive, len(state.Players), strings.Join(players, " "),
+		len(state.Bombs), len(state.Explosions), len(state.Items))
+}
+
+// describeEvent 关键事件的文本描述，其余事件返回空串
+func describeEvent(event *gamev1.GameEvent) string {
+	switch e := event.Event.(type) {
+	case *gamev1.GameEvent_PlayerJoined:
+		return fmt.Sprintf("玩家 %d (%s) 加入", e.PlayerJoined.PlayerId, e.PlayerJoined.PlayerName)
+	case *gamev1.GameEvent_PlayerLeft:
+		return fmt.Sprintf("玩家 %d 离开", e.PlayerLeft.PlayerId)
+	case *gamev1.GameEvent_PlayerDied:
+		return fmt.Sprintf("玩家 %d 死亡（击杀归属 %d）", e.PlayerDied.PlayerId, e.PlayerDied.KillerId)
+	case *gamev1.GameEvent_GameStart:
+		return "游戏开始"
+	case *gamev1.GameEvent_GameOver:
+		if e.GameOver.WinningTeam != 0 {
+			return fmt.Sprintf("游戏结束，队伍 %d 获胜（玩家 %d 进门）", e.GameOver.WinningTeam, e.GameOver.WinnerId)
+		}
+		return fmt.Sprintf("游戏结束，获胜者 %d", e.GameOver.WinnerId)
+	case *gamev1.GameEvent_RoundEnd:
+		return fmt.Sprintf("第 %d 回合结束，获胜者 %d", e.RoundEnd.Round, e.RoundEnd.WinnerId)
+	case *gamev1.GameEvent_PlayerConnectionState:
+		if e.PlayerConnectionState.State == gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_OFFLINE {
+			return fmt.Sprintf("玩家 %d 断线，%d 秒内可重连", e.PlayerConnectionState.PlayerId, e.PlayerConnectionState.SecondsRemaining)
+		}
+		return fmt.Sprintf("玩家 %d 已重连", e.PlayerConnectionState.PlayerId)
+	case *gamev1.GameEvent_AiTakeover:
+		return fmt.Sprintf("玩家 %s 接管 AI %d", e.AiTakeover.PlayerName, e.AiTakeover.PlayerId)
+	case *gamev1.GameEvent_RoomIdleWarning:
+		return fmt.Sprintf("房间空闲，%d 秒后解散", e.RoomIdleWarning.SecondsRemaining)
+	case *gamev1.GameEvent_ReadyNudge:
+		return fmt.Sprintf("等待玩家 %s 准备已 %d 秒", e.ReadyNudge.PlayerName, e.ReadyNudge.WaitedSeconds)
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 房主迁移：房主离开或断线时（等待、对局、结算阶段都一样）立即把房主交给在线玩家中 ID 最小的一个，
+// 新房主马上获得开始、踢人、修改规则和审批接管的权限。真人玩家全部断线时房主暂时空缺，第一个回来的玩家接任
+
+// transferHost 重新选出房主，房主变化时广播 HostChanged 事件，并把待审批的接管申请转给新房主
+// （房间状态由调用方随后广播）
+func (r *Room) transferHost() {
+	previous := r.hostID
+	r.hostID = 0
+	for playerID := range r.connections {
+		if r.hostID == 0 || playerID < r.hostID {
+			r.hostID = playerID
+		}
+	}
+	if r.hostID == previous {
+		return
+	}
+	log.Printf("房间 %s 房主变更: %d -> %d", r.id, previous, r.hostID)
+	if r.legacyMode {
+		return
+	}
+
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_HostChanged{
+			HostChanged: &gamev1.HostChangedEvent{
+				PreviousHostId: previous,
+				NewHostId:      r.hostID,
+				NewHostName:    r.playerNames[r.hostID],
+			},
+		},
+	})
+	if host, ok := r.connections[r.hostID]; ok {
+		for requestID, pending := range r.pendingTakeovers {
+			r.sendTakeoverRequest(host, requestID, pending)
+		}
+	}
+}
+
+// migrateHost 房主断线或空缺的房主有人接任时重新选出房主并广播房间状态
+func (r *Room) migrateHost() {
+	r.transferHost()
+	if !r.legacyMode {
+		r.broadcastRoomState()
+	}
+}
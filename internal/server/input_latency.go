@@ -0,0 +1,87 @@
+package server
+
+import (
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// inputLatency 一名玩家本局的输入延迟统计
+// 延迟为输入实际生效的帧与客户端指定的目标帧之差：准时到达的输入在目标帧生效，延迟为 0；
+// 迟到的输入最早只能在到达帧生效（移动被上一帧输入代替，放弹由 placeLateBomb 补放）
+type inputLatency struct {
+	lastFrame  int32 // 已统计的最大目标帧（客户端会在多个包里重发同一帧）
+	inputs     int32
+	late       int32
+	totalDelay int64
+	maxDelay   int32
+
+	// 以下只用于举报时附带的观察记录（report_store.go），只统计同意收集的玩家
+	firstFrame int32 // 收到第一个输入包时的服务器帧
+	packets    int32 // 收到的输入包数
+	ahead      int32 // 目标帧超前服务器 InputBufferFrames 以上的输入（客户端时钟走快）
+}
+
+// inputStats 玩家本局的输入统计，不存在时创建
+func (r *Room) inputStats(playerID int32) *inputLatency {
+	stats, ok := r.inputDelays[playerID]
+	if !ok {
+		stats = &inputLatency{lastFrame: -1, firstFrame: r.frameID}
+		r.inputDelays[playerID] = stats
+	}
+	return stats
+}
+
+// recordInputPacket 记录收到一个带输入的包
+func (r *Room) recordInputPacket(playerID int32) {
+	if r.collectTelemetry(playerID) {
+		r.inputStats(playerID).packets++
+	}
+}
+
+// recordInputDelay 记录一帧输入的延迟，重发的帧只统计第一次到达
+func (r *Room) recordInputDelay(playerID, targetFrame int32) {
+	stats := r.inputStats(playerID)
+	if targetFrame <= stats.lastFrame {
+		return
+	}
+	stats.lastFrame = targetFrame
+	stats.inputs++
+	if targetFrame > r.frameID+InputBufferFrames && r.collectTelemetry(playerID) {
+		stats.ahead++
+	}
+
+	delay := r.frameID - targetFrame
+	if delay <= 0 {
+		return
+	}
+	stats.late++
+	stats.totalDelay += int64(delay)
+	stats.maxDelay = max(stats.maxDelay, delay)
+}
+
+// buildPlayerMatchStats 赛后统计（只包含本局发送过输入的真人玩家）
+func (r *Room) buildPlayerMatchStats() []*gamev1.PlayerMatchStats {
+	stats := make([]*gamev1.PlayerMatchStats, 0, len(r.inputDelays))
+	for _, playerID := range sortedPlayerIDs(r.inputDelays) {
+		l := r.inputDelays[playerID]
+		avg := l.avgDelay()
+		stats = append(stats, &gamev1.PlayerMatchStats{
+			PlayerId:            playerID,
+			Inputs:              l.inputs,
+			LateInputs:          l.late,
+			AvgInputDelayFrames: avg,
+			MaxInputDelayFrames: l.maxDelay,
+		})
+		log.Printf("玩家 %d 输入延迟: 平均 %.2f 帧，最大 %d 帧，迟到 %d/%d", playerID, avg, l.maxDelay, l.late, l.inputs)
+	}
+	return stats
+}
+
+// avgDelay 平均输入延迟（帧）
+func (l *inputLatency) avgDelay() float32 {
+	if l.inputs == 0 {
+		return 0
+	}
+	return float32(l.totalDelay) / float32(l.inputs)
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"log"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// 兴趣管理：开启后每名玩家只收到以自己所在格子为中心、半径内（切比雪夫距离，格）的玩家、炸弹和爆炸，
+// 地图变化、道具等其余字段不变。过滤结果只取决于该帧的完整状态和玩家自己在该帧的位置，
+// 所以历史中的任意一帧都能重新过滤出玩家当时收到的状态，直接作为增量基线。
+// 已有实体跨过范围边界时单独发给该玩家 EntityVisibilityEvent。观战者、录制和默认房间始终收到完整状态
+
+// entityKey 兴趣管理中的一个实体
+type entityKey struct {
+	kind gamev1.EntityKind
+	id   int32
+}
+
+// entitySet 一帧状态中的实体
+type entitySet map[entityKey]bool
+
+// interestEnabled 本房间是否过滤状态
+func (r *Room) interestEnabled() bool {
+	return r.interestRadius > 0 && !r.legacyMode
+}
+
+// interestView 玩家在该帧收到的状态（未开启兴趣管理时原样返回）
+func (r *Room) interestView(state *gamev1.GameState, playerID int32) *gamev1.GameState {
+	if !r.interestEnabled() {
+		return state
+	}
+	return filterInterest(state, playerID, r.interestRadius)
+}
+
+// filterInterest 只保留半径内的玩家（自己始终保留）、炸弹和爆炸；玩家不在状态中时原样返回。
+// 其余字段与原状态共用（只读）。校验和覆盖全部实体，过滤后不再附带
+func filterInterest(state *gamev1.GameState, playerID, radius int32) *gamev1.GameState {
+	var center core.GridPos
+	found := false
+	for _, p := range state.Players {
+		if p.Id == playerID {
+			center = core.PlayerXYToGrid(int(p.X), int(p.Y))
+			found = true
+			break
+		}
+	}
+	if !found {
+		return state
+	}
+	inRange := func(x, y int32) bool {
+		return abs32(x-int32(center.GridX)) <= radius && abs32(y-int32(center.GridY)) <= radius
+	}
+
+	// 新增 GameState 字段时需要在这里同步
+	view := &gamev1.GameState{
+		FrameId:            state.FrameId,
+		Phase:              state.Phase,
+		LastProcessedSeq:   state.LastProcessedSeq,
+		TileChanges:        state.TileChanges,
+		MatchEndFrame:      state.MatchEndFrame,
+		Items:              state.Items,
+		PlayerEffects:      state.PlayerEffects,
+		Hazards:            state.Hazards,
+		WarningTiles:       state.WarningTiles,
+		OvertimeFloodFrame: state.OvertimeFloodFrame,
+		Boss:               state.Boss,
+		InterestRadius:     radius,
+	}
+	for _, p := range state.Players {
+		cell := core.PlayerXYToGrid(int(p.X), int(p.Y))
+		if p.Id == playerID || inRange(int32(cell.GridX), int32(cell.GridY)) {
+			view.Players = append(view.Players, p)
+		}
+	}
+	for _, b := range state.Bombs {
+		if inRange(b.GridX, b.GridY) {
+			view.Bombs = append(view.Bombs, b)
+		}
+	}
+	for _, e := range state.Explosions {
+		for _, c := range e.Cells {
+			if inRange(c.X, c.Y) {
+				view.Explosions = append(view.Explosions, e)
+				break
+			}
+		}
+	}
+	return view
+}
+
+func abs32(v int32) int32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// entitiesOf 状态中的全部玩家、炸弹和爆炸
+func entitiesOf(state *gamev1.GameState) entitySet {
+	set := make(entitySet, len(state.Players)+len(state.Bombs)+len(state.Explosions))
+	for _, p := range state.Players {
+		set[entityKey{gamev1.EntityKind_ENTITY_KIND_PLAYER, p.Id}] = true
+	}
+	for _, b := range state.Bombs {
+		set[entityKey{gamev1.EntityKind_ENTITY_KIND_BOMB, b.Id}] = true
+	}
+	for _, e := range state.Explosions {
+		set[entityKey{gamev1.EntityKind_ENTITY_KIND_EXPLOSION, e.Id}] = true
+	}
+	return set
+}
+
+// interestStateDataFor 发给玩家的过滤后状态包：有可用基线时发送相对过滤后基线的增量，否则发送过滤后的完整状态。
+// 每名玩家的视野不同，增量不在玩家之间共用
+func (r *Room) interestStateDataFor(playerID int32, cur *gamev1.GameState) (data []byte, view *gamev1.GameState) {
+	view = filterInterest(cur, playerID, r.interestRadius)
+	if base := r.deltaBaseline(playerID); base != nil {
+		data, err := r.marshalDeltaState(filterInterest(base, playerID, r.interestRadius), view)
+		if err == nil {
+			return data, view
+		}
+		log.Printf("构造增量状态失败: %v", err)
+	}
+	packet, err := protocol.NewGameStatePacketFromState(view)
+	if err == nil {
+		data, err = protocol.MarshalPacket(packet)
+	}
+	if err != nil {
+		log.Printf("构造过滤后的状态失败: %v", err)
+		return nil, view
+	}
+	return data, view
+}
+
+// sendVisibilityChanges 比较玩家上一帧与本帧看到的实体，发送跨过范围边界的实体。
+// 新出现（上一帧不存在）或已消失（本帧不存在）的实体随状态增减，不算进入或离开
+func (r *Room) sendVisibilityChanges(conn Session, view *gamev1.GameState, all entitySet) {
+	playerID := conn.ID()
+	visible := entitiesOf(view)
+	prev, ok := r.interestVisible[playerID]
+	r.interestVisible[playerID] = visible
+	if !ok {
+		return
+	}
+
+	event := &gamev1.EntityVisibilityEvent{Radius: r.interestRadius}
+	for key := range visible {
+		if !prev[key] && r.interestAll[key] {
+			event.Entered = append(event.Entered, &gamev1.EntityRef{Kind: key.kind, Id: key.id})
+		}
+	}
+	for key := range prev {
+		if !visible[key] && all[key] {
+			event.Left = append(event.Left, &gamev1.EntityRef{Kind: key.kind, Id: key.id})
+		}
+	}
+	if len(event.Entered) == 0 && len(event.Left) == 0 {
+		return
+	}
+	sortEntityRefs(event.Entered)
+	sortEntityRefs(event.Left)
+	r.sendEvent(conn, &gamev1.GameEvent{
+		Event: &gamev1.GameEvent_EntityVisibility{EntityVisibility: event},
+	})
+}
+
+func sortEntityRefs(refs []*gamev1.EntityRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		return refs[i].Id < refs[j].Id
+	})
+}
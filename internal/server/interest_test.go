@@ -0,0 +1,99 @@
+package server
+
+import (
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const testInterestRadius = 2
+
+// observeInterest 按 broadcastState 的流程为玩家 1 计算本帧的视野变化，返回发送的可见性事件（没有发送时为 nil）
+func observeInterest(t *testing.T, r *Room) *gamev1.EntityVisibilityEvent {
+	t.Helper()
+	state := &gamev1.GameState{
+		FrameId:    r.frameID,
+		Players:    protocol.CorePlayersToProto(r.game.Players),
+		Bombs:      protocol.CoreBombsToProto(r.game.Bombs),
+		Explosions: protocol.CoreExplosionsToProto(r.game.Explosions),
+	}
+	all := entitiesOf(state)
+	conn := r.connections[1].(*fakeSession)
+	before := conn.sent
+	r.sendVisibilityChanges(conn, filterInterest(state, 1, r.interestRadius), all)
+	r.interestAll = all
+	r.frameID++
+	if conn.sent == before {
+		return nil
+	}
+
+	pkt, err := protocol.UnmarshalPacket(conn.last)
+	if err != nil {
+		t.Fatalf("解析数据包失败: %v", err)
+	}
+	event := &gamev1.GameEvent{}
+	if err := proto.Unmarshal(pkt.Payload, event); err != nil {
+		t.Fatalf("解析游戏事件失败: %v", err)
+	}
+	visibility := event.GetEntityVisibility()
+	if visibility == nil {
+		t.Fatalf("期望可见性事件，得到 %v", event)
+	}
+	return visibility
+}
+
+// newInterestRoom 开启兴趣管理的测试房间，返回玩家 1 所在的格子
+func newInterestRoom(t *testing.T) (*Room, core.GridPos) {
+	t.Helper()
+	r := newTestRoom(t, 0)
+	r.interestRadius = testInterestRadius
+	p := r.game.GetPlayer(1)
+	return r, core.PlayerXYToGrid(int(p.X), int(p.Y))
+}
+
+// TestVisibilityStableWhenEarlierBombExplodes 视野外的炸弹在前、视野内的炸弹在后，前者爆炸后不产生可见性事件
+func TestVisibilityStableWhenEarlierBombExplodes(t *testing.T) {
+	r, center := newInterestRoom(t)
+	far := core.NewBomb(center.GridX+testInterestRadius+3, center.GridY, 2, 0)
+	near := core.NewBomb(center.GridX+1, center.GridY, 2, 0)
+	r.game.AddBomb(far)
+	r.game.AddBomb(near)
+
+	if event := observeInterest(t, r); event != nil {
+		t.Fatalf("第一帧只记录视野，不应发送事件: %v", event)
+	}
+	// 视野外的炸弹消失（爆炸），视野内的炸弹留在原地
+	r.game.Bombs = []*core.Bomb{near}
+	if event := observeInterest(t, r); event != nil {
+		t.Errorf("视野内的炸弹没有变化，不应发送可见性事件: %v", event)
+	}
+}
+
+// TestVisibilityEnterAndLeaveUseStableIDs 炸弹跨过视野边界时按炸弹自己的 ID 发送进入/离开，与它在列表中的位置无关
+func TestVisibilityEnterAndLeaveUseStableIDs(t *testing.T) {
+	r, center := newInterestRoom(t)
+	first := core.NewBomb(center.GridX+testInterestRadius+3, center.GridY, 2, 0)
+	sliding := core.NewBomb(center.GridX+1, center.GridY, 2, 0)
+	r.game.AddBomb(first)
+	r.game.AddBomb(sliding)
+	observeInterest(t, r)
+
+	// 前一颗炸弹消失的同一帧，后一颗滑出视野
+	r.game.Bombs = []*core.Bomb{sliding}
+	sliding.GridX = center.GridX + testInterestRadius + 1
+	event := observeInterest(t, r)
+	if event == nil || len(event.Entered) != 0 || len(event.Left) != 1 || event.Left[0].Id != sliding.ID {
+		t.Fatalf("期望只有炸弹 %d 离开视野，得到 %v", sliding.ID, event)
+	}
+
+	// 滑回视野
+	sliding.GridX = center.GridX + 1
+	event = observeInterest(t, r)
+	if event == nil || len(event.Left) != 0 || len(event.Entered) != 1 || event.Entered[0].Id != sliding.ID {
+		t.Fatalf("期望只有炸弹 %d 进入视野，得到 %v", sliding.ID, event)
+	}
+}
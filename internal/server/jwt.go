@@ -1,16 +1,20 @@
 package server
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // JWT 相关配置
 const (
-	// Session 有效期：5 分钟（一局游戏的时间）
+	// Session 有效期：5 分钟（在线期间房间定期续期，见 session_refresh.go）
 	SessionTTL = 5 * time.Minute
 
 	// Token 签名者
@@ -35,7 +39,8 @@ func getSigningKey() []byte {
 	return []byte(secret)
 }
 
-// GenerateSessionToken 生成会话 Token
+// GenerateSessionToken 生成会话 Token（HMAC-SHA256 签名，包含玩家 ID、房间 ID 和过期时间；
+// 加入时签发，在线期间定期续期，每次重连成功后重新签发）
 func GenerateSessionToken(playerID int32, roomID string) (string, error) {
 	now := time.Now()
 	claims := Claims{
@@ -53,24 +58,26 @@ func GenerateSessionToken(playerID int32, roomID string) (string, error) {
 	return token.SignedString(getSigningKey())
 }
 
-// VerifySessionToken 验证并解析 Token
-// 返回 playerID 和 error
+// VerifySessionToken 验证并解析 Token：只接受本服务器用 HS256 签发、带有效期且未过期的令牌
+// 返回 playerID、roomID；失败时返回带错误码的错误（过期为 SESSION_EXPIRED，其余为 SESSION_INVALID）
 func VerifySessionToken(tokenString string) (int32, string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名算法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return getSigningKey(), nil
-	})
-
-	if err != nil {
-		return 0, "", fmt.Errorf("token parsing failed: %w", err)
+	if tokenString == "" {
+		return 0, "", newRoomError(gamev1.ErrorCode_ERROR_CODE_SESSION_INVALID, "缺少会话令牌")
 	}
-
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims.PlayerID, claims.RoomID, nil
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return getSigningKey(), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithExpirationRequired(),
+	)
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return 0, "", newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_SESSION_EXPIRED,
+			[]string{strconv.Itoa(int(SessionTTL / time.Second))}, "会话令牌已过期: %v", err)
+	case err != nil:
+		return 0, "", newRoomError(gamev1.ErrorCode_ERROR_CODE_SESSION_INVALID, "会话令牌无效: %v", err)
 	}
-
-	return 0, "", fmt.Errorf("invalid token")
+	return claims.PlayerID, claims.RoomID, nil
 }
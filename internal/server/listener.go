@@ -29,6 +29,8 @@ func newListener(proto, addr string) (ServerListener, error) {
 			return nil, err
 		}
 		return &kcpListener{listener: listener}, nil
+	case "ws":
+		return newWSListener(addr)
 	default:
 		return nil, fmt.Errorf("不支持的协议: %s", proto)
 	}
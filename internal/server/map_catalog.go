@@ -0,0 +1,280 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"bomberman/pkg/core"
+)
+
+// 社区地图目录限制
+const (
+	maxPendingMaps  = 100 // 待审核地图上限
+	maxApprovedMaps = 32  // 审核通过的地图上限（地图名随房间状态下发，受数据包大小限制）
+)
+
+// pendingMapsDir 地图目录下存放待审核地图的子目录
+const pendingMapsDir = "pending"
+
+// validMapName 地图名只能包含小写字母、数字、- 和 _（同时作为文件名，避免路径穿越）
+func validMapName(name string) bool {
+	if name == "" || len(name) > core.MaxMapNameLen {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// mapCatalog 社区地图目录：上传的地图先进入待审核队列，运维审核通过后才能在房间中选择
+//
+//	<maps-dir>/pending/<name>.json  待审核
+//	<maps-dir>/<name>.json          审核通过
+//	<maps-dir>/<name>.txt           运维手写的文本地图（见 core.ParseMapText），视为审核通过
+//
+// 与内置地图同名的社区地图不会加载，也不能上传（房间按名称选择地图时内置地图优先）
+//
+// nil 表示服务器未开启社区地图
+type mapCatalog struct {
+	dir      string
+	mu       sync.RWMutex // 保护 approved，并串行化目录写入
+	approved map[string]*core.MapDefinition
+}
+
+// newMapCatalog 创建地图目录并读取审核通过的地图（无效的地图文件跳过并记录日志）
+func newMapCatalog(dir string) *mapCatalog {
+	c := &mapCatalog{dir: dir, approved: make(map[string]*core.MapDefinition)}
+	if err := os.MkdirAll(filepath.Join(dir, pendingMapsDir), 0o755); err != nil {
+		log.Printf("创建地图目录失败: %v", err)
+	}
+	for _, name := range listMapFiles(dir) {
+		if len(c.approved) >= maxApprovedMaps {
+			log.Printf("审核通过的地图超过上限 %d 张，忽略 %s", maxApprovedMaps, name)
+			continue
+		}
+		if core.IsBuiltinMap(name) {
+			log.Printf("跳过地图 %s: 与内置地图同名", name)
+			continue
+		}
+		def, err := core.LoadMapFile(c.approvedFile(name))
+		if err == nil && def.Name != name {
+			err = errors.New("地图名与文件名不一致")
+		}
+		if err != nil {
+			log.Printf("跳过无效地图 %s: %v", name, err)
+			continue
+		}
+		c.approved[name] = def
+	}
+	log.Printf("已加载 %d 张社区地图: %s", len(c.approved), dir)
+	return c
+}
+
+// listMapFiles 列出目录中的地图名（JSON 与文本地图，按名称排序，同名只列一次）
+func listMapFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			name, ok = strings.CutSuffix(entry.Name(), ".txt")
+		}
+		if ok && !entry.IsDir() && validMapName(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *mapCatalog) pendingPath(name string) string {
+	return filepath.Join(c.dir, pendingMapsDir, name+".json")
+}
+
+func (c *mapCatalog) approvedPath(name string) string {
+	return filepath.Join(c.dir, name+".json")
+}
+
+// approvedFile 审核通过的地图文件：优先 JSON，不存在时使用同名的文本地图
+func (c *mapCatalog) approvedFile(name string) string {
+	path := c.approvedPath(name)
+	if _, err := os.Stat(path); err != nil {
+		return filepath.Join(c.dir, name+".txt")
+	}
+	return path
+}
+
+// checkMap 上传和审核共用的内容校验：尺寸、字符、连通性和出生点公平性
+func checkMap(name string, data []byte) (*core.MapDefinition, error) {
+	if !validMapName(name) {
+		return nil, fmt.Errorf("地图名无效（1-%d 个小写字母、数字、- 或 _）", core.MaxMapNameLen)
+	}
+	if core.IsBuiltinMap(name) {
+		return nil, fmt.Errorf("地图名 %s 与内置地图重复", name)
+	}
+	def, err := core.ParseMapDefinition(data)
+	if err == nil {
+		err = def.CheckFairness()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("地图无效: %w", err)
+	}
+	if def.Name != name {
+		return nil, errors.New("地图名与地图定义不一致")
+	}
+	return def, nil
+}
+
+// Submit 校验上传的地图并放入待审核队列（不覆盖已有的同名地图）
+func (c *mapCatalog) Submit(name string, data []byte) error {
+	if c == nil {
+		return errors.New("服务器未开启地图上传")
+	}
+	if _, err := checkMap(name, data); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.approved[name]; ok {
+		return fmt.Errorf("地图 %s 已存在", name)
+	}
+	if len(listMapFiles(filepath.Join(c.dir, pendingMapsDir))) >= maxPendingMaps {
+		return fmt.Errorf("待审核的地图已满（上限 %d 张）", maxPendingMaps)
+	}
+
+	path := c.pendingPath(name)
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("地图 %s 已在审核中", name)
+		}
+		return errors.New("服务器无法保存地图")
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(path)
+		return errors.New("服务器无法保存地图")
+	}
+	return file.Close()
+}
+
+// Pending 待审核的地图名
+func (c *mapCatalog) Pending() []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return listMapFiles(filepath.Join(c.dir, pendingMapsDir))
+}
+
+// Names 审核通过的地图名（按名称排序）
+func (c *mapCatalog) Names() []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, 0, len(c.approved))
+	for name := range c.approved {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get 审核通过的地图定义（不存在时返回 nil，调用方不能修改返回值）
+func (c *mapCatalog) Get(name string) *core.MapDefinition {
+	if c == nil {
+		return nil
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.approved[name]
+}
+
+// Approve 审核通过：重新校验后从待审核队列移到地图目录，之后可以在房间中选择
+func (c *mapCatalog) Approve(name string) error {
+	if c == nil {
+		return errors.New("服务器未开启社区地图")
+	}
+	if !validMapName(name) {
+		return fmt.Errorf("地图名无效: %q", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	data, err := os.ReadFile(c.pendingPath(name))
+	if err != nil {
+		return fmt.Errorf("待审核队列中没有地图 %s", name)
+	}
+	def, err := checkMap(name, data)
+	if err != nil {
+		return err
+	}
+	if _, ok := c.approved[name]; ok {
+		return fmt.Errorf("地图 %s 已存在", name)
+	}
+	if len(c.approved) >= maxApprovedMaps {
+		return fmt.Errorf("审核通过的地图已满（上限 %d 张），先用 remove 下架旧地图", maxApprovedMaps)
+	}
+	if err := os.Rename(c.pendingPath(name), c.approvedPath(name)); err != nil {
+		return fmt.Errorf("移动地图文件失败: %w", err)
+	}
+	c.approved[name] = def
+	return nil
+}
+
+// Reject 拒绝：删除待审核的地图
+func (c *mapCatalog) Reject(name string) error {
+	if c == nil {
+		return errors.New("服务器未开启社区地图")
+	}
+	if !validMapName(name) {
+		return fmt.Errorf("地图名无效: %q", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Remove(c.pendingPath(name)); err != nil {
+		return fmt.Errorf("待审核队列中没有地图 %s", name)
+	}
+	return nil
+}
+
+// Remove 下架审核通过的地图（已选择该地图的房间保留自己的副本，直到房主换图）
+func (c *mapCatalog) Remove(name string) error {
+	if c == nil {
+		return errors.New("服务器未开启社区地图")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.approved[name]; !ok {
+		return fmt.Errorf("没有审核通过的地图 %s", name)
+	}
+	if err := os.Remove(c.approvedFile(name)); err != nil {
+		return fmt.Errorf("删除地图文件失败: %w", err)
+	}
+	delete(c.approved, name)
+	return nil
+}
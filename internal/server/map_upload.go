@@ -0,0 +1,31 @@
+package server
+
+import (
+	"log"
+
+	"bomberman/pkg/protocol"
+)
+
+// handleMapUpload 处理地图上传：校验后放入待审核队列（见 mapCatalog）
+func (s *GameServer) handleMapUpload(conn Session, req *MapUploadEvent) {
+	if req == nil {
+		return
+	}
+	errMsg := ""
+	if err := s.maps.Submit(req.Name, req.MapJSON); err != nil {
+		log.Printf("地图上传被拒绝: %q: %v", req.Name, err)
+		errMsg = err.Error()
+	} else {
+		log.Printf("收到上传的地图，等待审核: %s（-admin 下输入 approve %s 通过）", req.Name, req.Name)
+	}
+
+	packet, err := protocol.NewMapUploadResponsePacket(errMsg == "", errMsg, req.Name)
+	if err != nil {
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return
+	}
+	_ = conn.Send(data)
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"bomberman/pkg/core"
+)
+
+// MatchStatsEntry 某张地图、某个 AI 难度下 AI 与真人对局的胜负统计
+type MatchStatsEntry struct {
+	Map        string `json:"map"`
+	Difficulty string `json:"difficulty"`
+	Games      int    `json:"games"`
+	AIWins     int    `json:"ai_wins"`
+	HumanWins  int    `json:"human_wins"`
+	Draws      int    `json:"draws"`
+}
+
+// AIWinRate AI 胜率（平局不计入分母）
+func (e MatchStatsEntry) AIWinRate() float64 {
+	decided := e.AIWins + e.HumanWins
+	if decided == 0 {
+		return 0
+	}
+	return float64(e.AIWins) / float64(decided)
+}
+
+// matchOutcome 一局的结果（从 AI 一方看）
+type matchOutcome int
+
+const (
+	outcomeDraw matchOutcome = iota
+	outcomeAIWin
+	outcomeHumanWin
+)
+
+// MatchStats AI 与真人对局统计，path 非空时每局结束后写回文件
+// 房间 goroutine 记录、运维控制台读取，内部加锁
+type MatchStats struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]*MatchStatsEntry // map + "/" + difficulty -> 统计
+}
+
+// LoadMatchStats 读取统计文件，文件不存在时从零开始（path 为空表示只在内存中统计）
+func LoadMatchStats(path string) (*MatchStats, error) {
+	s := &MatchStats{path: path, entries: make(map[string]*MatchStatsEntry)}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	var entries []MatchStatsEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return s, err
+	}
+	for i := range entries {
+		entry := entries[i]
+		s.entries[entry.Map+"/"+entry.Difficulty] = &entry
+	}
+	return s, nil
+}
+
+// record 记录一局：每个参赛 AI 难度各计一次
+func (s *MatchStats) record(mapName string, difficulties []string, outcome matchOutcome) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, difficulty := range difficulties {
+		key := mapName + "/" + difficulty
+		entry, ok := s.entries[key]
+		if !ok {
+			entry = &MatchStatsEntry{Map: mapName, Difficulty: difficulty}
+			s.entries[key] = entry
+		}
+		entry.Games++
+		switch outcome {
+		case outcomeAIWin:
+			entry.AIWins++
+		case outcomeHumanWin:
+			entry.HumanWins++
+		default:
+			entry.Draws++
+		}
+	}
+	return s.saveLocked()
+}
+
+// Entries 按地图、难度排序的统计快照
+func (s *MatchStats) Entries() []MatchStatsEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedLocked()
+}
+
+func (s *MatchStats) sortedLocked() []MatchStatsEntry {
+	entries := make([]MatchStatsEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Map != entries[j].Map {
+			return entries[i].Map < entries[j].Map
+		}
+		return entries[i].Difficulty < entries[j].Difficulty
+	})
+	return entries
+}
+
+// saveLocked 写回文件（先写临时文件再改名，避免中途退出留下半个文件）
+func (s *MatchStats) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s.sortedLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// recordMatchStats 对局结束时记录 AI 与真人的胜负（只统计同时有真人和行为树 AI 的对局）
+func (r *Room) recordMatchStats(winnerID int32) {
+	if r.matchStats == nil {
+		return
+	}
+
+	humans := 0
+	seen := make(map[string]bool)
+	var difficulties []string
+	for _, player := range r.game.Players {
+		controller, isAI := r.aiControllers[int32(player.ID)]
+		if !isAI {
+			humans++
+			continue
+		}
+		difficulty := controller.Difficulty().String()
+		if difficulty == "" || seen[difficulty] {
+			continue // 脚本 AI 不计入，同一难度只计一次
+		}
+		seen[difficulty] = true
+		difficulties = append(difficulties, difficulty)
+	}
+	if humans == 0 || len(difficulties) == 0 {
+		return
+	}
+
+	outcome := outcomeDraw
+	if winnerID > 0 {
+		outcome = outcomeHumanWin
+		if _, isAI := r.aiControllers[winnerID]; isAI {
+			outcome = outcomeAIWin
+		}
+	}
+	if err := r.matchStats.record(core.MapID(r.mapLayout(), r.game.Rules), difficulties, outcome); err != nil {
+		log.Printf("保存对局统计失败: %v", err)
+	}
+}
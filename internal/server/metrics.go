@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 运维指标：HTTP 端点以 Prometheus 文本格式输出房间数、连接数、tick 耗时分位数、
+// 发送队列满次数和每个房间的帧延迟。房间只在自己的 goroutine 中写指标，
+// 导出 goroutine 定期通过 RoomManager.GetRoomStats 汇总，请求直接返回最近一次的快照
+
+const (
+	MetricsPath     = "/metrics"
+	metricsInterval = 5 * time.Second
+	tickSampleSize  = 600 // 每个房间保留最近的 tick 耗时样本数（60 TPS 下约 10 秒）
+)
+
+// serverMetrics 服务器级计数器
+type serverMetrics struct {
+	connections   atomic.Int64 // 当前连接数（含大厅中未加入房间的连接）
+	sendQueueFull atomic.Int64 // 发送队列满的累计次数
+	desyncReports atomic.Int64 // 客户端上报的不同步累计次数
+	chaosDropped  atomic.Int64 // 故障注入丢弃的出站包累计数（chaos.go）
+
+	mu       sync.Mutex
+	snapshot []byte // 最近一次导出的指标文本
+}
+
+// roomMetrics 房间指标（房间 goroutine 写入，导出 goroutine 读取）
+type roomMetrics struct {
+	mu    sync.Mutex
+	ticks [tickSampleSize]time.Duration
+	next  int
+	count int
+
+	frameLag atomic.Int32 // 按墙钟应执行的帧数与实际帧数之差（tick 过慢时被丢弃的帧）
+
+	// 以下只在房间 goroutine 中访问
+	lagBase      time.Time
+	lagBaseFrame int32
+}
+
+func (m *roomMetrics) recordTick(d time.Duration) {
+	m.mu.Lock()
+	m.ticks[m.next] = d
+	m.next = (m.next + 1) % tickSampleSize
+	if m.count < tickSampleSize {
+		m.count++
+	}
+	m.mu.Unlock()
+}
+
+func (m *roomMetrics) tickSamples() []time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	samples := make([]time.Duration, m.count)
+	copy(samples, m.ticks[:m.count])
+	return samples
+}
+
+// observeTick 记录一次 tick 的耗时并更新帧延迟（房间 goroutine 调用）
+func (r *Room) observeTick(start time.Time) {
+	now := time.Now()
+	r.metrics.recordTick(now.Sub(start))
+
+	if r.state != StateRunning {
+		r.metrics.lagBase = time.Time{}
+		r.metrics.frameLag.Store(0)
+		return
+	}
+	if r.metrics.lagBase.IsZero() {
+		r.metrics.lagBase, r.metrics.lagBaseFrame = now, r.frameID
+		return
+	}
+	expected := int32(now.Sub(r.metrics.lagBase) / TickDuration)
+	r.metrics.frameLag.Store(max(expected-(r.frameID-r.metrics.lagBaseFrame), 0))
+}
+
+// percentile 已排序样本的分位数
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(q*float64(len(sorted)-1))]
+}
+
+// renderMetrics 汇总房间统计，生成 Prometheus 文本格式
+func (s *GameServer) renderMetrics() []byte {
+	var stats map[string]RoomStats
+	if s.roomManager != nil {
+		stats = s.roomManager.GetRoomStats()
+	}
+	ids := make([]string, 0, len(stats))
+	players := 0
+	var ticks []time.Duration
+	for id, st := range stats {
+		ids = append(ids, id)
+		players += st.PlayerCount
+		ticks = append(ticks, st.TickDurations...)
+	}
+	sort.Strings(ids)
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i] < ticks[j] })
+
+	var b strings.Builder
+	gauge := func(name, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+	}
+	gauge("bomberman_rooms", "当前房间数")
+	fmt.Fprintf(&b, "bomberman_rooms %d\n", len(ids))
+	gauge("bomberman_connections", "当前连接数（含大厅中的连接）")
+	fmt.Fprintf(&b, "bomberman_connections %d\n", s.metrics.connections.Load())
+	gauge("bomberman_room_players", "房间中的连接数")
+	fmt.Fprintf(&b, "bomberman_room_players %d\n", players)
+
+	fmt.Fprintf(&b, "# HELP bomberman_tick_duration_seconds 房间 tick 耗时（所有房间最近 %d 个 tick）\n", tickSampleSize)
+	fmt.Fprintf(&b, "# TYPE bomberman_tick_duration_seconds summary\n")
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		fmt.Fprintf(&b, "bomberman_tick_duration_seconds{quantile=\"%g\"} %g\n", q, percentile(ticks, q).Seconds())
+	}
+	fmt.Fprintf(&b, "bomberman_tick_duration_seconds_count %d\n", len(ticks))
+
+	fmt.Fprintf(&b, "# HELP bomberman_send_queue_full_total 发送队列满的累计次数\n# TYPE bomberman_send_queue_full_total counter\n")
+	fmt.Fprintf(&b, "bomberman_send_queue_full_total %d\n", s.metrics.sendQueueFull.Load())
+
+	fmt.Fprintf(&b, "# HELP bomberman_desync_reports_total 客户端上报状态校验和不一致的累计次数\n# TYPE bomberman_desync_reports_total counter\n")
+	fmt.Fprintf(&b, "bomberman_desync_reports_total %d\n", s.metrics.desyncReports.Load())
+
+	if s.chaos != nil {
+		fmt.Fprintf(&b, "# HELP bomberman_chaos_dropped_total 故障注入丢弃的出站包累计数\n# TYPE bomberman_chaos_dropped_total counter\n")
+		fmt.Fprintf(&b, "bomberman_chaos_dropped_total %d\n", s.metrics.chaosDropped.Load())
+	}
+
+	gauge("bomberman_room_frame_lag", "房间落后墙钟的帧数（只统计游戏中的房间）")
+	for _, id := range ids {
+		fmt.Fprintf(&b, "bomberman_room_frame_lag{room=%q} %d\n", id, stats[id].FrameLag)
+	}
+
+	listeners := s.ListenerStats()
+	gauge("bomberman_listener_connections", "每个监听器的当前连接数")
+	for _, l := range listeners {
+		fmt.Fprintf(&b, "bomberman_listener_connections{listener=%q,proto=%q} %d\n", l.Name, l.Proto, l.Active)
+	}
+	fmt.Fprintf(&b, "# HELP bomberman_listener_accepted_total 每个监听器累计接受的连接数\n# TYPE bomberman_listener_accepted_total counter\n")
+	for _, l := range listeners {
+		fmt.Fprintf(&b, "bomberman_listener_accepted_total{listener=%q,proto=%q} %d\n", l.Name, l.Proto, l.Accepted)
+	}
+	gauge("bomberman_listener_up", "监听器是否仍在接受新连接")
+	for _, l := range listeners {
+		up := 1
+		if l.Stopped {
+			up = 0
+		}
+		fmt.Fprintf(&b, "bomberman_listener_up{listener=%q,proto=%q} %d\n", l.Name, l.Proto, up)
+	}
+	return []byte(b.String())
+}
+
+// runMetricsExporter 定期刷新指标快照，并在 addr 上提供 HTTP 端点（随服务器关闭）
+func (s *GameServer) runMetricsExporter(addr string) {
+	defer s.wg.Done()
+
+	refresh := func() {
+		data := s.renderMetrics()
+		s.metrics.mu.Lock()
+		s.metrics.snapshot = data
+		s.metrics.mu.Unlock()
+	}
+	refresh()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(MetricsPath, func(w http.ResponseWriter, _ *http.Request) {
+		s.metrics.mu.Lock()
+		data := s.metrics.snapshot
+		s.metrics.mu.Unlock()
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write(data)
+	})
+	if s.reports != nil && s.reportsToken != "" {
+		s.handleReports(mux)
+	}
+	httpServer := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		log.Printf("指标端点: http://%s%s", addr, MetricsPath)
+		if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("指标端点启动失败: %v", err)
+		}
+	}()
+
+	ticker := time.NewTicker(metricsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			_ = httpServer.Shutdown(ctx)
+			cancel()
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
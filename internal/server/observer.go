@@ -0,0 +1,99 @@
+package server
+
+import (
+	"fmt"
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 运维观察者：接收与观战者相同的广播，但不占观战名额、不出现在房间信息中，
+// 由管理控制台挂载，用于排查线上问题
+
+type observeRequest struct {
+	conn   Session
+	attach bool
+	respCh chan error
+}
+
+type snapshotRequest struct {
+	respCh chan *gamev1.GameState
+}
+
+// AttachObserver 挂载运维观察者
+func (r *Room) AttachObserver(conn Session) error {
+	return r.sendObserveRequest(observeRequest{conn: conn, attach: true, respCh: make(chan error, 1)})
+}
+
+// DetachObserver 卸载运维观察者
+func (r *Room) DetachObserver(conn Session) error {
+	return r.sendObserveRequest(observeRequest{conn: conn, attach: false, respCh: make(chan error, 1)})
+}
+
+func (r *Room) sendObserveRequest(req observeRequest) error {
+	select {
+	case <-r.ctx.Done():
+		return errRoomClosed
+	case r.observeCh <- req:
+	}
+
+	select {
+	case <-r.ctx.Done():
+		return errRoomClosed
+	case err := <-req.respCh:
+		return err
+	}
+}
+
+func (r *Room) handleObserve(req observeRequest) {
+	id := req.conn.ID()
+	if !req.attach {
+		if _, ok := r.observers[id]; !ok {
+			req.respCh <- fmt.Errorf("观察者 %d 不在房间 %s", id, r.id)
+			return
+		}
+		delete(r.observers, id)
+		log.Printf("房间 %s 卸载运维观察者 %d", r.id, id)
+		req.respCh <- nil
+		return
+	}
+
+	if _, ok := r.observers[id]; ok {
+		req.respCh <- fmt.Errorf("观察者 %d 已在房间 %s", id, r.id)
+		return
+	}
+	req.conn.SetRoomID(r.id)
+	r.observers[id] = req.conn
+	log.Printf("房间 %s 挂载运维观察者 %d", r.id, id)
+	req.respCh <- nil
+}
+
+// Snapshot 当前完整游戏状态（含开局以来的全部地图变化）
+func (r *Room) Snapshot() (*gamev1.GameState, error) {
+	req := snapshotRequest{respCh: make(chan *gamev1.GameState, 1)}
+	select {
+	case <-r.ctx.Done():
+		return nil, errRoomClosed
+	case r.snapshotCh <- req:
+	}
+
+	select {
+	case <-r.ctx.Done():
+		return nil, errRoomClosed
+	case state := <-req.respCh:
+		return state, nil
+	}
+}
+
+func (r *Room) handleSnapshot(req snapshotRequest) {
+	state := r.BuildGameState()
+	state.TileChanges = r.mapDiffTileChanges()
+	req.respCh <- state
+}
+
+// closeObservers 房间关闭时通知观察者（房间重置不影响观察者）
+func (r *Room) closeObservers() {
+	for _, conn := range r.observers {
+		conn.CloseWithoutNotify()
+	}
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+	"log"
+	"time"
+)
+
+// pausedForOffline 对局中真人玩家全部断线（都在离线保护中）时冻结整个游戏循环：
+// AI 不再行动、帧号和对局计时不再前进、不做胜负判定，免得 AI 在没人的时候把对局打完。
+// 有人重连或离线保护全部超时（玩家被移除）后恢复
+func (r *Room) pausedForOffline(now time.Time) bool {
+	paused := len(r.connections) == 0 && len(r.offlinePlayers) > 0
+	switch {
+	case paused && r.offlinePausedAt.IsZero():
+		r.offlinePausedAt = now
+		log.Printf("房间 %s 真人玩家全部断线，对局暂停在帧 %d", r.id, r.frameID)
+	case !paused && !r.offlinePausedAt.IsZero():
+		log.Printf("房间 %s 对局恢复（暂停 %v）", r.id, now.Sub(r.offlinePausedAt).Truncate(time.Second))
+		r.offlinePausedAt = time.Time{}
+	}
+	return paused
+}
+
+// expireOfflinePlayers 清理离线保护超时的玩家（对局暂停期间照常计时）
+func (r *Room) expireOfflinePlayers() {
+	for playerID, disconnectTime := range r.offlinePlayers {
+		if time.Since(disconnectTime) > r.offlineTimeout {
+			log.Printf("玩家 %d 离线超时 (%v)，强制移除", playerID, r.offlineTimeout)
+			r.handleForceLeave(playerID)
+		}
+	}
+}
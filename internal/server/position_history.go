@@ -0,0 +1,92 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"bomberman/pkg/core"
+)
+
+const (
+	// PositionHistoryFrames 位置历史保留帧数（2 秒）
+	PositionHistoryFrames = 2 * core.TPS
+	// maxLagCompensationFrames 延迟补偿最多回溯的帧数（200ms）
+	maxLagCompensationFrames = core.TPS / 5
+)
+
+// positionSample 某一帧的玩家权威位置
+type positionSample struct {
+	x, y float64
+	dead bool
+}
+
+// grid 玩家中心点所在格子（与 core 伤害判定一致）
+func (s positionSample) grid() core.GridPos {
+	centerX := s.x + float64(core.PlayerWidth)/2
+	centerY := s.y + float64(core.PlayerHeight)/2
+	return core.GridPos{GridX: int(centerX) / core.TileSize, GridY: int(centerY) / core.TileSize}
+}
+
+// positionHistory 按帧记录玩家位置的环形缓冲区，只在房间 goroutine 中访问
+type positionHistory struct {
+	frames  [PositionHistoryFrames]int32
+	samples [PositionHistoryFrames]map[int32]positionSample
+}
+
+func newPositionHistory() *positionHistory {
+	h := &positionHistory{}
+	h.reset()
+	return h
+}
+
+func (h *positionHistory) reset() {
+	for i := range h.frames {
+		h.frames[i] = -1
+		h.samples[i] = make(map[int32]positionSample, MaxPlayers)
+	}
+}
+
+// record 记录本帧所有玩家的位置
+func (h *positionHistory) record(frame int32, players []*core.Player) {
+	slot := int(frame) % PositionHistoryFrames
+	h.frames[slot] = frame
+	samples := h.samples[slot]
+	clear(samples)
+	for _, p := range players {
+		samples[int32(p.ID)] = positionSample{x: p.X, y: p.Y, dead: p.Dead}
+	}
+}
+
+// at 查询玩家在某一帧的位置（超出缓冲区范围返回 false）
+func (h *positionHistory) at(playerID int32, frame int32) (positionSample, bool) {
+	if frame < 0 {
+		return positionSample{}, false
+	}
+	slot := int(frame) % PositionHistoryFrames
+	if h.frames[slot] != frame {
+		return positionSample{}, false
+	}
+	sample, ok := h.samples[slot][playerID]
+	return sample, ok
+}
+
+// describe 输出玩家在 [from, to] 帧的位置轨迹，用于排查判定争议
+func (h *positionHistory) describe(playerID int32, from, to int32) string {
+	var sb strings.Builder
+	for frame := from; frame <= to; frame++ {
+		sample, ok := h.at(playerID, frame)
+		if !ok {
+			continue
+		}
+		g := sample.grid()
+		fmt.Fprintf(&sb, " %d:(%.1f,%.1f)[%d,%d]", frame, sample.x, sample.y, g.GridX, g.GridY)
+	}
+	return sb.String()
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,331 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// 玩家偏好：客户端加入时携带账号密钥（JoinRequest.account_key），服务器按密钥的 SHA-256 保存
+// 角色、控制方案提示和房主的规则预设，换设备用同一个密钥加入即可恢复。不保存密钥本身
+
+const (
+	MinAccountKeyLength = 8  // 账号密钥最短长度（太短容易被猜中）
+	MaxAccountKeyLength = 64 // 账号密钥最长长度
+
+	MaxProfiles          = 10000           // 保存的账号数上限，超出时淘汰最久没有更新的账号
+	profileFlushInterval = 2 * time.Second // 修改后等待多久写回文件（期间的修改合并为一次写入）
+)
+
+// PlayerProfile 账号保存的偏好（nil 字段表示没有保存过）
+type PlayerProfile struct {
+	Character gamev1.CharacterType `json:"character"`
+	Control   string               `json:"control,omitempty"`
+	Map       string               `json:"map,omitempty"`
+	Rules     *core.GameRules      `json:"rules,omitempty"`
+	Config    *core.MatchConfig    `json:"config,omitempty"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// ProfileStore 按账号保存的偏好，path 为空时只保存在内存中
+// 连接 goroutine（加入、修改偏好）和房间 goroutine（房主修改规则）都会访问，内部加锁。
+// 修改只改内存并通知后台写入 goroutine（RunWriter），调用方不会等待磁盘
+type ProfileStore struct {
+	mu       sync.Mutex
+	path     string
+	profiles map[string]*PlayerProfile // 账号 ID（密钥的 SHA-256）-> 偏好
+
+	limit         int           // 账号数上限（MaxProfiles）
+	flushInterval time.Duration // 合并写入的等待时间（profileFlushInterval）
+	dirty         chan struct{} // 有尚未写回的修改（容量 1，多次修改只通知一次）
+}
+
+// LoadProfileStore 读取偏好文件，文件不存在时从空开始
+func LoadProfileStore(path string) (*ProfileStore, error) {
+	s := &ProfileStore{
+		path:          path,
+		profiles:      make(map[string]*PlayerProfile),
+		limit:         MaxProfiles,
+		flushInterval: profileFlushInterval,
+		dirty:         make(chan struct{}, 1),
+	}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err := json.Unmarshal(data, &s.profiles); err != nil {
+		return s, err
+	}
+	// 上限调低后，文件中多出的账号按最久没有更新的顺序淘汰
+	for len(s.profiles) > s.limit {
+		s.evictOldestLocked()
+	}
+	return s, nil
+}
+
+// RunWriter 启动后台写入 goroutine：收到修改通知后等待 flushInterval 合并，再整体写回文件；
+// ctx 取消时写入剩余的修改后退出（path 为空时不启动）
+func (s *ProfileStore) RunWriter(ctx context.Context, wg *sync.WaitGroup) {
+	if s.path == "" {
+		return
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				select {
+				case <-s.dirty:
+					s.flush()
+				default:
+				}
+				return
+			case <-s.dirty:
+			}
+
+			timer := time.NewTimer(s.flushInterval)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+			s.flush()
+		}
+	}()
+}
+
+// accountID 校验账号密钥并返回账号 ID
+func accountID(key string) (string, error) {
+	if len(key) < MinAccountKeyLength || len(key) > MaxAccountKeyLength {
+		return "", newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_INVALID_ACCOUNT_KEY,
+			[]string{strconv.Itoa(MinAccountKeyLength), strconv.Itoa(MaxAccountKeyLength)},
+			"账号密钥长度必须在 %d-%d 之间", MinAccountKeyLength, MaxAccountKeyLength)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get 账号保存的偏好（没有保存过时返回 false）
+func (s *ProfileStore) Get(account string) (PlayerProfile, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.profiles[account]
+	if !ok {
+		return PlayerProfile{}, false
+	}
+	return *profile, true
+}
+
+// Update 修改账号的偏好，返回修改后的偏好。只修改内存，文件由后台写入 goroutine 稍后写回
+// （写文件失败只记日志，内存中的偏好仍然生效）。新账号超出上限时淘汰最久没有更新的账号
+func (s *ProfileStore) Update(account string, change func(p *PlayerProfile)) PlayerProfile {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	profile, ok := s.profiles[account]
+	if !ok {
+		for len(s.profiles) >= s.limit {
+			s.evictOldestLocked()
+		}
+		profile = &PlayerProfile{}
+		s.profiles[account] = profile
+	}
+	change(profile)
+	profile.UpdatedAt = time.Now()
+	select {
+	case s.dirty <- struct{}{}:
+	default:
+	}
+	return *profile
+}
+
+// evictOldestLocked 删除最久没有更新的账号
+func (s *ProfileStore) evictOldestLocked() {
+	var oldest string
+	for account, profile := range s.profiles {
+		if oldest == "" || profile.UpdatedAt.Before(s.profiles[oldest].UpdatedAt) {
+			oldest = account
+		}
+	}
+	delete(s.profiles, oldest)
+}
+
+// flush 把当前的偏好写回文件（先写临时文件再改名，避免中途退出留下半个文件）。
+// 只在复制时持有锁，序列化和磁盘 I/O 不阻塞修改
+func (s *ProfileStore) flush() {
+	s.mu.Lock()
+	snapshot := make(map[string]PlayerProfile, len(s.profiles))
+	for account, profile := range s.profiles {
+		snapshot[account] = *profile
+	}
+	s.mu.Unlock()
+
+	if err := writeProfiles(s.path, snapshot); err != nil {
+		log.Printf("保存玩家偏好失败: %v", err)
+	}
+}
+
+func writeProfiles(path string, profiles map[string]PlayerProfile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// merge 用请求中设置了的字段覆盖偏好（角色 UNSPECIFIED、空字符串和 nil 表示不修改）
+func (p *PlayerProfile) merge(update *gamev1.PlayerProfile) {
+	if update == nil {
+		return
+	}
+	if update.Character != gamev1.CharacterType_CHARACTER_TYPE_UNSPECIFIED {
+		p.Character = update.Character
+	}
+	if update.Control != "" {
+		p.Control = update.Control
+	}
+	if update.Map != "" {
+		p.Map = update.Map
+	}
+	if update.Rules != nil {
+		rules := protocol.ProtoRulesToCore(update.Rules)
+		p.Rules = &rules
+	}
+	if update.MatchConfig != nil {
+		config := protocol.ProtoMatchConfigToCore(update.MatchConfig)
+		p.Config = &config
+	}
+}
+
+// toProto 转换为协议消息
+func (p PlayerProfile) toProto() *gamev1.PlayerProfile {
+	msg := &gamev1.PlayerProfile{
+		Character: p.Character,
+		Control:   p.Control,
+		Map:       p.Map,
+	}
+	if p.Rules != nil {
+		msg.Rules = protocol.CoreRulesToProto(*p.Rules)
+	}
+	if p.Config != nil {
+		msg.MatchConfig = protocol.CoreMatchConfigToProto(*p.Config)
+	}
+	return msg
+}
+
+// resolveJoinProfile 加入时处理账号：未指定角色时使用保存的角色，指定了则保存为新的偏好。
+// 没有携带密钥时不做任何事
+func (s *GameServer) resolveJoinProfile(req *JoinEvent) error {
+	if req.AccountKey == "" || s.profiles == nil {
+		return nil
+	}
+	account, err := accountID(req.AccountKey)
+	if err != nil {
+		return err
+	}
+	req.Account = account
+
+	var profile PlayerProfile
+	if req.Character == gamev1.CharacterType_CHARACTER_TYPE_UNSPECIFIED {
+		profile, _ = s.profiles.Get(account)
+		req.Character = profile.Character
+	} else {
+		profile = s.profiles.Update(account, func(p *PlayerProfile) { p.Character = req.Character })
+	}
+	req.Profile = profile.toProto()
+	return nil
+}
+
+// handleProfileUpdate 修改账号保存的偏好（无需加入房间）
+func (s *GameServer) handleProfileUpdate(conn Session, req *ProfileUpdateEvent) {
+	if req == nil || s.profiles == nil {
+		return
+	}
+	var packet *gamev1.Packet
+	account, err := accountID(req.AccountKey)
+	if err != nil {
+		packet, err = protocol.NewProfileUpdateResponsePacket(false, errorCodeOf(err), errorParamsOf(err), err.Error(), nil)
+	} else {
+		profile := s.profiles.Update(account, func(p *PlayerProfile) { p.merge(req.Profile) })
+		packet, err = protocol.NewProfileUpdateResponsePacket(true, gamev1.ErrorCode_ERROR_CODE_UNSPECIFIED, nil, "", profile.toProto())
+	}
+	if err != nil {
+		log.Printf("构造偏好修改响应失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化偏好修改响应失败: %v", err)
+		return
+	}
+	_ = conn.Send(data)
+}
+
+// applyProfilePreset 新建房间的第一位房主：按其账号保存的预设设置地图、规则和对局参数
+func (r *Room) applyProfilePreset(playerID int32) {
+	account := r.playerAccounts[playerID]
+	if account == "" || r.profiles == nil {
+		return
+	}
+	profile, ok := r.profiles.Get(account)
+	if !ok {
+		return
+	}
+	if profile.Rules != nil {
+		// 与房主修改规则一致：开启公平种子时换一个新种子重新承诺
+		reroll := profile.Rules.FairSeed && !r.rules.FairSeed
+		r.rules = *profile.Rules
+		if reroll {
+			r.rerollSeed()
+		}
+	}
+	if profile.Config != nil {
+		r.config = profile.Config.Clamp()
+	}
+	if profile.Map != "" {
+		if err := r.setMap(playerID, profile.Map); err != nil {
+			log.Printf("房间 %s 无法使用玩家 %d 的地图预设 %q: %v", r.id, playerID, profile.Map, err)
+		}
+	}
+	log.Printf("房间 %s 使用房主 %d 保存的规则预设", r.id, playerID)
+}
+
+// saveHostPreset 房主修改地图、规则或对局参数后保存为其账号的预设
+func (r *Room) saveHostPreset() {
+	account := r.playerAccounts[r.hostID]
+	if account == "" || r.profiles == nil {
+		return
+	}
+	rules, config, mapName := r.rules, r.config, r.selectedMapLayout()
+	r.profiles.Update(account, func(p *PlayerProfile) {
+		p.Rules = &rules
+		p.Config = &config
+		p.Map = mapName
+	})
+}
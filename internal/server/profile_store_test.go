@@ -0,0 +1,117 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// TestProfileStoreWritesInBackground 修改不直接写文件，由后台写入 goroutine 合并后写回，关闭时写入剩余的修改
+func TestProfileStoreWritesInBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	s, err := LoadProfileStore(path)
+	if err != nil {
+		t.Fatalf("读取偏好失败: %v", err)
+	}
+	s.flushInterval = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	s.RunWriter(ctx, &wg)
+
+	s.Update("a", func(p *PlayerProfile) { p.Character = gamev1.CharacterType_CHARACTER_TYPE_RED })
+	s.Update("b", func(p *PlayerProfile) { p.Control = "arrow" })
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("修改时不应同步写文件: %v", err)
+	}
+
+	cancel()
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("关闭时应写回偏好文件: %v", err)
+	}
+	var saved map[string]PlayerProfile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatalf("偏好文件无法解析: %v", err)
+	}
+	if saved["a"].Character != gamev1.CharacterType_CHARACTER_TYPE_RED || saved["b"].Control != "arrow" {
+		t.Errorf("偏好文件内容 %+v 与修改不一致", saved)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("写入完成后不应残留临时文件")
+	}
+
+	reloaded, err := LoadProfileStore(path)
+	if err != nil {
+		t.Fatalf("重新读取偏好失败: %v", err)
+	}
+	if p, ok := reloaded.Get("b"); !ok || p.Control != "arrow" {
+		t.Errorf("重新读取后账号 b 的偏好 %+v, %v", p, ok)
+	}
+}
+
+// TestProfileStoreFlushesAfterInterval 服务器运行期间，修改在等待时间过后写回文件
+func TestProfileStoreFlushesAfterInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	s, _ := LoadProfileStore(path)
+	s.flushInterval = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	s.RunWriter(ctx, &wg)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for i := 0; i < 100; i++ {
+		s.Update("a", func(p *PlayerProfile) { p.Control = "wasd" })
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("等待时间过后应写回偏好文件")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestProfileStoreEvictsOldest 账号数达到上限时淘汰最久没有更新的账号
+func TestProfileStoreEvictsOldest(t *testing.T) {
+	s, _ := LoadProfileStore("")
+	s.limit = 2
+
+	s.Update("old", func(p *PlayerProfile) {})
+	s.Update("mid", func(p *PlayerProfile) {})
+	s.mu.Lock()
+	s.profiles["old"].UpdatedAt = time.Now().Add(-time.Hour)
+	s.mu.Unlock()
+
+	s.Update("new", func(p *PlayerProfile) {})
+	if _, ok := s.Get("old"); ok {
+		t.Error("最久没有更新的账号应被淘汰")
+	}
+	for _, account := range []string{"mid", "new"} {
+		if _, ok := s.Get(account); !ok {
+			t.Errorf("账号 %s 不应被淘汰", account)
+		}
+	}
+
+	// 已有账号的修改不触发淘汰
+	s.Update("mid", func(p *PlayerProfile) { p.Control = "arrow" })
+	if len(s.profiles) != 2 {
+		t.Errorf("账号数 %d，期望 2", len(s.profiles))
+	}
+}
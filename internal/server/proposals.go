@@ -0,0 +1,184 @@
+package server
+
+import (
+	"log"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// 对局之间的地图/规则提议：任意玩家在等待阶段提出，其他玩家投票，房主决定采纳或驳回。
+// 票数只是给房主的参考，采纳时走与房主直接修改相同的流程（SET_MAP / SET_RULES）
+
+// maxProposals 同时保留的提议数上限（每名玩家最多一个，超出时丢弃最早的）
+const maxProposals = 4
+
+// proposal 一条尚未处理的提议
+type proposal struct {
+	id       int32
+	proposer int32
+	mapName  *string         // nil 表示不换地图
+	rules    *core.GameRules // nil 表示不改规则
+	votes    map[int32]bool  // 玩家 -> 赞成/反对
+}
+
+// propose 提出提议，同一玩家的旧提议被替换
+func (r *Room) propose(playerID int32, msg *gamev1.RoomProposal) error {
+	if err := r.checkProposalPhase(playerID, actionPropose); err != nil {
+		return err
+	}
+	if msg == nil || (msg.MapName == nil && msg.Rules == nil) {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "提议没有包含地图或规则")
+	}
+	p := &proposal{proposer: playerID, votes: map[int32]bool{playerID: true}}
+	if msg.MapName != nil {
+		name := *msg.MapName
+		if name == "" {
+			name = core.MapLayoutDefault
+		}
+		if name != core.MapLayoutDefault && !core.IsBuiltinMap(name) && r.maps.Get(name) == nil {
+			return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_MAP_NOT_FOUND, []string{name}, "社区地图 %s 不存在", name)
+		}
+		p.mapName = &name
+	}
+	if msg.Rules != nil {
+		rules := protocol.ProtoRulesToCore(msg.Rules)
+		p.rules = &rules
+	}
+
+	r.dropProposalsBy(playerID)
+	if len(r.proposals) >= maxProposals {
+		r.proposals = r.proposals[1:]
+	}
+	r.nextProposalID++
+	p.id = r.nextProposalID
+	r.proposals = append(r.proposals, p)
+	log.Printf("房间 %s 玩家 %d 提议 #%d", r.id, playerID, p.id)
+	return nil
+}
+
+// vote 对提议投赞成或反对票（可以改票）
+func (r *Room) vote(playerID, proposalID int32, approve bool) error {
+	if err := r.checkProposalPhase(playerID, actionVote); err != nil {
+		return err
+	}
+	p := r.findProposal(proposalID)
+	if p == nil {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_PROPOSAL_NOT_FOUND, "提议 %d 不存在", proposalID)
+	}
+	p.votes[playerID] = approve
+	return nil
+}
+
+// resolveProposal 房主采纳或驳回提议，采纳时按提议修改地图和规则
+func (r *Room) resolveProposal(playerID, proposalID int32, accept bool) error {
+	if playerID != r.hostID {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionResolve}, "只有房主可以处理提议")
+	}
+	if r.state != StateWaiting {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionResolve}, "游戏中无法处理提议")
+	}
+	p := r.findProposal(proposalID)
+	if p == nil {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_PROPOSAL_NOT_FOUND, "提议 %d 不存在", proposalID)
+	}
+	if accept {
+		if p.mapName != nil {
+			if err := r.setMap(playerID, *p.mapName); err != nil {
+				return err
+			}
+		}
+		if p.rules != nil {
+			r.setRules(*p.rules)
+		}
+		r.saveHostPreset()
+	}
+	r.removeProposal(proposalID)
+	log.Printf("房间 %s 房主处理了提议 #%d（采纳: %v）", r.id, proposalID, accept)
+	return nil
+}
+
+// checkProposalPhase 提议和投票只在等待阶段、由房间中的玩家进行（观战者不参与）
+func (r *Room) checkProposalPhase(playerID int32, action string) error {
+	if r.state != StateWaiting {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{action}, "游戏中无法提议或投票")
+	}
+	if _, ok := r.connections[playerID]; !ok {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", playerID)
+	}
+	return nil
+}
+
+func (r *Room) findProposal(id int32) *proposal {
+	for _, p := range r.proposals {
+		if p.id == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (r *Room) removeProposal(id int32) {
+	kept := r.proposals[:0]
+	for _, p := range r.proposals {
+		if p.id != id {
+			kept = append(kept, p)
+		}
+	}
+	r.proposals = kept
+}
+
+// dropProposalsBy 删除玩家提出的提议
+func (r *Room) dropProposalsBy(playerID int32) {
+	kept := r.proposals[:0]
+	for _, p := range r.proposals {
+		if p.proposer != playerID {
+			kept = append(kept, p)
+		}
+	}
+	r.proposals = kept
+}
+
+// pruneProposals 提议者离开时删除其提议，离开的玩家的票不再计入（开局时 startGame 清空全部提议）
+func (r *Room) pruneProposals() {
+	kept := r.proposals[:0]
+	for _, p := range r.proposals {
+		if _, ok := r.connections[p.proposer]; !ok {
+			continue
+		}
+		for voter := range p.votes {
+			if _, ok := r.connections[voter]; !ok {
+				delete(p.votes, voter)
+			}
+		}
+		kept = append(kept, p)
+	}
+	r.proposals = kept
+}
+
+// proposalsProto 随房间状态下发的提议列表
+func (r *Room) proposalsProto() []*gamev1.RoomProposal {
+	if len(r.proposals) == 0 {
+		return nil
+	}
+	list := make([]*gamev1.RoomProposal, 0, len(r.proposals))
+	for _, p := range r.proposals {
+		msg := &gamev1.RoomProposal{Id: p.id, ProposerId: p.proposer, MapName: p.mapName}
+		if p.rules != nil {
+			msg.Rules = protocol.CoreRulesToProto(*p.rules)
+		}
+		for voter, yes := range p.votes {
+			if yes {
+				msg.YesIds = append(msg.YesIds, voter)
+			} else {
+				msg.NoIds = append(msg.NoIds, voter)
+			}
+		}
+		sort.Slice(msg.YesIds, func(i, j int) bool { return msg.YesIds[i] < msg.YesIds[j] })
+		sort.Slice(msg.NoIds, func(i, j int) bool { return msg.NoIds[i] < msg.NoIds[j] })
+		list = append(list, msg)
+	}
+	return list
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// readyNudgeAfter 其他人都已准备、只剩一名玩家未准备多久后提醒他
+const readyNudgeAfter = 30 * time.Second
+
+// soleUnreadyPlayer 除房主外恰好只有一名在线玩家未准备时返回该玩家
+func (r *Room) soleUnreadyPlayer() (int32, bool) {
+	var unready int32
+	count := 0
+	for playerID := range r.connections {
+		if playerID == r.hostID || r.readyStatus[playerID] {
+			continue
+		}
+		unready = playerID
+		count++
+	}
+	return unready, count == 1
+}
+
+// resetReadyStall 清除拖延计时（开局或拖延的玩家变化时）
+func (r *Room) resetReadyStall() {
+	r.readyStallPlayer = 0
+	r.readyStallSince = time.Time{}
+	r.readyNudged = false
+}
+
+// checkReadyStall 只剩一名玩家未准备超过 readyNudgeAfter 时广播提醒
+// 被提醒的玩家醒目提示，房主之后可以不等他直接开始
+func (r *Room) checkReadyStall(now time.Time) {
+	playerID, ok := r.soleUnreadyPlayer()
+	if !ok {
+		r.resetReadyStall()
+		return
+	}
+	if playerID != r.readyStallPlayer || r.readyStallSince.IsZero() {
+		r.resetReadyStall()
+		r.readyStallPlayer = playerID
+		r.readyStallSince = now
+		return
+	}
+
+	waited := now.Sub(r.readyStallSince)
+	if r.readyNudged || waited < readyNudgeAfter {
+		return
+	}
+	r.readyNudged = true
+	log.Printf("房间 %s 只剩玩家 %d 未准备 %v，发送提醒", r.id, playerID, waited.Truncate(time.Second))
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_ReadyNudge{
+			ReadyNudge: &gamev1.ReadyNudgeEvent{
+				PlayerId:      playerID,
+				PlayerName:    r.playerNames[playerID],
+				WaitedSeconds: int32(waited.Seconds()),
+			},
+		},
+	})
+}
+
+// startWithoutUnready 房主不再等待被提醒过的未准备玩家：把他移回大厅后直接开局
+func (r *Room) startWithoutUnready(requestorID int32) error {
+	if requestorID != r.hostID {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionStart}, "只有房主可以开始游戏")
+	}
+	if r.state != StateWaiting {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionStart}, "游戏已经开始")
+	}
+	playerID, ok := r.soleUnreadyPlayer()
+	if !ok || playerID != r.readyStallPlayer || !r.readyNudged {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_PLAYERS_NOT_READY, "没有已被提醒的未准备玩家")
+	}
+
+	totalPlayers := len(r.connections) + len(r.aiControllers) - 1
+	if totalPlayers < minPlayersToStart {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_ENOUGH_PLAYERS, countParams(totalPlayers, minPlayersToStart), "人数不足 (%d/%d)", totalPlayers, minPlayersToStart)
+	}
+
+	log.Printf("房间 %s 房主不再等待未准备的玩家 %d，直接开局", r.id, playerID)
+	r.sendRemoved(r.connections[playerID], gamev1.ErrorCode_ERROR_CODE_NOT_READY_REMOVED, "长时间未准备，房主已开始游戏")
+	r.handleForceLeave(playerID)
+
+	if err := r.CanStart(requestorID); err != nil {
+		return err
+	}
+	r.startGame()
+	return nil
+}
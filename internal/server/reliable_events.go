@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 关键事件的可靠送达
+//
+// 状态快照每帧都会发送，丢一帧无所谓；但开局、玩家死亡、游戏结束只发送一次，
+// 发送队列满时被丢弃会让客户端卡住。关键事件带有全局递增的 event_seq，
+// 客户端收到后立即用 ClientInput.ack_event_seqs 确认，服务器对未确认的事件定期重发，
+// 客户端按 event_seq 去重。观战者不确认，不重发。
+const (
+	eventRetransmitInterval = 250 * time.Millisecond
+	eventMaxRetransmits     = 8 // 超过后放弃（客户端已断开，或游戏结束后不再发送确认）
+)
+
+// eventSeq 全局事件序号：跨房间唯一，客户端换房间后不会把新事件误判为重复
+var eventSeq atomic.Int32
+
+func nextEventSeq() int32 {
+	return eventSeq.Add(1)
+}
+
+// pendingEvent 已发送、等待确认的关键事件
+type pendingEvent struct {
+	seq         int32
+	data        []byte
+	sentAt      time.Time
+	retransmits int
+}
+
+// isCriticalEvent 丢失后客户端无法从后续状态快照恢复的事件
+func isCriticalEvent(event *gamev1.GameEvent) bool {
+	switch event.Event.(type) {
+	case *gamev1.GameEvent_GameStart, *gamev1.GameEvent_PlayerDied, *gamev1.GameEvent_GameOver, *gamev1.GameEvent_RoundEnd:
+		return true
+	default:
+		return false
+	}
+}
+
+// trackPendingEvent 记录发给玩家的关键事件（发送失败也记录，由重发补上）
+func (r *Room) trackPendingEvent(playerID, seq int32, data []byte) {
+	r.pendingEvents[playerID] = append(r.pendingEvents[playerID], &pendingEvent{
+		seq:    seq,
+		data:   data,
+		sentAt: time.Now(),
+	})
+}
+
+// ackEvents 移除玩家已确认的事件
+func (r *Room) ackEvents(playerID int32, seqs []int32) {
+	pending := r.pendingEvents[playerID]
+	if len(pending) == 0 {
+		return
+	}
+	acked := make(map[int32]bool, len(seqs))
+	for _, seq := range seqs {
+		acked[seq] = true
+	}
+	kept := pending[:0]
+	for _, ev := range pending {
+		if !acked[ev.seq] {
+			kept = append(kept, ev)
+		}
+	}
+	if len(kept) == 0 {
+		delete(r.pendingEvents, playerID)
+		return
+	}
+	r.pendingEvents[playerID] = kept
+}
+
+// retransmitEvents 重发超时未确认的关键事件（每个 tick 调用，不受游戏阶段限制）
+func (r *Room) retransmitEvents() {
+	if len(r.pendingEvents) == 0 {
+		return
+	}
+	now := time.Now()
+	for playerID, pending := range r.pendingEvents {
+		conn, ok := r.connections[playerID]
+		if !ok {
+			delete(r.pendingEvents, playerID)
+			continue
+		}
+
+		kept := pending[:0]
+		for _, ev := range pending {
+			if now.Sub(ev.sentAt) < eventRetransmitInterval {
+				kept = append(kept, ev)
+				continue
+			}
+			if ev.retransmits >= eventMaxRetransmits {
+				log.Printf("玩家 %d 未确认事件 %d，放弃重发", playerID, ev.seq)
+				continue
+			}
+			ev.retransmits++
+			ev.sentAt = now
+			if err := conn.Send(ev.data); err != nil && !errors.Is(err, ErrSendQueueFull) {
+				log.Printf("重发事件 %d 到玩家 %d 失败: %v", ev.seq, playerID, err)
+			}
+			kept = append(kept, ev)
+		}
+		if len(kept) == 0 {
+			delete(r.pendingEvents, playerID)
+			continue
+		}
+		r.pendingEvents[playerID] = kept
+	}
+}
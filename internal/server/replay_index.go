@@ -0,0 +1,189 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+const (
+	replayIndexFile         = "index.json" // 录制目录下的索引文件名
+	defaultReplaySearchSize = 20
+	maxReplaySearchSize     = 100
+)
+
+// ReplayIndexEntry 一局录像的索引信息
+type ReplayIndexEntry struct {
+	Recording  string    `json:"recording"` // 录制文件名（录制目录下）
+	Match      int       `json:"match"`     // 文件中的第几局（从 0 开始，与 replayconv 的输出顺序一致）
+	RoomID     string    `json:"room_id"`
+	Map        string    `json:"map"`
+	Players    []string  `json:"players"`
+	Winner     string    `json:"winner"` // 空表示平局
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// ReplayQuery 录像搜索条件（零值表示不限）
+type ReplayQuery struct {
+	Player string
+	Map    string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
+
+// ReplayIndex 录像索引，保存在录制目录下的 index.json
+// 房间 goroutine 写入、连接 goroutine 查询，内部加锁
+type ReplayIndex struct {
+	mu      sync.Mutex
+	path    string
+	entries []ReplayIndexEntry // 按开局时间从旧到新
+}
+
+// LoadReplayIndex 读取录制目录下的索引，文件不存在时从空索引开始
+func LoadReplayIndex(dir string) (*ReplayIndex, error) {
+	x := &ReplayIndex{path: filepath.Join(dir, replayIndexFile)}
+	data, err := os.ReadFile(x.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return x, nil
+	}
+	if err != nil {
+		return x, err
+	}
+	if err := json.Unmarshal(data, &x.entries); err != nil {
+		return x, err
+	}
+	sort.SliceStable(x.entries, func(i, j int) bool {
+		return x.entries[i].StartedAt.Before(x.entries[j].StartedAt)
+	})
+	return x, nil
+}
+
+// add 追加一局并写回文件
+func (x *ReplayIndex) add(entry ReplayIndexEntry) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.entries = append(x.entries, entry)
+	return x.saveLocked()
+}
+
+// Search 按条件搜索，返回从新到旧的前 Limit 条以及符合条件的总数
+func (x *ReplayIndex) Search(q ReplayQuery) ([]ReplayIndexEntry, int) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultReplaySearchSize
+	}
+	limit = min(limit, maxReplaySearchSize)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	var results []ReplayIndexEntry
+	total := 0
+	for i := len(x.entries) - 1; i >= 0; i-- {
+		entry := x.entries[i]
+		if !q.matches(entry) {
+			continue
+		}
+		total++
+		if len(results) < limit {
+			results = append(results, entry)
+		}
+	}
+	return results, total
+}
+
+func (q ReplayQuery) matches(entry ReplayIndexEntry) bool {
+	if q.Map != "" && !strings.EqualFold(entry.Map, q.Map) {
+		return false
+	}
+	if !q.Since.IsZero() && entry.StartedAt.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && entry.StartedAt.After(q.Until) {
+		return false
+	}
+	if q.Player == "" {
+		return true
+	}
+	for _, name := range entry.Players {
+		if strings.EqualFold(name, q.Player) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveLocked 写回文件（先写临时文件再改名，避免中途退出留下半个文件）
+func (x *ReplayIndex) saveLocked() error {
+	data, err := json.MarshalIndent(x.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := x.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, x.path)
+}
+
+// replaySummaryToProto 转换为协议消息
+func replaySummaryToProto(entry ReplayIndexEntry) *gamev1.ReplaySummary {
+	return &gamev1.ReplaySummary{
+		Recording:   entry.Recording,
+		Match:       int32(entry.Match),
+		RoomId:      entry.RoomID,
+		Map:         entry.Map,
+		Players:     entry.Players,
+		Winner:      entry.Winner,
+		StartedAtMs: entry.StartedAt.UnixMilli(),
+		DurationMs:  entry.DurationMs,
+	}
+}
+
+// markReplayStart 开局时记下本局在录制文件中的序号（录制文件中每次开局都是一局回放）
+func (r *Room) markReplayStart() {
+	if r.recorder == nil {
+		return
+	}
+	r.replayMatch = r.recordedMatches
+	r.recordedMatches++
+	r.matchStartedAt = time.Now()
+}
+
+// indexReplay 对局结束时写入录像索引
+func (r *Room) indexReplay(winnerID int32) {
+	if r.recorder == nil || r.replayIndex == nil {
+		return
+	}
+
+	players := make([]string, 0, len(r.game.Players))
+	for _, player := range r.game.Players {
+		players = append(players, r.playerNames[int32(player.ID)])
+	}
+	entry := ReplayIndexEntry{
+		Recording:  filepath.Base(r.recorder.path),
+		Match:      r.replayMatch,
+		RoomID:     r.id,
+		Map:        core.MapID(r.mapLayout(), r.game.Rules),
+		Players:    players,
+		Winner:     r.playerNames[winnerID],
+		StartedAt:  r.matchStartedAt,
+		DurationMs: time.Since(r.matchStartedAt).Milliseconds(),
+	}
+	if err := r.replayIndex.add(entry); err != nil {
+		log.Printf("保存录像索引失败: %v", err)
+	}
+}
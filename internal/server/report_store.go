@@ -0,0 +1,337 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// 玩家举报：玩家或观战者举报某名玩家作弊或恶意行为时，服务器把近期的观察记录（输入频率、输入延迟、房间聊天）
+// 打包成一条举报记录写入举报目录，运维在控制台（reports / report <id>）或指标端点的 /reports（需令牌）查看。
+// 观察记录和聊天只收集加入时同意的玩家（JoinRequest.telemetry_opt_in，默认不同意）；
+// 服务器不设置 -reports-dir 时不接受举报，也不保存任何观察数据
+const (
+	reportCooldown    = 30 * time.Second // 同一人两次举报的最短间隔
+	defaultReportList = 20               // 控制台和 HTTP 列表默认返回的条数
+)
+
+// ReportsPath 指标端点上查看举报的路径：/reports 列出最近的举报，/reports/<id> 返回完整记录。
+// 举报包含玩家名字和聊天，只在设置了令牌（SetReportsToken）时开放，请求需带 Authorization: Bearer <令牌>
+const ReportsPath = "/reports"
+
+// PlayerReport 一条举报记录（JSON 文件，<reports-dir>/<id>.json）
+type PlayerReport struct {
+	ID           string             `json:"id"`
+	CreatedAt    time.Time          `json:"created_at"`
+	RoomID       string             `json:"room_id"`
+	RoomState    string             `json:"room_state"`
+	Frame        int32              `json:"frame"`
+	ReporterID   int32              `json:"reporter_id"`
+	ReporterName string             `json:"reporter_name"`
+	TargetID     int32              `json:"target_id"`
+	TargetName   string             `json:"target_name"`
+	Reason       string             `json:"reason"`
+	Target       *ReportObservation `json:"target,omitempty"`   // 被举报玩家本局的观察记录（未同意收集时没有）
+	Reporter     *ReportObservation `json:"reporter,omitempty"` // 举报人本局的同类数据（作对照，观战者和未同意收集时没有）
+	History      []string           `json:"history"`            // 最近的聊天和房间事件（只包含同意收集的玩家）
+}
+
+// ReportObservation 服务器对一名玩家本局输入的观察
+// 迟到输入越多，客户端预测与服务器结果的偏差越大；超前输入说明客户端时钟走得比服务器快
+type ReportObservation struct {
+	Online           bool    `json:"online"`
+	Inputs           int32   `json:"inputs"`
+	Packets          int32   `json:"packets"`
+	InputsPerSecond  float64 `json:"inputs_per_second"`
+	PacketsPerSecond float64 `json:"packets_per_second"`
+	LateInputs       int32   `json:"late_inputs"`
+	AheadInputs      int32   `json:"ahead_inputs"`
+	AvgDelayFrames   float32 `json:"avg_delay_frames"`
+	MaxDelayFrames   int32   `json:"max_delay_frames"`
+}
+
+// ReportSummary 举报列表中的一行
+type ReportSummary struct {
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	RoomID     string    `json:"room_id"`
+	Reporter   string    `json:"reporter"`
+	Target     string    `json:"target"`
+	Reason     string    `json:"reason"`
+	Observed   bool      `json:"observed"` // 被举报玩家同意了收集，有观察记录
+	LateInputs int32     `json:"late_inputs"`
+}
+
+// reportStore 举报目录，房间 goroutine 写入、控制台和 HTTP 读取，内部加锁
+// nil 表示服务器未开启举报
+type reportStore struct {
+	dir string
+	mu  sync.Mutex
+	seq int
+}
+
+// newReportStore 创建举报目录
+func newReportStore(dir string) *reportStore {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("创建举报目录失败: %v", err)
+	}
+	return &reportStore{dir: dir}
+}
+
+// validReportID 举报 ID 由时间和序号组成（同时作为文件名，避免路径穿越）
+func validReportID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *reportStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+// save 分配 ID 并写入举报记录
+func (s *reportStore) save(report *PlayerReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	report.ID = report.CreatedAt.Format("20060102-150405") + "-" + strconv.Itoa(s.seq)
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path(report.ID) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(report.ID))
+}
+
+// Get 读取一条举报记录
+func (s *reportStore) Get(id string) (*PlayerReport, error) {
+	if !validReportID(id) {
+		return nil, fmt.Errorf("无效的举报 ID %q", id)
+	}
+	s.mu.Lock()
+	data, err := os.ReadFile(s.path(id))
+	s.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("举报 %s 不存在", id)
+	}
+	var report PlayerReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("读取举报 %s 失败: %w", id, err)
+	}
+	return &report, nil
+}
+
+// List 最近的 limit 条举报（从新到旧，limit<=0 时使用默认值），无法解析的文件跳过
+func (s *reportStore) List(limit int) []ReportSummary {
+	if limit <= 0 {
+		limit = defaultReportList
+	}
+	s.mu.Lock()
+	entries, err := os.ReadDir(s.dir)
+	s.mu.Unlock()
+	if err != nil {
+		return nil
+	}
+	reports := make([]ReportSummary, 0)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !validReportID(id) {
+			continue
+		}
+		report, err := s.Get(id)
+		if err != nil {
+			continue
+		}
+		summary := ReportSummary{
+			ID:        report.ID,
+			CreatedAt: report.CreatedAt,
+			RoomID:    report.RoomID,
+			Reporter:  report.ReporterName,
+			Target:    report.TargetName,
+			Reason:    report.Reason,
+		}
+		if report.Target != nil {
+			summary.Observed = true
+			summary.LateInputs = report.Target.LateInputs
+		}
+		reports = append(reports, summary)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].CreatedAt.After(reports[j].CreatedAt)
+	})
+	if len(reports) > limit {
+		reports = reports[:limit]
+	}
+	return reports
+}
+
+// handleReports 在指标端点上注册举报查看接口（JSON）：/reports?limit=N 列出最近的举报，/reports/<id> 返回完整记录。
+// 每个请求都要带正确的令牌，否则返回 401
+func (s *GameServer) handleReports(mux *http.ServeMux) {
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	authorized := func(w http.ResponseWriter, req *http.Request) bool {
+		token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.reportsToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+	mux.HandleFunc(ReportsPath, func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(w, req) {
+			return
+		}
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		writeJSON(w, s.reports.List(limit))
+	})
+	mux.HandleFunc(ReportsPath+"/", func(w http.ResponseWriter, req *http.Request) {
+		if !authorized(w, req) {
+			return
+		}
+		report, err := s.reports.Get(strings.TrimPrefix(req.URL.Path, ReportsPath+"/"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, report)
+	})
+}
+
+// handleReport 处理举报：举报人可以是玩家或观战者，被举报的必须是房间中的真人玩家（包括断线保留中的）
+func (r *Room) handleReport(reporterID, targetID int32, reason string) error {
+	if r.reports == nil {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_REPORTS_DISABLED, "服务器未开启举报")
+	}
+	_, isPlayer := r.connections[reporterID]
+	_, isSpectator := r.spectators[reporterID]
+	if !isPlayer && !isSpectator {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", reporterID)
+	}
+	_, online := r.connections[targetID]
+	_, offline := r.offlinePlayers[targetID]
+	_, isAI := r.aiControllers[targetID]
+	if targetID == reporterID || isAI || (!online && !offline) {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND, "举报目标 %d 不在房间中", targetID)
+	}
+	now := time.Now()
+	if last, ok := r.reportedAt[reporterID]; ok && now.Sub(last) < reportCooldown {
+		left := int((reportCooldown - now.Sub(last) + time.Second - 1) / time.Second)
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_REPORT_COOLDOWN, []string{strconv.Itoa(left)}, "举报过于频繁")
+	}
+
+	reason = sanitizeChat(reason) // 与聊天消息相同的清理和长度限制
+	report := &PlayerReport{
+		CreatedAt:    now,
+		RoomID:       r.id,
+		RoomState:    roomStateName(r.state),
+		Frame:        r.frameID,
+		ReporterID:   reporterID,
+		ReporterName: r.memberName(reporterID),
+		TargetID:     targetID,
+		TargetName:   r.playerNames[targetID],
+		Reason:       reason,
+		Target:       r.observePlayer(targetID),
+	}
+	if isPlayer {
+		report.Reporter = r.observePlayer(reporterID)
+	}
+	for _, entry := range r.history {
+		if !r.telemetryOptIn[entry.PlayerId] {
+			continue
+		}
+		if line := historyLine(entry); line != "" {
+			report.History = append(report.History, line)
+		}
+	}
+	if err := r.reports.save(report); err != nil {
+		log.Printf("保存举报失败: %v", err)
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "保存举报失败")
+	}
+	r.reportedAt[reporterID] = now
+	log.Printf("房间 %s 玩家 %d 举报了玩家 %d（%s）: %s", r.id, reporterID, targetID, report.ID, reason)
+	return nil
+}
+
+// collectTelemetry 是否收集玩家的输入观察：服务器开启了举报且玩家加入时同意
+func (r *Room) collectTelemetry(playerID int32) bool {
+	return r.reports != nil && r.telemetryOptIn[playerID]
+}
+
+// observePlayer 汇总玩家本局的输入观察（等待阶段为上一局的数据），玩家未同意收集时返回 nil
+func (r *Room) observePlayer(playerID int32) *ReportObservation {
+	if !r.telemetryOptIn[playerID] {
+		return nil
+	}
+	_, online := r.connections[playerID]
+	obs := &ReportObservation{Online: online}
+	l, ok := r.inputDelays[playerID]
+	if !ok {
+		return obs
+	}
+	obs.Inputs = l.inputs
+	obs.Packets = l.packets
+	obs.LateInputs = l.late
+	obs.AheadInputs = l.ahead
+	obs.AvgDelayFrames = l.avgDelay()
+	obs.MaxDelayFrames = l.maxDelay
+	if frames := r.frameID - l.firstFrame; frames > 0 {
+		seconds := core.FramesToSeconds(int(frames))
+		obs.InputsPerSecond = float64(l.inputs) / seconds
+		obs.PacketsPerSecond = float64(l.packets) / seconds
+	}
+	return obs
+}
+
+// historyLine 历史记录的文本形式（举报中保存）
+func historyLine(entry *gamev1.RoomHistoryEntry) string {
+	stamp := time.UnixMilli(entry.TimeMs).Format("15:04:05")
+	name := fmt.Sprintf("%s(%d)", entry.PlayerName, entry.PlayerId)
+	switch entry.Kind {
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_CHAT:
+		return stamp + " " + name + ": " + entry.Text
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_JOINED:
+		return stamp + " * " + name + " 加入"
+	case gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_LEFT:
+		return stamp + " * " + name + " 离开"
+	default:
+		return ""
+	}
+}
+
+// roomStateName 房间状态的文本形式
+func roomStateName(state GameState) string {
+	switch state {
+	case StateWaiting:
+		return "waiting"
+	case StateRunning:
+		return "running"
+	default:
+		return "ending"
+	}
+}
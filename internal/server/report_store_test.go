@@ -0,0 +1,82 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// TestReportRespectsTelemetryOptIn 只有同意收集的玩家有观察记录和聊天记录
+func TestReportRespectsTelemetryOptIn(t *testing.T) {
+	r := newTestRoom(t, 0)
+	r.reports = newReportStore(t.TempDir())
+	r.telemetryOptIn[2] = true
+
+	r.state = StateRunning
+	r.frameID = 100
+	for _, id := range []int32{1, 2} {
+		r.handleInput(inputEvent{playerID: id, input: InputEvent{Inputs: []InputData{{FrameID: 100 + InputBufferFrames + 5}}}})
+	}
+	if stats := r.inputDelays[1]; stats.packets != 0 || stats.ahead != 0 {
+		t.Errorf("未同意的玩家 1 不应统计观察数据，得到 packets=%d ahead=%d", stats.packets, stats.ahead)
+	}
+	if stats := r.inputDelays[2]; stats.packets != 1 || stats.ahead != 1 {
+		t.Errorf("玩家 2 的观察数据 packets=%d ahead=%d，期望 1 和 1", stats.packets, stats.ahead)
+	}
+
+	r.recordHistory(gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_CHAT, 1, "secret from 1")
+	r.recordHistory(gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_CHAT, 2, "hello from 2")
+
+	// 玩家 2 举报未同意的玩家 1：没有目标的观察记录，只有举报人自己的
+	if err := r.handleReport(2, 1, "cheating"); err != nil {
+		t.Fatalf("举报失败: %v", err)
+	}
+	// 玩家 1 举报同意了的玩家 2：有目标的观察记录，举报人未同意没有对照
+	if err := r.handleReport(1, 2, "griefing"); err != nil {
+		t.Fatalf("举报失败: %v", err)
+	}
+
+	reports := make(map[string]*PlayerReport)
+	for _, summary := range r.reports.List(0) {
+		report, err := r.reports.Get(summary.ID)
+		if err != nil {
+			t.Fatalf("读取举报失败: %v", err)
+		}
+		if summary.Observed != (report.Target != nil) {
+			t.Errorf("举报 %s 列表中 observed=%v，与记录不一致", summary.ID, summary.Observed)
+		}
+		reports[report.Reason] = report
+	}
+	if len(reports) != 2 {
+		t.Fatalf("保存了 %d 条举报，期望 2", len(reports))
+	}
+
+	if report := reports["cheating"]; report.Target != nil || report.Reporter == nil {
+		t.Errorf("举报未同意的玩家: target=%v reporter=%v，期望只有举报人的观察记录", report.Target, report.Reporter)
+	}
+	report := reports["griefing"]
+	if report.Target == nil || report.Reporter != nil {
+		t.Fatalf("举报同意的玩家: target=%v reporter=%v，期望只有目标的观察记录", report.Target, report.Reporter)
+	}
+	if report.Target.Packets != 1 || report.Target.AheadInputs != 1 {
+		t.Errorf("目标观察记录 %+v，期望 1 个包、1 个超前输入", report.Target)
+	}
+	for reason, saved := range reports {
+		history := strings.Join(saved.History, "\n")
+		if strings.Contains(history, "secret from 1") || !strings.Contains(history, "hello from 2") {
+			t.Errorf("举报 %q 的聊天记录应只包含同意收集的玩家:\n%s", reason, history)
+		}
+	}
+}
+
+// TestNoTelemetryWithoutReports 服务器未开启举报时即使玩家同意也不收集
+func TestNoTelemetryWithoutReports(t *testing.T) {
+	r := newTestRoom(t, 0)
+	r.telemetryOptIn[1] = true
+	r.state = StateRunning
+	r.handleInput(inputEvent{playerID: 1, input: InputEvent{Inputs: []InputData{{FrameID: InputBufferFrames + 5}}}})
+	if stats := r.inputDelays[1]; stats.packets != 0 || stats.ahead != 0 {
+		t.Errorf("未开启举报时不应收集观察数据，得到 packets=%d ahead=%d", stats.packets, stats.ahead)
+	}
+}
@@ -12,12 +12,18 @@ import (
 	gamev1 "bomberman/api/gen/bomberman/v1"
 	"bomberman/pkg/ai"
 	"bomberman/pkg/core"
+	"bomberman/pkg/fairseed"
 	"bomberman/pkg/protocol"
 )
 
 const (
 	// OfflinePlayerTimeout 离线玩家保留时间，超时后强制移除
 	OfflinePlayerTimeout = 60 * time.Second
+
+	// MaxSpectators 每个房间最多观战人数
+	MaxSpectators = 8
+	// SpectatorIDBase 观战者 ID 起始值，与玩家 ID 区分开，避免影响出生点分配
+	SpectatorIDBase = 10000
 )
 
 type Room struct {
@@ -26,6 +32,8 @@ type Room struct {
 	id     string // 房间 ID
 	seed   int64
 
+	seedCommit fairseed.Commitment // 公平种子规则下当前种子的承诺
+
 	legacyMode bool
 
 	game          *core.Game
@@ -43,27 +51,114 @@ type Room struct {
 	sendQueueFullAt map[int32]time.Time
 	lastInput       map[int32]InputData
 
+	// 增量状态：最近广播的完整状态与每个玩家确认的状态帧（见 delta_state.go）
+	stateHistory []*gamev1.GameState
+	stateAcks    map[int32]int32
+
+	// 兴趣管理：每名玩家上一帧看到的实体与上一帧的全部实体（见 interest.go）
+	interestRadius  int32 // 半径（格），0 表示不过滤
+	interestVisible map[int32]entitySet
+	interestAll     entitySet
+
+	// 等待玩家确认的关键事件（见 reliable_events.go）
+	pendingEvents map[int32][]*pendingEvent
+
 	// 离线玩家（断线保护），记录断线时间
-	offlinePlayers map[int32]time.Time
+	offlinePlayers  map[int32]time.Time
+	offlineTimeout  time.Duration // 离线玩家保留时间
+	offlinePausedAt time.Time     // 真人玩家全部断线、对局暂停的时间（零值表示未暂停，见 offline_pause.go）
+
+	tokensRefreshedAt time.Time // 上次给在线会话续期令牌的时间（见 session_refresh.go）
+
+	// 等待阶段空闲解散（兼容房间不启用）
+	idleTimeout  time.Duration // <=0 表示不限制
+	lastActivity time.Time     // 最近一次加入、准备或房间操作的时间
+	idleWarned   bool
+
+	// 只剩一名玩家未准备时的提醒（见 ready_nudge.go）
+	readyStallPlayer int32
+	readyStallSince  time.Time // 零值表示当前没有单独拖延的玩家
+	readyNudged      bool
+
+	// 多回合对局的当前回合与比分（见 rounds.go，round 为 0 表示不在多回合对局中）
+	round     int32
+	roundWins map[int32]int32
+	nextRound bool // 回合间歇结束后开始下一回合（否则回到等待阶段）
+
+	// 对局之间的地图/规则提议与投票（见 proposals.go）
+	proposals      []*proposal
+	nextProposalID int32
 
 	// 客户端预测支持：记录每个玩家最后处理的输入序号
 	lastProcessedInputSeq map[int32]int32
 
-	// 记录上一帧玩家死亡状态，用于检测变化
-	lastPlayerDeadState map[int32]bool
+	killsBroadcast int              // 已广播的 game.KillLog 条数
+	posHistory     *positionHistory // 最近若干帧的权威位置，用于死亡判定复核与放弹补偿
+	lateBombs      map[int32]int32  // playerID -> 迟到的放弹输入帧号
+
+	// 调试场景（仅在 -debug-scenarios 开启时可用，默认房间永不开启）
+	scenariosEnabled    bool
+	scenarioTileChanges []core.TileChange // 场景修改的格子，随下一次状态广播下发
+	suddenDeathChanges  []core.TileChange // 突然死亡落墙的格子，随下一次状态广播下发
+	bossTileChanges     []core.TileChange // 首领出场清除的砖块，随下一次状态广播下发
+	boss                *bossController   // 首领战的首领控制器（见 boss.go，nil 表示本局没有首领）
+	debugAI             bool              // 广播 AI 调试信息（仅 -debug-ai 开启时）
+	syncCheck           bool              // 定期广播帧同步校验（仅 -sync-check 开启时）
+
+	matchStats *MatchStats   // AI 与真人胜负统计（服务器共享）
+	aiTree     ai.Node       // 从配置加载的 AI 行为树（nil 表示使用内置树，服务器共享）
+	maps       *mapCatalog   // 社区地图（nil 表示未开启，服务器共享）
+	reports    *reportStore  // 玩家举报（nil 表示未开启，服务器共享）
+	profiles   *ProfileStore // 玩家偏好（nil 表示未开启，服务器共享）
+
+	// 录像索引（仅开启录制时）
+	recorder        *BroadcastRecorder
+	replayIndex     *ReplayIndex // 服务器共享
+	recordedMatches int          // 录制文件中已开始的对局数
+	replayMatch     int          // 当前对局在录制文件中的序号
+	matchStartedAt  time.Time
 
 	// 房间大厅状态
 	hostID           int32
 	readyStatus      map[int32]bool
+	nextRoundReady   map[int32]bool // 结算期间的准备操作，返回等待状态时生效
 	playerNames      map[int32]string
+	playerAccounts   map[int32]string // 携带账号密钥加入的玩家的账号 ID（profile_store.go）
+	telemetryOptIn   map[int32]bool   // 同意收集输入观察的玩家（report_store.go）
 	playerCharacters map[int32]core.CharacterType
+	playerTeams      map[int32]int // 玩家所在队伍（加入时分配到人少的一队，组队模式开局时写入 core.Player）
 	roomName         string
+	rules            core.GameRules             // 房间规则（开始游戏时写入 game.Rules）
+	config           core.MatchConfig           // 对局参数（开始游戏时写入 game.Config）
+	history          []*gamev1.RoomHistoryEntry // 最近的聊天和事件（room_history.go）
+	inputDelays      map[int32]*inputLatency    // 本局每名玩家的输入延迟统计（赛后下发）
+	reportedAt       map[int32]time.Time        // 每人上一次举报的时间（report_store.go）
+	metrics          *roomMetrics               // tick 耗时与帧延迟（metrics.go）
+	mapDef           *core.MapDefinition        // 房主选择的地图（nil 表示默认地图，room_map.go）
+	mapJSON          []byte                     // 社区地图 mapDef 的紧凑 JSON，随房间状态下发（内置地图只下发 ID）
+	largeMap         bool                       // 人数超过所选地图的出生点数，换成了大地图（room_map.go）
+
+	// 观战者（不参与游戏，只接收广播）
+	spectators        map[int32]Session
+	spectatorNames    map[int32]string
+	offlineSpectators map[int32]offlineSpectator // 断线的观战者，会话令牌有效期内可以重连回来
+	nextSpectatorID   int32
+
+	// 运维观察者（管理控制台挂载，对玩家不可见）
+	observers map[int32]Session
+
+	// 游戏中接管 AI 的请求（等待房主审批）
+	pendingTakeovers map[int32]*takeoverRequest
+	nextTakeoverID   int32
 
 	joinCh      chan joinRequest
 	reconnectCh chan reconnectRequest // 新增重连请求通道
 	inputCh     chan inputEvent
 	leaveCh     chan int32
 	actionCh    chan roomActionRequest
+	scenarioCh  chan scenarioRequest
+	observeCh   chan observeRequest
+	snapshotCh  chan snapshotRequest
 }
 
 type joinRequest struct {
@@ -75,7 +170,13 @@ type joinRequest struct {
 type reconnectRequest struct {
 	playerID int32
 	conn     Session
-	respCh   chan bool // true=成功, false=失败
+	respCh   chan reconnectResult
+}
+
+// reconnectResult 重连结果，成功时附带房间历史
+type reconnectResult struct {
+	ok      bool
+	history []*gamev1.RoomHistoryEntry
 }
 
 type inputEvent struct {
@@ -108,18 +209,42 @@ func NewRoom(parent context.Context, roomID string, seed int64, enableAI bool, l
 		nextPlayerID:          1,
 		inputQueue:            make(map[int32]map[int32]InputData),
 		sendQueueFullAt:       make(map[int32]time.Time),
+		stateAcks:             make(map[int32]int32),
+		interestVisible:       make(map[int32]entitySet),
+		pendingEvents:         make(map[int32][]*pendingEvent),
 		lastInput:             make(map[int32]InputData),
 		offlinePlayers:        make(map[int32]time.Time),
+		tokensRefreshedAt:     time.Now(),
+		offlineTimeout:        OfflinePlayerTimeout,
+		lastActivity:          time.Now(),
 		lastProcessedInputSeq: make(map[int32]int32),
-		lastPlayerDeadState:   make(map[int32]bool),
+		posHistory:            newPositionHistory(),
+		metrics:               &roomMetrics{},
+		lateBombs:             make(map[int32]int32),
 		readyStatus:           make(map[int32]bool),
+		nextRoundReady:        make(map[int32]bool),
 		playerNames:           make(map[int32]string),
+		playerAccounts:        make(map[int32]string),
+		telemetryOptIn:        make(map[int32]bool),
 		playerCharacters:      make(map[int32]core.CharacterType),
+		playerTeams:           make(map[int32]int),
+		config:                core.DefaultMatchConfig(),
+		inputDelays:           make(map[int32]*inputLatency),
+		reportedAt:            make(map[int32]time.Time),
+		spectators:            make(map[int32]Session),
+		spectatorNames:        make(map[int32]string),
+		offlineSpectators:     make(map[int32]offlineSpectator),
+		nextSpectatorID:       SpectatorIDBase,
+		observers:             make(map[int32]Session),
+		pendingTakeovers:      make(map[int32]*takeoverRequest),
 		joinCh:                make(chan joinRequest),
 		reconnectCh:           make(chan reconnectRequest), // 初始化
 		inputCh:               make(chan inputEvent, 256),
 		leaveCh:               make(chan int32, 256),
 		actionCh:              make(chan roomActionRequest, 64),
+		scenarioCh:            make(chan scenarioRequest),
+		observeCh:             make(chan observeRequest),
+		snapshotCh:            make(chan snapshotRequest),
 	}
 }
 
@@ -135,13 +260,16 @@ func (r *Room) Run(wg *sync.WaitGroup) {
 		select {
 		case <-r.ctx.Done():
 			r.closeAllConnections(false)
+			r.closeObservers()
 			log.Println("房间循环停止")
 			return
 
 		case req := <-r.joinCh:
+			r.touchActivity()
 			r.handleJoin(req)
 
 		case req := <-r.reconnectCh: // 处理重连请求
+			r.touchActivity()
 			r.handleReconnect(req)
 
 		case ev := <-r.inputCh:
@@ -151,10 +279,23 @@ func (r *Room) Run(wg *sync.WaitGroup) {
 			r.handleLeave(playerID)
 
 		case req := <-r.actionCh:
+			r.touchActivity()
 			r.handleRoomAction(req)
 
+		case req := <-r.scenarioCh:
+			r.handleScenario(req)
+
+		case req := <-r.observeCh:
+			r.handleObserve(req)
+
+		case req := <-r.snapshotCh:
+			r.handleSnapshot(req)
+
 		case <-ticker.C:
+			start := time.Now()
+			r.retransmitEvents()
 			r.tick()
+			r.observeTick(start)
 		}
 	}
 }
@@ -172,13 +313,13 @@ func (r *Room) Join(conn Session, req JoinEvent) error {
 
 	select {
 	case <-r.ctx.Done():
-		return fmt.Errorf("房间已关闭")
+		return errRoomClosed
 	case r.joinCh <- joinRequest{conn: conn, req: req, respCh: respCh}:
 	}
 
 	select {
 	case <-r.ctx.Done():
-		return fmt.Errorf("房间已关闭")
+		return errRoomClosed
 	case err := <-respCh:
 		return err
 	}
@@ -202,13 +343,13 @@ func (r *Room) Leave(playerID int32) {
 
 func (r *Room) HandleRoomAction(playerID int32, action *gamev1.RoomAction) error {
 	if action == nil {
-		return fmt.Errorf("房间操作为空")
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "房间操作为空")
 	}
 
 	respCh := make(chan error, 1)
 	select {
 	case <-r.ctx.Done():
-		return fmt.Errorf("房间已关闭")
+		return errRoomClosed
 	case r.actionCh <- roomActionRequest{
 		playerID: playerID,
 		action:   action,
@@ -218,7 +359,7 @@ func (r *Room) HandleRoomAction(playerID int32, action *gamev1.RoomAction) error
 
 	select {
 	case <-r.ctx.Done():
-		return fmt.Errorf("房间已关闭")
+		return errRoomClosed
 	case err := <-respCh:
 		return err
 	}
@@ -226,24 +367,41 @@ func (r *Room) HandleRoomAction(playerID int32, action *gamev1.RoomAction) error
 
 func (r *Room) tick() {
 	now := time.Now()
+	r.expireTakeovers()
+	r.maybeRefreshSessionTokens(now)
 
 	if r.state == StateEnding && !r.resetAt.IsZero() && now.After(r.resetAt) {
-		r.resetRoom()
+		if r.nextRound {
+			r.startNextRound()
+		} else {
+			r.resetRoom()
+		}
 		return
 	}
 
+	if r.state == StateWaiting {
+		r.checkIdle(now)
+		r.checkReadyStall(now)
+	}
+
 	if r.state != StateRunning {
 		return
 	}
+	if r.pausedForOffline(now) {
+		r.expireOfflinePlayers()
+		return
+	}
 
-	r.applyInputs()
-	r.updateAI()
+	r.applyFrameInputs()
+	r.updateBoss()
 
 	// 更新核心游戏逻辑（帧递增在 Update 内部）
 	r.game.Update()
 
 	// 增加帧 ID（game.CurrentFrame 已在 Update 中递增）
 	r.frameID = r.game.CurrentFrame
+	r.posHistory.record(r.frameID, r.game.Players)
+	r.suddenDeathChanges = append(r.suddenDeathChanges, r.game.SuddenDeathChanges...)
 
 	if r.isMatchTimedOut() {
 		r.handleMatchTimeout()
@@ -252,74 +410,144 @@ func (r *Room) tick() {
 	// 检测玩家死亡状态变化并广播
 	r.checkAndBroadcastPlayerDeaths()
 
+	// 门口蹲守位置提示
+	r.broadcastDoorCampPings()
+
+	// 决斗加时倒计时
+	r.broadcastOvertimeCountdown()
+
 	if shouldEnd, winnerID := r.checkGameOver(); shouldEnd {
 		r.handleGameOver(winnerID)
 	}
 
 	if r.state == StateRunning {
 		r.broadcastState()
+		r.broadcastSyncCheck()
+		r.broadcastDebugAIState()
 	}
 
 	// 清理超时离线玩家
-	for playerID, disconnectTime := range r.offlinePlayers {
-		if time.Since(disconnectTime) > OfflinePlayerTimeout {
-			log.Printf("玩家 %d 离线超时 (%v)，强制移除", playerID, OfflinePlayerTimeout)
-			r.handleForceLeave(playerID)
+	r.expireOfflinePlayers()
+}
+
+// checkAndBroadcastPlayerDeaths 广播本帧新增的阵亡记录（game.KillLog 中尚未广播的部分）
+func (r *Room) checkAndBroadcastPlayerDeaths() {
+	for _, kill := range r.game.KillLog[r.killsBroadcast:] {
+		playerID := int32(kill.VictimID)
+		switch kill.KillerID {
+		case core.KillerHazard:
+			log.Printf("玩家 %d 死于地图危险区域", playerID)
+		case core.KillerSuddenDeath:
+			log.Printf("玩家 %d 被突然死亡落下的墙压死", playerID)
+		case core.KillerOvertime:
+			log.Printf("玩家 %d 在决斗加时中离开竞技场被淹没", playerID)
+		case core.KillerBoss:
+			log.Printf("玩家 %d 被首领击杀", playerID)
+		default:
+			log.Printf("玩家 %d 被炸死（击杀归属: 玩家 %d）", playerID, kill.KillerID)
+			r.reviewDeath(playerID)
 		}
+
+		// 广播玩家死亡事件（关键事件，未确认时重发）
+		r.broadcastEvent(&gamev1.GameEvent{
+			Event: &gamev1.GameEvent_PlayerDied{
+				PlayerDied: &gamev1.PlayerDiedEvent{
+					PlayerId: playerID,
+					KillerId: killerIDOf(kill),
+				},
+			},
+		})
 	}
+	r.killsBroadcast = len(r.game.KillLog)
 }
 
-func (r *Room) checkAndBroadcastPlayerDeaths() {
-	for _, player := range r.game.Players {
-		playerID := int32(player.ID)
-		wasDead := r.lastPlayerDeadState[playerID]
-		isDead := player.Dead
+// killerIDOf 死亡事件的击杀者，自杀返回 -1
+func killerIDOf(kill core.KillRecord) int32 {
+	if kill.SelfKill() {
+		return -1
+	}
+	return int32(kill.KillerID)
+}
 
-		// 初始化状态（第一次看到这个玩家）
-		if !wasDead && !isDead {
-			r.lastPlayerDeadState[playerID] = false
-			continue
+// reviewDeath 用位置历史复核死亡判定并记录日志
+// 玩家视角落后服务器若干帧（输入延迟），如果回溯到其视角帧时并不在爆炸格子内，
+// 说明是在输入尚未到达期间"走进"了爆炸，记录为可疑判定以便事后排查
+func (r *Room) reviewDeath(playerID int32) {
+	deathFrame := r.frameID
+	sample, ok := r.posHistory.at(playerID, deathFrame)
+	if !ok {
+		return
+	}
+
+	blast := make(map[core.GridPos]bool)
+	for _, exp := range r.game.Explosions {
+		for _, cell := range exp.Cells {
+			blast[cell] = true
 		}
+	}
 
-		// 检测死亡事件：从存活变为死亡
-		if !wasDead && isDead {
-			r.lastPlayerDeadState[playerID] = true
-			log.Printf("玩家 %d 被炸死", playerID)
+	lagFrames := int32(0)
+	if last, ok := r.lastInput[playerID]; ok && last.FrameID < deathFrame {
+		lagFrames = deathFrame - last.FrameID
+	}
+	if lagFrames > maxLagCompensationFrames {
+		lagFrames = maxLagCompensationFrames
+	}
 
-			// 广播玩家死亡事件
-			event := &gamev1.GameEvent{
-				Event: &gamev1.GameEvent_PlayerDied{
-					PlayerDied: &gamev1.PlayerDiedEvent{
-						PlayerId: playerID,
-					},
-				},
-			}
-			packet, err := protocol.NewGameEventPacket(r.frameID, event)
-			if err != nil {
-				log.Printf("构造玩家死亡事件失败: %v", err)
-				continue
-			}
+	compensatedFrame := deathFrame - lagFrames
+	trail := r.posHistory.describe(playerID, compensatedFrame-5, deathFrame)
 
-			data, err := protocol.MarshalPacket(packet)
-			if err != nil {
-				log.Printf("序列化玩家死亡事件失败: %v", err)
-				continue
-			}
+	if compSample, ok := r.posHistory.at(playerID, compensatedFrame); ok && lagFrames > 0 && !blast[compSample.grid()] {
+		log.Printf("[判定复核] 房间 %s 玩家 %d 可疑死亡: 帧 %d 位于 %v (爆炸内), 回溯 %d 帧到帧 %d 位于 %v (爆炸外), 轨迹:%s",
+			r.id, playerID, deathFrame, sample.grid(), lagFrames, compensatedFrame, compSample.grid(), trail)
+		return
+	}
+	log.Printf("[判定复核] 房间 %s 玩家 %d 死亡: 帧 %d 位于 %v, 输入延迟 %d 帧, 轨迹:%s",
+		r.id, playerID, deathFrame, sample.grid(), lagFrames, trail)
+}
 
-			for _, conn := range r.connections {
-				if err := conn.Send(data); err != nil {
-					log.Printf("发送玩家死亡事件到玩家 %d 失败: %v", conn.ID(), err)
-				}
-			}
-		}
+// sortedPlayerIDs 按 ID 升序返回 map 中的玩家 ID（逐帧处理玩家时使用的统一顺序）
+func sortedPlayerIDs[V any](m map[int32]V) []int32 {
+	ids := make([]int32, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
 	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
 }
 
-func (r *Room) applyInputs() {
-	if len(r.connections) == 0 {
-		return
+// frameInput 一名玩家（真人或 AI）本帧要应用的输入
+type frameInput struct {
+	playerID int32
+	input    core.Input
+	human    bool
+}
+
+// applyFrameInputs 先收集本帧真人玩家的输入和 AI 的决策，再按玩家 ID 顺序统一应用：
+// 同帧放炸弹、碰撞等结果只取决于玩家 ID，不依赖真人/AI 身份和 map 遍历顺序
+func (r *Room) applyFrameInputs() {
+	inputs := r.collectHumanInputs()
+	inputs = append(inputs, r.decideAI()...)
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].playerID < inputs[j].playerID })
+
+	for _, in := range inputs {
+		placed := core.ApplyInput(r.game, int(in.playerID), in.input, r.frameID)
+		if !in.human {
+			continue
+		}
+		if placed {
+			log.Printf("玩家 %d 放置炸弹", in.playerID)
+		}
+		if inputFrame, ok := r.lateBombs[in.playerID]; ok {
+			delete(r.lateBombs, in.playerID)
+			r.placeLateBomb(in.playerID, inputFrame)
+		}
 	}
+}
 
+// collectHumanInputs 取出真人玩家本帧的输入（没有新输入时沿用上一次的输入）
+func (r *Room) collectHumanInputs() []frameInput {
+	inputs := make([]frameInput, 0, len(r.connections))
 	for playerID := range r.connections {
 		inputData, ok := r.popInputForFrame(playerID, r.frameID)
 		if !ok {
@@ -331,40 +559,69 @@ func (r *Room) applyInputs() {
 		} else {
 			r.lastInput[playerID] = inputData
 		}
-
-		r.applyInputData(playerID, inputData)
+		inputs = append(inputs, frameInput{playerID: playerID, input: inputData.coreInput(), human: true})
 	}
+	return inputs
 }
 
-func (r *Room) applyInputData(playerID int32, input InputData) {
-	ci := core.Input{
-		Up:    input.Up,
-		Down:  input.Down,
-		Left:  input.Left,
-		Right: input.Right,
-		Bomb:  input.Bomb,
+// placeLateBomb 处理迟到的放弹输入：按输入帧回溯玩家当时所在格子放置炸弹
+// 只在延迟不超过补偿上限、且目标格子与当前格子相同或相邻时生效
+func (r *Room) placeLateBomb(playerID int32, inputFrame int32) {
+	player := r.game.GetPlayer(int(playerID))
+	if player == nil || player.Dead {
+		return
 	}
 
-	// ApplyInput 现在需要帧号而不是 deltaTime
-	placed := core.ApplyInput(r.game, int(playerID), ci, r.frameID)
-	if placed {
-		log.Printf("玩家 %d 放置炸弹", playerID)
+	gridX, gridY := player.GetGridPosition()
+	if r.frameID-inputFrame <= maxLagCompensationFrames {
+		// 输入帧 F 的移动结果记录在 F+1
+		sample, ok := r.posHistory.at(playerID, inputFrame+1)
+		if !ok {
+			sample, ok = r.posHistory.at(playerID, inputFrame)
+		}
+		if ok {
+			intended := sample.grid()
+			if abs(intended.GridX-gridX)+abs(intended.GridY-gridY) <= 1 {
+				gridX, gridY = intended.GridX, intended.GridY
+			}
+		}
 	}
+
+	bomb := player.PlaceBombAt(r.game, gridX, gridY, r.frameID)
+	if bomb == nil {
+		// 与按时到达的输入一样进入缓冲，冷却结束后在当前格子放出
+		player.BufferBomb(r.frameID)
+		return
+	}
+	player.BombBufferUntil = 0
+	r.game.AddBomb(bomb)
+	log.Printf("玩家 %d 放置炸弹（迟到 %d 帧，格子 %d,%d）", playerID, r.frameID-inputFrame, gridX, gridY)
 }
 
 func (r *Room) handleJoin(req joinRequest) {
+	if req.req.Spectate {
+		r.handleSpectatorJoin(req)
+		return
+	}
+
 	if r.state == StateEnding {
-		req.respCh <- fmt.Errorf("房间结算中，暂时无法加入")
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_ROOM_PLAYING, "房间结算中，暂时无法加入")
+		return
+	}
+
+	if req.req.TakeOverAI && r.state == StateRunning {
+		r.handleTakeoverJoin(req)
 		return
 	}
 
 	if len(r.connections)+len(r.aiControllers) >= MaxPlayers {
-		req.respCh <- fmt.Errorf("服务器已满 (%d/%d)", len(r.connections)+len(r.aiControllers), MaxPlayers)
+		current := len(r.connections) + len(r.aiControllers)
+		req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_FULL, countParams(current, MaxPlayers), "服务器已满 (%d/%d)", current, MaxPlayers)
 		return
 	}
 
 	if !r.legacyMode && r.state != StateWaiting {
-		req.respCh <- fmt.Errorf("房间游戏中，暂时无法加入")
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_ROOM_PLAYING, "房间游戏中，暂时无法加入")
 		return
 	}
 
@@ -376,7 +633,7 @@ func (r *Room) handleJoin(req joinRequest) {
 	r.nextPlayerID++
 
 	// 获取出生点
-	x, y := getSpawnPosition(int(playerID))
+	x, y := r.spawnPosition(int(playerID))
 
 	// 创建玩家
 	player := core.NewPlayer(int(playerID), x, y, characterType)
@@ -389,7 +646,14 @@ func (r *Room) handleJoin(req joinRequest) {
 	req.conn.SetRoomID(r.id)
 	r.connections[playerID] = req.conn
 	r.playerNames[playerID] = req.req.PlayerName
+	if req.req.Account != "" {
+		r.playerAccounts[playerID] = req.req.Account
+	}
+	if req.req.TelemetryOptIn {
+		r.telemetryOptIn[playerID] = true
+	}
 	r.playerCharacters[playerID] = characterType
+	r.playerTeams[playerID] = r.smallerTeam()
 	r.readyStatus[playerID] = false
 
 	if r.hostID == 0 {
@@ -401,6 +665,9 @@ func (r *Room) handleJoin(req joinRequest) {
 			}
 			r.roomName = fmt.Sprintf("%s's room", name)
 		}
+		if !r.legacyMode && r.state == StateWaiting {
+			r.applyProfilePreset(playerID)
+		}
 	}
 
 	// 生成会话 Token（用于重连）
@@ -413,14 +680,17 @@ func (r *Room) handleJoin(req joinRequest) {
 	// 发送 JoinResponse（包含玩家ID和游戏配置）
 	roomState := r.buildRoomState()
 	packet, err := protocol.NewJoinResponsePacket(
+		req.req.RequestID,
 		true,
 		playerID,
 		"",
-		r.game.Seed,
+		r.visibleSeed(),
 		int32(core.TPS),
 		sessionToken,
 		r.id,
 		roomState,
+		r.historySnapshot(),
+		req.req.Profile,
 	)
 	if err != nil {
 		req.respCh <- fmt.Errorf("构造加入响应失败: %w", err)
@@ -445,6 +715,7 @@ func (r *Room) handleJoin(req joinRequest) {
 
 	log.Printf("玩家 %d 加入，角色: %s, 出生点: (%d, %d)", playerID, characterType, x, y)
 	log.Printf("玩家 %d 加入响应已发送", playerID)
+	r.recordHistory(gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_JOINED, playerID, "")
 
 	if r.legacyMode {
 		if r.state != StateRunning {
@@ -460,7 +731,223 @@ func (r *Room) handleJoin(req joinRequest) {
 	req.respCh <- nil
 }
 
+// handleSpectatorJoin 处理观战者加入（不占用玩家位置，游戏中也可加入）
+func (r *Room) handleSpectatorJoin(req joinRequest) {
+	if r.legacyMode {
+		req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_LEGACY_UNSUPPORTED, []string{actionSpectate}, "兼容房间不支持观战")
+		return
+	}
+
+	if len(r.spectators) >= MaxSpectators {
+		req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_FULL, countParams(len(r.spectators), MaxSpectators), "观战人数已满 (%d/%d)", len(r.spectators), MaxSpectators)
+		return
+	}
+
+	spectatorID := r.nextSpectatorID
+	r.nextSpectatorID++
+
+	name := req.req.PlayerName
+	if name == "" {
+		name = fmt.Sprintf("Spectator%d", spectatorID-SpectatorIDBase)
+	}
+
+	req.conn.SetPlayerID(spectatorID)
+	req.conn.SetRoomID(r.id)
+	r.spectators[spectatorID] = req.conn
+	r.spectatorNames[spectatorID] = name
+
+	sessionToken, err := GenerateSessionToken(spectatorID, r.id)
+	if err != nil {
+		r.dropSpectator(spectatorID)
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "生成会话 Token 失败: %v", err)
+		return
+	}
+
+	packet, err := protocol.NewJoinResponsePacket(
+		req.req.RequestID,
+		true,
+		spectatorID,
+		"",
+		r.visibleSeed(),
+		int32(core.TPS),
+		sessionToken,
+		r.id,
+		r.buildRoomState(),
+		r.historySnapshot(),
+		nil,
+	)
+	if err != nil {
+		r.dropSpectator(spectatorID)
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "构造加入响应失败: %v", err)
+		return
+	}
+
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		r.dropSpectator(spectatorID)
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "序列化加入响应失败: %v", err)
+		return
+	}
+
+	if err := req.conn.Send(data); err != nil {
+		r.dropSpectator(spectatorID)
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "发送加入响应失败: %v", err)
+		return
+	}
+
+	log.Printf("观战者 %d (%s) 加入房间 %s", spectatorID, name, r.id)
+
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_SpectatorJoined{
+			SpectatorJoined: &gamev1.SpectatorJoinedEvent{
+				SpectatorId:    spectatorID,
+				Name:           name,
+				SpectatorCount: int32(len(r.spectators)),
+			},
+		},
+	})
+	r.broadcastRoomState()
+
+	req.respCh <- nil
+}
+
+// removeSpectator 移除观战者并通知房间内所有人
+func (r *Room) removeSpectator(spectatorID int32) {
+	name, ok := r.spectatorNames[spectatorID]
+	if !ok {
+		return
+	}
+	r.dropSpectator(spectatorID)
+
+	log.Printf("观战者 %d (%s) 离开房间 %s", spectatorID, name, r.id)
+
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_SpectatorLeft{
+			SpectatorLeft: &gamev1.SpectatorLeftEvent{
+				SpectatorId:    spectatorID,
+				Name:           name,
+				SpectatorCount: int32(len(r.spectators)),
+			},
+		},
+	})
+	r.broadcastRoomState()
+}
+
+// dropSpectator 清理观战者数据（不广播）
+func (r *Room) dropSpectator(spectatorID int32) {
+	if conn, ok := r.spectators[spectatorID]; ok {
+		conn.SetPlayerID(-1)
+		conn.SetRoomID("")
+	}
+	delete(r.spectators, spectatorID)
+	delete(r.spectatorNames, spectatorID)
+}
+
+// sendToSpectators 将已序列化的数据发送给所有观战者
+func (r *Room) sendToSpectators(data []byte) {
+	for _, conn := range r.spectators {
+		if err := conn.Send(data); err != nil {
+			log.Printf("发送数据到观战者 %d 失败: %v", conn.ID(), err)
+		}
+	}
+	for _, conn := range r.observers {
+		if err := conn.Send(data); err != nil {
+			log.Printf("发送数据到运维观察者 %d 失败: %v", conn.ID(), err)
+		}
+	}
+}
+
+// broadcastEvent 向房间内所有玩家和观战者广播游戏事件
+// sendEvent 向单个连接发送游戏事件
+func (r *Room) sendEvent(conn Session, event *gamev1.GameEvent) {
+	packet, err := protocol.NewGameEventPacket(r.frameID, event)
+	if err != nil {
+		log.Printf("构造游戏事件失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化游戏事件失败: %v", err)
+		return
+	}
+	if err := conn.Send(data); err != nil {
+		log.Printf("发送游戏事件到玩家 %d 失败: %v", conn.ID(), err)
+	}
+}
+
+// 关键事件（见 isCriticalEvent）带有 event_seq，玩家确认前会定期重发
+func (r *Room) broadcastEvent(event *gamev1.GameEvent) {
+	critical := isCriticalEvent(event)
+	if critical {
+		event.EventSeq = nextEventSeq()
+	}
+	packet, err := protocol.NewGameEventPacket(r.frameID, event)
+	if err != nil {
+		log.Printf("构造游戏事件失败: %v", err)
+		return
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		log.Printf("序列化游戏事件失败: %v", err)
+		return
+	}
+	for _, conn := range r.connections {
+		if critical {
+			r.trackPendingEvent(conn.ID(), event.EventSeq, data)
+		}
+		if err := conn.Send(data); err != nil {
+			log.Printf("发送游戏事件到玩家 %d 失败: %v", conn.ID(), err)
+		}
+	}
+	r.sendToSpectators(data)
+}
+
+// broadcastDoorCampPings 向所有人广播本帧产生的门口蹲守提示
+func (r *Room) broadcastDoorCampPings() {
+	for _, ping := range r.game.DoorCampPings {
+		r.broadcastEvent(&gamev1.GameEvent{
+			Event: &gamev1.GameEvent_DoorCampPing{
+				DoorCampPing: &gamev1.DoorCampPingEvent{
+					PlayerId: int32(ping.PlayerID),
+					GridX:    int32(ping.GridX),
+					GridY:    int32(ping.GridY),
+				},
+			},
+		})
+	}
+}
+
+// broadcastOvertimeCountdown 决斗加时触发后每秒广播一次倒计时，淹没时再广播一次（seconds_left = 0）
+func (r *Room) broadcastOvertimeCountdown() {
+	if !r.game.OvertimeTriggered() {
+		return
+	}
+	left := r.game.OvertimeFloodFrame - r.game.CurrentFrame
+	if left < 0 || left%core.TPS != 0 {
+		return
+	}
+	if left == core.OvertimeCountdownFrames {
+		log.Printf("房间 %s 进入决斗加时，%d 帧后淹没门口竞技场外的格子", r.id, left)
+	}
+	door := r.game.Map.HiddenDoorPos
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_OvertimeCountdown{
+			OvertimeCountdown: &gamev1.OvertimeCountdownEvent{
+				SecondsLeft: left / core.TPS,
+				FloodFrame:  r.game.OvertimeFloodFrame,
+				DoorX:       int32(door.X),
+				DoorY:       int32(door.Y),
+			},
+		},
+	})
+}
+
 func (r *Room) handleInput(ev inputEvent) {
+	// 事件确认在任何阶段都处理（游戏结束事件的确认到达时已不在游戏中）
+	if len(ev.input.AckEventSeqs) > 0 {
+		r.ackEvents(ev.playerID, ev.input.AckEventSeqs)
+	}
+
 	if r.state != StateRunning {
 		return
 	}
@@ -469,6 +956,10 @@ func (r *Room) handleInput(ev inputEvent) {
 		return
 	}
 
+	if ev.input.AckStateFrame > 0 {
+		r.stateAcks[ev.playerID] = ev.input.AckStateFrame
+	}
+
 	if len(ev.input.Inputs) == 0 {
 		return
 	}
@@ -479,10 +970,19 @@ func (r *Room) handleInput(ev inputEvent) {
 		r.inputQueue[ev.playerID] = queue
 	}
 
+	r.recordInputPacket(ev.playerID)
 	for _, in := range ev.input.Inputs {
+		r.recordInputDelay(ev.playerID, in.FrameID)
 		if in.FrameID < r.frameID-InputBufferFrames {
 			continue
 		}
+		// 已经错过的帧不会再被应用，但放弹意图需要保留，交给 placeLateBomb 处理
+		if in.FrameID < r.frameID {
+			if in.Bomb {
+				r.lateBombs[ev.playerID] = in.FrameID
+			}
+			continue
+		}
 		queue[in.FrameID] = in
 	}
 
@@ -494,6 +994,13 @@ func (r *Room) handleInput(ev inputEvent) {
 // handleLeave 处理玩家离开（网络断开或超时）
 // 默认为软删除（断线保护），除非超时
 func (r *Room) handleLeave(playerID int32) {
+	// 观战者断线直接移除（不占用玩家位置，不做断线保护），记下名字供会话令牌有效期内重连
+	if _, isSpectator := r.spectators[playerID]; isSpectator {
+		r.rememberOfflineSpectator(playerID)
+		r.removeSpectator(playerID)
+		return
+	}
+
 	// 如果是 AI，直接硬删除
 	if _, isAI := r.aiControllers[playerID]; isAI {
 		r.handleForceLeave(playerID)
@@ -508,11 +1015,17 @@ func (r *Room) handleLeave(playerID int32) {
 
 		// 清理连接相关但不清理游戏数据
 		delete(r.sendQueueFullAt, playerID)
+		delete(r.stateAcks, playerID)
+		delete(r.pendingEvents, playerID)
 		conn.SetPlayerID(-1)
 		conn.SetRoomID("")
 
 		// 不广播 PlayerLeft，也不从 game.Players 移除
-		// 这样玩家在游戏中会停留在原地
+		// 这样玩家在游戏中会停留在原地；房主权限立即交给在线的玩家
+		r.broadcastConnectionState(playerID, gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_OFFLINE)
+		if playerID == r.hostID {
+			r.migrateHost()
+		}
 		return
 	}
 
@@ -523,6 +1036,7 @@ func (r *Room) handleLeave(playerID int32) {
 }
 
 // IsEmpty 检查房间是否真正为空（包括离线保护中的玩家）
+// 观战者不会让房间保持存活
 // 注意：此方法从外部调用，不需要锁（Room 内部使用单线程模型）
 func (r *Room) IsEmpty() bool {
 	return len(r.connections) == 0 && len(r.offlinePlayers) == 0
@@ -556,13 +1070,24 @@ func (r *Room) handleForceLeave(playerID int32) {
 	if isHuman {
 		delete(r.inputQueue, playerID)
 		delete(r.sendQueueFullAt, playerID)
+		delete(r.stateAcks, playerID)
+		delete(r.pendingEvents, playerID)
 		delete(r.lastProcessedInputSeq, playerID)
 		delete(r.lastInput, playerID)
 	}
 
+	if isHuman {
+		// 名字随后删除，先记录
+		r.recordHistory(gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_LEFT, playerID, "")
+	}
+
 	delete(r.readyStatus, playerID)
+	delete(r.nextRoundReady, playerID)
 	delete(r.playerNames, playerID)
+	delete(r.playerAccounts, playerID)
+	delete(r.telemetryOptIn, playerID)
 	delete(r.playerCharacters, playerID)
+	delete(r.playerTeams, playerID)
 
 	r.removePlayerByID(playerID)
 
@@ -583,6 +1108,7 @@ func (r *Room) handleForceLeave(playerID int32) {
 			for _, c := range r.connections {
 				c.Send(data)
 			}
+			r.sendToSpectators(data)
 		}
 	}
 
@@ -601,64 +1127,87 @@ func (r *Room) handleForceLeave(playerID int32) {
 
 func (r *Room) handleRoomAction(req roomActionRequest) {
 	if req.action == nil {
-		req.respCh <- errors.New("房间操作为空")
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "房间操作为空")
 		return
 	}
 
 	if r.legacyMode && req.action.Type != gamev1.RoomActionType_ROOM_ACTION_LEAVE {
-		req.respCh <- errors.New("兼容房间不支持该操作")
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_LEGACY_UNSUPPORTED, "兼容房间不支持该操作")
 		return
 	}
 
 	switch req.action.Type {
 	case gamev1.RoomActionType_ROOM_ACTION_READY:
+		if r.state == StateEnding {
+			// 结算期间记下准备状态，下一轮生效
+			if _, ok := r.connections[req.playerID]; !ok {
+				req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", req.playerID)
+				return
+			}
+			r.nextRoundReady[req.playerID] = req.action.Ready
+			break
+		}
 		if r.state != StateWaiting {
-			req.respCh <- errors.New("游戏中无法准备")
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionReady}, "游戏中无法准备")
 			return
 		}
 		if _, ok := r.connections[req.playerID]; !ok {
-			req.respCh <- errors.New("玩家不在房间中")
+			req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", req.playerID)
 			return
 		}
 		r.readyStatus[req.playerID] = req.action.Ready
+		kind := gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_UNREADY
+		if req.action.Ready {
+			kind = gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_READY
+		}
+		r.recordHistory(kind, req.playerID, "")
 		r.broadcastRoomState()
 
 	case gamev1.RoomActionType_ROOM_ACTION_START:
-		if ok, msg := r.CanStart(req.playerID); !ok {
-			req.respCh <- errors.New(msg)
+		if err := r.CanStart(req.playerID); err != nil {
+			req.respCh <- err
 			return
 		}
 		r.startGame()
 
 	case gamev1.RoomActionType_ROOM_ACTION_ADD_AI:
 		if req.playerID != r.hostID {
-			req.respCh <- errors.New("只有房主可以添加 AI")
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionAddAI}, "只有房主可以添加 AI")
 			return
 		}
 		if r.state != StateWaiting {
-			req.respCh <- errors.New("游戏中无法添加 AI")
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionAddAI}, "游戏中无法添加 AI")
 			return
 		}
 		if !r.enableAI {
-			req.respCh <- errors.New("服务器未启用 AI")
+			req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_AI_DISABLED, "服务器未启用 AI")
 			return
 		}
-		if err := r.addAI(int(req.action.AiCount)); err != nil {
+		script, err := r.parseAIScript(req.action.AiScript)
+		if err != nil {
+			req.respCh <- err
+			return
+		}
+		if err := r.addAI(int(req.action.AiCount), script, aiDifficultyFromProto(req.action.AiDifficulty)); err != nil {
 			req.respCh <- err
 			return
 		}
 		r.broadcastRoomState()
 
 	case gamev1.RoomActionType_ROOM_ACTION_LEAVE:
+		if _, ok := r.spectators[req.playerID]; ok {
+			r.removeSpectator(req.playerID)
+			break
+		}
 		if _, ok := r.connections[req.playerID]; !ok {
-			req.respCh <- errors.New("玩家不在房间中")
+			req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", req.playerID)
 			return
 		}
 		r.handleForceLeave(req.playerID)
 
 	case gamev1.RoomActionType_ROOM_ACTION_KICK:
 		if req.playerID != r.hostID {
-			req.respCh <- errors.New("只有房主可以踢人")
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionKick}, "只有房主可以踢人")
 			return
 		}
 		if err := r.kickPlayer(req.action.TargetPlayer); err != nil {
@@ -666,26 +1215,169 @@ func (r *Room) handleRoomAction(req roomActionRequest) {
 			return
 		}
 
+	case gamev1.RoomActionType_ROOM_ACTION_REROLL_SEED:
+		if req.playerID != r.hostID {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionRerollSeed}, "只有房主可以更换地图种子")
+			return
+		}
+		if r.state != StateWaiting {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionRerollSeed}, "游戏中无法更换地图种子")
+			return
+		}
+		r.rerollSeed()
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_SET_RULES:
+		if req.playerID != r.hostID {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionSetRules}, "只有房主可以修改规则")
+			return
+		}
+		if r.state != StateWaiting {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionSetRules}, "游戏中无法修改规则")
+			return
+		}
+		r.setRules(protocol.ProtoRulesToCore(req.action.Rules))
+		r.saveHostPreset()
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_SET_CONFIG:
+		if req.playerID != r.hostID {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionSetConfig}, "只有房主可以修改对局参数")
+			return
+		}
+		if r.state != StateWaiting {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionSetConfig}, "游戏中无法修改对局参数")
+			return
+		}
+		r.config = protocol.ProtoMatchConfigToCore(req.action.Config)
+		log.Printf("房间 %s 对局参数已更新: %+v", r.id, r.config)
+		r.saveHostPreset()
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_SET_MAP:
+		if err := r.setMap(req.playerID, req.action.MapName); err != nil {
+			req.respCh <- err
+			return
+		}
+		r.saveHostPreset()
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_CHAT:
+		if err := r.handleChat(req.playerID, req.action.ChatText); err != nil {
+			req.respCh <- err
+			return
+		}
+
+	case gamev1.RoomActionType_ROOM_ACTION_REPORT:
+		if err := r.handleReport(req.playerID, req.action.TargetPlayer, req.action.ReportReason); err != nil {
+			req.respCh <- err
+			return
+		}
+
+	case gamev1.RoomActionType_ROOM_ACTION_PROPOSE:
+		if err := r.propose(req.playerID, req.action.Proposal); err != nil {
+			req.respCh <- err
+			return
+		}
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_VOTE:
+		if err := r.vote(req.playerID, req.action.TargetPlayer, req.action.Approve); err != nil {
+			req.respCh <- err
+			return
+		}
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_RESOLVE_PROPOSAL:
+		if err := r.resolveProposal(req.playerID, req.action.TargetPlayer, req.action.Approve); err != nil {
+			req.respCh <- err
+			return
+		}
+		r.broadcastRoomState()
+
+	case gamev1.RoomActionType_ROOM_ACTION_APPROVE_TAKEOVER:
+		if req.playerID != r.hostID {
+			req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionTakeover}, "只有房主可以审批接管")
+			return
+		}
+		if err := r.handleTakeoverDecision(req.action.TargetPlayer, req.action.Approve); err != nil {
+			req.respCh <- err
+			return
+		}
+
+	case gamev1.RoomActionType_ROOM_ACTION_START_WITHOUT_UNREADY:
+		if err := r.startWithoutUnready(req.playerID); err != nil {
+			req.respCh <- err
+			return
+		}
+
+	case gamev1.RoomActionType_ROOM_ACTION_SET_TEAM:
+		if err := r.setTeam(req.playerID, req.action.TargetPlayer, int(req.action.Team)); err != nil {
+			req.respCh <- err
+			return
+		}
+		r.broadcastRoomState()
+
 	default:
-		req.respCh <- errors.New("未知房间操作")
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "未知房间操作: %v", req.action.Type)
 		return
 	}
 
 	req.respCh <- nil
 }
 
-// CanStart 检查是否可以开始游戏
-func (r *Room) CanStart(requestorID int32) (bool, string) {
+// setRules 修改房间规则
+func (r *Room) setRules(rules core.GameRules) {
+	// 刚开启公平种子时当前种子已经公开过，换一个新种子重新承诺
+	reroll := rules.FairSeed && !r.rules.FairSeed
+	r.rules = rules
+	if reroll {
+		r.rerollSeed()
+	}
+	log.Printf("房间 %s 规则已更新: %+v", r.id, r.rules)
+}
+
+// rerollSeed 重新随机地图种子并重建地图
+// 地图变化后取消所有真人玩家的准备状态，需要重新确认
+func (r *Room) rerollSeed() {
+	seed := r.freshSeed()
+	r.seed = seed
+	r.game.Seed = seed
+	r.game.Map = r.newGameMap(seed)
+
+	for playerID := range r.connections {
+		r.readyStatus[playerID] = false
+	}
+	log.Printf("房间 %s 地图种子已更换: %d", r.id, seed)
+}
+
+// freshSeed 生成一个与当前不同的新种子
+func (r *Room) freshSeed() int64 {
+	seed := time.Now().UnixNano()
+	for seed == r.seed {
+		seed++
+	}
+	return seed
+}
+
+// minPlayersToStart 开始游戏的最少人数（含 AI）
+const minPlayersToStart = 2
+
+// CanStart 检查是否可以开始游戏，不满足时返回带错误码的错误
+func (r *Room) CanStart(requestorID int32) error {
+	if r.state == StateEnding {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionStart}, "对局结算中，返回房间后才能开始")
+	}
 	if r.state != StateWaiting {
-		return false, "Game already started"
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionStart}, "游戏已经开始")
 	}
 	if requestorID != r.hostID {
-		return false, "Only host can start game"
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionStart}, "只有房主可以开始游戏")
 	}
 
 	totalPlayers := len(r.connections) + len(r.aiControllers)
-	if totalPlayers < 2 {
-		return false, "Need at least 2 players"
+	if totalPlayers < minPlayersToStart {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_ENOUGH_PLAYERS, countParams(totalPlayers, minPlayersToStart), "人数不足 (%d/%d)", totalPlayers, minPlayersToStart)
 	}
 
 	for playerID := range r.connections {
@@ -693,11 +1385,17 @@ func (r *Room) CanStart(requestorID int32) (bool, string) {
 			continue
 		}
 		if !r.readyStatus[playerID] {
-			return false, "Some players not ready"
+			return newRoomError(gamev1.ErrorCode_ERROR_CODE_PLAYERS_NOT_READY, "玩家 %d 未准备", playerID)
+		}
+	}
+
+	if r.rules.Teams {
+		if err := r.checkTeamsBalanced(); err != nil {
+			return err
 		}
 	}
 
-	return true, ""
+	return nil
 }
 
 func (r *Room) startGame() {
@@ -705,23 +1403,44 @@ func (r *Room) startGame() {
 		return
 	}
 	r.state = StateRunning
+	r.game.Rules = r.rules
+	r.game.ApplyMatchConfig(r.config)
+	r.applyTeams()
 	r.initMatchTimer()
 	r.inputQueue = make(map[int32]map[int32]InputData)
 	r.lastInput = make(map[int32]InputData)
 	r.lastProcessedInputSeq = make(map[int32]int32)
-	r.lastPlayerDeadState = make(map[int32]bool)
+	r.killsBroadcast = 0
+	r.inputDelays = make(map[int32]*inputLatency)
+	r.proposals = nil // 未处理的提议随开局作废
+	r.beginRound()
+	r.posHistory.reset()
+	r.lateBombs = make(map[int32]int32)
+	r.scenarioTileChanges = nil
+	r.suddenDeathChanges = nil
+	r.spawnBoss()
+	r.resetReadyStall()
 	r.offlinePlayers = make(map[int32]time.Time) // 清理离线玩家
+	r.offlinePausedAt = time.Time{}
+	r.markReplayStart()
+	r.refreshSessionTokens(time.Now()) // 一局可能比令牌剩余的有效期长
 
 	r.broadcastRoomState()
 	r.broadcastGameStart(0)
 }
 
 func (r *Room) initMatchTimer() {
-	if core.MatchDurationFrames <= 0 {
+	r.game.SuddenDeathStartFrame = 0
+	r.game.MatchEndFrame = 0
+	if r.game.Config.MatchDurationFrames <= 0 {
 		r.matchEndFrame = 0
 		return
 	}
-	r.matchEndFrame = r.game.CurrentFrame + core.MatchDurationFrames
+	r.matchEndFrame = r.game.CurrentFrame + r.game.Config.MatchDurationFrames
+	r.game.MatchEndFrame = r.matchEndFrame // AI 据此在限时将尽时改变策略
+	if r.game.Rules.SuddenDeath {
+		r.game.SuddenDeathStartFrame = max(r.game.CurrentFrame+1, r.matchEndFrame-core.SuddenDeathFrames)
+	}
 }
 
 func (r *Room) isMatchTimedOut() bool {
@@ -746,27 +1465,27 @@ func (r *Room) spawnMatchEndExplosion() {
 		CreatedAtFrame: r.frameID,
 		ExpiresAtFrame: r.frameID + core.BombExplosionFrames,
 		OwnerID:        -1,
-		Cells:          make([]core.GridPos, 0, core.MapWidth*core.MapHeight),
+		Cells:          make([]core.GridPos, 0, r.game.Map.Width*r.game.Map.Height),
 	}
 
-	for y := 0; y < core.MapHeight; y++ {
-		for x := 0; x < core.MapWidth; x++ {
+	for y := 0; y < r.game.Map.Height; y++ {
+		for x := 0; x < r.game.Map.Width; x++ {
 			explosion.Cells = append(explosion.Cells, core.GridPos{GridX: x, GridY: y})
 		}
 	}
 
-	r.game.Explosions = append(r.game.Explosions, explosion)
+	r.game.AddExplosion(explosion)
 }
 
-func (r *Room) transferHost() {
-	r.hostID = 0
-	for playerID := range r.connections {
-		r.hostID = playerID
-		break
-	}
+// newAIController 创建指定难度的行为树 AI（服务器加载了行为树配置时使用配置的树）
+func (r *Room) newAIController(playerID int32, difficulty ai.Difficulty) *ai.AIController {
+	controller := ai.NewAIController(int(playerID), difficulty)
+	controller.SetTree(r.aiTree)
+	return controller
 }
 
-func (r *Room) addAI(count int) error {
+// addAI 添加指定难度的 AI，script 非 nil 时添加按脚本回放输入的 AI（忽略难度）
+func (r *Room) addAI(count int, script *ai.Script, difficulty ai.Difficulty) error {
 	if count <= 0 {
 		return nil
 	}
@@ -781,22 +1500,29 @@ func (r *Room) addAI(count int) error {
 		playerID := r.nextPlayerID
 		r.nextPlayerID++
 
-		x, y := getSpawnPosition(int(playerID))
+		x, y := r.spawnPosition(int(playerID))
 		charType := availableChars[(playerID-1)%int32(len(availableChars))]
 
 		player := core.NewPlayer(int(playerID), x, y, charType)
 		r.game.AddPlayer(player)
 
-		r.aiControllers[playerID] = ai.NewAIController(int(playerID))
-		r.playerNames[playerID] = fmt.Sprintf("AI-%d", playerID)
+		if script != nil {
+			r.aiControllers[playerID] = ai.NewScriptedController(int(playerID), script)
+			r.playerNames[playerID] = fmt.Sprintf("Script-%d", playerID)
+		} else {
+			r.aiControllers[playerID] = r.newAIController(playerID, difficulty)
+			r.playerNames[playerID] = r.pickAIName(playerID)
+		}
 		r.playerCharacters[playerID] = charType
+		r.playerTeams[playerID] = r.smallerTeam()
 		r.readyStatus[playerID] = true
 
 		count--
 	}
 
 	if count > 0 {
-		return errors.New("房间已满，无法继续添加 AI")
+		current := len(r.connections) + len(r.aiControllers)
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_FULL, countParams(current, MaxPlayers), "房间已满，无法继续添加 AI")
 	}
 	return nil
 }
@@ -804,23 +1530,36 @@ func (r *Room) addAI(count int) error {
 func (r *Room) kickPlayer(targetID int32) error {
 	conn, ok := r.connections[targetID]
 	if !ok {
-		return errors.New("目标玩家不在房间中")
-	}
-
-	sessionToken, err := GenerateSessionToken(0, "")
-	if err == nil {
-		packet, err := protocol.NewRoomActionResponsePacket(false, "你已被踢出房间", sessionToken, "")
-		if err == nil {
-			if data, err := protocol.MarshalPacket(packet); err == nil {
-				_ = conn.Send(data)
-			}
+		// 对局中断线的玩家也可以踢出，不必等离线保护超时
+		if _, offline := r.offlinePlayers[targetID]; offline {
+			r.handleForceLeave(targetID)
+			return nil
 		}
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND, "目标玩家 %d 不在房间中", targetID)
 	}
 
-	r.handleLeave(targetID)
+	r.sendRemoved(conn, gamev1.ErrorCode_ERROR_CODE_KICKED, "你已被踢出房间")
+
+	// 被踢出的玩家不享受断线保护，直接彻底移除
+	r.handleForceLeave(targetID)
 	return nil
 }
 
+// sendRemoved 通知连接已被移出房间（客户端据此返回大厅）
+func (r *Room) sendRemoved(conn Session, code gamev1.ErrorCode, message string) {
+	sessionToken, err := GenerateSessionToken(0, "")
+	if err != nil {
+		return
+	}
+	packet, err := protocol.NewRoomActionResponsePacket(0, false, code, nil, message, sessionToken, "")
+	if err != nil {
+		return
+	}
+	if data, err := protocol.MarshalPacket(packet); err == nil {
+		_ = conn.Send(data)
+	}
+}
+
 func (r *Room) buildRoomState() *gamev1.RoomStateUpdate {
 	status := gamev1.RoomStatus_ROOM_STATUS_WAITING
 	if r.state == StateRunning || r.state == StateEnding {
@@ -839,7 +1578,7 @@ func (r *Room) buildRoomState() *gamev1.RoomStateUpdate {
 	players := make([]*gamev1.RoomPlayer, 0, len(playerIDs))
 	for _, id := range playerIDs {
 		playerID := int32(id)
-		_, isAI := r.aiControllers[playerID]
+		controller, isAI := r.aiControllers[playerID]
 		name := r.playerNames[playerID]
 		if name == "" {
 			if isAI {
@@ -852,26 +1591,62 @@ func (r *Room) buildRoomState() *gamev1.RoomStateUpdate {
 		if isAI {
 			ready = true
 		}
+		difficulty := gamev1.AIDifficulty_AI_DIFFICULTY_UNSPECIFIED
+		if isAI {
+			difficulty = aiDifficultyToProto(controller.Difficulty())
+		}
 		charType := protocol.CoreCharacterTypeToProto(r.playerCharacters[playerID])
 		players = append(players, &gamev1.RoomPlayer{
-			Id:        playerID,
-			Name:      name,
-			Character: charType,
-			IsReady:   ready,
-			IsHost:    playerID == r.hostID,
-			IsAi:      isAI,
+			Id:           playerID,
+			Name:         name,
+			Character:    charType,
+			CharacterId:  r.playerCharacters[playerID].ID(),
+			IsReady:      ready,
+			IsHost:       playerID == r.hostID,
+			IsAi:         isAI,
+			AiDifficulty: difficulty,
+			Team:         int32(r.playerTeams[playerID]),
 		})
 	}
 
+	spectatorIDs := make([]int, 0, len(r.spectators))
+	for spectatorID := range r.spectators {
+		spectatorIDs = append(spectatorIDs, int(spectatorID))
+	}
+	sort.Ints(spectatorIDs)
+
+	spectatorNames := make([]string, 0, len(spectatorIDs))
+	for _, id := range spectatorIDs {
+		spectatorNames = append(spectatorNames, r.spectatorNames[int32(id)])
+	}
+
+	commitment, salt := r.seedReveal()
 	return &gamev1.RoomStateUpdate{
-		RoomId:  r.id,
-		Status:  status,
-		Players: players,
-		HostId:  r.hostID,
+		RoomId:         r.id,
+		Status:         status,
+		Players:        players,
+		HostId:         r.hostID,
+		SpectatorNames: spectatorNames,
+		SpectatorCount: int32(len(spectatorNames)),
+		Seed:           r.visibleSeed(),
+		Rules:          protocol.CoreRulesToProto(r.rules),
+		Config:         protocol.CoreMatchConfigToProto(r.config),
+		MapId:          core.MapID(r.mapLayout(), r.rules),
+		SeedCommitment: commitment,
+		SeedSalt:       salt,
+		CustomMap:      r.customMapName(),
+		CustomMapJson:  r.customMapJSON(),
+		MapChoices:     r.maps.Names(),
+		MaxPlayers:     MaxPlayers,
+		Proposals:      r.proposalsProto(),
+		Series:         r.seriesScore(),
 	}
 }
 
 func (r *Room) broadcastRoomState() {
+	// 人数变化后都会广播房间状态，在这里按人数换地图、清理离开的玩家的提议
+	r.fitMapToRoster()
+	r.pruneProposals()
 	update := r.buildRoomState()
 	packet, err := protocol.NewRoomStateUpdatePacket(update)
 	if err != nil {
@@ -888,31 +1663,17 @@ func (r *Room) broadcastRoomState() {
 			log.Printf("发送房间状态到玩家 %d 失败: %v", conn.ID(), err)
 		}
 	}
+	r.sendToSpectators(data)
 }
 
 func (r *Room) broadcastGameStart(countdownFrames int32) {
-	event := &gamev1.GameEvent{
+	r.broadcastEvent(&gamev1.GameEvent{
 		Event: &gamev1.GameEvent_GameStart{
 			GameStart: &gamev1.GameStartEvent{
 				CountdownFrames: countdownFrames,
 			},
 		},
-	}
-	packet, err := protocol.NewGameEventPacket(r.frameID, event)
-	if err != nil {
-		log.Printf("构造游戏开始事件失败: %v", err)
-		return
-	}
-	data, err := protocol.MarshalPacket(packet)
-	if err != nil {
-		log.Printf("序列化游戏开始事件失败: %v", err)
-		return
-	}
-	for _, conn := range r.connections {
-		if err := conn.Send(data); err != nil {
-			log.Printf("发送游戏开始到玩家 %d 失败: %v", conn.ID(), err)
-		}
-	}
+	})
 }
 
 func (r *Room) handleGameOver(winnerID int32) {
@@ -925,6 +1686,11 @@ func (r *Room) handleGameOver(winnerID int32) {
 
 	log.Printf("游戏结束，获胜者: %d", winnerID)
 
+	r.recordMatchStats(winnerID)
+	r.indexReplay(winnerID)
+	if r.round > 0 && !r.finishRound(winnerID) {
+		return // 系列赛还没结束，回合间歇后开始下一回合
+	}
 	r.broadcastGameOver(winnerID)
 }
 
@@ -943,48 +1709,71 @@ func (r *Room) resetRoom() {
 		r.connections = make(map[int32]Session)
 		r.inputQueue = make(map[int32]map[int32]InputData)
 		r.sendQueueFullAt = make(map[int32]time.Time)
+		r.resetStateHistory()
+		r.pendingEvents = make(map[int32][]*pendingEvent)
 		r.lastProcessedInputSeq = make(map[int32]int32)
 		r.lastInput = make(map[int32]InputData)
-		r.lastPlayerDeadState = make(map[int32]bool)
+		r.killsBroadcast = 0
 		r.readyStatus = make(map[int32]bool)
 		r.playerNames = make(map[int32]string)
+		r.telemetryOptIn = make(map[int32]bool)
 		r.playerCharacters = make(map[int32]core.CharacterType)
+		r.playerTeams = make(map[int32]int)
 		r.hostID = 0
 		r.roomName = ""
+		r.spectators = make(map[int32]Session)
+		r.spectatorNames = make(map[int32]string)
+		r.offlineSpectators = make(map[int32]offlineSpectator)
+		r.nextRoundReady = make(map[int32]bool)
 
 		log.Println("房间已重置，等待新玩家加入")
 		return
 	}
 
 	// 非兼容房间：保留连接，重置游戏状态
+	// 结算期间断线的玩家没有对局可以恢复，直接移除（清理名字、准备状态并通知其他人）
+	for playerID := range r.offlinePlayers {
+		r.handleForceLeave(playerID)
+	}
+
 	oldAI := r.aiControllers
 	r.aiControllers = make(map[int32]*ai.AIController)
+	// 公平种子：上一局的种子已经公开，下一局换新种子重新承诺
+	if r.rules.FairSeed {
+		r.seed = r.freshSeed()
+	}
 	r.game = core.NewGame(r.seed)
+	r.game.Map = r.newGameMap(r.seed)
 	r.frameID = 0
 	r.state = StateWaiting
 	r.resetAt = time.Time{}
 	r.matchEndFrame = 0
 	r.inputQueue = make(map[int32]map[int32]InputData)
 	r.sendQueueFullAt = make(map[int32]time.Time)
+	r.resetStateHistory()
+	r.pendingEvents = make(map[int32][]*pendingEvent)
 	r.lastProcessedInputSeq = make(map[int32]int32)
 	r.lastInput = make(map[int32]InputData)
-	r.lastPlayerDeadState = make(map[int32]bool)
+	r.killsBroadcast = 0
 	r.offlinePlayers = make(map[int32]time.Time)
+	r.offlinePausedAt = time.Time{}
 
 	for playerID := range r.connections {
 		charType := r.playerCharacters[playerID]
-		x, y := getSpawnPosition(int(playerID))
+		x, y := r.spawnPosition(int(playerID))
 		player := core.NewPlayer(int(playerID), x, y, charType)
 		r.game.AddPlayer(player)
-		r.readyStatus[playerID] = false
+		r.readyStatus[playerID] = r.nextRoundReady[playerID]
 	}
+	r.nextRoundReady = make(map[int32]bool)
 
-	for playerID := range oldAI {
+	for playerID, controller := range oldAI {
 		charType := r.playerCharacters[playerID]
-		x, y := getSpawnPosition(int(playerID))
+		x, y := r.spawnPosition(int(playerID))
 		player := core.NewPlayer(int(playerID), x, y, charType)
 		r.game.AddPlayer(player)
-		r.aiControllers[playerID] = ai.NewAIController(int(playerID))
+		controller.Reset()
+		r.aiControllers[playerID] = controller
 		r.readyStatus[playerID] = true
 	}
 
@@ -994,6 +1783,7 @@ func (r *Room) resetRoom() {
 		}
 	}
 
+	r.touchActivity()
 	r.broadcastRoomState()
 	log.Println("房间已重置，返回等待状态")
 }
@@ -1006,55 +1796,46 @@ func (r *Room) closeAllConnections(notify bool) {
 			conn.CloseWithoutNotify()
 		}
 	}
+	for _, conn := range r.spectators {
+		conn.CloseWithoutNotify()
+	}
 }
 
 func (r *Room) broadcastState() {
-	// 转换玩家列表
-	protoPlayers := protocol.CorePlayersToProto(r.game.Players)
-
-	// 转换炸弹列表
-	protoBombs := protocol.CoreBombsToProto(r.game.Bombs)
-
-	// 转换爆炸列表
-	protoExplosions := protocol.CoreExplosionsToProto(r.game.Explosions)
-
-	// 收集地图变化（从爆炸中收集）
-	var tileChanges []*gamev1.TileChange
-	for _, exp := range r.game.Explosions {
-		for _, tc := range exp.TileChanges {
-			tileChanges = append(tileChanges, &gamev1.TileChange{
-				X:       int32(tc.GridX),
-				Y:       int32(tc.GridY),
-				NewType: gamev1.TileType(tc.NewType),
-			})
-		}
-	}
-
-	// 构造 GameState 消息（使用帧！）
-	packet, err := protocol.NewGameStatePacket(
-		r.frameID,
-		protocol.CoreGameStateToProto(int(r.state)),
-		protoPlayers,
-		protoBombs,
-		protoExplosions,
-		tileChanges,
-		r.lastProcessedInputSeq,
-		r.matchEndFrame,
-	)
+	// 与重连使用同一份完整状态，避免两处字段不一致
+	state := r.BuildGameState()
+	packet, err := protocol.NewGameStatePacketFromState(state)
 	if err != nil {
 		log.Printf("构造游戏状态失败: %v", err)
 		return
 	}
 
 	// 序列化
-	data, err := protocol.MarshalPacket(packet)
+	full, err := protocol.MarshalPacket(packet)
 	if err != nil {
 		log.Printf("序列化状态失败: %v", err)
 		return
 	}
+	r.recordStateHistory(state)
 
-	// 发送到所有连接
+	// 发送到所有连接：确认过基线的玩家收到增量，其余收到完整状态；开启兴趣管理时都只包含玩家周围的实体
+	deltas := make(map[int32][]byte)
+	interest := r.interestEnabled()
+	var all entitySet
+	if interest {
+		all = entitiesOf(state)
+	}
 	for _, conn := range r.connections {
+		var data []byte
+		var view *gamev1.GameState
+		if interest {
+			data, view = r.interestStateDataFor(conn.ID(), state)
+		} else {
+			data = r.stateDataFor(conn.ID(), state, full, deltas)
+		}
+		if data == nil {
+			continue
+		}
 		if err := conn.Send(data); err != nil {
 			if errors.Is(err, ErrSendQueueFull) {
 				r.handleSendQueueFull(conn)
@@ -1065,13 +1846,27 @@ func (r *Room) broadcastState() {
 			continue
 		}
 		delete(r.sendQueueFullAt, conn.ID())
+		if interest {
+			r.sendVisibilityChanges(conn, view, all)
+		}
+	}
+	if interest {
+		r.interestAll = all
 	}
+	// 观战者与录制没有确认机制，始终发送完整状态
+	r.sendToSpectators(full)
+	r.scenarioTileChanges = nil
+	r.suddenDeathChanges = nil
+	r.bossTileChanges = nil
 }
 
 // BuildGameState 构建当前游戏状态（用于重连）
 func (r *Room) BuildGameState() *gamev1.GameState {
 	// 转换玩家列表
 	protoPlayers := protocol.CorePlayersToProto(r.game.Players)
+	for _, p := range protoPlayers {
+		p.CurrentBombs = int32(r.game.ActiveBombCount(int(p.Id)))
+	}
 
 	// 转换炸弹列表
 	protoBombs := protocol.CoreBombsToProto(r.game.Bombs)
@@ -1090,6 +1885,15 @@ func (r *Room) BuildGameState() *gamev1.GameState {
 			})
 		}
 	}
+	for _, changes := range [][]core.TileChange{r.scenarioTileChanges, r.suddenDeathChanges, r.bossTileChanges} {
+		for _, tc := range changes {
+			tileChanges = append(tileChanges, &gamev1.TileChange{
+				X:       int32(tc.GridX),
+				Y:       int32(tc.GridY),
+				NewType: gamev1.TileType(tc.NewType),
+			})
+		}
+	}
 
 	// 复制 lastProcessedInputSeq
 	lastProcessedSeq := make(map[int32]int32, len(r.lastProcessedInputSeq))
@@ -1097,7 +1901,7 @@ func (r *Room) BuildGameState() *gamev1.GameState {
 		lastProcessedSeq[k] = v
 	}
 
-	return &gamev1.GameState{
+	state := &gamev1.GameState{
 		FrameId:          r.frameID,
 		Phase:            protocol.CoreGameStateToProto(int(r.state)),
 		Players:          protoPlayers,
@@ -1106,16 +1910,28 @@ func (r *Room) BuildGameState() *gamev1.GameState {
 		TileChanges:      tileChanges,
 		LastProcessedSeq: lastProcessedSeq,
 		MatchEndFrame:    r.matchEndFrame,
+		Items:            protocol.CoreItemsToProto(r.game.Items),
+		PlayerEffects:    protocol.CorePlayersEffectsToProto(r.game.Players, r.frameID),
+		Hazards:          protocol.CoreHazardsToProto(r.game.HazardOverlays()),
+		WarningTiles:     protocol.CoreGridCellsToProto(r.game.SuddenDeathWarnings()),
+
+		OvertimeFloodFrame: r.game.OvertimeFloodFrame,
+		Boss:               protocol.CoreBossToProto(r.game.Boss),
+	}
+	// 定期附带校验和，客户端据此发现与服务器不同步
+	if r.frameID%core.ChecksumIntervalFrames == 0 {
+		state.Checksum = protocol.CoreChecksumToProto(r.game.Checksum())
 	}
+	return state
 }
 
-// TryReconnect 尝试重连玩家（线程安全）
-func (r *Room) TryReconnect(playerID int32, newConn Session) bool {
-	respCh := make(chan bool, 1)
+// TryReconnect 尝试重连玩家（线程安全），成功时返回房间历史
+func (r *Room) TryReconnect(playerID int32, newConn Session) ([]*gamev1.RoomHistoryEntry, bool) {
+	respCh := make(chan reconnectResult, 1)
 
 	select {
 	case <-r.ctx.Done():
-		return false
+		return nil, false
 	case r.reconnectCh <- reconnectRequest{
 		playerID: playerID,
 		conn:     newConn,
@@ -1125,9 +1941,9 @@ func (r *Room) TryReconnect(playerID int32, newConn Session) bool {
 
 	select {
 	case <-r.ctx.Done():
-		return false
-	case success := <-respCh:
-		return success
+		return nil, false
+	case result := <-respCh:
+		return result.history, result.ok
 	}
 }
 
@@ -1142,8 +1958,12 @@ func (r *Room) handleReconnect(req reconnectRequest) {
 			// 不过这可能会导致并发问题，所以只替换引用是安全的
 		}
 		r.connections[req.playerID] = req.conn
+		// 新连接的客户端没有旧的基线状态，等它重新确认；重连后的完整状态已包含未确认事件的结果
+		delete(r.stateAcks, req.playerID)
+		delete(r.pendingEvents, req.playerID)
 		log.Printf("玩家 %d 在线重连，连接已替换", req.playerID)
-		req.respCh <- true
+		r.sendOfflineStates(req.conn)
+		req.respCh <- reconnectResult{ok: true, history: r.historySnapshot()}
 		return
 	}
 
@@ -1155,13 +1975,25 @@ func (r *Room) handleReconnect(req reconnectRequest) {
 
 		// 重置相关的状态
 		delete(r.sendQueueFullAt, req.playerID)
+		delete(r.stateAcks, req.playerID)
+		delete(r.pendingEvents, req.playerID)
 
 		log.Printf("玩家 %d 从离线状态重连成功", req.playerID)
-		req.respCh <- true
+		r.broadcastConnectionState(req.playerID, gamev1.PlayerConnectionState_PLAYER_CONNECTION_STATE_RECONNECTED)
+		r.sendOfflineStates(req.conn)
+		if r.hostID == 0 {
+			r.migrateHost()
+		}
+		req.respCh <- reconnectResult{ok: true, history: r.historySnapshot()}
+		return
+	}
+
+	if req.playerID >= SpectatorIDBase {
+		req.respCh <- reconnectResult{ok: r.reconnectSpectator(req.playerID, req.conn), history: r.historySnapshot()}
 		return
 	}
 
-	req.respCh <- false
+	req.respCh <- reconnectResult{}
 }
 
 const InputBufferFrames = 120
@@ -1211,30 +2043,16 @@ func (r *Room) handleSendQueueFull(conn Session) {
 
 func (r *Room) broadcastGameOver(winnerID int32) {
 	// 广播游戏结束事件
-	event := &gamev1.GameEvent{
+	r.broadcastEvent(&gamev1.GameEvent{
 		Event: &gamev1.GameEvent_GameOver{
 			GameOver: &gamev1.GameOverEvent{
-				WinnerId: winnerID,
+				WinnerId:     winnerID,
+				WinningTeam:  r.winningTeam(winnerID),
+				PlayerStats:  r.buildPlayerMatchStats(),
+				BossDefeated: r.game.Boss != nil && r.game.Boss.Defeated(),
 			},
 		},
-	}
-	packet, err := protocol.NewGameEventPacket(r.frameID, event)
-	if err != nil {
-		log.Printf("构造游戏结束事件失败: %v", err)
-		return
-	}
-
-	data, err := protocol.MarshalPacket(packet)
-	if err != nil {
-		log.Printf("序列化游戏结束事件失败: %v", err)
-		return
-	}
-
-	for _, conn := range r.connections {
-		if err := conn.Send(data); err != nil {
-			log.Printf("发送游戏结束到玩家 %d 失败: %v", conn.ID(), err)
-		}
-	}
+	})
 }
 
 func (r *Room) checkGameOver() (bool, int32) {
@@ -1244,6 +2062,27 @@ func (r *Room) checkGameOver() (bool, int32) {
 		return false, -1
 	}
 
+	// 首领战：没有单独的获胜者，结果由 GameOverEvent.boss_defeated 表示
+	if r.game.Rules.BossMode && r.game.Boss != nil {
+		if r.game.Boss.Defeated() {
+			log.Printf("房间 %s 首领被击败，玩家获胜", r.id)
+		} else {
+			log.Printf("房间 %s 玩家全部阵亡，首领获胜", r.id)
+		}
+		return true, -1
+	}
+
+	// 组队模式：进门的玩家代表所在队伍获胜
+	if r.game.Rules.Teams {
+		if r.game.WinningTeam() == core.TeamNone {
+			return true, -1
+		}
+		if door := r.game.DoorPlayer(); door != nil {
+			return true, int32(door.ID)
+		}
+		return true, -1
+	}
+
 	// 游戏结束，找出获胜者
 	total, alive, winnerID := r.countPlayersAlive()
 	if total == 0 {
@@ -1289,22 +2128,32 @@ func (r *Room) removePlayerByID(playerID int32) {
 	}
 }
 
-// updateAI 更新 AI 玩家
-func (r *Room) updateAI() {
+// decideAI 所有 AI 本帧的决策（由 applyFrameInputs 与真人输入一起按 ID 应用）
+func (r *Room) decideAI() []frameInput {
 	if len(r.aiControllers) == 0 {
-		return
+		return nil
 	}
 
-	for id, controller := range r.aiControllers {
-		input := controller.Decide(r.game)
-		core.ApplyInput(r.game, int(id), input, r.frameID)
+	// 所有 AI 共享同一帧的地图快照（可行走格子与距离场只计算一次）
+	// 快照在本帧任何输入生效前构建，同帧其他玩家的新炸弹要到下一帧才可见
+	world := ai.NewWorld(r.game)
+	ids := sortedPlayerIDs(r.aiControllers)
+	aiIDs := make([]int, 0, len(ids))
+	for _, id := range ids {
+		aiIDs = append(aiIDs, int(id))
+	}
+	world.SetAIPlayers(aiIDs)
+	inputs := make([]frameInput, 0, len(ids))
+	for _, id := range ids {
+		inputs = append(inputs, frameInput{playerID: id, input: r.aiControllers[id].DecideInWorld(world)})
 	}
+	return inputs
 }
 
-// tryFillWithAI 尝试用 AI 填满房间
+// tryFillWithAI 尝试用 AI 把房间填到标准地图的人数（更多 AI 需要房主手动添加）
 func (r *Room) tryFillWithAI() {
 	// 只在有真实玩家且房间未满时添加 AI
-	if len(r.connections) == 0 || len(r.game.Players) >= MaxPlayers {
+	if len(r.connections) == 0 || len(r.game.Players) >= core.StandardMapPlayers {
 		return
 	}
 
@@ -1316,11 +2165,11 @@ func (r *Room) tryFillWithAI() {
 		core.CharacterRed,
 	}
 
-	for len(r.game.Players) < MaxPlayers {
+	for len(r.game.Players) < core.StandardMapPlayers {
 		playerID := r.nextPlayerID
 		r.nextPlayerID++
 
-		x, y := getSpawnPosition(int(playerID))
+		x, y := r.spawnPosition(int(playerID))
 		charType := availableChars[(playerID-1)%int32(len(availableChars))]
 
 		player := core.NewPlayer(int(playerID), x, y, charType)
@@ -1328,7 +2177,8 @@ func (r *Room) tryFillWithAI() {
 		r.game.AddPlayer(player)
 
 		// 创建 AI 控制器
-		r.aiControllers[playerID] = ai.NewAIController(int(playerID))
+		r.aiControllers[playerID] = r.newAIController(playerID, ai.DifficultyNormal)
+		r.playerNames[playerID] = r.pickAIName(playerID)
 
 		log.Printf("添加 AI 玩家 %d", playerID)
 	}
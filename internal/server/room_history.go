@@ -0,0 +1,82 @@
+package server
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 房间历史：最近的聊天消息和主要事件（加入、离开、准备），新加入或重连的人随响应收到，
+// 了解进房前发生了什么；新记录同时以 GameEvent 广播给房间内所有人
+const (
+	roomHistorySize = 50  // 保留的记录条数
+	maxChatRunes    = 120 // 单条聊天消息的最大字符数
+)
+
+// recordHistory 追加一条历史记录并广播（兼容房间没有历史）
+func (r *Room) recordHistory(kind gamev1.RoomHistoryKind, playerID int32, text string) {
+	if r.legacyMode {
+		return
+	}
+	entry := &gamev1.RoomHistoryEntry{
+		Kind:       kind,
+		PlayerId:   playerID,
+		PlayerName: r.memberName(playerID),
+		Text:       text,
+		TimeMs:     time.Now().UnixMilli(),
+	}
+	r.history = append(r.history, entry)
+	if len(r.history) > roomHistorySize {
+		r.history = r.history[len(r.history)-roomHistorySize:]
+	}
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_RoomHistory{RoomHistory: entry},
+	})
+}
+
+// historySnapshot 当前历史记录的副本（随加入/重连响应发送）
+func (r *Room) historySnapshot() []*gamev1.RoomHistoryEntry {
+	if len(r.history) == 0 {
+		return nil
+	}
+	return append([]*gamev1.RoomHistoryEntry(nil), r.history...)
+}
+
+// memberName 玩家或观战者的名字
+func (r *Room) memberName(id int32) string {
+	if name, ok := r.spectatorNames[id]; ok {
+		return name
+	}
+	return r.playerNames[id]
+}
+
+// handleChat 处理聊天消息：去掉控制字符和首尾空白，过长时截断
+func (r *Room) handleChat(playerID int32, text string) error {
+	_, isPlayer := r.connections[playerID]
+	_, isSpectator := r.spectators[playerID]
+	if !isPlayer && !isSpectator {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", playerID)
+	}
+	text = sanitizeChat(text)
+	if text == "" {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "聊天消息为空")
+	}
+	r.recordHistory(gamev1.RoomHistoryKind_ROOM_HISTORY_KIND_CHAT, playerID, text)
+	return nil
+}
+
+func sanitizeChat(text string) string {
+	text = strings.Map(func(c rune) rune {
+		if unicode.IsControl(c) {
+			return -1
+		}
+		return c
+	}, text)
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > maxChatRunes {
+		text = string(runes[:maxChatRunes])
+	}
+	return text
+}
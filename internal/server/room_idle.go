@@ -0,0 +1,62 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// touchActivity 记录房间活动，重新开始空闲计时
+func (r *Room) touchActivity() {
+	r.lastActivity = time.Now()
+	r.idleWarned = false
+}
+
+// checkIdle 等待阶段长时间无人准备或操作时先警告，超时后解散房间
+// 只有在线玩家时才计时（房间空了会被 RoomManager 清理）
+func (r *Room) checkIdle(now time.Time) {
+	if r.idleTimeout <= 0 || len(r.connections) == 0 {
+		return
+	}
+
+	idle := now.Sub(r.lastActivity)
+	if idle >= r.idleTimeout {
+		log.Printf("房间 %s 等待阶段空闲 %v，解散房间", r.id, idle.Truncate(time.Second))
+		r.dissolve(gamev1.ErrorCode_ERROR_CODE_ROOM_IDLE, "房间长时间无人操作，已解散")
+		return
+	}
+
+	warnAt := r.idleTimeout - roomIdleWarnBefore
+	if warnAt < 0 {
+		warnAt = r.idleTimeout / 2
+	}
+	if idle >= warnAt && !r.idleWarned {
+		r.idleWarned = true
+		r.broadcastEvent(&gamev1.GameEvent{
+			Event: &gamev1.GameEvent_RoomIdleWarning{
+				RoomIdleWarning: &gamev1.RoomIdleWarningEvent{
+					SecondsRemaining: int32((r.idleTimeout - idle).Seconds()),
+				},
+			},
+		})
+	}
+}
+
+// dissolve 把所有玩家和观战者送回大厅，房间变空后由 RoomManager 回收
+func (r *Room) dissolve(code gamev1.ErrorCode, message string) {
+	for spectatorID, conn := range r.spectators {
+		r.sendRemoved(conn, code, message)
+		r.dropSpectator(spectatorID)
+	}
+	for playerID, conn := range r.connections {
+		r.sendRemoved(conn, code, message)
+		r.handleForceLeave(playerID)
+	}
+	for playerID := range r.offlinePlayers {
+		r.handleForceLeave(playerID)
+	}
+	for playerID := range r.aiControllers {
+		r.handleForceLeave(playerID)
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"bomberman/pkg/ai"
+	"bomberman/pkg/core"
+)
+
+// contestedBombOwner 所有玩家站在同一格并在同一帧按下放弹，返回放出的唯一一颗炸弹的归属
+// humans 为真人玩家 ID（本帧输入放弹），ais 为按脚本放弹的 AI 玩家 ID
+func contestedBombOwner(t *testing.T, humans, ais []int32) int {
+	t.Helper()
+	script, err := ai.ParseScript("bomb\n")
+	if err != nil {
+		t.Fatalf("解析脚本失败: %v", err)
+	}
+
+	r := NewRoom(context.Background(), "test", 42, true, false)
+	t.Cleanup(r.cancel)
+	x, y := r.spawnPosition(1)
+	for _, id := range humans {
+		r.game.AddPlayer(core.NewPlayer(int(id), x, y, core.CharacterWhite))
+		r.connections[id] = &fakeSession{id: id, roomID: r.id}
+		r.inputQueue[id] = map[int32]InputData{0: {FrameID: 0, Bomb: true}}
+	}
+	for _, id := range ais {
+		r.game.AddPlayer(core.NewPlayer(int(id), x, y, core.CharacterWhite))
+		r.aiControllers[id] = ai.NewScriptedController(int(id), script)
+	}
+	r.state = StateRunning
+
+	r.applyFrameInputs()
+
+	if len(r.game.Bombs) != 1 {
+		t.Fatalf("同一格子放出 %d 颗炸弹，期望 1", len(r.game.Bombs))
+	}
+	return r.game.Bombs[0].OwnerID
+}
+
+// TestFrameInputsAppliedInIDOrder 同帧输入按玩家 ID 应用，与玩家是真人还是 AI 无关
+func TestFrameInputsAppliedInIDOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		humans []int32
+		ais    []int32
+	}{
+		{"真人 ID 小", []int32{1}, []int32{2}},
+		{"AI ID 小", []int32{2}, []int32{1}},
+		{"交错", []int32{2, 4}, []int32{1, 3}},
+		{"交错（身份互换）", []int32{1, 3}, []int32{2, 4}},
+		{"只有真人", []int32{3, 1, 2}, nil},
+		{"只有 AI", nil, []int32{3, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if owner := contestedBombOwner(t, tt.humans, tt.ais); owner != 1 {
+				t.Errorf("炸弹归属玩家 %d，期望 ID 最小的玩家 1", owner)
+			}
+		})
+	}
+}
+
+// TestFrameInputsIndependentOfMapOrder 多次运行结果一致（不依赖 map 遍历顺序）
+func TestFrameInputsIndependentOfMapOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		if owner := contestedBombOwner(t, []int32{5, 2, 7}, []int32{6, 3, 4}); owner != 2 {
+			t.Fatalf("第 %d 次运行炸弹归属玩家 %d，期望 2", i+1, owner)
+		}
+	}
+}
@@ -10,32 +10,50 @@ import (
 	"time"
 
 	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/ai"
+	"bomberman/pkg/core"
 )
 
 const (
 	DefaultRoomID    = "default" // 默认房间 ID
-	MaxRooms         = 100       // 最大房间数
+	MaxRooms         = 100       // 默认最大房间数
 	RoomEmptyTimeout = 60        // 房间空置超时（秒）
 )
 
 type RoomManager struct {
-	ctx         context.Context
-	enableAI    bool
-	nextRoomSeq int64
-	rooms       map[string]*Room // 房间 ID -> 房间
-	roomMutex   sync.RWMutex     // 保护 rooms map
-	wg          sync.WaitGroup   // 等待组
-	shutdown    chan struct{}    // 关闭信号
+	ctx             context.Context
+	enableAI        bool
+	debugScenarios  bool          // 是否允许新建房间执行调试场景
+	debugAI         bool          // 新建房间是否广播 AI 调试信息
+	recordDir       string        // 新建房间的广播录制目录（空表示不录制）
+	maxRooms        int           // 房间数上限（含默认房间）
+	offlineTimeout  time.Duration // 新建房间的离线玩家保留时间
+	roomIdleTimeout time.Duration // 新建房间的等待阶段空闲解散时间
+	matchStats      *MatchStats   // AI 与真人胜负统计
+	replayIndex     *ReplayIndex  // 录像索引（开启录制时）
+	aiTree          ai.Node       // 从配置加载的 AI 行为树（nil 表示使用内置树）
+	maps            *mapCatalog   // 社区地图（nil 表示未开启）
+	reports         *reportStore  // 玩家举报（nil 表示未开启）
+	profiles        *ProfileStore // 按账号保存的玩家偏好
+	syncCheck       bool          // 新建房间是否广播帧同步校验
+	interestRadius  int32         // 新建房间的兴趣管理半径（格，0 表示不过滤）
+	nextRoomSeq     int64
+	rooms           map[string]*Room // 房间 ID -> 房间
+	roomMutex       sync.RWMutex     // 保护 rooms map
+	wg              sync.WaitGroup   // 等待组
+	shutdown        chan struct{}    // 关闭信号
 }
 
 // NewRoomManager 创建新的房间管理器
 func NewRoomManager(ctx context.Context, enableAI bool) *RoomManager {
 	return &RoomManager{
-		ctx:         ctx,
-		enableAI:    enableAI,
-		nextRoomSeq: 0,
-		rooms:       make(map[string]*Room),
-		shutdown:    make(chan struct{}),
+		ctx:             ctx,
+		enableAI:        enableAI,
+		nextRoomSeq:     0,
+		maxRooms:        MaxRooms,
+		roomIdleTimeout: DefaultRoomIdleTimeout,
+		rooms:           make(map[string]*Room),
+		shutdown:        make(chan struct{}),
 	}
 }
 
@@ -104,6 +122,25 @@ func (m *RoomManager) getOrCreateRoom(roomID string) *Room {
 		seed = 0
 	}
 	room := NewRoom(m.ctx, roomID, seed, m.enableAI, legacyMode)
+	room.scenariosEnabled = m.debugScenarios && !legacyMode
+	room.debugAI = m.debugAI && !legacyMode
+	room.syncCheck = m.syncCheck
+	room.interestRadius = m.interestRadius
+	if m.offlineTimeout > 0 {
+		room.offlineTimeout = m.offlineTimeout
+	}
+	if !legacyMode {
+		room.idleTimeout = m.roomIdleTimeout
+	}
+	room.matchStats = m.matchStats
+	room.replayIndex = m.replayIndex
+	room.aiTree = m.aiTree
+	room.maps = m.maps
+	room.reports = m.reports
+	room.profiles = m.profiles
+	if m.recordDir != "" {
+		room.startRecording(m.recordDir)
+	}
 	m.rooms[roomID] = room
 
 	// 启动房间循环
@@ -121,6 +158,9 @@ func (m *RoomManager) Join(session Session, req JoinEvent) error {
 	// Handle CREATE:room_id format for custom room creation
 	if len(roomID) > 7 && roomID[:7] == "CREATE:" {
 		customID := roomID[7:]
+		if err := m.checkRoomLimit(); err != nil {
+			return err
+		}
 		if customID == "" {
 			// "CREATE:" with empty ID -> generate random
 			roomID = m.CreateRoom()
@@ -133,13 +173,19 @@ func (m *RoomManager) Join(session Session, req JoinEvent) error {
 		case "":
 			roomID = m.findAvailableRoom()
 			if roomID == "" {
+				if err := m.checkRoomLimit(); err != nil {
+					return err
+				}
 				roomID = m.CreateRoom()
 			}
 		case "CREATE":
+			if err := m.checkRoomLimit(); err != nil {
+				return err
+			}
 			roomID = m.CreateRoom()
 		default:
 			if !m.roomExists(roomID) {
-				return fmt.Errorf("Room %s not found", roomID)
+				return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_NOT_FOUND, []string{roomID}, "房间 %s 不存在", roomID)
 			}
 		}
 	}
@@ -147,14 +193,15 @@ func (m *RoomManager) Join(session Session, req JoinEvent) error {
 	// 获取或创建房间
 	room := m.getOrCreateRoom(roomID)
 
-	// 检查房间是否已满
-	if len(room.connections)+len(room.aiControllers) >= MaxPlayers {
-		return fmt.Errorf("房间 %s 已满 (%d/%d)", roomID, len(room.connections)+len(room.aiControllers), MaxPlayers)
+	// 检查房间是否已满（观战者不占用玩家位置，接管 AI 不增加人数）
+	if !req.Spectate && !req.TakeOverAI && len(room.connections)+len(room.aiControllers) >= MaxPlayers {
+		current := len(room.connections) + len(room.aiControllers)
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_FULL, countParams(current, MaxPlayers), "房间 %s 已满 (%d/%d)", roomID, current, MaxPlayers)
 	}
 
 	// 检查房间状态
 	if room.state == StateEnding {
-		return fmt.Errorf("房间 %s 结算中，暂时无法加入", roomID)
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_ROOM_PLAYING, "房间 %s 结算中，暂时无法加入", roomID)
 	}
 
 	// 设置玩家房间 ID
@@ -166,10 +213,25 @@ func (m *RoomManager) Join(session Session, req JoinEvent) error {
 		return err
 	}
 
+	if req.TakeOverAI && session.GetRoomID() == "" {
+		log.Printf("房间 %s 的接管申请已提交，等待房主审批", roomID)
+		return nil
+	}
 	log.Printf("玩家 %d 加入房间 %s", session.ID(), roomID)
 	return nil
 }
 
+// checkRoomLimit 新建房间前检查房间数上限
+func (m *RoomManager) checkRoomLimit() error {
+	m.roomMutex.RLock()
+	count := len(m.rooms)
+	m.roomMutex.RUnlock()
+	if m.maxRooms > 0 && count >= m.maxRooms {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_TOO_MANY_ROOMS, countParams(count, m.maxRooms), "房间数已达上限 (%d/%d)", count, m.maxRooms)
+	}
+	return nil
+}
+
 // findAvailableRoom 查找可加入的房间
 func (m *RoomManager) findAvailableRoom() string {
 	m.roomMutex.RLock()
@@ -227,22 +289,127 @@ func (m *RoomManager) GetRoomList() []*gamev1.RoomInfo {
 			MaxPlayers:     MaxPlayers,
 			Status:         status,
 			HostName:       room.playerNames[room.hostID],
+			MapId:          core.MapID(room.mapLayout(), room.rules),
 		})
 	}
 	return list
 }
 
+// 房间列表分页
+const (
+	DefaultRoomListPageSize = 20 // 请求未指定每页数量时使用
+	MaxRoomListPageSize     = 50 // 每页数量上限
+)
+
+// RoomListQuery 房间列表的分页与过滤条件（过滤条件同时开启时取交集）
+type RoomListQuery struct {
+	Page        int32 // 从 1 开始，<=0 视为第 1 页
+	PageSize    int32 // <=0 时使用 DefaultRoomListPageSize
+	WaitingOnly bool  // 只列出等待中的房间
+	NotFull     bool  // 只列出还有空位的房间（玩家与 AI 合计未达 MaxPlayers）
+	HasAI       bool  // 只列出有 AI 的房间
+}
+
+// matches 房间是否满足过滤条件
+func (q RoomListQuery) matches(room *gamev1.RoomInfo) bool {
+	if q.WaitingOnly && room.Status != gamev1.RoomStatus_ROOM_STATUS_WAITING {
+		return false
+	}
+	if q.NotFull && room.CurrentPlayers+room.AiCount >= room.MaxPlayers {
+		return false
+	}
+	if q.HasAI && room.AiCount == 0 {
+		return false
+	}
+	return true
+}
+
+// RoomListPage 一页房间列表
+type RoomListPage struct {
+	Rooms    []*gamev1.RoomInfo
+	Total    int32 // 满足过滤条件的房间总数
+	Page     int32 // 实际返回的页码（请求的页超出范围时为最后一页）
+	PageSize int32
+}
+
+// ListRooms 按条件过滤房间列表后分页（房间按 ID 排序，翻页时顺序稳定）
+func (m *RoomManager) ListRooms(query RoomListQuery) RoomListPage {
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultRoomListPageSize
+	}
+	pageSize = min(pageSize, MaxRoomListPageSize)
+
+	var rooms []*gamev1.RoomInfo
+	for _, room := range m.GetRoomList() {
+		if query.matches(room) {
+			rooms = append(rooms, room)
+		}
+	}
+	total := int32(len(rooms))
+
+	lastPage := max((total+pageSize-1)/pageSize, 1)
+	page := min(max(query.Page, 1), lastPage)
+	start := min((page-1)*pageSize, total)
+	end := min(start+pageSize, total)
+	return RoomListPage{Rooms: rooms[start:end], Total: total, Page: page, PageSize: pageSize}
+}
+
 // HandleRoomAction 处理房间操作
 func (m *RoomManager) HandleRoomAction(roomID string, playerID int32, action *gamev1.RoomAction) error {
 	m.roomMutex.RLock()
 	room, exists := m.rooms[roomID]
 	m.roomMutex.RUnlock()
 	if !exists {
-		return fmt.Errorf("房间 %s 不存在", roomID)
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_ROOM_NOT_FOUND, []string{roomID}, "房间 %s 不存在", roomID)
 	}
 	return room.HandleRoomAction(playerID, action)
 }
 
+// RunScenario 在指定房间执行调试场景
+func (m *RoomManager) RunScenario(roomID string, ops []ScenarioOp) error {
+	m.roomMutex.RLock()
+	room, exists := m.rooms[roomID]
+	m.roomMutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("房间 %s 不存在", roomID)
+	}
+	return room.RunScenario(ops)
+}
+
+// AttachObserver 在指定房间挂载运维观察者
+func (m *RoomManager) AttachObserver(roomID string, conn Session) error {
+	m.roomMutex.RLock()
+	room, exists := m.rooms[roomID]
+	m.roomMutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("房间 %s 不存在", roomID)
+	}
+	return room.AttachObserver(conn)
+}
+
+// DetachObserver 从指定房间卸载运维观察者
+func (m *RoomManager) DetachObserver(roomID string, conn Session) error {
+	m.roomMutex.RLock()
+	room, exists := m.rooms[roomID]
+	m.roomMutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("房间 %s 不存在", roomID)
+	}
+	return room.DetachObserver(conn)
+}
+
+// SnapshotRoom 获取指定房间的完整游戏状态
+func (m *RoomManager) SnapshotRoom(roomID string) (*gamev1.GameState, error) {
+	m.roomMutex.RLock()
+	room, exists := m.rooms[roomID]
+	m.roomMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("房间 %s 不存在", roomID)
+	}
+	return room.Snapshot()
+}
+
 // EnqueueInput 将输入放入对应房间的队列
 func (m *RoomManager) EnqueueInput(playerID int32, input InputEvent) {
 	m.roomMutex.RLock()
@@ -274,6 +441,12 @@ func (m *RoomManager) Leave(playerID int32, roomID string) {
 		return
 	}
 
+	if _, exists := room.spectators[playerID]; exists {
+		log.Printf("观战者 %d 离开房间 %s", playerID, roomID)
+		room.Leave(playerID)
+		return
+	}
+
 	log.Printf("警告: 玩家 %d 不在任何房间中", playerID)
 }
 
@@ -317,9 +490,11 @@ func (m *RoomManager) GetRoomStats() map[string]RoomStats {
 	stats := make(map[string]RoomStats)
 	for roomID, room := range m.rooms {
 		stats[roomID] = RoomStats{
-			PlayerCount: len(room.connections),
-			State:       int(room.state),
-			FrameID:     room.frameID,
+			PlayerCount:   len(room.connections),
+			State:         int(room.state),
+			FrameID:       room.frameID,
+			FrameLag:      room.metrics.frameLag.Load(),
+			TickDurations: room.metrics.tickSamples(),
 		}
 	}
 	return stats
@@ -327,9 +502,11 @@ func (m *RoomManager) GetRoomStats() map[string]RoomStats {
 
 // RoomStats 房间统计信息
 type RoomStats struct {
-	PlayerCount int
-	State       int
-	FrameID     int32
+	PlayerCount   int
+	State         int
+	FrameID       int32
+	FrameLag      int32           // 落后墙钟的帧数
+	TickDurations []time.Duration // 最近的 tick 耗时样本
 }
 
 // CreateRoom 创建新房间（返回房间 ID）
@@ -364,25 +541,26 @@ func (m *RoomManager) CreateRoomWithID(customID string) string {
 }
 
 // ReconnectPlayer 玩家重连
-// 返回当前游戏状态用于恢复
-func (m *RoomManager) ReconnectPlayer(newConnID int32, playerID int32, roomID string, newConn Session) (*gamev1.GameState, error) {
+// 返回当前游戏状态用于恢复，以及房间最近的聊天和事件
+func (m *RoomManager) ReconnectPlayer(newConnID int32, playerID int32, roomID string, newConn Session) (*gamev1.GameState, []*gamev1.RoomHistoryEntry, error) {
 	m.roomMutex.RLock()
 	defer m.roomMutex.RUnlock()
 
 	room, exists := m.rooms[roomID]
 	if !exists {
-		return nil, fmt.Errorf("房间 %s 不存在", roomID)
+		return nil, nil, fmt.Errorf("房间 %s 不存在", roomID)
 	}
 
 	// 尝试重连 (支持在线替换和离线恢复)
-	if !room.TryReconnect(playerID, newConn) {
-		return nil, fmt.Errorf("玩家 %d 无法重连到房间 %s (可能不在房间中或已超时移除)", playerID, roomID)
+	history, ok := room.TryReconnect(playerID, newConn)
+	if !ok {
+		return nil, nil, fmt.Errorf("玩家 %d 无法重连到房间 %s (可能不在房间中或已超时移除)", playerID, roomID)
 	}
 
-	// 获取当前游戏状态
-	currentState := room.BuildGameState()
+	// 获取当前游戏状态（开启兴趣管理时只包含玩家周围的实体，与之后的增量基线一致）
+	currentState := room.interestView(room.BuildGameState(), playerID)
 
 	log.Printf("玩家 %d 在房间 %s 重连，新连接 ID: %d", playerID, roomID, newConnID)
 
-	return currentState, nil
+	return currentState, history, nil
 }
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// largeMapDef 人数超过所选地图的出生点数时换用的大地图（只读，各房间共享）
+var largeMapDef = core.BuiltinMap(core.MapLayoutLarge)
+
+// activeMap 实际使用的地图定义：换成大地图时为大地图，否则为房主选择的地图（nil 表示默认地图）
+func (r *Room) activeMap() *core.MapDefinition {
+	if r.largeMap {
+		return largeMapDef
+	}
+	return r.mapDef
+}
+
+// newGameMap 按房间实际使用的地图生成地图（未选择时为默认地图）
+func (r *Room) newGameMap(seed int64) *core.GameMap {
+	if def := r.activeMap(); def != nil {
+		return core.NewGameMapFromDefinition(def, seed)
+	}
+	return core.NewGameMap(seed)
+}
+
+// spawnPosition 按房间实际使用的地图获取出生点（像素坐标）
+func (r *Room) spawnPosition(playerID int) (int, int) {
+	if def := r.activeMap(); def != nil {
+		cell := def.SpawnCell(playerID)
+		return core.GridToPlayerXY(cell.X, cell.Y)
+	}
+	return getSpawnPosition(playerID)
+}
+
+// mapLayout 实际使用的地图：内置地图 ID 或社区地图名（见 core.MapID）
+func (r *Room) mapLayout() string {
+	if def := r.activeMap(); def != nil {
+		return def.Name
+	}
+	return core.MapLayoutDefault
+}
+
+// selectedMapLayout 房主选择的地图（换成大地图时与 mapLayout 不同）
+func (r *Room) selectedMapLayout() string {
+	if r.mapDef == nil {
+		return core.MapLayoutDefault
+	}
+	return r.mapDef.Name
+}
+
+// customMapName 当前社区地图名（内置地图为空）
+func (r *Room) customMapName() string {
+	if r.customMapJSON() == nil {
+		return ""
+	}
+	return r.mapDef.Name
+}
+
+// customMapJSON 随房间状态下发的社区地图定义（内置地图和换成大地图时为 nil）
+func (r *Room) customMapJSON() []byte {
+	if r.largeMap {
+		return nil
+	}
+	return r.mapJSON
+}
+
+// needsLargeMap 人数是否超过房主所选地图的出生点数（默认地图为四个角落）
+func (r *Room) needsLargeMap() bool {
+	spawns := core.StandardMapPlayers
+	if r.mapDef != nil {
+		spawns = len(r.mapDef.Spawns)
+	}
+	return len(r.connections)+len(r.aiControllers) > spawns
+}
+
+// fitMapToRoster 按房间人数选择地图：人数超过所选地图的出生点数时换成大地图，
+// 人数降回来后换回房主选择的地图。只在等待开始时调整，不取消准备状态（房主选择的地图没有变）
+func (r *Room) fitMapToRoster() {
+	large := r.needsLargeMap()
+	if r.state != StateWaiting || large == r.largeMap {
+		return
+	}
+	r.largeMap = large
+	r.rebuildMap()
+	log.Printf("房间 %s 人数变化，地图换为 %q", r.id, r.mapLayout())
+}
+
+// rebuildMap 地图变化后按当前种子重建地图，并把玩家移到新的出生点
+func (r *Room) rebuildMap() {
+	r.game.Map = r.newGameMap(r.seed)
+	for _, player := range r.game.Players {
+		if player == nil {
+			continue
+		}
+		x, y := r.spawnPosition(player.ID)
+		player.X, player.Y = float64(x), float64(y)
+	}
+}
+
+// setMap 房主选择地图：name 为内置地图 ID 或社区地图名（为空表示默认地图），只能在开始前修改。
+// 内置地图只下发 ID，客户端用本地的同一份定义和种子生成地图；社区地图随房间状态下发定义。
+// 地图变化后重建地图（人数超过该地图的出生点数时仍用大地图）、把玩家移到新出生点并取消真人玩家的准备状态
+func (r *Room) setMap(playerID int32, name string) error {
+	if playerID != r.hostID {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionSetMap}, "只有房主可以选择地图")
+	}
+	if r.state != StateWaiting {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionSetMap}, "游戏中无法选择地图")
+	}
+	if name == "" {
+		name = core.MapLayoutDefault
+	}
+	if name == r.selectedMapLayout() {
+		return nil
+	}
+
+	var def *core.MapDefinition
+	var data []byte
+	switch {
+	case name == core.MapLayoutDefault:
+	case core.IsBuiltinMap(name):
+		def = core.BuiltinMap(name)
+	default:
+		def = r.maps.Get(name)
+		if def == nil {
+			return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_MAP_NOT_FOUND, []string{name}, "社区地图 %s 不存在", name)
+		}
+		var err error
+		if data, err = json.Marshal(def); err != nil {
+			return newRoomError(gamev1.ErrorCode_ERROR_CODE_INTERNAL, "序列化地图失败: %v", err)
+		}
+	}
+	r.mapDef = def
+	r.mapJSON = data
+	r.largeMap = r.needsLargeMap()
+	r.rebuildMap()
+	for id := range r.connections {
+		r.readyStatus[id] = false
+	}
+	log.Printf("房间 %s 地图已更换: %q", r.id, name)
+	return nil
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/ai"
+	"bomberman/pkg/core"
+)
+
+// fakeSession 只记录发送次数和最后一个数据包的会话
+type fakeSession struct {
+	mu     sync.Mutex
+	id     int32
+	roomID string
+	sent   int
+	last   []byte
+	closed bool
+}
+
+func (s *fakeSession) ID() int32               { return s.id }
+func (s *fakeSession) GetRoomID() string       { return s.roomID }
+func (s *fakeSession) SetRoomID(roomID string) { s.roomID = roomID }
+func (s *fakeSession) SetPlayerID(id int32)    { s.id = id }
+func (s *fakeSession) Close()                  { s.CloseWithoutNotify() }
+
+func (s *fakeSession) CloseWithoutNotify() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+}
+
+func (s *fakeSession) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+func (s *fakeSession) Send(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent++
+	s.last = data
+	return nil
+}
+
+// newTestRoom 不启动房间循环的房间：两名真人玩家（1 为房主）和 count 个 AI，全部已准备
+func newTestRoom(t *testing.T, aiCount int) *Room {
+	t.Helper()
+	r := NewRoom(context.Background(), "test", 42, true, false)
+	t.Cleanup(r.cancel)
+	for _, id := range []int32{1, 2} {
+		x, y := r.spawnPosition(int(id))
+		r.game.AddPlayer(core.NewPlayer(int(id), x, y, core.CharacterWhite))
+		r.connections[id] = &fakeSession{id: id, roomID: r.id}
+		r.playerNames[id] = "human"
+		r.playerCharacters[id] = core.CharacterWhite
+		r.readyStatus[id] = true
+	}
+	r.hostID = 1
+	r.nextPlayerID = 3
+	if err := r.addAI(aiCount, nil, ai.DifficultyNormal); err != nil {
+		t.Fatalf("添加 AI 失败: %v", err)
+	}
+	return r
+}
+
+// roomAction 在房间 goroutine 之外直接执行房间操作
+func roomAction(r *Room, playerID int32, action *gamev1.RoomAction) error {
+	respCh := make(chan error, 1)
+	r.handleRoomAction(roomActionRequest{playerID: playerID, action: action, respCh: respCh})
+	select {
+	case err := <-respCh:
+		return err
+	default:
+		return nil
+	}
+}
+
+func TestResetRoomRestoresWaitingState(t *testing.T) {
+	r := newTestRoom(t, 2)
+	rules := core.GameRules{MapHazards: true, Teams: true, FriendlyFire: true}
+	r.rules = rules
+
+	// 对局进行到一半：AI 阵亡，场上还有炸弹、爆炸和道具
+	r.state = StateRunning
+	r.frameID = 900
+	r.game.CurrentFrame = 900
+	for id := range r.aiControllers {
+		r.game.GetPlayer(int(id)).Dead = true
+	}
+	r.game.AddBomb(core.NewBomb(3, 3, 1, 880))
+	r.game.Explosions = append(r.game.Explosions, &core.Explosion{Cells: []core.GridPos{{GridX: 5, GridY: 5}}, ExpiresAtFrame: 920})
+	r.game.Items = append(r.game.Items, &core.Item{GridX: 7, GridY: 7, Type: core.ItemKick})
+	aiIDs := make(map[int32]*ai.AIController, len(r.aiControllers))
+	for id, controller := range r.aiControllers {
+		aiIDs[id] = controller
+	}
+
+	// 结算期间：玩家 1 准备下一局，玩家 2 没有操作
+	r.handleGameOver(1)
+	if r.state != StateEnding {
+		t.Fatalf("state = %v，期望 StateEnding", r.state)
+	}
+	if err := roomAction(r, 1, &gamev1.RoomAction{Type: gamev1.RoomActionType_ROOM_ACTION_READY, Ready: true}); err != nil {
+		t.Fatalf("结算期间准备失败: %v", err)
+	}
+	if err := roomAction(r, 1, &gamev1.RoomAction{Type: gamev1.RoomActionType_ROOM_ACTION_START}); err == nil {
+		t.Fatal("结算期间不应能开始游戏")
+	}
+
+	r.resetRoom()
+
+	if r.state != StateWaiting || r.frameID != 0 || !r.resetAt.IsZero() {
+		t.Fatalf("重置后 state=%v frameID=%d resetAt=%v，期望等待状态", r.state, r.frameID, r.resetAt)
+	}
+	if r.rules != rules {
+		t.Errorf("规则 = %+v，期望保留 %+v", r.rules, rules)
+	}
+	if !r.readyStatus[1] {
+		t.Error("玩家 1 在结算期间准备，重置后应为已准备")
+	}
+	if r.readyStatus[2] {
+		t.Error("玩家 2 没有重新准备，重置后应为未准备")
+	}
+	if len(r.nextRoundReady) != 0 {
+		t.Errorf("nextRoundReady 应清空，得到 %v", r.nextRoundReady)
+	}
+
+	if len(r.aiControllers) != len(aiIDs) {
+		t.Fatalf("AI 数量 %d，期望 %d", len(r.aiControllers), len(aiIDs))
+	}
+	for id, controller := range aiIDs {
+		if r.aiControllers[id] != controller {
+			t.Errorf("AI %d 的控制器没有保留", id)
+		}
+		if !r.readyStatus[id] {
+			t.Errorf("AI %d 应为已准备", id)
+		}
+		p := r.game.GetPlayer(int(id))
+		if p == nil || p.Dead {
+			t.Fatalf("AI %d 应在新对局中复活", id)
+		}
+		if x, y := r.spawnPosition(int(id)); p.X != float64(x) || p.Y != float64(y) {
+			t.Errorf("AI %d 位置 (%v,%v)，期望出生点 (%d,%d)", id, p.X, p.Y, x, y)
+		}
+	}
+	if len(r.game.Players) != len(r.connections)+len(r.aiControllers) {
+		t.Errorf("玩家数 %d，期望 %d", len(r.game.Players), len(r.connections)+len(r.aiControllers))
+	}
+
+	if len(r.game.Bombs) != 0 || len(r.game.Explosions) != 0 || len(r.game.Items) != 0 {
+		t.Errorf("重置后残留 %d 颗炸弹、%d 处爆炸、%d 个道具", len(r.game.Bombs), len(r.game.Explosions), len(r.game.Items))
+	}
+	if r.game.CurrentFrame != 0 {
+		t.Errorf("新对局帧号 %d，期望 0", r.game.CurrentFrame)
+	}
+
+	// 下一局开始时沿用房间规则
+	r.startGame()
+	if r.game.Rules != rules {
+		t.Errorf("下一局规则 = %+v，期望 %+v", r.game.Rules, rules)
+	}
+}
+
+func TestResetRoomRemovesPlayersOfflineDuringEnding(t *testing.T) {
+	r := newTestRoom(t, 1)
+	r.state = StateRunning
+	r.handleGameOver(1)
+
+	// 玩家 2 在结算期间断线：没有对局可以恢复，重置时直接移除
+	delete(r.connections, 2)
+	r.offlinePlayers[2] = r.resetAt
+
+	r.resetRoom()
+
+	if _, ok := r.playerNames[2]; ok {
+		t.Error("结算期间断线的玩家重置后应被移除")
+	}
+	if r.game.GetPlayer(2) != nil {
+		t.Error("结算期间断线的玩家不应出现在新对局中")
+	}
+	if len(r.offlinePlayers) != 0 {
+		t.Errorf("offlinePlayers 应清空，得到 %v", r.offlinePlayers)
+	}
+	if r.hostID != 1 {
+		t.Errorf("房主 = %d，期望 1", r.hostID)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// 多回合对局（MatchConfig.RoundsToWin > 1）：每回合结束后记分并广播 RoundEnd，回合间歇后换新种子
+// 直接开始下一回合（不需要重新准备）；有玩家赢满 RoundsToWin 回合时才广播 GameOver 并回到等待阶段
+
+// roundIntermission 回合之间的间歇（客户端显示比分）
+const roundIntermission = 5 * time.Second
+
+// seriesEnabled 本局是否按多回合进行（首领战是合作模式，不分回合）
+func (r *Room) seriesEnabled() bool {
+	return !r.legacyMode && r.config.RoundsToWin > 1 && !r.rules.BossMode
+}
+
+// beginRound 开局时进入下一回合，第一回合清空比分
+func (r *Room) beginRound() {
+	if !r.seriesEnabled() {
+		r.endSeries()
+		return
+	}
+	if r.round == 0 {
+		r.roundWins = make(map[int32]int32)
+	}
+	r.round++
+	log.Printf("房间 %s 第 %d 回合开始（先赢 %d 回合获胜）", r.id, r.round, r.config.RoundsToWin)
+}
+
+// endSeries 结束（或放弃）多回合对局
+func (r *Room) endSeries() {
+	r.round = 0
+	r.roundWins = nil
+	r.nextRound = false
+}
+
+// finishRound 回合结束：获胜者（组队模式为获胜队伍的每名队员）得一分并广播 RoundEnd。
+// 返回 true 表示有玩家赢满回合，系列赛结束；否则安排回合间歇后开始下一回合
+func (r *Room) finishRound(winnerID int32) bool {
+	team := r.winningTeam(winnerID)
+	for _, p := range r.game.Players {
+		id := int32(p.ID)
+		if (team != core.TeamNone && int32(p.Team) == team) || (team == core.TeamNone && id == winnerID) {
+			r.roundWins[id]++
+		}
+	}
+
+	champion := r.seriesChampion()
+	event := &gamev1.RoundEndEvent{
+		Round:         r.round,
+		WinnerId:      winnerID,
+		WinningTeam:   team,
+		Score:         r.seriesScore(),
+		MatchWinnerId: champion,
+	}
+	if champion < 0 {
+		r.nextRound = true
+		r.resetAt = time.Now().Add(roundIntermission)
+		event.IntermissionMs = int32(roundIntermission.Milliseconds())
+	}
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_RoundEnd{RoundEnd: event},
+	})
+	log.Printf("房间 %s 第 %d 回合结束，获胜者: %d", r.id, r.round, winnerID)
+
+	if champion < 0 {
+		return false
+	}
+	r.endSeries()
+	return true
+}
+
+// seriesChampion 赢满回合数的玩家（多人同时赢满时取 ID 最小的，组队模式下同队队员分数相同），-1 表示没有
+func (r *Room) seriesChampion() int32 {
+	for _, p := range r.game.Players {
+		if r.roundWins[int32(p.ID)] >= r.config.RoundsToWin {
+			return int32(p.ID)
+		}
+	}
+	return -1
+}
+
+// seriesScore 当前比分（按玩家 ID 升序），不在多回合对局中时为 nil
+func (r *Room) seriesScore() *gamev1.SeriesScore {
+	if r.round == 0 {
+		return nil
+	}
+	score := &gamev1.SeriesScore{Round: r.round, RoundsToWin: r.config.RoundsToWin}
+	for _, p := range r.game.Players {
+		score.Wins = append(score.Wins, &gamev1.RoundWins{PlayerId: int32(p.ID), Wins: r.roundWins[int32(p.ID)]})
+	}
+	return score
+}
+
+// startNextRound 回合间歇结束：换新种子重置对局并直接开始下一回合；人数不足或队伍不完整时放弃系列赛，回到等待阶段
+func (r *Room) startNextRound() {
+	r.nextRound = false
+	if !r.rules.FairSeed {
+		r.seed = r.freshSeed() // 公平种子规则下 resetRoom 自己换种子
+	}
+	r.resetRoom()
+
+	total := len(r.connections) + len(r.aiControllers)
+	if total < minPlayersToStart || (r.rules.Teams && r.checkTeamsBalanced() != nil) {
+		log.Printf("房间 %s 人数不足，放弃剩余回合", r.id)
+		r.endSeries()
+		r.broadcastRoomState()
+		return
+	}
+	r.startGame()
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"bomberman/pkg/core"
+)
+
+// ScenarioOpKind 场景脚本操作类型
+type ScenarioOpKind int
+
+const (
+	ScenarioSetTile   ScenarioOpKind = iota // 修改地图格子（放砖、拆墙等）
+	ScenarioSpawnBomb                       // 生成炸弹（可自定义引信）
+	ScenarioTeleport                        // 传送玩家
+)
+
+// ScenarioOp 一条场景脚本操作
+// 用于在运行中的房间内复现问题，仅在开启调试的服务器上可用
+type ScenarioOp struct {
+	Kind ScenarioOpKind
+
+	GridX, GridY int
+
+	Tile core.TileType // SetTile: 目标格子类型
+
+	FuseFrames int32 // SpawnBomb: 引信帧数，<=0 使用默认值
+	Range      int   // SpawnBomb: 爆炸范围，<=0 使用默认值
+	OwnerID    int32 // SpawnBomb: 炸弹归属玩家（0 表示无主）
+
+	PlayerID int32 // Teleport: 目标玩家
+}
+
+// SetTileOp 修改格子
+func SetTileOp(gridX, gridY int, tile core.TileType) ScenarioOp {
+	return ScenarioOp{Kind: ScenarioSetTile, GridX: gridX, GridY: gridY, Tile: tile}
+}
+
+// SpawnBombOp 生成炸弹
+func SpawnBombOp(gridX, gridY int, fuseFrames int32, ownerID int32) ScenarioOp {
+	return ScenarioOp{Kind: ScenarioSpawnBomb, GridX: gridX, GridY: gridY, FuseFrames: fuseFrames, OwnerID: ownerID}
+}
+
+// TeleportOp 传送玩家到指定格子
+func TeleportOp(playerID int32, gridX, gridY int) ScenarioOp {
+	return ScenarioOp{Kind: ScenarioTeleport, PlayerID: playerID, GridX: gridX, GridY: gridY}
+}
+
+// ErrScenarioDisabled 房间未开启调试场景
+var ErrScenarioDisabled = errors.New("房间未开启调试场景")
+
+type scenarioRequest struct {
+	ops    []ScenarioOp
+	respCh chan error
+}
+
+// RunScenario 在房间 goroutine 中按顺序执行场景脚本
+// 任意一条操作失败时停止执行并返回错误（之前的操作不回滚）
+func (r *Room) RunScenario(ops []ScenarioOp) error {
+	if !r.scenariosEnabled {
+		return ErrScenarioDisabled
+	}
+
+	respCh := make(chan error, 1)
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("房间已关闭")
+	case r.scenarioCh <- scenarioRequest{ops: ops, respCh: respCh}:
+	}
+
+	select {
+	case <-r.ctx.Done():
+		return fmt.Errorf("房间已关闭")
+	case err := <-respCh:
+		return err
+	}
+}
+
+func (r *Room) handleScenario(req scenarioRequest) {
+	if r.state != StateRunning {
+		req.respCh <- fmt.Errorf("游戏未开始")
+		return
+	}
+	for i, op := range req.ops {
+		if err := r.applyScenarioOp(op); err != nil {
+			req.respCh <- fmt.Errorf("场景操作 %d 失败: %w", i, err)
+			return
+		}
+	}
+	log.Printf("[场景] 房间 %s 执行 %d 条操作 (帧 %d)", r.id, len(req.ops), r.frameID)
+	req.respCh <- nil
+}
+
+func (r *Room) applyScenarioOp(op ScenarioOp) error {
+	if !r.game.Map.InBounds(op.GridX, op.GridY) {
+		return fmt.Errorf("格子越界 (%d,%d)", op.GridX, op.GridY)
+	}
+
+	switch op.Kind {
+	case ScenarioSetTile:
+		oldType := r.game.Map.GetTile(op.GridX, op.GridY)
+		r.game.Map.SetTile(op.GridX, op.GridY, op.Tile)
+		r.scenarioTileChanges = append(r.scenarioTileChanges, core.TileChange{
+			GridX:   op.GridX,
+			GridY:   op.GridY,
+			OldType: oldType,
+			NewType: op.Tile,
+		})
+
+	case ScenarioSpawnBomb:
+		for _, b := range r.game.Bombs {
+			if b.GridX == op.GridX && b.GridY == op.GridY && !b.Exploded {
+				return fmt.Errorf("格子 (%d,%d) 已有炸弹", op.GridX, op.GridY)
+			}
+		}
+		bomb := core.NewBomb(op.GridX, op.GridY, int(op.OwnerID), r.frameID)
+		if op.FuseFrames > 0 {
+			bomb.ExplodeAtFrame = r.frameID + op.FuseFrames
+		}
+		if op.Range > 0 {
+			bomb.ExplosionRange = op.Range
+		}
+		r.game.AddBomb(bomb)
+
+	case ScenarioTeleport:
+		player := r.game.GetPlayer(int(op.PlayerID))
+		if player == nil {
+			return fmt.Errorf("玩家 %d 不存在", op.PlayerID)
+		}
+		x, y := core.GridToPlayerXY(op.GridX, op.GridY)
+		player.X = float64(x)
+		player.Y = float64(y)
+		player.BombIgnoreActive = false
+
+	default:
+		return fmt.Errorf("未知场景操作 %d", op.Kind)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/protocol"
+)
+
+// 会话令牌续期：令牌只在加入和重连时签发，有效期 SessionTTL。房间定期给在线玩家和观战者重新签发，
+// 任何时刻断线，手上的令牌都至少还有 SessionReconnectWindow 的有效期，断线保护和观战者重连都在这个时间内
+
+const (
+	// SessionRefreshInterval 在线会话的令牌续期间隔（开局时也会立即续期）
+	SessionRefreshInterval = SessionTTL / 2
+
+	// SessionReconnectWindow 断线后令牌保证仍然有效的时间，断线玩家和观战者的保留时间不能超过它
+	SessionReconnectWindow = SessionTTL - SessionRefreshInterval
+)
+
+// maybeRefreshSessionTokens 距上次续期超过 SessionRefreshInterval 时续期（房间 tick 调用）
+func (r *Room) maybeRefreshSessionTokens(now time.Time) {
+	if now.Sub(r.tokensRefreshedAt) < SessionRefreshInterval {
+		return
+	}
+	r.refreshSessionTokens(now)
+}
+
+// refreshSessionTokens 给房间内所有在线玩家和观战者重新签发会话令牌：
+// 以没有请求 ID 的成功房间操作响应发送，客户端只更新令牌
+func (r *Room) refreshSessionTokens(now time.Time) {
+	r.tokensRefreshedAt = now
+	for playerID, conn := range r.connections {
+		r.sendSessionToken(conn, playerID)
+	}
+	for spectatorID, conn := range r.spectators {
+		r.sendSessionToken(conn, spectatorID)
+	}
+}
+
+// sendSessionToken 给一个在线会话发送新的会话令牌（失败时客户端继续使用旧令牌，下次续期再试）
+func (r *Room) sendSessionToken(conn Session, playerID int32) {
+	if sessionClosed(conn) {
+		return
+	}
+	token, err := GenerateSessionToken(playerID, r.id)
+	if err != nil {
+		log.Printf("续期玩家 %d 的会话令牌失败: %v", playerID, err)
+		return
+	}
+	packet, err := protocol.NewRoomActionResponsePacket(0, true, gamev1.ErrorCode_ERROR_CODE_UNSPECIFIED, nil, "", token, r.id)
+	if err != nil {
+		return
+	}
+	if data, err := protocol.MarshalPacket(packet); err == nil {
+		_ = conn.Send(data)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"bomberman/pkg/protocol"
+)
+
+// refreshedToken 解析会话收到的最后一个数据包中续期的令牌，返回令牌中的玩家 ID 和房间 ID
+func refreshedToken(t *testing.T, conn *fakeSession) (int32, string) {
+	t.Helper()
+	pkt, err := protocol.UnmarshalPacket(conn.last)
+	if err != nil {
+		t.Fatalf("解析数据包失败: %v", err)
+	}
+	resp, err := protocol.ParseRoomActionResponse(pkt)
+	if err != nil {
+		t.Fatalf("期望房间操作响应: %v", err)
+	}
+	if !resp.Success || resp.RequestId != 0 || resp.RoomId == "" {
+		t.Fatalf("续期响应 %v，期望无请求 ID 的成功响应", resp)
+	}
+	playerID, roomID, err := VerifySessionToken(resp.SessionToken)
+	if err != nil {
+		t.Fatalf("续期的令牌无效: %v", err)
+	}
+	return playerID, roomID
+}
+
+// TestRefreshSessionTokens 在线玩家和观战者按间隔收到新的会话令牌
+func TestRefreshSessionTokens(t *testing.T) {
+	r := newTestRoom(t, 0)
+	spectator := &fakeSession{id: 100, roomID: r.id}
+	r.spectators[100] = spectator
+
+	now := time.Now()
+	r.tokensRefreshedAt = now
+	r.maybeRefreshSessionTokens(now.Add(SessionRefreshInterval - time.Second))
+	if sent := spectator.sent; sent != 0 {
+		t.Fatalf("未到续期间隔发送了 %d 个数据包", sent)
+	}
+
+	r.maybeRefreshSessionTokens(now.Add(SessionRefreshInterval))
+	sessions := map[int32]*fakeSession{100: spectator}
+	for id, conn := range r.connections {
+		sessions[id] = conn.(*fakeSession)
+	}
+	for id, conn := range sessions {
+		playerID, roomID := refreshedToken(t, conn)
+		if playerID != id || roomID != r.id {
+			t.Errorf("会话 %d 收到玩家 %d 房间 %q 的令牌", id, playerID, roomID)
+		}
+	}
+}
+
+// TestReconnectWindowWithinTokenLifetime 断线保留时间不超过续期后令牌保证的剩余有效期
+func TestReconnectWindowWithinTokenLifetime(t *testing.T) {
+	if OfflinePlayerTimeout > SessionReconnectWindow {
+		t.Errorf("断线玩家保留 %v，超过令牌保证的 %v", OfflinePlayerTimeout, SessionReconnectWindow)
+	}
+	s := &GameServer{}
+	s.SetOfflineTimeout(time.Hour)
+	if s.offlineTimeout != SessionReconnectWindow {
+		t.Errorf("保留时间 %v，期望截断为 %v", s.offlineTimeout, SessionReconnectWindow)
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"log"
+	"time"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// 观战者重连：观战者断线时直接移出房间（不占用玩家位置，不做断线保护），
+// 但加入时同样签发了会话令牌（在线期间定期续期，见 session_refresh.go），
+// 断线后 SessionReconnectWindow 内带着它重连可以回到原来的观战位置（同一 ID 和名字）
+
+// offlineSpectator 断线的观战者
+type offlineSpectator struct {
+	name  string
+	since time.Time
+}
+
+// rememberOfflineSpectator 记下断线的观战者，顺带清理超过重连时间的记录
+func (r *Room) rememberOfflineSpectator(spectatorID int32) {
+	now := time.Now()
+	for id, offline := range r.offlineSpectators {
+		if now.Sub(offline.since) > SessionReconnectWindow {
+			delete(r.offlineSpectators, id)
+		}
+	}
+	r.offlineSpectators[spectatorID] = offlineSpectator{name: r.spectatorNames[spectatorID], since: now}
+}
+
+// reconnectSpectator 观战者重连：仍在房间中时替换连接，断线不久时重新加入观战（观战人数已满时失败）
+func (r *Room) reconnectSpectator(spectatorID int32, conn Session) bool {
+	if _, ok := r.spectators[spectatorID]; ok {
+		r.spectators[spectatorID] = conn
+		log.Printf("观战者 %d 在线重连，连接已替换", spectatorID)
+		return true
+	}
+
+	offline, ok := r.offlineSpectators[spectatorID]
+	if !ok {
+		return false
+	}
+	if time.Since(offline.since) > SessionReconnectWindow || len(r.spectators) >= MaxSpectators {
+		delete(r.offlineSpectators, spectatorID)
+		return false
+	}
+	delete(r.offlineSpectators, spectatorID)
+	r.spectators[spectatorID] = conn
+	r.spectatorNames[spectatorID] = offline.name
+
+	log.Printf("观战者 %d (%s) 重连回到房间 %s", spectatorID, offline.name, r.id)
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_SpectatorJoined{
+			SpectatorJoined: &gamev1.SpectatorJoinedEvent{
+				SpectatorId:    spectatorID,
+				Name:           offline.name,
+				SpectatorCount: int32(len(r.spectators)),
+			},
+		},
+	})
+	r.broadcastRoomState()
+	return true
+}
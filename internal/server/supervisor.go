@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// 多地址监听：一个进程同时在多个地址/协议上接受连接（例如 TCP :8080、KCP :8081、WS :8082），
+// 所有监听器共用同一个 RoomManager。每个监听器单独统计连接数，可以单独停止：
+// 停止后不再接受新连接，已建立的连接照常游戏直到自行断开（断线重连可以走其他监听器）
+
+// ListenerConfig 一个监听器的配置
+type ListenerConfig struct {
+	Name  string `json:"name"`  // 监听器名称（指标和运维命令使用，空表示 proto@addr）
+	Proto string `json:"proto"` // tcp、kcp 或 ws
+	Addr  string `json:"addr"`  // 监听地址，例如 ":8080"
+}
+
+// ServerConfig 服务器配置文件（JSON，-config 指定）
+type ServerConfig struct {
+	Listeners []ListenerConfig `json:"listeners"`
+}
+
+// LoadServerConfig 读取服务器配置文件并校验监听器：协议必须有效，名称与地址不能重复
+func LoadServerConfig(path string) (*ServerConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg ServerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if err := validateListeners(cfg.Listeners); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validateListeners 校验监听器配置并补全名称
+func validateListeners(listeners []ListenerConfig) error {
+	names := make(map[string]bool)
+	addrs := make(map[string]bool)
+	for i := range listeners {
+		l := &listeners[i]
+		l.Proto = strings.ToLower(l.Proto)
+		switch l.Proto {
+		case "tcp", "kcp", "ws":
+		default:
+			return fmt.Errorf("监听器 %d: 不支持的协议 %q（可选: tcp、kcp、ws）", i, l.Proto)
+		}
+		if l.Addr == "" {
+			return fmt.Errorf("监听器 %d: 缺少监听地址", i)
+		}
+		if l.Name == "" {
+			l.Name = l.Proto + "@" + l.Addr
+		}
+		if names[l.Name] {
+			return fmt.Errorf("监听器名称重复: %s", l.Name)
+		}
+		names[l.Name] = true
+		// TCP 和 WS 都占用 TCP 端口，KCP 占用 UDP 端口
+		network := "tcp"
+		if l.Proto == "kcp" {
+			network = "udp"
+		}
+		if addrs[network+l.Addr] {
+			return fmt.Errorf("监听器 %s: 地址 %s 已被其他监听器占用", l.Name, l.Addr)
+		}
+		addrs[network+l.Addr] = true
+	}
+	return nil
+}
+
+// defaultListeners 未使用配置文件时的监听器：TCP 与 KCP 监听同一地址，另可开启 WebSocket
+func defaultListeners(addr, wsAddr string) []ListenerConfig {
+	listeners := []ListenerConfig{
+		{Name: "tcp", Proto: "tcp", Addr: addr},
+		{Name: "kcp", Proto: "kcp", Addr: addr},
+	}
+	if wsAddr != "" {
+		listeners = append(listeners, ListenerConfig{Name: "ws", Proto: "ws", Addr: wsAddr})
+	}
+	return listeners
+}
+
+// managedListener 运行中的监听器及其指标
+type managedListener struct {
+	config   ListenerConfig
+	listener ServerListener
+
+	accepted atomic.Int64 // 累计接受的连接数
+	active   atomic.Int64 // 当前连接数
+
+	stopOnce sync.Once
+	stopped  atomic.Bool
+	done     chan struct{} // 接受循环退出时关闭
+}
+
+// stop 停止接受新连接（可重复调用）
+func (l *managedListener) stop() {
+	l.stopOnce.Do(func() {
+		l.stopped.Store(true)
+		l.listener.Close()
+	})
+}
+
+// label 日志中的监听器描述
+func (l *managedListener) label() string {
+	if l.config.Proto == "ws" {
+		return fmt.Sprintf("%s (ws://%s%s)", l.config.Name, l.config.Addr, WebSocketPath)
+	}
+	return fmt.Sprintf("%s (%s %s)", l.config.Name, strings.ToUpper(l.config.Proto), l.config.Addr)
+}
+
+// ListenerStats 监听器状态（运维控制台与指标使用）
+type ListenerStats struct {
+	Name     string
+	Proto    string
+	Addr     string
+	Accepted int64
+	Active   int64
+	Stopped  bool
+}
+
+// openListeners 按配置打开全部监听器，任何一个失败时关闭已打开的监听器
+func openListeners(configs []ListenerConfig) ([]*managedListener, error) {
+	opened := make([]*managedListener, 0, len(configs))
+	for _, cfg := range configs {
+		listener, err := newListener(cfg.Proto, cfg.Addr)
+		if err != nil {
+			for _, l := range opened {
+				l.listener.Close()
+			}
+			return nil, fmt.Errorf("监听器 %s 启动失败: %w", cfg.Name, err)
+		}
+		opened = append(opened, &managedListener{config: cfg, listener: listener, done: make(chan struct{})})
+	}
+	return opened, nil
+}
+
+// SetListeners 使用配置文件中的监听器代替 -addr/-proto/-ws-addr（需在 Start 前调用）
+func (s *GameServer) SetListeners(listeners []ListenerConfig) {
+	s.listenerConfigs = listeners
+}
+
+// ListenerStats 返回所有监听器的状态（按配置顺序）
+func (s *GameServer) ListenerStats() []ListenerStats {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	stats := make([]ListenerStats, 0, len(s.listeners))
+	for _, l := range s.listeners {
+		stats = append(stats, ListenerStats{
+			Name:     l.config.Name,
+			Proto:    l.config.Proto,
+			Addr:     l.config.Addr,
+			Accepted: l.accepted.Load(),
+			Active:   l.active.Load(),
+			Stopped:  l.stopped.Load(),
+		})
+	}
+	return stats
+}
+
+// StopListener 单独停止一个监听器：不再接受新连接，已有连接不受影响，其他监听器继续运行
+func (s *GameServer) StopListener(name string) error {
+	s.listenersMu.Lock()
+	var target *managedListener
+	for _, l := range s.listeners {
+		if l.config.Name == name {
+			target = l
+			break
+		}
+	}
+	s.listenersMu.Unlock()
+	if target == nil {
+		return fmt.Errorf("监听器 %s 不存在", name)
+	}
+	if target.stopped.Load() {
+		return fmt.Errorf("监听器 %s 已停止", name)
+	}
+	target.stop()
+	<-target.done
+	log.Printf("监听器 %s 已停止，剩余 %d 个连接继续运行", target.label(), target.active.Load())
+	return nil
+}
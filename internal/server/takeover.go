@@ -0,0 +1,281 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+	"bomberman/pkg/protocol"
+)
+
+// TakeoverApprovalFrames 房主审批接管请求的时限
+// 需小于客户端等待加入响应的超时（10 秒），超时视为拒绝
+const TakeoverApprovalFrames = 8 * core.TPS
+
+// takeoverRequest 等待房主审批的 AI 接管请求
+// 申请提交后 Join 立即返回（申请者的接收循环照常处理 Ping），审批结果由房间直接发送加入响应或加入失败
+type takeoverRequest struct {
+	join      joinRequest
+	expiresAt int32
+}
+
+// sessionCloser 能判断连接是否已断开的会话（Connection、HeadlessSpectator）
+type sessionCloser interface {
+	Closed() bool
+}
+
+// sessionClosed 会话是否已断开（无法判断的会话视为在线）
+func sessionClosed(conn Session) bool {
+	c, ok := conn.(sessionCloser)
+	return ok && c.Closed()
+}
+
+// handleTakeoverJoin 游戏进行中的新玩家申请接管一个 AI，转交房主审批
+func (r *Room) handleTakeoverJoin(req joinRequest) {
+	if r.legacyMode {
+		req.respCh <- newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_LEGACY_UNSUPPORTED, []string{actionTakeover}, "兼容房间不支持接管 AI")
+		return
+	}
+	if r.pickTakeoverAI() < 0 || len(r.pendingTakeovers) >= r.countAliveAI() {
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_NO_AI_AVAILABLE, "房间 %s 没有可接管的 AI", r.id)
+		return
+	}
+	host, ok := r.connections[r.hostID]
+	if !ok {
+		req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED, "房主不在线，无法审批接管")
+		return
+	}
+	for _, pending := range r.pendingTakeovers {
+		if pending.join.conn == req.conn {
+			req.respCh <- newRoomError(gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED, "已有等待审批的接管请求")
+			return
+		}
+	}
+
+	r.nextTakeoverID++
+	requestID := r.nextTakeoverID
+	pending := &takeoverRequest{join: req, expiresAt: r.frameID + TakeoverApprovalFrames}
+	r.pendingTakeovers[requestID] = pending
+
+	// 审批完成前申请者不属于任何房间（断线时不会触发离开房间）
+	req.conn.SetRoomID("")
+	req.respCh <- nil
+
+	r.sendTakeoverRequest(host, requestID, pending)
+	log.Printf("房间 %s 收到接管请求 #%d (%s)，等待房主审批", r.id, requestID, pending.playerName(requestID))
+}
+
+// rejectTakeover 通知申请者接管失败
+func (r *Room) rejectTakeover(pending *takeoverRequest, err error) {
+	sendJoinFailure(pending.join.conn, pending.join.req.RequestID, err)
+}
+
+// sendTakeoverRequest 把接管申请发给房主审批（房主变更后会重新发给新房主）
+func (r *Room) sendTakeoverRequest(host Session, requestID int32, pending *takeoverRequest) {
+	r.sendEvent(host, &gamev1.GameEvent{
+		Event: &gamev1.GameEvent_TakeoverRequest{
+			TakeoverRequest: &gamev1.TakeoverRequestEvent{
+				RequestId:      requestID,
+				PlayerName:     pending.playerName(requestID),
+				ExpiresAtFrame: pending.expiresAt,
+			},
+		},
+	})
+}
+
+// playerName 申请者的名字（没填时按请求编号生成）
+func (t *takeoverRequest) playerName(requestID int32) string {
+	if name := t.join.req.PlayerName; name != "" {
+		return name
+	}
+	return fmt.Sprintf("Player%d", requestID)
+}
+
+// handleTakeoverDecision 房主审批接管请求
+func (r *Room) handleTakeoverDecision(requestID int32, approve bool) error {
+	pending, ok := r.pendingTakeovers[requestID]
+	if !ok {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND, "接管请求 #%d 不存在或已过期", requestID)
+	}
+	delete(r.pendingTakeovers, requestID)
+
+	if !approve {
+		log.Printf("房间 %s 接管请求 #%d 被房主拒绝", r.id, requestID)
+		r.rejectTakeover(pending, newRoomError(gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED, "房主拒绝了接管请求"))
+		return nil
+	}
+
+	// 申请者在审批期间断线：AI 继续控制
+	if sessionClosed(pending.join.conn) {
+		log.Printf("房间 %s 接管请求 #%d 的申请者已断线，AI 继续控制", r.id, requestID)
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND, "接管请求 #%d 的申请者已离线", requestID)
+	}
+
+	if err := r.completeTakeover(pending.join); err != nil {
+		r.rejectTakeover(pending, err)
+		return err
+	}
+	return nil
+}
+
+// expireTakeovers 清理申请者已断线或已加入其他房间的接管请求，拒绝超时或对局已结束的
+func (r *Room) expireTakeovers() {
+	for requestID, pending := range r.pendingTakeovers {
+		if sessionClosed(pending.join.conn) {
+			delete(r.pendingTakeovers, requestID)
+			log.Printf("房间 %s 接管请求 #%d 的申请者已断线", r.id, requestID)
+			continue
+		}
+		if roomID := pending.join.conn.GetRoomID(); roomID != "" {
+			delete(r.pendingTakeovers, requestID)
+			log.Printf("房间 %s 接管请求 #%d 的申请者已加入房间 %s", r.id, requestID, roomID)
+			continue
+		}
+		if r.state == StateRunning && r.frameID < pending.expiresAt {
+			continue
+		}
+		delete(r.pendingTakeovers, requestID)
+		log.Printf("房间 %s 接管请求 #%d 已失效", r.id, requestID)
+		r.rejectTakeover(pending, newRoomError(gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED, "接管请求超时或对局已结束"))
+	}
+}
+
+// completeTakeover 用申请者的连接替换一个存活 AI：沿用 AI 的玩家 ID、位置和属性
+func (r *Room) completeTakeover(req joinRequest) error {
+	// 审批期间申请者加入了其他房间：一个连接不能同时在两个房间中
+	if roomID := req.conn.GetRoomID(); roomID != "" {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED, "申请者已在房间 %s 中", roomID)
+	}
+	aiID := r.pickTakeoverAI()
+	if aiID < 0 {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_NO_AI_AVAILABLE, "房间 %s 没有可接管的 AI", r.id)
+	}
+
+	controller := r.aiControllers[aiID]
+	previousName := r.playerNames[aiID]
+	name := req.req.PlayerName
+	if name == "" {
+		name = fmt.Sprintf("Player%d", aiID)
+	}
+
+	delete(r.aiControllers, aiID)
+	req.conn.SetPlayerID(aiID)
+	req.conn.SetRoomID(r.id)
+	r.connections[aiID] = req.conn
+	r.playerNames[aiID] = name
+	if req.req.Account != "" {
+		r.playerAccounts[aiID] = req.req.Account
+	}
+	if req.req.TelemetryOptIn {
+		r.telemetryOptIn[aiID] = true
+	}
+	r.readyStatus[aiID] = true
+
+	if err := r.sendTakeoverJoin(req.conn, aiID, req.req); err != nil {
+		// 回滚：AI 继续控制
+		delete(r.connections, aiID)
+		delete(r.playerAccounts, aiID)
+		delete(r.telemetryOptIn, aiID)
+		r.aiControllers[aiID] = controller
+		r.playerNames[aiID] = previousName
+		req.conn.SetPlayerID(-1)
+		req.conn.SetRoomID("")
+		return err
+	}
+
+	log.Printf("房间 %s 玩家 %s 接管了 AI %d (%s)", r.id, name, aiID, previousName)
+	r.broadcastEvent(&gamev1.GameEvent{
+		Event: &gamev1.GameEvent_AiTakeover{
+			AiTakeover: &gamev1.AITakeoverEvent{
+				PlayerId:     aiID,
+				PlayerName:   name,
+				PreviousName: previousName,
+			},
+		},
+	})
+	r.broadcastRoomState()
+	return nil
+}
+
+// sendTakeoverJoin 发送加入响应和完整游戏状态（含开局以来的全部地图变化），join 为申请者的加入请求
+func (r *Room) sendTakeoverJoin(conn Session, playerID int32, join JoinEvent) error {
+	sessionToken, err := GenerateSessionToken(playerID, r.id)
+	if err != nil {
+		return fmt.Errorf("生成会话 Token 失败: %w", err)
+	}
+	packet, err := protocol.NewJoinResponsePacket(join.RequestID, true, playerID, "", r.game.Seed, int32(core.TPS), sessionToken, r.id, r.buildRoomState(), r.historySnapshot(), join.Profile)
+	if err != nil {
+		return fmt.Errorf("构造加入响应失败: %w", err)
+	}
+	data, err := protocol.MarshalPacket(packet)
+	if err != nil {
+		return fmt.Errorf("序列化加入响应失败: %w", err)
+	}
+	if err := conn.Send(data); err != nil {
+		return fmt.Errorf("发送加入响应失败: %w", err)
+	}
+
+	state := r.BuildGameState()
+	state.TileChanges = r.mapDiffTileChanges()
+	packet, err = protocol.NewGameStatePacketFromState(r.interestView(state, playerID))
+	if err != nil {
+		return fmt.Errorf("构造完整状态失败: %w", err)
+	}
+	data, err = protocol.MarshalPacket(packet)
+	if err != nil {
+		return fmt.Errorf("序列化完整状态失败: %w", err)
+	}
+	if err := conn.Send(data); err != nil {
+		return fmt.Errorf("发送完整状态失败: %w", err)
+	}
+	return nil
+}
+
+// mapDiffTileChanges 当前地图相对种子初始地图的全部差异
+// 中途加入的客户端按种子生成地图，再应用这些变化即可与服务器一致
+func (r *Room) mapDiffTileChanges() []*gamev1.TileChange {
+	base := r.newGameMap(r.game.Seed)
+	var changes []*gamev1.TileChange
+	for y := 0; y < r.game.Map.Height; y++ {
+		for x := 0; x < r.game.Map.Width; x++ {
+			tile := r.game.Map.GetTile(x, y)
+			if tile == base.GetTile(x, y) {
+				continue
+			}
+			changes = append(changes, &gamev1.TileChange{
+				X:       int32(x),
+				Y:       int32(y),
+				NewType: gamev1.TileType(tile),
+			})
+		}
+	}
+	return changes
+}
+
+// pickTakeoverAI 选择 ID 最小的存活 AI，没有时返回 -1
+func (r *Room) pickTakeoverAI() int32 {
+	ids := make([]int, 0, len(r.aiControllers))
+	for id := range r.aiControllers {
+		ids = append(ids, int(id))
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		if p := r.game.GetPlayer(id); p != nil && !p.Dead {
+			return int32(id)
+		}
+	}
+	return -1
+}
+
+// countAliveAI 存活 AI 数量（同时等待审批的请求不能超过该数量）
+func (r *Room) countAliveAI() int {
+	count := 0
+	for id := range r.aiControllers {
+		if p := r.game.GetPlayer(int(id)); p != nil && !p.Dead {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+)
+
+// requestTakeover 以 conn 提交接管申请，返回房间的立即答复
+func requestTakeover(r *Room, conn Session, requestID int32) error {
+	respCh := make(chan error, 1)
+	conn.SetRoomID(r.id)
+	r.handleTakeoverJoin(joinRequest{conn: conn, req: JoinEvent{RequestID: requestID, TakeOverAI: true}, respCh: respCh})
+	err := <-respCh
+	if err != nil {
+		conn.SetRoomID("")
+	}
+	return err
+}
+
+// TestTakeoverRejectsDuplicateRequest 同一连接不能同时有两个待审批的接管请求
+func TestTakeoverRejectsDuplicateRequest(t *testing.T) {
+	r := newTestRoom(t, 2)
+	r.state = StateRunning
+	applicant := &fakeSession{id: -1}
+
+	if err := requestTakeover(r, applicant, 1); err != nil {
+		t.Fatalf("首次申请失败: %v", err)
+	}
+	err := requestTakeover(r, applicant, 2)
+	if code := errorCodeOf(err); code != gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED {
+		t.Fatalf("重复申请得到 %v，期望 TAKEOVER_DENIED", err)
+	}
+	if len(r.pendingTakeovers) != 1 {
+		t.Errorf("待审批请求 %d 个，期望 1", len(r.pendingTakeovers))
+	}
+}
+
+// TestTakeoverDeniedAfterJoiningAnotherRoom 审批期间申请者加入了其他房间时批准失败，AI 继续控制
+func TestTakeoverDeniedAfterJoiningAnotherRoom(t *testing.T) {
+	r := newTestRoom(t, 1)
+	r.state = StateRunning
+	applicant := &fakeSession{id: -1}
+
+	if err := requestTakeover(r, applicant, 1); err != nil {
+		t.Fatalf("申请失败: %v", err)
+	}
+	applicant.SetRoomID("other")
+	applicant.SetPlayerID(5)
+
+	var requestID int32
+	for id := range r.pendingTakeovers {
+		requestID = id
+	}
+	err := r.handleTakeoverDecision(requestID, true)
+	if code := errorCodeOf(err); code != gamev1.ErrorCode_ERROR_CODE_TAKEOVER_DENIED {
+		t.Fatalf("批准得到 %v，期望 TAKEOVER_DENIED", err)
+	}
+	if len(r.pendingTakeovers) != 0 {
+		t.Errorf("失败的请求应被移除，剩余 %d 个", len(r.pendingTakeovers))
+	}
+	if len(r.aiControllers) != 1 {
+		t.Errorf("AI 控制器 %d 个，期望 AI 继续控制", len(r.aiControllers))
+	}
+	if applicant.GetRoomID() != "other" || applicant.ID() != 5 {
+		t.Errorf("申请者被改为房间 %q 玩家 %d，期望保持在 other/5", applicant.GetRoomID(), applicant.ID())
+	}
+}
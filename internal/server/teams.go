@@ -0,0 +1,78 @@
+package server
+
+import (
+	"log"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// smallerTeam 新玩家（含 AI）加入的队伍：人少的一队，人数相同时为 TeamA
+// 不分队时也会分配，房主中途开启组队模式不需要重新分队
+func (r *Room) smallerTeam() int {
+	counts := make(map[int]int, core.TeamCount)
+	for _, team := range r.playerTeams {
+		counts[team]++
+	}
+	if counts[core.TeamB] < counts[core.TeamA] {
+		return core.TeamB
+	}
+	return core.TeamA
+}
+
+// setTeam 修改队伍：玩家只能修改自己，房主还可以指定 AI（targetID 为 0 表示自己）
+func (r *Room) setTeam(requestorID, targetID int32, team int) error {
+	if r.state != StateWaiting {
+		return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_GAME_IN_PROGRESS, []string{actionSetTeam}, "游戏中无法修改队伍")
+	}
+	if team != core.TeamA && team != core.TeamB {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_INVALID_ACTION, "无效的队伍: %d", team)
+	}
+	if targetID == 0 {
+		targetID = requestorID
+	}
+	if _, ok := r.connections[requestorID]; !ok {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_NOT_IN_ROOM, "玩家 %d 不在房间中", requestorID)
+	}
+	if targetID != requestorID {
+		if _, isAI := r.aiControllers[targetID]; !isAI {
+			return newRoomError(gamev1.ErrorCode_ERROR_CODE_TARGET_NOT_FOUND, "目标 AI %d 不在房间中", targetID)
+		}
+		if requestorID != r.hostID {
+			return newRoomErrorWithParams(gamev1.ErrorCode_ERROR_CODE_NOT_HOST, []string{actionSetTeam}, "只有房主可以修改 AI 的队伍")
+		}
+	}
+	r.playerTeams[targetID] = team
+	log.Printf("房间 %s 玩家 %d 加入队伍 %d", r.id, targetID, team)
+	return nil
+}
+
+// checkTeamsBalanced 组队模式开局前两队都至少要有一名玩家
+func (r *Room) checkTeamsBalanced() error {
+	counts := make(map[int]int, core.TeamCount)
+	for _, player := range r.game.Players {
+		counts[r.playerTeams[int32(player.ID)]]++
+	}
+	if counts[core.TeamA] == 0 || counts[core.TeamB] == 0 {
+		return newRoomError(gamev1.ErrorCode_ERROR_CODE_TEAMS_UNBALANCED, "队伍人数不足 (%d v %d)", counts[core.TeamA], counts[core.TeamB])
+	}
+	return nil
+}
+
+// applyTeams 开局时把队伍写入核心玩家（不分队时清空）
+func (r *Room) applyTeams() {
+	for _, player := range r.game.Players {
+		player.Team = core.TeamNone
+		if r.rules.Teams {
+			player.Team = r.playerTeams[int32(player.ID)]
+		}
+	}
+}
+
+// winningTeam GameOverEvent 中的获胜队伍：组队模式下为进门玩家的队伍，平局或不分队为 0
+func (r *Room) winningTeam(winnerID int32) int32 {
+	if !r.game.Rules.Teams || winnerID <= 0 {
+		return core.TeamNone
+	}
+	return int32(r.playerTeams[winnerID])
+}
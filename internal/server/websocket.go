@@ -0,0 +1,108 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// WebSocketPath WebSocket 监听的 HTTP 路径
+const WebSocketPath = "/ws"
+
+// wsListener WebSocket 监听器：每个 WebSocket 连接作为一个 net.Conn 交给 acceptLoop，
+// 消息边界由 WebSocket 帧提供（每个二进制帧是一个完整的 Packet），不使用长度前缀
+type wsListener struct {
+	listener  net.Listener
+	server    *http.Server
+	conns     chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newWSListener(addr string) (*wsListener, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	l := &wsListener{
+		listener: listener,
+		conns:    make(chan net.Conn),
+		closed:   make(chan struct{}),
+	}
+
+	// 不设置 Handshake：不校验 Origin，浏览器客户端可以从任意页面连接
+	mux := http.NewServeMux()
+	mux.Handle(WebSocketPath, websocket.Server{Handler: l.serve})
+	l.server = &http.Server{Handler: mux}
+	go func() {
+		if err := l.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("WebSocket 服务停止: %v", err)
+		}
+	}()
+	return l, nil
+}
+
+// serve 把握手完成的连接交给 Accept，并阻塞到连接关闭（处理函数返回时连接会被关闭）
+func (l *wsListener) serve(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	ws.MaxPayloadBytes = MaxPacketSize
+	conn := &wsConn{Conn: ws, done: make(chan struct{})}
+
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		return
+	}
+	<-conn.done
+}
+
+func (l *wsListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *wsListener) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		close(l.closed)
+		err = l.server.Close()
+	})
+	return err
+}
+
+func (l *wsListener) Addr() net.Addr {
+	return l.listener.Addr()
+}
+
+// wsConn WebSocket 连接，关闭时通知 serve 返回
+type wsConn struct {
+	*websocket.Conn
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *wsConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() { close(c.done) })
+	return err
+}
+
+// ReadPacket 读取一帧（每个二进制帧是一个完整的数据包）
+func (c *wsConn) ReadPacket() ([]byte, error) {
+	var data []byte
+	err := websocket.Message.Receive(c.Conn, &data)
+	return data, err
+}
+
+// WritePacket 把数据包作为一个二进制帧发送
+func (c *wsConn) WritePacket(data []byte) error {
+	return websocket.Message.Send(c.Conn, data)
+}
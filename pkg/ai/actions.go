@@ -78,6 +78,7 @@ func actEscape(bb *Blackboard) Status {
 			input, remainPath := MoveAlongPath(bb.Player, bb.Path)
 			bb.Path = remainPath
 			bb.NextInput = input
+			escapeSprint(bb)
 			return StatusRunning
 		}
 	}
@@ -90,7 +91,7 @@ func actEscape(bb *Blackboard) Status {
 
 	// 4. 规划路径
 	start := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
-	path := FindPath(bb.Game, start, *safePos)
+	path := bb.World.FindSafePath(start, *safePos, bb.Danger, bb.Player.ID, bb.Config)
 	if path == nil {
 		return StatusFailure
 	}
@@ -102,48 +103,33 @@ func actEscape(bb *Blackboard) Status {
 	input, remainPath := MoveAlongPath(bb.Player, bb.Path)
 	bb.Path = remainPath
 	bb.NextInput = input
+	escapeSprint(bb)
 	return StatusRunning
 }
 
-// findNearestSafePos BFS 寻找最近的安全格子
+// escapeSprint 逃生路径还剩不止一格时冲刺（最后一格不冲刺，方便停在格子中心）
+func escapeSprint(bb *Blackboard) {
+	bb.NextInput.Sprint = bb.Config.SprintToEscape && len(bb.Path) > 1
+}
+
+// findNearestSafePos 按距离场的 BFS 顺序寻找最近的安全格子
 func findNearestSafePos(bb *Blackboard) *core.GridPos {
 	start := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
-	queue := []core.GridPos{start}
-	visited := make(map[core.GridPos]bool)
-	visited[start] = true
-
-	directions := []core.GridPos{{GridX: 0, GridY: -1}, {GridX: 0, GridY: 1}, {GridX: -1, GridY: 0}, {GridX: 1, GridY: 0}}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
-
+	for _, current := range bb.World.DistanceFrom(start).Order {
 		// 如果该点安全，就是目标
 		if bb.Danger.IsSafe(current.GridX, current.GridY) {
-			return &current
-		}
-
-		for _, d := range directions {
-			next := core.GridPos{GridX: current.GridX + d.GridX, GridY: current.GridY + d.GridY}
-
-			if !isValid(next.GridX, next.GridY) {
-				continue
-			}
-			// 只搜索可行走的区域
-			if !isWalkable(bb.Game, next) {
-				continue
-			}
-			if !visited[next] {
-				visited[next] = true
-				queue = append(queue, next)
-			}
+			result := current
+			return &result
 		}
 	}
 	return nil
 }
 
-// actFindBrick 寻找可炸的砖块目标
+// actFindBrick 寻找可炸的砖块目标（终局时只在被困外圈时炸砖）
 func actFindBrick(bb *Blackboard) Status {
+	if bb.Endgame && !bb.endgameBlocked {
+		return StatusFailure
+	}
 	// 如果已有目标，先验证目标是否仍有效
 	if bb.CurrentTarget != nil {
 		if isBrickAttackPosition(bb, *bb.CurrentTarget) {
@@ -174,37 +160,17 @@ func actFindBrick(bb *Blackboard) Status {
 func findBrickAttackPosition(bb *Blackboard) *core.GridPos {
 	start := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
 
-	// BFS 找最近的砖块攻击点
-	queue := []core.GridPos{start}
-	visited := make(map[core.GridPos]bool)
-	visited[start] = true
-
-	directions := []core.GridPos{{GridX: 0, GridY: -1}, {GridX: 0, GridY: 1}, {GridX: -1, GridY: 0}, {GridX: 1, GridY: 0}}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+	// 终局被困时选最靠近中央的攻击点，打通去中央的路
+	if bb.Endgame {
+		return findEndgameBrickPosition(bb, start)
+	}
 
+	// 沿距离场的 BFS 顺序找最近的砖块攻击点
+	for _, current := range bb.World.DistanceFrom(start).Order {
 		if isBrickAttackPosition(bb, current) {
 			result := current
 			return &result
 		}
-
-		// 继续扩散
-		for _, d := range directions {
-			next := core.GridPos{GridX: current.GridX + d.GridX, GridY: current.GridY + d.GridY}
-			if !isValid(next.GridX, next.GridY) {
-				continue
-			}
-			if !isWalkable(bb.Game, next) {
-				continue
-			}
-
-			if !visited[next] {
-				visited[next] = true
-				queue = append(queue, next)
-			}
-		}
 	}
 	return nil
 }
@@ -214,7 +180,7 @@ func isBrickAttackPosition(bb *Blackboard, pos core.GridPos) bool {
 	if !isValid(pos.GridX, pos.GridY) {
 		return false
 	}
-	if !isWalkable(bb.Game, pos) {
+	if !bb.World.Walkable(pos) {
 		return false
 	}
 	if !bb.Danger.IsSafe(pos.GridX, pos.GridY) {
@@ -265,19 +231,23 @@ func actMoveToTarget(bb *Blackboard) Status {
 		return StatusSuccess
 	}
 
-	// 如果没有路径，规划路径
-	if len(bb.Path) == 0 {
-		bb.Path = FindPath(bb.Game, currentPos, *bb.CurrentTarget)
+	// 优先使用缓存路径；目标变化、砖块被炸或路径附近出现新炸弹时重新规划
+	if cached, ok := bb.paths.lookup(bb.World, *bb.CurrentTarget); ok {
+		bb.Path = cached
+	} else {
+		bb.Path = bb.World.FindSafePath(currentPos, *bb.CurrentTarget, bb.Danger, bb.Player.ID, bb.Config)
 		if len(bb.Path) == 0 {
 			// 无法到达
 			bb.CurrentTarget = nil // 放弃这个目标
 			return StatusFailure
 		}
+		bb.paths.store(bb.World, *bb.CurrentTarget, bb.Path)
 	}
 
 	// 执行路径
 	input, remainPath := MoveAlongPath(bb.Player, bb.Path)
 	bb.Path = remainPath
+	bb.paths.advance(remainPath)
 	bb.NextInput = input
 	return StatusRunning
 }
@@ -292,11 +262,16 @@ func actPlaceBomb(bb *Blackboard) Status {
 	if currentPos != *bb.CurrentTarget {
 		return StatusFailure
 	}
-	if !isBrickAttackPosition(bb, *bb.CurrentTarget) {
+	if !isBrickAttackPosition(bb, *bb.CurrentTarget) &&
+		!(bb.Config.ChaseEnemies && isEnemyAttackPosition(bb, *bb.CurrentTarget)) {
 		bb.CurrentTarget = nil
 		bb.Path = nil
 		return StatusFailure
 	}
+	if bb.Config.AvoidFriendlyFire && wouldTrapAlly(bb, currentPos) {
+		// 会把其他 AI 困死在爆炸范围内，先不放，等下一帧局势变化再判断
+		return StatusFailure
+	}
 
 	// 放置炸弹
 	bb.NextInput.Bomb = true
@@ -0,0 +1,82 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// chaseMaxDistance 追击时只考虑这么多步以内的放弹位置（太远的对手交给炸砖逻辑）
+const chaseMaxDistance = 12
+
+// actFindEnemy 寻找能炸到对手的放弹位置（AIConfig.ChaseEnemies 关闭时失败，交给炸砖逻辑）
+func actFindEnemy(bb *Blackboard) Status {
+	if !bb.Config.ChaseEnemies {
+		return StatusFailure
+	}
+
+	// 对手会移动：已有目标仍能炸到对手就继续，否则重新找
+	if bb.CurrentTarget != nil && isEnemyAttackPosition(bb, *bb.CurrentTarget) {
+		return StatusSuccess
+	}
+
+	start := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
+	field := bb.World.DistanceFrom(start)
+	for _, current := range field.Order {
+		if field.Dist[current.GridY][current.GridX] > chaseMaxDistance {
+			break
+		}
+		if isEnemyAttackPosition(bb, current) {
+			target := current
+			bb.CurrentTarget = &target
+			bb.Path = nil
+			return StatusSuccess
+		}
+	}
+	return StatusFailure
+}
+
+// isEnemyAttackPosition 在 pos 放弹能否波及存活的对手（沿四个方向，遇墙或砖块停止）
+func isEnemyAttackPosition(bb *Blackboard, pos core.GridPos) bool {
+	if !isValid(pos.GridX, pos.GridY) {
+		return false
+	}
+	if !bb.World.Walkable(pos) || !bb.Danger.IsSafe(pos.GridX, pos.GridY) {
+		return false
+	}
+	if enemyAt(bb, pos) {
+		return true
+	}
+
+	directions := []core.GridPos{{GridX: 0, GridY: -1}, {GridX: 0, GridY: 1}, {GridX: -1, GridY: 0}, {GridX: 1, GridY: 0}}
+	for _, d := range directions {
+		for i := 1; i <= bb.Player.BombRange; i++ {
+			cell := core.GridPos{GridX: pos.GridX + d.GridX*i, GridY: pos.GridY + d.GridY*i}
+			tile := bb.Game.Map.GetTile(cell.GridX, cell.GridY)
+			if tile == core.TileWall || tile == core.TileBrick {
+				break
+			}
+			if enemyAt(bb, cell) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// enemyAt 格子上是否有存活的对手（组队模式下不算队友；首领战中唯一的对手是首领）
+func enemyAt(bb *Blackboard, cell core.GridPos) bool {
+	if boss := bb.Game.Boss; bb.Game.Rules.BossMode && boss != nil {
+		return !boss.Defeated() && boss.Covers(cell)
+	}
+	for _, p := range bb.Game.Players {
+		if p.ID == bb.Player.ID || p.Dead {
+			continue
+		}
+		if bb.Game.Rules.Teams && p.Team != core.TeamNone && p.Team == bb.Player.Team {
+			continue
+		}
+		if core.PlayerXYToGrid(int(p.X), int(p.Y)) == cell {
+			return true
+		}
+	}
+	return false
+}
@@ -10,22 +10,43 @@ type Blackboard struct {
 	Game   *core.Game
 	Player *core.Player
 	Frame  int32
+	Config AIConfig
 
 	// 智能感知
 	Danger *DangerField
+	World  *World // 房间 tick 内共享的地图快照
 
 	// 行为状态
 	Path          []core.GridPos // 当前规划的路径
 	CurrentTarget *core.GridPos  // 当前最终目标（如某块砖或安全点）
 	NextInput     core.Input     // 本帧的输入
+	ActiveNode    string         // 本帧最后一个成功/运行中的动作节点（调试用）
+
+	// 终局信号（endgame.go）
+	RemainingFrames int32 // 距离限时结束的帧数，-1 表示不限时
+	SuddenDeath     bool  // 突然死亡已开始落墙
+	Endgame         bool  // 进入终局策略：不再炸砖，走向门或中央
+
+	paths          pathCache     // 跨帧保留的路径缓存
+	endgameSpot    *core.GridPos // 终局时选定的停留位置（跨帧保留）
+	endgameBlocked bool          // 终局时够不到内圈，允许继续炸砖（只炸通往中央方向的砖块）
+
+	lastX, lastY float64 // 上一次决策时的位置（检测被推开）
+	hasLast      bool
 }
 
 // ResetFrame 重置每帧状态
-func (bb *Blackboard) ResetFrame(game *core.Game, player *core.Player) {
+func (bb *Blackboard) ResetFrame(world *World, player *core.Player) {
+	game := world.Game
 	bb.Game = game
+	bb.World = world
 	bb.Player = player
 	bb.Frame = game.CurrentFrame
 	bb.NextInput = core.Input{}
+	bb.ActiveNode = ""
+	bb.checkDisplaced()
+	bb.updateEndgame()
+	bb.endgameBlocked = false
 
 	// BombJustPlaced 需要在逻辑处理完后重置，或者由 Action 显式设置
 	// 这里不重置 BombJustPlaced，因为它可能跨帧（比如放置那一帧之后的思考）
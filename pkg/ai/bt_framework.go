@@ -46,11 +46,16 @@ func (s *Selector) Tick(bb *Blackboard) Status {
 
 // Action 动作节点
 type Action struct {
-	Do func(bb *Blackboard) Status
+	Name string // 调试显示用
+	Do   func(bb *Blackboard) Status
 }
 
 func (a *Action) Tick(bb *Blackboard) Status {
-	return a.Do(bb)
+	status := a.Do(bb)
+	if status != StatusFailure && a.Name != "" {
+		bb.ActiveNode = a.Name
+	}
+	return status
 }
 
 // Condition 条件节点
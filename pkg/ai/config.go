@@ -0,0 +1,50 @@
+package ai
+
+import "bomberman/pkg/core"
+
+// AIConfig AI 行为参数
+type AIConfig struct {
+	// 寻路代价权重
+	DangerWeight float64 // 穿过危险格子的额外代价（乘以危险等级 0~1）
+	EnemyWeight  float64 // 靠近敌人的额外代价（乘以接近程度 0~1）
+	EnemyRadius  int     // 敌人影响半径（曼哈顿距离，格）
+
+	// 放弹策略
+	AvoidFriendlyFire bool // 放弹前预估是否会困死其他 AI，会则放弃
+
+	// 推人策略（房间开启玩家碰撞时生效）
+	ShoveIntoDanger bool // 面前的对手身后是危险区时把他推进去
+
+	// 冲刺策略
+	SprintToEscape bool // 逃离危险区时冲刺（到达最后一格前停止，避免冲过头）
+
+	// 难度相关（各档位的取值见 Difficulty.Config）
+	ThinkInterval int32 // 每隔多少帧重新决策，中间帧沿用上一次的移动（1 表示每帧决策）
+	DangerHorizon int32 // 只把这么多帧内爆炸的炸弹视为危险，0 表示所有炸弹（过小会误判逃生时机）
+	ChainDanger   bool  // 按连锁引爆计算炸弹的实际爆炸时间，越早爆炸的格子危险等级越高
+	ChaseEnemies  bool  // 主动追击对手：在能炸到对手的位置放弹（behaviors_attack.go）
+
+	// 终局策略（endgame.go）
+	EndgameFrames     int32 // 限时剩余这么多帧（或突然死亡开始）后停止炸砖，走向门或中央，0 表示不改变策略
+	SuddenDeathMargin int32 // 把这么多帧内将要落墙的格子视为危险（早于落墙预警撤离外圈），0 表示只看预警
+}
+
+// DefaultAIConfig 默认配置
+func DefaultAIConfig() AIConfig {
+	return AIConfig{
+		DangerWeight: 8.0,
+		EnemyWeight:  2.0,
+		EnemyRadius:  3,
+
+		AvoidFriendlyFire: true,
+
+		ShoveIntoDanger: true,
+
+		SprintToEscape: true,
+
+		ThinkInterval: 1,
+
+		EndgameFrames:     40 * core.TPS,
+		SuddenDeathMargin: 5 * core.TPS,
+	}
+}
@@ -5,82 +5,114 @@ import (
 )
 
 type AIController struct {
-	PlayerID int
-	bb       Blackboard
-	tree     Node
-	danger   DangerField
+	PlayerID   int
+	difficulty Difficulty
+	bb         Blackboard
+	tree       Node
+	danger     DangerField
+	script     *scriptRunner // 非 nil 时按脚本回放输入，不走行为树
+
+	lastDecision int32      // 上次决策的帧号（AIConfig.ThinkInterval）
+	lastInput    core.Input // 上次决策的输入，两次决策之间沿用其中的移动
 }
 
-func NewAIController(playerID int) *AIController {
+// NewAIController 创建指定难度的行为树 AI（参数见 Difficulty.Config）
+func NewAIController(playerID int, difficulty Difficulty) *AIController {
+	if difficulty < DifficultyEasy || difficulty > DifficultyHard {
+		difficulty = DifficultyNormal
+	}
 	c := &AIController{
-		PlayerID: playerID,
+		PlayerID:   playerID,
+		difficulty: difficulty,
 	}
+	c.bb.Config = difficulty.Config()
 
 	// 初始化黑板
 	c.bb.Danger = &c.danger
 
-	// 构建行为树
-	// Root Sequence: 先确保安全，再执行攻击
-
-	// 1. 生存逻辑 (Sequence)
-	// 如果 InDanger -> 执行 Escape
-	//   -> Escape Running: 返回 Running (中断后续)
-	//   -> Escape Success: 返回 Success (继续后续，说明已安全)
-	//   -> Escape Failure: 返回 Failure (无路可逃?) -> 应该让它继续尝试或者Fail
-	survivalSeq := &Sequence{
-		Children: []Node{
-			&Condition{Check: condIsInDanger},
-			&Action{Do: actEscape},
-		},
+	// 构建内置行为树（定义见 DefaultTreeDef）
+	tree, err := DefaultTreeDef().Build()
+	if err != nil {
+		panic(err) // 内置树只引用注册表中的节点，不会出错
 	}
+	c.tree = tree
 
-	// 安全选择器 (Selector)
-	// 尝试执行生存逻辑，如果不需要生存（InDanger=false），则默认为安全（Success）
-	safetySelector := &Selector{
-		Children: []Node{
-			survivalSeq,
-			// 如果不处于危险中（survivalSeq 失败），则返回 Success 继续执行攻击
-			&Action{Do: func(bb *Blackboard) Status { return StatusSuccess }},
-		},
-	}
+	return c
+}
 
-	// 2. 攻击逻辑 (Sequence)
-	attackSeq := &Sequence{
-		Children: []Node{
-			&Condition{Check: condCanPlaceBomb},
-			&Action{Do: actFindBrick},
-			&Action{Do: actMoveToTarget},
-			&Action{Do: actPlaceBomb},
-		},
+// SetTree 替换行为树（服务器从配置加载的树，应先经过 TreeDef.Build 校验）
+func (c *AIController) SetTree(tree Node) {
+	if tree != nil {
+		c.tree = tree
 	}
+}
 
-	// 根节点：顺序执行 安全检查 -> 攻击
-	c.tree = &Sequence{
-		Children: []Node{
-			safetySelector,
-			attackSeq,
-		},
+// NewScriptedController 创建按脚本回放输入的 AI（调试/测试用）
+func NewScriptedController(playerID int, script *Script) *AIController {
+	c := NewAIController(playerID, DifficultyNormal)
+	c.script = &scriptRunner{script: script}
+	return c
+}
+
+// Scripted 是否为脚本 AI
+func (c *AIController) Scripted() bool {
+	return c.script != nil
+}
+
+// Difficulty AI 难度（脚本 AI 返回 0，不显示难度）
+func (c *AIController) Difficulty() Difficulty {
+	if c.script != nil {
+		return 0
 	}
+	return c.difficulty
+}
 
-	return c
+// Reset 清空跨帧决策状态（新一局开始时调用），脚本从头执行
+func (c *AIController) Reset() {
+	c.bb = Blackboard{Config: c.bb.Config, Danger: &c.danger}
+	c.danger = DangerField{}
+	c.lastDecision, c.lastInput = 0, core.Input{}
+	if c.script != nil {
+		c.script.reset()
+	}
 }
 
+// Decide 单独决策（自行构建地图快照）
 func (c *AIController) Decide(game *core.Game) core.Input {
+	return c.DecideInWorld(NewWorld(game))
+}
+
+// DecideInWorld 使用房间 tick 内共享的地图快照进行决策
+func (c *AIController) DecideInWorld(world *World) core.Input {
+	game := world.Game
 	player := getPlayerByID(game, c.PlayerID)
 	if player == nil || player.Dead {
 		return core.Input{}
 	}
 
+	// 反应慢的 AI 隔几帧才重新决策，中间沿用上次的移动（不重复放弹和推人）
+	if c.script == nil && c.bb.Config.ThinkInterval > 1 && c.lastDecision > 0 &&
+		game.CurrentFrame-c.lastDecision < c.bb.Config.ThinkInterval {
+		input := c.lastInput
+		input.Bomb, input.Shove = false, false
+		return input
+	}
+
 	// 1. 重置黑板状态
-	c.bb.ResetFrame(game, player)
+	c.bb.ResetFrame(world, player)
 
 	// 2. 更新感知 (DangerField)
-	c.danger.Update(game)
+	c.danger.Update(game, c.bb.Config)
 
-	// 3. 执行行为树
-	c.tree.Tick(&c.bb)
+	// 3. 执行脚本或行为树
+	if c.script != nil {
+		c.script.tick(&c.bb)
+	} else {
+		c.tree.Tick(&c.bb)
+	}
 
 	// 4. 返回决策结果
+	c.lastDecision, c.lastInput = game.CurrentFrame, c.bb.NextInput
 	return c.bb.NextInput
 }
 
@@ -93,5 +125,14 @@ func getPlayerByID(game *core.Game, id int) *core.Player {
 	return nil
 }
 
-// SetConfig 兼容旧接口，虽然我们暂时没用配置
-func (c *AIController) SetConfig(cfg interface{}) {}
+// SetConfig 设置 AI 参数（兼容旧接口，非 AIConfig 类型的参数会被忽略）
+func (c *AIController) SetConfig(cfg interface{}) {
+	switch v := cfg.(type) {
+	case AIConfig:
+		c.bb.Config = v
+	case *AIConfig:
+		if v != nil {
+			c.bb.Config = *v
+		}
+	}
+}
@@ -6,27 +6,37 @@ import (
 
 // DangerField 危险场：记录每个格子的危险等级
 type DangerField struct {
-	Level [core.MapHeight][core.MapWidth]float64 // 危险等级 0~1，0=安全，1=必死
+	Level [core.MaxMapHeight][core.MaxMapWidth]float64 // 危险等级 0~1，0=安全，1=必死
 }
 
-// Update 更新危险场
-func (df *DangerField) Update(game *core.Game) {
+// Update 更新危险场（cfg.DangerHorizon、cfg.ChainDanger 决定如何看待炸弹）
+func (df *DangerField) Update(game *core.Game, cfg AIConfig) {
 	// 1. 清空
-	for y := 0; y < core.MapHeight; y++ {
-		for x := 0; x < core.MapWidth; x++ {
+	for y := 0; y < core.MaxMapHeight; y++ {
+		for x := 0; x < core.MaxMapWidth; x++ {
 			df.Level[y][x] = 0.0
 		}
 	}
 
 	// 2. 标记炸弹危险区域
-	for _, bomb := range game.Bombs {
-		// 计算炸弹当前的危险度 (时间越近越危险)
-		// 简单起见，只要有炸弹覆盖，就设为危险
+	// 默认只要有炸弹覆盖就设为危险；ChainDanger 时按实际爆炸时间分级（越近越危险）
+	explodeAt := bombExplodeFrames(game, cfg.ChainDanger)
+	for i, bomb := range game.Bombs {
+		left := explodeAt[i] - game.CurrentFrame
+		if cfg.DangerHorizon > 0 && left > cfg.DangerHorizon {
+			continue // 还早，（误）以为来得及
+		}
+		level := 1.0
+		if cfg.ChainDanger {
+			level = max(1-float64(left)/float64(bomb.FuseFrames()), 0.5)
+		}
 		cells := bomb.GetExplosionCells(game.Map)
+		if bomb.Sliding() {
+			cells = slidingExplosionCells(game, bomb, left)
+		}
 		for _, cell := range cells {
 			if isValid(cell.GridX, cell.GridY) {
-				// 标记为危险
-				df.Level[cell.GridY][cell.GridX] = 1.0
+				df.Level[cell.GridY][cell.GridX] = max(df.Level[cell.GridY][cell.GridX], level)
 			}
 		}
 	}
@@ -39,6 +49,101 @@ func (df *DangerField) Update(game *core.Game) {
 			}
 		}
 	}
+
+	// 4. 标记地图危险区域（预警中的也要提前避开）
+	for _, overlay := range game.HazardOverlays() {
+		for _, cell := range overlay.Cells {
+			if isValid(cell.GridX, cell.GridY) {
+				df.Level[cell.GridY][cell.GridX] = 1.0
+			}
+		}
+	}
+
+	// 5. 标记突然死亡即将落墙的格子（提前撤离）
+	for _, cell := range game.SuddenDeathWarnings() {
+		if isValid(cell.GridX, cell.GridY) {
+			df.Level[cell.GridY][cell.GridX] = 1.0
+		}
+	}
+	// SuddenDeathMargin 内将要落墙的外圈：预警之前就离开，寻路尽量绕开
+	if cfg.SuddenDeathMargin > 0 && game.SuddenDeathStartFrame > 0 {
+		for y := 0; y < game.Map.Height; y++ {
+			for x := 0; x < game.Map.Width; x++ {
+				drop := game.SuddenDeathDropFrame(core.GridPos{GridX: x, GridY: y})
+				if drop > 0 && drop-game.CurrentFrame <= cfg.SuddenDeathMargin {
+					df.Level[y][x] = max(df.Level[y][x], 0.5)
+				}
+			}
+		}
+	}
+
+	// 6. 标记决斗加时将被淹没的格子（倒计时期间就退回门口竞技场）
+	for _, cell := range game.OvertimeFloodCells() {
+		if isValid(cell.GridX, cell.GridY) {
+			df.Level[cell.GridY][cell.GridX] = 1.0
+		}
+	}
+
+	// 7. 标记首领身上和蓄力中的喷火范围（首领放的炸弹已按普通炸弹标记）
+	if boss := game.Boss; boss != nil && !boss.Defeated() {
+		cells := boss.Cells()
+		if boss.Attack == core.BossAttackFire {
+			cells = append(cells, game.BossFireCells(boss.Direction)...)
+		}
+		for _, cell := range cells {
+			if isValid(cell.GridX, cell.GridY) {
+				df.Level[cell.GridY][cell.GridX] = 1.0
+			}
+		}
+	}
+}
+
+// slidingExplosionCells 滑动中的炸弹停在哪里还不确定（有人挡路会提前停下），
+// 把爆炸前可能经过的每一格都当作爆炸中心
+func slidingExplosionCells(game *core.Game, bomb *core.Bomb, left int32) []core.GridPos {
+	var cells []core.GridPos
+	for _, pos := range bomb.SlidePath(game, max(left, 0)) {
+		at := *bomb
+		at.GridX, at.GridY = pos.GridX, pos.GridY
+		at.OffsetX, at.OffsetY = 0, 0
+		cells = append(cells, at.GetExplosionCells(game.Map)...)
+	}
+	return cells
+}
+
+// bombExplodeFrames 每个炸弹的实际爆炸帧：chain 时被更早爆炸的炸弹波及的炸弹随之提前引爆
+func bombExplodeFrames(game *core.Game, chain bool) []int32 {
+	frames := make([]int32, len(game.Bombs))
+	for i, bomb := range game.Bombs {
+		frames[i] = bomb.ExplodeAtFrame
+	}
+	if !chain {
+		return frames
+	}
+
+	cells := make([][]core.GridPos, len(game.Bombs))
+	for i, bomb := range game.Bombs {
+		cells[i] = bomb.GetExplosionCells(game.Map)
+	}
+	// 爆炸帧只会变小，反复传播直到稳定
+	for changed := true; changed; {
+		changed = false
+		for i := range game.Bombs {
+			for j, other := range game.Bombs {
+				if frames[j] <= frames[i] {
+					continue
+				}
+				for _, cell := range cells[i] {
+					if cell.GridX == other.GridX && cell.GridY == other.GridY {
+						frames[j] = frames[i]
+						changed = true
+						break
+					}
+				}
+			}
+		}
+	}
+	return frames
 }
 
 // InDanger 检查某位置是否危险
@@ -54,6 +159,7 @@ func (df *DangerField) IsSafe(x, y int) bool {
 	return !df.InDanger(x, y)
 }
 
+// isValid 格子是否在数组范围内（按最大地图尺寸）；实际地图外的格子 GetTile 视为墙，不可走
 func isValid(x, y int) bool {
-	return x >= 0 && x < core.MapWidth && y >= 0 && y < core.MapHeight
+	return x >= 0 && x < core.MaxMapWidth && y >= 0 && y < core.MaxMapHeight
 }
@@ -0,0 +1,25 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// DebugState AI 当前意图（调试广播用）
+type DebugState struct {
+	Node   string         // 最近一次决策停留的行为树节点
+	Target *core.GridPos  // 当前目标格子，可能为 nil
+	Path   []core.GridPos // 剩余规划路径
+}
+
+// DebugState 返回最近一次决策的快照（拷贝，可在房间 goroutine 外使用）
+func (c *AIController) DebugState() DebugState {
+	state := DebugState{
+		Node: c.bb.ActiveNode,
+		Path: append([]core.GridPos(nil), c.bb.Path...),
+	}
+	if c.bb.CurrentTarget != nil {
+		target := *c.bb.CurrentTarget
+		state.Target = &target
+	}
+	return state
+}
@@ -0,0 +1,44 @@
+package ai
+
+// Difficulty AI 难度档位
+type Difficulty int
+
+const (
+	DifficultyEasy Difficulty = iota + 1
+	DifficultyNormal
+	DifficultyHard
+)
+
+func (d Difficulty) String() string {
+	switch d {
+	case DifficultyEasy:
+		return "Easy"
+	case DifficultyNormal:
+		return "Normal"
+	case DifficultyHard:
+		return "Hard"
+	}
+	return ""
+}
+
+// Config 难度对应的 AI 参数：
+// Easy 反应慢、不追人、只看快要爆炸的炸弹（常常来不及逃），也不理会限时和落墙，直到预警才撤离；
+// Hard 按连锁引爆计算危险场，不回避对手并主动追击
+func (d Difficulty) Config() AIConfig {
+	cfg := DefaultAIConfig()
+	switch d {
+	case DifficultyEasy:
+		cfg.ThinkInterval = 12
+		cfg.DangerHorizon = 45
+		cfg.AvoidFriendlyFire = false
+		cfg.ShoveIntoDanger = false
+		cfg.SprintToEscape = false
+		cfg.EndgameFrames = 0
+		cfg.SuddenDeathMargin = 0
+	case DifficultyHard:
+		cfg.ChainDanger = true
+		cfg.ChaseEnemies = true
+		cfg.EnemyWeight = 0
+	}
+	return cfg
+}
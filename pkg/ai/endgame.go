@@ -0,0 +1,128 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// 终局策略：限时将尽（AIConfig.EndgameFrames）或突然死亡开始落墙后，不再炸砖攒道具，
+// 改为走向已露出的门或地图中央，并避开即将落墙的外圈（见 DangerField.Update）。
+// 被砖块困在外圈时（够不到 endgameMinRing 以内的格子）继续炸砖，但只炸通往中央方向的砖块
+
+// endgameMinRing 终局位置至少在第几圈以内（0 为最外圈），突然死亡从外向内落墙，外圈的格子撑不了多久
+const endgameMinRing = 3
+
+// ring 格子所在的圈（到地图边缘的最短距离）
+func ring(m *core.GameMap, pos core.GridPos) int {
+	return min(pos.GridX, pos.GridY, m.Width-1-pos.GridX, m.Height-1-pos.GridY)
+}
+
+// endgameScore 终局位置的评分（越小越好）：离中央越近越好，路程作为次要因素
+func endgameScore(m *core.GameMap, pos core.GridPos, dist int) int {
+	center := core.GridPos{GridX: m.Width / 2, GridY: m.Height / 2}
+	return manhattan(pos, center)*4 + dist
+}
+
+// updateEndgame 根据限时和突然死亡状态更新黑板上的终局信号
+func (bb *Blackboard) updateEndgame() {
+	bb.RemainingFrames = bb.Game.RemainingMatchFrames()
+	bb.SuddenDeath = bb.Game.Rules.SuddenDeath && bb.Game.SuddenDeathStartFrame > 0 &&
+		bb.Frame >= bb.Game.SuddenDeathStartFrame
+	bb.Endgame = bb.Config.EndgameFrames > 0 &&
+		(bb.SuddenDeath || bb.RemainingFrames >= 0 && bb.RemainingFrames <= bb.Config.EndgameFrames)
+}
+
+// condIsEndgame 是否进入终局
+func condIsEndgame(bb *Blackboard) bool {
+	return bb.Endgame
+}
+
+// actFindEndgameSpot 终局目标：已露出的门，否则离地图中央最近、短期内不会落墙的安全格子
+// 选中的位置跨帧保留，失效（将要落墙、出现危险）或门露出后才重新选择
+func actFindEndgameSpot(bb *Blackboard) Status {
+	if !bb.Endgame {
+		return StatusFailure
+	}
+	door := core.GridPos{GridX: bb.Game.Map.HiddenDoorPos.X, GridY: bb.Game.Map.HiddenDoorPos.Y}
+	doorOpen := bb.Game.Map.GetTile(door.GridX, door.GridY) == core.TileDoor && isEndgameSpot(bb, door)
+
+	spot := bb.endgameSpot
+	if spot == nil || !isEndgameSpot(bb, *spot) || doorOpen && *spot != door {
+		spot = findEndgameSpot(bb, door, doorOpen)
+		bb.endgameSpot = spot
+	}
+	// 够不到内圈：交给炸砖逻辑打通去中央的路
+	bb.endgameBlocked = spot == nil || ring(bb.Game.Map, *spot) < endgameMinRing && !(doorOpen && *spot == door)
+	if bb.endgameBlocked {
+		return StatusFailure
+	}
+	if bb.CurrentTarget == nil || *bb.CurrentTarget != *spot {
+		target := *spot
+		bb.CurrentTarget = &target
+		bb.Path = nil
+	}
+	// 终局走位不看能否放弹：自己的炸弹还没炸时不要从安全格走进爆炸范围，原地等待
+	if endgameStepIntoDanger(bb, *spot) {
+		return StatusRunning
+	}
+	return StatusSuccess
+}
+
+// endgameStepIntoDanger 去终局位置的下一步是否会从安全格走进危险格
+func endgameStepIntoDanger(bb *Blackboard, spot core.GridPos) bool {
+	current := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
+	if current == spot || bb.Danger.InDanger(current.GridX, current.GridY) {
+		return false
+	}
+	path := bb.World.FindSafePath(current, spot, bb.Danger, bb.Player.ID, bb.Config)
+	return len(path) > 0 && bb.Danger.InDanger(path[0].GridX, path[0].GridY)
+}
+
+// findEndgameSpot 在可达格子中选择终局位置：门可达时直接去门，否则选评分最好的格子
+func findEndgameSpot(bb *Blackboard, door core.GridPos, doorOpen bool) *core.GridPos {
+	start := core.PlayerXYToGrid(int(bb.Player.X), int(bb.Player.Y))
+	field := bb.World.DistanceFrom(start)
+
+	var best *core.GridPos
+	bestScore := 0
+	for _, current := range field.Order {
+		if !isEndgameSpot(bb, current) {
+			continue
+		}
+		if doorOpen && current == door {
+			return &current
+		}
+		score := endgameScore(bb.Game.Map, current, field.Dist[current.GridY][current.GridX])
+		if best == nil || score < bestScore {
+			pos := current
+			best, bestScore = &pos, score
+		}
+	}
+	return best
+}
+
+// isEndgameSpot 可以停留的终局位置：可走、安全，且在 SuddenDeathMargin 内不会落墙
+func isEndgameSpot(bb *Blackboard, pos core.GridPos) bool {
+	if !isValid(pos.GridX, pos.GridY) || !bb.World.Walkable(pos) || !bb.Danger.IsSafe(pos.GridX, pos.GridY) {
+		return false
+	}
+	drop := bb.Game.SuddenDeathDropFrame(pos)
+	return drop == 0 || drop-bb.Frame > bb.Config.SuddenDeathMargin
+}
+
+// findEndgameBrickPosition 终局被困时的炸砖位置：评分最好（最靠近中央）的砖块攻击点
+func findEndgameBrickPosition(bb *Blackboard, start core.GridPos) *core.GridPos {
+	field := bb.World.DistanceFrom(start)
+	var best *core.GridPos
+	bestScore := 0
+	for _, current := range field.Order {
+		if !isBrickAttackPosition(bb, current) {
+			continue
+		}
+		score := endgameScore(bb.Game.Map, current, field.Dist[current.GridY][current.GridX])
+		if best == nil || score < bestScore {
+			pos := current
+			best, bestScore = &pos, score
+		}
+	}
+	return best
+}
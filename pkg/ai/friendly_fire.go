@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// escapeReachCells 引信时间内 AI 最多能移动的格数（向下取整，按房间配置的引信和初始速度）
+func escapeReachCells(game *core.Game) int {
+	return int(float64(game.Config.BombFuseFrames) * game.Config.PlayerSpeed / core.TileSize)
+}
+
+// wouldTrapAlly 预估在 pos 放置炸弹后，是否会有其他 AI 落在爆炸范围内且无路可逃
+func wouldTrapAlly(bb *Blackboard, pos core.GridPos) bool {
+	bomb := core.NewBomb(pos.GridX, pos.GridY, bb.Player.ID, bb.Frame)
+	blast := make(map[core.GridPos]bool)
+	for _, cell := range bomb.GetExplosionCells(bb.Game.Map) {
+		blast[cell] = true
+	}
+
+	for _, p := range bb.Game.Players {
+		if p.ID == bb.Player.ID || p.Dead || !bb.World.IsAI(p.ID) {
+			continue
+		}
+		x, y := p.GetGridPosition()
+		allyPos := core.GridPos{GridX: x, GridY: y}
+		if !blast[allyPos] {
+			continue
+		}
+		if !canEscapeBlast(bb, allyPos, pos, blast) {
+			return true
+		}
+	}
+	return false
+}
+
+// canEscapeBlast 从 from 出发，在引信时间内能否到达既不在新爆炸范围、也不在已有危险区的格子
+// 新炸弹所在格视为障碍（起点除外）
+func canEscapeBlast(bb *Blackboard, from, bombPos core.GridPos, blast map[core.GridPos]bool) bool {
+	reach := escapeReachCells(bb.Game)
+	dist := map[core.GridPos]int{from: 0}
+	queue := []core.GridPos{from}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if !blast[current] && bb.Danger.IsSafe(current.GridX, current.GridY) {
+			return true
+		}
+		if dist[current] >= reach {
+			continue
+		}
+
+		for _, d := range directions {
+			next := core.GridPos{GridX: current.GridX + d.GridX, GridY: current.GridY + d.GridY}
+			if next == bombPos || !bb.World.Walkable(next) {
+				continue
+			}
+			if _, seen := dist[next]; seen {
+				continue
+			}
+			dist[next] = dist[current] + 1
+			queue = append(queue, next)
+		}
+	}
+	return false
+}
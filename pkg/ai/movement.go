@@ -32,7 +32,7 @@ func FindPath(game *core.Game, start, end core.GridPos) []core.GridPos {
 		for _, d := range directions {
 			next := core.GridPos{GridX: current.GridX + d.GridX, GridY: current.GridY + d.GridY}
 
-			if next.GridX < 0 || next.GridX >= core.MapWidth || next.GridY < 0 || next.GridY >= core.MapHeight {
+			if !game.Map.InBounds(next.GridX, next.GridY) {
 				continue
 			}
 
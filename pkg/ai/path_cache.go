@@ -0,0 +1,89 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// pathCache 每个 AI 控制器独立的路径缓存
+// 以下任一情况发生时失效：
+//   - 目标变化
+//   - 地图格子变化（砖块被炸毁）
+//   - 路径附近新放置了炸弹
+type pathCache struct {
+	valid      bool
+	goal       core.GridPos
+	path       []core.GridPos
+	brickCount int
+	bombs      map[core.GridPos]bool
+}
+
+// lookup 命中时返回剩余路径
+func (pc *pathCache) lookup(w *World, goal core.GridPos) ([]core.GridPos, bool) {
+	if !pc.valid || pc.goal != goal || len(pc.path) == 0 {
+		return nil, false
+	}
+	if pc.brickCount != w.brickCount {
+		pc.invalidate()
+		return nil, false
+	}
+	for bomb := range w.bombs {
+		if pc.bombs[bomb] {
+			continue
+		}
+		if bombNearPath(bomb, pc.path) {
+			pc.invalidate()
+			return nil, false
+		}
+	}
+	return pc.path, true
+}
+
+// store 记录新规划的路径
+func (pc *pathCache) store(w *World, goal core.GridPos, path []core.GridPos) {
+	pc.valid = len(path) > 0
+	pc.goal = goal
+	pc.path = path
+	pc.brickCount = w.brickCount
+	pc.bombs = make(map[core.GridPos]bool, len(w.bombs))
+	for bomb := range w.bombs {
+		pc.bombs[bomb] = true
+	}
+}
+
+// advance 同步已经走过的路径
+func (pc *pathCache) advance(remain []core.GridPos) {
+	if !pc.valid {
+		return
+	}
+	pc.path = remain
+	if len(remain) == 0 {
+		pc.invalidate()
+	}
+}
+
+func (pc *pathCache) invalidate() {
+	pc.valid = false
+	pc.path = nil
+	pc.bombs = nil
+}
+
+// bombNearPath 炸弹是否落在路径上或其爆炸范围覆盖路径
+func bombNearPath(bomb core.GridPos, path []core.GridPos) bool {
+	for _, p := range path {
+		dx, dy := p.GridX-bomb.GridX, p.GridY-bomb.GridY
+		if dx == 0 && abs(dy) <= core.BombExplosionRange {
+			return true
+		}
+		if dy == 0 && abs(dx) <= core.BombExplosionRange {
+			return true
+		}
+	}
+	return false
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,140 @@
+package ai
+
+import (
+	"container/heap"
+
+	"bomberman/pkg/core"
+)
+
+// FindSafePath A* 寻路，边代价考虑危险等级与敌人距离
+// 返回从 start 到 end 的路径（不含 start），不可达返回 nil
+// 代价始终 >= 1，曼哈顿距离作为启发函数仍然可采纳
+func (w *World) FindSafePath(start, end core.GridPos, danger *DangerField, selfID int, cfg AIConfig) []core.GridPos {
+	if start == end {
+		return []core.GridPos{}
+	}
+	if !w.Walkable(end) {
+		return nil
+	}
+
+	enemies := w.enemyPositions(selfID)
+
+	var gScore [core.MaxMapHeight][core.MaxMapWidth]float64
+	var parent [core.MaxMapHeight][core.MaxMapWidth]int8
+	var closed [core.MaxMapHeight][core.MaxMapWidth]bool
+	for y := 0; y < core.MaxMapHeight; y++ {
+		for x := 0; x < core.MaxMapWidth; x++ {
+			gScore[y][x] = -1
+			parent[y][x] = -1
+		}
+	}
+
+	open := &nodeHeap{}
+	gScore[start.GridY][start.GridX] = 0
+	heap.Push(open, &pathNode{pos: start, f: float64(manhattan(start, end))})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*pathNode)
+		pos := current.pos
+		if closed[pos.GridY][pos.GridX] {
+			continue
+		}
+		closed[pos.GridY][pos.GridX] = true
+
+		if pos == end {
+			return reconstructPath(&parent, start, end)
+		}
+
+		for di, d := range directions {
+			next := core.GridPos{GridX: pos.GridX + d.GridX, GridY: pos.GridY + d.GridY}
+			if !w.Walkable(next) || closed[next.GridY][next.GridX] {
+				continue
+			}
+			g := gScore[pos.GridY][pos.GridX] + stepCost(next, danger, enemies, cfg)
+			if old := gScore[next.GridY][next.GridX]; old >= 0 && g >= old {
+				continue
+			}
+			gScore[next.GridY][next.GridX] = g
+			parent[next.GridY][next.GridX] = int8(di)
+			heap.Push(open, &pathNode{pos: next, f: g + float64(manhattan(next, end))})
+		}
+	}
+
+	return nil
+}
+
+// stepCost 进入某个格子的代价
+func stepCost(pos core.GridPos, danger *DangerField, enemies []core.GridPos, cfg AIConfig) float64 {
+	cost := 1.0
+	if danger != nil {
+		cost += cfg.DangerWeight * danger.Level[pos.GridY][pos.GridX]
+	}
+	if cfg.EnemyRadius > 0 {
+		for _, e := range enemies {
+			dist := manhattan(pos, e)
+			if dist <= cfg.EnemyRadius {
+				cost += cfg.EnemyWeight * float64(cfg.EnemyRadius-dist+1) / float64(cfg.EnemyRadius+1)
+			}
+		}
+	}
+	return cost
+}
+
+// enemyPositions 其他存活玩家所在格子（首领战中为首领占据的格子）
+func (w *World) enemyPositions(selfID int) []core.GridPos {
+	if boss := w.Game.Boss; w.Game.Rules.BossMode && boss != nil {
+		if boss.Defeated() {
+			return nil
+		}
+		return boss.Cells()
+	}
+	enemies := make([]core.GridPos, 0, len(w.Game.Players))
+	for _, p := range w.Game.Players {
+		if p.ID == selfID || p.Dead {
+			continue
+		}
+		x, y := p.GetGridPosition()
+		enemies = append(enemies, core.GridPos{GridX: x, GridY: y})
+	}
+	return enemies
+}
+
+func reconstructPath(parent *[core.MaxMapHeight][core.MaxMapWidth]int8, start, end core.GridPos) []core.GridPos {
+	var reversed []core.GridPos
+	current := end
+	for current != start {
+		reversed = append(reversed, current)
+		d := directions[parent[current.GridY][current.GridX]]
+		current = core.GridPos{GridX: current.GridX - d.GridX, GridY: current.GridY - d.GridY}
+	}
+	path := make([]core.GridPos, len(reversed))
+	for i := range reversed {
+		path[i] = reversed[len(reversed)-1-i]
+	}
+	return path
+}
+
+func manhattan(a, b core.GridPos) int {
+	return abs(a.GridX-b.GridX) + abs(a.GridY-b.GridY)
+}
+
+// pathNode A* 开放列表节点
+type pathNode struct {
+	pos core.GridPos
+	f   float64
+}
+
+// nodeHeap 按 f 值排序的最小堆
+type nodeHeap []*pathNode
+
+func (h nodeHeap) Len() int            { return len(h) }
+func (h nodeHeap) Less(i, j int) bool  { return h[i].f < h[j].f }
+func (h nodeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *nodeHeap) Push(x interface{}) { *h = append(*h, x.(*pathNode)) }
+func (h *nodeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,85 @@
+package ai
+
+import (
+	"testing"
+
+	"bomberman/pkg/core"
+)
+
+// benchGame 对局中段的默认地图：砖块已清空，四个角落各有一名玩家，中央附近有两颗炸弹
+func benchGame() (*core.Game, core.GridPos, core.GridPos) {
+	game := core.NewGame(42)
+	m := game.Map
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.GetTile(x, y) == core.TileBrick {
+				m.SetTile(x, y, core.TileEmpty)
+			}
+		}
+	}
+	corners := []core.GridPos{{GridX: 1, GridY: 1}, {GridX: m.Width - 2, GridY: 1}, {GridX: 1, GridY: m.Height - 2}, {GridX: m.Width - 2, GridY: m.Height - 2}}
+	for i, c := range corners {
+		x, y := core.GridToPlayerXY(c.GridX, c.GridY)
+		game.AddPlayer(core.NewPlayer(i+1, x, y, core.CharacterWhite))
+	}
+	game.AddBomb(core.NewBomb(m.Width/2-1, m.Height/2, 2, 0))
+	game.AddBomb(core.NewBomb(m.Width/2+1, m.Height/2-2, 3, 0))
+	return game, corners[0], corners[3]
+}
+
+func TestFindSafePathMatchesBFSLengthWithoutDanger(t *testing.T) {
+	game, start, end := benchGame()
+	bfs := FindPath(game, start, end)
+	if bfs == nil {
+		t.Fatal("BFS 找不到路径")
+	}
+	cfg := DefaultAIConfig()
+	cfg.EnemyRadius = 0
+	path := NewWorld(game).FindSafePath(start, end, nil, 1, cfg)
+	if len(path) != len(bfs) {
+		t.Fatalf("无危险时 A* 路径长度 %d，BFS 为 %d", len(path), len(bfs))
+	}
+	if path[len(path)-1] != end {
+		t.Fatalf("A* 路径终点 %v，期望 %v", path[len(path)-1], end)
+	}
+}
+
+// BenchmarkFindPathBFS 原来的 BFS 寻路（每步复制整条路径）
+func BenchmarkFindPathBFS(b *testing.B) {
+	game, start, end := benchGame()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if FindPath(game, start, end) == nil {
+			b.Fatal("找不到路径")
+		}
+	}
+}
+
+// BenchmarkFindSafePath A* 寻路（含危险场与敌人代价），包含每个 tick 构建一次 World 的开销
+func BenchmarkFindSafePath(b *testing.B) {
+	game, start, end := benchGame()
+	cfg := DefaultAIConfig()
+	var danger DangerField
+	danger.Update(game, cfg)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if NewWorld(game).FindSafePath(start, end, &danger, 1, cfg) == nil {
+			b.Fatal("找不到路径")
+		}
+	}
+}
+
+// BenchmarkFindSafePathSharedWorld 同一 tick 内多个 AI 共用 World 时单次 A* 寻路的开销
+func BenchmarkFindSafePathSharedWorld(b *testing.B) {
+	game, start, end := benchGame()
+	cfg := DefaultAIConfig()
+	var danger DangerField
+	danger.Update(game, cfg)
+	world := NewWorld(game)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if world.FindSafePath(start, end, &danger, 1, cfg) == nil {
+			b.Fatal("找不到路径")
+		}
+	}
+}
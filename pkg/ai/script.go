@@ -0,0 +1,225 @@
+package ai
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bomberman/pkg/core"
+)
+
+// 脚本 AI：按帧回放固定的输入序列，用于可复现的联机/平衡/新机制测试
+//
+// 语法（每行一条指令，# 开头为注释）：
+//
+//	move X Y      寻路走到格子 (X,Y) 并对齐中心；暂时不可达时原地等待重试
+//	wait N        原地不动 N 帧
+//	bomb          放置炸弹（占 1 帧）
+//	shove         推人（占 1 帧）
+//	hold KEYS N   按住 KEYS 共 N 帧，KEYS 为 u/d/l/r/b/s/x 的组合（上下左右/炸弹/推人/冲刺），
+//	              用于回放逐帧录制的原始输入
+//	loop          回到脚本开头
+//
+// 脚本执行完毕后原地待机。
+
+// ScriptError 脚本解析错误
+type ScriptError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("脚本第 %d 行: %s", e.Line, e.Msg)
+}
+
+type scriptOpKind int
+
+const (
+	scriptMove scriptOpKind = iota
+	scriptWait
+	scriptHold
+	scriptLoop
+)
+
+// scriptOp 一条脚本指令（bomb/shove 解析为 1 帧的 hold）
+type scriptOp struct {
+	kind   scriptOpKind
+	target core.GridPos
+	frames int
+	input  core.Input
+	line   int
+	text   string
+}
+
+// Script 解析后的 AI 脚本（只读，可被多个控制器共享）
+type Script struct {
+	ops []scriptOp
+}
+
+// ParseScript 解析脚本源码
+func ParseScript(src string) (*Script, error) {
+	script := &Script{}
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		op, err := parseScriptLine(line)
+		if err != nil {
+			return nil, &ScriptError{Line: lineNo, Msg: err.Error()}
+		}
+		op.line = lineNo
+		op.text = line
+		script.ops = append(script.ops, op)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(script.ops) == 0 {
+		return nil, &ScriptError{Line: lineNo, Msg: "脚本为空"}
+	}
+	return script, nil
+}
+
+func parseScriptLine(line string) (scriptOp, error) {
+	fields := strings.Fields(line)
+	args := fields[1:]
+	switch strings.ToLower(fields[0]) {
+	case "move":
+		if len(args) != 2 {
+			return scriptOp{}, fmt.Errorf("move 需要 2 个参数")
+		}
+		x, errX := strconv.Atoi(args[0])
+		y, errY := strconv.Atoi(args[1])
+		if errX != nil || errY != nil || !isValid(x, y) {
+			return scriptOp{}, fmt.Errorf("无效的格子坐标 %s %s", args[0], args[1])
+		}
+		return scriptOp{kind: scriptMove, target: core.GridPos{GridX: x, GridY: y}}, nil
+	case "wait":
+		n, err := parseFrames(args)
+		if err != nil {
+			return scriptOp{}, err
+		}
+		return scriptOp{kind: scriptWait, frames: n}, nil
+	case "bomb":
+		return scriptOp{kind: scriptHold, frames: 1, input: core.Input{Bomb: true}}, nil
+	case "shove":
+		return scriptOp{kind: scriptHold, frames: 1, input: core.Input{Shove: true}}, nil
+	case "hold":
+		if len(args) != 2 {
+			return scriptOp{}, fmt.Errorf("hold 需要按键和帧数两个参数")
+		}
+		input, err := parseKeys(args[0])
+		if err != nil {
+			return scriptOp{}, err
+		}
+		n, err := parseFrames(args[1:])
+		if err != nil {
+			return scriptOp{}, err
+		}
+		return scriptOp{kind: scriptHold, frames: n, input: input}, nil
+	case "loop":
+		return scriptOp{kind: scriptLoop}, nil
+	}
+	return scriptOp{}, fmt.Errorf("未知指令 %q", fields[0])
+}
+
+func parseFrames(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("需要帧数参数")
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("无效的帧数 %q", args[0])
+	}
+	return n, nil
+}
+
+func parseKeys(keys string) (core.Input, error) {
+	var input core.Input
+	for _, k := range strings.ToLower(keys) {
+		switch k {
+		case 'u':
+			input.Up = true
+		case 'd':
+			input.Down = true
+		case 'l':
+			input.Left = true
+		case 'r':
+			input.Right = true
+		case 'b':
+			input.Bomb = true
+		case 's':
+			input.Shove = true
+		case 'x':
+			input.Sprint = true
+		case '-':
+			// 占位，表示无按键
+		default:
+			return core.Input{}, fmt.Errorf("无效的按键 %q", k)
+		}
+	}
+	return input, nil
+}
+
+// scriptRunner 单个控制器的脚本执行进度
+type scriptRunner struct {
+	script  *Script
+	pc      int // 当前指令下标
+	elapsed int // 当前指令已执行的帧数
+}
+
+// reset 从头执行
+func (s *scriptRunner) reset() {
+	s.pc = 0
+	s.elapsed = 0
+}
+
+// tick 计算本帧输入，每帧恰好调用一次
+// move 到达和 loop 不占帧，同一帧最多执行一轮，避免只含这两类指令的循环卡死
+func (s *scriptRunner) tick(bb *Blackboard) {
+	for steps := 0; s.pc < len(s.script.ops); steps++ {
+		if steps > len(s.script.ops) {
+			return
+		}
+		op := s.script.ops[s.pc]
+		bb.ActiveNode = fmt.Sprintf("Script:%d %s", op.line, op.text)
+
+		switch op.kind {
+		case scriptLoop:
+			s.pc = 0
+			s.elapsed = 0
+			continue
+		case scriptMove:
+			bb.CurrentTarget = &op.target
+			if actMoveToTarget(bb) != StatusSuccess {
+				// 路上或暂时不可达：本帧结束，下一帧继续
+				return
+			}
+			bb.CurrentTarget = nil
+			bb.Path = nil
+			s.advance()
+			continue
+		case scriptWait, scriptHold:
+			bb.NextInput = op.input
+			s.elapsed++
+			if s.elapsed >= op.frames {
+				s.advance()
+			}
+			return
+		}
+	}
+	bb.ActiveNode = "Script:done"
+}
+
+func (s *scriptRunner) advance() {
+	s.pc++
+	s.elapsed = 0
+}
@@ -0,0 +1,42 @@
+package ai
+
+import (
+	"math"
+
+	"bomberman/pkg/core"
+)
+
+// displacedThreshold 两次决策之间位移超过该像素数视为被推开（正常移动每帧只有几个像素）
+const displacedThreshold = core.TileSize / 2
+
+// checkDisplaced 被推开后原路径不再连续，丢弃路径重新规划（目标保留）
+func (bb *Blackboard) checkDisplaced() {
+	x, y := bb.Player.X, bb.Player.Y
+	if bb.hasLast && math.Abs(x-bb.lastX)+math.Abs(y-bb.lastY) > displacedThreshold {
+		bb.Path = nil
+		bb.paths.invalidate()
+	}
+	bb.lastX, bb.lastY = x, y
+	bb.hasLast = true
+}
+
+// actShove 面前的对手身后是危险区时把他推进去
+func actShove(bb *Blackboard) Status {
+	if !bb.Config.ShoveIntoDanger || bb.Player.NextShoveFrame > bb.Frame {
+		return StatusFailure
+	}
+
+	target, dest := bb.Player.ShoveTarget(bb.Game)
+	if target == nil {
+		return StatusFailure
+	}
+	if bb.Config.AvoidFriendlyFire && bb.World.IsAI(target.ID) {
+		return StatusFailure
+	}
+	if !bb.Danger.InDanger(dest.GridX, dest.GridY) {
+		return StatusFailure
+	}
+
+	bb.NextInput.Shove = true
+	return StatusSuccess
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// 行为树的 JSON 定义：调整树结构不需要重新编译
+//
+// 每个节点是 {"type": ..., "name": ..., "children": [...]}：
+//
+//	sequence / selector   组合节点，children 为子节点
+//	action / condition    叶子节点，name 为注册表中的动作/条件名
+//
+// 叶子节点只能引用注册表中已有的动作和条件（见 TreeNodeNames），
+// 服务器加载失败时使用内置行为树（DefaultTreeDef）。
+
+// 节点类型
+const (
+	TreeSequence  = "sequence"
+	TreeSelector  = "selector"
+	TreeAction    = "action"
+	TreeCondition = "condition"
+)
+
+// TreeDef 行为树节点定义
+type TreeDef struct {
+	Type     string    `json:"type"`
+	Name     string    `json:"name,omitempty"`     // action/condition 的注册名
+	Children []TreeDef `json:"children,omitempty"` // sequence/selector 的子节点
+}
+
+// treeConditions 可在 JSON 中引用的条件
+var treeConditions = map[string]func(bb *Blackboard) bool{
+	"IsInDanger":   condIsInDanger,
+	"CanPlaceBomb": condCanPlaceBomb,
+	"IsEndgame":    condIsEndgame,
+}
+
+// treeActions 可在 JSON 中引用的动作
+var treeActions = map[string]func(bb *Blackboard) Status{
+	"Escape":          actEscape,
+	"Idle":            actIdle,
+	"FindBrick":       actFindBrick,
+	"FindEnemy":       actFindEnemy,
+	"FindEndgameSpot": actFindEndgameSpot,
+	"MoveToTarget":    actMoveToTarget,
+	"PlaceBomb":       actPlaceBomb,
+	"Shove":           actShove,
+}
+
+// actIdle 什么都不做，直接成功
+func actIdle(bb *Blackboard) Status {
+	return StatusSuccess
+}
+
+// TreeNodeNames 注册表中的条件名和动作名（按字母排序，供编辑器列出可用节点）
+func TreeNodeNames() (conditions, actions []string) {
+	for name := range treeConditions {
+		conditions = append(conditions, name)
+	}
+	for name := range treeActions {
+		actions = append(actions, name)
+	}
+	sort.Strings(conditions)
+	sort.Strings(actions)
+	return conditions, actions
+}
+
+// DefaultTreeDef 内置行为树：先确保安全，再推人或攻击
+func DefaultTreeDef() TreeDef {
+	// 1. 生存逻辑：处于危险中时逃生
+	//   -> Escape Running: 返回 Running（中断后续）
+	//   -> Escape Success: 返回 Success（已安全，继续后续）
+	// 不处于危险中时 Idle 返回 Success，继续执行攻击
+	safety := TreeDef{Type: TreeSelector, Children: []TreeDef{
+		{Type: TreeSequence, Children: []TreeDef{
+			{Type: TreeCondition, Name: "IsInDanger"},
+			{Type: TreeAction, Name: "Escape"},
+		}},
+		{Type: TreeAction, Name: "Idle"},
+	}}
+
+	// 2. 攻击逻辑：找砖块、走过去、放炸弹
+	attack := TreeDef{Type: TreeSequence, Children: []TreeDef{
+		{Type: TreeCondition, Name: "CanPlaceBomb"},
+		{Type: TreeAction, Name: "FindBrick"},
+		{Type: TreeAction, Name: "MoveToTarget"},
+		{Type: TreeAction, Name: "PlaceBomb"},
+	}}
+
+	// 3. 追击（仅 AIConfig.ChaseEnemies）：走到能炸到对手的位置放弹
+	chase := TreeDef{Type: TreeSequence, Children: []TreeDef{
+		{Type: TreeCondition, Name: "CanPlaceBomb"},
+		{Type: TreeAction, Name: "FindEnemy"},
+		{Type: TreeAction, Name: "MoveToTarget"},
+		{Type: TreeAction, Name: "PlaceBomb"},
+	}}
+
+	// 4. 终局：限时将尽或开始落墙后不再炸砖，走到门口或中央待着
+	endgame := TreeDef{Type: TreeSequence, Children: []TreeDef{
+		{Type: TreeCondition, Name: "IsEndgame"},
+		{Type: TreeAction, Name: "FindEndgameSpot"},
+		{Type: TreeAction, Name: "MoveToTarget"},
+	}}
+
+	// 5. 推人（机会主义）：能把对手推进危险区时优先推人，其次追击，再次终局走位，否则炸砖
+	offense := TreeDef{Type: TreeSelector, Children: []TreeDef{
+		{Type: TreeAction, Name: "Shove"},
+		chase,
+		endgame,
+		attack,
+	}}
+
+	// 根节点：顺序执行 安全检查 -> 推人/追击/终局/攻击
+	return TreeDef{Type: TreeSequence, Children: []TreeDef{safety, offense}}
+}
+
+// TreeError 行为树定义错误
+type TreeError struct {
+	Path string // 出错节点的位置，例如 root.children[1].children[0]
+	Msg  string
+}
+
+func (e *TreeError) Error() string {
+	return fmt.Sprintf("行为树 %s: %s", e.Path, e.Msg)
+}
+
+// Build 校验并构建行为树（节点本身无状态，构建结果可被多个控制器共享）
+func (d TreeDef) Build() (Node, error) {
+	return d.build("root")
+}
+
+func (d TreeDef) build(path string) (Node, error) {
+	switch d.Type {
+	case TreeSequence, TreeSelector:
+		if len(d.Children) == 0 {
+			return nil, &TreeError{Path: path, Msg: d.Type + " 没有子节点"}
+		}
+		children := make([]Node, 0, len(d.Children))
+		for i, child := range d.Children {
+			node, err := child.build(fmt.Sprintf("%s.children[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, node)
+		}
+		if d.Type == TreeSequence {
+			return &Sequence{Children: children}, nil
+		}
+		return &Selector{Children: children}, nil
+
+	case TreeAction:
+		do, ok := treeActions[d.Name]
+		if !ok {
+			return nil, &TreeError{Path: path, Msg: fmt.Sprintf("未知动作 %q", d.Name)}
+		}
+		return &Action{Name: d.Name, Do: do}, nil
+
+	case TreeCondition:
+		check, ok := treeConditions[d.Name]
+		if !ok {
+			return nil, &TreeError{Path: path, Msg: fmt.Sprintf("未知条件 %q", d.Name)}
+		}
+		return &Condition{Check: check}, nil
+
+	default:
+		return nil, &TreeError{Path: path, Msg: fmt.Sprintf("未知节点类型 %q", d.Type)}
+	}
+}
+
+// ParseTreeDef 解析并校验 JSON 行为树定义
+func ParseTreeDef(data []byte) (TreeDef, error) {
+	var def TreeDef
+	if err := json.Unmarshal(data, &def); err != nil {
+		return TreeDef{}, err
+	}
+	if _, err := def.Build(); err != nil {
+		return TreeDef{}, err
+	}
+	return def, nil
+}
+
+// LoadTreeDef 从文件读取 JSON 行为树定义
+func LoadTreeDef(path string) (TreeDef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TreeDef{}, err
+	}
+	return ParseTreeDef(data)
+}
+
+// MarshalTreeDef 导出为缩进的 JSON（可作为编辑的起点）
+func MarshalTreeDef(def TreeDef) ([]byte, error) {
+	return json.MarshalIndent(def, "", "  ")
+}
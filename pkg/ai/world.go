@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"bomberman/pkg/core"
+)
+
+// directions 上下左右四个方向
+var directions = []core.GridPos{{GridX: 0, GridY: -1}, {GridX: 0, GridY: 1}, {GridX: -1, GridY: 0}, {GridX: 1, GridY: 0}}
+
+// World 单个房间 tick 内所有 AI 共享的地图快照
+// 可行走格子只计算一次，BFS 距离场按起点懒计算并缓存，避免 3~4 个 AI 重复搜索
+type World struct {
+	Game  *core.Game
+	Frame int32
+
+	walkable   [core.MaxMapHeight][core.MaxMapWidth]bool
+	brickCount int
+	bombs      map[core.GridPos]bool
+	fields     map[core.GridPos]*DistanceField
+	aiPlayers  map[int]bool
+}
+
+// DistanceField 从某个起点出发的 BFS 距离场
+type DistanceField struct {
+	Source core.GridPos
+	Dist   [core.MaxMapHeight][core.MaxMapWidth]int  // -1 表示不可达
+	Parent [core.MaxMapHeight][core.MaxMapWidth]int8 // 到达该格子时使用的方向下标，-1 表示起点或不可达
+	Order  []core.GridPos                            // 按 BFS 扩展顺序排列的可达格子（距离非递减）
+}
+
+// NewWorld 根据当前游戏状态构建共享快照
+func NewWorld(game *core.Game) *World {
+	w := &World{
+		Game:   game,
+		Frame:  game.CurrentFrame,
+		bombs:  make(map[core.GridPos]bool),
+		fields: make(map[core.GridPos]*DistanceField),
+	}
+
+	for y := 0; y < game.Map.Height; y++ {
+		for x := 0; x < game.Map.Width; x++ {
+			tile := game.Map.GetTile(x, y)
+			if tile == core.TileBrick {
+				w.brickCount++
+			}
+			w.walkable[y][x] = tile != core.TileWall && tile != core.TileBrick
+		}
+	}
+
+	// 未爆炸弹也是障碍
+	for _, b := range game.Bombs {
+		if b.Exploded || !isValid(b.GridX, b.GridY) {
+			continue
+		}
+		w.walkable[b.GridY][b.GridX] = false
+		w.bombs[core.GridPos{GridX: b.GridX, GridY: b.GridY}] = true
+	}
+
+	// 首领身上走进去就死
+	if boss := game.Boss; boss != nil && !boss.Defeated() {
+		for _, cell := range boss.Cells() {
+			if isValid(cell.GridX, cell.GridY) {
+				w.walkable[cell.GridY][cell.GridX] = false
+			}
+		}
+	}
+
+	return w
+}
+
+// SetAIPlayers 标记哪些玩家由 AI 控制（用于友军误伤判断）
+func (w *World) SetAIPlayers(ids []int) {
+	w.aiPlayers = make(map[int]bool, len(ids))
+	for _, id := range ids {
+		w.aiPlayers[id] = true
+	}
+}
+
+// IsAI 检查玩家是否由 AI 控制
+func (w *World) IsAI(playerID int) bool {
+	return w.aiPlayers[playerID]
+}
+
+// Walkable 检查格子是否可行走
+func (w *World) Walkable(pos core.GridPos) bool {
+	if !isValid(pos.GridX, pos.GridY) {
+		return false
+	}
+	return w.walkable[pos.GridY][pos.GridX]
+}
+
+// DistanceFrom 返回从 src 出发的距离场（同一 tick 内复用）
+func (w *World) DistanceFrom(src core.GridPos) *DistanceField {
+	if field, ok := w.fields[src]; ok {
+		return field
+	}
+
+	field := &DistanceField{Source: src}
+	for y := 0; y < core.MaxMapHeight; y++ {
+		for x := 0; x < core.MaxMapWidth; x++ {
+			field.Dist[y][x] = -1
+			field.Parent[y][x] = -1
+		}
+	}
+
+	if isValid(src.GridX, src.GridY) {
+		// 起点本身可能站着炸弹（刚放下），依然允许从起点出发
+		field.Dist[src.GridY][src.GridX] = 0
+		field.Order = append(field.Order, src)
+		for i := 0; i < len(field.Order); i++ {
+			current := field.Order[i]
+			for di, d := range directions {
+				next := core.GridPos{GridX: current.GridX + d.GridX, GridY: current.GridY + d.GridY}
+				if !w.Walkable(next) || field.Dist[next.GridY][next.GridX] >= 0 {
+					continue
+				}
+				field.Dist[next.GridY][next.GridX] = field.Dist[current.GridY][current.GridX] + 1
+				field.Parent[next.GridY][next.GridX] = int8(di)
+				field.Order = append(field.Order, next)
+			}
+		}
+	}
+
+	w.fields[src] = field
+	return field
+}
+
+// FindPath 基于距离场的寻路，返回从 start 到 end 的路径（不含 start）
+func (w *World) FindPath(start, end core.GridPos) []core.GridPos {
+	if start == end {
+		return []core.GridPos{}
+	}
+	return w.DistanceFrom(start).PathTo(end)
+}
+
+// PathTo 回溯得到从起点到 end 的路径（不含起点），不可达返回 nil
+func (df *DistanceField) PathTo(end core.GridPos) []core.GridPos {
+	if !isValid(end.GridX, end.GridY) || df.Dist[end.GridY][end.GridX] < 0 {
+		return nil
+	}
+
+	path := make([]core.GridPos, df.Dist[end.GridY][end.GridX])
+	current := end
+	for i := len(path) - 1; i >= 0; i-- {
+		path[i] = current
+		d := directions[df.Parent[current.GridY][current.GridX]]
+		current = core.GridPos{GridX: current.GridX - d.GridX, GridY: current.GridY - d.GridY}
+	}
+	return path
+}
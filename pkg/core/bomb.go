@@ -2,16 +2,22 @@ package core
 
 // Bomb 炸弹
 type Bomb struct {
-	GridX int // 格子坐标X
-	GridY int // 格子坐标Y
+	ID    int32 // 对局内唯一的实体 ID（Game.AddBomb 分配，增量同步和兴趣管理按它比较）
+	GridX int   // 格子坐标X
+	GridY int   // 格子坐标Y
 
 	// 时间（帧为单位）
 	ExplodeAtFrame int32 // 引爆帧号
 	PlacedAtFrame  int32 // 放置时的帧号
 
 	// 属性
-	ExplosionRange int // 爆炸范围（格子数）
-	OwnerID        int // 放置者 ID
+	ExplosionRange int   // 爆炸范围（格子数）
+	OwnerID        int   // 放置者 ID
+	TouchChain     []int // 之后踢/扔过该炸弹的玩家（按先后顺序），最后一位获得击杀归属
+
+	// 滑动（bomb_slide.go）
+	VelX, VelY       float64 // 滑动速度（像素/帧，0 表示静止）
+	OffsetX, OffsetY float64 // 相对所在格子的像素偏移（滑动途中非 0）
 
 	// 状态
 	Exploded bool // 是否已爆炸（用于连锁爆炸）
@@ -30,6 +36,22 @@ func NewBomb(gridX, gridY int, ownerID int, currentFrame int32) *Bomb {
 	}
 }
 
+// Touch 记录玩家改变了炸弹的轨迹（踢/扔），之后的击杀归属该玩家
+func (b *Bomb) Touch(playerID int) {
+	if b.CreditID() == playerID {
+		return
+	}
+	b.TouchChain = append(b.TouchChain, playerID)
+}
+
+// CreditID 击杀归属：最后接触炸弹的玩家，没人接触过时为放置者
+func (b *Bomb) CreditID() int {
+	if n := len(b.TouchChain); n > 0 {
+		return b.TouchChain[n-1]
+	}
+	return b.OwnerID
+}
+
 // IsExploded 检查炸弹是否已爆炸
 func (b *Bomb) IsExploded(frameId int32) bool {
 	return frameId >= b.ExplodeAtFrame
@@ -59,10 +81,19 @@ func (b *Bomb) GetDangerLevel(frameId int32) float64 {
 	if remaining <= 0 {
 		return 1.0
 	}
-	if remaining >= float64(BombFuseFrames) {
+	fuse := float64(b.FuseFrames())
+	if remaining >= fuse {
 		return 0.0
 	}
-	return 1.0 - remaining/float64(BombFuseFrames)
+	return 1.0 - remaining/fuse
+}
+
+// FuseFrames 炸弹的引信总长（帧）：由放置帧和引爆帧推出，引信随房间配置变化
+func (b *Bomb) FuseFrames() int32 {
+	if fuse := b.ExplodeAtFrame - b.PlacedAtFrame; fuse > 0 {
+		return fuse
+	}
+	return BombFuseFrames
 }
 
 // GetExplosionCells 获取爆炸影响的格子（缓存友好）
@@ -77,7 +108,7 @@ func (b *Bomb) GetExplosionCells(gameMap *GameMap) []GridPos {
 			nx, ny := b.GridX+dir[0]*i, b.GridY+dir[1]*i
 
 			// 边界检查
-			if nx < 0 || nx >= MapWidth || ny < 0 || ny >= MapHeight {
+			if !gameMap.InBounds(nx, ny) {
 				break
 			}
 
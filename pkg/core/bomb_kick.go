@@ -0,0 +1,54 @@
+package core
+
+// 踢炸弹：拾取踢炸弹道具（ItemKick）后，朝相邻格子里静止的炸弹走过去会把它踢开，
+// 炸弹沿移动方向滑动，直到前方被墙、砖块、其他炸弹或玩家挡住（bomb_slide.go）。
+// 只在权威模式下生效，客户端的预测移动照常被炸弹挡住，炸弹的滑动以服务器同步为准
+
+// KickTarget 返回朝 dir 方向相邻格子里可以踢动的炸弹，没有则返回 nil
+func (p *Player) KickTarget(game *Game, dir DirectionType) *Bomb {
+	if p.Dead || !p.CanKick {
+		return nil
+	}
+
+	gridX, gridY := p.GetGridPosition()
+	offset := DirectionOffset(dir)
+	targetX, targetY := gridX+offset.GridX, gridY+offset.GridY
+
+	for _, bomb := range game.Bombs {
+		if bomb.Exploded || bomb.Sliding() || bomb.GridX != targetX || bomb.GridY != targetY {
+			continue
+		}
+		next := GridPos{GridX: targetX + offset.GridX, GridY: targetY + offset.GridY}
+		if !game.isSlideDestinationFree(next, bomb) {
+			return nil
+		}
+		return bomb
+	}
+	return nil
+}
+
+// Kick 移动被炸弹挡住时把它踢开（返回被踢的炸弹，没有踢动返回 nil）
+// 只处理水平或竖直方向的移动，斜向输入不踢
+func (p *Player) Kick(game *Game, dx, dy float64) *Bomb {
+	if !game.IsAuthoritative || (dx != 0) == (dy != 0) {
+		return nil
+	}
+
+	dir := DirRight
+	switch {
+	case dx < 0:
+		dir = DirLeft
+	case dy < 0:
+		dir = DirUp
+	case dy > 0:
+		dir = DirDown
+	}
+
+	bomb := p.KickTarget(game, dir)
+	if bomb == nil {
+		return nil
+	}
+	bomb.StartSlide(dir)
+	bomb.Touch(p.ID)
+	return bomb
+}
@@ -0,0 +1,105 @@
+package core
+
+// 滑动炸弹：炸弹带有速度时每帧沿一个方向移动，到达格子中心时若前方格子被占就停下。
+// 踢炸弹（bomb_kick.go）调用 StartSlide，服务器和客户端航位推算共用这里的规则。
+
+// BombSlideSpeed 炸弹滑动速度（像素/帧，需要整除 TileSize，保证每格都能恰好停在中心）
+const BombSlideSpeed = 4.0
+
+// StartSlide 炸弹开始朝 dir 滑动
+func (b *Bomb) StartSlide(dir DirectionType) {
+	b.VelX, b.VelY = 0, 0
+	switch dir {
+	case DirUp:
+		b.VelY = -BombSlideSpeed
+	case DirDown:
+		b.VelY = BombSlideSpeed
+	case DirLeft:
+		b.VelX = -BombSlideSpeed
+	case DirRight:
+		b.VelX = BombSlideSpeed
+	}
+}
+
+// Sliding 炸弹是否在滑动
+func (b *Bomb) Sliding() bool {
+	return b.VelX != 0 || b.VelY != 0
+}
+
+// PixelPosition 炸弹所在格子左上角的像素坐标加上滑动偏移
+func (b *Bomb) PixelPosition() (float64, float64) {
+	return float64(b.GridX*TileSize) + b.OffsetX, float64(b.GridY*TileSize) + b.OffsetY
+}
+
+// StepSlide 滑动一帧：从格子中心出发前检查前方格子，被占则停下；偏移满一格时进入下一格
+func (b *Bomb) StepSlide(g *Game) {
+	if !b.Sliding() || b.Exploded {
+		return
+	}
+	if b.OffsetX == 0 && b.OffsetY == 0 {
+		next := GridPos{GridX: b.GridX + sign(b.VelX), GridY: b.GridY + sign(b.VelY)}
+		if !g.isSlideDestinationFree(next, b) {
+			b.VelX, b.VelY = 0, 0
+			return
+		}
+	}
+	b.OffsetX += b.VelX
+	b.OffsetY += b.VelY
+	if b.OffsetX >= TileSize || b.OffsetX <= -TileSize {
+		b.GridX += sign(b.OffsetX)
+		b.OffsetX = 0
+	}
+	if b.OffsetY >= TileSize || b.OffsetY <= -TileSize {
+		b.GridY += sign(b.OffsetY)
+		b.OffsetY = 0
+	}
+}
+
+// SlidePath 按当前速度预测炸弹在之后 frames 帧内经过的格子（从当前格子开始，按先后顺序）
+// 只按当前的地图、炸弹和玩家位置推算，有人走进滑动路线时炸弹会提前停下
+func (b *Bomb) SlidePath(g *Game, frames int32) []GridPos {
+	sim := *b
+	path := []GridPos{{GridX: sim.GridX, GridY: sim.GridY}}
+	for i := int32(0); i < frames && sim.Sliding(); i++ {
+		sim.StepSlide(g)
+		if last := path[len(path)-1]; last.GridX != sim.GridX || last.GridY != sim.GridY {
+			path = append(path, GridPos{GridX: sim.GridX, GridY: sim.GridY})
+		}
+	}
+	return path
+}
+
+// isSlideDestinationFree 滑动的下一格必须在地图内、是空地，且没有其他炸弹和存活玩家
+func (g *Game) isSlideDestinationFree(dest GridPos, sliding *Bomb) bool {
+	if !g.Map.InBounds(dest.GridX, dest.GridY) {
+		return false
+	}
+	if g.Map.GetTile(dest.GridX, dest.GridY) != TileEmpty {
+		return false
+	}
+	for _, bomb := range g.Bombs {
+		if bomb != sliding && !bomb.Exploded && bomb.GridX == dest.GridX && bomb.GridY == dest.GridY {
+			return false
+		}
+	}
+	for _, p := range g.Players {
+		if p.Dead {
+			continue
+		}
+		if x, y := p.GetGridPosition(); x == dest.GridX && y == dest.GridY {
+			return false
+		}
+	}
+	return true
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
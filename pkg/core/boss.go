@@ -0,0 +1,310 @@
+package core
+
+// KillerBoss 被首领撞到或被首领的炸弹、火焰炸死时的击杀归属（也是首领炸弹的 OwnerID）
+const KillerBoss = -5
+
+// 首领战（GameRules.BossMode）：开局时地图中央出现一个占 BossSize×BossSize 格的首领，所有玩家合作击败它。
+// 首领何时移动、何时攻击由服务器的首领控制器决定，这里只负责规则：
+// 首领越过墙壁、压碎砖块，爆炸扣血（受伤后短暂无敌）、血量降低进入下一阶段、碰到首领的玩家死亡、攻击蓄力结束后放出
+
+// BossAttack 首领的攻击方式
+type BossAttack int
+
+const (
+	BossAttackNone  BossAttack = iota
+	BossAttackBombs            // 在身边放下炸弹（阶段越高放得越多、范围越大）
+	BossAttackFire             // 朝一个方向喷出两格宽的火焰（遇墙或砖块停止）
+)
+
+// Boss 首领
+type Boss struct {
+	GridX, GridY   int // 左上角格子
+	HP, MaxHP      int
+	Direction      DirectionType // 朝向（喷火、放炸弹的方向）
+	HurtUntilFrame int32         // 受伤无敌的截止帧
+	Attack         BossAttack    // 蓄力中的攻击（BossAttackNone 表示没有）
+	AttackFrame    int32         // 蓄力结束、放出攻击的帧
+}
+
+// NewBoss 创建满血的首领
+func NewBoss(gridX, gridY int) *Boss {
+	return &Boss{GridX: gridX, GridY: gridY, HP: BossMaxHP, MaxHP: BossMaxHP, Direction: DirDown}
+}
+
+// Stage 当前阶段（1 ~ BossStages）：血量每降低 MaxHP/BossStages 进入下一阶段
+func (b *Boss) Stage() int {
+	if b.MaxHP <= 0 {
+		return 1
+	}
+	stage := (b.MaxHP-b.HP)*BossStages/b.MaxHP + 1
+	return max(min(stage, BossStages), 1)
+}
+
+// Defeated 首领是否已被击败
+func (b *Boss) Defeated() bool {
+	return b.HP <= 0
+}
+
+// Covers 格子是否在首领身上
+func (b *Boss) Covers(cell GridPos) bool {
+	return cell.GridX >= b.GridX && cell.GridX < b.GridX+BossSize &&
+		cell.GridY >= b.GridY && cell.GridY < b.GridY+BossSize
+}
+
+// Cells 首领占据的格子
+func (b *Boss) Cells() []GridPos {
+	cells := make([]GridPos, 0, BossSize*BossSize)
+	for dy := 0; dy < BossSize; dy++ {
+		for dx := 0; dx < BossSize; dx++ {
+			cells = append(cells, GridPos{GridX: b.GridX + dx, GridY: b.GridY + dy})
+		}
+	}
+	return cells
+}
+
+// SpawnBoss 在地图中央放出首领并压碎身下的砖块，返回地图变化（由服务器随状态下发）；
+// 中央放不下时退到最近的空位
+func (g *Game) SpawnBoss() []TileChange {
+	centerX, centerY := (g.Map.Width-BossSize)/2, (g.Map.Height-BossSize)/2
+	bestX, bestY, bestDist := -1, -1, 0
+	for y := 0; y+BossSize <= g.Map.Height; y++ {
+		for x := 0; x+BossSize <= g.Map.Width; x++ {
+			if !g.bossFits(x, y) {
+				continue
+			}
+			if dist := absInt(x-centerX) + absInt(y-centerY); bestX < 0 || dist < bestDist {
+				bestX, bestY, bestDist = x, y, dist
+			}
+		}
+	}
+	if bestX < 0 {
+		return nil
+	}
+
+	g.Boss = NewBoss(bestX, bestY)
+	return g.crushBricks()
+}
+
+// bossFits 首领能否站在以 (x, y) 为左上角的区域：不能出界、不能压在炸弹或存活的玩家身上
+// （首领一次移动一整格，直接压死玩家来不及反应）。首领体型巨大，能越过墙壁、压碎砖块
+func (g *Game) bossFits(x, y int) bool {
+	if x < 0 || y < 0 || x+BossSize > g.Map.Width || y+BossSize > g.Map.Height {
+		return false
+	}
+	area := Boss{GridX: x, GridY: y}
+	for _, cell := range area.Cells() {
+		if g.bombAt(cell) || g.playerAt(cell) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *Game) bombAt(cell GridPos) bool {
+	for _, bomb := range g.Bombs {
+		if !bomb.Exploded && bomb.GridX == cell.GridX && bomb.GridY == cell.GridY {
+			return true
+		}
+	}
+	return false
+}
+
+// playerAt 格子上是否有存活的玩家
+func (g *Game) playerAt(cell GridPos) bool {
+	for _, p := range g.Players {
+		if !p.Dead && PlayerXYToGrid(int(p.X), int(p.Y)) == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// crushBricks 压碎首领身下的砖块（藏着门的砖块露出门，不掉落道具）
+func (g *Game) crushBricks() []TileChange {
+	var changes []TileChange
+	for _, cell := range g.Boss.Cells() {
+		if g.Map.GetTile(cell.GridX, cell.GridY) != TileBrick {
+			continue
+		}
+		newTile := TileEmpty
+		if cell.GridX == g.Map.HiddenDoorPos.X && cell.GridY == g.Map.HiddenDoorPos.Y {
+			newTile = TileDoor
+		}
+		g.Map.SetTile(cell.GridX, cell.GridY, newTile)
+		changes = append(changes, TileChange{GridX: cell.GridX, GridY: cell.GridY, OldType: TileBrick, NewType: newTile})
+	}
+	return changes
+}
+
+// MoveBoss 首领向 dir 移动一格（见 bossFits），蓄力期间不能移动；
+// 返回压碎砖块产生的地图变化和是否移动成功
+func (g *Game) MoveBoss(dir DirectionType) ([]TileChange, bool) {
+	b := g.Boss
+	if b == nil || b.Defeated() || b.Attack != BossAttackNone {
+		return nil, false
+	}
+	off := DirectionOffset(dir)
+	b.Direction = dir
+	if !g.bossFits(b.GridX+off.GridX, b.GridY+off.GridY) {
+		return nil, false
+	}
+	b.GridX += off.GridX
+	b.GridY += off.GridY
+	return g.crushBricks(), true
+}
+
+// ChargeBossAttack 首领朝 dir 蓄力，BossWarnFrames 帧后放出攻击（已在蓄力时返回 false）
+func (g *Game) ChargeBossAttack(attack BossAttack, dir DirectionType) bool {
+	b := g.Boss
+	if b == nil || b.Defeated() || b.Attack != BossAttackNone || attack == BossAttackNone {
+		return false
+	}
+	b.Direction = dir
+	b.Attack = attack
+	b.AttackFrame = g.CurrentFrame + BossWarnFrames
+	return true
+}
+
+// BossFireCells 朝 dir 喷火覆盖的格子：从首领这一侧起两格宽、最长 BossFireLength 格，每一列（行）遇墙或砖块停止
+func (g *Game) BossFireCells(dir DirectionType) []GridPos {
+	b := g.Boss
+	if b == nil {
+		return nil
+	}
+	off := DirectionOffset(dir)
+	var cells []GridPos
+	for lane := 0; lane < BossSize; lane++ {
+		// 喷火一侧的边缘格子
+		start := GridPos{GridX: b.GridX + lane, GridY: b.GridY + lane}
+		switch dir {
+		case DirUp:
+			start.GridY = b.GridY
+		case DirDown:
+			start.GridY = b.GridY + BossSize - 1
+		case DirLeft:
+			start.GridX = b.GridX
+		case DirRight:
+			start.GridX = b.GridX + BossSize - 1
+		}
+		for i := 1; i <= BossFireLength; i++ {
+			cell := GridPos{GridX: start.GridX + off.GridX*i, GridY: start.GridY + off.GridY*i}
+			if tile := g.Map.GetTile(cell.GridX, cell.GridY); tile == TileWall || tile == TileBrick {
+				break
+			}
+			cells = append(cells, cell)
+		}
+	}
+	return cells
+}
+
+// bossBombCells 炸弹攻击放炸弹的格子：阶段 1 只在朝向一侧，阶段 2 加上背后，阶段 3 四面都放，
+// 每一侧取紧挨首领的第一个空格（不放在玩家脚下）
+func (g *Game) bossBombCells() []GridPos {
+	b := g.Boss
+	count := []int{1, 2, 4}[b.Stage()-1]
+	var cells []GridPos
+	for _, side := range bossSides(b.Direction)[:count] {
+		off := DirectionOffset(side)
+		dx, dy := off.GridX, off.GridY
+		for lane := 0; lane < BossSize; lane++ {
+			cell := GridPos{GridX: b.GridX + lane*absInt(dy), GridY: b.GridY + lane*absInt(dx)}
+			if dx > 0 {
+				cell.GridX = b.GridX + BossSize
+			} else if dx < 0 {
+				cell.GridX = b.GridX - 1
+			}
+			if dy > 0 {
+				cell.GridY = b.GridY + BossSize
+			} else if dy < 0 {
+				cell.GridY = b.GridY - 1
+			}
+			if g.Map.GetTile(cell.GridX, cell.GridY) == TileEmpty && !g.bombAt(cell) && !g.playerAt(cell) {
+				cells = append(cells, cell)
+				break
+			}
+		}
+	}
+	return cells
+}
+
+// bossSides 朝向、背后、两侧（放炸弹的顺序）
+func bossSides(dir DirectionType) []DirectionType {
+	if dir == DirUp || dir == DirDown {
+		return []DirectionType{dir, DirUp + DirDown - dir, DirLeft, DirRight}
+	}
+	return []DirectionType{dir, DirLeft + DirRight - dir, DirUp, DirDown}
+}
+
+// updateBoss 首领受伤、撞人和放出蓄力完成的攻击（只在权威模式下运行）
+func (g *Game) updateBoss() {
+	b := g.Boss
+	if b == nil || !g.IsAuthoritative || b.Defeated() {
+		return
+	}
+
+	// 1. 受伤：玩家的爆炸碰到首领扣一点血，之后短暂无敌
+	for _, exp := range g.Explosions {
+		if exp.CreditID == KillerBoss || g.CurrentFrame < b.HurtUntilFrame {
+			continue
+		}
+		for _, cell := range exp.Cells {
+			if b.Covers(cell) {
+				b.HP--
+				b.HurtUntilFrame = g.CurrentFrame + BossHurtFrames
+				break
+			}
+		}
+	}
+	if b.Defeated() {
+		b.Attack = BossAttackNone
+		return
+	}
+
+	// 2. 走进首领的玩家死亡
+	for _, p := range g.Players {
+		if !p.Dead && b.Covers(PlayerXYToGrid(int(p.X), int(p.Y))) {
+			p.Dead = true
+			p.KillerID = KillerBoss
+		}
+	}
+
+	// 3. 蓄力结束，放出攻击
+	if b.Attack != BossAttackNone && g.CurrentFrame >= b.AttackFrame {
+		g.releaseBossAttack()
+	}
+}
+
+// releaseBossAttack 放出蓄力完成的攻击
+func (g *Game) releaseBossAttack() {
+	b := g.Boss
+	switch b.Attack {
+	case BossAttackBombs:
+		for _, cell := range g.bossBombCells() {
+			bomb := NewBomb(cell.GridX, cell.GridY, KillerBoss, g.CurrentFrame)
+			bomb.ExplodeAtFrame = g.CurrentFrame + g.Config.BombFuseFrames
+			bomb.ExplosionRange = b.Stage()
+			g.AddBomb(bomb)
+		}
+	case BossAttackFire:
+		if cells := g.BossFireCells(b.Direction); len(cells) > 0 {
+			fire := &Explosion{
+				GridX:          b.GridX,
+				GridY:          b.GridY,
+				CreatedAtFrame: g.CurrentFrame,
+				ExpiresAtFrame: g.CurrentFrame + BossFireFrames,
+				Cells:          cells,
+				OwnerID:        KillerBoss,
+				CreditID:       KillerBoss,
+			}
+			g.AddExplosion(fire)
+			g.checkDamage(fire)
+		}
+	}
+	b.Attack = BossAttackNone
+	b.AttackFrame = 0
+}
+
+// isBossGameOver 首领战的结束条件：首领被击败（玩家获胜）或所有玩家死亡
+func (g *Game) isBossGameOver() bool {
+	return g.Boss.Defeated() || len(g.GetAlivePlayers()) == 0
+}
@@ -1,5 +1,7 @@
 package core
 
+import "bomberman/pkg/resources"
+
 //go:generate stringer -linecomment -type=CharacterType
 
 // CharacterType 角色类型
@@ -12,17 +14,25 @@ const (
 	CharacterBlue                       // 蓝色炸弹人
 )
 
-// String 返回角色类型的字符串表示
-func (c CharacterType) String() string {
+// ID 角色资源 ID（协议中下发，客户端用它查找本地语言的名称，见 pkg/resources）
+func (c CharacterType) ID() string {
 	switch c {
 	case CharacterWhite:
-		return "经典白"
+		return resources.CharacterWhite
 	case CharacterBlack:
-		return "暗夜黑"
+		return resources.CharacterBlack
 	case CharacterRed:
-		return "烈焰红"
+		return resources.CharacterRed
 	case CharacterBlue:
-		return "冰霜蓝"
+		return resources.CharacterBlue
+	}
+	return ""
+}
+
+// String 返回角色类型的中文名称
+func (c CharacterType) String() string {
+	if id := c.ID(); id != "" {
+		return resources.Character(id, resources.LangZH).Name
 	}
 	return "未知"
 }
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"sort"
+)
+
+const (
+	ChecksumIntervalFrames  = 30  // 服务器每隔多少帧在状态中附带一次校验和
+	SyncCheckIntervalFrames = 300 // 开启帧同步校验时，服务器每隔多少帧单独广播一次校验和（SyncCheckEvent）
+)
+
+// StateChecksum 对局状态的分段校验和：地图、炸弹、玩家分开计算，不一致时能看出是哪一部分出了问题。
+// 只覆盖双方都应当完全一致的离散状态（格子、炸弹、玩家所在格与属性），
+// 不包括插值/预测会影响的精确坐标和纯表现用的数据
+type StateChecksum struct {
+	Map     uint32
+	Bombs   uint32
+	Players uint32
+}
+
+// Checksum 计算当前状态的校验和
+func (g *Game) Checksum() StateChecksum {
+	return ChecksumOf(g.Map, g.Bombs, g.Players)
+}
+
+// ChecksumOf 按给定的地图、炸弹和玩家计算校验和（与列表顺序无关）
+func ChecksumOf(m *GameMap, bombs []*Bomb, players []*Player) StateChecksum {
+	return StateChecksum{
+		Map:     mapChecksum(m),
+		Bombs:   bombsChecksum(bombs),
+		Players: playersChecksum(players),
+	}
+}
+
+// Diff 不一致的部分（"map"、"bombs"、"players"），一致时返回 nil
+func (c StateChecksum) Diff(other StateChecksum) []string {
+	var parts []string
+	if c.Map != other.Map {
+		parts = append(parts, "map")
+	}
+	if c.Bombs != other.Bombs {
+		parts = append(parts, "bombs")
+	}
+	if c.Players != other.Players {
+		parts = append(parts, "players")
+	}
+	return parts
+}
+
+// checksumWriter 把整数依次写入 FNV-1a
+type checksumWriter struct {
+	buf [8]byte
+	sum hash.Hash32
+}
+
+func newChecksumWriter() *checksumWriter {
+	return &checksumWriter{sum: fnv.New32a()}
+}
+
+func (w *checksumWriter) int(v int) {
+	binary.LittleEndian.PutUint64(w.buf[:], uint64(int64(v)))
+	_, _ = w.sum.Write(w.buf[:])
+}
+
+func (w *checksumWriter) bool(v bool) {
+	if v {
+		w.int(1)
+	} else {
+		w.int(0)
+	}
+}
+
+func mapChecksum(m *GameMap) uint32 {
+	w := newChecksumWriter()
+	if m == nil {
+		return w.sum.Sum32()
+	}
+	w.int(m.Width)
+	w.int(m.Height)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			w.int(int(m.GetTile(x, y)))
+		}
+	}
+	return w.sum.Sum32()
+}
+
+// bombsChecksum 炸弹按格子和放置者排序后计算（服务器与客户端的列表顺序可能不同）
+func bombsChecksum(bombs []*Bomb) uint32 {
+	sorted := make([]*Bomb, 0, len(bombs))
+	for _, b := range bombs {
+		if b != nil && !b.Exploded {
+			sorted = append(sorted, b)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.GridY != b.GridY {
+			return a.GridY < b.GridY
+		}
+		if a.GridX != b.GridX {
+			return a.GridX < b.GridX
+		}
+		if a.OwnerID != b.OwnerID {
+			return a.OwnerID < b.OwnerID
+		}
+		return a.PlacedAtFrame < b.PlacedAtFrame
+	})
+
+	w := newChecksumWriter()
+	w.int(len(sorted))
+	for _, b := range sorted {
+		w.int(b.GridX)
+		w.int(b.GridY)
+		w.int(b.OwnerID)
+		w.int(b.ExplosionRange)
+		w.int(int(b.PlacedAtFrame))
+		w.int(int(b.ExplodeAtFrame))
+	}
+	return w.sum.Sum32()
+}
+
+// playersChecksum 玩家按 ID 排序后计算，位置只取所在格子
+func playersChecksum(players []*Player) uint32 {
+	sorted := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if p != nil {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	w := newChecksumWriter()
+	w.int(len(sorted))
+	for _, p := range sorted {
+		cell := PlayerXYToGrid(int(p.X), int(p.Y))
+		w.int(p.ID)
+		w.bool(p.Dead)
+		w.int(cell.GridX)
+		w.int(cell.GridY)
+		w.int(p.MaxBombs)
+		w.int(p.BombRange)
+	}
+	return w.sum.Sum32()
+}
@@ -19,6 +19,15 @@ const (
 	MapHeight    = ScreenHeight / TileSize // 15
 )
 
+// ===== 大地图（5-8 人房间，map_large.go）=====
+const (
+	StandardMapPlayers = 4  // 标准尺寸地图的人数（四个角落），房间人数更多时换成大地图
+	LargeMapWidth      = 26 // 大地图超出屏幕，客户端镜头跟随本地玩家滚动
+	LargeMapHeight     = 19
+	MaxMapWidth        = LargeMapWidth // 各种尺寸中最大的宽高（AI 等按此分配固定大小的数组）
+	MaxMapHeight       = LargeMapHeight
+)
+
 type DirectionType int
 
 // ===== 方向 =====
@@ -45,11 +54,27 @@ const (
 	BombFuseFrames           = 180 // 炸弹引爆时间：3秒 × 60 = 180帧
 	BombExplosionFrames      = 30  // 爆炸持续时间：0.5秒 × 60 = 30帧
 	BombPlacementDelayFrames = 12  // 炸弹放置防抖：0.2秒 × 60 = 12帧
+	BombInputBufferFrames    = 6   // 放弹按键缓冲：按下时放不了，6 帧内条件满足仍会放出（需小于放置防抖）
 	BombExplosionRange       = 2   // 默认爆炸范围：2格
 	BombMaxCountDefault      = 2   // 默认可同时放置炸弹数
 
 	// 玩家相关
-	PlayerSpeedPerFrame = 2.0 // 像素/帧 = 120像素/秒 ÷ 60
+	PlayerSpeedPerFrame = 2.0     // 像素/帧 = 120像素/秒 ÷ 60
+	ShoveCooldownFrames = 1 * TPS // 推人冷却：1秒
+
+	// 冲刺与体力（sprint.go）
+	SprintSpeedMultiplier = 1.5            // 冲刺时的速度倍率
+	StaminaMax            = 240            // 体力上限
+	SprintStaminaCost     = 2              // 冲刺每帧消耗的体力：满体力可冲刺 2 秒
+	StaminaRegenPerFrame  = 1              // 不冲刺时每帧恢复的体力：耗尽后 4 秒回满
+	SprintMinStamina      = StaminaMax / 4 // 重新开始冲刺所需的最低体力（避免耗尽后一顿一顿地冲刺）
+
+	// 道具相关
+	ItemDropPercent = 30                        // 砖块被炸毁时掉落道具的概率（%）
+	ItemMaxBombs    = 8                         // 炸弹数道具的上限
+	ItemMaxRange    = 8                         // 范围道具的上限（格）
+	ItemSpeedStep   = 0.4                       // 每个速度道具增加的速度（像素/帧）
+	ItemMaxSpeed    = PlayerSpeedPerFrame * 1.6 // 速度道具的上限
 
 	// AI 相关
 	AIThinkIntervalFrames       = 6  // 100ms × 60 ≈ 6帧
@@ -60,6 +85,29 @@ const (
 	GameStartCountdownFrames = 180       // 开始倒计时：3秒
 	GameOverDelayFrames      = 300       // 结束延时：5秒
 	MatchDurationFrames      = 120 * TPS // 对局时长：2分钟（<=0 关闭限时）
+
+	// 门口蹲守提示（GameRules.DoorCampPing）
+	DoorCampPingDelayFrames    = 3 * TPS // 站在门上 3 秒后首次暴露位置
+	DoorCampPingIntervalFrames = 2 * TPS // 之后每 2 秒重复一次
+
+	// 突然死亡（GameRules.SuddenDeath）
+	SuddenDeathFrames         = 30 * TPS // 限时结束前 30 秒开始落墙
+	SuddenDeathIntervalFrames = 6        // 每 6 帧落下一格（整张地图恰好 30 秒落满）
+	SuddenDeathWarningFrames  = 3 * TPS  // 提前 3 秒预警
+
+	// 门口决斗加时（GameRules.DoorOvertime）
+	OvertimeContestFrames   = 2 * TPS // 最后两人同时在门口竞技场内 2 秒后触发
+	OvertimeCountdownFrames = 5 * TPS // 倒计时 5 秒后竞技场外被淹没
+	OvertimeArenaRadius     = 2       // 竞技场：以门为中心 5x5 格
+
+	// 首领战（GameRules.BossMode）
+	BossSize       = 2                   // 首领占 2x2 格
+	BossStages     = 3                   // 血量每降低三分之一进入下一阶段
+	BossMaxHP      = 2 * BossStages      // 每个阶段挨 2 次爆炸
+	BossHurtFrames = BombExplosionFrames // 受伤后的无敌帧数（同一次爆炸只扣一次血）
+	BossFireFrames = BombExplosionFrames // 喷火持续帧数
+	BossFireLength = 6                   // 喷火距离（格）
+	BossWarnFrames = TPS                 // 攻击前的预警帧数（客户端据此闪烁提示）
 )
 
 // ===== 玩家碰撞配置 =====
@@ -0,0 +1,95 @@
+package core
+
+// KillerOvertime 决斗加时阶段留在竞技场外被淹没时的击杀归属
+const KillerOvertime = -4
+
+// 决斗加时（GameRules.DoorOvertime）：门已露出、只剩两名存活玩家且两人都守在门附近时，
+// 胜利条件（最后一人进门）谁也无法达成。持续 OvertimeContestFrames 帧后触发倒计时，
+// 倒计时结束后以门为中心的竞技场外全部被淹没，踏出竞技场即死，逼两人决出胜负
+
+// OvertimeTriggered 是否已触发决斗加时（包括倒计时阶段）
+func (g *Game) OvertimeTriggered() bool {
+	return g.Rules.DoorOvertime && g.OvertimeFloodFrame > 0
+}
+
+// OvertimeFlooding 竞技场外是否已经被淹没
+func (g *Game) OvertimeFlooding() bool {
+	return g.OvertimeTriggered() && g.CurrentFrame >= g.OvertimeFloodFrame
+}
+
+// InOvertimeArena 格子是否在门口竞技场内（以门为中心、半径 OvertimeArenaRadius 的方形）
+func (g *Game) InOvertimeArena(pos GridPos) bool {
+	door := g.Map.HiddenDoorPos
+	return absInt(pos.GridX-door.X) <= OvertimeArenaRadius && absInt(pos.GridY-door.Y) <= OvertimeArenaRadius
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// OvertimeFloodCells 决斗加时会被淹没的非墙格子（未触发时为 nil）
+func (g *Game) OvertimeFloodCells() []GridPos {
+	if !g.OvertimeTriggered() {
+		return nil
+	}
+	var cells []GridPos
+	for y := 0; y < g.Map.Height; y++ {
+		for x := 0; x < g.Map.Width; x++ {
+			pos := GridPos{GridX: x, GridY: y}
+			if g.Map.GetTile(x, y) != TileWall && !g.InOvertimeArena(pos) {
+				cells = append(cells, pos)
+			}
+		}
+	}
+	return cells
+}
+
+// updateOvertime 统计门口僵持的帧数并触发倒计时；淹没后杀死竞技场外的玩家
+func (g *Game) updateOvertime() {
+	if !g.Rules.DoorOvertime || g.Rules.Teams || !g.IsAuthoritative {
+		return
+	}
+
+	if g.OvertimeTriggered() {
+		if !g.OvertimeFlooding() {
+			return
+		}
+		for _, player := range g.Players {
+			if !player.Dead && !g.InOvertimeArena(PlayerXYToGrid(int(player.X), int(player.Y))) {
+				player.Dead = true
+				player.KillerID = KillerOvertime
+			}
+		}
+		return
+	}
+
+	if !g.doorContested() {
+		g.overtimeContestFrames = 0
+		return
+	}
+	g.overtimeContestFrames++
+	if g.overtimeContestFrames >= OvertimeContestFrames {
+		g.OvertimeFloodFrame = g.CurrentFrame + OvertimeCountdownFrames
+	}
+}
+
+// doorContested 门已露出，且恰好两名存活玩家都在竞技场内
+func (g *Game) doorContested() bool {
+	door := g.Map.HiddenDoorPos
+	if g.Map.GetTile(door.X, door.Y) != TileDoor {
+		return false
+	}
+	alive := g.GetAlivePlayers()
+	if len(alive) != 2 {
+		return false
+	}
+	for _, p := range alive {
+		if !g.InOvertimeArena(PlayerXYToGrid(int(p.X), int(p.Y))) {
+			return false
+		}
+	}
+	return true
+}
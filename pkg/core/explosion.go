@@ -2,6 +2,7 @@ package core
 
 // Explosion 爆炸效果
 type Explosion struct {
+	ID             int32     // 对局内唯一的实体 ID（Game.AddExplosion 分配）
 	GridX          int       // 中心格子X
 	GridY          int       // 中心格子Y
 	Range          int       // 爆炸范围
@@ -9,6 +10,7 @@ type Explosion struct {
 	CreatedAtFrame int32     // 创建帧号
 	Cells          []GridPos // 影响的格子
 	OwnerID        int       // 来源炸弹的所有者
+	CreditID       int       // 击杀归属（来源炸弹最后的接触者）
 	// 地图变化（用于客户端同步）
 	TileChanges []TileChange // 爆炸导致的地图变化
 }
@@ -30,6 +32,7 @@ func NewExplosion(bomb *Bomb, currentFrame int32) *Explosion {
 		CreatedAtFrame: currentFrame,
 		Cells:          []GridPos{},
 		OwnerID:        bomb.OwnerID,
+		CreditID:       bomb.CreditID(),
 	}
 }
 
@@ -63,7 +66,7 @@ func (e *Explosion) CalculateExplosionCells(gameMap *GameMap) []GridPos {
 			ny := e.GridY + dir.dy*i
 
 			// 检查边界
-			if nx < 0 || nx >= MapWidth || ny < 0 || ny >= MapHeight {
+			if !gameMap.InBounds(nx, ny) {
 				break
 			}
 
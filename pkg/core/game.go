@@ -3,12 +3,30 @@ package core
 // Game 游戏状态（纯逻辑，不包含渲染）
 type Game struct {
 	Map             *GameMap
-	Players         []*Player
+	Players         []*Player // 按玩家 ID 升序，每帧按此顺序处理（保证确定性）
 	Bombs           []*Bomb
 	Explosions      []*Explosion
-	IsAuthoritative bool  // 是否由于权威逻辑（控制爆炸、伤害判定等）
-	CurrentFrame    int32 // 当前帧号
-	Seed            int64 // 随机种子（用于确定性）
+	Items           []*Item // 地图上尚未拾取的道具
+	IsAuthoritative bool    // 是否由于权威逻辑（控制爆炸、伤害判定等）
+	CurrentFrame    int32   // 当前帧号
+	Seed            int64   // 随机种子（用于确定性）
+
+	Rules         GameRules      // 房间可选规则
+	Config        MatchConfig    // 对局参数（引信、速度、范围、时长）
+	DoorCampPings []DoorCampPing // 本帧产生的门口蹲守提示（每帧重置）
+
+	MatchEndFrame         int32        // 限时结束的帧号（0 表示不限时，由服务器开局时设置）
+	SuddenDeathStartFrame int32        // 突然死亡开始落墙的帧号（GameRules.SuddenDeath，0 表示不开启）
+	SuddenDeathChanges    []TileChange // 本帧落墙产生的地图变化（每帧重置）
+
+	OvertimeFloodFrame    int32 // 决斗加时淹没竞技场外格子的帧号（GameRules.DoorOvertime，0 表示未触发）
+	overtimeContestFrames int32 // 两名存活玩家连续守在门口的帧数
+
+	Boss *Boss // 首领（GameRules.BossMode，服务器开局时放出；nil 表示没有）
+
+	KillLog []KillRecord // 本局的阵亡记录（按发生顺序，kills.go）
+
+	lastEntityID int32 // 最近分配的炸弹/爆炸 ID（不随实体消失复用，下标变化不影响 ID）
 }
 
 // NewGame 创建新游戏
@@ -18,27 +36,53 @@ func NewGame(seed int64) *Game {
 		Players:         make([]*Player, 0),
 		Bombs:           make([]*Bomb, 0),
 		Explosions:      make([]*Explosion, 0),
+		Items:           make([]*Item, 0),
+		Rules:           DefaultGameRules(),
+		Config:          DefaultMatchConfig(),
 		IsAuthoritative: true, // 默认开启权威逻辑（单机模式）
 		CurrentFrame:    0,
 		Seed:            seed,
 	}
 }
 
-// AddPlayer 添加玩家
+// AddPlayer 添加玩家（按 ID 插入到有序位置，与加入顺序无关）
 func (g *Game) AddPlayer(player *Player) {
-	g.Players = append(g.Players, player)
+	i := len(g.Players)
+	for i > 0 && g.Players[i-1].ID > player.ID {
+		i--
+	}
+	g.Players = append(g.Players, nil)
+	copy(g.Players[i+1:], g.Players[i:])
+	g.Players[i] = player
 }
 
-// AddBomb 添加炸弹
+// AddBomb 添加炸弹（没有 ID 时分配新 ID；客户端从服务器同步的炸弹沿用服务器的 ID）
 func (g *Game) AddBomb(bomb *Bomb) {
+	if bomb.ID == 0 {
+		bomb.ID = g.nextEntityID()
+	}
 	g.Bombs = append(g.Bombs, bomb)
 }
 
+// AddExplosion 添加爆炸（没有 ID 时分配新 ID）
+func (g *Game) AddExplosion(explosion *Explosion) {
+	if explosion.ID == 0 {
+		explosion.ID = g.nextEntityID()
+	}
+	g.Explosions = append(g.Explosions, explosion)
+}
+
+// nextEntityID 分配炸弹和爆炸的 ID（从 1 开始，0 表示未分配）
+func (g *Game) nextEntityID() int32 {
+	g.lastEntityID++
+	return g.lastEntityID
+}
+
 // Update 每帧更新游戏状态（不再需要 deltaTime）
 func (g *Game) Update() {
 	g.CurrentFrame++
 
-	// 1. 更新玩家
+	// 1. 更新玩家（按 ID 升序）
 	for _, player := range g.Players {
 		player.Update(g)
 	}
@@ -48,6 +92,27 @@ func (g *Game) Update() {
 
 	// 3. 更新爆炸
 	g.updateExplosions()
+
+	// 4. 拾取道具
+	g.updateItems()
+
+	// 5. 地图危险区域
+	g.updateHazards()
+
+	// 6. 突然死亡落墙
+	g.updateSuddenDeath()
+
+	// 7. 门口蹲守提示
+	g.updateDoorCamping()
+
+	// 8. 门口决斗加时
+	g.updateOvertime()
+
+	// 9. 首领战
+	g.updateBoss()
+
+	// 10. 记录本帧的阵亡与击杀
+	g.recordKills()
 }
 
 // updateBombs 更新所有炸弹
@@ -57,6 +122,7 @@ func (g *Game) updateBombs() {
 	explodingBombs := make([]*Bomb, 0)
 
 	for _, bomb := range g.Bombs {
+		bomb.StepSlide(g)
 		if bomb.Update(g.CurrentFrame) {
 			if g.IsAuthoritative {
 				explodingBombs = append(explodingBombs, bomb)
@@ -102,7 +168,10 @@ func (g *Game) explodeBomb(bomb *Bomb) {
 	copy(explosion.Cells, cells)
 	// 预分配地图变化数组
 	explosion.TileChanges = make([]TileChange, 0, len(cells))
-	g.Explosions = append(g.Explosions, explosion)
+	g.AddExplosion(explosion)
+
+	// 炸毁地上的道具（本次炸出的道具不受影响）
+	g.destroyItems(cells)
 
 	// 炸毁砖块，记录变化
 	for _, cell := range cells {
@@ -127,6 +196,10 @@ func (g *Game) explodeBomb(bomb *Bomb) {
 
 			// 应用变化
 			g.Map.SetTile(cell.GridX, cell.GridY, newTile)
+
+			if newTile == TileEmpty {
+				g.dropItem(cell.GridX, cell.GridY)
+			}
 		}
 	}
 
@@ -169,7 +242,7 @@ func (g *Game) updateExplosions() {
 // checkDamage 检查玩家伤害
 func (g *Game) checkDamage(explosion *Explosion) {
 	for _, player := range g.Players {
-		if player.Dead {
+		if player.Dead || g.protectedFromFriendlyFire(player, explosion) {
 			continue
 		}
 
@@ -182,6 +255,7 @@ func (g *Game) checkDamage(explosion *Explosion) {
 		for _, cell := range explosion.Cells {
 			if cell.GridX == gridX && cell.GridY == gridY {
 				player.Dead = true
+				player.KillerID = explosion.CreditID
 				break
 			}
 		}
@@ -198,6 +272,17 @@ func (g *Game) GetPlayer(id int) *Player {
 	return nil
 }
 
+// ActiveBombCount 玩家当前在场（未爆炸）的炸弹数
+func (g *Game) ActiveBombCount(ownerID int) int {
+	count := 0
+	for _, bomb := range g.Bombs {
+		if bomb.OwnerID == ownerID && !bomb.Exploded {
+			count++
+		}
+	}
+	return count
+}
+
 // GetAlivePlayers 获取存活玩家
 func (g *Game) GetAlivePlayers() []*Player {
 	alive := make([]*Player, 0)
@@ -214,6 +299,12 @@ func (g *Game) IsGameOver() bool {
 	if len(g.Players) == 0 {
 		return false
 	}
+	if g.Rules.BossMode && g.Boss != nil {
+		return g.isBossGameOver()
+	}
+	if g.Rules.Teams {
+		return g.isTeamGameOver()
+	}
 
 	aliveCount := 0
 	var survivor *Player
@@ -0,0 +1,138 @@
+package core
+
+// KillerHazard 被地图危险区域杀死时的击杀归属
+const KillerHazard = -2
+
+// HazardKind 危险区域形状
+type HazardKind int
+
+const (
+	HazardRow    HazardKind = iota // 整行
+	HazardColumn                   // 整列
+)
+
+// HazardPhase 危险区域当前阶段
+type HazardPhase int
+
+const (
+	HazardIdle    HazardPhase = iota // 安全
+	HazardWarning                    // 预警（即将生效）
+	HazardActive                     // 生效中，踏入即死
+)
+
+// Hazard 地图定义的周期性危险区域（如熔岩行）
+// 每 PeriodFrames 帧生效一次，持续 ActiveFrames 帧，生效前 WarningFrames 帧开始预警；
+// OffsetFrames 为第一次生效的帧号
+type Hazard struct {
+	Kind          HazardKind
+	Index         int // 行号或列号
+	PeriodFrames  int32
+	ActiveFrames  int32
+	WarningFrames int32
+	OffsetFrames  int32
+}
+
+// HazardOverlay 危险区域的格子覆盖状态（只包含预警和生效中的区域，用于同步和渲染）
+type HazardOverlay struct {
+	Cells    []GridPos
+	Active   bool
+	EndFrame int32 // 当前阶段结束的帧号
+}
+
+// defaultMapHazards 默认的危险区域：地图中间一行和中间一列交替变成熔岩
+func defaultMapHazards(width, height int) []Hazard {
+	return []Hazard{
+		{Kind: HazardRow, Index: height / 2, PeriodFrames: 10 * TPS, ActiveFrames: TPS, WarningFrames: 2 * TPS, OffsetFrames: 10 * TPS},
+		{Kind: HazardColumn, Index: width / 2, PeriodFrames: 10 * TPS, ActiveFrames: TPS, WarningFrames: 2 * TPS, OffsetFrames: 15 * TPS},
+	}
+}
+
+// PhaseAt 指定帧的阶段以及该阶段结束的帧号（Idle 时为下次预警开始的帧号）
+func (h Hazard) PhaseAt(frame int32) (HazardPhase, int32) {
+	if h.PeriodFrames <= 0 || h.ActiveFrames <= 0 {
+		return HazardIdle, 0
+	}
+
+	// 下一次（或当前）生效的起始帧
+	start := h.OffsetFrames
+	if frame >= start {
+		start += (frame - start) / h.PeriodFrames * h.PeriodFrames
+		if frame >= start+h.ActiveFrames {
+			start += h.PeriodFrames
+		}
+	}
+
+	switch {
+	case frame >= start:
+		return HazardActive, start + h.ActiveFrames
+	case frame >= start-h.WarningFrames:
+		return HazardWarning, start
+	default:
+		return HazardIdle, start - h.WarningFrames
+	}
+}
+
+// Cells 危险区域覆盖的非墙格子
+func (h Hazard) Cells(m *GameMap) []GridPos {
+	var cells []GridPos
+	switch h.Kind {
+	case HazardRow:
+		for x := 0; x < m.Width; x++ {
+			if m.GetTile(x, h.Index) != TileWall {
+				cells = append(cells, GridPos{GridX: x, GridY: h.Index})
+			}
+		}
+	case HazardColumn:
+		for y := 0; y < m.Height; y++ {
+			if m.GetTile(h.Index, y) != TileWall {
+				cells = append(cells, GridPos{GridX: h.Index, GridY: y})
+			}
+		}
+	}
+	return cells
+}
+
+// HazardOverlays 当前帧处于预警或生效中的危险区域（规则关闭时为空）
+func (g *Game) HazardOverlays() []HazardOverlay {
+	if !g.Rules.MapHazards {
+		return nil
+	}
+	var overlays []HazardOverlay
+	for _, h := range g.Map.Hazards {
+		phase, end := h.PhaseAt(g.CurrentFrame)
+		if phase == HazardIdle {
+			continue
+		}
+		overlays = append(overlays, HazardOverlay{
+			Cells:    h.Cells(g.Map),
+			Active:   phase == HazardActive,
+			EndFrame: end,
+		})
+	}
+	return overlays
+}
+
+// updateHazards 杀死站在生效中危险区域上的玩家
+func (g *Game) updateHazards() {
+	if !g.Rules.MapHazards || !g.IsAuthoritative {
+		return
+	}
+	for _, overlay := range g.HazardOverlays() {
+		if !overlay.Active {
+			continue
+		}
+		for _, player := range g.Players {
+			if player.Dead {
+				continue
+			}
+			pos := PlayerXYToGrid(int(player.X), int(player.Y))
+			for _, cell := range overlay.Cells {
+				if cell == pos {
+					player.Dead = true
+					player.KillerID = KillerHazard
+					break
+				}
+			}
+		}
+	}
+}
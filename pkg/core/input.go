@@ -2,11 +2,13 @@ package core
 
 // Input 表示一帧内玩家的输入
 type Input struct {
-	Up    bool
-	Down  bool
-	Left  bool
-	Right bool
-	Bomb  bool
+	Up     bool
+	Down   bool
+	Left   bool
+	Right  bool
+	Bomb   bool
+	Shove  bool // 推开朝向上相邻的对手（需开启玩家碰撞）
+	Sprint bool // 按住冲刺（消耗体力）
 }
 
 // ApplyInput 将输入应用到指定玩家
@@ -21,8 +23,12 @@ func ApplyInput(game *Game, playerID int, input Input, currentFrame int32) bool
 		return false
 	}
 
-	// 速度已经是像素/帧，直接使用
+	// 速度已经是像素/帧，直接使用；冲刺时加速（只有移动时才消耗体力）
 	speed := player.Speed
+	moving := input.Up || input.Down || input.Left || input.Right
+	if player.UpdateSprint(input.Sprint && moving) {
+		speed *= SprintSpeedMultiplier
+	}
 	moveX := 0.0
 	moveY := 0.0
 
@@ -53,13 +59,24 @@ func ApplyInput(game *Game, playerID int, input Input, currentFrame int32) bool
 		player.Move(moveX, 0, game)
 	}
 
-	// 处理炸弹
-	if input.Bomb {
+	// 推人（只移动对手，不影响返回值）
+	if input.Shove {
+		player.Shove(game, currentFrame)
+	}
+
+	// 处理炸弹：按下的瞬间放不了（冷却中、炸弹数已满）时缓冲几帧，条件一满足就放出
+	pressed := input.Bomb && !player.BombHeld
+	player.BombHeld = input.Bomb
+	if input.Bomb || player.BombBuffered(currentFrame) {
 		bomb := player.PlaceBomb(game, currentFrame)
 		if bomb != nil {
+			player.BombBufferUntil = 0
 			game.AddBomb(bomb)
 			return true
 		}
+		if pressed {
+			player.BufferBomb(currentFrame)
+		}
 	}
 
 	return false
@@ -0,0 +1,121 @@
+package core
+
+// ItemType 道具类型
+type ItemType int
+
+const (
+	ItemBombUp  ItemType = iota // 炸弹数 +1
+	ItemFireUp                  // 爆炸范围 +1
+	ItemSpeedUp                 // 移动速度提升
+	ItemKick                    // 踢炸弹（bomb_kick.go）
+
+	itemTypeCount = 4
+)
+
+// Item 地图上的道具（纯逻辑）
+type Item struct {
+	GridX int
+	GridY int
+	Type  ItemType
+
+	SpawnedAtFrame int32 // 出现的帧号
+}
+
+// EffectType 玩家身上的增益/减益类型
+type EffectType int
+
+const (
+	EffectSpeedBoost EffectType = iota // 加速
+	EffectSlow                         // 减速
+	EffectShield                       // 护盾（抵挡一次爆炸）
+)
+
+// Effect 玩家当前生效的效果，按帧计时
+type Effect struct {
+	Type           EffectType
+	ExpiresAtFrame int32 // 失效帧号（<=0 表示永久，直到被消耗）
+}
+
+// RemainingFrames 剩余帧数（永久效果返回 -1）
+func (e Effect) RemainingFrames(currentFrame int32) int32 {
+	if e.ExpiresAtFrame <= 0 {
+		return -1
+	}
+	if remain := e.ExpiresAtFrame - currentFrame; remain > 0 {
+		return remain
+	}
+	return 0
+}
+
+// dropItem 砖块被炸毁后掉落创建地图时预先分配的道具（见 item_drops.go）
+func (g *Game) dropItem(x, y int) {
+	itemType, ok := g.Map.ItemDrops[GridPos{GridX: x, GridY: y}]
+	if !ok {
+		return
+	}
+	g.Items = append(g.Items, &Item{
+		GridX:          x,
+		GridY:          y,
+		Type:           itemType,
+		SpawnedAtFrame: g.CurrentFrame,
+	})
+}
+
+// destroyItems 移除爆炸格子上已有的道具
+func (g *Game) destroyItems(cells []GridPos) {
+	items := g.Items[:0]
+	for _, item := range g.Items {
+		hit := false
+		for _, cell := range cells {
+			if item.GridX == cell.GridX && item.GridY == cell.GridY {
+				hit = true
+				break
+			}
+		}
+		if !hit {
+			items = append(items, item)
+		}
+	}
+	g.Items = items
+}
+
+// updateItems 存活玩家走到道具所在格子时拾取
+// 非权威模式下道具列表和玩家属性以服务器同步为准
+func (g *Game) updateItems() {
+	if !g.IsAuthoritative || len(g.Items) == 0 {
+		return
+	}
+	items := g.Items[:0]
+	for _, item := range g.Items {
+		picked := false
+		for _, player := range g.Players {
+			if player.Dead {
+				continue
+			}
+			pos := PlayerXYToGrid(int(player.X), int(player.Y))
+			if pos.GridX == item.GridX && pos.GridY == item.GridY {
+				player.ApplyItem(item.Type)
+				picked = true
+				break
+			}
+		}
+		if !picked {
+			items = append(items, item)
+		}
+	}
+	g.Items = items
+}
+
+// ApplyItem 道具效果（都有上限）
+func (p *Player) ApplyItem(itemType ItemType) {
+	switch itemType {
+	case ItemBombUp:
+		p.SetMaxBombs(min(p.MaxBombs+1, ItemMaxBombs))
+	case ItemFireUp:
+		p.SetBombRange(min(p.BombRange+1, ItemMaxRange))
+	case ItemSpeedUp:
+		p.Speed = min(p.Speed+ItemSpeedStep, ItemMaxSpeed)
+	case ItemKick:
+		p.CanKick = true
+	}
+}
@@ -0,0 +1,135 @@
+package core
+
+import "fmt"
+
+// 道具掉落表：砖块被炸毁时是否掉落道具、掉落哪种道具。全局表（DefaultItemDropTable）可以被地图定义中的
+// item_drops 覆盖，创建地图时按对局种子为每块砖预先分配好道具（GameMap.ItemDrops），
+// 只由地图定义和种子决定，与炸毁顺序无关：回放、按种子生成地图的客户端和重连后都得到同一份结果
+//
+//	"item_drops": {
+//	  "percent": 40,                          // 掉落概率（%），省略时使用全局值
+//	  "weights": {"kick": 0, "fire_up": 2}    // 各道具的相对权重，省略的道具使用全局权重，0 表示不掉落
+//	}
+
+// itemNames 道具在地图定义中的名称（按 ItemType 顺序）
+var itemNames = [itemTypeCount]string{"bomb_up", "fire_up", "speed_up", "kick"}
+
+// ItemDropTable 道具掉落表
+type ItemDropTable struct {
+	Percent *int           `json:"percent,omitempty"` // 砖块掉落道具的概率（%）
+	Weights map[string]int `json:"weights,omitempty"` // 道具名 -> 相对权重
+}
+
+// DefaultItemDropTable 全局掉落表：ItemDropPercent 的概率掉落，各道具机会均等
+func DefaultItemDropTable() ItemDropTable {
+	percent := ItemDropPercent
+	weights := make(map[string]int, itemTypeCount)
+	for _, name := range itemNames {
+		weights[name] = 1
+	}
+	return ItemDropTable{Percent: &percent, Weights: weights}
+}
+
+// validate 校验地图中的掉落表覆盖
+func (t *ItemDropTable) validate() error {
+	if t.Percent != nil && (*t.Percent < 0 || *t.Percent > 100) {
+		return mapError(MapErrorItemDrops, MapCell{}, "道具掉落概率必须在 0-100 之间，实际 %d", *t.Percent)
+	}
+	for name, weight := range t.Weights {
+		if itemTypeByName(name) < 0 {
+			return mapError(MapErrorItemDrops, MapCell{}, "未知的道具 %q（只能是 bomb_up、fire_up、speed_up、kick）", name)
+		}
+		if weight < 0 {
+			return mapError(MapErrorItemDrops, MapCell{}, "道具 %s 的权重不能为负数", name)
+		}
+	}
+	if resolved := ResolveItemDropTable(t); resolved.totalWeight() == 0 && *resolved.Percent > 0 {
+		return mapError(MapErrorItemDrops, MapCell{}, "所有道具的权重都是 0，请把掉落概率设为 0")
+	}
+	return nil
+}
+
+// ResolveItemDropTable 用地图的覆盖（nil 表示没有）替换全局表中对应的字段
+func ResolveItemDropTable(override *ItemDropTable) ItemDropTable {
+	table := DefaultItemDropTable()
+	if override == nil {
+		return table
+	}
+	if override.Percent != nil {
+		percent := *override.Percent
+		table.Percent = &percent
+	}
+	for name, weight := range override.Weights {
+		table.Weights[name] = weight
+	}
+	return table
+}
+
+// String 掉落表的文本形式（日志和界面提示用）
+func (t ItemDropTable) String() string {
+	s := fmt.Sprintf("%d%%", *t.Percent)
+	for _, name := range itemNames {
+		s += fmt.Sprintf(" %s=%d", name, t.Weights[name])
+	}
+	return s
+}
+
+func (t ItemDropTable) totalWeight() int {
+	total := 0
+	for _, name := range itemNames {
+		total += t.Weights[name]
+	}
+	return total
+}
+
+// itemTypeByName 按名称查找道具类型（未知名称返回 -1）
+func itemTypeByName(name string) ItemType {
+	for i, n := range itemNames {
+		if n == name {
+			return ItemType(i)
+		}
+	}
+	return -1
+}
+
+// pick 按格子的哈希值决定是否掉落以及掉落哪种道具
+func (t ItemDropTable) pick(h uint64) (ItemType, bool) {
+	total := t.totalWeight()
+	if total == 0 || h%100 >= uint64(*t.Percent) {
+		return 0, false
+	}
+	roll := int(h / 100 % uint64(total))
+	for i, name := range itemNames {
+		if roll < t.Weights[name] {
+			return ItemType(i), true
+		}
+		roll -= t.Weights[name]
+	}
+	return 0, false
+}
+
+// assignItemDrops 为地图上的每块砖预先分配道具（藏门的砖块炸开后是门，不分配）
+func assignItemDrops(m *GameMap, table ItemDropTable, seed int64) {
+	m.ItemDrops = make(map[GridPos]ItemType)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Tiles[y][x] != TileBrick || (x == m.HiddenDoorPos.X && y == m.HiddenDoorPos.Y) {
+				continue
+			}
+			if itemType, ok := table.pick(cellHash(seed, x, y)); ok {
+				m.ItemDrops[GridPos{GridX: x, GridY: y}] = itemType
+			}
+		}
+	}
+}
+
+// cellHash 种子和坐标的哈希（splitmix 风格的混合），每个格子独立
+func cellHash(seed int64, x, y int) uint64 {
+	h := uint64(seed) ^ uint64(x)*0x9E3779B97F4A7C15 ^ uint64(y)*0xC2B2AE3D27D4EB4F
+	h ^= h >> 33
+	h *= 0xFF51AFD7ED558CCD
+	h ^= h >> 33
+	h *= 0xC4CEB9FE1A85EC53
+	h ^= h >> 33
+	return h
+}
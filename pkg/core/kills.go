@@ -0,0 +1,62 @@
+package core
+
+// KillRecord 一次阵亡：VictimID 在 Frame 帧被 KillerID 击杀
+// KillerID 与 Player.KillerID 相同：自杀时等于 VictimID，地图、落墙、加时和首领击杀为负数
+type KillRecord struct {
+	Frame    int32
+	VictimID int
+	KillerID int
+}
+
+// SelfKill 是否被自己的炸弹炸死
+func (r KillRecord) SelfKill() bool {
+	return r.KillerID == r.VictimID
+}
+
+// KillStats 玩家本局的击杀/阵亡统计
+type KillStats struct {
+	Kills     int // 击杀其他玩家的次数（不含自杀和队友）
+	Deaths    int
+	SelfKills int
+}
+
+// recordKills 把本帧新阵亡的玩家追加到 KillLog 并给击杀者计分（只在权威逻辑中执行，客户端的击杀数由服务器同步）
+func (g *Game) recordKills() {
+	if !g.IsAuthoritative {
+		return
+	}
+	for _, victim := range g.Players {
+		if !victim.Dead || victim.deathRecorded {
+			continue
+		}
+		victim.deathRecorded = true
+		record := KillRecord{Frame: g.CurrentFrame, VictimID: victim.ID, KillerID: victim.KillerID}
+		g.KillLog = append(g.KillLog, record)
+		if record.SelfKill() {
+			continue
+		}
+		killer := g.GetPlayer(record.KillerID)
+		if killer == nil || (g.Rules.Teams && killer.Team != TeamNone && killer.Team == victim.Team) {
+			continue
+		}
+		killer.Kills++
+	}
+}
+
+// KillStats 按 KillLog 统计玩家本局的击杀和阵亡
+func (g *Game) KillStats(playerID int) KillStats {
+	var stats KillStats
+	if p := g.GetPlayer(playerID); p != nil {
+		stats.Kills = p.Kills
+	}
+	for _, r := range g.KillLog {
+		if r.VictimID != playerID {
+			continue
+		}
+		stats.Deaths++
+		if r.SelfKill() {
+			stats.SelfKills++
+		}
+	}
+	return stats
+}
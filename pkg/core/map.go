@@ -1,15 +1,13 @@
 package core
 
-import (
-	"math/rand"
-)
-
 // GameMap 游戏地图（核心逻辑，不包含渲染）
 type GameMap struct {
 	Tiles         [][]TileType
 	Width         int
 	Height        int
-	HiddenDoorPos struct{ X, Y int } // 隐藏门的坐标
+	HiddenDoorPos struct{ X, Y int }   // 隐藏门的坐标
+	Hazards       []Hazard             // 周期性危险区域（由 GameRules.MapHazards 启用）
+	ItemDrops     map[GridPos]ItemType // 砖块下预先分配的道具（创建地图时由掉落表和种子决定）
 }
 
 // GridPos 格子坐标（通用类型）
@@ -17,76 +15,19 @@ type GridPos struct {
 	GridX, GridY int
 }
 
-// NewGameMapWithSeed 使用指定种子创建新地图（用于确定性）
+// NewGameMap 使用指定种子创建内置地图（用于确定性）
 func NewGameMap(seed int64) *GameMap {
-	m := &GameMap{
-		Tiles:  make([][]TileType, MapHeight),
-		Width:  MapWidth,
-		Height: MapHeight,
-	}
-
-	// 使用地图模板（带种子）
-	m.loadMapTemplateWithSeed(int64(seed))
-
-	return m
+	return NewGameMapFromDefinition(DefaultMapDefinition(), seed)
 }
 
-// loadMapTemplateWithSeed 加载地图模板（带种子，用于确定性）
-func (m *GameMap) loadMapTemplateWithSeed(seed int64) {
-	// 地图模板：W=墙壁, B=砖块, .=空地
-	template := []string{
-		"..B.B.W.W.W.W.B.B...",
-		"..W.W.B...B...W.W...",
-		".W.W.W.W.W.W.W.W.W..",
-		"B..B..BBB.BBB..B..B.",
-		".W.W.WBW.W.WBW.W.W..",
-		"B....B...B...B....B.",
-		".WBWBW.W.W.W.WBWBW..",
-		"W.B..B...B...B..B.WW",
-		".WBWBW.W.W.W.WBWBW..",
-		"B....B...B...B....B.",
-		".W.W.WBW.W.WBW.W.W..",
-		"B..B..BBB.BBB..B..B.",
-		".W.W.W.W.W.W.W.W.W..",
-		"..W.W.B...B...W.W...",
-		"..B.B.W.W.W.W.B.B...",
-	}
-
-	// 解析模板
-	for y := 0; y < MapHeight; y++ {
-		m.Tiles[y] = make([]TileType, MapWidth)
-		for x := 0; x < MapWidth; x++ {
-			switch template[y][x] {
-			case 'W':
-				m.Tiles[y][x] = TileWall
-			case 'B':
-				m.Tiles[y][x] = TileBrick
-			case '.':
-				m.Tiles[y][x] = TileEmpty
-			}
-		}
-	}
-
-	// 随机选择一个砖块放置隐藏门
-	brickPositions := []struct{ X, Y int }{}
-	for y := 0; y < MapHeight; y++ {
-		for x := 0; x < MapWidth; x++ {
-			if m.Tiles[y][x] == TileBrick {
-				brickPositions = append(brickPositions, struct{ X, Y int }{X: x, Y: y})
-			}
-		}
-	}
-
-	r := rand.New(rand.NewSource(seed))
-	if len(brickPositions) > 0 {
-		idx := r.Intn(len(brickPositions))
-		m.HiddenDoorPos = brickPositions[idx]
-	}
+// InBounds 格子是否在地图内（地图尺寸见 Width、Height，大地图比屏幕大）
+func (m *GameMap) InBounds(x, y int) bool {
+	return x >= 0 && x < m.Width && y >= 0 && y < m.Height
 }
 
-// GetTile 获取指定位置的地图块
+// GetTile 获取指定位置的地图块（地图外视为墙）
 func (m *GameMap) GetTile(x, y int) TileType {
-	if x < 0 || x >= MapWidth || y < 0 || y >= MapHeight {
+	if !m.InBounds(x, y) {
 		return TileWall
 	}
 	return m.Tiles[y][x]
@@ -94,7 +35,7 @@ func (m *GameMap) GetTile(x, y int) TileType {
 
 // SetTile 设置指定位置的地图块
 func (m *GameMap) SetTile(x, y int, tile TileType) {
-	if x >= 0 && x < MapWidth && y >= 0 && y < MapHeight {
+	if m.InBounds(x, y) {
 		m.Tiles[y][x] = tile
 	}
 }
@@ -112,7 +53,7 @@ func (m *GameMap) CanMoveTo(x, y, width, height int, bombGridPositions []struct{
 	hitboxHeight := PlayerHeight - PlayerMargin*2
 
 	// 边界检查
-	if hitboxX < 0 || hitboxY < 0 || hitboxX+hitboxW > MapWidth*TileSize || hitboxY+hitboxHeight > MapHeight*TileSize {
+	if hitboxX < 0 || hitboxY < 0 || hitboxX+hitboxW > m.Width*TileSize || hitboxY+hitboxHeight > m.Height*TileSize {
 		return false
 	}
 
@@ -0,0 +1,473 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+)
+
+// 地图的 JSON 定义：客户端地图编辑器保存、服务器地图目录存放的格式
+//
+//	{
+//	  "name": "arena",
+//	  "tiles": ["..B.W...", ...],            // MapHeight 行，每行 MapWidth 个字符（大地图为 LargeMapHeight×LargeMapWidth）：W=墙壁, B=砖块, .=空地
+//	  "spawns": [{"x": 0, "y": 0}, ...],     // 出生点，按玩家 ID 依次使用
+//	  "door_candidates": [{"x": 2, "y": 0}], // 隐藏门的候选砖块，为空表示任意砖块
+//	  "item_drops": {"percent": 40}          // 可选：覆盖全局道具掉落表（item_drops.go）
+//	}
+
+// 地图定义限制
+const (
+	MaxMapFileSize       = 3 << 10 // JSON 大小上限（字节），上传时必须放进一个数据包
+	MaxMapNameLen        = 32      // 地图名长度上限
+	MinMapSpawns         = 2       // 至少两个出生点才能对战
+	MaxMapSpawns         = 8       // 出生点上限（与房间人数上限一致）
+	MaxMapDoorCandidates = 32      // 门的候选位置上限
+)
+
+// 出生点公平性阈值（CheckFairness）
+const (
+	maxSpawnDistanceRatio  = 2 // 各出生点到最近对手的距离之比上限
+	maxBrickDistanceSpread = 4 // 各出生点到最近砖块的距离差上限（格）
+)
+
+// minSpawnOpenCells 出生点（清除安全区的砖块后）只走空地至少能到达的格子数，保证开局有合法的第一步
+const minSpawnOpenCells = 2
+
+// MapCell 地图定义中的格子坐标
+type MapCell struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// MapDefinition 地图定义
+type MapDefinition struct {
+	Name           string         `json:"name"`
+	Tiles          []string       `json:"tiles"`
+	Spawns         []MapCell      `json:"spawns"`
+	DoorCandidates []MapCell      `json:"door_candidates,omitempty"`
+	ItemDrops      *ItemDropTable `json:"item_drops,omitempty"`
+
+	procedural bool // 随机地图（map_gen.go）：格子由对局种子重新生成
+}
+
+// MapErrorReason 地图定义错误的类别（客户端编辑器据此显示提示）
+type MapErrorReason int
+
+const (
+	MapErrorName        MapErrorReason = iota // 地图名无效
+	MapErrorSize                              // 行数或列数不对
+	MapErrorTile                              // 无效字符
+	MapErrorSpawnCount                        // 出生点数量不对
+	MapErrorSpawn                             // 出生点不在空地上或重复
+	MapErrorDoor                              // 门的候选位置不在砖块上、重复或过多
+	MapErrorUnreachable                       // 出生点或门无法到达
+	MapErrorNoDoor                            // 没有可到达的砖块放置隐藏门
+	MapErrorUnfair                            // 出生点不公平（CheckFairness）
+	MapErrorItemDrops                         // 道具掉落表无效
+)
+
+// MapError 地图定义错误
+type MapError struct {
+	Reason MapErrorReason
+	Cell   MapCell // 出错的格子（与格子无关的错误为零值）
+	Msg    string
+}
+
+func (e *MapError) Error() string {
+	return "地图定义: " + e.Msg
+}
+
+func mapError(reason MapErrorReason, cell MapCell, format string, args ...any) *MapError {
+	return &MapError{Reason: reason, Cell: cell, Msg: fmt.Sprintf(format, args...)}
+}
+
+// defaultMapTiles 内置地图模板
+var defaultMapTiles = []string{
+	"..B.B.W.W.W.W.B.B...",
+	"..W.W.B...B...W.W...",
+	".W.W.W.W.W.W.W.W.W..",
+	"B..B..BBB.BBB..B..B.",
+	".W.W.WBW.W.WBW.W.W..",
+	"B....B...B...B....B.",
+	".WBWBW.W.W.W.WBWBW..",
+	"W.B..B...B...B..B.WW",
+	".WBWBW.W.W.W.WBWBW..",
+	"B....B...B...B....B.",
+	".W.W.WBW.W.WBW.W.W..",
+	"B..B..BBB.BBB..B..B.",
+	".W.W.W.W.W.W.W.W.W..",
+	"..W.W.B...B...W.W...",
+	"..B.B.W.W.W.W.B.B...",
+}
+
+// DefaultMapDefinition 内置地图（出生点为四个角落，与服务器 getSpawnPosition 一致）
+func DefaultMapDefinition() *MapDefinition {
+	return &MapDefinition{
+		Name:  "default",
+		Tiles: append([]string(nil), defaultMapTiles...),
+		Spawns: []MapCell{
+			{X: 0, Y: 0},
+			{X: MapWidth - 1, Y: 0},
+			{X: 0, Y: MapHeight - 1},
+			{X: MapWidth - 1, Y: MapHeight - 1},
+		},
+	}
+}
+
+// tileFromChar 模板字符转换为地图块
+func tileFromChar(c byte) (TileType, bool) {
+	switch c {
+	case 'W':
+		return TileWall, true
+	case 'B':
+		return TileBrick, true
+	case '.':
+		return TileEmpty, true
+	}
+	return TileEmpty, false
+}
+
+// Width 地图宽度（格）
+func (d *MapDefinition) Width() int {
+	if len(d.Tiles) == 0 {
+		return 0
+	}
+	return len(d.Tiles[0])
+}
+
+// Height 地图高度（格）
+func (d *MapDefinition) Height() int {
+	return len(d.Tiles)
+}
+
+// mapWidthFor 按行数确定地图应有的宽度：标准地图或大地图，其它行数返回 0
+func mapWidthFor(height int) int {
+	switch height {
+	case MapHeight:
+		return MapWidth
+	case LargeMapHeight:
+		return LargeMapWidth
+	}
+	return 0
+}
+
+// tileAt 读取定义中的地图块（调用前需已校验尺寸）
+func (d *MapDefinition) tileAt(x, y int) TileType {
+	tile, _ := tileFromChar(d.Tiles[y][x])
+	return tile
+}
+
+// Validate 校验地图定义：尺寸、字符、出生点、门的候选位置和连通性
+func (d *MapDefinition) Validate() error {
+	if d.Name == "" || len(d.Name) > MaxMapNameLen {
+		return mapError(MapErrorName, MapCell{}, "地图名长度必须在 1-%d 之间", MaxMapNameLen)
+	}
+	width := mapWidthFor(len(d.Tiles))
+	if width == 0 {
+		return mapError(MapErrorSize, MapCell{}, "地图必须是 %d 行（大地图 %d 行），实际 %d 行", MapHeight, LargeMapHeight, len(d.Tiles))
+	}
+	for y, row := range d.Tiles {
+		if len(row) != width {
+			return mapError(MapErrorSize, MapCell{Y: y}, "第 %d 行必须是 %d 个字符，实际 %d 个", y+1, width, len(row))
+		}
+		for x := 0; x < width; x++ {
+			if _, ok := tileFromChar(row[x]); !ok {
+				return mapError(MapErrorTile, MapCell{X: x, Y: y}, "(%d,%d) 的字符 %q 无效（只能是 W、B、.）", x, y, row[x])
+			}
+		}
+	}
+
+	if len(d.Spawns) < MinMapSpawns || len(d.Spawns) > MaxMapSpawns {
+		return mapError(MapErrorSpawnCount, MapCell{}, "出生点数量必须在 %d-%d 之间，实际 %d 个", MinMapSpawns, MaxMapSpawns, len(d.Spawns))
+	}
+	seen := make(map[MapCell]bool)
+	for _, s := range d.Spawns {
+		if !d.inMap(s) {
+			return mapError(MapErrorSpawn, MapCell{}, "出生点 (%d,%d) 超出地图", s.X, s.Y)
+		}
+		if d.tileAt(s.X, s.Y) != TileEmpty {
+			return mapError(MapErrorSpawn, s, "出生点 (%d,%d) 必须是空地", s.X, s.Y)
+		}
+		if seen[s] {
+			return mapError(MapErrorSpawn, s, "出生点 (%d,%d) 重复", s.X, s.Y)
+		}
+		seen[s] = true
+	}
+
+	if len(d.DoorCandidates) > MaxMapDoorCandidates {
+		return mapError(MapErrorDoor, MapCell{}, "门的候选位置最多 %d 个", MaxMapDoorCandidates)
+	}
+	pocket := d.spawnPockets()
+	doors := make(map[MapCell]bool)
+	for _, c := range d.DoorCandidates {
+		if !d.inMap(c) || d.tileAt(c.X, c.Y) != TileBrick {
+			return mapError(MapErrorDoor, c, "门的候选位置 (%d,%d) 必须是砖块", c.X, c.Y)
+		}
+		if pocket[c] {
+			return mapError(MapErrorDoor, c, "门的候选位置 (%d,%d) 紧挨出生点（出生点旁的砖块会被清除）", c.X, c.Y)
+		}
+		if doors[c] {
+			return mapError(MapErrorDoor, c, "门的候选位置 (%d,%d) 重复", c.X, c.Y)
+		}
+		doors[c] = true
+	}
+
+	if d.ItemDrops != nil {
+		if err := d.ItemDrops.validate(); err != nil {
+			return err
+		}
+	}
+
+	cleared := d.withSpawnPockets()
+	for _, s := range d.Spawns {
+		if open := len(cleared.distances(s, emptyTile)) - 1; open < minSpawnOpenCells {
+			return mapError(MapErrorSpawn, s, "出生点 (%d,%d) 被墙围住，至少要能走到 %d 格空地", s.X, s.Y, minSpawnOpenCells)
+		}
+	}
+	return cleared.checkConnectivity()
+}
+
+// spawnPockets 出生点安全区：每个出生点及其上下左右相邻的格子。
+// 生成地图时安全区内的砖块被清除，保证开局不会被砖块堵死
+func (d *MapDefinition) spawnPockets() map[MapCell]bool {
+	pocket := make(map[MapCell]bool)
+	for _, s := range d.Spawns {
+		for _, c := range []MapCell{s, {s.X + 1, s.Y}, {s.X - 1, s.Y}, {s.X, s.Y + 1}, {s.X, s.Y - 1}} {
+			if d.inMap(c) {
+				pocket[c] = true
+			}
+		}
+	}
+	return pocket
+}
+
+// withSpawnPockets 清除出生点安全区内砖块后的定义（没有需要清除的砖块时返回原定义）
+func (d *MapDefinition) withSpawnPockets() *MapDefinition {
+	var rows [][]byte
+	for c := range d.spawnPockets() {
+		if d.tileAt(c.X, c.Y) != TileBrick {
+			continue
+		}
+		if rows == nil {
+			rows = make([][]byte, len(d.Tiles))
+			for y, row := range d.Tiles {
+				rows[y] = []byte(row)
+			}
+		}
+		rows[c.Y][c.X] = '.'
+	}
+	if rows == nil {
+		return d
+	}
+	cleared := *d
+	cleared.Tiles = make([]string, len(rows))
+	for y, row := range rows {
+		cleared.Tiles[y] = string(row)
+	}
+	return &cleared
+}
+
+// checkConnectivity 连通性检查：砖块可以炸开，只有墙壁阻挡。
+// 所有出生点必须互相可达，门必须能从出生点到达
+func (d *MapDefinition) checkConnectivity() error {
+	start := d.Spawns[0]
+	reached := d.distances(start, notWall)
+
+	for _, s := range d.Spawns[1:] {
+		if _, ok := reached[s]; !ok {
+			return mapError(MapErrorUnreachable, s, "出生点 (%d,%d) 与 (%d,%d) 不连通", s.X, s.Y, start.X, start.Y)
+		}
+	}
+	for _, c := range d.DoorCandidates {
+		if _, ok := reached[c]; !ok {
+			return mapError(MapErrorUnreachable, c, "门的候选位置 (%d,%d) 无法到达", c.X, c.Y)
+		}
+	}
+	if len(d.DoorCandidates) == 0 {
+		for c := range reached {
+			if d.tileAt(c.X, c.Y) == TileBrick {
+				return nil
+			}
+		}
+		return mapError(MapErrorNoDoor, MapCell{}, "地图中没有可到达的砖块放置隐藏门")
+	}
+	return nil
+}
+
+// CheckFairness 出生点公平性的启发式检查（审核上传的地图时使用，定义需已校验）：
+// 每个出生点不炸砖块就能躲到不同行列的空地；各出生点到最近对手的距离相差不超过
+// maxSpawnDistanceRatio 倍；到最近砖块（道具来源）的距离相差不超过 maxBrickDistanceSpread 格
+func (d *MapDefinition) CheckFairness() error {
+	d = d.withSpawnPockets()
+	enemyDist := make([]int, len(d.Spawns))
+	brickDist := make([]int, len(d.Spawns))
+	for i, s := range d.Spawns {
+		if !d.hasCover(s) {
+			return mapError(MapErrorUnfair, s, "出生点 (%d,%d) 附近没有躲避炸弹的空地", s.X, s.Y)
+		}
+		dist := d.distances(s, notWall)
+		enemyDist[i], brickDist[i] = -1, -1
+		for j, o := range d.Spawns {
+			if n, ok := dist[o]; ok && j != i && (enemyDist[i] < 0 || n < enemyDist[i]) {
+				enemyDist[i] = n
+			}
+		}
+		for c, n := range dist {
+			if d.tileAt(c.X, c.Y) == TileBrick && (brickDist[i] < 0 || n < brickDist[i]) {
+				brickDist[i] = n
+			}
+		}
+	}
+
+	nearest, farthest := 0, 0
+	for i := range d.Spawns {
+		if enemyDist[i] < enemyDist[nearest] {
+			nearest = i
+		}
+		if enemyDist[i] > enemyDist[farthest] {
+			farthest = i
+		}
+	}
+	if enemyDist[farthest] > enemyDist[nearest]*maxSpawnDistanceRatio {
+		s := d.Spawns[nearest]
+		return mapError(MapErrorUnfair, s, "出生点 (%d,%d) 离对手太近（%d 格，最远的出生点 %d 格）",
+			s.X, s.Y, enemyDist[nearest], enemyDist[farthest])
+	}
+
+	nearest, farthest = 0, 0
+	for i := range d.Spawns {
+		if brickDist[i] < brickDist[nearest] {
+			nearest = i
+		}
+		if brickDist[i] > brickDist[farthest] {
+			farthest = i
+		}
+	}
+	if brickDist[farthest]-brickDist[nearest] > maxBrickDistanceSpread {
+		s := d.Spawns[farthest]
+		return mapError(MapErrorUnfair, s, "出生点 (%d,%d) 离砖块太远（%d 格，最近的出生点 %d 格）",
+			s.X, s.Y, brickDist[farthest], brickDist[nearest])
+	}
+	return nil
+}
+
+// hasCover 出生点只走空地能否到达不同行也不同列的格子（放下第一颗炸弹后躲到拐角）
+func (d *MapDefinition) hasCover(spawn MapCell) bool {
+	for c := range d.distances(spawn, emptyTile) {
+		if c.X != spawn.X && c.Y != spawn.Y {
+			return true
+		}
+	}
+	return false
+}
+
+// distances 从 start 出发的广度优先距离（只经过 passable 的格子）
+func (d *MapDefinition) distances(start MapCell, passable func(TileType) bool) map[MapCell]int {
+	dist := map[MapCell]int{start: 0}
+	queue := []MapCell{start}
+	for len(queue) > 0 {
+		c := queue[0]
+		queue = queue[1:]
+		for _, n := range []MapCell{{c.X + 1, c.Y}, {c.X - 1, c.Y}, {c.X, c.Y + 1}, {c.X, c.Y - 1}} {
+			if _, seen := dist[n]; seen || !d.inMap(n) || !passable(d.tileAt(n.X, n.Y)) {
+				continue
+			}
+			dist[n] = dist[c] + 1
+			queue = append(queue, n)
+		}
+	}
+	return dist
+}
+
+// notWall 砖块可以炸开，只有墙壁阻挡
+func notWall(t TileType) bool {
+	return t != TileWall
+}
+
+// emptyTile 不炸砖块时只能走空地
+func emptyTile(t TileType) bool {
+	return t == TileEmpty
+}
+
+func (d *MapDefinition) inMap(c MapCell) bool {
+	return c.Y >= 0 && c.Y < len(d.Tiles) && c.X >= 0 && c.X < len(d.Tiles[c.Y])
+}
+
+// SpawnCell 按玩家 ID 选择出生点（取模，支持任意数量的玩家）
+func (d *MapDefinition) SpawnCell(playerID int) MapCell {
+	if playerID <= 0 {
+		return d.Spawns[0]
+	}
+	return d.Spawns[(playerID-1)%len(d.Spawns)]
+}
+
+// ParseMapDefinition 解析并校验 JSON 地图定义
+func ParseMapDefinition(data []byte) (*MapDefinition, error) {
+	if len(data) > MaxMapFileSize {
+		return nil, fmt.Errorf("地图文件过大（%d 字节，上限 %d）", len(data), MaxMapFileSize)
+	}
+	var def MapDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// LoadMapDefinition 从文件读取 JSON 地图定义
+func LoadMapDefinition(path string) (*MapDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMapDefinition(data)
+}
+
+// MarshalMapDefinition 导出为缩进的 JSON
+func MarshalMapDefinition(def *MapDefinition) ([]byte, error) {
+	return json.MarshalIndent(def, "", "  ")
+}
+
+// NewGameMapFromDefinition 按地图定义创建地图（定义需已校验），隐藏门由种子在候选位置中选择，
+// 砖块下的道具按地图的掉落表和种子预先分配；随机地图的格子也由种子生成，所有出生点安全区内的砖块被清除
+func NewGameMapFromDefinition(def *MapDefinition, seed int64) *GameMap {
+	def = seededDefinition(def, seed).withSpawnPockets()
+	m := &GameMap{
+		Tiles:  make([][]TileType, def.Height()),
+		Width:  def.Width(),
+		Height: def.Height(),
+	}
+	for y := 0; y < m.Height; y++ {
+		m.Tiles[y] = make([]TileType, m.Width)
+		for x := 0; x < m.Width; x++ {
+			m.Tiles[y][x] = def.tileAt(x, y)
+		}
+	}
+
+	// 没有指定候选位置时，任意砖块都可能藏门
+	candidates := make([]struct{ X, Y int }, 0, len(def.DoorCandidates))
+	for _, c := range def.DoorCandidates {
+		candidates = append(candidates, struct{ X, Y int }{X: c.X, Y: c.Y})
+	}
+	if len(candidates) == 0 {
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				if m.Tiles[y][x] == TileBrick {
+					candidates = append(candidates, struct{ X, Y int }{X: x, Y: y})
+				}
+			}
+		}
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	if len(candidates) > 0 {
+		m.HiddenDoorPos = candidates[r.Intn(len(candidates))]
+	}
+	m.Hazards = defaultMapHazards(m.Width, m.Height)
+	assignItemDrops(m, ResolveItemDropTable(def.ItemDrops), seed)
+	return m
+}
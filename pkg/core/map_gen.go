@@ -0,0 +1,118 @@
+package core
+
+import "math/rand"
+
+// 随机地图：由种子（Game.Seed）确定性地生成，四向镜像对称，出生点在四个角落。
+// 房间只下发地图 ID 和种子，客户端用同一个生成器得到同一张地图
+
+// 随机地图生成参数
+const (
+	genMaxAttempts     = 16   // 生成的地图未通过校验时换一组随机数重试的次数
+	genPillarDropRate  = 0.15 // 去掉柱子的概率（打通更多通道）
+	genExtraWallRate   = 0.06 // 空地额外变成墙壁的概率
+	genMinBrickDensity = 0.45 // 砖块密度下限
+	genMaxBrickDensity = 0.70 // 砖块密度上限
+)
+
+// genHalfWidth, genHalfHeight 生成的左上象限（含中间行），其余三个象限由镜像得到
+const (
+	genHalfWidth  = (MapWidth + 1) / 2
+	genHalfHeight = (MapHeight + 1) / 2
+)
+
+// genSpawnPocket 出生点附近保持空地的格子（左上角，其余角落镜像）：
+// 不炸砖就能躲到 (2,1) 或 (1,2)，保证第一颗炸弹有处可躲
+var genSpawnPocket = []MapCell{{0, 0}, {1, 0}, {2, 0}, {0, 1}, {0, 2}, {2, 1}, {1, 2}}
+
+// RandomMap 随机地图定义（种子 0 的生成结果），房间选择随机地图时使用；
+// 实际地图由 NewGameMapFromDefinition 按对局种子重新生成
+func RandomMap() *MapDefinition {
+	return GenerateMap(0)
+}
+
+// GenerateMap 按种子生成随机地图定义：每次生成后做出生点安全、连通性（洪水填充）和公平性校验，
+// 不通过时重试，多次失败则退回默认地图的布局
+func GenerateMap(seed int64) *MapDefinition {
+	r := rand.New(rand.NewSource(seed))
+	for attempt := 0; attempt < genMaxAttempts; attempt++ {
+		def := generateMapOnce(r)
+		if def.Validate() == nil && def.CheckFairness() == nil {
+			return def
+		}
+	}
+	def := DefaultMapDefinition()
+	def.Name = MapLayoutRandom
+	def.procedural = true
+	return def
+}
+
+// generateMapOnce 生成一次：随机柱子和砖块铺满左上象限，镜像到整张地图，
+// 清空出生点周围，再把与出生点不连通的格子填成墙（避免门藏进到不了的地方）
+func generateMapOnce(r *rand.Rand) *MapDefinition {
+	density := genMinBrickDensity + r.Float64()*(genMaxBrickDensity-genMinBrickDensity)
+	rows := make([][]byte, MapHeight)
+	for y := range rows {
+		rows[y] = make([]byte, MapWidth)
+	}
+	for y := 0; y < genHalfHeight; y++ {
+		for x := 0; x < genHalfWidth; x++ {
+			tile := byte('.')
+			switch {
+			case x%2 == 1 && y%2 == 1:
+				if r.Float64() >= genPillarDropRate {
+					tile = 'W'
+				}
+			case r.Float64() < genExtraWallRate:
+				tile = 'W'
+			case r.Float64() < density:
+				tile = 'B'
+			}
+			setMirrored(rows, x, y, tile)
+		}
+	}
+	for _, c := range genSpawnPocket {
+		setMirrored(rows, c.X, c.Y, '.')
+	}
+
+	def := &MapDefinition{
+		Name: MapLayoutRandom,
+		Spawns: []MapCell{
+			{X: 0, Y: 0},
+			{X: MapWidth - 1, Y: 0},
+			{X: 0, Y: MapHeight - 1},
+			{X: MapWidth - 1, Y: MapHeight - 1},
+		},
+		procedural: true,
+	}
+	for _, row := range rows {
+		def.Tiles = append(def.Tiles, string(row))
+	}
+
+	reached := def.distances(def.Spawns[0], notWall)
+	for y, row := range rows {
+		for x := range row {
+			if _, ok := reached[MapCell{X: x, Y: y}]; !ok {
+				row[x] = 'W'
+			}
+		}
+		def.Tiles[y] = string(row)
+	}
+	return def
+}
+
+// setMirrored 设置左上象限的格子及其在另外三个象限的镜像
+func setMirrored(rows [][]byte, x, y int, tile byte) {
+	mx, my := MapWidth-1-x, MapHeight-1-y
+	rows[y][x] = tile
+	rows[y][mx] = tile
+	rows[my][x] = tile
+	rows[my][mx] = tile
+}
+
+// seededDefinition 随机地图按种子重新生成，其余地图原样返回
+func seededDefinition(def *MapDefinition, seed int64) *MapDefinition {
+	if def.procedural {
+		return GenerateMap(seed)
+	}
+	return def
+}
@@ -0,0 +1,45 @@
+package core
+
+// 大地图：房间超过 StandardMapPlayers 人时服务器自动换成这张地图（房主也可以直接选择），
+// 比屏幕大，客户端镜头跟随本地玩家滚动。出生点是四个角落加四条边的中点，
+// 按玩家 ID 依次使用：前四名与标准地图一样在四个角落，第 5-8 名在四条边上
+
+// largeMapTiles 大地图模板（LargeMapHeight 行，每行 LargeMapWidth 个字符）
+var largeMapTiles = []string{
+	"....BB.BB.......BB.BB.....",
+	".W.W.WBWBW.W.W.WBWBW.WBW..",
+	"...BB.BB.BB...BB.BB.BB.B..",
+	".WBWBW.WBWBW.WBWBW.WBWBW..",
+	"B.BB.BB.BB.BBB.BB.BB.BB.BB",
+	"BW.WBWBW.WBWBWBW.WBWBW.WBB",
+	".BB.BB.BB.BB.BB.BB.BB.BB..",
+	".WBW.WBWBW.WBW.WBWBW.WBW..",
+	"...BB.BB.BB.B.BB.BB.BB....",
+	".W.WBW.WBWBW.WBWBW.WBW.W..",
+	"...BB.BB.BB.B.BB.BB.BB....",
+	".WBW.WBWBW.WBW.WBWBW.WBW..",
+	".BB.BB.BB.BB.BB.BB.BB.BB..",
+	"BW.WBWBW.WBWBWBW.WBWBW.WBB",
+	"B.BB.BB.BB.BBB.BB.BB.BB.BB",
+	".WBWBW.WBWBW.WBWBW.WBWBW..",
+	"...BB.BB.BB...BB.BB.BB.B..",
+	".W.W.WBWBW.W.W.WBWBW.WBW..",
+	"....BB.BB.......BB.BB.....",
+}
+
+// largeMapSpawns 大地图的出生点（按玩家 ID 依次使用）
+var largeMapSpawns = []MapCell{
+	{X: 0, Y: 0},
+	{X: LargeMapWidth - 1, Y: 0},
+	{X: 0, Y: LargeMapHeight - 1},
+	{X: LargeMapWidth - 1, Y: LargeMapHeight - 1},
+	{X: 12, Y: 0},
+	{X: 12, Y: LargeMapHeight - 1},
+	{X: 0, Y: LargeMapHeight / 2},
+	{X: LargeMapWidth - 1, Y: LargeMapHeight / 2},
+}
+
+// FitsPlayers 地图的出生点是否够 players 名玩家各占一个（不够时多出的玩家与别人共用出生点）
+func (d *MapDefinition) FitsPlayers(players int) bool {
+	return len(d.Spawns) >= players
+}
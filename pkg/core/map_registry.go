@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bomberman/pkg/resources"
+)
+
+// 内置地图注册表：每张内置地图有固定 ID，房间只下发 ID 和种子，客户端用同一份定义确定性地生成地图。
+// 标准尺寸的内置地图均以四个角落为出生点（与服务器 getSpawnPosition 一致），大地图另有八个出生点（map_large.go）
+
+// 内置地图 ID（与 pkg/resources 中的地图资源 ID 一致）
+const (
+	MapLayoutDefault    = resources.MapDefault
+	MapLayoutOpen       = resources.MapOpen
+	MapLayoutFortress   = resources.MapFortress
+	MapLayoutCrossroads = resources.MapCrossroads
+	MapLayoutLarge      = resources.MapLarge  // 大地图（5-8 人）
+	MapLayoutRandom     = resources.MapRandom // 按种子生成（map_gen.go），排在固定模板之后
+)
+
+// mapHazardsSuffix 开启危险区域规则时地图资源 ID 的后缀（见 MapID）
+const mapHazardsSuffix = "+hazards"
+
+// builtinMapTiles 内置地图模板（按选择顺序排列，第一张为默认地图）
+var builtinMapTiles = []struct {
+	id     string
+	tiles  []string
+	spawns []MapCell // nil 表示四个角落
+}{
+	{MapLayoutDefault, defaultMapTiles, nil},
+	// 空旷：只有柱子和少量砖块，开局就能短兵相接
+	{MapLayoutOpen, []string{
+		"....B...B..B...B....",
+		".W.W.W.W....W.W.W.W.",
+		"B.....B......B.....B",
+		".W.W.W.W.BB.W.W.W.W.",
+		"...B............B...",
+		".W.W.W.W.WW.W.W.W.W.",
+		"B.....B......B.....B",
+		"...B...B....B...B...",
+		"B.....B......B.....B",
+		".W.W.W.W.WW.W.W.W.W.",
+		"...B............B...",
+		".W.W.W.W.BB.W.W.W.W.",
+		"B.....B......B.....B",
+		".W.W.W.W....W.W.W.W.",
+		"....B...B..B...B....",
+	}, nil},
+	// 堡垒：中央被墙围起的砖块区，只能从左右两侧的缺口进入
+	{MapLayoutFortress, []string{
+		"...B.B.B....B.B.B...",
+		".W.W.W.W.BB.W.W.W.W.",
+		"B.B..B........B..B.B",
+		".W.B.WWWWWWWWWW.B.W.",
+		"B..B.WBB.BB.BBW.B..B",
+		".W.W.WB.B..B.BW.W.W.",
+		"B...BB.B.BB.B.BB...B",
+		".W.W..B.B..B.B..W.W.",
+		"B...BB.B.BB.B.BB...B",
+		".W.W.WB.B..B.BW.W.W.",
+		"B..B.WBB.BB.BBW.B..B",
+		".W.B.WWWWWWWWWW.B.W.",
+		"B.B..B........B..B.B",
+		".W.W.W.W.BB.W.W.W.W.",
+		"...B.B.B....B.B.B...",
+	}, nil},
+	// 十字路口：四角是密集的砖块区，中央十字通道开阔
+	{MapLayoutCrossroads, []string{
+		"...BB..........BB...",
+		".W.WB.W.W..W.W.BW.W.",
+		"BBB.B..........B.BBB",
+		"BW.WB.W.W..W.W.BW.WB",
+		"BBBB............BBBB",
+		"......BBB..BBB......",
+		".W.W..BWB..BWB..W.W.",
+		"......B.B..B.B......",
+		".W.W..BWB..BWB..W.W.",
+		"......BBB..BBB......",
+		"BBBB............BBBB",
+		"BW.WB.W.W..W.W.BW.WB",
+		"BBB.B..........B.BBB",
+		".W.WB.W.W..W.W.BW.W.",
+		"...BB..........BB...",
+	}, nil},
+	{MapLayoutLarge, largeMapTiles, largeMapSpawns},
+}
+
+// BuiltinMapIDs 内置地图 ID（按选择顺序）
+func BuiltinMapIDs() []string {
+	ids := make([]string, 0, len(builtinMapTiles))
+	for _, m := range builtinMapTiles {
+		ids = append(ids, m.id)
+	}
+	return append(ids, MapLayoutRandom)
+}
+
+// IsBuiltinMap 是否为内置地图 ID
+func IsBuiltinMap(id string) bool {
+	if id == MapLayoutRandom {
+		return true
+	}
+	for _, m := range builtinMapTiles {
+		if m.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// BuiltinMap 内置地图定义（每次返回新对象，未知 ID 返回 nil）
+func BuiltinMap(id string) *MapDefinition {
+	if id == MapLayoutRandom {
+		return RandomMap()
+	}
+	for _, m := range builtinMapTiles {
+		if m.id == id {
+			def := DefaultMapDefinition()
+			def.Name = m.id
+			def.Tiles = append([]string(nil), m.tiles...)
+			if m.spawns != nil {
+				def.Spawns = append([]MapCell(nil), m.spawns...)
+			}
+			return def
+		}
+	}
+	return nil
+}
+
+// MapLayout 从地图资源 ID（MapID）中取出内置地图 ID，空 ID 视为默认地图
+func MapLayout(mapID string) string {
+	layout := strings.TrimSuffix(mapID, mapHazardsSuffix)
+	if layout == "" {
+		return MapLayoutDefault
+	}
+	return layout
+}
+
+// 文本地图格式：每行一排格子，共 MapHeight 行、每行 MapWidth 个字符（大地图为 LargeMapHeight 行、
+// 每行 LargeMapWidth 个字符），# 开头的行是注释
+//
+//	W = 墙壁, B = 砖块, . = 空地
+//	1-8 = 对应玩家的出生点（空地）
+//	D = 可能藏门的砖块（没有 D 时任意砖块都可能藏门）
+//
+// 地图名取自文件名（LoadMapFile）
+
+// ParseMapText 解析并校验文本地图
+func ParseMapText(name string, data []byte) (*MapDefinition, error) {
+	if len(data) > MaxMapFileSize {
+		return nil, fmt.Errorf("地图文件过大（%d 字节，上限 %d）", len(data), MaxMapFileSize)
+	}
+	def := &MapDefinition{Name: name}
+	spawns := make(map[int]MapCell)
+	for _, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		y := len(def.Tiles)
+		row := []byte(line)
+		for x, c := range row {
+			switch {
+			case c >= '1' && c <= '0'+MaxMapSpawns:
+				if _, dup := spawns[int(c-'0')]; dup {
+					return nil, mapError(MapErrorSpawn, MapCell{X: x, Y: y}, "出生点 %c 重复", c)
+				}
+				spawns[int(c-'0')] = MapCell{X: x, Y: y}
+				row[x] = '.'
+			case c == 'D':
+				def.DoorCandidates = append(def.DoorCandidates, MapCell{X: x, Y: y})
+				row[x] = 'B'
+			}
+		}
+		def.Tiles = append(def.Tiles, string(row))
+	}
+	for id := 1; id <= len(spawns); id++ {
+		cell, ok := spawns[id]
+		if !ok {
+			return nil, mapError(MapErrorSpawnCount, MapCell{}, "出生点必须从 1 开始连续编号，缺少 %d", id)
+		}
+		def.Spawns = append(def.Spawns, cell)
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// MarshalMapText 导出为文本地图格式（与 ParseMapText 对应）
+func MarshalMapText(def *MapDefinition) []byte {
+	rows := make([][]byte, len(def.Tiles))
+	for y, row := range def.Tiles {
+		rows[y] = []byte(row)
+	}
+	for _, c := range def.DoorCandidates {
+		rows[c.Y][c.X] = 'D'
+	}
+	for i, s := range def.Spawns {
+		rows[s.Y][s.X] = byte('1' + i)
+	}
+	var sb strings.Builder
+	for _, row := range rows {
+		sb.Write(row)
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
+
+// IsMapTextFile 按扩展名判断是否为文本地图（.txt），其余按 JSON 处理
+func IsMapTextFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".txt")
+}
+
+// LoadMapFile 读取外部地图文件：.txt 为文本地图（地图名取自文件名），其余为 JSON 地图定义
+func LoadMapFile(path string) (*MapDefinition, error) {
+	if !IsMapTextFile(path) {
+		return LoadMapDefinition(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseMapText(name, data)
+}
@@ -0,0 +1,72 @@
+package core
+
+// MatchConfig 房间可调的对局参数（房主在开始前设置，默认值与原来的全局常量一致）
+type MatchConfig struct {
+	BombFuseFrames      int32   // 炸弹引爆时间（帧）
+	PlayerSpeed         float64 // 初始移动速度（像素/帧）
+	BombRange           int     // 初始爆炸范围（格）
+	MaxBombs            int     // 初始可同时放置炸弹数
+	MatchDurationFrames int32   // 对局时长（帧，0 表示不限时）
+	RoundsToWin         int32   // 多回合对局：先赢得几回合的玩家获胜（0 或 1 表示单局；回合由服务器管理，核心逻辑不使用）
+}
+
+// 对局参数的取值范围（超出范围时 Clamp 会修正）
+const (
+	MinBombFuseFrames      = 1 * TPS
+	MaxBombFuseFrames      = 5 * TPS
+	MinPlayerSpeed         = 1.0
+	MinMatchDurationFrames = 30 * TPS
+	MaxMatchDurationFrames = 10 * 60 * TPS
+	MaxRoundsToWin         = 5
+)
+
+// DefaultMatchConfig 默认对局参数
+func DefaultMatchConfig() MatchConfig {
+	return MatchConfig{
+		BombFuseFrames:      BombFuseFrames,
+		PlayerSpeed:         PlayerSpeedPerFrame,
+		BombRange:           BombExplosionRange,
+		MaxBombs:            BombMaxCountDefault,
+		MatchDurationFrames: MatchDurationFrames,
+	}
+}
+
+// Clamp 把各项参数限制在允许范围内（零值视为默认值，时长 0 表示不限时）
+// 初始属性不超过道具上限，拾取道具的逻辑不需要区分配置
+func (c MatchConfig) Clamp() MatchConfig {
+	def := DefaultMatchConfig()
+	if c.BombFuseFrames <= 0 {
+		c.BombFuseFrames = def.BombFuseFrames
+	}
+	c.BombFuseFrames = min(max(c.BombFuseFrames, MinBombFuseFrames), MaxBombFuseFrames)
+	if c.PlayerSpeed <= 0 {
+		c.PlayerSpeed = def.PlayerSpeed
+	}
+	c.PlayerSpeed = min(max(c.PlayerSpeed, MinPlayerSpeed), ItemMaxSpeed)
+	if c.BombRange <= 0 {
+		c.BombRange = def.BombRange
+	}
+	c.BombRange = min(c.BombRange, ItemMaxRange)
+	if c.MaxBombs <= 0 {
+		c.MaxBombs = def.MaxBombs
+	}
+	c.MaxBombs = min(c.MaxBombs, ItemMaxBombs)
+	if c.MatchDurationFrames < 0 {
+		c.MatchDurationFrames = 0
+	}
+	if c.MatchDurationFrames > 0 {
+		c.MatchDurationFrames = min(max(c.MatchDurationFrames, MinMatchDurationFrames), MaxMatchDurationFrames)
+	}
+	c.RoundsToWin = min(max(c.RoundsToWin, 0), MaxRoundsToWin)
+	return c
+}
+
+// ApplyMatchConfig 设置对局参数，并把初始属性写入现有玩家（开局前调用）
+func (g *Game) ApplyMatchConfig(config MatchConfig) {
+	g.Config = config.Clamp()
+	for _, p := range g.Players {
+		p.Speed = g.Config.PlayerSpeed
+		p.BombRange = g.Config.BombRange
+		p.MaxBombs = g.Config.MaxBombs
+	}
+}
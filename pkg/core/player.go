@@ -15,6 +15,12 @@ type Player struct {
 	Dead      bool          // 是否死亡
 
 	NextPlacementFrame int32 // 下一次可放置炸弹的帧号
+	BombBufferUntil    int32 // 缓冲中的放弹按键的截止帧（0 表示没有）
+	BombHeld           bool  // 上一帧是否按着放弹键（只缓冲按下的瞬间）
+	NextShoveFrame     int32 // 下一次可推人的帧号
+	CanKick            bool  // 拾取过踢炸弹道具（bomb_kick.go）
+	Stamina            int   // 冲刺体力（sprint.go）
+	Sprinting          bool  // 上一次输入是否在冲刺
 
 	Speed float64 // 移动速度（像素/帧）
 
@@ -24,6 +30,16 @@ type Player struct {
 
 	MaxBombs  int // 最大同时炸弹数
 	BombRange int // 炸弹爆炸范围
+
+	Effects []Effect // 当前生效的增益/减益
+
+	DoorCampFrames int32 // 连续站在门上的帧数（GameRules.DoorCampPing）
+
+	KillerID      int  // 致死爆炸的归属玩家（仅 Dead 时有效，可能是自己）
+	Kills         int  // 本局击杀其他玩家的次数（kills.go）
+	deathRecorded bool // 阵亡已记入 Game.KillLog
+
+	Team int // 所属队伍（GameRules.Teams，TeamNone 表示不分队）
 }
 
 // NewPlayer 创建新玩家
@@ -45,6 +61,7 @@ func NewPlayer(id int, x, y int, charType CharacterType) *Player {
 		BombIgnoreActive:   false,
 		MaxBombs:           BombMaxCountDefault,
 		BombRange:          BombExplosionRange,
+		Stamina:            StaminaMax,
 	}
 }
 
@@ -93,12 +110,23 @@ func (p *Player) Move(dx, dy float64, game *Game) bool {
 	if !game.Map.CanMoveTo(int(newX), int(newY), p.Width, p.Height, bombPositions, explosionCells) {
 		correctedX, correctedY, ok := p.tryCornerCorrection(dx, dy, game, bombPositions, explosionCells)
 		if !ok {
+			if p.Kick(game, dx, dy) != nil {
+				p.faceTowards(dx, dy)
+			}
 			return false
 		}
 		newX = correctedX
 		newY = correctedY
 	}
 
+	// 玩家碰撞（房间规则），贴住挡路的玩家
+	newX, newY, ok := p.resolvePlayerCollision(game, dx, dy, newX, newY)
+	if !ok {
+		// 被玩家挡住时仍然转向，才能推开挡路的人
+		p.faceTowards(dx, dy)
+		return false
+	}
+
 	p.X = newX
 	p.Y = newY
 	p.IsMoving = (dx != 0 || dy != 0)
@@ -106,6 +134,17 @@ func (p *Player) Move(dx, dy float64, game *Game) bool {
 	p.applySoftAlign(dx, dy, game, bombPositions, explosionCells)
 
 	// 更新方向
+	p.faceTowards(dx, dy)
+
+	if p.BombIgnoreActive && !p.overlapsGrid(p.BombIgnoreGridX, p.BombIgnoreGridY) {
+		p.BombIgnoreActive = false
+	}
+
+	return true
+}
+
+// faceTowards 按移动方向更新朝向
+func (p *Player) faceTowards(dx, dy float64) {
 	if dx > 0 {
 		p.Direction = DirRight
 	} else if dx < 0 {
@@ -115,12 +154,6 @@ func (p *Player) Move(dx, dy float64, game *Game) bool {
 	} else if dy < 0 {
 		p.Direction = DirUp
 	}
-
-	if p.BombIgnoreActive && !p.overlapsGrid(p.BombIgnoreGridX, p.BombIgnoreGridY) {
-		p.BombIgnoreActive = false
-	}
-
-	return true
 }
 
 // collectExplosionCells 收集所有爆炸影响的格子
@@ -132,8 +165,25 @@ func collectExplosionCells(explosions []*Explosion) []GridPos {
 	return cells
 }
 
+// BufferBomb 缓冲一次没能放出的放弹按键
+func (p *Player) BufferBomb(currentFrame int32) {
+	p.BombBufferUntil = currentFrame + BombInputBufferFrames
+}
+
+// BombBuffered 当前帧是否有缓冲中的放弹按键
+func (p *Player) BombBuffered(currentFrame int32) bool {
+	return p.BombBufferUntil > 0 && currentFrame <= p.BombBufferUntil
+}
+
 // PlaceBomb 放置炸弹（返回是否成功）
 func (p *Player) PlaceBomb(game *Game, currentFrame int32) *Bomb {
+	gridX, gridY := p.GetGridPosition()
+	return p.PlaceBombAt(game, gridX, gridY, currentFrame)
+}
+
+// PlaceBombAt 在指定格子放置炸弹
+// 服务器延迟补偿使用，调用方负责校验该格子与玩家当前位置的距离
+func (p *Player) PlaceBombAt(game *Game, gridX, gridY int, currentFrame int32) *Bomb {
 	if p.Dead {
 		return nil
 	}
@@ -144,19 +194,10 @@ func (p *Player) PlaceBomb(game *Game, currentFrame int32) *Bomb {
 	}
 
 	// 检查当前活跃炸弹数量
-	activeBombs := 0
-	for _, bomb := range game.Bombs {
-		if bomb.OwnerID == p.ID && !bomb.Exploded {
-			activeBombs++
-		}
-	}
-	if activeBombs >= p.MaxBombs {
+	if game.ActiveBombCount(p.ID) >= p.MaxBombs {
 		return nil
 	}
 
-	// 获取玩家所在格子
-	gridX, gridY := p.GetGridPosition()
-
 	// 只能在空地放置炸弹
 	if game.Map.GetTile(gridX, gridY) != TileEmpty {
 		return nil
@@ -173,8 +214,9 @@ func (p *Player) PlaceBomb(game *Game, currentFrame int32) *Bomb {
 	p.NextPlacementFrame = currentFrame + BombPlacementDelayFrames
 	p.BombIgnoreGridX = gridX
 	p.BombIgnoreGridY = gridY
-	p.BombIgnoreActive = true
+	p.BombIgnoreActive = p.overlapsGrid(gridX, gridY)
 	bomb := NewBomb(gridX, gridY, p.ID, currentFrame)
+	bomb.ExplodeAtFrame = currentFrame + game.Config.BombFuseFrames
 	bomb.ExplosionRange = p.BombRange
 	return bomb
 }
@@ -227,7 +269,7 @@ func (p *Player) tryCornerCorrection(dx, dy float64, game *Game, bombPositions [
 	}
 
 	if dx != 0 {
-		targetY := p.nearestAlignedY()
+		targetY := p.nearestAlignedY(game.Map)
 		offset := targetY - p.Y
 		if math.Abs(offset) > CornerCorrectionTolerance {
 			return 0, 0, false
@@ -241,13 +283,13 @@ func (p *Player) tryCornerCorrection(dx, dy float64, game *Game, bombPositions [
 		}
 		newY := p.Y + step
 		newX := p.X + dx
-		if game.Map.CanMoveTo(int(newX), int(newY), p.Width, p.Height, bombPositions, explosionCells) {
+		if p.canMoveTo(game, newX, newY, bombPositions, explosionCells) {
 			return newX, newY, true
 		}
 		return 0, 0, false
 	}
 
-	targetX := p.nearestAlignedX()
+	targetX := p.nearestAlignedX(game.Map)
 	offset := targetX - p.X
 	if math.Abs(offset) > CornerCorrectionTolerance {
 		return 0, 0, false
@@ -261,7 +303,7 @@ func (p *Player) tryCornerCorrection(dx, dy float64, game *Game, bombPositions [
 	}
 	newX := p.X + step
 	newY := p.Y + dy
-	if game.Map.CanMoveTo(int(newX), int(newY), p.Width, p.Height, bombPositions, explosionCells) {
+	if p.canMoveTo(game, newX, newY, bombPositions, explosionCells) {
 		return newX, newY, true
 	}
 	return 0, 0, false
@@ -276,7 +318,7 @@ func (p *Player) applySoftAlign(dx, dy float64, game *Game, bombPositions []stru
 	}
 
 	if dx != 0 {
-		targetY := p.nearestAlignedY()
+		targetY := p.nearestAlignedY(game.Map)
 		offset := targetY - p.Y
 		if math.Abs(offset) > CornerCorrectionTolerance {
 			return
@@ -289,13 +331,13 @@ func (p *Player) applySoftAlign(dx, dy float64, game *Game, bombPositions []stru
 			step = -step
 		}
 		newY := p.Y + step
-		if game.Map.CanMoveTo(int(p.X), int(newY), p.Width, p.Height, bombPositions, explosionCells) {
+		if p.canMoveTo(game, p.X, newY, bombPositions, explosionCells) {
 			p.Y = newY
 		}
 		return
 	}
 
-	targetX := p.nearestAlignedX()
+	targetX := p.nearestAlignedX(game.Map)
 	offset := targetX - p.X
 	if math.Abs(offset) > CornerCorrectionTolerance {
 		return
@@ -308,30 +350,30 @@ func (p *Player) applySoftAlign(dx, dy float64, game *Game, bombPositions []stru
 		step = -step
 	}
 	newX := p.X + step
-	if game.Map.CanMoveTo(int(newX), int(p.Y), p.Width, p.Height, bombPositions, explosionCells) {
+	if p.canMoveTo(game, newX, p.Y, bombPositions, explosionCells) {
 		p.X = newX
 	}
 }
 
-func (p *Player) nearestAlignedX() float64 {
+func (p *Player) nearestAlignedX(m *GameMap) float64 {
 	centerX := p.X + float64(p.Width)/2
 	gridX := int(math.Floor(centerX/float64(TileSize) + 0.5))
 	if gridX < 0 {
 		gridX = 0
-	} else if gridX >= MapWidth {
-		gridX = MapWidth - 1
+	} else if gridX >= m.Width {
+		gridX = m.Width - 1
 	}
 	offset := float64(TileSize-p.Width) / 2
 	return float64(gridX*TileSize) + offset
 }
 
-func (p *Player) nearestAlignedY() float64 {
+func (p *Player) nearestAlignedY(m *GameMap) float64 {
 	centerY := p.Y + float64(p.Height)/2
 	gridY := int(math.Floor(centerY/float64(TileSize) + 0.5))
 	if gridY < 0 {
 		gridY = 0
-	} else if gridY >= MapHeight {
-		gridY = MapHeight - 1
+	} else if gridY >= m.Height {
+		gridY = m.Height - 1
 	}
 	offset := float64(TileSize-p.Height) / 2
 	return float64(gridY*TileSize) + offset
@@ -0,0 +1,70 @@
+package core
+
+// 玩家之间的碰撞（GameRules.PlayerCollision）
+// 只阻挡“新产生”的重叠：已经重叠的两名玩家（如复活/传送后）可以自由分开，避免互相卡死
+
+// overlapsPlayerAt 玩家位于 (x, y) 时是否与 other 的碰撞盒重叠
+func (p *Player) overlapsPlayerAt(x, y float64, other *Player) bool {
+	return x < other.X+float64(other.Width) && x+float64(p.Width) > other.X &&
+		y < other.Y+float64(other.Height) && y+float64(p.Height) > other.Y
+}
+
+// collidesWithPlayer 移动到 (x, y) 是否会撞上其他存活玩家
+func (p *Player) collidesWithPlayer(game *Game, x, y float64) bool {
+	if !game.Rules.PlayerCollision {
+		return false
+	}
+	for _, other := range game.Players {
+		if other == p || other.Dead {
+			continue
+		}
+		if p.overlapsPlayerAt(x, y, other) && !p.overlapsPlayerAt(p.X, p.Y, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// canMoveTo 地图碰撞 + 玩家碰撞
+func (p *Player) canMoveTo(game *Game, x, y float64, bombPositions []struct{ X, Y int }, explosionCells []GridPos) bool {
+	if !game.Map.CanMoveTo(int(x), int(y), p.Width, p.Height, bombPositions, explosionCells) {
+		return false
+	}
+	return !p.collidesWithPlayer(game, x, y)
+}
+
+// resolvePlayerCollision 沿移动方向把目标位置截断到刚好贴住挡路的玩家
+// 返回截断后的位置，完全无法前进时返回 false
+func (p *Player) resolvePlayerCollision(game *Game, dx, dy, newX, newY float64) (float64, float64, bool) {
+	if !game.Rules.PlayerCollision {
+		return newX, newY, true
+	}
+
+	for _, other := range game.Players {
+		if other == p || other.Dead {
+			continue
+		}
+		if !p.overlapsPlayerAt(newX, newY, other) || p.overlapsPlayerAt(p.X, p.Y, other) {
+			continue
+		}
+		switch {
+		case dx > 0:
+			newX = min(newX, other.X-float64(p.Width))
+		case dx < 0:
+			newX = max(newX, other.X+float64(other.Width))
+		case dy > 0:
+			newY = min(newY, other.Y-float64(p.Height))
+		case dy < 0:
+			newY = max(newY, other.Y+float64(other.Height))
+		}
+	}
+
+	// 截断后没有向前推进，或者仍与他人重叠（拐角修正造成的侧向位移），视为被挡住
+	if (dx > 0 && newX <= p.X) || (dx < 0 && newX >= p.X) || (dy > 0 && newY <= p.Y) || (dy < 0 && newY >= p.Y) {
+		return 0, 0, false
+	}
+	if p.collidesWithPlayer(game, newX, newY) {
+		return 0, 0, false
+	}
+	return newX, newY, true
+}
@@ -0,0 +1,66 @@
+package core
+
+// GameRules 房间可选规则（由房主在开始前设置）
+type GameRules struct {
+	DoorCampPing    bool // 门口蹲守提示：站在已露出的门上超过一定时间会向所有人暴露位置
+	PlayerCollision bool // 玩家碰撞：玩家之间不能互相穿过
+	MapHazards      bool // 地图危险区域：按地图定义周期性出现熔岩行/列
+	SuddenDeath     bool // 突然死亡：限时结束前墙壁从外圈向内螺旋落下
+	FairSeed        bool // 公平种子：开始前只公开种子哈希（承诺），开局时揭示种子
+	Teams           bool // 组队模式（2v2）：玩家分为两队，一队全灭且另一队有人进门时该队获胜
+	FriendlyFire    bool // 友军伤害：组队模式下队友的炸弹也会炸死自己（自己的炸弹总是有效）
+	DoorOvertime    bool // 决斗加时：最后两人在门口僵持时，门口竞技场外被淹没，逼两人决斗（组队模式不生效）
+	BossMode        bool // 首领战：所有玩家合作击败地图中央的首领（见 boss.go），门不再是胜利条件
+}
+
+// MapID 地图资源 ID：内置地图 ID 或社区地图名（layout 为空表示默认地图），开启危险区域时加上 +hazards 后缀
+// 统计、录像索引和房间状态都使用它，展示名称见 pkg/resources，客户端用 MapLayout 取回地图
+func MapID(layout string, rules GameRules) string {
+	if layout == "" {
+		layout = MapLayoutDefault
+	}
+	if rules.MapHazards {
+		return layout + mapHazardsSuffix
+	}
+	return layout
+}
+
+// DefaultGameRules 默认规则（全部关闭，保持原有玩法）
+func DefaultGameRules() GameRules {
+	return GameRules{}
+}
+
+// DoorCampPing 门口蹲守提示
+type DoorCampPing struct {
+	PlayerID     int
+	GridX, GridY int
+}
+
+// updateDoorCamping 统计玩家站在门上的帧数，超过阈值后周期性产生位置提示
+// 只在还有其他存活玩家时计数：此时站在门上不会带来任何收益（胜利需要最后一人进门），
+// 提示只是为了让对手找到蹲守者
+func (g *Game) updateDoorCamping() {
+	g.DoorCampPings = g.DoorCampPings[:0]
+	if !g.Rules.DoorCampPing || !g.IsAuthoritative {
+		return
+	}
+
+	alive := g.GetAlivePlayers()
+	for _, p := range alive {
+		gridPos := PlayerXYToGrid(int(p.X), int(p.Y))
+		if len(alive) < 2 || g.Map.GetTile(gridPos.GridX, gridPos.GridY) != TileDoor {
+			p.DoorCampFrames = 0
+			continue
+		}
+
+		p.DoorCampFrames++
+		elapsed := p.DoorCampFrames - DoorCampPingDelayFrames
+		if elapsed >= 0 && elapsed%DoorCampPingIntervalFrames == 0 {
+			g.DoorCampPings = append(g.DoorCampPings, DoorCampPing{
+				PlayerID: p.ID,
+				GridX:    gridPos.GridX,
+				GridY:    gridPos.GridY,
+			})
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package core
+
+// 推人（需要开启 GameRules.PlayerCollision）
+// 把朝向上相邻格子里的对手推开一格，目标格子必须空闲
+
+// DirectionOffset 朝向对应的格子偏移
+func DirectionOffset(dir DirectionType) GridPos {
+	switch dir {
+	case DirUp:
+		return GridPos{GridX: 0, GridY: -1}
+	case DirDown:
+		return GridPos{GridX: 0, GridY: 1}
+	case DirLeft:
+		return GridPos{GridX: -1, GridY: 0}
+	default:
+		return GridPos{GridX: 1, GridY: 0}
+	}
+}
+
+// ShoveTarget 返回朝向上可以被推开的对手及其落点，没有则返回 nil
+func (p *Player) ShoveTarget(game *Game) (*Player, GridPos) {
+	if p.Dead || !game.Rules.PlayerCollision {
+		return nil, GridPos{}
+	}
+
+	gridX, gridY := p.GetGridPosition()
+	offset := DirectionOffset(p.Direction)
+	targetX, targetY := gridX+offset.GridX, gridY+offset.GridY
+
+	for _, other := range game.Players {
+		if other == p || other.Dead {
+			continue
+		}
+		otherX, otherY := other.GetGridPosition()
+		if otherX != targetX || otherY != targetY {
+			continue
+		}
+		dest := GridPos{GridX: targetX + offset.GridX, GridY: targetY + offset.GridY}
+		if !game.isShoveDestinationFree(dest, other) {
+			return nil, GridPos{}
+		}
+		return other, dest
+	}
+	return nil, GridPos{}
+}
+
+// Shove 推开朝向上相邻的对手（返回被推的玩家，失败返回 nil）
+func (p *Player) Shove(game *Game, currentFrame int32) *Player {
+	if p.NextShoveFrame > currentFrame {
+		return nil
+	}
+
+	target, dest := p.ShoveTarget(game)
+	if target == nil {
+		return nil
+	}
+
+	x, y := GridToPlayerXY(dest.GridX, dest.GridY)
+	target.X = float64(x)
+	target.Y = float64(y)
+	target.BombIgnoreActive = false // 已离开原来的格子
+
+	p.NextShoveFrame = currentFrame + ShoveCooldownFrames
+	return target
+}
+
+// isShoveDestinationFree 落点必须在地图内、可通行、没有炸弹且没有其他玩家
+func (g *Game) isShoveDestinationFree(dest GridPos, pushed *Player) bool {
+	if !g.Map.InBounds(dest.GridX, dest.GridY) {
+		return false
+	}
+	tile := g.Map.GetTile(dest.GridX, dest.GridY)
+	if tile == TileWall || tile == TileBrick {
+		return false
+	}
+	for _, bomb := range g.Bombs {
+		if !bomb.Exploded && bomb.GridX == dest.GridX && bomb.GridY == dest.GridY {
+			return false
+		}
+	}
+
+	x, y := GridToPlayerXY(dest.GridX, dest.GridY)
+	for _, other := range g.Players {
+		if other == pushed || other.Dead {
+			continue
+		}
+		if pushed.overlapsPlayerAt(float64(x), float64(y), other) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,27 @@
+package core
+
+// 冲刺：按住冲刺键移动时速度提高到 SprintSpeedMultiplier 倍，每帧消耗体力；
+// 不冲刺时体力逐帧恢复。体力耗尽后要恢复到 SprintMinStamina 才能再次冲刺
+
+// CanSprint 当前体力是否允许冲刺（正在冲刺时只要够本帧消耗即可继续）
+func (p *Player) CanSprint() bool {
+	if p.Stamina < SprintStaminaCost {
+		return false
+	}
+	return p.Sprinting || p.Stamina >= SprintMinStamina
+}
+
+// UpdateSprint 按本帧是否想冲刺更新体力，返回本帧是否冲刺
+// 每次应用输入调用一次，客户端预测重放输入时得到与服务器相同的结果
+func (p *Player) UpdateSprint(want bool) bool {
+	p.Sprinting = want && p.CanSprint()
+	if p.Sprinting {
+		p.Stamina -= SprintStaminaCost
+		return true
+	}
+	p.Stamina += StaminaRegenPerFrame
+	if p.Stamina > StaminaMax {
+		p.Stamina = StaminaMax
+	}
+	return false
+}
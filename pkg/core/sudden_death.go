@@ -0,0 +1,175 @@
+package core
+
+// KillerSuddenDeath 被突然死亡阶段落下的墙压死时的击杀归属
+const KillerSuddenDeath = -3
+
+// suddenDeathSpiral 突然死亡阶段落墙的顺序：从最外圈开始顺时针向内螺旋
+type suddenDeathSpiral struct {
+	order []GridPos // 落墙顺序
+	index [][]int   // 每个格子在 order 中的下标
+}
+
+// suddenDeathSpirals 各种地图尺寸的落墙顺序（启动时算好，各房间只读共享）
+var suddenDeathSpirals = map[[2]int]*suddenDeathSpiral{
+	{MapWidth, MapHeight}:           buildSuddenDeathSpiral(MapWidth, MapHeight),
+	{LargeMapWidth, LargeMapHeight}: buildSuddenDeathSpiral(LargeMapWidth, LargeMapHeight),
+}
+
+// suddenDeathSpiral 当前地图尺寸的落墙顺序
+func (g *Game) suddenDeathSpiral() *suddenDeathSpiral {
+	if spiral, ok := suddenDeathSpirals[[2]int{g.Map.Width, g.Map.Height}]; ok {
+		return spiral
+	}
+	return buildSuddenDeathSpiral(g.Map.Width, g.Map.Height)
+}
+
+func buildSuddenDeathSpiral(width, height int) *suddenDeathSpiral {
+	spiral := &suddenDeathSpiral{
+		order: buildSuddenDeathOrder(width, height),
+		index: make([][]int, height),
+	}
+	for y := range spiral.index {
+		spiral.index[y] = make([]int, width)
+	}
+	for i, pos := range spiral.order {
+		spiral.index[pos.GridY][pos.GridX] = i
+	}
+	return spiral
+}
+
+func buildSuddenDeathOrder(width, height int) []GridPos {
+	order := make([]GridPos, 0, width*height)
+	left, top, right, bottom := 0, 0, width-1, height-1
+	for left <= right && top <= bottom {
+		for x := left; x <= right; x++ {
+			order = append(order, GridPos{GridX: x, GridY: top})
+		}
+		for y := top + 1; y <= bottom; y++ {
+			order = append(order, GridPos{GridX: right, GridY: y})
+		}
+		if top < bottom {
+			for x := right - 1; x >= left; x-- {
+				order = append(order, GridPos{GridX: x, GridY: bottom})
+			}
+		}
+		if left < right {
+			for y := bottom - 1; y > top; y-- {
+				order = append(order, GridPos{GridX: left, GridY: y})
+			}
+		}
+		left, top, right, bottom = left+1, top+1, right-1, bottom-1
+	}
+	return order
+}
+
+// suddenDeathActive 突然死亡是否已开启（规则打开且设置了开始帧）
+func (g *Game) suddenDeathActive() bool {
+	return g.Rules.SuddenDeath && g.SuddenDeathStartFrame > 0
+}
+
+// suddenDeathDropFrame 螺旋顺序中第 i 个格子落墙的帧号
+func (g *Game) suddenDeathDropFrame(i int) int32 {
+	return g.SuddenDeathStartFrame + int32(i)*SuddenDeathIntervalFrames
+}
+
+// suddenDeathTarget 会被落墙覆盖的格子（已经是墙或者是门时跳过）
+func (g *Game) suddenDeathTarget(pos GridPos) bool {
+	if g.Map.GetTile(pos.GridX, pos.GridY) == TileWall {
+		return false
+	}
+	door := g.Map.HiddenDoorPos
+	return pos.GridX != door.X || pos.GridY != door.Y
+}
+
+// RemainingMatchFrames 距离限时结束的帧数（不限时返回 -1）
+func (g *Game) RemainingMatchFrames() int32 {
+	if g.MatchEndFrame <= 0 {
+		return -1
+	}
+	return max(g.MatchEndFrame-g.CurrentFrame, 0)
+}
+
+// SuddenDeathDropFrame 格子被突然死亡落墙覆盖的帧号（未开启、越界、门或已是墙时返回 0）
+func (g *Game) SuddenDeathDropFrame(pos GridPos) int32 {
+	if !g.suddenDeathActive() || !g.Map.InBounds(pos.GridX, pos.GridY) {
+		return 0
+	}
+	if !g.suddenDeathTarget(pos) {
+		return 0
+	}
+	return g.suddenDeathDropFrame(g.suddenDeathSpiral().index[pos.GridY][pos.GridX])
+}
+
+// SuddenDeathWarnings 接下来 SuddenDeathWarningFrames 帧内将要落墙的格子
+func (g *Game) SuddenDeathWarnings() []GridPos {
+	if !g.suddenDeathActive() {
+		return nil
+	}
+	var cells []GridPos
+	for i, pos := range g.suddenDeathSpiral().order {
+		drop := g.suddenDeathDropFrame(i)
+		if drop <= g.CurrentFrame {
+			continue
+		}
+		if drop > g.CurrentFrame+SuddenDeathWarningFrames {
+			break
+		}
+		if g.suddenDeathTarget(pos) {
+			cells = append(cells, pos)
+		}
+	}
+	return cells
+}
+
+// updateSuddenDeath 按顺序落墙：压死格子上的玩家，移除格子上的炸弹和道具
+func (g *Game) updateSuddenDeath() {
+	g.SuddenDeathChanges = g.SuddenDeathChanges[:0]
+	if !g.suddenDeathActive() || !g.IsAuthoritative {
+		return
+	}
+	elapsed := g.CurrentFrame - g.SuddenDeathStartFrame
+	if elapsed < 0 || elapsed%SuddenDeathIntervalFrames != 0 {
+		return
+	}
+	i := int(elapsed / SuddenDeathIntervalFrames)
+	order := g.suddenDeathSpiral().order
+	if i >= len(order) {
+		return
+	}
+	pos := order[i]
+	if !g.suddenDeathTarget(pos) {
+		return
+	}
+
+	old := g.Map.GetTile(pos.GridX, pos.GridY)
+	g.Map.SetTile(pos.GridX, pos.GridY, TileWall)
+	g.SuddenDeathChanges = append(g.SuddenDeathChanges, TileChange{
+		GridX:   pos.GridX,
+		GridY:   pos.GridY,
+		OldType: old,
+		NewType: TileWall,
+	})
+
+	for _, player := range g.Players {
+		if !player.Dead && PlayerXYToGrid(int(player.X), int(player.Y)) == pos {
+			player.Dead = true
+			player.KillerID = KillerSuddenDeath
+		}
+	}
+
+	bombs := g.Bombs[:0]
+	for _, bomb := range g.Bombs {
+		if bomb.GridX != pos.GridX || bomb.GridY != pos.GridY {
+			bombs = append(bombs, bomb)
+		}
+	}
+	g.Bombs = bombs
+
+	items := g.Items[:0]
+	for _, item := range g.Items {
+		if item.GridX != pos.GridX || item.GridY != pos.GridY {
+			items = append(items, item)
+		}
+	}
+	g.Items = items
+}
@@ -0,0 +1,69 @@
+package core
+
+// 队伍编号（GameRules.Teams）
+const (
+	TeamNone = 0 // 不分队
+	TeamA    = 1
+	TeamB    = 2
+)
+
+// TeamCount 组队模式的队伍数
+const TeamCount = 2
+
+// protectedFromFriendlyFire 组队模式且关闭友军伤害时，队友炸弹产生的爆炸不伤害玩家；
+// 首领战所有玩家是一伙的，只有自己和首领的爆炸有效。
+// 自己的炸弹仍然有效，避免躲在自己炸弹旁边无敌
+func (g *Game) protectedFromFriendlyFire(player *Player, explosion *Explosion) bool {
+	if g.Rules.BossMode && g.Boss != nil {
+		return explosion.CreditID != player.ID && explosion.CreditID != KillerBoss
+	}
+	if !g.Rules.Teams || g.Rules.FriendlyFire || player.Team == TeamNone {
+		return false
+	}
+	if explosion.CreditID == player.ID {
+		return false
+	}
+	credit := g.GetPlayer(explosion.CreditID)
+	return credit != nil && credit.Team == player.Team
+}
+
+// isTeamGameOver 组队模式的结束条件：所有人死亡，或存活者同属一队且其中有人站在门上
+func (g *Game) isTeamGameOver() bool {
+	alive := g.GetAlivePlayers()
+	if len(alive) == 0 {
+		return true
+	}
+	if g.WinningTeam() == TeamNone {
+		return false
+	}
+	return g.DoorPlayer() != nil
+}
+
+// WinningTeam 存活者全部属于同一队时返回该队，否则返回 TeamNone（未分出胜负或全员死亡）
+func (g *Game) WinningTeam() int {
+	team := TeamNone
+	for _, p := range g.Players {
+		if p.Dead {
+			continue
+		}
+		if team != TeamNone && p.Team != team {
+			return TeamNone
+		}
+		team = p.Team
+	}
+	return team
+}
+
+// DoorPlayer 站在门上的存活玩家（有多人时返回第一个），没有时返回 nil
+func (g *Game) DoorPlayer() *Player {
+	for _, p := range g.Players {
+		if p.Dead {
+			continue
+		}
+		gridPos := PlayerXYToGrid(int(p.X), int(p.Y))
+		if g.Map.GetTile(gridPos.GridX, gridPos.GridY) == TileDoor {
+			return p
+		}
+	}
+	return nil
+}
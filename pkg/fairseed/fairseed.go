@@ -0,0 +1,47 @@
+// Package fairseed 地图种子的承诺-揭示（commit-reveal）
+//
+// 开始前服务器只公开 SHA-256(盐 || 种子)，开局时再公开种子和盐，
+// 客户端重新计算哈希即可确认地图在所有人准备之后没有被换过。
+package fairseed
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// SaltSize 盐的字节数（防止穷举种子反推）
+const SaltSize = 16
+
+// Commitment 一次种子承诺
+type Commitment struct {
+	Seed int64
+	Salt []byte
+	Hash []byte // SHA-256(Salt || Seed 大端序)
+}
+
+// Commit 为种子生成随机盐并计算承诺
+func Commit(seed int64) Commitment {
+	salt := make([]byte, SaltSize)
+	rand.Read(salt) // crypto/rand.Read 不会返回错误
+	return Commitment{Seed: seed, Salt: salt, Hash: Hash(seed, salt)}
+}
+
+// Hash 计算 SHA-256(盐 || 种子)
+func Hash(seed int64, salt []byte) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(seed))
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(buf[:])
+	return h.Sum(nil)
+}
+
+// Verify 检查公开的种子和盐是否与之前的承诺一致
+func Verify(hash []byte, seed int64, salt []byte) bool {
+	if len(hash) != sha256.Size || len(salt) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hash, Hash(seed, salt)) == 1
+}
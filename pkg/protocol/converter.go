@@ -77,6 +77,13 @@ func CorePlayerToProto(p *core.Player) *gamev1.PlayerState {
 		NextPlacementFrame: int32(p.NextPlacementFrame),
 		CurrentBombs:       0, // 核心中不跟踪当前炸弹数，由 Game 层管理
 		MaxBombs:           int32(p.MaxBombs),
+		BombRange:          int32(p.BombRange),
+		Speed:              p.Speed,
+		Team:               int32(p.Team),
+		CanKick:            p.CanKick,
+		Stamina:            int32(p.Stamina),
+		Sprinting:          p.Sprinting,
+		Kills:              int32(p.Kills),
 	}
 }
 
@@ -93,8 +100,19 @@ func ProtoPlayerToCore(p *gamev1.PlayerState) *core.Player {
 	player.Direction = ProtoDirectionToCore(p.Direction)
 	player.IsMoving = p.IsMoving
 	player.Dead = p.Dead
+	player.Team = int(p.Team)
+	player.CanKick = p.CanKick
+	player.Stamina = int(p.Stamina)
+	player.Sprinting = p.Sprinting
+	player.Kills = int(p.Kills)
 	player.NextPlacementFrame = int32(p.NextPlacementFrame)
 	player.MaxBombs = int(p.MaxBombs)
+	if p.BombRange > 0 {
+		player.BombRange = int(p.BombRange)
+	}
+	if p.Speed > 0 {
+		player.Speed = p.Speed
+	}
 	return player
 }
 
@@ -102,19 +120,24 @@ func ProtoPlayerToCore(p *gamev1.PlayerState) *core.Player {
 
 // CoreBombToProto 将 core.Bomb 转换为 gamev1.BombState
 // 关键：直接使用帧，不再转换为毫秒！
-func CoreBombToProto(b *core.Bomb, id int32) *gamev1.BombState {
+func CoreBombToProto(b *core.Bomb) *gamev1.BombState {
 	if b == nil {
 		return nil
 	}
 
 	return &gamev1.BombState{
-		Id:             id,
+		Id:             b.ID,
 		GridX:          int32(b.GridX),
 		GridY:          int32(b.GridY),
 		ExplodeAtFrame: b.ExplodeAtFrame,
 		ExplosionRange: int32(b.ExplosionRange),
 		OwnerId:        int32(b.OwnerID),
 		PlacedAtFrame:  b.PlacedAtFrame,
+		TouchChain:     intsToInt32s(b.TouchChain),
+		OffsetX:        b.OffsetX,
+		OffsetY:        b.OffsetY,
+		VelX:           b.VelX,
+		VelY:           b.VelY,
 	}
 }
 
@@ -126,12 +149,18 @@ func ProtoBombToCore(b *gamev1.BombState) *core.Bomb {
 	}
 
 	return &core.Bomb{
+		ID:             b.Id,
 		GridX:          int(b.GridX),
 		GridY:          int(b.GridY),
 		ExplodeAtFrame: b.ExplodeAtFrame,
 		PlacedAtFrame:  b.PlacedAtFrame,
 		ExplosionRange: int(b.ExplosionRange),
 		OwnerID:        int(b.OwnerId),
+		TouchChain:     int32sToInts(b.TouchChain),
+		VelX:           b.VelX,
+		VelY:           b.VelY,
+		OffsetX:        b.OffsetX,
+		OffsetY:        b.OffsetY,
 		Exploded:       false,
 	}
 }
@@ -140,7 +169,7 @@ func ProtoBombToCore(b *gamev1.BombState) *core.Bomb {
 
 // CoreExplosionToProto 将 core.Explosion 转换为 gamev1.ExplosionState
 // 关键：直接使用帧，不再转换为毫秒！
-func CoreExplosionToProto(e *core.Explosion, id int32) *gamev1.ExplosionState {
+func CoreExplosionToProto(e *core.Explosion) *gamev1.ExplosionState {
 	if e == nil {
 		return nil
 	}
@@ -154,10 +183,11 @@ func CoreExplosionToProto(e *core.Explosion, id int32) *gamev1.ExplosionState {
 	}
 
 	return &gamev1.ExplosionState{
-		Id:             id,
+		Id:             e.ID,
 		Cells:          cells,
 		ExpiresAtFrame: e.ExpiresAtFrame,
 		CreatedAtFrame: e.CreatedAtFrame,
+		CreditId:       int32(e.CreditID),
 	}
 }
 
@@ -174,11 +204,147 @@ func ProtoExplosionToCore(e *gamev1.ExplosionState) *core.Explosion {
 	}
 
 	return &core.Explosion{
+		ID:             e.Id,
 		Cells:          cells,
 		ExpiresAtFrame: e.ExpiresAtFrame,
 		CreatedAtFrame: e.CreatedAtFrame,
 		OwnerID:        0, // 从 proto 无法获取
+		CreditID:       int(e.CreditId),
+	}
+}
+
+// CoreHazardsToProto 将 core.HazardOverlay 列表转换为 gamev1.HazardState 列表
+func CoreHazardsToProto(overlays []core.HazardOverlay) []*gamev1.HazardState {
+	if len(overlays) == 0 {
+		return nil
+	}
+
+	result := make([]*gamev1.HazardState, 0, len(overlays))
+	for _, o := range overlays {
+		cells := make([]*gamev1.GridCell, len(o.Cells))
+		for i, cell := range o.Cells {
+			cells[i] = &gamev1.GridCell{X: int32(cell.GridX), Y: int32(cell.GridY)}
+		}
+		result = append(result, &gamev1.HazardState{
+			Cells:    cells,
+			Active:   o.Active,
+			EndFrame: o.EndFrame,
+		})
+	}
+	return result
+}
+
+// ProtoHazardsToCore 将 gamev1.HazardState 列表转换为 core.HazardOverlay 列表
+func ProtoHazardsToCore(hazards []*gamev1.HazardState) []core.HazardOverlay {
+	if len(hazards) == 0 {
+		return nil
+	}
+
+	result := make([]core.HazardOverlay, 0, len(hazards))
+	for _, h := range hazards {
+		cells := make([]core.GridPos, len(h.Cells))
+		for i, cell := range h.Cells {
+			cells[i] = core.GridPos{GridX: int(cell.X), GridY: int(cell.Y)}
+		}
+		result = append(result, core.HazardOverlay{
+			Cells:    cells,
+			Active:   h.Active,
+			EndFrame: h.EndFrame,
+		})
+	}
+	return result
+}
+
+// CoreBossToProto 将 core.Boss 转换为 gamev1.BossState（nil 返回 nil）
+func CoreBossToProto(b *core.Boss) *gamev1.BossState {
+	if b == nil {
+		return nil
 	}
+	return &gamev1.BossState{
+		GridX:          int32(b.GridX),
+		GridY:          int32(b.GridY),
+		Hp:             int32(b.HP),
+		MaxHp:          int32(b.MaxHP),
+		Direction:      CoreDirectionToProto(b.Direction),
+		HurtUntilFrame: b.HurtUntilFrame,
+		Attack:         gamev1.BossAttack(b.Attack),
+		AttackFrame:    b.AttackFrame,
+	}
+}
+
+// ProtoBossToCore 将 gamev1.BossState 转换为 core.Boss（nil 返回 nil）
+func ProtoBossToCore(b *gamev1.BossState) *core.Boss {
+	if b == nil {
+		return nil
+	}
+	return &core.Boss{
+		GridX:          int(b.GridX),
+		GridY:          int(b.GridY),
+		HP:             int(b.Hp),
+		MaxHP:          int(b.MaxHp),
+		Direction:      ProtoDirectionToCore(b.Direction),
+		HurtUntilFrame: b.HurtUntilFrame,
+		Attack:         core.BossAttack(b.Attack),
+		AttackFrame:    b.AttackFrame,
+	}
+}
+
+// CoreChecksumToProto 将 core.StateChecksum 转换为 gamev1.StateChecksum
+func CoreChecksumToProto(c core.StateChecksum) *gamev1.StateChecksum {
+	return &gamev1.StateChecksum{Map: c.Map, Bombs: c.Bombs, Players: c.Players}
+}
+
+// ProtoChecksumToCore 将 gamev1.StateChecksum 转换为 core.StateChecksum
+func ProtoChecksumToCore(c *gamev1.StateChecksum) core.StateChecksum {
+	return core.StateChecksum{Map: c.GetMap(), Bombs: c.GetBombs(), Players: c.GetPlayers()}
+}
+
+// CoreGridCellsToProto 将 core.GridPos 列表转换为 gamev1.GridCell 列表
+func CoreGridCellsToProto(cells []core.GridPos) []*gamev1.GridCell {
+	if len(cells) == 0 {
+		return nil
+	}
+
+	result := make([]*gamev1.GridCell, len(cells))
+	for i, cell := range cells {
+		result[i] = &gamev1.GridCell{X: int32(cell.GridX), Y: int32(cell.GridY)}
+	}
+	return result
+}
+
+// ProtoGridCellsToCore 将 gamev1.GridCell 列表转换为 core.GridPos 列表
+func ProtoGridCellsToCore(cells []*gamev1.GridCell) []core.GridPos {
+	if len(cells) == 0 {
+		return nil
+	}
+
+	result := make([]core.GridPos, len(cells))
+	for i, cell := range cells {
+		result[i] = core.GridPos{GridX: int(cell.X), GridY: int(cell.Y)}
+	}
+	return result
+}
+
+func intsToInt32s(values []int) []int32 {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]int32, len(values))
+	for i, v := range values {
+		out[i] = int32(v)
+	}
+	return out
+}
+
+func int32sToInts(values []int32) []int {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]int, len(values))
+	for i, v := range values {
+		out[i] = int(v)
+	}
+	return out
 }
 
 // ========== TileChange 转换 ==========
@@ -224,6 +390,207 @@ func CoreTileChangeToProto(tc core.TileChange) *gamev1.TileChange {
 	}
 }
 
+// ========== Item 转换 ==========
+
+// CoreItemTypeToProto 将 core.ItemType 转换为 gamev1.ItemType
+// Core: BombUp=0, FireUp=1, SpeedUp=2, Kick=3
+// Proto: BOMB_UP=1, FIRE_UP=2, SPEED_UP=3, KICK=4
+func CoreItemTypeToProto(itemType core.ItemType) gamev1.ItemType {
+	switch itemType {
+	case core.ItemBombUp:
+		return gamev1.ItemType_ITEM_TYPE_BOMB_UP
+	case core.ItemFireUp:
+		return gamev1.ItemType_ITEM_TYPE_FIRE_UP
+	case core.ItemSpeedUp:
+		return gamev1.ItemType_ITEM_TYPE_SPEED_UP
+	case core.ItemKick:
+		return gamev1.ItemType_ITEM_TYPE_KICK
+	default:
+		return gamev1.ItemType_ITEM_TYPE_UNSPECIFIED
+	}
+}
+
+// ProtoItemTypeToCore 将 gamev1.ItemType 转换为 core.ItemType
+func ProtoItemTypeToCore(itemType gamev1.ItemType) core.ItemType {
+	switch itemType {
+	case gamev1.ItemType_ITEM_TYPE_FIRE_UP:
+		return core.ItemFireUp
+	case gamev1.ItemType_ITEM_TYPE_SPEED_UP:
+		return core.ItemSpeedUp
+	case gamev1.ItemType_ITEM_TYPE_KICK:
+		return core.ItemKick
+	default:
+		return core.ItemBombUp // 默认炸弹数道具
+	}
+}
+
+// CoreItemToProto 将 core.Item 转换为 gamev1.ItemState
+func CoreItemToProto(item *core.Item) *gamev1.ItemState {
+	if item == nil {
+		return nil
+	}
+
+	return &gamev1.ItemState{
+		GridX:          int32(item.GridX),
+		GridY:          int32(item.GridY),
+		Type:           CoreItemTypeToProto(item.Type),
+		SpawnedAtFrame: item.SpawnedAtFrame,
+	}
+}
+
+// ProtoItemToCore 将 gamev1.ItemState 转换为 core.Item
+func ProtoItemToCore(item *gamev1.ItemState) *core.Item {
+	if item == nil {
+		return nil
+	}
+
+	return &core.Item{
+		GridX:          int(item.GridX),
+		GridY:          int(item.GridY),
+		Type:           ProtoItemTypeToCore(item.Type),
+		SpawnedAtFrame: item.SpawnedAtFrame,
+	}
+}
+
+// ========== Effect 转换 ==========
+
+// CoreEffectTypeToProto 将 core.EffectType 转换为 gamev1.EffectType
+// Core: SpeedBoost=0, Slow=1, Shield=2
+// Proto: SPEED_BOOST=1, SLOW=2, SHIELD=3
+func CoreEffectTypeToProto(effectType core.EffectType) gamev1.EffectType {
+	switch effectType {
+	case core.EffectSpeedBoost:
+		return gamev1.EffectType_EFFECT_TYPE_SPEED_BOOST
+	case core.EffectSlow:
+		return gamev1.EffectType_EFFECT_TYPE_SLOW
+	case core.EffectShield:
+		return gamev1.EffectType_EFFECT_TYPE_SHIELD
+	default:
+		return gamev1.EffectType_EFFECT_TYPE_UNSPECIFIED
+	}
+}
+
+// ProtoEffectTypeToCore 将 gamev1.EffectType 转换为 core.EffectType
+func ProtoEffectTypeToCore(effectType gamev1.EffectType) core.EffectType {
+	switch effectType {
+	case gamev1.EffectType_EFFECT_TYPE_SLOW:
+		return core.EffectSlow
+	case gamev1.EffectType_EFFECT_TYPE_SHIELD:
+		return core.EffectShield
+	default:
+		return core.EffectSpeedBoost // 默认加速
+	}
+}
+
+// CoreEffectToProto 将 core.Effect 转换为 gamev1.PlayerEffect
+// 网络上传输剩余帧数，避免依赖双方帧号对齐
+func CoreEffectToProto(e core.Effect, currentFrame int32) *gamev1.PlayerEffect {
+	return &gamev1.PlayerEffect{
+		Type:            CoreEffectTypeToProto(e.Type),
+		RemainingFrames: e.RemainingFrames(currentFrame),
+	}
+}
+
+// ProtoEffectToCore 将 gamev1.PlayerEffect 转换为 core.Effect
+func ProtoEffectToCore(e *gamev1.PlayerEffect, currentFrame int32) core.Effect {
+	effect := core.Effect{Type: ProtoEffectTypeToCore(e.Type)}
+	if e.RemainingFrames >= 0 {
+		effect.ExpiresAtFrame = currentFrame + e.RemainingFrames
+	}
+	return effect
+}
+
+// CorePlayerEffectsToProto 将玩家当前效果转换为 gamev1.PlayerEffects
+func CorePlayerEffectsToProto(p *core.Player, currentFrame int32) *gamev1.PlayerEffects {
+	if p == nil {
+		return nil
+	}
+
+	effects := make([]*gamev1.PlayerEffect, len(p.Effects))
+	for i, e := range p.Effects {
+		effects[i] = CoreEffectToProto(e, currentFrame)
+	}
+	return &gamev1.PlayerEffects{
+		PlayerId: int32(p.ID),
+		Effects:  effects,
+	}
+}
+
+// ProtoPlayerEffectsToCore 将 gamev1.PlayerEffects 转换为 core.Effect 列表
+func ProtoPlayerEffectsToCore(pe *gamev1.PlayerEffects, currentFrame int32) []core.Effect {
+	if pe == nil {
+		return nil
+	}
+
+	effects := make([]core.Effect, 0, len(pe.Effects))
+	for _, e := range pe.Effects {
+		if e != nil {
+			effects = append(effects, ProtoEffectToCore(e, currentFrame))
+		}
+	}
+	return effects
+}
+
+// CoreRulesToProto 将 core.GameRules 转换为 gamev1.RoomRules
+func CoreRulesToProto(rules core.GameRules) *gamev1.RoomRules {
+	return &gamev1.RoomRules{
+		DoorCampPing:    rules.DoorCampPing,
+		PlayerCollision: rules.PlayerCollision,
+		MapHazards:      rules.MapHazards,
+		SuddenDeath:     rules.SuddenDeath,
+		FairSeed:        rules.FairSeed,
+		Teams:           rules.Teams,
+		FriendlyFire:    rules.FriendlyFire,
+		DoorOvertime:    rules.DoorOvertime,
+		BossMode:        rules.BossMode,
+	}
+}
+
+// ProtoRulesToCore 将 gamev1.RoomRules 转换为 core.GameRules（nil 返回默认规则）
+func ProtoRulesToCore(rules *gamev1.RoomRules) core.GameRules {
+	if rules == nil {
+		return core.DefaultGameRules()
+	}
+	return core.GameRules{
+		DoorCampPing:    rules.DoorCampPing,
+		PlayerCollision: rules.PlayerCollision,
+		MapHazards:      rules.MapHazards,
+		SuddenDeath:     rules.SuddenDeath,
+		FairSeed:        rules.FairSeed,
+		Teams:           rules.Teams,
+		FriendlyFire:    rules.FriendlyFire,
+		DoorOvertime:    rules.DoorOvertime,
+		BossMode:        rules.BossMode,
+	}
+}
+
+// CoreMatchConfigToProto 将 core.MatchConfig 转换为 gamev1.MatchConfig
+func CoreMatchConfigToProto(config core.MatchConfig) *gamev1.MatchConfig {
+	return &gamev1.MatchConfig{
+		BombFuseFrames:      config.BombFuseFrames,
+		PlayerSpeed:         config.PlayerSpeed,
+		BombRange:           int32(config.BombRange),
+		MaxBombs:            int32(config.MaxBombs),
+		MatchDurationFrames: config.MatchDurationFrames,
+		RoundsToWin:         config.RoundsToWin,
+	}
+}
+
+// ProtoMatchConfigToCore 将 gamev1.MatchConfig 转换为 core.MatchConfig（nil 返回默认参数，超出范围的值会被修正）
+func ProtoMatchConfigToCore(config *gamev1.MatchConfig) core.MatchConfig {
+	if config == nil {
+		return core.DefaultMatchConfig()
+	}
+	return core.MatchConfig{
+		BombFuseFrames:      config.BombFuseFrames,
+		PlayerSpeed:         config.PlayerSpeed,
+		BombRange:           int(config.BombRange),
+		MaxBombs:            int(config.MaxBombs),
+		MatchDurationFrames: config.MatchDurationFrames,
+		RoundsToWin:         config.RoundsToWin,
+	}.Clamp()
+}
+
 // ========== 批量转换辅助函数 ==========
 
 // CorePlayersToProto 批量转换 Player 列表
@@ -248,9 +615,9 @@ func CoreBombsToProto(bombs []*core.Bomb) []*gamev1.BombState {
 	}
 
 	protoBombs := make([]*gamev1.BombState, 0, len(bombs))
-	for i, b := range bombs {
+	for _, b := range bombs {
 		if b != nil {
-			protoBombs = append(protoBombs, CoreBombToProto(b, int32(i)))
+			protoBombs = append(protoBombs, CoreBombToProto(b))
 		}
 	}
 	return protoBombs
@@ -263,14 +630,40 @@ func CoreExplosionsToProto(explosions []*core.Explosion) []*gamev1.ExplosionStat
 	}
 
 	protoExplosions := make([]*gamev1.ExplosionState, 0, len(explosions))
-	for i, e := range explosions {
+	for _, e := range explosions {
 		if e != nil {
-			protoExplosions = append(protoExplosions, CoreExplosionToProto(e, int32(i)))
+			protoExplosions = append(protoExplosions, CoreExplosionToProto(e))
 		}
 	}
 	return protoExplosions
 }
 
+// CoreItemsToProto 批量转换 Item 列表
+func CoreItemsToProto(items []*core.Item) []*gamev1.ItemState {
+	if items == nil {
+		return nil
+	}
+
+	protoItems := make([]*gamev1.ItemState, 0, len(items))
+	for _, item := range items {
+		if item != nil {
+			protoItems = append(protoItems, CoreItemToProto(item))
+		}
+	}
+	return protoItems
+}
+
+// CorePlayersEffectsToProto 批量转换玩家效果（只包含有效果的玩家）
+func CorePlayersEffectsToProto(players []*core.Player, currentFrame int32) []*gamev1.PlayerEffects {
+	var result []*gamev1.PlayerEffects
+	for _, p := range players {
+		if p != nil && len(p.Effects) > 0 {
+			result = append(result, CorePlayerEffectsToProto(p, currentFrame))
+		}
+	}
+	return result
+}
+
 // CoreTileChangesToProto 批量转换 TileChange 列表
 func CoreTileChangesToProto(changes []core.TileChange) []*gamev1.TileChange {
 	if changes == nil {
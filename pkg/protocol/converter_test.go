@@ -0,0 +1,195 @@
+package protocol
+
+import (
+	"reflect"
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// 转换器往返测试：core 状态转换为 proto 再转换回来，同步的字段必须保持不变
+
+func TestItemTypeRoundTrip(t *testing.T) {
+	for _, itemType := range []core.ItemType{core.ItemBombUp, core.ItemFireUp, core.ItemSpeedUp, core.ItemKick} {
+		pb := CoreItemTypeToProto(itemType)
+		if pb == gamev1.ItemType_ITEM_TYPE_UNSPECIFIED {
+			t.Errorf("道具类型 %v 没有对应的 proto 值", itemType)
+		}
+		if got := ProtoItemTypeToCore(pb); got != itemType {
+			t.Errorf("道具类型往返: %v -> %v -> %v", itemType, pb, got)
+		}
+	}
+}
+
+func TestEffectTypeRoundTrip(t *testing.T) {
+	for _, effectType := range []core.EffectType{core.EffectSpeedBoost, core.EffectSlow, core.EffectShield} {
+		pb := CoreEffectTypeToProto(effectType)
+		if pb == gamev1.EffectType_EFFECT_TYPE_UNSPECIFIED {
+			t.Errorf("效果类型 %v 没有对应的 proto 值", effectType)
+		}
+		if got := ProtoEffectTypeToCore(pb); got != effectType {
+			t.Errorf("效果类型往返: %v -> %v -> %v", effectType, pb, got)
+		}
+	}
+}
+
+func TestItemRoundTrip(t *testing.T) {
+	item := &core.Item{GridX: 7, GridY: 3, Type: core.ItemKick, SpawnedAtFrame: 420}
+	if got := ProtoItemToCore(CoreItemToProto(item)); !reflect.DeepEqual(got, item) {
+		t.Errorf("道具往返: 得到 %+v，期望 %+v", got, item)
+	}
+	if CoreItemToProto(nil) != nil || ProtoItemToCore(nil) != nil {
+		t.Error("nil 道具应转换为 nil")
+	}
+}
+
+func TestPlayerEffectsRoundTrip(t *testing.T) {
+	// 剩余帧数在两端帧号不同时也能还原出相同的剩余时间；永久效果保持永久
+	player := core.NewPlayer(2, 64, 96, core.CharacterRed)
+	player.Effects = []core.Effect{
+		{Type: core.EffectSpeedBoost, ExpiresAtFrame: 700},
+		{Type: core.EffectShield, ExpiresAtFrame: 0},
+	}
+	pb := CorePlayerEffectsToProto(player, 500)
+	if pb.PlayerId != 2 {
+		t.Fatalf("PlayerId = %d，期望 2", pb.PlayerId)
+	}
+
+	got := ProtoPlayerEffectsToCore(pb, 510)
+	want := []core.Effect{
+		{Type: core.EffectSpeedBoost, ExpiresAtFrame: 710},
+		{Type: core.EffectShield, ExpiresAtFrame: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("效果往返: 得到 %+v，期望 %+v", got, want)
+	}
+	for i := range got {
+		if got[i].RemainingFrames(510) != player.Effects[i].RemainingFrames(500) {
+			t.Errorf("效果 %d 剩余帧数 %d，期望 %d", i, got[i].RemainingFrames(510), player.Effects[i].RemainingFrames(500))
+		}
+	}
+
+	if effects := CorePlayersEffectsToProto([]*core.Player{core.NewPlayer(1, 0, 0, core.CharacterWhite), player}, 500); len(effects) != 1 {
+		t.Errorf("只应包含有效果的玩家，得到 %d 条", len(effects))
+	}
+}
+
+func TestPlayerRoundTrip(t *testing.T) {
+	player := core.NewPlayer(3, 100, 200, core.CharacterBlue)
+	player.X = 101.5
+	player.Y = 199.25
+	player.Direction = core.DirLeft
+	player.IsMoving = true
+	player.Dead = true
+	player.NextPlacementFrame = 321
+	player.MaxBombs = 4
+	player.BombRange = 5
+	player.Speed = 2.5
+	player.Team = 1
+	player.CanKick = true
+	player.Stamina = 17
+	player.Sprinting = true
+	player.Kills = 2
+
+	if got := ProtoPlayerToCore(CorePlayerToProto(player)); !reflect.DeepEqual(got, player) {
+		t.Errorf("玩家往返:\n得到 %+v\n期望 %+v", got, player)
+	}
+}
+
+func TestBombRoundTrip(t *testing.T) {
+	bomb := &core.Bomb{
+		ID:             7,
+		GridX:          4,
+		GridY:          9,
+		ExplodeAtFrame: 300,
+		PlacedAtFrame:  120,
+		ExplosionRange: 3,
+		OwnerID:        2,
+		TouchChain:     []int{1, 4},
+		VelX:           1.5,
+		OffsetX:        6,
+	}
+	if got := ProtoBombToCore(CoreBombToProto(bomb)); !reflect.DeepEqual(got, bomb) {
+		t.Errorf("炸弹往返:\n得到 %+v\n期望 %+v", got, bomb)
+	}
+}
+
+func TestExplosionRoundTrip(t *testing.T) {
+	explosion := &core.Explosion{
+		ID:             1,
+		Cells:          []core.GridPos{{GridX: 2, GridY: 2}, {GridX: 3, GridY: 2}},
+		ExpiresAtFrame: 250,
+		CreatedAtFrame: 220,
+		CreditID:       4,
+	}
+	if got := ProtoExplosionToCore(CoreExplosionToProto(explosion)); !reflect.DeepEqual(got, explosion) {
+		t.Errorf("爆炸往返:\n得到 %+v\n期望 %+v", got, explosion)
+	}
+}
+
+func TestHazardsRoundTrip(t *testing.T) {
+	hazards := []core.HazardOverlay{
+		{Cells: []core.GridPos{{GridX: 1, GridY: 7}, {GridX: 2, GridY: 7}}, Active: true, EndFrame: 900},
+		{Cells: []core.GridPos{{GridX: 10, GridY: 1}}, EndFrame: 1200},
+	}
+	if got := ProtoHazardsToCore(CoreHazardsToProto(hazards)); !reflect.DeepEqual(got, hazards) {
+		t.Errorf("危险区域往返:\n得到 %+v\n期望 %+v", got, hazards)
+	}
+}
+
+func TestBossRoundTrip(t *testing.T) {
+	boss := core.NewBoss(9, 6)
+	boss.HP = 4
+	boss.Direction = core.DirRight
+	boss.HurtUntilFrame = 80
+	boss.Attack = core.BossAttackFire
+	boss.AttackFrame = 95
+	if got := ProtoBossToCore(CoreBossToProto(boss)); !reflect.DeepEqual(got, boss) {
+		t.Errorf("首领往返:\n得到 %+v\n期望 %+v", got, boss)
+	}
+	if CoreBossToProto(nil) != nil || ProtoBossToCore(nil) != nil {
+		t.Error("nil 首领应转换为 nil")
+	}
+}
+
+func TestChecksumRoundTrip(t *testing.T) {
+	checksum := core.StateChecksum{Map: 0xdeadbeef, Bombs: 17, Players: 0x80000001}
+	if got := ProtoChecksumToCore(CoreChecksumToProto(checksum)); got != checksum {
+		t.Errorf("校验和往返: 得到 %+v，期望 %+v", got, checksum)
+	}
+}
+
+func TestRulesAndMatchConfigRoundTrip(t *testing.T) {
+	rules := core.GameRules{
+		DoorCampPing:    true,
+		PlayerCollision: true,
+		MapHazards:      true,
+		SuddenDeath:     true,
+		FairSeed:        true,
+		Teams:           true,
+		FriendlyFire:    true,
+		DoorOvertime:    true,
+		BossMode:        true,
+	}
+	if got := ProtoRulesToCore(CoreRulesToProto(rules)); got != rules {
+		t.Errorf("规则往返: 得到 %+v，期望 %+v", got, rules)
+	}
+	if got := ProtoRulesToCore(nil); got != core.DefaultGameRules() {
+		t.Errorf("nil 规则应返回默认规则，得到 %+v", got)
+	}
+
+	config := core.DefaultMatchConfig()
+	config.RoundsToWin = 3
+	if got := ProtoMatchConfigToCore(CoreMatchConfigToProto(config)); got != config {
+		t.Errorf("对局参数往返: 得到 %+v，期望 %+v", got, config)
+	}
+}
+
+func TestGamePhaseRoundTrip(t *testing.T) {
+	for state := 0; state <= 3; state++ {
+		if got := ProtoGamePhaseToCore(CoreGameStateToProto(state)); got != state {
+			t.Errorf("阶段往返: %d -> %d", state, got)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package protocol
+
+import (
+	"fmt"
+	"sort"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// 增量状态：服务器以客户端确认过的状态帧为基线，只发送变化的实体；
+// 客户端保存最近收到的完整状态，在对应基线上应用增量，重建出与 GameState 相同的完整状态。
+// 玩家、炸弹、爆炸按 ID 比较，道具、效果等没有 ID 的列表变化时整体发送。
+
+// DiffGameState 计算 cur 相对 base 的增量
+// tileChanges 为 base 之后（不含 base、含 cur）所有帧的地图变化，由调用方按帧收集
+func DiffGameState(base, cur *gamev1.GameState, tileChanges []*gamev1.TileChange) *gamev1.DeltaState {
+	delta := &gamev1.DeltaState{
+		FrameId:          cur.FrameId,
+		BaseFrameId:      base.FrameId,
+		LastProcessedSeq: cur.LastProcessedSeq,
+		Phase:            cur.Phase,
+		MatchEndFrame:    cur.MatchEndFrame,
+		TileChanges:      tileChanges,
+
+		OvertimeFloodFrame: cur.OvertimeFloodFrame,
+		Boss:               cur.Boss,
+		Checksum:           cur.Checksum,
+		InterestRadius:     cur.InterestRadius,
+	}
+
+	basePlayers := make(map[int32]*gamev1.PlayerState, len(base.Players))
+	for _, p := range base.Players {
+		basePlayers[p.Id] = p
+	}
+	for _, p := range cur.Players {
+		if d := diffPlayer(basePlayers[p.Id], p); d != nil {
+			delta.PlayerDeltas = append(delta.PlayerDeltas, d)
+		}
+		delete(basePlayers, p.Id)
+	}
+	for id := range basePlayers {
+		delta.RemovedPlayerIds = append(delta.RemovedPlayerIds, id)
+	}
+	sort.Slice(delta.RemovedPlayerIds, func(i, j int) bool { return delta.RemovedPlayerIds[i] < delta.RemovedPlayerIds[j] })
+
+	delta.ChangedBombs, delta.RemovedBombIds = diffByID(base.Bombs, cur.Bombs, (*gamev1.BombState).GetId)
+	delta.ChangedExplosions, delta.RemovedExplosionIds = diffByID(base.Explosions, cur.Explosions, (*gamev1.ExplosionState).GetId)
+
+	if !equalMessages(base.Items, cur.Items) {
+		delta.ItemsChanged = true
+		delta.Items = cur.Items
+	}
+	if !equalMessages(base.PlayerEffects, cur.PlayerEffects) {
+		delta.PlayerEffectsChanged = true
+		delta.PlayerEffects = cur.PlayerEffects
+	}
+	if !equalMessages(base.Hazards, cur.Hazards) {
+		delta.HazardsChanged = true
+		delta.Hazards = cur.Hazards
+	}
+	if !equalMessages(base.WarningTiles, cur.WarningTiles) {
+		delta.WarningTilesChanged = true
+		delta.WarningTiles = cur.WarningTiles
+	}
+	return delta
+}
+
+// ApplyDeltaState 在 base 上应用增量，返回新的完整状态（base 不会被修改）
+func ApplyDeltaState(base *gamev1.GameState, delta *gamev1.DeltaState) (*gamev1.GameState, error) {
+	if base.FrameId != delta.BaseFrameId {
+		return nil, fmt.Errorf("增量基线不匹配: 需要帧 %d，当前基线为帧 %d", delta.BaseFrameId, base.FrameId)
+	}
+
+	state := proto.Clone(base).(*gamev1.GameState)
+	state.FrameId = delta.FrameId
+	state.Phase = delta.Phase
+	state.MatchEndFrame = delta.MatchEndFrame
+	state.OvertimeFloodFrame = delta.OvertimeFloodFrame
+	state.Boss = delta.Boss
+	state.Checksum = delta.Checksum
+	state.InterestRadius = delta.InterestRadius
+	state.LastProcessedSeq = delta.LastProcessedSeq
+	state.TileChanges = delta.TileChanges
+
+	removed := make(map[int32]bool, len(delta.RemovedPlayerIds))
+	for _, id := range delta.RemovedPlayerIds {
+		removed[id] = true
+	}
+	players := make(map[int32]*gamev1.PlayerState, len(state.Players))
+	kept := state.Players[:0]
+	for _, p := range state.Players {
+		if !removed[p.Id] {
+			players[p.Id] = p
+			kept = append(kept, p)
+		}
+	}
+	state.Players = kept
+	for _, d := range delta.PlayerDeltas {
+		p, ok := players[d.Id]
+		if !ok {
+			p = &gamev1.PlayerState{Id: d.Id}
+			players[d.Id] = p
+			state.Players = append(state.Players, p)
+		}
+		applyPlayerDelta(p, d)
+	}
+	// 服务器按 ID 升序发送玩家
+	sort.Slice(state.Players, func(i, j int) bool { return state.Players[i].Id < state.Players[j].Id })
+
+	state.Bombs = applyByID(state.Bombs, delta.ChangedBombs, delta.RemovedBombIds, (*gamev1.BombState).GetId)
+	state.Explosions = applyByID(state.Explosions, delta.ChangedExplosions, delta.RemovedExplosionIds, (*gamev1.ExplosionState).GetId)
+
+	if delta.ItemsChanged {
+		state.Items = delta.Items
+	}
+	if delta.PlayerEffectsChanged {
+		state.PlayerEffects = delta.PlayerEffects
+	}
+	if delta.HazardsChanged {
+		state.Hazards = delta.Hazards
+	}
+	if delta.WarningTilesChanged {
+		state.WarningTiles = delta.WarningTiles
+	}
+	return state, nil
+}
+
+// diffPlayer 只记录变化的字段，base 为 nil（新玩家）时记录全部字段；没有变化返回 nil
+func diffPlayer(base, cur *gamev1.PlayerState) *gamev1.PlayerDelta {
+	// 新玩家即使所有字段都是零值也要发送，客户端才会创建它
+	changed := base == nil
+	if base == nil {
+		base = &gamev1.PlayerState{}
+	}
+	d := &gamev1.PlayerDelta{Id: cur.Id}
+	if base.X != cur.X {
+		d.X, changed = proto.Float64(cur.X), true
+	}
+	if base.Y != cur.Y {
+		d.Y, changed = proto.Float64(cur.Y), true
+	}
+	if base.Direction != cur.Direction {
+		d.Direction, changed = cur.Direction.Enum(), true
+	}
+	if base.IsMoving != cur.IsMoving {
+		d.IsMoving, changed = proto.Bool(cur.IsMoving), true
+	}
+	if base.Dead != cur.Dead {
+		d.Dead, changed = proto.Bool(cur.Dead), true
+	}
+	if base.Character != cur.Character {
+		d.Character, changed = cur.Character.Enum(), true
+	}
+	if base.NextPlacementFrame != cur.NextPlacementFrame {
+		d.NextPlacementFrame, changed = proto.Int32(cur.NextPlacementFrame), true
+	}
+	if base.CurrentBombs != cur.CurrentBombs {
+		d.CurrentBombs, changed = proto.Int32(cur.CurrentBombs), true
+	}
+	if base.MaxBombs != cur.MaxBombs {
+		d.MaxBombs, changed = proto.Int32(cur.MaxBombs), true
+	}
+	if base.BombRange != cur.BombRange {
+		d.BombRange, changed = proto.Int32(cur.BombRange), true
+	}
+	if base.Speed != cur.Speed {
+		d.Speed, changed = proto.Float64(cur.Speed), true
+	}
+	if base.Team != cur.Team {
+		d.Team, changed = proto.Int32(cur.Team), true
+	}
+	if base.CanKick != cur.CanKick {
+		d.CanKick, changed = proto.Bool(cur.CanKick), true
+	}
+	if base.Stamina != cur.Stamina {
+		d.Stamina, changed = proto.Int32(cur.Stamina), true
+	}
+	if base.Sprinting != cur.Sprinting {
+		d.Sprinting, changed = proto.Bool(cur.Sprinting), true
+	}
+	if base.Kills != cur.Kills {
+		d.Kills, changed = proto.Int32(cur.Kills), true
+	}
+	if !changed {
+		return nil
+	}
+	return d
+}
+
+// applyPlayerDelta 把增量中出现的字段写入玩家状态
+func applyPlayerDelta(p *gamev1.PlayerState, d *gamev1.PlayerDelta) {
+	if d.X != nil {
+		p.X = *d.X
+	}
+	if d.Y != nil {
+		p.Y = *d.Y
+	}
+	if d.Direction != nil {
+		p.Direction = *d.Direction
+	}
+	if d.IsMoving != nil {
+		p.IsMoving = *d.IsMoving
+	}
+	if d.Dead != nil {
+		p.Dead = *d.Dead
+	}
+	if d.Character != nil {
+		p.Character = *d.Character
+	}
+	if d.NextPlacementFrame != nil {
+		p.NextPlacementFrame = *d.NextPlacementFrame
+	}
+	if d.CurrentBombs != nil {
+		p.CurrentBombs = *d.CurrentBombs
+	}
+	if d.MaxBombs != nil {
+		p.MaxBombs = *d.MaxBombs
+	}
+	if d.BombRange != nil {
+		p.BombRange = *d.BombRange
+	}
+	if d.Speed != nil {
+		p.Speed = *d.Speed
+	}
+	if d.Team != nil {
+		p.Team = *d.Team
+	}
+	if d.CanKick != nil {
+		p.CanKick = *d.CanKick
+	}
+	if d.Stamina != nil {
+		p.Stamina = *d.Stamina
+	}
+	if d.Sprinting != nil {
+		p.Sprinting = *d.Sprinting
+	}
+	if d.Kills != nil {
+		p.Kills = *d.Kills
+	}
+}
+
+// diffByID 按 ID 比较实体列表：返回新增或变化的实体（按 cur 中的顺序）和被移除的 ID
+func diffByID[T proto.Message](base, cur []T, id func(T) int32) (changed []T, removed []int32) {
+	baseByID := make(map[int32]T, len(base))
+	for _, e := range base {
+		baseByID[id(e)] = e
+	}
+	for _, e := range cur {
+		old, ok := baseByID[id(e)]
+		if !ok || !proto.Equal(old, e) {
+			changed = append(changed, e)
+		}
+		delete(baseByID, id(e))
+	}
+	for _, e := range base {
+		if _, gone := baseByID[id(e)]; gone {
+			removed = append(removed, id(e))
+		}
+	}
+	return changed, removed
+}
+
+// applyByID 移除 removed 中的实体，原位替换变化的实体，新实体追加到末尾（与服务器的插入顺序一致）
+func applyByID[T proto.Message](base, changed []T, removed []int32, id func(T) int32) []T {
+	gone := make(map[int32]bool, len(removed))
+	for _, r := range removed {
+		gone[r] = true
+	}
+	updates := make(map[int32]T, len(changed))
+	for _, e := range changed {
+		updates[id(e)] = e
+	}
+
+	result := make([]T, 0, len(base)+len(changed))
+	for _, e := range base {
+		if gone[id(e)] {
+			continue
+		}
+		if u, ok := updates[id(e)]; ok {
+			e = u
+			delete(updates, id(e))
+		}
+		result = append(result, e)
+	}
+	for _, e := range changed {
+		if _, added := updates[id(e)]; added {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// equalMessages 逐个比较两个消息列表
+func equalMessages[T proto.Message](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !proto.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package protocol
+
+import (
+	"testing"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// snapshotBombs 只包含炸弹和爆炸的状态（增量测试只关心这两类实体）
+func snapshotBombs(g *core.Game) *gamev1.GameState {
+	return &gamev1.GameState{
+		FrameId:    g.CurrentFrame,
+		Bombs:      CoreBombsToProto(g.Bombs),
+		Explosions: CoreExplosionsToProto(g.Explosions),
+	}
+}
+
+// TestDeltaAfterEarlierBombExplodes 前一颗炸弹爆炸后，后面的炸弹 ID 不变，增量只移除爆炸的炸弹
+func TestDeltaAfterEarlierBombExplodes(t *testing.T) {
+	g := core.NewGame(42)
+	first := core.NewBomb(1, 1, 1, 0)
+	first.ExplodeAtFrame = 1
+	first.ExplosionRange = 1
+	second := core.NewBomb(g.Map.Width-2, g.Map.Height-2, 2, 0)
+	second.ExplosionRange = 1
+	g.AddBomb(first)
+	g.AddBomb(second)
+	if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+		t.Fatalf("炸弹 ID %d、%d，期望不同的非零 ID", first.ID, second.ID)
+	}
+
+	base := snapshotBombs(g)
+	g.Update()
+	if len(g.Bombs) != 1 || g.Bombs[0] != second {
+		t.Fatalf("第一颗炸弹爆炸后剩余 %d 颗炸弹，期望只剩第二颗", len(g.Bombs))
+	}
+	cur := snapshotBombs(g)
+
+	delta := DiffGameState(base, cur, nil)
+	if len(delta.RemovedBombIds) != 1 || delta.RemovedBombIds[0] != first.ID {
+		t.Errorf("移除的炸弹 %v，期望 [%d]", delta.RemovedBombIds, first.ID)
+	}
+	if len(delta.ChangedBombs) != 0 {
+		t.Errorf("没有变化的炸弹不应重发，得到 %v", delta.ChangedBombs)
+	}
+	if len(delta.ChangedExplosions) != 1 || len(delta.RemovedExplosionIds) != 0 {
+		t.Errorf("应只新增一处爆炸，得到新增/变化 %d、移除 %v", len(delta.ChangedExplosions), delta.RemovedExplosionIds)
+	}
+
+	rebuilt, err := ApplyDeltaState(base, delta)
+	if err != nil {
+		t.Fatalf("应用增量失败: %v", err)
+	}
+	if !equalMessages(rebuilt.Bombs, cur.Bombs) || !equalMessages(rebuilt.Explosions, cur.Explosions) {
+		t.Errorf("应用增量后的状态与当前状态不一致\n得到 %v\n期望 %v", rebuilt, cur)
+	}
+}
+
+// TestDeltaAfterEarlierExplosionExpires 前一处爆炸结束后，后面的爆炸 ID 不变，增量只移除结束的爆炸
+func TestDeltaAfterEarlierExplosionExpires(t *testing.T) {
+	g := core.NewGame(42)
+	g.AddExplosion(&core.Explosion{Cells: []core.GridPos{{GridX: 1, GridY: 1}}, ExpiresAtFrame: 1})
+	later := &core.Explosion{Cells: []core.GridPos{{GridX: 3, GridY: 1}}, ExpiresAtFrame: 100}
+	g.AddExplosion(later)
+	expiredID := g.Explosions[0].ID
+
+	base := snapshotBombs(g)
+	g.Update()
+	cur := snapshotBombs(g)
+
+	delta := DiffGameState(base, cur, nil)
+	if len(delta.RemovedExplosionIds) != 1 || delta.RemovedExplosionIds[0] != expiredID {
+		t.Errorf("移除的爆炸 %v，期望 [%d]", delta.RemovedExplosionIds, expiredID)
+	}
+	if len(delta.ChangedExplosions) != 0 {
+		t.Errorf("没有变化的爆炸不应重发，得到 %v", delta.ChangedExplosions)
+	}
+	if len(cur.Explosions) != 1 || !proto.Equal(cur.Explosions[0], CoreExplosionToProto(later)) {
+		t.Errorf("剩余的爆炸 %v，期望 ID %d", cur.Explosions, later.ID)
+	}
+}
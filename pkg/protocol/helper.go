@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/version"
 
 	"google.golang.org/protobuf/proto"
 )
@@ -27,9 +28,28 @@ func NewClientInputPacket(seq int32, frameId int32, up, down, left, right, bomb
 
 // NewClientInputPacketWithInputs 构造批量输入消息包
 func NewClientInputPacketWithInputs(seq int32, inputs []*gamev1.InputData) (*gamev1.Packet, error) {
+	return NewClientInputPacketWithAck(seq, inputs, 0)
+}
+
+// NewEventAckPacket 构造只确认关键事件的输入消息包（不包含输入，seq 为 0 不影响输入序号）
+func NewEventAckPacket(eventSeqs []int32) (*gamev1.Packet, error) {
+	payload, err := proto.Marshal(&gamev1.ClientInput{AckEventSeqs: eventSeqs})
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_CLIENT_INPUT,
+		Payload: payload,
+	}, nil
+}
+
+// NewClientInputPacketWithAck 构造批量输入消息包，并确认已收到的最新状态帧（增量状态的基线）
+func NewClientInputPacketWithAck(seq int32, inputs []*gamev1.InputData, ackStateFrame int32) (*gamev1.Packet, error) {
 	input := &gamev1.ClientInput{
-		Seq:    seq,
-		Inputs: inputs,
+		Seq:           seq,
+		Inputs:        inputs,
+		AckStateFrame: ackStateFrame,
 	}
 
 	payload, err := proto.Marshal(input)
@@ -43,12 +63,21 @@ func NewClientInputPacketWithInputs(seq int32, inputs []*gamev1.InputData) (*gam
 	}, nil
 }
 
-// NewJoinRequestPacket 构造加入请求消息包
-func NewJoinRequestPacket(playerName string, characterType gamev1.CharacterType, roomID string) (*gamev1.Packet, error) {
+// NewJoinRequestPacket 构造加入请求消息包（spectate=true 表示以观战者身份加入，takeOverAI=true 表示申请接管游戏中的 AI，
+// accountKey 非空时服务器按账号保存和恢复偏好，telemetryOptIn=true 表示同意服务器收集输入观察并写入举报，requestID 由服务器原样带回响应）。请求中附带本程序的协议版本和发布版本号（pkg/version）
+func NewJoinRequestPacket(requestID int32, playerName string, characterType gamev1.CharacterType, roomID string, spectate bool, takeOverAI bool, accountKey string, telemetryOptIn bool) (*gamev1.Packet, error) {
 	req := &gamev1.JoinRequest{
-		PlayerName: playerName,
-		Character:  characterType,
-		RoomId:     roomID,
+		RequestId:      requestID,
+		PlayerName:     playerName,
+		Character:      characterType,
+		RoomId:         roomID,
+		Spectate:       spectate,
+		TakeOverAi:     takeOverAI,
+		AccountKey:     accountKey,
+		TelemetryOptIn: telemetryOptIn,
+
+		ProtocolVersion: version.Protocol,
+		ClientVersion:   version.Game,
 	}
 
 	payload, err := proto.Marshal(req)
@@ -62,13 +91,8 @@ func NewJoinRequestPacket(playerName string, characterType gamev1.CharacterType,
 	}, nil
 }
 
-// NewRoomListRequestPacket 构造房间列表请求消息包
-func NewRoomListRequestPacket(page, pageSize int32) (*gamev1.Packet, error) {
-	req := &gamev1.RoomListRequest{
-		Page:     page,
-		PageSize: pageSize,
-	}
-
+// NewRoomListRequestPacket 构造房间列表请求消息包（分页与过滤条件）
+func NewRoomListRequestPacket(req *gamev1.RoomListRequest) (*gamev1.Packet, error) {
 	payload, err := proto.Marshal(req)
 	if err != nil {
 		return nil, err
@@ -110,11 +134,98 @@ func NewPingPacket(clientTime int64) (*gamev1.Packet, error) {
 	}, nil
 }
 
+// NewServerStatusRequestPacket 构造服务器状态查询消息包
+func NewServerStatusRequestPacket(clientTime int64) (*gamev1.Packet, error) {
+	req := &gamev1.ServerStatusRequest{
+		ClientTime: clientTime,
+	}
+
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_REQUEST,
+		Payload: payload,
+	}, nil
+}
+
+// NewReplaySearchRequestPacket 构造录像搜索消息包
+func NewReplaySearchRequestPacket(req *gamev1.ReplaySearchRequest) (*gamev1.Packet, error) {
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_REQUEST,
+		Payload: payload,
+	}, nil
+}
+
+// NewDesyncReportPacket 构造不同步上报消息包
+func NewDesyncReportPacket(frameID int32, server, client *gamev1.StateChecksum) (*gamev1.Packet, error) {
+	report := &gamev1.DesyncReport{
+		FrameId: frameID,
+		Server:  server,
+		Client:  client,
+	}
+
+	payload, err := proto.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_DESYNC_REPORT,
+		Payload: payload,
+	}, nil
+}
+
+// NewProfileUpdateRequestPacket 构造偏好修改消息包
+func NewProfileUpdateRequestPacket(accountKey string, profile *gamev1.PlayerProfile) (*gamev1.Packet, error) {
+	req := &gamev1.ProfileUpdateRequest{
+		AccountKey: accountKey,
+		Profile:    profile,
+	}
+
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_REQUEST,
+		Payload: payload,
+	}, nil
+}
+
+// NewMapUploadRequestPacket 构造地图上传消息包
+func NewMapUploadRequestPacket(name string, mapJSON []byte) (*gamev1.Packet, error) {
+	req := &gamev1.MapUploadRequest{
+		Name:    name,
+		MapJson: mapJSON,
+	}
+
+	payload, err := proto.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_REQUEST,
+		Payload: payload,
+	}, nil
+}
+
 // ========== 服务器消息构造 ==========
 
 // NewJoinResponsePacket 构造加入响应消息包
-func NewJoinResponsePacket(success bool, playerId int32, errorMessage string, gameSeed int64, tps int32, sessionToken string, roomID string, roomState *gamev1.RoomStateUpdate) (*gamev1.Packet, error) {
+// requestID 为加入请求的请求 ID，history 为房间最近的聊天和事件记录
+func NewJoinResponsePacket(requestID int32, success bool, playerId int32, errorMessage string, gameSeed int64, tps int32, sessionToken string, roomID string, roomState *gamev1.RoomStateUpdate, history []*gamev1.RoomHistoryEntry, profile *gamev1.PlayerProfile) (*gamev1.Packet, error) {
 	resp := &gamev1.JoinResponse{
+		RequestId:    requestID,
 		Success:      success,
 		PlayerId:     playerId,
 		ErrorMessage: errorMessage,
@@ -123,6 +234,32 @@ func NewJoinResponsePacket(success bool, playerId int32, errorMessage string, ga
 		SessionToken: sessionToken,
 		RoomId:       roomID,
 		RoomState:    roomState,
+		History:      history,
+		MapId:        roomState.GetMapId(),
+		Profile:      profile,
+	}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_JOIN_RESPONSE,
+		Payload: payload,
+	}, nil
+}
+
+// NewJoinFailurePacket 构造加入失败响应消息包
+// errorParams 为客户端本地化使用的参数，errorMessage 仅作兜底展示
+func NewJoinFailurePacket(requestID int32, errorCode gamev1.ErrorCode, errorParams []string, errorMessage string) (*gamev1.Packet, error) {
+	resp := &gamev1.JoinResponse{
+		RequestId:    requestID,
+		Success:      false,
+		PlayerId:     -1,
+		ErrorCode:    errorCode,
+		ErrorParams:  errorParams,
+		ErrorMessage: errorMessage,
 	}
 
 	payload, err := proto.Marshal(resp)
@@ -137,10 +274,13 @@ func NewJoinResponsePacket(success bool, playerId int32, errorMessage string, ga
 }
 
 // NewRoomListResponsePacket 构造房间列表响应消息包
-func NewRoomListResponsePacket(rooms []*gamev1.RoomInfo, total int32) (*gamev1.Packet, error) {
+func NewRoomListResponsePacket(requestID int32, rooms []*gamev1.RoomInfo, total, page, pageSize int32) (*gamev1.Packet, error) {
 	resp := &gamev1.RoomListResponse{
-		Rooms: rooms,
-		Total: total,
+		RequestId: requestID,
+		Rooms:     rooms,
+		Total:     total,
+		Page:      page,
+		PageSize:  pageSize,
 	}
 
 	payload, err := proto.Marshal(resp)
@@ -154,10 +294,13 @@ func NewRoomListResponsePacket(rooms []*gamev1.RoomInfo, total int32) (*gamev1.P
 	}, nil
 }
 
-// NewRoomActionResponsePacket 构造房间操作响应消息包
-func NewRoomActionResponsePacket(success bool, errorMessage string, sessionToken string, roomID string) (*gamev1.Packet, error) {
+// NewRoomActionResponsePacket 构造房间操作响应消息包（requestID 为 0 表示服务器主动发送，不对应任何请求）
+func NewRoomActionResponsePacket(requestID int32, success bool, errorCode gamev1.ErrorCode, errorParams []string, errorMessage string, sessionToken string, roomID string) (*gamev1.Packet, error) {
 	resp := &gamev1.RoomActionResponse{
+		RequestId:    requestID,
 		Success:      success,
+		ErrorCode:    errorCode,
+		ErrorParams:  errorParams,
 		ErrorMessage: errorMessage,
 		SessionToken: sessionToken,
 		RoomId:       roomID,
@@ -187,6 +330,38 @@ func NewRoomStateUpdatePacket(update *gamev1.RoomStateUpdate) (*gamev1.Packet, e
 	}, nil
 }
 
+// NewServerNoticePacket 构造服务器通知消息包
+func NewServerNoticePacket(noticeType gamev1.NoticeType, message string, secondsRemaining int32) (*gamev1.Packet, error) {
+	notice := &gamev1.ServerNotice{
+		Type:             noticeType,
+		Message:          message,
+		SecondsRemaining: secondsRemaining,
+	}
+
+	payload, err := proto.Marshal(notice)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_SERVER_NOTICE,
+		Payload: payload,
+	}, nil
+}
+
+// NewDebugAIStatePacket 构造 AI 调试信息消息包
+func NewDebugAIStatePacket(state *gamev1.DebugAIState) (*gamev1.Packet, error) {
+	payload, err := proto.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_DEBUG_AI_STATE,
+		Payload: payload,
+	}, nil
+}
+
 // NewGameStatePacket 构造游戏状态消息包
 func NewGameStatePacket(
 	frameId int32,
@@ -208,7 +383,11 @@ func NewGameStatePacket(
 		LastProcessedSeq: lastProcessedSeq,
 		MatchEndFrame:    matchEndFrame,
 	}
+	return NewGameStatePacketFromState(state)
+}
 
+// NewGameStatePacketFromState 由完整的 GameState 构造消息包
+func NewGameStatePacketFromState(state *gamev1.GameState) (*gamev1.Packet, error) {
 	payload, err := proto.Marshal(state)
 	if err != nil {
 		return nil, err
@@ -220,6 +399,19 @@ func NewGameStatePacket(
 	}, nil
 }
 
+// NewDeltaStatePacket 构造增量状态消息包
+func NewDeltaStatePacket(delta *gamev1.DeltaState) (*gamev1.Packet, error) {
+	payload, err := proto.Marshal(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_DELTA_STATE,
+		Payload: payload,
+	}, nil
+}
+
 // NewGameEventPacket 构造游戏事件消息包
 func NewGameEventPacket(frameId int32, event *gamev1.GameEvent) (*gamev1.Packet, error) {
 	event.FrameId = frameId
@@ -254,12 +446,98 @@ func NewPongPacket(clientTime, serverTime int64, serverFrame int32) (*gamev1.Pac
 	}, nil
 }
 
-// NewReconnectResponsePacket 构造重连响应消息包
-func NewReconnectResponsePacket(success bool, errorMessage string, currentState *gamev1.GameState) (*gamev1.Packet, error) {
+// NewServerStatusResponsePacket 构造服务器状态响应消息包
+func NewServerStatusResponsePacket(clientTime int64, rooms, maxRooms, players int32, serverName, version, motd string) (*gamev1.Packet, error) {
+	resp := &gamev1.ServerStatusResponse{
+		ClientTime: clientTime,
+		Rooms:      rooms,
+		MaxRooms:   maxRooms,
+		Players:    players,
+		ServerName: serverName,
+		Version:    version,
+		Motd:       motd,
+	}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_RESPONSE,
+		Payload: payload,
+	}, nil
+}
+
+// NewReplaySearchResponsePacket 构造录像搜索响应消息包
+func NewReplaySearchResponsePacket(replays []*gamev1.ReplaySummary, total int32) (*gamev1.Packet, error) {
+	resp := &gamev1.ReplaySearchResponse{
+		Replays: replays,
+		Total:   total,
+	}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_RESPONSE,
+		Payload: payload,
+	}, nil
+}
+
+// NewMapUploadResponsePacket 构造地图上传结果消息包
+func NewMapUploadResponsePacket(success bool, errorMessage, name string) (*gamev1.Packet, error) {
+	resp := &gamev1.MapUploadResponse{
+		Success:      success,
+		ErrorMessage: errorMessage,
+		Name:         name,
+	}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_RESPONSE,
+		Payload: payload,
+	}, nil
+}
+
+// NewProfileUpdateResponsePacket 构造偏好修改结果消息包
+func NewProfileUpdateResponsePacket(success bool, errorCode gamev1.ErrorCode, errorParams []string, errorMessage string, profile *gamev1.PlayerProfile) (*gamev1.Packet, error) {
+	resp := &gamev1.ProfileUpdateResponse{
+		Success:      success,
+		ErrorCode:    errorCode,
+		ErrorParams:  errorParams,
+		ErrorMessage: errorMessage,
+		Profile:      profile,
+	}
+
+	payload, err := proto.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gamev1.Packet{
+		Type:    gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_RESPONSE,
+		Payload: payload,
+	}, nil
+}
+
+// NewReconnectResponsePacket 构造重连响应消息包（sessionToken 为成功时刷新的会话令牌）
+func NewReconnectResponsePacket(requestID int32, success bool, errorCode gamev1.ErrorCode, errorParams []string, errorMessage string, sessionToken string, currentState *gamev1.GameState, history []*gamev1.RoomHistoryEntry) (*gamev1.Packet, error) {
 	resp := &gamev1.ReconnectResponse{
+		RequestId:    requestID,
 		Success:      success,
 		ErrorMessage: errorMessage,
 		CurrentState: currentState,
+		History:      history,
+		ErrorCode:    errorCode,
+		ErrorParams:  errorParams,
+		SessionToken: sessionToken,
 	}
 
 	payload, err := proto.Marshal(resp)
@@ -404,6 +682,34 @@ func ParseRoomStateUpdate(pkt *gamev1.Packet) (*gamev1.RoomStateUpdate, error) {
 	return update, nil
 }
 
+// ParseServerNotice 从 Packet 中解析 ServerNotice
+func ParseServerNotice(pkt *gamev1.Packet) (*gamev1.ServerNotice, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_SERVER_NOTICE {
+		return nil, errors.New("not a server notice message")
+	}
+
+	notice := &gamev1.ServerNotice{}
+	err := proto.Unmarshal(pkt.Payload, notice)
+	if err != nil {
+		return nil, err
+	}
+	return notice, nil
+}
+
+// ParseDebugAIState 从 Packet 中解析 DebugAIState
+func ParseDebugAIState(pkt *gamev1.Packet) (*gamev1.DebugAIState, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_DEBUG_AI_STATE {
+		return nil, errors.New("not a debug ai state message")
+	}
+
+	state := &gamev1.DebugAIState{}
+	err := proto.Unmarshal(pkt.Payload, state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
 // ParseGameState 从 Packet 中解析 GameState
 func ParseGameState(pkt *gamev1.Packet) (*gamev1.GameState, error) {
 	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_GAME_STATE {
@@ -418,6 +724,20 @@ func ParseGameState(pkt *gamev1.Packet) (*gamev1.GameState, error) {
 	return state, nil
 }
 
+// ParseDeltaState 从 Packet 中解析 DeltaState
+func ParseDeltaState(pkt *gamev1.Packet) (*gamev1.DeltaState, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_DELTA_STATE {
+		return nil, errors.New("not a delta state message")
+	}
+
+	delta := &gamev1.DeltaState{}
+	err := proto.Unmarshal(pkt.Payload, delta)
+	if err != nil {
+		return nil, err
+	}
+	return delta, nil
+}
+
 // ParseGameEvent 从 Packet 中解析 GameEvent
 func ParseGameEvent(pkt *gamev1.Packet) (*gamev1.GameEvent, error) {
 	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT {
@@ -487,3 +807,129 @@ func ParseReconnectResponse(pkt *gamev1.Packet) (*gamev1.ReconnectResponse, erro
 	}
 	return resp, nil
 }
+
+// ParseServerStatusRequest 从 Packet 中解析 ServerStatusRequest
+func ParseServerStatusRequest(pkt *gamev1.Packet) (*gamev1.ServerStatusRequest, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_REQUEST {
+		return nil, errors.New("not a server status request message")
+	}
+
+	req := &gamev1.ServerStatusRequest{}
+	err := proto.Unmarshal(pkt.Payload, req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ParseServerStatusResponse 从 Packet 中解析 ServerStatusResponse
+func ParseServerStatusResponse(pkt *gamev1.Packet) (*gamev1.ServerStatusResponse, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_SERVER_STATUS_RESPONSE {
+		return nil, errors.New("not a server status response message")
+	}
+
+	resp := &gamev1.ServerStatusResponse{}
+	err := proto.Unmarshal(pkt.Payload, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ParseReplaySearchRequest 从 Packet 中解析 ReplaySearchRequest
+func ParseReplaySearchRequest(pkt *gamev1.Packet) (*gamev1.ReplaySearchRequest, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_REQUEST {
+		return nil, errors.New("not a replay search request message")
+	}
+
+	req := &gamev1.ReplaySearchRequest{}
+	err := proto.Unmarshal(pkt.Payload, req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ParseReplaySearchResponse 从 Packet 中解析 ReplaySearchResponse
+func ParseReplaySearchResponse(pkt *gamev1.Packet) (*gamev1.ReplaySearchResponse, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_REPLAY_SEARCH_RESPONSE {
+		return nil, errors.New("not a replay search response message")
+	}
+
+	resp := &gamev1.ReplaySearchResponse{}
+	err := proto.Unmarshal(pkt.Payload, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ParseDesyncReport 从 Packet 中解析 DesyncReport
+func ParseDesyncReport(pkt *gamev1.Packet) (*gamev1.DesyncReport, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_DESYNC_REPORT {
+		return nil, errors.New("not a desync report message")
+	}
+
+	report := &gamev1.DesyncReport{}
+	err := proto.Unmarshal(pkt.Payload, report)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ParseProfileUpdateRequest 从 Packet 中解析 ProfileUpdateRequest
+func ParseProfileUpdateRequest(pkt *gamev1.Packet) (*gamev1.ProfileUpdateRequest, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_REQUEST {
+		return nil, errors.New("not a profile update request message")
+	}
+
+	req := &gamev1.ProfileUpdateRequest{}
+	err := proto.Unmarshal(pkt.Payload, req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ParseProfileUpdateResponse 从 Packet 中解析 ProfileUpdateResponse
+func ParseProfileUpdateResponse(pkt *gamev1.Packet) (*gamev1.ProfileUpdateResponse, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_PROFILE_UPDATE_RESPONSE {
+		return nil, errors.New("not a profile update response message")
+	}
+
+	resp := &gamev1.ProfileUpdateResponse{}
+	err := proto.Unmarshal(pkt.Payload, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ParseMapUploadRequest 从 Packet 中解析 MapUploadRequest
+func ParseMapUploadRequest(pkt *gamev1.Packet) (*gamev1.MapUploadRequest, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_REQUEST {
+		return nil, errors.New("not a map upload request message")
+	}
+
+	req := &gamev1.MapUploadRequest{}
+	err := proto.Unmarshal(pkt.Payload, req)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// ParseMapUploadResponse 从 Packet 中解析 MapUploadResponse
+func ParseMapUploadResponse(pkt *gamev1.Packet) (*gamev1.MapUploadResponse, error) {
+	if pkt.Type != gamev1.MessageType_MESSAGE_TYPE_MAP_UPLOAD_RESPONSE {
+		return nil, errors.New("not a map upload response message")
+	}
+
+	resp := &gamev1.MapUploadResponse{}
+	err := proto.Unmarshal(pkt.Payload, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,141 @@
+package protocol
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// 服务器广播录制文件格式（gzip 压缩）：
+//
+//	magic "BMREC" + 版本号 1 字节
+//	uvarint(len) + 房间 ID
+//	重复: uvarint(Unix 毫秒) + uvarint(len) + 序列化后的 Packet
+
+const (
+	recordingMagic   = "BMREC"
+	recordingVersion = 1
+
+	// maxRecordSize 单条记录的上限，防止损坏的文件导致超大分配
+	maxRecordSize = 16 << 20
+)
+
+// Record 一条录制的出站广播
+type Record struct {
+	Time   time.Time
+	Packet []byte // 序列化后的 Packet
+}
+
+// RecordingWriter 写入录制文件
+type RecordingWriter struct {
+	gz  *gzip.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// NewRecordingWriter 创建录制写入器并写入文件头
+func NewRecordingWriter(w io.Writer, roomID string) (*RecordingWriter, error) {
+	rw := &RecordingWriter{gz: gzip.NewWriter(w)}
+	if _, err := rw.gz.Write(append([]byte(recordingMagic), recordingVersion)); err != nil {
+		return nil, err
+	}
+	if err := rw.writeBytes([]byte(roomID)); err != nil {
+		return nil, err
+	}
+	return rw, nil
+}
+
+// Write 追加一条记录
+func (rw *RecordingWriter) Write(t time.Time, packet []byte) error {
+	if err := rw.writeUvarint(uint64(t.UnixMilli())); err != nil {
+		return err
+	}
+	return rw.writeBytes(packet)
+}
+
+// Close 刷新压缩流（不关闭底层 io.Writer）
+func (rw *RecordingWriter) Close() error {
+	return rw.gz.Close()
+}
+
+func (rw *RecordingWriter) writeUvarint(v uint64) error {
+	n := binary.PutUvarint(rw.buf[:], v)
+	_, err := rw.gz.Write(rw.buf[:n])
+	return err
+}
+
+func (rw *RecordingWriter) writeBytes(data []byte) error {
+	if err := rw.writeUvarint(uint64(len(data))); err != nil {
+		return err
+	}
+	_, err := rw.gz.Write(data)
+	return err
+}
+
+// RecordingReader 读取录制文件
+type RecordingReader struct {
+	r      *bufio.Reader
+	RoomID string
+}
+
+// NewRecordingReader 读取并校验文件头
+func NewRecordingReader(r io.Reader) (*RecordingReader, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("不是有效的录制文件: %w", err)
+	}
+	rr := &RecordingReader{r: bufio.NewReader(gz)}
+
+	header := make([]byte, len(recordingMagic)+1)
+	if _, err := io.ReadFull(rr.r, header); err != nil {
+		return nil, fmt.Errorf("读取文件头失败: %w", err)
+	}
+	if string(header[:len(recordingMagic)]) != recordingMagic {
+		return nil, errors.New("不是有效的录制文件")
+	}
+	if header[len(recordingMagic)] != recordingVersion {
+		return nil, fmt.Errorf("不支持的录制文件版本 %d", header[len(recordingMagic)])
+	}
+
+	roomID, err := rr.readBytes()
+	if err != nil {
+		return nil, fmt.Errorf("读取房间 ID 失败: %w", err)
+	}
+	rr.RoomID = string(roomID)
+	return rr, nil
+}
+
+// Next 读取下一条记录，文件结束时返回 io.EOF
+// 服务器异常退出时文件末尾可能不完整，此时返回 io.ErrUnexpectedEOF
+func (rr *RecordingReader) Next() (Record, error) {
+	ms, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return Record{}, err
+	}
+	packet, err := rr.readBytes()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+	return Record{Time: time.UnixMilli(int64(ms)), Packet: packet}, nil
+}
+
+func (rr *RecordingReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(rr.r)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxRecordSize {
+		return nil, fmt.Errorf("记录长度 %d 超过上限", n)
+	}
+	data := make([]byte, n)
+	if _, err := io.ReadFull(rr.r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"errors"
+	"io"
+
+	gamev1 "bomberman/api/gen/bomberman/v1"
+	"bomberman/pkg/core"
+)
+
+// BuildReplays 将服务器录制文件按对局切分为回放
+// 每局从 GameStart 事件开始、到 GameOver 事件结束；地图种子和规则取开局前最近一次房间状态。
+// 文件末尾不完整（服务器异常退出）时保留已读到的部分
+func BuildReplays(rr *RecordingReader) ([]*gamev1.Replay, error) {
+	var (
+		replays []*gamev1.Replay
+		current *gamev1.Replay
+		room    *gamev1.RoomStateUpdate
+	)
+
+	for {
+		rec, err := rr.Next()
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return replays, err
+		}
+
+		pkt, err := UnmarshalPacket(rec.Packet)
+		if err != nil {
+			return replays, err
+		}
+
+		switch pkt.Type {
+		case gamev1.MessageType_MESSAGE_TYPE_ROOM_STATE_UPDATE:
+			if update, err := ParseRoomStateUpdate(pkt); err == nil {
+				room = update
+			}
+
+		case gamev1.MessageType_MESSAGE_TYPE_GAME_EVENT:
+			event, err := ParseGameEvent(pkt)
+			if err != nil {
+				return replays, err
+			}
+			if _, ok := event.Event.(*gamev1.GameEvent_GameStart); ok {
+				current = newReplay(rr.RoomID, room)
+				replays = append(replays, current)
+			}
+			if current == nil {
+				continue
+			}
+			frame := replayFrame(current, event.FrameId, rec.Time.UnixMilli())
+			frame.Events = append(frame.Events, event)
+			if _, ok := event.Event.(*gamev1.GameEvent_GameOver); ok {
+				current = nil
+			}
+
+		case gamev1.MessageType_MESSAGE_TYPE_GAME_STATE:
+			if current == nil {
+				continue
+			}
+			state, err := ParseGameState(pkt)
+			if err != nil {
+				return replays, err
+			}
+			replayFrame(current, state.FrameId, rec.Time.UnixMilli()).State = state
+		}
+	}
+	return replays, nil
+}
+
+func newReplay(roomID string, room *gamev1.RoomStateUpdate) *gamev1.Replay {
+	replay := &gamev1.Replay{RoomId: roomID, Tps: int32(core.TPS)}
+	if room != nil {
+		replay.Seed = room.Seed
+		replay.Rules = room.Rules
+	}
+	return replay
+}
+
+// replayFrame 返回指定帧（广播按帧号递增，只需检查最后一帧）
+func replayFrame(replay *gamev1.Replay, frameID int32, timeMs int64) *gamev1.ReplayFrame {
+	if n := len(replay.Frames); n > 0 && replay.Frames[n-1].FrameId == frameID {
+		return replay.Frames[n-1]
+	}
+	frame := &gamev1.ReplayFrame{FrameId: frameID, ServerTimeMs: timeMs}
+	replay.Frames = append(replay.Frames, frame)
+	return frame
+}
@@ -0,0 +1,139 @@
+// Package resources 角色、地图的展示文案（名称与描述）
+//
+// 协议中只传资源 ID（RoomPlayer.character_id、RoomStateUpdate.map_id 等），
+// 客户端按自己的语言在这里查找展示文案；查不到的 ID（例如新服务器定义的地图）
+// 直接显示 ID 本身，旧客户端也不会显示错误的名称。
+package resources
+
+// Lang 展示语言
+type Lang string
+
+const (
+	LangZH Lang = "zh"
+	LangEN Lang = "en"
+)
+
+// Text 一个资源的展示文案
+type Text struct {
+	Name        string
+	Description string
+}
+
+// 角色资源 ID（与 core.CharacterType.ID 一致）
+const (
+	CharacterWhite = "white"
+	CharacterBlack = "black"
+	CharacterRed   = "red"
+	CharacterBlue  = "blue"
+)
+
+// 地图资源 ID（与 core.MapID 一致：内置地图 ID，开启危险区域时带 +hazards 后缀）
+const (
+	MapDefault           = "default"
+	MapHazards           = "default+hazards"
+	MapOpen              = "open"
+	MapOpenHazards       = "open+hazards"
+	MapFortress          = "fortress"
+	MapFortressHazards   = "fortress+hazards"
+	MapCrossroads        = "crossroads"
+	MapCrossroadsHazards = "crossroads+hazards"
+	MapLarge             = "large"
+	MapLargeHazards      = "large+hazards"
+	MapRandom            = "random"
+	MapRandomHazards     = "random+hazards"
+)
+
+var characters = map[string]map[Lang]Text{
+	CharacterWhite: {
+		LangZH: {Name: "经典白", Description: "经典炸弹人造型"},
+		LangEN: {Name: "White", Description: "The classic bomber"},
+	},
+	CharacterBlack: {
+		LangZH: {Name: "暗夜黑", Description: "神秘的暗夜战士"},
+		LangEN: {Name: "Black", Description: "A mysterious night fighter"},
+	},
+	CharacterRed: {
+		LangZH: {Name: "烈焰红", Description: "火热的爆破专家"},
+		LangEN: {Name: "Red", Description: "A fiery demolition expert"},
+	},
+	CharacterBlue: {
+		LangZH: {Name: "冰霜蓝", Description: "冷静的策略大师"},
+		LangEN: {Name: "Blue", Description: "A cool-headed strategist"},
+	},
+}
+
+var maps = map[string]map[Lang]Text{
+	MapDefault: {
+		LangZH: {Name: "经典", Description: "随机砖块，隐藏出口门"},
+		LangEN: {Name: "Classic", Description: "Random bricks and a hidden exit door"},
+	},
+	MapHazards: {
+		LangZH: {Name: "熔岩", Description: "经典地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Lava", Description: "Classic map with periodic lava rows and columns"},
+	},
+	MapOpen: {
+		LangZH: {Name: "空旷", Description: "只有柱子和少量砖块，开局就能交手"},
+		LangEN: {Name: "Open", Description: "Pillars and few bricks, fights start early"},
+	},
+	MapOpenHazards: {
+		LangZH: {Name: "空旷（熔岩）", Description: "空旷地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Open Lava", Description: "Open map with periodic lava rows and columns"},
+	},
+	MapFortress: {
+		LangZH: {Name: "堡垒", Description: "中央被墙围起的砖块区，只能从两侧进入"},
+		LangEN: {Name: "Fortress", Description: "A walled brick keep in the middle, entered from the sides"},
+	},
+	MapFortressHazards: {
+		LangZH: {Name: "堡垒（熔岩）", Description: "堡垒地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Fortress Lava", Description: "Fortress map with periodic lava rows and columns"},
+	},
+	MapCrossroads: {
+		LangZH: {Name: "十字路口", Description: "四角砖块密集，中央通道开阔"},
+		LangEN: {Name: "Crossroads", Description: "Dense brick corners around open central lanes"},
+	},
+	MapCrossroadsHazards: {
+		LangZH: {Name: "十字路口（熔岩）", Description: "十字路口地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Crossroads Lava", Description: "Crossroads map with periodic lava rows and columns"},
+	},
+	MapLarge: {
+		LangZH: {Name: "广场", Description: "容纳 8 人的大地图，画面跟随玩家滚动"},
+		LangEN: {Name: "Plaza", Description: "A large map for up to 8 players that scrolls with you"},
+	},
+	MapLargeHazards: {
+		LangZH: {Name: "广场（熔岩）", Description: "广场地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Plaza Lava", Description: "Plaza map with periodic lava rows and columns"},
+	},
+	MapRandom: {
+		LangZH: {Name: "随机", Description: "按种子生成的对称地图，每局都不一样"},
+		LangEN: {Name: "Random", Description: "A symmetric map generated from the seed, new every match"},
+	},
+	MapRandomHazards: {
+		LangZH: {Name: "随机（熔岩）", Description: "随机地图，周期性出现熔岩行/列"},
+		LangEN: {Name: "Random Lava", Description: "Random map with periodic lava rows and columns"},
+	},
+}
+
+// Character 角色的展示文案
+func Character(id string, lang Lang) Text {
+	return lookup(characters, id, lang)
+}
+
+// Map 地图的展示文案
+func Map(id string, lang Lang) Text {
+	return lookup(maps, id, lang)
+}
+
+// lookup 按语言查找，缺少该语言时使用英文，未知 ID 以 ID 作为名称
+func lookup(table map[string]map[Lang]Text, id string, lang Lang) Text {
+	texts, ok := table[id]
+	if !ok {
+		return Text{Name: id}
+	}
+	if text, ok := texts[lang]; ok {
+		return text
+	}
+	if text, ok := texts[LangEN]; ok {
+		return text
+	}
+	return Text{Name: id}
+}
@@ -0,0 +1,96 @@
+package selfplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"bomberman/pkg/core"
+)
+
+// Controller 每帧为一名玩家给出输入（*ai.AIController 也满足该接口）
+type Controller interface {
+	Decide(game *core.Game) core.Input
+}
+
+// ExternalPolicy 通过标准输入/输出与外部进程交互的策略：
+// 每帧向进程的 stdin 写一行 Observation JSON，从 stdout 读一行 Action JSON。
+// 进程的 stderr 原样转发，便于调试。出错后不再与进程交互，之后的输入全部为空，错误由 Err 返回
+type ExternalPolicy struct {
+	PlayerID int
+
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	stdout   *bufio.Reader
+	observer *Observer
+	err      error
+}
+
+// StartExternalPolicy 启动外部策略进程
+func StartExternalPolicy(playerID int, name string, args ...string) (*ExternalPolicy, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &ExternalPolicy{
+		PlayerID: playerID,
+		cmd:      cmd,
+		stdin:    stdin,
+		stdout:   bufio.NewReader(stdout),
+		observer: NewObserver(),
+	}, nil
+}
+
+// Decide 把当前帧的特征发给外部进程并等待它的动作
+func (p *ExternalPolicy) Decide(game *core.Game) core.Input {
+	if p.err != nil {
+		return core.Input{}
+	}
+	obs := p.observer.Observe(game, p.PlayerID)
+	if obs == nil {
+		return core.Input{}
+	}
+	data, err := json.Marshal(obs)
+	if err != nil {
+		p.err = err
+		return core.Input{}
+	}
+	if _, err := p.stdin.Write(append(data, '\n')); err != nil {
+		p.err = fmt.Errorf("写入外部策略失败: %w", err)
+		return core.Input{}
+	}
+	line, err := p.stdout.ReadBytes('\n')
+	if err != nil {
+		p.err = fmt.Errorf("读取外部策略输出失败: %w", err)
+		return core.Input{}
+	}
+	var action Action
+	if err := json.Unmarshal(line, &action); err != nil {
+		p.err = fmt.Errorf("外部策略输出不是合法的动作 %q: %w", line, err)
+		return core.Input{}
+	}
+	return action.Input()
+}
+
+// Err 与外部进程交互时发生的第一个错误
+func (p *ExternalPolicy) Err() error {
+	return p.err
+}
+
+// Close 关闭外部进程的 stdin 并等待其退出
+func (p *ExternalPolicy) Close() error {
+	_ = p.stdin.Close()
+	return p.cmd.Wait()
+}
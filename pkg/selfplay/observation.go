@@ -0,0 +1,192 @@
+package selfplay
+
+import (
+	"bomberman/pkg/ai"
+	"bomberman/pkg/core"
+)
+
+// ViewRadius 以玩家所在格为中心的局部视野半径，视野为 (2*ViewRadius+1)² 个格子
+const ViewRadius = 5
+
+// 视野中的格子编码（同一格有多种内容时取编号最大的）
+const (
+	CellEmpty     = 0 // 空地
+	CellWall      = 1 // 不可破坏的墙（地图外也算墙）
+	CellBrick     = 2 // 砖块
+	CellDoor      = 3 // 门
+	CellItem      = 4 // 道具
+	CellBomb      = 5 // 炸弹
+	CellExplosion = 6 // 正在爆炸
+)
+
+// Observation 某一帧某名玩家看到的状态特征。
+// View 与 Danger 按行展开（先 y 后 x），下标 (dy+ViewRadius)*(2*ViewRadius+1) + (dx+ViewRadius)
+type Observation struct {
+	Frame    int32 `json:"frame"`
+	PlayerID int   `json:"player"`
+	Alive    bool  `json:"alive"`
+
+	X      float64 `json:"x"` // 像素坐标（左上角）
+	Y      float64 `json:"y"`
+	GridX  int     `json:"grid_x"`
+	GridY  int     `json:"grid_y"`
+	Width  int     `json:"map_width"`
+	Height int     `json:"map_height"`
+
+	MaxBombs    int     `json:"max_bombs"`
+	ActiveBombs int     `json:"active_bombs"` // 已放置、尚未爆炸的炸弹数
+	BombRange   int     `json:"bomb_range"`
+	Speed       float64 `json:"speed"`
+	CanKick     bool    `json:"can_kick"`
+	Stamina     int     `json:"stamina"`
+
+	FramesLeft int32 `json:"frames_left"` // 距离限时结束的帧数（-1 表示不限时）
+
+	View    []int     `json:"view"`    // 局部视野的格子编码（Cell*）
+	Danger  []float64 `json:"danger"`  // 局部视野的危险等级 0~1（炸弹覆盖、爆炸、地图危险区域）
+	Enemies []Enemy   `json:"enemies"` // 其他玩家（按 ID 升序）
+}
+
+// Enemy 其他玩家相对本玩家的格子偏移
+type Enemy struct {
+	PlayerID int  `json:"player"`
+	DX       int  `json:"dx"`
+	DY       int  `json:"dy"`
+	Alive    bool `json:"alive"`
+	Teammate bool `json:"teammate,omitempty"`
+}
+
+// Observer 提取特征。危险场按 Hard 难度的参数计算（连锁引爆、不设预判上限），
+// 每个 Observer 复用同一块缓冲，不能在多个 goroutine 中共用
+type Observer struct {
+	danger ai.DangerField
+	config ai.AIConfig
+	frame  int32 // danger 对应的帧号（同一帧多名玩家只计算一次）
+	ready  bool
+}
+
+// NewObserver 创建特征提取器
+func NewObserver() *Observer {
+	cfg := ai.DifficultyHard.Config()
+	cfg.DangerHorizon = 0
+	return &Observer{config: cfg}
+}
+
+// Observe 提取玩家 playerID 在当前帧的特征（玩家不存在时返回 nil）
+func (o *Observer) Observe(game *core.Game, playerID int) *Observation {
+	var self *core.Player
+	for _, p := range game.Players {
+		if p.ID == playerID {
+			self = p
+		}
+	}
+	if self == nil {
+		return nil
+	}
+	if !o.ready || o.frame != game.CurrentFrame {
+		o.danger.Update(game, o.config)
+		o.frame, o.ready = game.CurrentFrame, true
+	}
+
+	cell := core.PlayerXYToGrid(int(self.X), int(self.Y))
+	obs := &Observation{
+		Frame:       game.CurrentFrame,
+		PlayerID:    self.ID,
+		Alive:       !self.Dead,
+		X:           self.X,
+		Y:           self.Y,
+		GridX:       cell.GridX,
+		GridY:       cell.GridY,
+		Width:       game.Map.Width,
+		Height:      game.Map.Height,
+		MaxBombs:    self.MaxBombs,
+		ActiveBombs: activeBombs(game, self.ID),
+		BombRange:   self.BombRange,
+		Speed:       self.Speed,
+		CanKick:     self.CanKick,
+		Stamina:     self.Stamina,
+		FramesLeft:  -1,
+	}
+	if game.MatchEndFrame > 0 {
+		obs.FramesLeft = max(game.MatchEndFrame-game.CurrentFrame, 0)
+	}
+	obs.View, obs.Danger = o.view(game, cell)
+
+	for _, p := range game.Players {
+		if p.ID == self.ID {
+			continue
+		}
+		other := core.PlayerXYToGrid(int(p.X), int(p.Y))
+		obs.Enemies = append(obs.Enemies, Enemy{
+			PlayerID: p.ID,
+			DX:       other.GridX - cell.GridX,
+			DY:       other.GridY - cell.GridY,
+			Alive:    !p.Dead,
+			Teammate: game.Rules.Teams && p.Team != core.TeamNone && p.Team == self.Team,
+		})
+	}
+	return obs
+}
+
+// view 以 center 为中心的格子编码与危险等级
+func (o *Observer) view(game *core.Game, center core.GridPos) ([]int, []float64) {
+	const size = 2*ViewRadius + 1
+	cells := make([]int, size*size)
+	danger := make([]float64, size*size)
+	index := func(x, y int) int {
+		dx, dy := x-center.GridX, y-center.GridY
+		if dx < -ViewRadius || dx > ViewRadius || dy < -ViewRadius || dy > ViewRadius {
+			return -1
+		}
+		return (dy+ViewRadius)*size + (dx + ViewRadius)
+	}
+	mark := func(x, y, code int) {
+		if i := index(x, y); i >= 0 && code > cells[i] {
+			cells[i] = code
+		}
+	}
+
+	for dy := -ViewRadius; dy <= ViewRadius; dy++ {
+		for dx := -ViewRadius; dx <= ViewRadius; dx++ {
+			x, y := center.GridX+dx, center.GridY+dy
+			i := index(x, y)
+			if !game.Map.InBounds(x, y) {
+				cells[i] = CellWall
+				continue
+			}
+			switch game.Map.GetTile(x, y) {
+			case core.TileWall:
+				cells[i] = CellWall
+			case core.TileBrick:
+				cells[i] = CellBrick
+			case core.TileDoor:
+				cells[i] = CellDoor
+			}
+			danger[i] = o.danger.Level[y][x]
+		}
+	}
+	for _, item := range game.Items {
+		mark(item.GridX, item.GridY, CellItem)
+	}
+	for _, bomb := range game.Bombs {
+		if !bomb.Exploded {
+			mark(bomb.GridX, bomb.GridY, CellBomb)
+		}
+	}
+	for _, exp := range game.Explosions {
+		for _, c := range exp.Cells {
+			mark(c.GridX, c.GridY, CellExplosion)
+		}
+	}
+	return cells, danger
+}
+
+func activeBombs(game *core.Game, playerID int) int {
+	n := 0
+	for _, bomb := range game.Bombs {
+		if bomb.OwnerID == playerID && !bomb.Exploded {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,110 @@
+package selfplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"bomberman/pkg/core"
+)
+
+// Action 玩家在某一帧的输入（与 core.Input 一一对应，省略的字段为 false）
+type Action struct {
+	Up     bool `json:"up,omitempty"`
+	Down   bool `json:"down,omitempty"`
+	Left   bool `json:"left,omitempty"`
+	Right  bool `json:"right,omitempty"`
+	Bomb   bool `json:"bomb,omitempty"`
+	Shove  bool `json:"shove,omitempty"`
+	Sprint bool `json:"sprint,omitempty"`
+}
+
+// ActionFromInput 把核心输入转换为动作
+func ActionFromInput(in core.Input) Action {
+	return Action(in)
+}
+
+// Input 转换为核心输入
+func (a Action) Input() core.Input {
+	return core.Input(a)
+}
+
+// 对局结果
+const (
+	ResultWin  = "win"  // 本玩家（或所在队伍）获胜
+	ResultLoss = "loss" // 其他玩家获胜，或本玩家阵亡而对局以平局结束
+	ResultDraw = "draw" // 没有获胜者且本玩家存活到最后（限时结束或达到帧数上限）
+)
+
+// Outcome 玩家在一局中的最终结果，在对局结束后回填到该玩家的每条记录
+type Outcome struct {
+	Result     string  `json:"result"`
+	Reward     float64 `json:"reward"`      // win=1, loss=-1, draw=0
+	DeathFrame int32   `json:"death_frame"` // 阵亡的帧号（存活到最后为 0）
+	EndFrame   int32   `json:"end_frame"`   // 对局结束的帧号
+}
+
+// Record 导出文件中的一行：某一帧某名玩家的特征、选择的动作和最终结果
+type Record struct {
+	Game    int          `json:"game"` // 本次导出中的对局序号（从 0 开始）
+	Seed    int64        `json:"seed"`
+	Map     string       `json:"map"`
+	Obs     *Observation `json:"obs"`
+	Action  Action       `json:"action"`
+	Outcome Outcome      `json:"outcome"`
+}
+
+// Writer 按局缓存记录，结果确定后以 JSONL（每行一个 Record）写出
+type Writer struct {
+	w       *bufio.Writer
+	pending []*Record
+	count   int
+}
+
+// NewWriter 创建写入 w 的导出器
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: bufio.NewWriter(w)}
+}
+
+// Add 缓存一条记录（Outcome 由 FinishGame 回填）
+func (w *Writer) Add(rec *Record) {
+	w.pending = append(w.pending, rec)
+}
+
+// FinishGame 回填本局每名玩家的结果并写出缓存的记录
+func (w *Writer) FinishGame(outcomes map[int]Outcome) error {
+	enc := json.NewEncoder(w.w)
+	for _, rec := range w.pending {
+		rec.Outcome = outcomes[rec.Obs.PlayerID]
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	w.count += len(w.pending)
+	w.pending = w.pending[:0]
+	return w.w.Flush()
+}
+
+// Count 已写出的记录数
+func (w *Writer) Count() int {
+	return w.count
+}
+
+// Outcomes 对局结束时每名玩家的结果。winnerTeam 为获胜队伍（组队模式），
+// winnerID 为获胜玩家（-1 表示没有），deathFrames 记录各玩家阵亡的帧号
+func Outcomes(game *core.Game, winnerID, winnerTeam int, deathFrames map[int]int32) map[int]Outcome {
+	outcomes := make(map[int]Outcome, len(game.Players))
+	for _, p := range game.Players {
+		out := Outcome{DeathFrame: deathFrames[p.ID], EndFrame: game.CurrentFrame}
+		switch {
+		case winnerTeam != core.TeamNone && p.Team == winnerTeam, winnerTeam == core.TeamNone && p.ID == winnerID:
+			out.Result, out.Reward = ResultWin, 1
+		case winnerID >= 0 || winnerTeam != core.TeamNone || p.Dead:
+			out.Result, out.Reward = ResultLoss, -1
+		default:
+			out.Result = ResultDraw
+		}
+		outcomes[p.ID] = out
+	}
+	return outcomes
+}
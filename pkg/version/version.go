@@ -0,0 +1,25 @@
+// Package version 游戏版本与协议版本（服务器和客户端共用）
+//
+// 发布版本号和提交号由 cmd/package 在编译时注入：
+//
+//	go build -ldflags "-X bomberman/pkg/version.Game=1.2.0 -X bomberman/pkg/version.Commit=abc1234"
+package version
+
+import "fmt"
+
+// Protocol 协议版本：消息格式有不兼容的修改时加一
+// 客户端加入时携带，服务器拒绝协议版本不一致的客户端（旧客户端不携带，按 0 处理并放行）
+const Protocol = 1
+
+var (
+	Game   = "dev" // 发布版本号（开发构建为 dev）
+	Commit = ""    // 构建时的 git 提交（开发构建为空）
+)
+
+// String 完整版本描述，例如 "1.2.0 (abc1234, protocol 1)"
+func String() string {
+	if Commit == "" {
+		return fmt.Sprintf("%s (protocol %d)", Game, Protocol)
+	}
+	return fmt.Sprintf("%s (%s, protocol %d)", Game, Commit, Protocol)
+}